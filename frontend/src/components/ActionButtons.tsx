@@ -1,6 +1,7 @@
 import type { models } from "@go/models";
+import { DocsBranchAheadBehind } from "@go/services/ClientService";
 import { ArrowRight } from "lucide-react";
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import { useTranslation } from "react-i18next";
 import {
 	AlertDialog,
@@ -55,6 +56,28 @@ export const ActionButtons = ({
 }: ActionButtonsProps) => {
 	const { t } = useTranslation();
 	const [showMergeConfirm, setShowMergeConfirm] = useState(false);
+	const [mergeStatus, setMergeStatus] = useState<models.AheadBehind | null>(
+		null,
+	);
+	const sessionId = docResult?.sessionId;
+
+	useEffect(() => {
+		if (!(showMergeConfirm && sessionId)) {
+			setMergeStatus(null);
+			return;
+		}
+		let isActive = true;
+		DocsBranchAheadBehind(sessionId)
+			.then((status) => {
+				if (isActive) {
+					setMergeStatus(status);
+				}
+			})
+			.catch((err) => console.error("failed to compare docs branch:", err));
+		return () => {
+			isActive = false;
+		};
+	}, [showMergeConfirm, sessionId]);
 
 	return (
 		<footer className="flex shrink-0 flex-col gap-2 sm:flex-row sm:items-center sm:justify-between">
@@ -129,6 +152,20 @@ export const ActionButtons = ({
 													branch: docResult.branch,
 												})}
 											</AlertDialogDescription>
+											{mergeStatus &&
+												(mergeStatus.behind > 0 ? (
+													<p className="text-amber-600 text-sm">
+														{t("common.mergeSourceDiverged", {
+															count: mergeStatus.behind,
+														})}
+													</p>
+												) : (
+													<p className="text-muted-foreground text-sm">
+														{t("common.mergeDocsAhead", {
+															count: mergeStatus.ahead,
+														})}
+													</p>
+												))}
 										</AlertDialogHeader>
 										<AlertDialogFooter>
 											<AlertDialogCancel>
@@ -147,10 +184,16 @@ export const ActionButtons = ({
 								</AlertDialog>
 							</>
 						)}
-						<Button className="gap-2 font-semibold" onClick={onApprove}>
-							{t("common.approve")}
-							<ArrowRight className="h-4 w-4" />
-						</Button>
+						{docResult.filesystemTarget ? (
+							<p className="text-muted-foreground text-sm">
+								{t("common.docsWrittenToDirectory")}
+							</p>
+						) : (
+							<Button className="gap-2 font-semibold" onClick={onApprove}>
+								{t("common.approve")}
+								<ArrowRight className="h-4 w-4" />
+							</Button>
+						)}
 					</>
 				) : (
 					<Button
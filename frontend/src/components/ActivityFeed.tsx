@@ -17,9 +17,10 @@ import {
 } from "@/lib/toolIcons";
 import { cn } from "@/lib/utils";
 import type { ChatMessage, DocGenerationStatus } from "@/stores/docGeneration";
-import type { TodoItem, ToolEvent } from "@/types/events";
+import type { ProgressEvent, TodoItem, ToolEvent } from "@/types/events";
 
 const REASONING_STREAM = "reasoning";
+const CONTENT_STREAM = "content";
 const STREAM_METADATA_KEY = "stream";
 const STREAM_STATE_KEY = "state";
 const STREAM_STATE_RESET = "reset";
@@ -35,12 +36,14 @@ function cleanMessageContent(content: string): string {
 export function ActivityFeed({
 	events,
 	todos,
+	progress,
 	messages,
 	status,
 	summary,
 }: {
 	events: ToolEvent[];
 	todos: TodoItem[];
+	progress: ProgressEvent | null;
 	messages: ChatMessage[];
 	status: DocGenerationStatus;
 	summary: string | null;
@@ -58,10 +61,46 @@ export function ActivityFeed({
 		const result: ToolEvent[] = [];
 		const reasoningBlocks = new Map<string, ToolEvent>();
 		let currentReasoningId: string | null = null;
+		const contentBlocks = new Map<string, ToolEvent>();
+		let currentContentId: string | null = null;
+		// Streamed assistant text is only a live preview; once the run ends the
+		// persisted summary is shown as a message instead
+		const showContent = status === "running";
 
 		for (const event of events) {
-			const streamName = event.metadata?.[STREAM_METADATA_KEY];
-			if (streamName === REASONING_STREAM) {
+			const isContentEvent =
+				event.kind === "content" ||
+				event.metadata?.[STREAM_METADATA_KEY] === CONTENT_STREAM;
+			if (isContentEvent) {
+				const state = event.metadata?.[STREAM_STATE_KEY];
+				if (!showContent) {
+					continue;
+				}
+				if (state === STREAM_STATE_RESET) {
+					// Start a new assistant text block
+					currentContentId = `content-${event.id}`;
+					const contentEvent: ToolEvent = {
+						id: currentContentId,
+						type: "info",
+						message: "",
+						timestamp: event.timestamp,
+						metadata: { isContent: "true" },
+					};
+					contentBlocks.set(currentContentId, contentEvent);
+					result.push(contentEvent);
+				} else if (state === STREAM_STATE_UPDATE && currentContentId) {
+					const existingBlock = contentBlocks.get(currentContentId);
+					if (existingBlock) {
+						existingBlock.message = event.message;
+						existingBlock.timestamp = event.timestamp;
+					}
+				}
+				continue;
+			}
+			const isReasoningEvent =
+				event.kind === "reasoning" ||
+				event.metadata?.[STREAM_METADATA_KEY] === REASONING_STREAM;
+			if (isReasoningEvent) {
 				const state = event.metadata?.[STREAM_STATE_KEY];
 				if (state === STREAM_STATE_RESET) {
 					// Start a new reasoning block
@@ -86,14 +125,14 @@ export function ActivityFeed({
 				continue;
 			}
 			// Filter out todo_read events
-			if (event.metadata?.tool === "todo_read") {
+			if ((event.toolName ?? event.metadata?.tool) === "todo_read") {
 				continue;
 			}
 			result.push(event);
 		}
 
 		return result;
-	}, [events]);
+	}, [events, status]);
 
 	// Create a unified display list that merges events and chat messages
 	type DisplayItem =
@@ -283,7 +322,7 @@ export function ActivityFeed({
 
 	// Helper to parse tool events and extract parameters
 	const parseToolEvent = (event: ToolEvent) => {
-		const toolMetadata = event.metadata?.tool;
+		const toolMetadata = event.toolName ?? event.metadata?.tool;
 		if (!toolMetadata) return null;
 
 		// Map backend tool names to frontend tool types
@@ -314,6 +353,10 @@ export function ActivityFeed({
 			todo_read: "todo_read",
 			todo_write_tool: "todo_write",
 			todo_write: "todo_write",
+			inspect_asset_tool: "inspect",
+			inspect: "inspect",
+			blame_tool: "blame",
+			blame: "blame",
 		};
 
 		const toolType = toolNameMap[toolMetadata];
@@ -361,6 +404,30 @@ export function ActivityFeed({
 
 	return (
 		<div className="flex min-h-0 flex-1 flex-col gap-2">
+			{isRunning && progress && (
+				<div
+					className="flex items-center gap-2"
+					title={
+						progress.estimated
+							? t("activity.progressEstimated")
+							: t("activity.progressTodos", {
+									completed: progress.completed,
+									total: progress.total,
+								})
+					}
+				>
+					<div className="h-1.5 flex-1 overflow-hidden rounded-full bg-muted">
+						<div
+							className="h-full rounded-full bg-blue-600 transition-all"
+							style={{ width: `${progress.percent}%` }}
+						/>
+					</div>
+					<span className="w-10 shrink-0 text-right text-muted-foreground text-xs tabular-nums">
+						{progress.estimated ? "~" : ""}
+						{progress.percent}%
+					</span>
+				</div>
+			)}
 			{/* Active todo or completion status - visible when todos exist */}
 			{(activeTodo || allCompleted) && (
 				<div
@@ -583,6 +650,38 @@ export function ActivityFeed({
 								const isReasoning = event.metadata?.isReasoning === "true";
 								const isLastEvent = index === displayItems.length - 1;
 
+								if (event.metadata?.isContent === "true") {
+									if (!event.message.trim()) {
+										return null;
+									}
+									const isVisible = visibleEvents.includes(event.id);
+									return (
+										<li
+											className={cn("transition-all duration-300", {
+												"translate-y-0 opacity-100": isVisible,
+												"translate-y-2 opacity-0": !isVisible,
+											})}
+											key={event.id}
+										>
+											<div className="flex w-full items-start gap-2.5 rounded-lg border border-border bg-background px-3 py-2">
+												<div className="min-w-0 flex-1">
+													<div className="mb-0.5 flex items-center gap-1.5">
+														<span className="font-medium text-foreground text-xs">
+															{t("activity.assistant", "Assistant")}
+														</span>
+														{isLastEvent && inProgress && (
+															<Loader2 className="h-3 w-3 animate-spin text-muted-foreground" />
+														)}
+													</div>
+													<div className="break-words text-foreground/90 text-sm">
+														<MarkdownRenderer content={event.message} />
+													</div>
+												</div>
+											</div>
+										</li>
+									);
+								}
+
 								if (isReasoning) {
 									const isVisible = visibleEvents.includes(event.id);
 									const isExpanded = expandedReasoning.has(event.id);
@@ -786,6 +885,67 @@ export function ActivityFeed({
 									);
 								}
 
+								// Completion card for a finished run
+								if (event.summary) {
+									const summary = event.summary;
+									const toolCalls = Object.values(
+										summary.toolCalls ?? {},
+									).reduce((total, count) => total + count, 0);
+									const isVisible = visibleEvents.includes(event.id);
+									return (
+										<li
+											className={cn("transition-all duration-300", {
+												"translate-y-0 opacity-100": isVisible,
+												"translate-y-2 opacity-0": !isVisible,
+											})}
+											key={event.id}
+										>
+											<div className="flex items-start gap-2.5 rounded-lg border border-emerald-500/20 bg-emerald-500/10 px-3 py-2">
+												<CheckCircle2 className="mt-0.5 h-4 w-4 shrink-0 text-emerald-600" />
+												<div className="min-w-0 flex-1">
+													<div className="font-medium text-foreground text-sm">
+														{t("activity.runComplete")}
+													</div>
+													<div className="flex flex-wrap gap-x-3 text-muted-foreground text-xs">
+														<span>
+															{t("activity.runFiles", {
+																created: summary.filesCreated,
+																modified: summary.filesModified,
+																deleted: summary.filesDeleted,
+															})}
+														</span>
+														<span>
+															{t("activity.runToolCalls", { count: toolCalls })}
+														</span>
+														{summary.totalTokens > 0 && (
+															<span>
+																{t("activity.runTokens", {
+																	count: summary.totalTokens,
+																})}
+															</span>
+														)}
+														{(summary.reasoningTokens ?? 0) > 0 && (
+															<span>
+																{t("activity.runReasoningTokens", {
+																	count: summary.reasoningTokens,
+																})}
+															</span>
+														)}
+														<span>
+															{t("activity.runElapsed", {
+																seconds: Math.round(summary.elapsedMs / 1000),
+															})}
+														</span>
+													</div>
+												</div>
+												<span className="ml-auto shrink-0 text-muted-foreground text-xs">
+													{event.timestamp.toLocaleTimeString()}
+												</span>
+											</div>
+										</li>
+									);
+								}
+
 								// Regular event display
 								const isVisible = visibleEvents.includes(event.id);
 								return (
@@ -5,7 +5,7 @@ import {
 	CheckIcon,
 	ChevronsUpDownIcon,
 } from "lucide-react";
-import { useId, useMemo } from "react";
+import { useId, useMemo, useState } from "react";
 import { useTranslation } from "react-i18next";
 import { Button } from "@/components/ui/button";
 import {
@@ -64,8 +64,26 @@ export const BranchSelector = ({
 	const targetBranchComboboxId = useId();
 	const targetBranchListId = useId();
 
+	const [sourceQuery, setSourceQuery] = useState("");
+	const [targetQuery, setTargetQuery] = useState("");
+
 	const canSwap = Boolean(sourceBranch && targetBranch);
 
+	// Any revision the backend can resolve (tag, remote branch, SHA) may be typed in
+	const customRevision = (query: string, other: string | undefined) => {
+		const revision = query.trim();
+		if (
+			!revision ||
+			revision === other ||
+			branches.some((b) => b.name === revision)
+		) {
+			return null;
+		}
+		return revision;
+	};
+	const sourceRevision = customRevision(sourceQuery, targetBranch);
+	const targetRevision = customRevision(targetQuery, sourceBranch);
+
 	const availableSourceBranches = useMemo(
 		() => branches.filter((b) => b.name !== targetBranch),
 		[branches, targetBranch],
@@ -113,9 +131,27 @@ export const BranchSelector = ({
 						)}
 					>
 						<Command>
-							<CommandInput placeholder="Search branch..." />
+							<CommandInput
+								onValueChange={setSourceQuery}
+								placeholder="Search branch..."
+								value={sourceQuery}
+							/>
 							<CommandList className="max-h-[200px]" id={sourceBranchListId}>
 								<CommandEmpty>No branch found.</CommandEmpty>
+								{sourceRevision && (
+									<CommandGroup>
+										<CommandItem
+											onSelect={() => {
+												setSourceBranch(sourceRevision);
+												setSourceQuery("");
+												setSourceOpen(false);
+											}}
+											value={sourceRevision}
+										>
+											{t("common.useRevision", { revision: sourceRevision })}
+										</CommandItem>
+									</CommandGroup>
+								)}
 								<CommandGroup>
 									{availableSourceBranches.map((b) => (
 										<CommandItem
@@ -191,9 +227,27 @@ export const BranchSelector = ({
 						)}
 					>
 						<Command>
-							<CommandInput placeholder="Search branch..." />
+							<CommandInput
+								onValueChange={setTargetQuery}
+								placeholder="Search branch..."
+								value={targetQuery}
+							/>
 							<CommandList className="max-h-[200px]" id={targetBranchListId}>
 								<CommandEmpty>No branch found.</CommandEmpty>
+								{targetRevision && (
+									<CommandGroup>
+										<CommandItem
+											onSelect={() => {
+												setTargetBranch(targetRevision);
+												setTargetQuery("");
+												setTargetOpen(false);
+											}}
+											value={targetRevision}
+										>
+											{t("common.useRevision", { revision: targetRevision })}
+										</CommandItem>
+									</CommandGroup>
+								)}
 								<CommandGroup>
 									{availableTargetBranches.map((b) => (
 										<CommandItem
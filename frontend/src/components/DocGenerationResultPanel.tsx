@@ -3,6 +3,7 @@ import { useCallback, useEffect, useMemo, useState } from "react";
 import { Diff, Hunk, parseDiff } from "react-diff-view";
 import { useTranslation } from "react-i18next";
 import { DocRefinementChat } from "@/components/DocRefinementChat";
+import { RouteTreePreview } from "@/components/RouteTreePreview";
 import { Button } from "@/components/ui/button";
 import {
 	Tooltip,
@@ -86,6 +87,14 @@ export function DocGenerationResultPanel({
 		return map;
 	}, [result?.files]);
 
+	const statMap = useMemo(() => {
+		const map = new Map<string, { additions: number; deletions: number }>();
+		for (const stat of result?.diffStat ?? []) {
+			map.set(normalizeDiffPath(stat.path), stat);
+		}
+		return map;
+	}, [result?.diffStat]);
+
 	const entries = useMemo(
 		() =>
 			parsedDiff.map((file) => {
@@ -98,9 +107,10 @@ export function DocGenerationResultPanel({
 					diff: file,
 					path: key,
 					status: statusMap.get(key) ?? "changed",
+					stat: statMap.get(key),
 				};
 			}),
-		[parsedDiff, statusMap],
+		[parsedDiff, statusMap, statMap],
 	);
 
 	const [selectedPath, setSelectedPath] = useState<string | null>(null);
@@ -124,6 +134,16 @@ export function DocGenerationResultPanel({
 	}
 
 	const hasDiff = entries.length > 0 && result.diff.trim().length > 0;
+	const frontmatterWarnings = Object.entries(result.frontmatterWarnings ?? {});
+	const componentWarnings = result.componentWarnings ?? [];
+	const totalAdditions = (result.diffStat ?? []).reduce(
+		(sum, stat) => sum + stat.additions,
+		0,
+	);
+	const totalDeletions = (result.diffStat ?? []).reduce(
+		(sum, stat) => sum + stat.deletions,
+		0,
+	);
 
 	return (
 		<section className="flex h-full flex-col gap-4 overflow-hidden rounded-lg border border-border bg-card p-4">
@@ -172,6 +192,43 @@ export function DocGenerationResultPanel({
 				</div>
 			</header>
 
+			{frontmatterWarnings.length > 0 && (
+				<div className="shrink-0 rounded-md border border-amber-500/40 bg-amber-500/10 p-3 text-xs sm:text-sm">
+					<div className="mb-1 font-medium text-foreground">
+						{t("common.frontmatterWarnings", "Frontmatter issues")}
+					</div>
+					<ul className="space-y-0.5 text-muted-foreground">
+						{frontmatterWarnings.map(([path, problems]) => (
+							<li key={path}>
+								<span className="font-mono">{path}</span>: {problems.join("; ")}
+							</li>
+						))}
+					</ul>
+				</div>
+			)}
+
+			{componentWarnings.length > 0 && (
+				<div className="shrink-0 rounded-md border border-amber-500/40 bg-amber-500/10 p-3 text-xs sm:text-sm">
+					<div className="mb-1 font-medium text-foreground">
+						{t("common.componentWarnings", "MDX component issues")}
+					</div>
+					<ul className="space-y-0.5 text-muted-foreground">
+						{componentWarnings.map((warning) => (
+							<li key={`${warning.path}:${warning.component}`}>
+								<span className="font-mono">
+									{warning.path}:{warning.line}
+								</span>
+								: {warning.message}
+							</li>
+						))}
+					</ul>
+				</div>
+			)}
+
+			{result.routeTree && (
+				<RouteTreePreview files={result.files ?? []} tree={result.routeTree} />
+			)}
+
 			{hasDiff ? (
 				<div
 					className={cn(
@@ -182,8 +239,14 @@ export function DocGenerationResultPanel({
 					)}
 				>
 					<div className="flex max-h-48 min-h-0 flex-col gap-2 overflow-hidden lg:h-full lg:max-h-none">
-						<div className="text-muted-foreground text-xs uppercase tracking-wide">
-							{t("common.files", "Files")}
+						<div className="flex items-center justify-between text-muted-foreground text-xs uppercase tracking-wide">
+							<span>{t("common.files", "Files")}</span>
+							{(result.diffStat?.length ?? 0) > 0 && (
+								<span className="font-mono normal-case">
+									<span className="text-emerald-600">+{totalAdditions}</span>{" "}
+									<span className="text-red-600">-{totalDeletions}</span>
+								</span>
+							)}
 						</div>
 						<ul className="min-h-0 flex-1 space-y-0.5 overflow-y-auto pr-1">
 							{entries.map((entry) => (
@@ -222,6 +285,16 @@ export function DocGenerationResultPanel({
 																	)}
 																>
 																	{t(statusKey)}
+																	{entry.stat && (
+																		<span className="ml-2 font-mono">
+																			<span className="text-emerald-600">
+																				+{entry.stat.additions}
+																			</span>{" "}
+																			<span className="text-red-600">
+																				-{entry.stat.deletions}
+																			</span>
+																		</span>
+																	)}
 																	{isChanged && (
 																		<span className="ml-2 inline-flex items-center rounded border border-amber-200 bg-amber-100/60 px-1.5 py-0.5 font-medium text-[10px] text-amber-800">
 																			{t("common.updated")}
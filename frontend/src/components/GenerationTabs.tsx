@@ -5,13 +5,14 @@ import { DocGenerationResultPanel } from "@/components/DocGenerationResultPanel"
 import { Tabs, TabsContent, TabsList, TabsTrigger } from "@/components/ui/tabs";
 import { cn } from "@/lib/utils";
 import type { ChatMessage, DocGenerationStatus } from "@/stores/docGeneration";
-import type { TodoItem, ToolEvent } from "@/types/events";
+import type { ProgressEvent, TodoItem, ToolEvent } from "@/types/events";
 
 interface GenerationTabsProps {
 	activeTab: "activity" | "review";
 	setActiveTab: (tab: "activity" | "review") => void;
 	events: ToolEvent[];
 	todos: TodoItem[];
+	progress: ProgressEvent | null;
 	messages: ChatMessage[];
 	status: DocGenerationStatus;
 	docResult: models.DocGenerationResult | null;
@@ -24,6 +25,7 @@ export const GenerationTabs = ({
 	setActiveTab,
 	events,
 	todos,
+	progress,
 	messages,
 	status,
 	docResult,
@@ -61,6 +63,7 @@ export const GenerationTabs = ({
 				<ActivityFeed
 					events={events}
 					messages={messages}
+					progress={progress}
 					status={status}
 					summary={docResult?.summary ?? null}
 					todos={todos}
@@ -0,0 +1,84 @@
+import { CreateDocumentationBaseBranch } from "@go/services/ClientService";
+import { useState } from "react";
+import { useTranslation } from "react-i18next";
+import { Button } from "@/components/ui/button";
+import {
+	Dialog,
+	DialogContent,
+	DialogDescription,
+	DialogFooter,
+	DialogHeader,
+	DialogTitle,
+} from "@/components/ui/dialog";
+import { useDocGenerationStore } from "@/stores/docGeneration";
+
+export type MissingBaseBranchDialogProps = {
+	projectId: number;
+	sessionKey: string;
+	branch: string;
+	from: string;
+	open: boolean;
+};
+
+// Offers to create a documentation base branch that does not exist yet in a
+// separate documentation repository. Generation is started again by the user.
+export const MissingBaseBranchDialog = ({
+	projectId,
+	sessionKey,
+	branch,
+	from,
+	open,
+}: MissingBaseBranchDialogProps) => {
+	const { t } = useTranslation();
+	const [busy, setBusy] = useState(false);
+	const [error, setError] = useState<string | null>(null);
+	const clearMissingBaseBranch = useDocGenerationStore(
+		(s) => s.clearMissingBaseBranch,
+	);
+
+	const handleClose = (next: boolean) => {
+		if (!next) {
+			setError(null);
+			clearMissingBaseBranch(sessionKey);
+		}
+	};
+
+	const handleCreate = async () => {
+		setBusy(true);
+		setError(null);
+		try {
+			await CreateDocumentationBaseBranch(projectId, from);
+			clearMissingBaseBranch(sessionKey);
+		} catch (err) {
+			setError(err instanceof Error ? err.message : String(err));
+		} finally {
+			setBusy(false);
+		}
+	};
+
+	return (
+		<Dialog onOpenChange={handleClose} open={open}>
+			<DialogContent>
+				<DialogHeader>
+					<DialogTitle>{t("common.docsBaseBranchMissingTitle")}</DialogTitle>
+					<DialogDescription>
+						{t("common.docsBaseBranchMissingDescription", { branch, from })}
+					</DialogDescription>
+				</DialogHeader>
+				{error ? <p className="text-destructive text-sm">{error}</p> : null}
+				<DialogFooter>
+					<Button
+						disabled={busy}
+						onClick={() => handleClose(false)}
+						variant="outline"
+					>
+						{t("common.cancel")}
+					</Button>
+					<Button disabled={busy} onClick={handleCreate}>
+						{t("common.createBaseBranch", { branch })}
+					</Button>
+				</DialogFooter>
+			</DialogContent>
+		</Dialog>
+	);
+};
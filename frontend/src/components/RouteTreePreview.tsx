@@ -0,0 +1,90 @@
+import type { models } from "@go/models";
+import { useTranslation } from "react-i18next";
+import { cn } from "@/lib/utils";
+
+function isChanged(node: models.RouteNode, changedPaths: string[]): boolean {
+	if (!node.path) {
+		return false;
+	}
+	return changedPaths.some(
+		(path) => path === node.path || path.endsWith(`/${node.path}`),
+	);
+}
+
+function RouteTreeItems({
+	nodes,
+	changedPaths,
+}: {
+	nodes: models.RouteNode[];
+	changedPaths: string[];
+}) {
+	return (
+		<ul className="space-y-0.5">
+			{nodes.map((node, index) => {
+				const key = `${node.type}-${node.url ?? node.title}-${index}`;
+				if (node.type === "separator") {
+					return (
+						<li
+							className="pt-2 font-medium text-[11px] text-muted-foreground uppercase tracking-wide"
+							key={key}
+						>
+							{node.title}
+						</li>
+					);
+				}
+				const changed = isChanged(node, changedPaths);
+				return (
+					<li key={key}>
+						<div
+							className={cn(
+								"flex items-baseline gap-2",
+								node.type === "folder" && "font-medium text-foreground",
+								changed && "text-emerald-600",
+							)}
+						>
+							<span className="truncate">{node.title}</span>
+							{node.url && (
+								<span className="truncate font-mono text-[11px] text-muted-foreground">
+									{node.url}
+								</span>
+							)}
+						</div>
+						{node.children && node.children.length > 0 && (
+							<div className="ml-3 border-border border-l pl-2">
+								<RouteTreeItems
+									changedPaths={changedPaths}
+									nodes={node.children}
+								/>
+							</div>
+						)}
+					</li>
+				);
+			})}
+		</ul>
+	);
+}
+
+export function RouteTreePreview({
+	tree,
+	files,
+}: {
+	tree: models.RouteNode;
+	files: models.DocChangedFile[];
+}) {
+	const { t } = useTranslation();
+	const changedPaths = files.map((file) => file.path);
+
+	return (
+		<details className="shrink-0 rounded-md border border-border p-3 text-xs sm:text-sm">
+			<summary className="cursor-pointer font-medium text-foreground">
+				{t("common.navigationPreview", "Navigation preview")}
+			</summary>
+			<div className="mt-2 max-h-64 overflow-y-auto text-muted-foreground">
+				<RouteTreeItems
+					changedPaths={changedPaths}
+					nodes={tree.children ?? []}
+				/>
+			</div>
+		</details>
+	);
+}
@@ -130,6 +130,16 @@ export function SessionSelectorModal({
 															{t("generations.running")}
 														</span>
 													)}
+													{session.status === "merged" && (
+														<span className="rounded-full bg-muted px-2 py-0.5 font-medium text-muted-foreground text-xs">
+															{t("generations.merged")}
+														</span>
+													)}
+													{session.status === "stale" && (
+														<span className="rounded-full bg-destructive/10 px-2 py-0.5 font-medium text-destructive text-xs">
+															{t("generations.stale")}
+														</span>
+													)}
 												</CardTitle>
 												<CardDescription className="mt-1 flex items-center gap-2">
 													<Clock className="h-3 w-3" />
@@ -16,6 +16,7 @@ import {
 } from "@dnd-kit/sortable";
 import { CSS } from "@dnd-kit/utilities";
 import { models } from "@go/models";
+import { InitNarrabyteConfig } from "@go/services/ClientService";
 import { Init } from "@go/services/GitService";
 import {
 	Delete,
@@ -226,10 +227,28 @@ function AppSidebarContent() {
 	}, [loadProjects]);
 
 	// Helper function to handle successful project linking
+	// Newly linked projects are listed first; give them a starter
+	// .narrabyte/llm_instructions.md unless they already have instructions
+	const scaffoldInstructions = async () => {
+		try {
+			const [linked] = ((await List(1, REPO_OFFSET)) as models.RepoLink[]) ?? [];
+			if (!linked) {
+				return;
+			}
+			const result = await InitNarrabyteConfig(linked.ID);
+			if (result?.created) {
+				toast(t("home.instructionsScaffolded"));
+			}
+		} catch (error) {
+			console.error("Error creating LLM instructions template:", error);
+		}
+	};
+
 	const handleSuccess = () => {
 		toast(t("home.linkSuccess"));
 		setIsAddProjectOpen(false);
 		loadProjects();
+		void scaffoldInstructions();
 	};
 
 	// Helper function to handle missing git repository error
@@ -14,6 +14,7 @@ import { BranchSelector } from "@/components/BranchSelector";
 import { ComparisonDisplay } from "@/components/ComparisonDisplay";
 import { DeleteSessionDialog } from "@/components/DeleteSessionDialog";
 import { DocBranchConflictDialog } from "@/components/DocBranchConflictDialog";
+import { MissingBaseBranchDialog } from "@/components/MissingBaseBranchDialog";
 import {
 	type BranchSelectionState,
 	ProjectDetailTabsSection,
@@ -99,6 +100,13 @@ export function ProjectDetailPage({ projectId }: { projectId: string }) {
 		}
 		return null;
 	});
+	const missingBaseBranch = useDocGenerationStore((s) => {
+		const activeSessionKey = s.activeSession[String(projectId)];
+		if (activeSessionKey) {
+			return s.docStates[activeSessionKey]?.missingBaseBranch ?? null;
+		}
+		return null;
+	});
 	const createTabSession = useDocGenerationStore((s) => s.createTabSession);
 
 	// Read the app's default model preference (if any)
@@ -674,6 +682,16 @@ export function ProjectDetailPage({ projectId }: { projectId: string }) {
 				);
 			})()}
 
+			{missingBaseBranch && activeDocManager.sessionKey ? (
+				<MissingBaseBranchDialog
+					branch={missingBaseBranch.branch}
+					from={missingBaseBranch.from}
+					open={true}
+					projectId={Number(project?.ID ?? projectId)}
+					sessionKey={activeDocManager.sessionKey}
+				/>
+			) : null}
+
 			<DeleteSessionDialog
 				branchName={pendingDeletion?.branchName}
 				onConfirm={confirmDeletion}
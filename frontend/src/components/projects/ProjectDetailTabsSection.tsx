@@ -320,6 +320,7 @@ function TabContentRenderer({
 						docResult={docManager.docResult}
 						events={docManager.events}
 						messages={docManager.messages}
+						progress={docManager.progress}
 						projectId={Number(project.ID)}
 						sessionKey={docManager.sessionKey}
 						setActiveTab={docManager.setActiveTab}
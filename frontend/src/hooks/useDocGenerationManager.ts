@@ -103,6 +103,9 @@ export const useDocGenerationManager = (projectId: string, tabId?: string) => {
 			(sessionKey ? s.docStates[sessionKey]?.todos : EMPTY_TODOS) ??
 			EMPTY_TODOS,
 	);
+	const progress = useDocGenerationStore((s) =>
+		sessionKey ? (s.docStates[sessionKey]?.progress ?? null) : null,
+	);
 	const messages = useDocGenerationStore(
 		(s) =>
 			(sessionKey ? s.docStates[sessionKey]?.messages : EMPTY_MESSAGES) ??
@@ -252,6 +255,7 @@ export const useDocGenerationManager = (projectId: string, tabId?: string) => {
 		messages,
 		events,
 		todos,
+		progress,
 		docGenerationError,
 		activeTab,
 		setActiveTab,
@@ -7,6 +7,8 @@ import {
 	FilePlus,
 	FileText,
 	FolderOpen,
+	GitCommitHorizontal,
+	Image,
 	ListTodo,
 	type LucideIcon,
 	Move,
@@ -31,6 +33,8 @@ export type ToolType =
 	| "copy"
 	| "todo_read"
 	| "todo_write"
+	| "inspect"
+	| "blame"
 	| "unknown";
 
 export const toolIconMap: Record<ToolType, LucideIcon> = {
@@ -47,6 +51,8 @@ export const toolIconMap: Record<ToolType, LucideIcon> = {
 	copy: Copy,
 	todo_read: ListTodo,
 	todo_write: CheckSquare,
+	inspect: Image,
+	blame: GitCommitHorizontal,
 	unknown: Wrench,
 };
 
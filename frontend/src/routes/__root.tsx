@@ -5,6 +5,8 @@ import {
 } from "@tanstack/react-router";
 import { TanStackRouterDevtools } from "@tanstack/router-devtools";
 import { useEffect } from "react";
+import { useTranslation } from "react-i18next";
+import { toast } from "sonner";
 import { CurrentGenerationsIndicator } from "@/components/CurrentGenerationsIndicator";
 import { ProjectTitleHeader } from "@/components/ProjectTitleHeader";
 import {
@@ -15,6 +17,8 @@ import { AppSidebar } from "@/components/Sidebar";
 import { SidebarInset, SidebarProvider } from "@/components/ui/sidebar";
 import { Toaster } from "@/components/ui/sonner";
 import { useAppSettingsStore } from "@/stores/appSettings";
+import { toolEventSchema } from "@/types/events";
+import { EventsOn } from "../../wailsjs/runtime";
 
 function ThemeSync() {
 	const { settings } = useAppSettingsStore();
@@ -55,12 +59,39 @@ function ThemeSync() {
 	return null;
 }
 
+// Sessions moved out of a tab no longer show their events, so let the user
+// know when one of their runs finishes or fails.
+function BackgroundRunNotifier() {
+	const { t } = useTranslation();
+
+	useEffect(() => {
+		return EventsOn("events:llm:done", (payload) => {
+			const parsed = toolEventSchema.safeParse(payload);
+			if (!parsed.success) {
+				return;
+			}
+			const evt = parsed.data;
+			if (!evt.background) {
+				return;
+			}
+			if (evt.type === "success") {
+				toast.success(t("common.backgroundRunComplete"));
+			} else if (evt.type === "error") {
+				toast.error(t("common.backgroundRunFailed"));
+			}
+		});
+	}, [t]);
+
+	return null;
+}
+
 function RootLayout() {
 	return (
 		<ProjectCacheProvider>
 			<SidebarProvider className="h-screen w-full overflow-hidden">
 				<Toaster />
 				<ThemeSync />
+				<BackgroundRunNotifier />
 				<AppSidebar />
 				<SidebarInset className="flex h-full w-full flex-col overflow-hidden">
 					<div className="flex w-full items-center px-4 py-2">
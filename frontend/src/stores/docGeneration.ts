@@ -10,7 +10,10 @@ import {
 	RefineDocs,
 	StopStream,
 } from "@go/services/ClientService";
-import { DeleteBranchByPath } from "@go/services/GitService";
+import {
+	DeleteBranchByPath,
+	HasUnpushedCommitsByPath,
+} from "@go/services/GitService";
 import {
 	DeleteByID as DeleteGenerationSession,
 	GetByDocsBranch,
@@ -20,6 +23,8 @@ import i18n from "i18next";
 import { parseDiff } from "react-diff-view";
 import { create } from "zustand";
 import {
+	type ProgressEvent,
+	progressEventSchema,
 	type TodoItem,
 	type ToolEvent,
 	todoEventSchema,
@@ -82,6 +87,12 @@ type DocsBranchConflict = {
 	isInProgress?: boolean; // true if conflict is due to ongoing generation, false/undefined if branch just exists
 };
 
+type MissingBaseBranch = {
+	branch: string;
+	// Ref the branch can be created from (the checked-out branch or HEAD)
+	from: string;
+};
+
 type DocGenerationData = {
 	sessionId: number | null;
 	projectId: number;
@@ -89,6 +100,8 @@ type DocGenerationData = {
 	sessionKey: SessionKey;
 	events: ToolEvent[];
 	todos: TodoItem[];
+	// Latest progress reported by the running agent, null until it reports any
+	progress: ProgressEvent | null;
 	status: DocGenerationStatus;
 	result: models.DocGenerationResult | null;
 	error: string | null;
@@ -105,7 +118,11 @@ type DocGenerationData = {
 	docsInCodeRepo: boolean;
 	docsBranch: string | null;
 	mergeInProgress: boolean;
+	// Key of the last generation request; kept after a failure so retrying
+	// reuses it, cleared once the run succeeds
+	idempotencyKey?: string | null;
 	conflict?: DocsBranchConflict | null;
+	missingBaseBranch?: MissingBaseBranch | null;
 };
 
 type State = {
@@ -179,6 +196,7 @@ type State = {
 		sessionKey?: SessionKey;
 	}) => Promise<void>;
 	clearConflict: (sessionKey: SessionKey) => void;
+	clearMissingBaseBranch: (sessionKey: SessionKey) => void;
 };
 
 const EMPTY_DOC_STATE: DocGenerationData = {
@@ -188,6 +206,7 @@ const EMPTY_DOC_STATE: DocGenerationData = {
 	sessionKey: "",
 	events: [],
 	todos: [],
+	progress: null,
 	status: "idle",
 	result: null,
 	error: null,
@@ -235,6 +254,8 @@ export const createTempSessionKey = (
 };
 
 const WHITESPACE_REGEX = /\s+/g;
+// Mirrors the backend: revisions like HEAD~2 hold characters invalid in branch names
+const REVISION_CHARS_REGEX = /[~^:]/g;
 
 const documentationBranchName = (
 	sourceBranch: string | null | undefined,
@@ -243,7 +264,7 @@ const documentationBranchName = (
 	if (!trimmed) {
 		return "docs";
 	}
-	return `docs/${trimmed.replace(WHITESPACE_REGEX, "-")}`;
+	return `docs/${trimmed.replace(WHITESPACE_REGEX, "-").replace(REVISION_CHARS_REGEX, "-")}`;
 };
 
 const backendSessionBindings = new Map<SessionKey, Set<SessionKey>>();
@@ -328,6 +349,32 @@ const mapErrorCodeToMessage = (errorMessage: string): string => {
 		return i18n.t("common.docsBranchExists");
 	}
 
+	if (trimmed.startsWith("ERR_DOCS_BASE_BRANCH_MISSING")) {
+		return i18n.t("common.docsBaseBranchMissing", {
+			branch: extractMissingBaseBranch(trimmed)?.branch ?? "",
+		});
+	}
+
+	if (trimmed.startsWith("ERR_NO_CHANGES:")) {
+		return i18n.t("common.noCodeChanges");
+	}
+
+	if (trimmed.startsWith("ERR_REASONING_BUDGET:")) {
+		return i18n.t("common.reasoningBudgetExceeded");
+	}
+
+	if (trimmed.startsWith("ERR_TOO_MANY_RUNS:")) {
+		return i18n.t("common.tooManyRuns");
+	}
+
+	const providerError = extractProviderError(trimmed);
+	if (providerError) {
+		return i18n.t(`common.providerError.${providerError.kind}`, {
+			provider: providerError.provider,
+			defaultValue: errorMessage,
+		});
+	}
+
 	// Check for specific error codes
 	if (trimmed === "ERR_UNCOMMITTED_CHANGES_ON_SOURCE_BRANCH") {
 		return i18n.t("common.mergeDisabledUncommittedChanges");
@@ -335,6 +382,22 @@ const mapErrorCodeToMessage = (errorMessage: string): string => {
 	return errorMessage;
 };
 
+// Extract a classified provider failure from errors like
+// - ERR_PROVIDER:invalid_key:Anthropic:<reason>
+const extractProviderError = (
+	errorMessage: string,
+): { kind: string; provider: string } | null => {
+	const prefix = "ERR_PROVIDER:";
+	if (!errorMessage.startsWith(prefix)) {
+		return null;
+	}
+	const [kind, provider] = errorMessage.slice(prefix.length).split(":");
+	if (!kind) {
+		return null;
+	}
+	return { kind, provider: provider?.trim() ?? "" };
+};
+
 const extractExistingDocsBranch = (errorMessage: string): string | null => {
 	const idx = errorMessage.indexOf("ERR_DOCS_BRANCH_EXISTS:");
 	if (idx === -1) {
@@ -346,6 +409,32 @@ const extractExistingDocsBranch = (errorMessage: string): string | null => {
 	return after || null;
 };
 
+// Extract the missing documentation base branch from error messages like
+// - ERR_DOCS_BASE_BRANCH_MISSING:docs
+// - ERR_DOCS_BASE_BRANCH_MISSING_SUGGEST:docs:main (from is empty without a suggestion)
+const extractMissingBaseBranch = (
+	errorMessage: string,
+): MissingBaseBranch | null => {
+	const suggestPrefix = "ERR_DOCS_BASE_BRANCH_MISSING_SUGGEST:";
+	const prefix = "ERR_DOCS_BASE_BRANCH_MISSING:";
+	const trimmed = errorMessage.trim();
+	if (trimmed.startsWith(suggestPrefix)) {
+		const remainder = trimmed.slice(suggestPrefix.length);
+		const colonIndex = remainder.indexOf(":");
+		if (colonIndex === -1) {
+			return null;
+		}
+		const branch = remainder.slice(0, colonIndex).trim();
+		const from = remainder.slice(colonIndex + 1).trim();
+		return branch ? { branch, from } : null;
+	}
+	if (trimmed.startsWith(prefix)) {
+		const branch = trimmed.slice(prefix.length).trim();
+		return branch ? { branch, from: "" } : null;
+	}
+	return null;
+};
+
 // Extract branch conflict information from error messages with suggestions
 // Handles formats like:
 // - ERR_DOCS_BRANCH_EXISTS_SUGGEST:docs/feature:docs/feature-2
@@ -403,6 +492,14 @@ const createLocalEvent = (
 	timestamp: new Date(),
 });
 
+// Sent with each generation request so a retried call returns the session it
+// already started instead of creating another one. A retry reuses the key of
+// the failed attempt; the backend forgets keys of runs that failed.
+const createIdempotencyKey = (): string =>
+	typeof crypto !== "undefined" && "randomUUID" in crypto
+		? crypto.randomUUID()
+		: `${Date.now()}-${Math.random().toString(36).slice(2)}`;
+
 const STARTS_WITH_A_SLASH_REGEX = /^a\//;
 const STARTS_WITH_B_SLASH_REGEX = /^b\//;
 
@@ -537,6 +634,7 @@ type SubscriptionMap = {
 	tool?: () => void;
 	done?: () => void;
 	todo?: () => void;
+	progress?: () => void;
 };
 
 // Subscriptions are now per-session instead of per-project
@@ -550,6 +648,7 @@ const clearSubscriptions = (key: SessionKey) => {
 	entry.tool?.();
 	entry.done?.();
 	entry.todo?.();
+	entry.progress?.();
 	subscriptions.delete(key);
 };
 
@@ -738,10 +837,26 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 			}
 		});
 
+		const progressUnsub = EventsOn("event:llm:progress", (payload) => {
+			try {
+				const evt = progressEventSchema.parse(payload);
+				if (!isEventForSession(evt.sessionKey, sessionKey, baseSessionKey)) {
+					return;
+				}
+				setDocState(sessionKey, (prev) => ({
+					...prev,
+					progress: evt,
+				}));
+			} catch (error) {
+				console.error("Invalid progress event", error, payload);
+			}
+		});
+
 		subscriptions.set(sessionKey, {
 			tool: toolUnsub,
 			done: doneUnsub,
 			todo: todoUnsub,
+			progress: progressUnsub,
 		});
 	};
 
@@ -755,7 +870,10 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 		userInstructions: string;
 		tabId?: TabId;
 		conflictMode: GenerationMode;
-		runRequest: (sessionKey: SessionKey) => Promise<models.DocGenerationResult>;
+		runRequest: (
+			sessionKey: SessionKey,
+			idempotencyKey: string,
+		) => Promise<models.DocGenerationResult>;
 	};
 
 	const runGeneration = async ({
@@ -801,6 +919,9 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 			});
 		}
 
+		const idempotencyKey =
+			currentState?.idempotencyKey ?? createIdempotencyKey();
+
 		// Set initial running state IMMEDIATELY so the UI shows the message right away
 		setDocState(tempSessionKey, {
 			sessionId: null,
@@ -811,6 +932,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 			todos: [],
 			error: null,
 			result: null,
+			progress: null,
 			status: "running",
 			cancellationRequested: false,
 			activeTab: "activity",
@@ -825,6 +947,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 			docsInCodeRepo: false,
 			docsBranch,
 			mergeInProgress: false,
+			idempotencyKey,
 		});
 		setActiveSessionKey(projectKey, tempSessionKey);
 		updateSessionMeta(tempSessionKey, {
@@ -882,7 +1005,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 		subscribeToGenerationEvents(tempSessionKey, tempSessionKey);
 
 		try {
-			const result = await runRequest(tempSessionKey);
+			const result = await runRequest(tempSessionKey, idempotencyKey);
 			// Extract sessionId from result and update state
 			const sessionId = result?.sessionId ?? null;
 			// Merge backend chat messages with our initial messages, preserving local timestamps
@@ -902,12 +1025,25 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 				docsInCodeRepo: Boolean(result?.docsInCodeRepo),
 				docsBranch: result?.docsBranch ?? null,
 				mergeInProgress: false,
+				idempotencyKey: null,
 				messages: mergedMessages,
 			});
 			updateSessionMeta(tempSessionKey, { sessionId, status: "success" });
 		} catch (error) {
 			const message = messageFromError(error);
 
+			const missingBase = extractMissingBaseBranch(message);
+			if (missingBase?.from) {
+				setDocState(tempSessionKey, (prev) => ({
+					...prev,
+					error: null,
+					status: "idle",
+					missingBaseBranch: missingBase,
+				}));
+				updateSessionMeta(tempSessionKey, { status: "idle" });
+				return;
+			}
+
 			const suggestion = extractBranchConflictSuggestion(message);
 			if (suggestion) {
 				setDocState(tempSessionKey, (prev) => ({
@@ -1070,7 +1206,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 				userInstructions,
 				tabId,
 				conflictMode: "diff",
-				runRequest: (sessionKey) =>
+				runRequest: (sessionKey, idempotencyKey) =>
 					GenerateDocs(
 						projectId,
 						sourceBranch,
@@ -1079,6 +1215,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 						userInstructions,
 						"",
 						sessionKey,
+						idempotencyKey,
 					),
 			});
 		},
@@ -1100,7 +1237,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 				userInstructions,
 				tabId,
 				conflictMode: "single",
-				runRequest: (sessionKey) =>
+				runRequest: (sessionKey, idempotencyKey) =>
 					GenerateDocsFromBranch(
 						projectId,
 						sourceBranch,
@@ -1108,6 +1245,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 						userInstructions,
 						"",
 						sessionKey,
+						idempotencyKey,
 					),
 			});
 		},
@@ -1361,17 +1499,36 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 
 					if (repoRoot && current.docsBranch) {
 						try {
-							await DeleteBranchByPath(repoRoot, current.docsBranch);
-							setDocState(sessionKey, (prev) => ({
-								...prev,
-								events: [
-									...prev.events,
-									createLocalEvent(
-										"info",
-										`Deleted docs branch '${current.docsBranch}'`,
-									),
-								],
-							}));
+							// Never drop commits that only exist locally
+							const unpushed = await HasUnpushedCommitsByPath(
+								repoRoot,
+								current.docsBranch,
+								"",
+							);
+							if (unpushed) {
+								setDocState(sessionKey, (prev) => ({
+									...prev,
+									events: [
+										...prev.events,
+										createLocalEvent(
+											"warn",
+											`Kept docs branch '${current.docsBranch}' because it has commits that are not on the remote`,
+										),
+									],
+								}));
+							} else {
+								await DeleteBranchByPath(repoRoot, current.docsBranch);
+								setDocState(sessionKey, (prev) => ({
+									...prev,
+									events: [
+										...prev.events,
+										createLocalEvent(
+											"info",
+											`Deleted docs branch '${current.docsBranch}'`,
+										),
+									],
+								}));
+							}
 						} catch (error) {
 							console.error("Failed to delete docs branch", error);
 							setDocState(sessionKey, (prev) => ({
@@ -1475,6 +1632,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 				...prev,
 				messages: [...prev.messages, userMessage],
 				error: null,
+				progress: null,
 				status: "running",
 			}));
 
@@ -1643,6 +1801,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 					projectId,
 					projectName: finalProjectName,
 					sessionKey,
+					progress: null,
 					status: "running",
 					events: [
 						createLocalEvent(
@@ -1742,6 +1901,10 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 			setDocState(sessionKey, { conflict: null, error: null });
 		},
 
+		clearMissingBaseBranch: (sessionKey: SessionKey) => {
+			setDocState(sessionKey, { missingBaseBranch: null, error: null });
+		},
+
 		resolveDocsBranchConflictByDelete: async ({
 			projectId,
 			projectName,
@@ -1863,6 +2026,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 			if (!(existing && targetName)) {
 				return;
 			}
+			const idempotencyKey = state.idempotencyKey ?? createIdempotencyKey();
 			try {
 				// If tabId provided, associate this session with the tab
 				if (tabId) {
@@ -1877,6 +2041,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 					projectName: state.projectName,
 					sessionKey,
 					activeTab: "activity",
+					progress: null,
 					status: "running",
 					sourceBranch,
 					targetBranch:
@@ -1889,6 +2054,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 					docsInCodeRepo: false,
 					docsBranch: targetName,
 					mergeInProgress: false,
+					idempotencyKey,
 					initialDiffSignatures: null,
 					changedSinceInitial: [],
 					todos: [],
@@ -1915,6 +2081,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 						userInstructions,
 						targetName,
 						sessionKey,
+						idempotencyKey,
 					);
 				} else {
 					result = await GenerateDocsFromBranch(
@@ -1924,6 +2091,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 						userInstructions,
 						targetName,
 						sessionKey,
+						idempotencyKey,
 					);
 				}
 
@@ -1944,6 +2112,7 @@ export const useDocGenerationStore = create<State>((set, get, _api) => {
 					),
 					docsBranch: result?.docsBranch ?? targetName,
 					mergeInProgress: false,
+					idempotencyKey: null,
 					conflict: null,
 				}));
 				updateSessionMeta(sessionKey, {
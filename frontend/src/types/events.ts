@@ -1,13 +1,44 @@
 import { z } from "zod/v4";
 
+// Mirrors events.EventKind in the backend
+export const eventKindSchema = z.enum([
+	"log",
+	"tool",
+	"policy",
+	"reasoning",
+	"content",
+]);
+
+export type EventKind = z.infer<typeof eventKindSchema>;
+
+// Mirrors events.RunSummary, attached to the completion event of a run
+export const runSummarySchema = z.object({
+	filesCreated: z.number(),
+	filesModified: z.number(),
+	filesDeleted: z.number(),
+	toolCalls: z.record(z.string(), z.number()).nullable().optional(),
+	promptTokens: z.number(),
+	completionTokens: z.number(),
+	reasoningTokens: z.number().optional(),
+	totalTokens: z.number(),
+	elapsedMs: z.number(),
+});
+
+export type RunSummary = z.infer<typeof runSummarySchema>;
+
 // Zod schema for ToolEvent
 export const toolEventSchema = z.object({
 	id: z.string().uuid(),
 	type: z.enum(["success", "info", "warn", "error"]),
+	kind: eventKindSchema.optional(),
+	toolName: z.string().optional(),
 	message: z.string(),
 	timestamp: z.coerce.date(),
 	sessionKey: z.string().optional(),
 	metadata: z.record(z.string(), z.string()).optional(),
+	summary: runSummarySchema.optional(),
+	// Set on the completion event of a run whose session is not in a tab
+	background: z.boolean().optional(),
 });
 
 export type ToolEvent = z.infer<typeof toolEventSchema>;
@@ -30,3 +61,17 @@ export const todoEventSchema = z.object({
 });
 
 export type TodoEvent = z.infer<typeof todoEventSchema>;
+
+// Zod schema for ProgressEvent; estimated is set when the percentage is
+// guessed from the number of tool calls rather than the todo list
+export const progressEventSchema = z.object({
+	id: z.string().uuid(),
+	percent: z.number().min(0).max(100),
+	completed: z.number(),
+	total: z.number(),
+	estimated: z.boolean(),
+	timestamp: z.coerce.date(),
+	sessionKey: z.string().optional(),
+});
+
+export type ProgressEvent = z.infer<typeof progressEventSchema>;
@@ -17,12 +17,57 @@ export namespace git {
 
 export namespace models {
 	
+	export class AheadBehind {
+	    ahead: number;
+	    behind: number;
+	    mergeBase: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AheadBehind(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ahead = source["ahead"];
+	        this.behind = source["behind"];
+	        this.mergeBase = source["mergeBase"];
+	    }
+	}
 	export class AppSettings {
 	    ID: number;
 	    Version: number;
 	    Theme: string;
 	    Locale: string;
 	    DefaultModelKey: string;
+	    TempBaseDir: string;
+	    RetainFailedWorkspaces: boolean;
+	    EventCoalesceWindowMs: number;
+	    VerboseEvents: boolean;
+	    DebugLogging: boolean;
+	    RecordSessionEvents: boolean;
+	    PublishedDocsSearch: boolean;
+	    GenerateWithoutChanges: boolean;
+	    DetachedDocCommits: boolean;
+	    FinalSummaryTurn: boolean;
+	    StructuredSummary: boolean;
+	    HistoryCompactMessages: number;
+	    HistoryCompactChars: number;
+	    HistoryKeepTurns: number;
+	    HistoryCompactModelKey: string;
+	    HistoryLoadMessages: number;
+	    HistoryLoadChars: number;
+	    CloneRetries: number;
+	    CloneTimeoutSeconds: number;
+	    MaxDocFileKB: number;
+	    MaxConcurrentRuns: number;
+	    MaxRunsPerProvider: number;
+	    RejectExcessRuns: boolean;
+	    CommitHistoryLimit: number;
+	    ExistingDocsContextChars: number;
+	    UpdateSubmodulePointer: boolean;
+	    PreserveManualEdits: boolean;
+	    GeminiSafetyThreshold: string;
+	    GeminiSystemInstruction: string;
 	    UpdatedAt: string;
 	
 	    static createFrom(source: any = {}) {
@@ -36,6 +81,35 @@ export namespace models {
 	        this.Theme = source["Theme"];
 	        this.Locale = source["Locale"];
 	        this.DefaultModelKey = source["DefaultModelKey"];
+	        this.TempBaseDir = source["TempBaseDir"];
+	        this.RetainFailedWorkspaces = source["RetainFailedWorkspaces"];
+	        this.EventCoalesceWindowMs = source["EventCoalesceWindowMs"];
+	        this.VerboseEvents = source["VerboseEvents"];
+	        this.DebugLogging = source["DebugLogging"];
+	        this.RecordSessionEvents = source["RecordSessionEvents"];
+	        this.PublishedDocsSearch = source["PublishedDocsSearch"];
+	        this.GenerateWithoutChanges = source["GenerateWithoutChanges"];
+	        this.DetachedDocCommits = source["DetachedDocCommits"];
+	        this.FinalSummaryTurn = source["FinalSummaryTurn"];
+	        this.StructuredSummary = source["StructuredSummary"];
+	        this.HistoryCompactMessages = source["HistoryCompactMessages"];
+	        this.HistoryCompactChars = source["HistoryCompactChars"];
+	        this.HistoryKeepTurns = source["HistoryKeepTurns"];
+	        this.HistoryCompactModelKey = source["HistoryCompactModelKey"];
+	        this.HistoryLoadMessages = source["HistoryLoadMessages"];
+	        this.HistoryLoadChars = source["HistoryLoadChars"];
+	        this.CloneRetries = source["CloneRetries"];
+	        this.CloneTimeoutSeconds = source["CloneTimeoutSeconds"];
+	        this.MaxDocFileKB = source["MaxDocFileKB"];
+	        this.MaxConcurrentRuns = source["MaxConcurrentRuns"];
+	        this.MaxRunsPerProvider = source["MaxRunsPerProvider"];
+	        this.RejectExcessRuns = source["RejectExcessRuns"];
+	        this.CommitHistoryLimit = source["CommitHistoryLimit"];
+	        this.ExistingDocsContextChars = source["ExistingDocsContextChars"];
+	        this.UpdateSubmodulePointer = source["UpdateSubmodulePointer"];
+	        this.PreserveManualEdits = source["PreserveManualEdits"];
+	        this.GeminiSafetyThreshold = source["GeminiSafetyThreshold"];
+	        this.GeminiSystemInstruction = source["GeminiSystemInstruction"];
 	        this.UpdatedAt = source["UpdatedAt"];
 	    }
 	}
@@ -88,6 +162,101 @@ export namespace models {
 	        this.createdAt = source["createdAt"];
 	    }
 	}
+	export class CommitInfo {
+	    hash: string;
+	    shortHash: string;
+	    message: string;
+	    author: string;
+	    email: string;
+	    // Go type: time
+	    date: any;
+	    filesChanged: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.shortHash = source["shortHash"];
+	        this.message = source["message"];
+	        this.author = source["author"];
+	        this.email = source["email"];
+	        this.date = this.convertValues(source["date"], null);
+	        this.filesChanged = source["filesChanged"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ContentRootCandidate {
+	    path: string;
+	    confidence: number;
+	    reasons: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ContentRootCandidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.confidence = source["confidence"];
+	        this.reasons = source["reasons"];
+	    }
+	}
+	export class ContentRootDetection {
+	    best: string;
+	    confidence: number;
+	    ambiguous: boolean;
+	    candidates: ContentRootCandidate[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ContentRootDetection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.best = source["best"];
+	        this.confidence = source["confidence"];
+	        this.ambiguous = source["ambiguous"];
+	        this.candidates = this.convertValues(source["candidates"], ContentRootCandidate);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class DocChangedFile {
 	    path: string;
 	    status: string;
@@ -102,6 +271,20 @@ export namespace models {
 	        this.status = source["status"];
 	    }
 	}
+	export class DocChangeRationale {
+	    path: string;
+	    rationale: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocChangeRationale(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.rationale = source["rationale"];
+	    }
+	}
 	export class DocGenerationResult {
 	    sessionId: number;
 	    sessionKey: string;
@@ -111,8 +294,21 @@ export namespace models {
 	    docsInCodeRepo: boolean;
 	    files: DocChangedFile[];
 	    diff: string;
+	    pendingDiff?: string;
+	    diffStat: FileDiffStat[];
 	    summary: string;
+	    summaryFiles?: DocChangeRationale[];
+	    followUps?: string[];
 	    chatMessages?: ChatMessage[];
+	    paused?: boolean;
+	    fullBootstrap?: boolean;
+	    fromWorktree?: boolean;
+	    filesystemTarget?: boolean;
+	    frontmatterWarnings?: Record<string, Array<string>>;
+	    componentWarnings?: MDXComponentWarning[];
+	    routeTree?: RouteNode;
+	    preservedEdits?: string[];
+	    mergeConflicts?: DocMergeConflict[];
 	
 	    static createFrom(source: any = {}) {
 	        return new DocGenerationResult(source);
@@ -128,8 +324,21 @@ export namespace models {
 	        this.docsInCodeRepo = source["docsInCodeRepo"];
 	        this.files = this.convertValues(source["files"], DocChangedFile);
 	        this.diff = source["diff"];
+	        this.pendingDiff = source["pendingDiff"];
+	        this.diffStat = this.convertValues(source["diffStat"], FileDiffStat);
 	        this.summary = source["summary"];
+	        this.summaryFiles = this.convertValues(source["summaryFiles"], DocChangeRationale);
+	        this.followUps = source["followUps"];
 	        this.chatMessages = this.convertValues(source["chatMessages"], ChatMessage);
+	        this.paused = source["paused"];
+	        this.fullBootstrap = source["fullBootstrap"];
+	        this.fromWorktree = source["fromWorktree"];
+	        this.filesystemTarget = source["filesystemTarget"];
+	        this.frontmatterWarnings = source["frontmatterWarnings"];
+	        this.componentWarnings = this.convertValues(source["componentWarnings"], MDXComponentWarning);
+	        this.routeTree = this.convertValues(source["routeTree"], RouteNode);
+	        this.preservedEdits = source["preservedEdits"];
+	        this.mergeConflicts = this.convertValues(source["mergeConflicts"], DocMergeConflict);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -150,6 +359,201 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class DocMergeConflict {
+	    path: string;
+	    regions?: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocMergeConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.regions = source["regions"];
+	        this.message = source["message"];
+	    }
+	}
+	export class DocPlannedChange {
+	    path: string;
+	    operation: string;
+	    rationale: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocPlannedChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.operation = source["operation"];
+	        this.rationale = source["rationale"];
+	    }
+	}
+	export class DocPlanResult {
+	    sessionKey: string;
+	    plan: boolean;
+	    branch: string;
+	    targetBranch: string;
+	    changes: DocPlannedChange[];
+	    summary: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocPlanResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sessionKey = source["sessionKey"];
+	        this.plan = source["plan"];
+	        this.branch = source["branch"];
+	        this.targetBranch = source["targetBranch"];
+	        this.changes = this.convertValues(source["changes"], DocPlannedChange);
+	        this.summary = source["summary"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DocsBranchInfo {
+	    name: string;
+	    // Go type: time
+	    lastCommitDate: any;
+	    sessionId: number;
+	    base: string;
+	    ahead: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocsBranchInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.lastCommitDate = this.convertValues(source["lastCommitDate"], null);
+	        this.sessionId = source["sessionId"];
+	        this.base = source["base"];
+	        this.ahead = source["ahead"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DocsExport {
+	    format: string;
+	    paths: Array<string>;
+	    files?: Record<string, string>;
+	    binary?: Array<string>;
+	    deleted?: Array<string>;
+	    archivePath?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocsExport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.paths = source["paths"];
+	        this.files = source["files"];
+	        this.binary = source["binary"];
+	        this.deleted = source["deleted"];
+	        this.archivePath = source["archivePath"];
+	    }
+	}
+	export class FileAtRef {
+	    path: string;
+	    hash: string;
+	    size: number;
+	    binary: boolean;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileAtRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.hash = source["hash"];
+	        this.size = source["size"];
+	        this.binary = source["binary"];
+	        this.content = source["content"];
+	    }
+	}
+	export class FileDiffStat {
+	    path: string;
+	    additions: number;
+	    deletions: number;
+	    status: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileDiffStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.additions = source["additions"];
+	        this.deletions = source["deletions"];
+	        this.status = source["status"];
+	    }
+	}
+	export class GenerationProfile {
+	    id: number;
+	    projectId: number;
+	    name: string;
+	    modelKey: string;
+	    templateId: number;
+	    extraInstructions: string;
+	    excludedPatterns: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GenerationProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.projectId = source["projectId"];
+	        this.name = source["name"];
+	        this.modelKey = source["modelKey"];
+	        this.templateId = source["templateId"];
+	        this.extraInstructions = source["extraInstructions"];
+	        this.excludedPatterns = source["excludedPatterns"];
+	    }
+	}
 	export class GenerationSession {
 	    ID: number;
 	    ProjectID: number;
@@ -160,6 +564,14 @@ export namespace models {
 	    DocsBranch: string;
 	    MessagesJSON: string;
 	    ChatMessagesJSON: string;
+	    TodosJSON: string;
+	    BootstrapProgressJSON: string;
+	    EventLogJSON: string;
+	    Paused: boolean;
+	    FullBootstrap: boolean;
+	    FromWorktree: boolean;
+	    LastSummary: string;
+	    IdempotencyKey?: string;
 	    // Go type: time
 	    CreatedAt: any;
 	    // Go type: time
@@ -180,6 +592,14 @@ export namespace models {
 	        this.DocsBranch = source["DocsBranch"];
 	        this.MessagesJSON = source["MessagesJSON"];
 	        this.ChatMessagesJSON = source["ChatMessagesJSON"];
+	        this.TodosJSON = source["TodosJSON"];
+	        this.BootstrapProgressJSON = source["BootstrapProgressJSON"];
+	        this.EventLogJSON = source["EventLogJSON"];
+	        this.Paused = source["Paused"];
+	        this.FullBootstrap = source["FullBootstrap"];
+	        this.FromWorktree = source["FromWorktree"];
+	        this.LastSummary = source["LastSummary"];
+	        this.IdempotencyKey = source["IdempotencyKey"];
 	        this.CreatedAt = this.convertValues(source["CreatedAt"], null);
 	        this.UpdatedAt = this.convertValues(source["UpdatedAt"], null);
 	    }
@@ -211,6 +631,13 @@ export namespace models {
 	    reasoningEffort?: string;
 	    thinking?: boolean;
 	    enabled: boolean;
+	    priority: number;
+	    providerKind?: string;
+	    baseUrl?: string;
+	    showReasoning: boolean;
+	    reasoningTokenBudget: number;
+	    reasoningBudgetAction: string;
+	    capabilities: ModelCapabilities;
 	
 	    static createFrom(source: any = {}) {
 	        return new LLMModel(source);
@@ -226,7 +653,32 @@ export namespace models {
 	        this.reasoningEffort = source["reasoningEffort"];
 	        this.thinking = source["thinking"];
 	        this.enabled = source["enabled"];
+	        this.priority = source["priority"];
+	        this.providerKind = source["providerKind"];
+	        this.baseUrl = source["baseUrl"];
+	        this.showReasoning = source["showReasoning"];
+	        this.reasoningTokenBudget = source["reasoningTokenBudget"];
+	        this.reasoningBudgetAction = source["reasoningBudgetAction"];
+	        this.capabilities = this.convertValues(source["capabilities"], ModelCapabilities);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class LLMModelGroup {
 	    providerId: string;
@@ -262,6 +714,96 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class MDXComponentWarning {
+	    path: string;
+	    line: number;
+	    component: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MDXComponentWarning(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.line = source["line"];
+	        this.component = source["component"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ModelCapabilities {
+	    thinking: boolean;
+	    reasoningEffort: boolean;
+	    structuredOutput: boolean;
+	    vision: boolean;
+	    maxContextTokens: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelCapabilities(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.thinking = source["thinking"];
+	        this.reasoningEffort = source["reasoningEffort"];
+	        this.structuredOutput = source["structuredOutput"];
+	        this.vision = source["vision"];
+	        this.maxContextTokens = source["maxContextTokens"];
+	    }
+	}
+	export class NarrabyteConfigInit {
+	    path: string;
+	    existed: boolean;
+	    created: boolean;
+	    readOnly: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NarrabyteConfigInit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.existed = source["existed"];
+	        this.created = source["created"];
+	        this.readOnly = source["readOnly"];
+	    }
+	}
+	export class RemoteInfo {
+	    name: string;
+	    urls: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.urls = source["urls"];
+	    }
+	}
+	export class RemoteStatus {
+	    remote: string;
+	    url: string;
+	    reachable: boolean;
+	    authFailed: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.remote = source["remote"];
+	        this.url = source["url"];
+	        this.reachable = source["reachable"];
+	        this.authFailed = source["authFailed"];
+	        this.error = source["error"];
+	    }
+	}
 	export class RepoLink {
 	    ID: number;
 	    DocumentationRepo: string;
@@ -269,6 +811,16 @@ export namespace models {
 	    ProjectName: string;
 	    DocumentationBaseBranch: string;
 	    index: number;
+	    MaintainFumadocsMeta: boolean;
+	    AllowedMDXComponents: string;
+	    CodeScopePath: string;
+	    FilesystemTarget: boolean;
+	    DefaultModelKey: string;
+	    AllowedTools: string;
+	    PromptOmitCodeListing: boolean;
+	    PromptOmitDocsListing: boolean;
+	    PromptOmitCommitContext: boolean;
+	    PromptMaxListingChars: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new RepoLink(source);
@@ -282,6 +834,16 @@ export namespace models {
 	        this.ProjectName = source["ProjectName"];
 	        this.DocumentationBaseBranch = source["DocumentationBaseBranch"];
 	        this.index = source["index"];
+	        this.MaintainFumadocsMeta = source["MaintainFumadocsMeta"];
+	        this.AllowedMDXComponents = source["AllowedMDXComponents"];
+	        this.CodeScopePath = source["CodeScopePath"];
+	        this.FilesystemTarget = source["FilesystemTarget"];
+	        this.DefaultModelKey = source["DefaultModelKey"];
+	        this.AllowedTools = source["AllowedTools"];
+	        this.PromptOmitCodeListing = source["PromptOmitCodeListing"];
+	        this.PromptOmitDocsListing = source["PromptOmitDocsListing"];
+	        this.PromptOmitCommitContext = source["PromptOmitCommitContext"];
+	        this.PromptMaxListingChars = source["PromptMaxListingChars"];
 	    }
 	}
 	export class RepoLinkOrderUpdate {
@@ -298,6 +860,115 @@ export namespace models {
 	        this.Index = source["Index"];
 	    }
 	}
+	export class RouteNode {
+	    type: string;
+	    title: string;
+	    url?: string;
+	    path?: string;
+	    children?: RouteNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RouteNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	        this.path = source["path"];
+	        this.children = this.convertValues(source["children"], RouteNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SessionEventLogEntry {
+	    // Go type: time
+	    timestamp: any;
+	    type: string;
+	    kind: string;
+	    toolName?: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionEventLogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.type = source["type"];
+	        this.kind = source["kind"];
+	        this.toolName = source["toolName"];
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StatusSummary {
+	    clean: boolean;
+	    files: DocChangedFile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StatusSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.clean = source["clean"];
+	        this.files = this.convertValues(source["files"], DocChangedFile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Template {
 	    id: number;
 	    name: string;
@@ -319,6 +990,38 @@ export namespace models {
 
 export namespace services {
 	
+	export class ApiKeyFingerprintResult {
+	    provider: string;
+	    fingerprint: string;
+	    length: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ApiKeyFingerprintResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.provider = source["provider"];
+	        this.fingerprint = source["fingerprint"];
+	        this.length = source["length"];
+	    }
+	}
+	export class ApiKeyValidationResult {
+	    stored: boolean;
+	    warningCode?: string;
+	    warning?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ApiKeyValidationResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stored = source["stored"];
+	        this.warningCode = source["warningCode"];
+	        this.warning = source["warning"];
+	    }
+	}
 	export class DirectoryValidationResult {
 	    isValid: boolean;
 	    errorCode: string;
@@ -333,6 +1036,20 @@ export namespace services {
 	        this.errorCode = source["errorCode"];
 	    }
 	}
+	export class ResolvedRef {
+	    hash: number[];
+	    label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResolvedRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.label = source["label"];
+	    }
+	}
 	export class SessionInfo {
 	    id: number;
 	    sessionKey: string;
@@ -344,6 +1061,9 @@ export namespace services {
 	    docsBranch: string;
 	    inTab: boolean;
 	    isRunning: boolean;
+	    fullBootstrap: boolean;
+	    fromWorktree: boolean;
+	    status: string;
 	    createdAt: string;
 	    updatedAt: string;
 	
@@ -363,6 +1083,9 @@ export namespace services {
 	        this.docsBranch = source["docsBranch"];
 	        this.inTab = source["inTab"];
 	        this.isRunning = source["isRunning"];
+	        this.fullBootstrap = source["fullBootstrap"];
+	        this.fromWorktree = source["fromWorktree"];
+	        this.status = source["status"];
 	        this.createdAt = source["createdAt"];
 	        this.updatedAt = source["updatedAt"];
 	    }
@@ -4,30 +4,82 @@ import {models} from '../models';
 import {services} from '../models';
 import {context} from '../models';
 
+export function ArchiveDocsBranch(arg1:number):Promise<string>;
+
 export function BindSessionToTab(arg1:number):Promise<void>;
 
 export function CheckDocsBranchAvailability(arg1:number,arg2:string,arg3:string):Promise<void>;
 
+export function CherryPickDocsCommit(arg1:number,arg2:string):Promise<string>;
+
+export function CleanupRetainedWorkspaces(arg1:number):Promise<number>;
+
 export function CommitDocs(arg1:number,arg2:number,arg3:Array<string>):Promise<void>;
 
-export function GenerateDocs(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string):Promise<models.DocGenerationResult>;
+export function CreateDocumentationBaseBranch(arg1:number,arg2:string):Promise<void>;
+
+export function DiscardStagedDocs(arg1:number):Promise<void>;
+
+export function DocFileAtBase(arg1:number,arg2:string):Promise<models.FileAtRef>;
+
+export function DocsBranchAheadBehind(arg1:number):Promise<models.AheadBehind>;
+
+export function DocsBranchLog(arg1:number,arg2:number,arg3:number):Promise<Array<models.CommitInfo>>;
+
+export function ExportDocsChanges(arg1:number,arg2:string,arg3:Array<string>,arg4:string):Promise<models.DocsExport>;
+
+export function GenerateDocs(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string):Promise<models.DocGenerationResult>;
+
+export function GenerateDocsFromBranch(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string):Promise<models.DocGenerationResult>;
+
+export function GenerateDocsFromWorktree(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<models.DocGenerationResult>;
+
+export function GenerateDocsFull(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string):Promise<models.DocGenerationResult>;
+
+export function GenerateDocsWithProfile(arg1:number,arg2:number,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string):Promise<models.DocGenerationResult>;
 
-export function GenerateDocsFromBranch(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<models.DocGenerationResult>;
+export function GenerateDocsWithTemplate(arg1:number,arg2:string,arg3:string,arg4:string,arg5:number,arg6:string,arg7:string,arg8:string,arg9:string):Promise<models.DocGenerationResult>;
 
 export function GetAvailableTabSessions(arg1:number):Promise<Array<services.SessionInfo>>;
 
+export function InitNarrabyteConfig(arg1:number):Promise<models.NarrabyteConfigInit>;
+
 export function IsSessionInTab(arg1:number):Promise<boolean>;
 
+export function ListDocsBranches(arg1:number):Promise<Array<models.DocsBranchInfo>>;
+
 export function LoadGenerationSession(arg1:number):Promise<models.DocGenerationResult>;
 
 export function MergeDocsIntoSource(arg1:number):Promise<void>;
 
+export function PauseSession(arg1:number,arg2:string):Promise<void>;
+
+export function PlanDocs(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<models.DocPlanResult>;
+
+export function PruneRemoteBranches(arg1:number,arg2:string):Promise<Array<string>>;
+
 export function RefineDocs(arg1:number,arg2:string,arg3:string):Promise<models.DocGenerationResult>;
 
+export function RefineDocsFiles(arg1:number,arg2:Array<string>,arg3:string):Promise<models.DocGenerationResult>;
+
+export function RenameDocsBranch(arg1:number,arg2:string):Promise<void>;
+
+export function RestoreDocsBranch(arg1:number):Promise<void>;
+
+export function ResumeCanceledRun(arg1:number,arg2:string):Promise<models.DocGenerationResult>;
+
+export function ResumeSession(arg1:number,arg2:string):Promise<models.DocGenerationResult>;
+
+export function RevertDocsCommit(arg1:number,arg2:string):Promise<string>;
+
 export function Startup(arg1:context.Context):Promise<void>;
 
 export function StopStream(arg1:number,arg2:string):Promise<void>;
 
+export function SwitchSessionModel(arg1:number,arg2:string,arg3:string):Promise<void>;
+
 export function UnbindSessionFromTab(arg1:number):Promise<void>;
 
 export function ValidateDocsBranch(arg1:number,arg2:string):Promise<void>;
+
+export function ValidateModel(arg1:string):Promise<void>;
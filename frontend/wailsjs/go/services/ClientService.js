@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ArchiveDocsBranch(arg1) {
+  return window['go']['services']['ClientService']['ArchiveDocsBranch'](arg1);
+}
+
 export function BindSessionToTab(arg1) {
   return window['go']['services']['ClientService']['BindSessionToTab'](arg1);
 }
@@ -10,26 +14,82 @@ export function CheckDocsBranchAvailability(arg1, arg2, arg3) {
   return window['go']['services']['ClientService']['CheckDocsBranchAvailability'](arg1, arg2, arg3);
 }
 
+export function CherryPickDocsCommit(arg1, arg2) {
+  return window['go']['services']['ClientService']['CherryPickDocsCommit'](arg1, arg2);
+}
+
+export function CleanupRetainedWorkspaces(arg1) {
+  return window['go']['services']['ClientService']['CleanupRetainedWorkspaces'](arg1);
+}
+
 export function CommitDocs(arg1, arg2, arg3) {
   return window['go']['services']['ClientService']['CommitDocs'](arg1, arg2, arg3);
 }
 
-export function GenerateDocs(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
-  return window['go']['services']['ClientService']['GenerateDocs'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+export function CreateDocumentationBaseBranch(arg1, arg2) {
+  return window['go']['services']['ClientService']['CreateDocumentationBaseBranch'](arg1, arg2);
+}
+
+export function DiscardStagedDocs(arg1) {
+  return window['go']['services']['ClientService']['DiscardStagedDocs'](arg1);
+}
+
+export function DocFileAtBase(arg1, arg2) {
+  return window['go']['services']['ClientService']['DocFileAtBase'](arg1, arg2);
+}
+
+export function DocsBranchAheadBehind(arg1) {
+  return window['go']['services']['ClientService']['DocsBranchAheadBehind'](arg1);
+}
+
+export function DocsBranchLog(arg1, arg2, arg3) {
+  return window['go']['services']['ClientService']['DocsBranchLog'](arg1, arg2, arg3);
+}
+
+export function ExportDocsChanges(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['ClientService']['ExportDocsChanges'](arg1, arg2, arg3, arg4);
+}
+
+export function GenerateDocs(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['services']['ClientService']['GenerateDocs'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function GenerateDocsFromBranch(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['services']['ClientService']['GenerateDocsFromBranch'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function GenerateDocsFromWorktree(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['services']['ClientService']['GenerateDocsFromWorktree'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function GenerateDocsFull(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['services']['ClientService']['GenerateDocsFull'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function GenerateDocsWithProfile(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['services']['ClientService']['GenerateDocsWithProfile'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
 }
 
-export function GenerateDocsFromBranch(arg1, arg2, arg3, arg4, arg5, arg6) {
-  return window['go']['services']['ClientService']['GenerateDocsFromBranch'](arg1, arg2, arg3, arg4, arg5, arg6);
+export function GenerateDocsWithTemplate(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['services']['ClientService']['GenerateDocsWithTemplate'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
 }
 
 export function GetAvailableTabSessions(arg1) {
   return window['go']['services']['ClientService']['GetAvailableTabSessions'](arg1);
 }
 
+export function InitNarrabyteConfig(arg1) {
+  return window['go']['services']['ClientService']['InitNarrabyteConfig'](arg1);
+}
+
 export function IsSessionInTab(arg1) {
   return window['go']['services']['ClientService']['IsSessionInTab'](arg1);
 }
 
+export function ListDocsBranches(arg1) {
+  return window['go']['services']['ClientService']['ListDocsBranches'](arg1);
+}
+
 export function LoadGenerationSession(arg1) {
   return window['go']['services']['ClientService']['LoadGenerationSession'](arg1);
 }
@@ -38,10 +98,46 @@ export function MergeDocsIntoSource(arg1) {
   return window['go']['services']['ClientService']['MergeDocsIntoSource'](arg1);
 }
 
+export function PauseSession(arg1, arg2) {
+  return window['go']['services']['ClientService']['PauseSession'](arg1, arg2);
+}
+
+export function PlanDocs(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['services']['ClientService']['PlanDocs'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function PruneRemoteBranches(arg1, arg2) {
+  return window['go']['services']['ClientService']['PruneRemoteBranches'](arg1, arg2);
+}
+
 export function RefineDocs(arg1, arg2, arg3) {
   return window['go']['services']['ClientService']['RefineDocs'](arg1, arg2, arg3);
 }
 
+export function RefineDocsFiles(arg1, arg2, arg3) {
+  return window['go']['services']['ClientService']['RefineDocsFiles'](arg1, arg2, arg3);
+}
+
+export function RenameDocsBranch(arg1, arg2) {
+  return window['go']['services']['ClientService']['RenameDocsBranch'](arg1, arg2);
+}
+
+export function RestoreDocsBranch(arg1) {
+  return window['go']['services']['ClientService']['RestoreDocsBranch'](arg1);
+}
+
+export function ResumeCanceledRun(arg1, arg2) {
+  return window['go']['services']['ClientService']['ResumeCanceledRun'](arg1, arg2);
+}
+
+export function ResumeSession(arg1, arg2) {
+  return window['go']['services']['ClientService']['ResumeSession'](arg1, arg2);
+}
+
+export function RevertDocsCommit(arg1, arg2) {
+  return window['go']['services']['ClientService']['RevertDocsCommit'](arg1, arg2);
+}
+
 export function Startup(arg1) {
   return window['go']['services']['ClientService']['Startup'](arg1);
 }
@@ -50,6 +146,10 @@ export function StopStream(arg1, arg2) {
   return window['go']['services']['ClientService']['StopStream'](arg1, arg2);
 }
 
+export function SwitchSessionModel(arg1, arg2, arg3) {
+  return window['go']['services']['ClientService']['SwitchSessionModel'](arg1, arg2, arg3);
+}
+
 export function UnbindSessionFromTab(arg1) {
   return window['go']['services']['ClientService']['UnbindSessionFromTab'](arg1);
 }
@@ -57,3 +157,7 @@ export function UnbindSessionFromTab(arg1) {
 export function ValidateDocsBranch(arg1, arg2) {
   return window['go']['services']['ClientService']['ValidateDocsBranch'](arg1, arg2);
 }
+
+export function ValidateModel(arg1) {
+  return window['go']['services']['ClientService']['ValidateModel'](arg1);
+}
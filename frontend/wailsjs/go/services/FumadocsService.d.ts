@@ -1,9 +1,20 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {models} from '../models';
 import {context} from '../models';
 
+export function BuildRouteTree(arg1:string):Promise<models.RouteNode>;
+
 export function CheckGitAvailability():Promise<void>;
 
 export function CreateFumadocsProject(arg1:string):Promise<string>;
 
+export function DetectContentRoot(arg1:string):Promise<models.ContentRootDetection>;
+
+export function LintMDXComponents(arg1:string,arg2:Array<models.DocChangedFile>,arg3:Array<string>):Promise<Array<models.MDXComponentWarning>>;
+
+export function ReconcileMetaFiles(arg1:string,arg2:Array<models.DocChangedFile>):Promise<Array<models.DocChangedFile>>;
+
 export function Startup(arg1:context.Context):Promise<void>;
+
+export function ValidateFrontmatter(arg1:string,arg2:Array<models.DocChangedFile>):Promise<Record<string, Array<string>>>;
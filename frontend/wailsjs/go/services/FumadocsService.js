@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function BuildRouteTree(arg1) {
+  return window['go']['services']['FumadocsService']['BuildRouteTree'](arg1);
+}
+
 export function CheckGitAvailability() {
   return window['go']['services']['FumadocsService']['CheckGitAvailability']();
 }
@@ -10,6 +14,22 @@ export function CreateFumadocsProject(arg1) {
   return window['go']['services']['FumadocsService']['CreateFumadocsProject'](arg1);
 }
 
+export function DetectContentRoot(arg1) {
+  return window['go']['services']['FumadocsService']['DetectContentRoot'](arg1);
+}
+
+export function LintMDXComponents(arg1, arg2, arg3) {
+  return window['go']['services']['FumadocsService']['LintMDXComponents'](arg1, arg2, arg3);
+}
+
+export function ReconcileMetaFiles(arg1, arg2) {
+  return window['go']['services']['FumadocsService']['ReconcileMetaFiles'](arg1, arg2);
+}
+
 export function Startup(arg1) {
   return window['go']['services']['FumadocsService']['Startup'](arg1);
 }
+
+export function ValidateFrontmatter(arg1, arg2) {
+  return window['go']['services']['FumadocsService']['ValidateFrontmatter'](arg1, arg2);
+}
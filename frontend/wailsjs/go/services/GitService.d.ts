@@ -4,15 +4,30 @@ import {git} from '../models';
 import {plumbing} from '../models';
 import {models} from '../models';
 import {context} from '../models';
+import {services} from '../models';
+
+export function AheadBehind(arg1:git.Repository,arg2:string,arg3:string):Promise<models.AheadBehind>;
+
+export function ArchiveBranch(arg1:git.Repository,arg2:string):Promise<string>;
 
 export function BranchExists(arg1:git.Repository,arg2:string):Promise<boolean>;
 
 export function Checkout(arg1:git.Repository,arg2:string):Promise<void>;
 
+export function CherryPick(arg1:git.Repository,arg2:string,arg3:string):Promise<plumbing.Hash>;
+
+export function CleanUntracked(arg1:git.Repository,arg2:string,arg3:boolean,arg4:boolean):Promise<Array<string>>;
+
 export function Clone(arg1:string,arg2:string):Promise<git.Repository>;
 
 export function Commit(arg1:git.Repository,arg2:string):Promise<plumbing.Hash>;
 
+export function CommitLog(arg1:git.Repository,arg2:string,arg3:string,arg4:number):Promise<Array<models.CommitInfo>>;
+
+export function CompareWorktreeToBranch(arg1:git.Repository,arg2:string,arg3:string):Promise<string>;
+
+export function CreateBranch(arg1:git.Repository,arg2:string,arg3:plumbing.Hash,arg4:boolean):Promise<void>;
+
 export function DeleteBranch(arg1:git.Repository,arg2:string):Promise<void>;
 
 export function DeleteBranchByPath(arg1:string,arg2:string):Promise<void>;
@@ -21,10 +36,26 @@ export function DiffBetweenBranches(arg1:git.Repository,arg2:string,arg3:string)
 
 export function DiffBetweenCommits(arg1:git.Repository,arg2:string,arg3:string):Promise<string>;
 
+export function DiffFile(arg1:git.Repository,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function FetchPrune(arg1:git.Repository,arg2:string):Promise<Array<string>>;
+
+export function FileAtRef(arg1:git.Repository,arg2:string,arg3:string):Promise<models.FileAtRef>;
+
 export function GetCurrentBranch(arg1:string):Promise<string>;
 
+export function GetRemotes(arg1:git.Repository):Promise<Array<models.RemoteInfo>>;
+
+export function GetRemotesByPath(arg1:string):Promise<Array<models.RemoteInfo>>;
+
 export function HasUncommittedChanges(arg1:string):Promise<boolean>;
 
+export function HasUnpushedCommits(arg1:git.Repository,arg2:string,arg3:string):Promise<boolean>;
+
+export function HasUnpushedCommitsByPath(arg1:string,arg2:string,arg3:string):Promise<boolean>;
+
+export function IsBranchMerged(arg1:git.Repository,arg2:string,arg3:string):Promise<boolean>;
+
 export function Init(arg1:string):Promise<git.Repository>;
 
 export function LatestCommit(arg1:string):Promise<string>;
@@ -33,16 +64,46 @@ export function ListBranches(arg1:git.Repository):Promise<Array<models.BranchInf
 
 export function ListBranchesByPath(arg1:string):Promise<Array<models.BranchInfo>>;
 
+export function Log(arg1:git.Repository,arg2:string,arg3:number,arg4:number):Promise<Array<models.CommitInfo>>;
+
+export function MergeBase(arg1:git.Repository,arg2:string,arg3:string):Promise<string>;
+
 export function Open(arg1:string):Promise<git.Repository>;
 
 export function Pull(arg1:git.Repository):Promise<void>;
 
 export function Push(arg1:git.Repository):Promise<void>;
 
+export function PushBranch(arg1:git.Repository,arg2:string):Promise<void>;
+
+export function RenameBranch(arg1:git.Repository,arg2:string,arg3:string):Promise<void>;
+
+export function ResetIndex(arg1:git.Repository):Promise<void>;
+
+export function ResolveRef(arg1:git.Repository,arg2:string):Promise<services.ResolvedRef>;
+
+export function RestoreArchivedBranch(arg1:git.Repository,arg2:string):Promise<string>;
+
+export function Revert(arg1:git.Repository,arg2:string,arg3:string):Promise<plumbing.Hash>;
+
+export function SetUpstream(arg1:git.Repository,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function ShowCommit(arg1:git.Repository,arg2:string):Promise<models.CommitInfo>;
+
 export function StageAll(arg1:git.Repository):Promise<void>;
 
 export function StageFiles(arg1:git.Repository,arg2:Array<string>):Promise<void>;
 
 export function Startup(arg1:context.Context):Promise<void>;
 
+export function StatusSummary(arg1:git.Repository,arg2:string):Promise<models.StatusSummary>;
+
+export function Unstage(arg1:git.Repository,arg2:Array<string>):Promise<void>;
+
+export function UpdateSubmodulePointer(arg1:git.Repository,arg2:string,arg3:string):Promise<plumbing.Hash>;
+
+export function ValidateRemote(arg1:git.Repository,arg2:string,arg3:any):Promise<models.RemoteStatus>;
+
+export function ValidateRemoteByPath(arg1:string,arg2:string):Promise<models.RemoteStatus>;
+
 export function ValidateRepository(arg1:string):Promise<void>;
@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AheadBehind(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['AheadBehind'](arg1, arg2, arg3);
+}
+
+export function ArchiveBranch(arg1, arg2) {
+  return window['go']['services']['GitService']['ArchiveBranch'](arg1, arg2);
+}
+
 export function BranchExists(arg1, arg2) {
   return window['go']['services']['GitService']['BranchExists'](arg1, arg2);
 }
@@ -10,6 +18,14 @@ export function Checkout(arg1, arg2) {
   return window['go']['services']['GitService']['Checkout'](arg1, arg2);
 }
 
+export function CherryPick(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['CherryPick'](arg1, arg2, arg3);
+}
+
+export function CleanUntracked(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['GitService']['CleanUntracked'](arg1, arg2, arg3, arg4);
+}
+
 export function Clone(arg1, arg2) {
   return window['go']['services']['GitService']['Clone'](arg1, arg2);
 }
@@ -18,6 +34,18 @@ export function Commit(arg1, arg2) {
   return window['go']['services']['GitService']['Commit'](arg1, arg2);
 }
 
+export function CommitLog(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['GitService']['CommitLog'](arg1, arg2, arg3, arg4);
+}
+
+export function CompareWorktreeToBranch(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['CompareWorktreeToBranch'](arg1, arg2, arg3);
+}
+
+export function CreateBranch(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['GitService']['CreateBranch'](arg1, arg2, arg3, arg4);
+}
+
 export function DeleteBranch(arg1, arg2) {
   return window['go']['services']['GitService']['DeleteBranch'](arg1, arg2);
 }
@@ -34,14 +62,46 @@ export function DiffBetweenCommits(arg1, arg2, arg3) {
   return window['go']['services']['GitService']['DiffBetweenCommits'](arg1, arg2, arg3);
 }
 
+export function DiffFile(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['GitService']['DiffFile'](arg1, arg2, arg3, arg4);
+}
+
+export function FetchPrune(arg1, arg2) {
+  return window['go']['services']['GitService']['FetchPrune'](arg1, arg2);
+}
+
+export function FileAtRef(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['FileAtRef'](arg1, arg2, arg3);
+}
+
 export function GetCurrentBranch(arg1) {
   return window['go']['services']['GitService']['GetCurrentBranch'](arg1);
 }
 
+export function GetRemotes(arg1) {
+  return window['go']['services']['GitService']['GetRemotes'](arg1);
+}
+
+export function GetRemotesByPath(arg1) {
+  return window['go']['services']['GitService']['GetRemotesByPath'](arg1);
+}
+
 export function HasUncommittedChanges(arg1) {
   return window['go']['services']['GitService']['HasUncommittedChanges'](arg1);
 }
 
+export function HasUnpushedCommits(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['HasUnpushedCommits'](arg1, arg2, arg3);
+}
+
+export function HasUnpushedCommitsByPath(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['HasUnpushedCommitsByPath'](arg1, arg2, arg3);
+}
+
+export function IsBranchMerged(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['IsBranchMerged'](arg1, arg2, arg3);
+}
+
 export function Init(arg1) {
   return window['go']['services']['GitService']['Init'](arg1);
 }
@@ -58,6 +118,14 @@ export function ListBranchesByPath(arg1) {
   return window['go']['services']['GitService']['ListBranchesByPath'](arg1);
 }
 
+export function Log(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['GitService']['Log'](arg1, arg2, arg3, arg4);
+}
+
+export function MergeBase(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['MergeBase'](arg1, arg2, arg3);
+}
+
 export function Open(arg1) {
   return window['go']['services']['GitService']['Open'](arg1);
 }
@@ -70,6 +138,38 @@ export function Push(arg1) {
   return window['go']['services']['GitService']['Push'](arg1);
 }
 
+export function PushBranch(arg1, arg2) {
+  return window['go']['services']['GitService']['PushBranch'](arg1, arg2);
+}
+
+export function RenameBranch(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['RenameBranch'](arg1, arg2, arg3);
+}
+
+export function ResetIndex(arg1) {
+  return window['go']['services']['GitService']['ResetIndex'](arg1);
+}
+
+export function ResolveRef(arg1, arg2) {
+  return window['go']['services']['GitService']['ResolveRef'](arg1, arg2);
+}
+
+export function RestoreArchivedBranch(arg1, arg2) {
+  return window['go']['services']['GitService']['RestoreArchivedBranch'](arg1, arg2);
+}
+
+export function Revert(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['Revert'](arg1, arg2, arg3);
+}
+
+export function SetUpstream(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['GitService']['SetUpstream'](arg1, arg2, arg3, arg4);
+}
+
+export function ShowCommit(arg1, arg2) {
+  return window['go']['services']['GitService']['ShowCommit'](arg1, arg2);
+}
+
 export function StageAll(arg1) {
   return window['go']['services']['GitService']['StageAll'](arg1);
 }
@@ -82,6 +182,26 @@ export function Startup(arg1) {
   return window['go']['services']['GitService']['Startup'](arg1);
 }
 
+export function StatusSummary(arg1, arg2) {
+  return window['go']['services']['GitService']['StatusSummary'](arg1, arg2);
+}
+
+export function Unstage(arg1, arg2) {
+  return window['go']['services']['GitService']['Unstage'](arg1, arg2);
+}
+
+export function UpdateSubmodulePointer(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['UpdateSubmodulePointer'](arg1, arg2, arg3);
+}
+
+export function ValidateRemote(arg1, arg2, arg3) {
+  return window['go']['services']['GitService']['ValidateRemote'](arg1, arg2, arg3);
+}
+
+export function ValidateRemoteByPath(arg1, arg2) {
+  return window['go']['services']['GitService']['ValidateRemoteByPath'](arg1, arg2);
+}
+
 export function ValidateRepository(arg1) {
   return window['go']['services']['GitService']['ValidateRepository'](arg1);
 }
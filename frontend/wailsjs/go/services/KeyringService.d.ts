@@ -1,10 +1,27 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {services} from '../models';
+
+export function ApiKeyFingerprint(arg1:string):Promise<services.ApiKeyFingerprintResult>;
 
 export function DeleteApiKey(arg1:string):Promise<void>;
 
+export function DeleteProjectApiKey(arg1:string,arg2:number):Promise<void>;
+
 export function GetApiKey(arg1:string):Promise<string>;
 
+export function GetProjectApiKey(arg1:string,arg2:number):Promise<string>;
+
+export function HasApiKey(arg1:string):Promise<boolean>;
+
 export function ListApiKeys():Promise<Array<Record<string, string>>>;
 
+export function ListStoredProviders():Promise<Array<string>>;
+
+export function ResolveApiKey(arg1:string,arg2:number):Promise<string>;
+
+export function SetApiKey(arg1:string,arg2:string,arg3:boolean):Promise<services.ApiKeyValidationResult>;
+
+export function SetProjectApiKey(arg1:string,arg2:number,arg3:Array<number>):Promise<void>;
+
 export function StoreApiKey(arg1:string,arg2:Array<number>):Promise<void>;
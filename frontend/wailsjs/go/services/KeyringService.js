@@ -2,18 +2,50 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ApiKeyFingerprint(arg1) {
+  return window['go']['services']['KeyringService']['ApiKeyFingerprint'](arg1);
+}
+
 export function DeleteApiKey(arg1) {
   return window['go']['services']['KeyringService']['DeleteApiKey'](arg1);
 }
 
+export function DeleteProjectApiKey(arg1, arg2) {
+  return window['go']['services']['KeyringService']['DeleteProjectApiKey'](arg1, arg2);
+}
+
 export function GetApiKey(arg1) {
   return window['go']['services']['KeyringService']['GetApiKey'](arg1);
 }
 
+export function GetProjectApiKey(arg1, arg2) {
+  return window['go']['services']['KeyringService']['GetProjectApiKey'](arg1, arg2);
+}
+
+export function HasApiKey(arg1) {
+  return window['go']['services']['KeyringService']['HasApiKey'](arg1);
+}
+
 export function ListApiKeys() {
   return window['go']['services']['KeyringService']['ListApiKeys']();
 }
 
+export function ListStoredProviders() {
+  return window['go']['services']['KeyringService']['ListStoredProviders']();
+}
+
+export function ResolveApiKey(arg1, arg2) {
+  return window['go']['services']['KeyringService']['ResolveApiKey'](arg1, arg2);
+}
+
+export function SetApiKey(arg1, arg2, arg3) {
+  return window['go']['services']['KeyringService']['SetApiKey'](arg1, arg2, arg3);
+}
+
+export function SetProjectApiKey(arg1, arg2, arg3) {
+  return window['go']['services']['KeyringService']['SetProjectApiKey'](arg1, arg2, arg3);
+}
+
 export function StoreApiKey(arg1, arg2) {
   return window['go']['services']['KeyringService']['StoreApiKey'](arg1, arg2);
 }
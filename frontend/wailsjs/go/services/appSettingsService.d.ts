@@ -5,8 +5,48 @@ import {context} from '../models';
 
 export function Get():Promise<models.AppSettings>;
 
+export function SetCloneOptions(arg1:number,arg2:number):Promise<models.AppSettings>;
+
+export function SetCommitHistoryLimit(arg1:number):Promise<models.AppSettings>;
+
+export function SetDebugLogging(arg1:boolean):Promise<models.AppSettings>;
+
 export function SetDefaultModel(arg1:string):Promise<models.AppSettings>;
 
+export function SetDetachedDocCommits(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetEventOptions(arg1:number,arg2:boolean):Promise<models.AppSettings>;
+
+export function SetExistingDocsContext(arg1:number):Promise<models.AppSettings>;
+
+export function SetFinalSummaryTurn(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetGeminiOptions(arg1:string,arg2:string):Promise<models.AppSettings>;
+
+export function SetGenerateWithoutChanges(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetHistoryCompactModel(arg1:string):Promise<models.AppSettings>;
+
+export function SetHistoryCompaction(arg1:number,arg2:number,arg3:number):Promise<models.AppSettings>;
+
+export function SetHistoryLoadLimit(arg1:number,arg2:number):Promise<models.AppSettings>;
+
+export function SetMaxDocFileSize(arg1:number):Promise<models.AppSettings>;
+
+export function SetPreserveManualEdits(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetPublishedDocsSearch(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetRecordSessionEvents(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetRunLimits(arg1:number,arg2:number,arg3:boolean):Promise<models.AppSettings>;
+
+export function SetStructuredSummary(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetUpdateSubmodulePointer(arg1:boolean):Promise<models.AppSettings>;
+
+export function SetWorkspaceOptions(arg1:string,arg2:boolean):Promise<models.AppSettings>;
+
 export function Startup(arg1:context.Context):Promise<void>;
 
 export function Update(arg1:string,arg2:string):Promise<models.AppSettings>;
@@ -6,10 +6,90 @@ export function Get() {
   return window['go']['services']['appSettingsService']['Get']();
 }
 
+export function SetCloneOptions(arg1, arg2) {
+  return window['go']['services']['appSettingsService']['SetCloneOptions'](arg1, arg2);
+}
+
+export function SetCommitHistoryLimit(arg1) {
+  return window['go']['services']['appSettingsService']['SetCommitHistoryLimit'](arg1);
+}
+
+export function SetDebugLogging(arg1) {
+  return window['go']['services']['appSettingsService']['SetDebugLogging'](arg1);
+}
+
 export function SetDefaultModel(arg1) {
   return window['go']['services']['appSettingsService']['SetDefaultModel'](arg1);
 }
 
+export function SetDetachedDocCommits(arg1) {
+  return window['go']['services']['appSettingsService']['SetDetachedDocCommits'](arg1);
+}
+
+export function SetEventOptions(arg1, arg2) {
+  return window['go']['services']['appSettingsService']['SetEventOptions'](arg1, arg2);
+}
+
+export function SetExistingDocsContext(arg1) {
+  return window['go']['services']['appSettingsService']['SetExistingDocsContext'](arg1);
+}
+
+export function SetFinalSummaryTurn(arg1) {
+  return window['go']['services']['appSettingsService']['SetFinalSummaryTurn'](arg1);
+}
+
+export function SetGeminiOptions(arg1, arg2) {
+  return window['go']['services']['appSettingsService']['SetGeminiOptions'](arg1, arg2);
+}
+
+export function SetGenerateWithoutChanges(arg1) {
+  return window['go']['services']['appSettingsService']['SetGenerateWithoutChanges'](arg1);
+}
+
+export function SetHistoryCompactModel(arg1) {
+  return window['go']['services']['appSettingsService']['SetHistoryCompactModel'](arg1);
+}
+
+export function SetHistoryCompaction(arg1, arg2, arg3) {
+  return window['go']['services']['appSettingsService']['SetHistoryCompaction'](arg1, arg2, arg3);
+}
+
+export function SetHistoryLoadLimit(arg1, arg2) {
+  return window['go']['services']['appSettingsService']['SetHistoryLoadLimit'](arg1, arg2);
+}
+
+export function SetMaxDocFileSize(arg1) {
+  return window['go']['services']['appSettingsService']['SetMaxDocFileSize'](arg1);
+}
+
+export function SetPreserveManualEdits(arg1) {
+  return window['go']['services']['appSettingsService']['SetPreserveManualEdits'](arg1);
+}
+
+export function SetPublishedDocsSearch(arg1) {
+  return window['go']['services']['appSettingsService']['SetPublishedDocsSearch'](arg1);
+}
+
+export function SetRecordSessionEvents(arg1) {
+  return window['go']['services']['appSettingsService']['SetRecordSessionEvents'](arg1);
+}
+
+export function SetRunLimits(arg1, arg2, arg3) {
+  return window['go']['services']['appSettingsService']['SetRunLimits'](arg1, arg2, arg3);
+}
+
+export function SetStructuredSummary(arg1) {
+  return window['go']['services']['appSettingsService']['SetStructuredSummary'](arg1);
+}
+
+export function SetUpdateSubmodulePointer(arg1) {
+  return window['go']['services']['appSettingsService']['SetUpdateSubmodulePointer'](arg1);
+}
+
+export function SetWorkspaceOptions(arg1, arg2) {
+  return window['go']['services']['appSettingsService']['SetWorkspaceOptions'](arg1, arg2);
+}
+
 export function Startup(arg1) {
   return window['go']['services']['appSettingsService']['Startup'](arg1);
 }
@@ -0,0 +1,18 @@
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+import {models} from '../models';
+import {context} from '../models';
+
+export function CreateProfile(arg1:models.GenerationProfile):Promise<models.GenerationProfile>;
+
+export function DeleteProfile(arg1:number):Promise<void>;
+
+export function GetProfile(arg1:number):Promise<models.GenerationProfile>;
+
+export function ListProfiles(arg1:number):Promise<Array<models.GenerationProfile>>;
+
+export function Startup(arg1:context.Context):Promise<void>;
+
+export function UpdateProfile(arg1:models.GenerationProfile):Promise<models.GenerationProfile>;
+
+export function ValidateProfile(arg1:models.GenerationProfile):Promise<void>;
@@ -0,0 +1,31 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+export function CreateProfile(arg1) {
+  return window['go']['services']['generationProfileService']['CreateProfile'](arg1);
+}
+
+export function DeleteProfile(arg1) {
+  return window['go']['services']['generationProfileService']['DeleteProfile'](arg1);
+}
+
+export function GetProfile(arg1) {
+  return window['go']['services']['generationProfileService']['GetProfile'](arg1);
+}
+
+export function ListProfiles(arg1) {
+  return window['go']['services']['generationProfileService']['ListProfiles'](arg1);
+}
+
+export function Startup(arg1) {
+  return window['go']['services']['generationProfileService']['Startup'](arg1);
+}
+
+export function UpdateProfile(arg1) {
+  return window['go']['services']['generationProfileService']['UpdateProfile'](arg1);
+}
+
+export function ValidateProfile(arg1) {
+  return window['go']['services']['generationProfileService']['ValidateProfile'](arg1);
+}
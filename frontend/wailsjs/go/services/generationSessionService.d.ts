@@ -3,6 +3,8 @@
 import {models} from '../models';
 import {context} from '../models';
 
+export function AppendEventLog(arg1:number,arg2:Array<models.SessionEventLogEntry>):Promise<void>;
+
 export function Create(arg1:models.GenerationSession):Promise<models.GenerationSession>;
 
 export function DeleteAll(arg1:number):Promise<void>;
@@ -13,6 +15,8 @@ export function GetByDocsBranch(arg1:number,arg2:string):Promise<models.Generati
 
 export function GetByID(arg1:number):Promise<models.GenerationSession>;
 
+export function GetEventLog(arg1:number):Promise<Array<models.SessionEventLogEntry>>;
+
 export function List(arg1:number):Promise<Array<models.GenerationSession>>;
 
 export function Startup(arg1:context.Context):Promise<void>;
@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AppendEventLog(arg1, arg2) {
+  return window['go']['services']['generationSessionService']['AppendEventLog'](arg1, arg2);
+}
+
 export function Create(arg1) {
   return window['go']['services']['generationSessionService']['Create'](arg1);
 }
@@ -22,6 +26,10 @@ export function GetByID(arg1) {
   return window['go']['services']['generationSessionService']['GetByID'](arg1);
 }
 
+export function GetEventLog(arg1) {
+  return window['go']['services']['generationSessionService']['GetEventLog'](arg1);
+}
+
 export function List(arg1) {
   return window['go']['services']['generationSessionService']['List'](arg1);
 }
@@ -3,12 +3,26 @@
 import {models} from '../models';
 import {context} from '../models';
 
+export function CheckEndpoint(arg1:string):Promise<void>;
+
 export function GetModel(arg1:string):Promise<models.LLMModel>;
 
 export function ListModelGroups():Promise<Array<models.LLMModelGroup>>;
 
+export function RegisterCustomModel(arg1:string,arg2:string,arg3:string,arg4:string):Promise<models.LLMModel>;
+
+export function SeedDefaultModels(arg1:string):Promise<Array<models.LLMModel>>;
+
 export function SetModelEnabled(arg1:string,arg2:boolean):Promise<models.LLMModel>;
 
+export function SetModelPriority(arg1:string,arg2:number):Promise<models.LLMModel>;
+
+export function SetModelReasoningBudget(arg1:string,arg2:number,arg3:string):Promise<models.LLMModel>;
+
+export function SetModelShowReasoning(arg1:string,arg2:boolean):Promise<models.LLMModel>;
+
+export function SetModelsEnabled(arg1:Array<string>,arg2:boolean):Promise<Array<models.LLMModel>>;
+
 export function SetProviderEnabled(arg1:string,arg2:boolean):Promise<Array<models.LLMModel>>;
 
 export function Startup(arg1:context.Context):Promise<void>;
@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CheckEndpoint(arg1) {
+  return window['go']['services']['modelConfigService']['CheckEndpoint'](arg1);
+}
+
 export function GetModel(arg1) {
   return window['go']['services']['modelConfigService']['GetModel'](arg1);
 }
@@ -10,10 +14,34 @@ export function ListModelGroups() {
   return window['go']['services']['modelConfigService']['ListModelGroups']();
 }
 
+export function RegisterCustomModel(arg1, arg2, arg3, arg4) {
+  return window['go']['services']['modelConfigService']['RegisterCustomModel'](arg1, arg2, arg3, arg4);
+}
+
+export function SeedDefaultModels(arg1) {
+  return window['go']['services']['modelConfigService']['SeedDefaultModels'](arg1);
+}
+
 export function SetModelEnabled(arg1, arg2) {
   return window['go']['services']['modelConfigService']['SetModelEnabled'](arg1, arg2);
 }
 
+export function SetModelPriority(arg1, arg2) {
+  return window['go']['services']['modelConfigService']['SetModelPriority'](arg1, arg2);
+}
+
+export function SetModelReasoningBudget(arg1, arg2, arg3) {
+  return window['go']['services']['modelConfigService']['SetModelReasoningBudget'](arg1, arg2, arg3);
+}
+
+export function SetModelShowReasoning(arg1, arg2) {
+  return window['go']['services']['modelConfigService']['SetModelShowReasoning'](arg1, arg2);
+}
+
+export function SetModelsEnabled(arg1, arg2) {
+  return window['go']['services']['modelConfigService']['SetModelsEnabled'](arg1, arg2);
+}
+
 export function SetProviderEnabled(arg1, arg2) {
   return window['go']['services']['modelConfigService']['SetProviderEnabled'](arg1, arg2);
 }
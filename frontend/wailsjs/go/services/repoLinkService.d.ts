@@ -18,6 +18,20 @@ export function List(arg1:number,arg2:number):Promise<Array<models.RepoLink>>;
 
 export function Register(arg1:string,arg2:string,arg3:string,arg4:string,arg5:boolean):Promise<models.RepoLink>;
 
+export function SetAllowedMDXComponents(arg1:number,arg2:Array<string>):Promise<void>;
+
+export function SetAllowedTools(arg1:number,arg2:Array<string>):Promise<void>;
+
+export function SetCodeScopePath(arg1:number,arg2:string):Promise<void>;
+
+export function SetDefaultModelKey(arg1:number,arg2:string):Promise<void>;
+
+export function SetFilesystemTarget(arg1:number,arg2:boolean):Promise<void>;
+
+export function SetMaintainFumadocsMeta(arg1:number,arg2:boolean):Promise<void>;
+
+export function SetPromptSections(arg1:number,arg2:boolean,arg3:boolean,arg4:boolean,arg5:number):Promise<void>;
+
 export function Startup(arg1:context.Context):Promise<void>;
 
 export function UpdateProjectOrder(arg1:Array<models.RepoLinkOrderUpdate>):Promise<void>;
@@ -30,6 +30,34 @@ export function Register(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['services']['repoLinkService']['Register'](arg1, arg2, arg3, arg4, arg5);
 }
 
+export function SetAllowedMDXComponents(arg1, arg2) {
+  return window['go']['services']['repoLinkService']['SetAllowedMDXComponents'](arg1, arg2);
+}
+
+export function SetAllowedTools(arg1, arg2) {
+  return window['go']['services']['repoLinkService']['SetAllowedTools'](arg1, arg2);
+}
+
+export function SetCodeScopePath(arg1, arg2) {
+  return window['go']['services']['repoLinkService']['SetCodeScopePath'](arg1, arg2);
+}
+
+export function SetDefaultModelKey(arg1, arg2) {
+  return window['go']['services']['repoLinkService']['SetDefaultModelKey'](arg1, arg2);
+}
+
+export function SetFilesystemTarget(arg1, arg2) {
+  return window['go']['services']['repoLinkService']['SetFilesystemTarget'](arg1, arg2);
+}
+
+export function SetMaintainFumadocsMeta(arg1, arg2) {
+  return window['go']['services']['repoLinkService']['SetMaintainFumadocsMeta'](arg1, arg2);
+}
+
+export function SetPromptSections(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['services']['repoLinkService']['SetPromptSections'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function Startup(arg1) {
   return window['go']['services']['repoLinkService']['Startup'](arg1);
 }
@@ -66,6 +66,7 @@ func migrate(db *gorm.DB) error {
 		&models.GenerationSession{},
 		&models.ModelSetting{},
 		&models.Template{},
+		&models.GenerationProfile{},
 	); err != nil {
 		return fmt.Errorf("auto migrate: %w", err)
 	}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultCoalesceWindow is how long repeated tool events are collected before a summary is sent.
+const DefaultCoalesceWindow = 1500 * time.Millisecond
+
+// CoalescedMetadataKey holds how many events a summary event stands for.
+const CoalescedMetadataKey = "coalesced"
+
+// CoalesceOptions controls how bursts of similar events are merged before reaching the UI.
+type CoalesceOptions struct {
+	// Window is how long a burst stays open after its first event; zero disables coalescing.
+	Window time.Duration
+	// Verbose forwards every event unchanged, e.g. for debugging a run.
+	Verbose bool
+}
+
+type burst struct {
+	ctx        context.Context
+	name       string
+	sessionKey string
+	last       ToolEvent
+	count      int
+}
+
+// Coalescer forwards events to a sink, merging bursts of successful events for
+// the same tool (or identical log messages) into one summary per window. The
+// first event of a burst is forwarded immediately; the rest are counted and
+// reported together when the window closes. Errors, policy and reasoning events
+// always pass straight through.
+type Coalescer struct {
+	sink func(ctx context.Context, name string, evt ToolEvent)
+
+	mu     sync.Mutex
+	opts   CoalesceOptions
+	bursts map[string]*burst
+}
+
+// NewCoalescer creates a Coalescer using DefaultCoalesceWindow.
+func NewCoalescer(sink func(ctx context.Context, name string, evt ToolEvent)) *Coalescer {
+	return &Coalescer{
+		sink:   sink,
+		opts:   CoalesceOptions{Window: DefaultCoalesceWindow},
+		bursts: make(map[string]*burst),
+	}
+}
+
+// SetOptions replaces the coalescing options. Pending bursts are flushed first.
+func (c *Coalescer) SetOptions(opts CoalesceOptions) {
+	c.Flush()
+	c.mu.Lock()
+	c.opts = opts
+	c.mu.Unlock()
+}
+
+// Emit forwards evt to the sink, or folds it into an open burst.
+func (c *Coalescer) Emit(ctx context.Context, name string, evt ToolEvent) {
+	if name == LLMEventDone {
+		// Summaries belong before the run's completion event
+		c.flushSession(evt.SessionKey)
+		c.sink(ctx, name, evt)
+		return
+	}
+
+	c.mu.Lock()
+	key := coalesceKey(name, evt)
+	if c.opts.Verbose || c.opts.Window <= 0 || key == "" {
+		c.mu.Unlock()
+		c.sink(ctx, name, evt)
+		return
+	}
+	if b, ok := c.bursts[key]; ok {
+		b.last = evt
+		b.count++
+		c.mu.Unlock()
+		return
+	}
+	b := &burst{ctx: ctx, name: name, sessionKey: evt.SessionKey}
+	c.bursts[key] = b
+	window := c.opts.Window
+	c.mu.Unlock()
+
+	c.sink(ctx, name, evt)
+	time.AfterFunc(window, func() { c.flushKey(key, b) })
+}
+
+// Flush sends the summaries of all open bursts immediately.
+func (c *Coalescer) Flush() {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.bursts))
+	for key := range c.bursts {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+	for _, key := range keys {
+		c.flushKey(key, nil)
+	}
+}
+
+func (c *Coalescer) flushSession(sessionKey string) {
+	c.mu.Lock()
+	var keys []string
+	for key, b := range c.bursts {
+		if b.sessionKey == sessionKey {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Unlock()
+	for _, key := range keys {
+		c.flushKey(key, nil)
+	}
+}
+
+// flushKey closes the burst under key. When only is set, the burst is closed
+// only if it is still the open one, so a stale timer cannot cut a newer burst short.
+func (c *Coalescer) flushKey(key string, only *burst) {
+	c.mu.Lock()
+	b, ok := c.bursts[key]
+	if ok && only != nil && b != only {
+		ok = false
+	}
+	if ok {
+		delete(c.bursts, key)
+	}
+	c.mu.Unlock()
+	if !ok || b.count == 0 {
+		return
+	}
+	c.sink(b.ctx, b.name, summarizeBurst(b))
+}
+
+// coalesceKey groups events that may be merged; "" means the event is never merged.
+func coalesceKey(name string, evt ToolEvent) string {
+	switch {
+	case evt.Type == EventError:
+		return ""
+	case evt.Kind == EventKindTool && evt.ToolName != "":
+		return fmt.Sprintf("%s|%s|%s|tool:%s", evt.SessionKey, name, evt.Type, evt.ToolName)
+	case evt.Kind == EventKindLog || evt.Kind == "":
+		return fmt.Sprintf("%s|%s|%s|msg:%s", evt.SessionKey, name, evt.Type, evt.Message)
+	default:
+		return ""
+	}
+}
+
+func summarizeBurst(b *burst) ToolEvent {
+	summary := CreateToolEvent(b.last.Type, fmt.Sprintf("%s (%d more)", b.last.Message, b.count))
+	summary.Kind = b.last.Kind
+	summary.ToolName = b.last.ToolName
+	summary.SessionKey = b.last.SessionKey
+	summary = summary.WithMetadata(b.last.Metadata)
+	summary.Metadata[CoalescedMetadataKey] = strconv.Itoa(b.count)
+	return summary
+}
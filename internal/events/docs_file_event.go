@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	LLMEventDocsFile = "event:llm:docs_file"
+)
+
+// DocsFileOperation describes how a documentation file was changed
+type DocsFileOperation string
+
+const (
+	DocsFileCreated  DocsFileOperation = "created"
+	DocsFileModified DocsFileOperation = "modified"
+	DocsFileDeleted  DocsFileOperation = "deleted"
+)
+
+// DocsFileEvent reports a documentation file successfully changed by the agent during a run
+type DocsFileEvent struct {
+	ID         string            `json:"id"`
+	Path       string            `json:"path"`
+	Operation  DocsFileOperation `json:"operation"`
+	Tool       string            `json:"tool,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+	SessionKey string            `json:"sessionKey,omitempty"`
+}
+
+// EmitDocsFileChanged emits a docs file change event to the frontend.
+// The path should be relative to the documentation root and slash-separated.
+// It is a no-op until EnableRuntimeEmitter is called, so the file tools can
+// run outside the Wails runtime (e.g. in tests).
+var EmitDocsFileChanged = func(ctx context.Context, path string, op DocsFileOperation, tool string) {}
+
+func emitDocsFileChangedRuntime(ctx context.Context, path string, op DocsFileOperation, tool string) {
+	sessionKey := SessionFromContext(ctx)
+
+	evt := DocsFileEvent{
+		ID:         uuid.NewString(),
+		Path:       path,
+		Operation:  op,
+		Tool:       tool,
+		Timestamp:  time.Now(),
+		SessionKey: sessionKey,
+	}
+
+	runtime.EventsEmit(ctx, LLMEventDocsFile, evt)
+}
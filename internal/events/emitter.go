@@ -2,40 +2,91 @@ package events
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-var Emit = func(ctx context.Context, name string, evt ToolEvent) {}
+// uiEvents coalesces events on their way to the frontend; logs and subscribers
+// still receive every event.
+var uiEvents = NewCoalescer(func(ctx context.Context, name string, evt ToolEvent) {
+	runtime.EventsEmit(ctx, name, evt)
+})
+
+// SetCoalesceOptions configures how bursts of events sent to the frontend are merged.
+func SetCoalesceOptions(opts CoalesceOptions) {
+	uiEvents.SetOptions(opts)
+}
+
+var debugEnabled atomic.Bool
+
+// SetDebugEnabled turns debug events on or off. They are dropped while disabled.
+func SetDebugEnabled(enabled bool) {
+	debugEnabled.Store(enabled)
+}
+
+// DebugEnabled reports whether debug events are emitted, so callers can skip
+// building expensive diagnostics.
+func DebugEnabled() bool {
+	return debugEnabled.Load()
+}
+
+var Emit = func(ctx context.Context, name string, evt ToolEvent) {
+	if suppressed(evt) {
+		return
+	}
+	publish(ctx, name, withBackground(ctx, name, withSessionKey(ctx, evt)))
+}
 
 func EnableRuntimeEmitter() {
 	Emit = func(ctx context.Context, name string, evt ToolEvent) {
-		if evt.SessionKey == "" {
-			if session := SessionFromContext(ctx); session != "" {
-				evt.SessionKey = session
-			}
+		if suppressed(evt) {
+			return
 		}
+		evt = withBackground(ctx, name, withSessionKey(ctx, evt))
+		publish(ctx, name, evt)
 
 		if evt.Type == EventSuccess || evt.Type == EventError {
-			runtime.EventsEmit(ctx, name, evt)
+			uiEvents.Emit(ctx, name, evt)
 		}
 
 		logRuntimeEvent(ctx, name, evt)
 	}
-
+	EmitGitTransferProgress = emitGitTransferProgressRuntime
+	EmitProgress = emitProgressRuntime
+	EmitDocsFileChanged = emitDocsFileChangedRuntime
+	runtimeEmitterEnabled.Store(true)
 }
 
 func SetCustomEmitter(f func(ctx context.Context, name string, evt ToolEvent)) {
 	if f == nil {
-		Emit = func(context.Context, string, ToolEvent) {}
+		Emit = func(ctx context.Context, name string, evt ToolEvent) {
+			if suppressed(evt) {
+				return
+			}
+			publish(ctx, name, withBackground(ctx, name, withSessionKey(ctx, evt)))
+		}
 		return
 	}
 	Emit = func(ctx context.Context, name string, evt ToolEvent) {
-		if evt.SessionKey == "" {
-			if session := SessionFromContext(ctx); session != "" {
-				evt.SessionKey = session
-			}
+		if suppressed(evt) {
+			return
 		}
+		evt = withBackground(ctx, name, withSessionKey(ctx, evt))
+		publish(ctx, name, evt)
 		f(ctx, name, evt)
 	}
 }
+
+func withSessionKey(ctx context.Context, evt ToolEvent) ToolEvent {
+	if evt.SessionKey == "" {
+		if session := SessionFromContext(ctx); session != "" {
+			evt.SessionKey = session
+		}
+	}
+	return evt
+}
+
+func suppressed(evt ToolEvent) bool {
+	return evt.Type == EventDebug && !DebugEnabled()
+}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	GitEventTransferProgress = "event:git:transfer_progress"
+)
+
+// GitTransferProgressEvent reports how many objects have been copied back to the main repository
+type GitTransferProgressEvent struct {
+	ID          string    `json:"id"`
+	Transferred int       `json:"transferred"`
+	Total       int       `json:"total"`
+	Timestamp   time.Time `json:"timestamp"`
+	SessionKey  string    `json:"sessionKey,omitempty"`
+}
+
+// EmitGitTransferProgress is a no-op until EnableRuntimeEmitter is called, so object
+// transfers can run outside the Wails runtime (e.g. in tests).
+var EmitGitTransferProgress = func(ctx context.Context, sessionKey string, transferred, total int) {}
+
+func emitGitTransferProgressRuntime(ctx context.Context, sessionKey string, transferred, total int) {
+	if sessionKey == "" {
+		sessionKey = SessionFromContext(ctx)
+	}
+
+	evt := GitTransferProgressEvent{
+		ID:          uuid.NewString(),
+		Transferred: transferred,
+		Total:       total,
+		Timestamp:   time.Now(),
+		SessionKey:  sessionKey,
+	}
+
+	runtime.EventsEmit(ctx, GitEventTransferProgress, evt)
+}
@@ -0,0 +1,152 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const redactedSecret = "[REDACTED]"
+
+// runtimeEmitterEnabled is set once events are persisted through the Wails
+// runtime log, so loggers no longer need to fall back to the standard logger.
+var runtimeEmitterEnabled atomic.Bool
+
+// Logger writes diagnostics for the session carried by its context. Messages
+// go to the session's event stream and to the application log, with any
+// registered secrets masked beforehand.
+type Logger struct {
+	ctx     context.Context
+	secrets []string
+}
+
+const loggerContextKey contextKey = "narrabyte/events/logger"
+
+// WithLogger returns a derived context that LoggerFrom resolves to l.
+func WithLogger(ctx context.Context, l *Logger) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// LoggerFrom returns the logger injected into ctx, or a logger scoped to the
+// session of ctx when none was injected.
+func LoggerFrom(ctx context.Context) *Logger {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if l, ok := ctx.Value(loggerContextKey).(*Logger); ok {
+		return l
+	}
+	return &Logger{ctx: ctx}
+}
+
+// Redacting returns a copy of l that masks every occurrence of the given
+// secrets, such as API keys, in logged messages.
+func (l *Logger) Redacting(secrets ...string) *Logger {
+	next := &Logger{ctx: l.ctx, secrets: append([]string(nil), l.secrets...)}
+	for _, secret := range secrets {
+		if strings.TrimSpace(secret) != "" {
+			next.secrets = append(next.secrets, secret)
+		}
+	}
+	return next
+}
+
+// Infof logs an info message.
+func (l *Logger) Infof(format string, args ...any) {
+	l.log(EventInfo, format, args...)
+}
+
+// Warnf logs a warning message.
+func (l *Logger) Warnf(format string, args ...any) {
+	l.log(EventWarn, format, args...)
+}
+
+// Errorf logs an error message.
+func (l *Logger) Errorf(format string, args ...any) {
+	l.log(EventError, format, args...)
+}
+
+// Debugf logs a debug message; it is dropped unless debug events are enabled.
+func (l *Logger) Debugf(format string, args ...any) {
+	l.log(EventDebug, format, args...)
+}
+
+func (l *Logger) log(eventType EventType, format string, args ...any) {
+	message := l.redact(fmt.Sprintf(format, args...))
+	evt := CreateToolEvent(eventType, message)
+	if suppressed(evt) {
+		return
+	}
+	Emit(l.ctx, LLMEventTool, evt)
+	if !runtimeEmitterEnabled.Load() {
+		if session := SessionFromContext(l.ctx); session != "" {
+			log.Printf("[%s] %s: %s", session, eventType, message)
+		} else {
+			log.Printf("%s: %s", eventType, message)
+		}
+	}
+}
+
+func (l *Logger) redact(message string) string {
+	for _, secret := range l.secrets {
+		message = strings.ReplaceAll(message, secret, redactedSecret)
+	}
+	return message
+}
+
+// Writer returns a writer that logs each complete line written to it at the
+// given level, so output a tool would print to stdout ends up in the session
+// log instead. Call Close to flush a trailing partial line.
+func (l *Logger) Writer(eventType EventType) io.WriteCloser {
+	return &lineWriter{logger: l, eventType: eventType}
+}
+
+type lineWriter struct {
+	mu        sync.Mutex
+	logger    *Logger
+	eventType EventType
+	buf       bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Keep the partial line until the rest of it arrives
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.emit(line)
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() > 0 {
+		w.emit(w.buf.String())
+		w.buf.Reset()
+	}
+	return nil
+}
+
+func (w *lineWriter) emit(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	w.logger.log(w.eventType, "%s", line)
+}
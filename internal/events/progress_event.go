@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	LLMEventProgress = "event:llm:progress"
+)
+
+// ProgressEvent reports how far along a generation run is
+type ProgressEvent struct {
+	ID string `json:"id"`
+	// Percent is between 0 and 100
+	Percent   int `json:"percent"`
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+	// Estimated is set when the percentage is guessed from the number of tool
+	// calls because the agent keeps no todo list
+	Estimated  bool      `json:"estimated"`
+	Timestamp  time.Time `json:"timestamp"`
+	SessionKey string    `json:"sessionKey,omitempty"`
+}
+
+// EmitProgress is a no-op until EnableRuntimeEmitter is called, so tool
+// wrappers can report progress outside the Wails runtime (e.g. in tests).
+var EmitProgress = func(ctx context.Context, percent, completed, total int, estimated bool) {}
+
+func emitProgressRuntime(ctx context.Context, percent, completed, total int, estimated bool) {
+	sessionKey := SessionFromContext(ctx)
+
+	evt := ProgressEvent{
+		ID:         uuid.NewString(),
+		Percent:    percent,
+		Completed:  completed,
+		Total:      total,
+		Estimated:  estimated,
+		Timestamp:  time.Now(),
+		SessionKey: sessionKey,
+	}
+
+	runtime.EventsEmit(ctx, LLMEventProgress, evt)
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Recorder keeps the most recent events emitted under a context, dropping the
+// oldest once capacity is reached.
+type Recorder struct {
+	mu       sync.Mutex
+	capacity int
+	events   []ToolEvent
+}
+
+// NewRecorder creates a Recorder holding at most capacity events.
+func NewRecorder(capacity int) *Recorder {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Recorder{capacity: capacity}
+}
+
+func (r *Recorder) record(evt ToolEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) == r.capacity {
+		copy(r.events, r.events[1:])
+		r.events = r.events[:len(r.events)-1]
+	}
+	r.events = append(r.events, evt)
+}
+
+// Drain returns the recorded events in emission order and clears the recorder.
+func (r *Recorder) Drain() []ToolEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	drained := r.events
+	r.events = nil
+	return drained
+}
+
+const recorderContextKey contextKey = "narrabyte/events/recorder"
+
+// WithRecorder returns a derived context whose emitted events are also appended to r.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, recorderContextKey, r)
+}
+
+func recordEvent(ctx context.Context, evt ToolEvent) {
+	if ctx == nil {
+		return
+	}
+	r, ok := ctx.Value(recorderContextKey).(*Recorder)
+	if !ok {
+		return
+	}
+	// Each reasoning or content update repeats the text so far, so recording them would only bloat the log
+	if evt.Kind == EventKindReasoning || evt.Kind == EventKindContent {
+		return
+	}
+	r.record(evt)
+}
@@ -8,6 +8,15 @@ import (
 )
 
 func logRuntimeEvent(ctx context.Context, name string, event ToolEvent) {
+	// Streamed reasoning and assistant text can be long and may quote file
+	// contents, so they are only logged in debug mode
+	if event.Kind == EventKindReasoning || event.Kind == EventKindContent {
+		if !DebugEnabled() {
+			return
+		}
+		event.Type = EventDebug
+	}
+
 	data, err := json.Marshal(event)
 	if err != nil {
 		runtime.LogError(ctx, "loggers: failed to marshal tool event: "+err.Error())
@@ -25,6 +34,8 @@ func logRuntimeEvent(ctx context.Context, name string, event ToolEvent) {
 		runtime.LogWarning(ctx, payload)
 	case EventInfo:
 		runtime.LogInfo(ctx, payload)
+	case EventDebug:
+		runtime.LogDebug(ctx, payload)
 	default:
 		runtime.LogInfo(ctx, payload)
 	}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// EventFilter selects ToolEvents by event name, kind, tool and session. Empty fields
+// match everything.
+type EventFilter struct {
+	Names      []string
+	Kinds      []EventKind
+	Tools      []string
+	SessionKey string
+}
+
+// Matches reports whether an event emitted under name passes the filter.
+func (f EventFilter) Matches(name string, evt ToolEvent) bool {
+	if len(f.Names) > 0 && !slices.Contains(f.Names, name) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, evt.Kind) {
+		return false
+	}
+	if len(f.Tools) > 0 && !slices.Contains(f.Tools, evt.ToolName) {
+		return false
+	}
+	if f.SessionKey != "" && evt.SessionKey != f.SessionKey {
+		return false
+	}
+	return true
+}
+
+type subscriber struct {
+	filter  EventFilter
+	handler func(ctx context.Context, name string, evt ToolEvent)
+}
+
+var (
+	subscribersMu    sync.RWMutex
+	subscribers      = make(map[uint64]subscriber)
+	nextSubscriberID uint64
+)
+
+// Subscribe registers handler for every emitted ToolEvent that matches filter and
+// returns a function that removes the subscription. Handlers run synchronously on
+// the emitting goroutine, so they must not block.
+func Subscribe(filter EventFilter, handler func(ctx context.Context, name string, evt ToolEvent)) (unsubscribe func()) {
+	if handler == nil {
+		return func() {}
+	}
+	subscribersMu.Lock()
+	nextSubscriberID++
+	id := nextSubscriberID
+	subscribers[id] = subscriber{filter: filter, handler: handler}
+	subscribersMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			subscribersMu.Lock()
+			delete(subscribers, id)
+			subscribersMu.Unlock()
+		})
+	}
+}
+
+// publish records evt on the context's Recorder, if any, and delivers it to
+// matching subscribers. It runs for every emitter, including the default no-op
+// one, so subscriptions and recording work outside the Wails runtime.
+func publish(ctx context.Context, name string, evt ToolEvent) {
+	recordEvent(ctx, evt)
+
+	subscribersMu.RLock()
+	if len(subscribers) == 0 {
+		subscribersMu.RUnlock()
+		return
+	}
+	matched := make([]subscriber, 0, len(subscribers))
+	for _, sub := range subscribers {
+		if sub.filter.Matches(name, evt) {
+			matched = append(matched, sub)
+		}
+	}
+	subscribersMu.RUnlock()
+
+	for _, sub := range matched {
+		sub.handler(ctx, name, evt)
+	}
+}
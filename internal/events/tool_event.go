@@ -15,6 +15,19 @@ const (
 	EventWarn    EventType = "warn"
 	EventSuccess EventType = "success"
 	EventError   EventType = "error"
+	// EventDebug events are dropped unless debug logging is enabled and never reach the UI.
+	EventDebug EventType = "debug"
+)
+
+// EventKind classifies a ToolEvent so subscribers can filter without parsing messages.
+type EventKind string
+
+const (
+	EventKindLog       EventKind = "log"       // progress and diagnostic messages
+	EventKindTool      EventKind = "tool"      // a tool call finished, successfully or not
+	EventKindPolicy    EventKind = "policy"    // a tool call was rejected before it ran
+	EventKindReasoning EventKind = "reasoning" // streamed model reasoning content
+	EventKindContent   EventKind = "content"   // streamed assistant text
 )
 
 const (
@@ -23,14 +36,35 @@ const (
 	LLMEventDone = "events:llm:done"
 )
 
-// ToolEvent is a simple struct representing a backend event payload
+// ToolEvent is a simple struct representing a backend event payload.
+// Message is human readable and meant for logs; the UI should rely on Kind and ToolName.
 type ToolEvent struct {
 	ID         string            `json:"id"`
 	Type       EventType         `json:"type"`
+	Kind       EventKind         `json:"kind"`
+	ToolName   string            `json:"toolName,omitempty"`
 	Message    string            `json:"message"`
 	Timestamp  time.Time         `json:"timestamp"`
 	SessionKey string            `json:"sessionKey,omitempty"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	// Summary is only set on the completion event of a successful run.
+	Summary *RunSummary `json:"summary,omitempty"`
+	// Background is set on the completion event of a run whose session was
+	// not shown in a tab when it finished; see WithTabCheck.
+	Background bool `json:"background,omitempty"`
+}
+
+// RunSummary reports what a generation run did, attached to its completion event.
+type RunSummary struct {
+	FilesCreated     int            `json:"filesCreated"`
+	FilesModified    int            `json:"filesModified"`
+	FilesDeleted     int            `json:"filesDeleted"`
+	ToolCalls        map[string]int `json:"toolCalls"`
+	PromptTokens     int            `json:"promptTokens"`
+	CompletionTokens int            `json:"completionTokens"`
+	ReasoningTokens  int            `json:"reasoningTokens"`
+	TotalTokens      int            `json:"totalTokens"`
+	ElapsedMs        int64          `json:"elapsedMs"`
 }
 
 type contextKey string
@@ -57,10 +91,35 @@ func SessionFromContext(ctx context.Context) string {
 	return ""
 }
 
+const tabCheckContextKey contextKey = "narrabyte/events/tab-check"
+
+// WithTabCheck returns a derived context whose run completion events call
+// inTab to learn whether their session is shown in a UI tab. It is called as
+// each event is emitted, since a session can be moved to the background while
+// its run is in progress.
+func WithTabCheck(ctx context.Context, inTab func() bool) context.Context {
+	if inTab == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tabCheckContextKey, inTab)
+}
+
+// withBackground flags a completion event whose session is not in a tab.
+func withBackground(ctx context.Context, name string, evt ToolEvent) ToolEvent {
+	if name != LLMEventDone || ctx == nil {
+		return evt
+	}
+	if inTab, ok := ctx.Value(tabCheckContextKey).(func() bool); ok && !inTab() {
+		evt.Background = true
+	}
+	return evt
+}
+
 func CreateToolEvent(eventType EventType, message string) ToolEvent {
 	return ToolEvent{
 		ID:        uuid.NewString(),
 		Type:      eventType,
+		Kind:      EventKindLog,
 		Message:   message,
 		Timestamp: time.Now(),
 	}
@@ -81,6 +140,11 @@ func NewError(message string) ToolEvent {
 	return CreateToolEvent(EventError, message)
 }
 
+// NewDebug creates a debug ToolEvent.
+func NewDebug(message string) ToolEvent {
+	return CreateToolEvent(EventDebug, message)
+}
+
 // NewSuccess creates a success ToolEvent.
 func NewSuccess(message string) ToolEvent {
 	return CreateToolEvent(EventSuccess, message)
@@ -97,9 +161,23 @@ func (te ToolEvent) WithMetadata(metadata map[string]string) ToolEvent {
 	return te
 }
 
+// WithTool sets the event kind and the tool it relates to.
+func (te ToolEvent) WithTool(kind EventKind, toolName string) ToolEvent {
+	te.Kind = kind
+	te.ToolName = toolName
+	return te
+}
+
+// WithSummary attaches a run summary to a ToolEvent.
+func (te ToolEvent) WithSummary(summary RunSummary) ToolEvent {
+	te.Summary = &summary
+	return te
+}
+
 // NewToolEvent creates a ToolEvent with tool type and path metadata for common tool operations.
 func NewToolEvent(eventType EventType, message, toolType, path string) ToolEvent {
-	event := CreateToolEvent(eventType, message)
+	event := CreateToolEvent(eventType, message).WithTool(EventKindTool, toolType)
+	// The tool metadata key predates ToolName and is kept for older frontends
 	event.Metadata = map[string]string{
 		"tool": toolType,
 	}
@@ -0,0 +1,251 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"narrabyte/internal/events"
+)
+
+// bootstrapPartSummaryChars bounds the summary of each earlier part carried
+// into the prompt of the next one.
+const bootstrapPartSummaryChars = 1500
+
+// bootstrapChunk is one part of a full bootstrap documented in several runs.
+type bootstrapChunk struct {
+	// index is the zero-based position of the part among total parts
+	index int
+	total int
+	// totalFiles is the number of code files across every part
+	totalFiles int
+	// previous summarizes what the earlier parts documented
+	previous string
+}
+
+// pending reports whether more parts follow this one. It is false for a run
+// that is not chunked.
+func (c *bootstrapChunk) pending() bool {
+	return c != nil && c.index < c.total-1
+}
+
+// bootstrapChunks packs the file groups of a full bootstrap into parts of at
+// most chunkFiles files, keeping each group whole so a directory is not split
+// across runs more than groupCodeFiles already splits it.
+func bootstrapChunks(files []ChangedFile, groupSize, chunkFiles int) [][]ChangedFile {
+	var chunks [][]ChangedFile
+	for _, group := range groupCodeFiles(files, groupSize) {
+		last := len(chunks) - 1
+		if last < 0 || len(chunks[last])+len(group) > chunkFiles {
+			chunks = append(chunks, nil)
+			last++
+		}
+		chunks[last] = append(chunks[last], group...)
+	}
+	return chunks
+}
+
+// BootstrapProgress is how far a chunked full bootstrap got. It is kept while
+// a part runs, so a paused or canceled run can save it and ContinueBootstrap
+// can document the parts the interrupted one was to be followed by.
+type BootstrapProgress struct {
+	// Part is the zero-based index of the part running or interrupted, and
+	// Label names it in the summaries
+	Part       int    `json:"part"`
+	Label      string `json:"label"`
+	Total      int    `json:"total"`
+	TotalFiles int    `json:"totalFiles"`
+	// Remaining holds the files of the parts after Part
+	Remaining [][]ChangedFile `json:"remaining"`
+	// Summaries are the summaries of the parts before Part; Carried is the
+	// shortened form of them the next part's prompt carries forward
+	Summaries []string `json:"summaries"`
+	Carried   string   `json:"carried"`
+	// The request fields the remaining parts are run with
+	SourceCommit        string `json:"sourceCommit"`
+	SourceCommitSummary string `json:"sourceCommitSummary"`
+	SpecificInstr       string `json:"specificInstr"`
+}
+
+// record adds the summary of the current part.
+func (p *BootstrapProgress) record(summary string) {
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		summary = "(no summary)"
+	}
+	p.Summaries = append(p.Summaries, fmt.Sprintf("## %s\n%s", p.Label, summary))
+	if runes := []rune(summary); len(runes) > bootstrapPartSummaryChars {
+		summary = strings.TrimSpace(string(runes[:bootstrapPartSummaryChars])) + "..."
+	}
+	p.Carried += fmt.Sprintf("## %s\n%s\n\n", p.Label, summary)
+}
+
+// generateDocsChunked documents a full bootstrap one part at a time. Each
+// part is a separate run whose prompt lists only that part's files and
+// carries the summaries of the earlier parts forward, so later parts extend
+// the pages already written instead of starting over. The conversation
+// history kept for refinement is the last part's; the summary joins all of
+// them.
+func (o *LLMClient) generateDocsChunked(ctx context.Context, req *DocGenerationRequest, chunks [][]ChangedFile) (*DocGenerationResponse, error) {
+	return o.documentBootstrapParts(ctx, req, &BootstrapProgress{
+		Total:               len(chunks),
+		TotalFiles:          len(req.ChangedFiles),
+		Remaining:           chunks,
+		SourceCommit:        req.SourceCommit,
+		SourceCommitSummary: req.SourceCommitSummary,
+		SpecificInstr:       req.SpecificInstr,
+	}, nil)
+}
+
+// documentBootstrapParts runs the parts left in progress in order. last is
+// the response of the part before them, if it already ran; the merged
+// response carries the structured summary of the final part.
+func (o *LLMClient) documentBootstrapParts(ctx context.Context, req *DocGenerationRequest, progress *BootstrapProgress, last *DocGenerationResponse) (*DocGenerationResponse, error) {
+	for len(progress.Remaining) > 0 {
+		files := progress.Remaining[0]
+		progress.Part = progress.Total - len(progress.Remaining)
+		progress.Label = fmt.Sprintf("Part %d of %d (%s)", progress.Part+1, progress.Total, chunkDirs(files))
+		progress.Remaining = progress.Remaining[1:]
+		o.setBootstrapProgress(progress)
+		events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("GenerateDocs: documenting %s, %d files", progress.Label, len(files))))
+
+		part := *req
+		part.ChangedFiles = files
+		part.chunk = &bootstrapChunk{
+			index:      progress.Part,
+			total:      progress.Total,
+			totalFiles: progress.TotalFiles,
+			previous:   strings.TrimSpace(progress.Carried),
+		}
+		resp, err := o.generateDocsRun(ctx, &part)
+		if err != nil {
+			return nil, err
+		}
+		progress.record(resp.Summary)
+		last = resp
+	}
+	o.setBootstrapProgress(nil)
+	merged := &DocGenerationResponse{Summary: strings.Join(progress.Summaries, "\n\n")}
+	if last != nil {
+		merged.Structured = last.Structured
+	}
+	return merged, nil
+}
+
+// ContinueBootstrap finishes a chunked full bootstrap restored with
+// RestoreBootstrapProgressJSON: resumed is the response of the run that
+// completed the interrupted part, and the parts after it are then documented
+// in turn. req supplies the paths and branches; the files, commit and
+// instructions come from the saved progress. Without restored progress it
+// returns resumed unchanged.
+func (o *LLMClient) ContinueBootstrap(ctx context.Context, req *DocGenerationRequest, resumed *DocGenerationResponse) (*DocGenerationResponse, error) {
+	o.mu.Lock()
+	progress := o.bootstrap
+	o.mu.Unlock()
+	if progress == nil {
+		return resumed, nil
+	}
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	summary := ""
+	if resumed != nil {
+		summary = resumed.Summary
+	}
+	next := *progress
+	next.Summaries = append([]string(nil), progress.Summaries...)
+	next.record(summary)
+
+	part := *req
+	part.FullBootstrap = true
+	part.SourceCommit = next.SourceCommit
+	part.SourceCommitSummary = next.SourceCommitSummary
+	part.SpecificInstr = next.SpecificInstr
+	return o.documentBootstrapParts(ctx, &part, &next, resumed)
+}
+
+// BootstrapPending reports whether a chunked full bootstrap has parts left
+// after the one running or interrupted.
+func (o *LLMClient) BootstrapPending() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.bootstrap != nil && len(o.bootstrap.Remaining) > 0
+}
+
+// BootstrapProgressJSON returns the progress of the chunked full bootstrap
+// the client is running, or an empty string when it is not running one.
+func (o *LLMClient) BootstrapProgressJSON() (string, error) {
+	o.mu.Lock()
+	progress := o.bootstrap
+	o.mu.Unlock()
+	if progress == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RestoreBootstrapProgressJSON restores progress captured by
+// BootstrapProgressJSON, so ContinueBootstrap documents the parts it has
+// left. It must be called after StartStream, which clears any progress.
+func (o *LLMClient) RestoreBootstrapProgressJSON(jsonStr string) error {
+	if strings.TrimSpace(jsonStr) == "" {
+		return nil
+	}
+	var progress BootstrapProgress
+	if err := json.Unmarshal([]byte(jsonStr), &progress); err != nil {
+		return err
+	}
+	o.setBootstrapProgress(&progress)
+	return nil
+}
+
+// setBootstrapProgress stores a copy of progress, or clears it when nil.
+func (o *LLMClient) setBootstrapProgress(progress *BootstrapProgress) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if progress == nil {
+		o.bootstrap = nil
+		return
+	}
+	copied := *progress
+	copied.Summaries = append([]string(nil), progress.Summaries...)
+	o.bootstrap = &copied
+}
+
+// chunkDirs lists the top-level directories of files in order of appearance.
+func chunkDirs(files []ChangedFile) string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		dir := topLevelDir(f.Path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, ", ")
+}
+
+// writeBootstrapProgress tells the agent which part of a chunked full
+// bootstrap it is documenting and what the earlier parts did. Nothing is
+// written for a bootstrap documented in one run.
+func writeBootstrapProgress(b *strings.Builder, chunk *bootstrapChunk) {
+	if chunk == nil {
+		return
+	}
+	b.WriteString("# Bootstrap Progress\n")
+	fmt.Fprintf(b, "This is part %d of %d of a full bootstrap of %d code files. ", chunk.index+1, chunk.total, chunk.totalFiles)
+	b.WriteString("Document the files listed under \"Codebase Files\"; the other parts are covered by separate runs.\n")
+	if chunk.previous != "" {
+		b.WriteString("\nThe earlier parts reported:\n<previous_parts>\n")
+		b.WriteString(chunk.previous)
+		b.WriteString("\n</previous_parts>\n")
+		b.WriteString("Extend the pages they wrote where this part belongs in them rather than duplicating them.\n")
+	}
+	b.WriteString("\n")
+}
@@ -9,13 +9,13 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
 	"narrabyte/internal/events"
 	"narrabyte/internal/llm/tools"
 	"narrabyte/internal/utils"
 	"os"
 	"path/filepath"
 	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -38,6 +38,9 @@ import (
 
 const llmInstructionsNamePrefix = "llm_instructions"
 
+// llmInstructionsSeparator joins the contents of multiple instruction files.
+const llmInstructionsSeparator = "\n\n---\n\n"
+
 type promptBuilderConfig struct {
 	ProjectName   string
 	DocRoot       string
@@ -47,6 +50,11 @@ type promptBuilderConfig struct {
 	ProjectInstr  string
 	SpecificInstr string
 	ExtraContext  map[string]string // For additional sections like "Source branch", "Changed Files", etc.
+	// SourceBranch and ChangedFiles feed the {{source_branch}} and {{changed_files}} instruction variables
+	SourceBranch string
+	ChangedFiles []ChangedFile
+	// Sections leaves optional sections out and bounds the listings
+	Sections PromptSections
 }
 
 // buildPromptWithInstructions constructs a prompt with common sections for documentation tasks
@@ -62,12 +70,13 @@ func buildPromptWithInstructions(ctx context.Context, cfg promptBuilderConfig) s
 	}
 
 	if strings.TrimSpace(cfg.SpecificInstr) != "" {
+		specificInstr := expandInstructionVars(cfg.SpecificInstr, instructionVars(cfg))
 		b.WriteString("# Generation-specific documentation instructions\n")
-		if strings.Contains(cfg.SpecificInstr, "<DOCUMENTATION_TEMPLATE>") ||
-			strings.Contains(cfg.SpecificInstr, "<USER_INSTRUCTIONS>") {
+		if strings.Contains(specificInstr, "<DOCUMENTATION_TEMPLATE>") ||
+			strings.Contains(specificInstr, "<USER_INSTRUCTIONS>") {
 			b.WriteString("The following tagged sections encode user guidance. Apply their content, but do not echo the tags or repeat them in your documentation output.\n\n")
 		}
-		b.WriteString(strings.TrimSpace(cfg.SpecificInstr))
+		b.WriteString(strings.TrimSpace(specificInstr))
 		b.WriteString("\n\n")
 		events.Emit(ctx, events.LLMEventTool, events.NewInfo("added specific instructions"))
 	}
@@ -78,6 +87,9 @@ func buildPromptWithInstructions(ctx context.Context, cfg promptBuilderConfig) s
 
 	// Add extra context fields if provided
 	for key, value := range cfg.ExtraContext {
+		if key == sourceCommitContextKey && cfg.Sections.OmitCommitContext {
+			continue
+		}
 		if strings.TrimSpace(value) != "" {
 			b.WriteString(fmt.Sprintf("%s: %s\n", key, strings.TrimSpace(value)))
 		}
@@ -86,16 +98,23 @@ func buildPromptWithInstructions(ctx context.Context, cfg promptBuilderConfig) s
 	b.WriteString(fmt.Sprintf("Documentation repository root: %s\n", filepath.ToSlash(cfg.DocRoot)))
 	b.WriteString(fmt.Sprintf("Codebase repository root: %s\n\n", filepath.ToSlash(cfg.CodeRoot)))
 
-	// Section 3: Repository Structure
+	// Section 3: Repository Structure, unless both listings are omitted
+	if cfg.Sections.OmitDocsListing && cfg.Sections.OmitCodeListing {
+		return b.String()
+	}
 	b.WriteString("# Repository Structure\n\n")
-	b.WriteString("## Documentation Repository\n")
-	b.WriteString("<documentation_repo_listing>\n")
-	b.WriteString(cfg.DocListing)
-	b.WriteString("\n</documentation_repo_listing>\n\n")
-	b.WriteString("## Codebase Repository\n")
-	b.WriteString("<codebase_repo_listing>\n")
-	b.WriteString(cfg.CodeListing)
-	b.WriteString("\n</codebase_repo_listing>\n\n")
+	if !cfg.Sections.OmitDocsListing {
+		b.WriteString("## Documentation Repository\n")
+		b.WriteString("<documentation_repo_listing>\n")
+		b.WriteString(truncateListing(cfg.DocListing, cfg.Sections.MaxListingChars))
+		b.WriteString("\n</documentation_repo_listing>\n\n")
+	}
+	if !cfg.Sections.OmitCodeListing {
+		b.WriteString("## Codebase Repository\n")
+		b.WriteString("<codebase_repo_listing>\n")
+		b.WriteString(truncateListing(cfg.CodeListing, cfg.Sections.MaxListingChars))
+		b.WriteString("\n</codebase_repo_listing>\n\n")
+	}
 
 	return b.String()
 }
@@ -117,9 +136,57 @@ type LLMClient struct {
 	codeSnapshot    *tools.GitSnapshot
 	sessionKey      string
 	workspaceID     string
+	// publishedDocsSearch builds a snapshot of the docs branch head so grep can
+	// search the published docs separately from the run's in-progress edits
+	publishedDocsSearch bool
+	docsSnapshot        *tools.GitSnapshot
+	// maxFileSize caps files written by the write and edit tools; 0 means
+	// tools.DefaultMaxFileSize
+	maxFileSize int64
+	// planMode swaps the write, edit and delete tools for plan variants that
+	// only record the intended change
+	planMode bool
+	// allowedTools, when set, are the only tools registered for a run; see
+	// SetAllowedTools
+	allowedTools []string
+	// finalSummaryTurn asks the model for an explicit summary when a run ends
+	// without a substantive one; see SetFinalSummaryTurn
+	finalSummaryTurn bool
+	// existingDocsChars bounds the existing pages GenerateDocs includes as
+	// context; 0 disables it. See SetExistingDocsContext
+	existingDocsChars int
+	// structuredSummary requests a StructuredSummary at the end of each run;
+	// see OpenAIModelOptions.StructuredSummary
+	structuredSummary bool
+	// allowedFiles, when set, are the only absolute paths the write, edit and
+	// delete tools may change; see DocRefineRequest.AllowedFiles
+	allowedFiles []string
+	// showReasoning gates the reasoning events sent to the UI. How it combines
+	// with the model's own thinking settings depends on the provider:
+	//   - Claude: thinking follows ReasoningEffort; showReasoning turns it on
+	//     for "low" effort, and Thinking=false disables it regardless.
+	//   - Gemini: showReasoning sets IncludeThoughts and, like Claude, gives
+	//     "low" effort a thinking budget unless Thinking=false.
+	//   - OpenAI: models always reason; showReasoning only requests summaries.
+	//   - OpenAI-compatible: reasoning is shown only if the endpoint returns it.
+	showReasoning bool
+	// budget limits the reasoning tokens of a run; see SetReasoningBudget
+	budget reasoningBudget
+	// compaction controls when refinement history is summarized; see SetHistoryCompaction
+	compaction HistoryCompaction
+	// promptSections selects the optional prompt sections; see SetPromptSections
+	promptSections PromptSections
+	// pingOptions cap the output of Ping's request and turn thinking off, so
+	// validating a key stays quick and cheap
+	pingOptions []model.Option
+	// pingModel, when set, replaces Ping's request with a lookup of the model
+	pingModel func(ctx context.Context) error
 
 	mu                    sync.Mutex
 	running               bool
+	paused                bool
+	canceled              bool // set by CancelStream; see IsCanceled
+	bootstrap             *BootstrapProgress
 	cancel                context.CancelFunc
 	conversationHistoryMu sync.Mutex
 	conversationHistory   []adk.Message // Store conversation for context in refinement
@@ -134,9 +201,203 @@ type DocGenerationRequest struct {
 	SourceBranch         string
 	TargetBranch         string
 	SourceCommit         string
-	Diff                 string
-	ChangedFiles         []string
-	SpecificInstr        string
+	// SourceCommitSummary describes SourceCommit in one line, such as its
+	// subject, author and date; it is shown next to the hash when set
+	SourceCommitSummary string
+	Diff                string
+	ChangedFiles        []ChangedFile
+	// CommitHistory lists the commits between TargetBranch and SourceBranch,
+	// newest first, so the model can see why the code changed
+	CommitHistory []CommitMessage
+	SpecificInstr string
+	// FullBootstrap documents the whole codebase instead of a diff: Diff is
+	// empty and ChangedFiles lists every code file in scope
+	FullBootstrap bool
+	// Worktree marks Diff as the uncommitted changes in the working tree on
+	// top of SourceCommit rather than a diff between two branches
+	Worktree bool
+	// chunk is the part of a full bootstrap this request documents; nil when
+	// the whole request is documented in one run
+	chunk *bootstrapChunk
+}
+
+// ChangedFile is a code file touched by the diff being documented. Status is
+// "added", "modified", "deleted" or "renamed"; OldPath is only set for renames.
+type ChangedFile struct {
+	Path    string
+	OldPath string
+	Status  string
+}
+
+// CommitMessage is a commit from the range being documented.
+type CommitMessage struct {
+	Hash    string
+	Author  string
+	Message string
+}
+
+// commitHistoryMaxMessageChars bounds each message in the "Commit History"
+// prompt section so a long body cannot crowd out the diff.
+const commitHistoryMaxMessageChars = 500
+
+// formatCommitHistory renders commits as the bullet list of the "Commit
+// History" prompt section, with message bodies indented under their subject.
+func formatCommitHistory(commits []CommitMessage) string {
+	var b strings.Builder
+	for _, c := range commits {
+		message := strings.TrimSpace(c.Message)
+		if runes := []rune(message); len(runes) > commitHistoryMaxMessageChars {
+			message = strings.TrimSpace(string(runes[:commitHistoryMaxMessageChars])) + "..."
+		}
+		subject, body, _ := strings.Cut(message, "\n")
+		hash := c.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		fmt.Fprintf(&b, "- %s %s", hash, strings.TrimSpace(subject))
+		if author := strings.TrimSpace(c.Author); author != "" {
+			fmt.Fprintf(&b, " (%s)", author)
+		}
+		b.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				b.WriteString("  ")
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// formatChangedFiles renders files as the bullet list of the "Changed Files"
+// prompt section, spelling out deletions and renames.
+func formatChangedFiles(files []ChangedFile) string {
+	if len(files) == 0 {
+		return "(none)"
+	}
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString("- ")
+		b.WriteString(filepath.ToSlash(f.Path))
+		switch f.Status {
+		case "added":
+			b.WriteString(" (added)")
+		case "deleted":
+			b.WriteString(" (deleted)")
+		case "renamed":
+			fmt.Fprintf(&b, " (renamed from %s)", filepath.ToSlash(f.OldPath))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// fullBootstrapGroupSize bounds the files in one group of a full bootstrap
+// listing, and fullBootstrapChunkFiles the files one run documents, so each
+// prompt stays within context limits on large repositories.
+const (
+	fullBootstrapGroupSize  = 40
+	fullBootstrapChunkFiles = 200
+)
+
+// groupCodeFiles splits files into groups by top-level directory, breaking up
+// directories with more than size files, so a full bootstrap can be
+// documented one group at a time.
+func groupCodeFiles(files []ChangedFile, size int) [][]ChangedFile {
+	if size <= 0 {
+		size = fullBootstrapGroupSize
+	}
+	sorted := append([]ChangedFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return filepath.ToSlash(sorted[i].Path) < filepath.ToSlash(sorted[j].Path)
+	})
+	var groups [][]ChangedFile
+	lastDir := ""
+	for _, f := range sorted {
+		dir := topLevelDir(f.Path)
+		if len(groups) == 0 || dir != lastDir || len(groups[len(groups)-1]) >= size {
+			groups = append(groups, nil)
+			lastDir = dir
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], f)
+	}
+	return groups
+}
+
+func topLevelDir(path string) string {
+	dir, _, nested := strings.Cut(filepath.ToSlash(path), "/")
+	if !nested {
+		return "."
+	}
+	return dir
+}
+
+// formatFileGroups renders the "Codebase Files" prompt section of a full
+// bootstrap.
+func formatFileGroups(files []ChangedFile, size int) string {
+	if len(files) == 0 {
+		return "(none)"
+	}
+	var b strings.Builder
+	for i, group := range groupCodeFiles(files, size) {
+		fmt.Fprintf(&b, "## Group %d: %s (%d files)\n", i+1, topLevelDir(group[0].Path), len(group))
+		for _, f := range group {
+			b.WriteString("- ")
+			b.WriteString(filepath.ToSlash(f.Path))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// docGenerationContext returns the branch and commit details of req shown in
+// the generation prompt.
+func docGenerationContext(req *DocGenerationRequest) map[string]string {
+	extraContext := map[string]string{
+		"Source branch": req.SourceBranch,
+		"Target branch": req.TargetBranch,
+	}
+	if req.FullBootstrap {
+		// There is no comparison branch when the whole codebase is documented
+		delete(extraContext, "Target branch")
+	}
+	if req.Worktree {
+		delete(extraContext, "Target branch")
+		extraContext["Code changes"] = "uncommitted changes in the working tree, not yet committed to the source branch"
+	}
+	if commit := strings.TrimSpace(req.SourceCommit); commit != "" {
+		if summary := strings.TrimSpace(req.SourceCommitSummary); summary != "" {
+			commit = fmt.Sprintf("%s (%s)", commit, summary)
+		}
+		extraContext["Source commit"] = commit
+	}
+	return extraContext
+}
+
+// writeCodeContext appends the sections describing the code to document:
+// the changed files and diff, or the grouped file listing of a full bootstrap.
+// The commit history is left out when sections omits the commit context.
+func writeCodeContext(b *strings.Builder, req *DocGenerationRequest, sections PromptSections) {
+	if req.FullBootstrap {
+		writeBootstrapProgress(b, req.chunk)
+		b.WriteString("# Codebase Files\n")
+		b.WriteString(formatFileGroups(req.ChangedFiles, fullBootstrapGroupSize))
+		return
+	}
+	if len(req.CommitHistory) > 0 && !sections.OmitCommitContext {
+		b.WriteString("# Commit History\n")
+		b.WriteString(formatCommitHistory(req.CommitHistory))
+		b.WriteString("\n\n")
+	}
+	b.WriteString("# Changed Files\n")
+	b.WriteString(formatChangedFiles(req.ChangedFiles))
+	b.WriteString("\n\n")
+	b.WriteString("# Code Changes\n")
+	b.WriteString("<git_diff>\n")
+	b.WriteString(req.Diff)
+	b.WriteString("\n</git_diff>")
 }
 
 type DocRefineRequest struct {
@@ -147,10 +408,18 @@ type DocRefineRequest struct {
 	SourceBranch         string
 	Instruction          string
 	SpecificInstr        string
+	// AllowedFiles, when set, lists the only files (relative to the
+	// documentation path) the run may write, edit or delete
+	AllowedFiles []string
 }
 
 type DocGenerationResponse struct {
 	Summary string
+	// Structured is the structured summary the run ended with, when the client
+	// requests one and the model returned it; Summary holds its text
+	Structured *StructuredSummary
+	// Plan lists the changes a PlanDocs run proposes; empty for regular runs
+	Plan []tools.PlannedChange
 }
 
 type docSessionResources struct {
@@ -164,24 +433,55 @@ type docSessionResources struct {
 type OpenAIModelOptions struct {
 	Model           string
 	ReasoningEffort string
+	ShowReasoning   bool
+	// StructuredSummary ends each run by asking for a StructuredSummary
+	// through structured outputs, falling back to the free-text summary when
+	// the model does not support them or its reply cannot be parsed
+	StructuredSummary bool
+}
+
+// OpenAICompatibleModelOptions configures a client for a third-party or
+// self-hosted endpoint that implements the OpenAI Chat Completions API.
+type OpenAICompatibleModelOptions struct {
+	Model         string
+	BaseURL       string
+	ShowReasoning bool
 }
 
 type ClaudeModelOptions struct {
 	Model           string
 	ReasoningEffort string
+	Thinking        *bool
+	ShowReasoning   bool
 }
 
 type GeminiModelOptions struct {
 	Model           string
 	ReasoningEffort string
+	Thinking        *bool
+	ShowReasoning   bool
+	// SafetySettings set Gemini's content filter per harm category. Nil uses
+	// DefaultGeminiSafetySettings; an empty slice sends none, leaving every
+	// category at Gemini's own, stricter default.
+	SafetySettings []*genai.SafetySetting
+	// SystemInstruction, when set, is added to the system instruction of every
+	// request the model receives, after the run's own system prompt.
+	SystemInstruction string
 }
 
+// minThinkingBudgetTokens is the smallest budget Claude accepts, and is used
+// for both Claude and Gemini when thinking is switched on for "low" effort.
+const minThinkingBudgetTokens = 1024
+
+// Streamed reasoning and assistant text are sent as a reset event followed by
+// update events that each carry the whole text so far, tagged with these keys.
 const (
-	reasoningMetadataStreamKey  = "stream"
-	reasoningMetadataStreamName = "reasoning"
-	reasoningMetadataStateKey   = "state"
-	reasoningMetadataReset      = "reset"
-	reasoningMetadataUpdate     = "update"
+	streamMetadataKey      = "stream"
+	streamNameReasoning    = "reasoning"
+	streamNameContent      = "content"
+	streamMetadataStateKey = "state"
+	streamMetadataReset    = "reset"
+	streamMetadataUpdate   = "update"
 )
 
 func NewOpenAIClient(ctx context.Context, key string, opts OpenAIModelOptions) (*LLMClient, error) {
@@ -189,18 +489,62 @@ func NewOpenAIClient(ctx context.Context, key string, opts OpenAIModelOptions) (
 	if modelName == "" {
 		modelName = "gpt-5.5"
 	}
+	reasoning := openAIResponsesReasoning(opts.ReasoningEffort)
+	if !opts.ShowReasoning {
+		// Summaries are only useful for display; the model reasons either way
+		reasoning.Summary = ""
+	}
 	agenticModel, err := agenticopenai.NewResponsesModel(ctx, &agenticopenai.ResponsesConfig{
 		APIKey:    key,
 		Model:     modelName,
-		Reasoning: openAIResponsesReasoning(opts.ReasoningEffort),
+		Reasoning: reasoning,
 	})
 
 	if err != nil {
-		log.Printf("Error creating OpenAI client: %v", err)
+		events.LoggerFrom(ctx).Redacting(key).Errorf("Error creating OpenAI client: %v", err)
 		return nil, err
 	}
 
-	return &LLMClient{agenticModel: agenticModel, Key: key}, err
+	return &LLMClient{
+		agenticModel:      agenticModel,
+		Key:               key,
+		showReasoning:     opts.ShowReasoning,
+		structuredSummary: opts.StructuredSummary,
+		pingOptions: []model.Option{
+			model.WithMaxTokens(pingMaxTokens),
+			agenticopenai.WithResponsesReasoning(&responses.ReasoningParam{Effort: responses.ReasoningEffortLow}),
+		},
+	}, err
+}
+
+// NewOpenAICompatibleClient targets Chat Completions rather than the Responses
+// API, since that is what gateways such as LiteLLM, vLLM and OpenRouter implement.
+func NewOpenAICompatibleClient(ctx context.Context, key string, opts OpenAICompatibleModelOptions) (*LLMClient, error) {
+	modelName := strings.TrimSpace(opts.Model)
+	if modelName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+	baseURL := strings.TrimSpace(opts.BaseURL)
+	if baseURL == "" {
+		return nil, fmt.Errorf("base URL is required")
+	}
+	agenticModel, err := agenticopenai.NewChatModel(ctx, &agenticopenai.ChatConfig{
+		APIKey:  key,
+		BaseURL: baseURL,
+		Model:   modelName,
+	})
+
+	if err != nil {
+		events.LoggerFrom(ctx).Redacting(key).Errorf("Error creating OpenAI-compatible client: %v", err)
+		return nil, err
+	}
+
+	return &LLMClient{
+		agenticModel:  agenticModel,
+		Key:           key,
+		showReasoning: opts.ShowReasoning,
+		pingOptions:   []model.Option{model.WithMaxTokens(pingMaxTokens)},
+	}, err
 }
 
 func NewClaudeClient(ctx context.Context, key string, opts ClaudeModelOptions) (*LLMClient, error) {
@@ -208,7 +552,7 @@ func NewClaudeClient(ctx context.Context, key string, opts ClaudeModelOptions) (
 	if modelName == "" {
 		modelName = "claude-sonnet-5"
 	}
-	thinking := claudeThinkingForEffort(opts.ReasoningEffort)
+	thinking := claudeThinking(opts.ReasoningEffort, opts.Thinking, opts.ShowReasoning)
 	chatModel, err := claude.NewChatModel(ctx, &claude.Config{
 		APIKey:    key,
 		Model:     modelName,
@@ -217,11 +561,15 @@ func NewClaudeClient(ctx context.Context, key string, opts ClaudeModelOptions) (
 	})
 
 	if err != nil {
-		log.Printf("Error creating Claude client: %v", err)
+		events.LoggerFrom(ctx).Redacting(key).Errorf("Error creating Claude client: %v", err)
 		return nil, err
 	}
 
-	return &LLMClient{chatModel: chatModel, Key: key}, err
+	c := &LLMClient{Key: key, showReasoning: opts.ShowReasoning}
+	c.budget.disableOptions = []model.Option{claude.WithThinking(&claude.Thinking{Enable: false})}
+	c.pingOptions = []model.Option{model.WithMaxTokens(pingMaxTokens), claude.WithThinking(&claude.Thinking{Enable: false})}
+	c.chatModel = newBudgetedChatModel(chatModel, &c.budget)
+	return c, err
 }
 
 func NewGeminiClient(ctx context.Context, key string, opts GeminiModelOptions) (*LLMClient, error) {
@@ -230,7 +578,7 @@ func NewGeminiClient(ctx context.Context, key string, opts GeminiModelOptions) (
 	})
 
 	if err != nil {
-		log.Printf("Error creating Gemini client: %v", err)
+		events.LoggerFrom(ctx).Redacting(key).Errorf("Error creating Gemini client: %v", err)
 		return nil, err
 	}
 
@@ -238,7 +586,11 @@ func NewGeminiClient(ctx context.Context, key string, opts GeminiModelOptions) (
 	if modelName == "" {
 		modelName = "gemini-3.5-flash"
 	}
-	thinkingBudget, includeThoughts := geminiThinkingForEffort(opts.ReasoningEffort)
+	thinkingBudget, includeThoughts := geminiThinking(opts.ReasoningEffort, opts.Thinking, opts.ShowReasoning)
+	safetySettings := opts.SafetySettings
+	if safetySettings == nil {
+		safetySettings = DefaultGeminiSafetySettings()
+	}
 	chatModel, err := gemini.NewChatModel(ctx, &gemini.Config{
 		Client: genaiClient,
 		Model:  modelName,
@@ -246,14 +598,25 @@ func NewGeminiClient(ctx context.Context, key string, opts GeminiModelOptions) (
 			IncludeThoughts: includeThoughts,
 			ThinkingBudget:  thinkingBudget,
 		},
+		SafetySettings: safetySettings,
 	})
 
 	if err != nil {
-		log.Printf("Error creating Gemini client: %v", err)
+		events.LoggerFrom(ctx).Redacting(key).Errorf("Error creating Gemini client: %v", err)
 		return nil, err
 	}
 
-	return &LLMClient{chatModel: chatModel, Key: key}, err
+	c := &LLMClient{Key: key, showReasoning: opts.ShowReasoning}
+	// Some Gemini models cannot turn thinking off, so the key is checked by
+	// looking the model up rather than by a completion
+	c.pingModel = func(ctx context.Context) error {
+		_, err := genaiClient.Models.Get(ctx, modelName, nil)
+		return err
+	}
+	zero := int32(0)
+	c.budget.disableOptions = []model.Option{gemini.WithThinkingConfig(&genai.ThinkingConfig{ThinkingBudget: &zero})}
+	c.chatModel = newBudgetedChatModel(withGeminiSystemInstruction(chatModel, opts.SystemInstruction), &c.budget)
+	return c, err
 }
 
 func claudeThinkingForEffort(effort string) *claude.Thinking {
@@ -267,6 +630,19 @@ func claudeThinkingForEffort(effort string) *claude.Thinking {
 	}
 }
 
+// claudeThinking applies the model's Thinking flag and the ShowReasoning toggle
+// on top of the effort-based default. An explicit Thinking=false always wins.
+func claudeThinking(effort string, thinking *bool, showReasoning bool) *claude.Thinking {
+	if thinking != nil && !*thinking {
+		return &claude.Thinking{Enable: false}
+	}
+	cfg := claudeThinkingForEffort(effort)
+	if !cfg.Enable && (showReasoning || thinking != nil) {
+		return &claude.Thinking{Enable: true, BudgetTokens: minThinkingBudgetTokens}
+	}
+	return cfg
+}
+
 func geminiThinkingForEffort(effort string) (*int32, bool) {
 	switch strings.ToLower(strings.TrimSpace(effort)) {
 	case "low":
@@ -280,6 +656,21 @@ func geminiThinkingForEffort(effort string) (*int32, bool) {
 	}
 }
 
+// geminiThinking returns the thinking budget and IncludeThoughts setting for a
+// Gemini model. Thoughts are only requested when they will be shown.
+func geminiThinking(effort string, thinking *bool, showReasoning bool) (*int32, bool) {
+	if thinking != nil && !*thinking {
+		zero := int32(0)
+		return &zero, false
+	}
+	budget, _ := geminiThinkingForEffort(effort)
+	if budget != nil && *budget == 0 && (showReasoning || thinking != nil) {
+		minimum := int32(minThinkingBudgetTokens)
+		budget = &minimum
+	}
+	return budget, showReasoning
+}
+
 func openAIResponsesReasoning(effort string) *responses.ReasoningParam {
 	var reasoningEffort responses.ReasoningEffort
 	switch strings.ToLower(strings.TrimSpace(effort)) {
@@ -296,6 +687,35 @@ func openAIResponsesReasoning(effort string) *responses.ReasoningParam {
 	}
 }
 
+const pingPrompt = "Reply with the single word: ok"
+
+// pingMaxTokens caps the reply to Ping's request; 16 is the smallest output
+// limit the OpenAI Responses API accepts.
+const pingMaxTokens = 16
+
+// Ping performs a minimal request against the configured provider to confirm
+// that the API key and model name are accepted: a completion capped at
+// pingMaxTokens with thinking off, or for Gemini a lookup of the model. The
+// response content is ignored; only transport and authentication failures are
+// reported.
+func (o *LLMClient) Ping(ctx context.Context) error {
+	if o == nil {
+		return fmt.Errorf("llm client is nil")
+	}
+	if o.pingModel != nil {
+		return o.pingModel(ctx)
+	}
+	if o.usesAgenticModel() {
+		_, err := o.agenticModel.Generate(ctx, []*schema.AgenticMessage{schema.UserAgenticMessage(pingPrompt)}, o.pingOptions...)
+		return err
+	}
+	if o.chatModel == nil {
+		return fmt.Errorf("llm client has no model configured")
+	}
+	_, err := o.chatModel.Generate(ctx, []*schema.Message{schema.UserMessage(pingPrompt)}, o.pingOptions...)
+	return err
+}
+
 func (o *LLMClient) usesAgenticModel() bool {
 	return o != nil && o.agenticModel != nil
 }
@@ -372,6 +792,9 @@ func (o *LLMClient) StartStream(ctx context.Context, sessionKey string) context.
 		return ctx
 	}
 	o.running = true
+	o.paused = false
+	o.canceled = false
+	o.bootstrap = nil
 	sessionKey = strings.TrimSpace(sessionKey)
 	o.sessionKey = sessionKey
 	workspaceID := generateSessionID()
@@ -380,6 +803,8 @@ func (o *LLMClient) StartStream(ctx context.Context, sessionKey string) context.
 	if sessionKey != "" {
 		ctx = events.WithSession(ctx, sessionKey)
 	}
+	// Provider errors can echo the request, so the run never logs the API key
+	ctx = events.WithLogger(ctx, events.LoggerFrom(ctx).Redacting(o.Key))
 	ctx = tools.ContextWithSession(ctx, workspaceID)
 	o.cancel = cancel
 	o.mu.Unlock()
@@ -400,9 +825,102 @@ func (o *LLMClient) StopStream() {
 		cancel()
 	}
 	o.running = false
+	o.paused = false
+	o.canceled = false
 	o.cancel = nil
 }
 
+// PauseStream cancels the in-flight run like StopStream, but keeps the todo
+// list of the tools session and flags the run as paused so the partial
+// conversation history is retained when the run unwinds. The workspace roots
+// are released right away, so tool calls outside a session are not refused
+// while the paused run waits to be resumed. Returns false when no run is
+// active.
+func (o *LLMClient) PauseStream() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.running {
+		return false
+	}
+	o.paused = true
+	tools.ReleaseSessionRoots(o.workspaceID)
+	if o.cancel != nil {
+		o.cancel()
+	}
+	return true
+}
+
+// IsPaused reports whether the current run was interrupted by PauseStream.
+func (o *LLMClient) IsPaused() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.paused
+}
+
+// CancelStream cancels the in-flight run like PauseStream, keeping its todo
+// list so the partial history and todo list can be saved when the run
+// unwinds, but flags it as canceled by the user rather than paused. Returns
+// false when no run is active.
+func (o *LLMClient) CancelStream() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.running {
+		return false
+	}
+	o.canceled = true
+	tools.ReleaseSessionRoots(o.workspaceID)
+	if o.cancel != nil {
+		o.cancel()
+	}
+	return true
+}
+
+// IsCanceled reports whether the current run was interrupted by CancelStream.
+func (o *LLMClient) IsCanceled() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.canceled
+}
+
+// TodosJSON returns the todo list of the active tools session as JSON.
+func (o *LLMClient) TodosJSON() (string, error) {
+	o.mu.Lock()
+	workspaceID := o.workspaceID
+	o.mu.Unlock()
+	if workspaceID == "" {
+		return "", nil
+	}
+	todos := tools.GetTodoSession(workspaceID).GetTodos()
+	if len(todos) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(todos)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RestoreTodosJSON seeds the active tools session with a todo list previously
+// captured by TodosJSON. It must be called after StartStream.
+func (o *LLMClient) RestoreTodosJSON(jsonStr string) error {
+	if strings.TrimSpace(jsonStr) == "" {
+		return nil
+	}
+	o.mu.Lock()
+	workspaceID := o.workspaceID
+	o.mu.Unlock()
+	if workspaceID == "" {
+		return fmt.Errorf("no active session to restore todos into")
+	}
+	var todos []tools.Todo
+	if err := json.Unmarshal([]byte(jsonStr), &todos); err != nil {
+		return err
+	}
+	tools.GetTodoSession(workspaceID).UpdateTodos(todos)
+	return nil
+}
+
 func generateSessionID() string {
 	var buf [8]byte
 	if _, err := rand.Read(buf[:]); err == nil {
@@ -532,7 +1050,7 @@ func (o *LLMClient) prepareDocResources(ctx context.Context, docRoot string, cod
 		return nil, err
 	}
 
-	projectInstr, repoErr := o.loadRepoLLMInstructions(docRoot)
+	projectInstr, repoErr := o.loadRepoLLMInstructions(ctx, docRoot)
 
 	return &docSessionResources{
 		docListing:      docListing,
@@ -543,15 +1061,29 @@ func (o *LLMClient) prepareDocResources(ctx context.Context, docRoot string, cod
 	}, nil
 }
 
+// GenerateDocs documents the code changes of req. A full bootstrap over more
+// files than one run can cover is documented in parts; see generateDocsChunked.
 func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest) (*DocGenerationResponse, error) {
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo("GenerateDocs: initializing"))
-
-	// Clear any existing conversation history to start fresh
-	o.ClearConversationHistory()
+	tools.ResetRunStats(ctx)
+	o.resetReasoningBudget()
 
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
 	}
+	if req.FullBootstrap {
+		if chunks := bootstrapChunks(req.ChangedFiles, fullBootstrapGroupSize, fullBootstrapChunkFiles); len(chunks) > 1 {
+			return o.generateDocsChunked(ctx, req, chunks)
+		}
+	}
+	return o.generateDocsRun(ctx, req)
+}
+
+// generateDocsRun runs the agent once over req.
+func (o *LLMClient) generateDocsRun(ctx context.Context, req *DocGenerationRequest) (*DocGenerationResponse, error) {
+	// Clear any existing conversation history to start fresh
+	o.ClearConversationHistory()
+
 	if strings.TrimSpace(req.DocumentationPath) == "" {
 		return nil, fmt.Errorf("documentation path is required")
 	}
@@ -573,6 +1105,23 @@ func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest)
 		return nil, fmt.Errorf("failed to load system instructions: %w", err)
 	}
 
+	if o.planMode {
+		planInstr, err := o.loadPrompt("plan_docs.txt")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plan instructions: %w", err)
+		}
+		systemInstr += planInstr
+	}
+
+	if req.FullBootstrap {
+		bootstrapInstr, err := o.loadPrompt("bootstrap_docs.txt")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bootstrap instructions: %w", err)
+		}
+		systemInstr += bootstrapInstr
+	}
+	systemInstr += o.disabledToolsInstructions()
+
 	if resources.projectInstrErr != nil {
 		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("unable to load repo LLM instructions: %v", resources.projectInstrErr)))
 	}
@@ -598,24 +1147,7 @@ func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest)
 		return nil, err
 	}
 
-	changedList := "(none)"
-	if len(req.ChangedFiles) > 0 {
-		var b strings.Builder
-		for _, f := range req.ChangedFiles {
-			b.WriteString("- ")
-			b.WriteString(filepath.ToSlash(f))
-			b.WriteString("\n")
-		}
-		changedList = strings.TrimSpace(b.String())
-	}
-
-	extraContext := map[string]string{
-		"Source branch": req.SourceBranch,
-		"Target branch": req.TargetBranch,
-	}
-	if commit := strings.TrimSpace(req.SourceCommit); commit != "" {
-		extraContext["Source commit"] = commit
-	}
+	extraContext := docGenerationContext(req)
 
 	prompt := buildPromptWithInstructions(ctx, promptBuilderConfig{
 		ProjectName:   req.ProjectName,
@@ -626,25 +1158,21 @@ func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest)
 		ProjectInstr:  resources.projectInstr,
 		SpecificInstr: req.SpecificInstr,
 		ExtraContext:  extraContext,
+		SourceBranch:  req.SourceBranch,
+		ChangedFiles:  req.ChangedFiles,
+		Sections:      o.promptSections,
 	})
 
 	var promptBuilder strings.Builder
 	promptBuilder.WriteString(prompt)
 
-	// Section 4: Changed Files
-	promptBuilder.WriteString("# Changed Files\n")
-	promptBuilder.WriteString(changedList)
-	promptBuilder.WriteString("\n\n")
-
-	// Section 5: Code Changes
-	promptBuilder.WriteString("# Code Changes\n")
-	promptBuilder.WriteString("<git_diff>\n")
-	promptBuilder.WriteString(req.Diff)
-	promptBuilder.WriteString("\n</git_diff>")
+	// Sections 4 and 5: Commit History, Changed Files and Code Changes, or the full file listing
+	writeCodeContext(&promptBuilder, req, o.promptSections)
+	o.writeExistingDocsContext(ctx, &promptBuilder, req, docRoot)
 
 	// Create runner for this generation session
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{Agent: agent, EnableStreaming: true})
-	println("=== GenerateDocs: Created runner ===")
+	events.Emit(ctx, events.LLMEventTool, events.NewDebug("GenerateDocs: created runner"))
 
 	// Store the user query as the first message in conversation history
 	// This ensures when history is restored, the first message is always a user message
@@ -657,7 +1185,7 @@ func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest)
 
 	// Initialize conversation history with the user query
 	conversationHistory := []adk.Message{userQueryMessage}
-	var lastMessage string
+	var summary runSummary
 	for {
 		event, ok := iter.Next()
 		if !ok {
@@ -665,6 +1193,13 @@ func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest)
 		}
 		if event.Err != nil {
 			if errors.Is(event.Err, context.Canceled) {
+				// Keep the partial history whether the run was paused or stopped, so
+				// either can be resumed
+				o.storePartialConversationHistory(conversationHistory)
+				if o.IsPaused() {
+					events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing paused"))
+					return nil, context.Canceled
+				}
 				events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing canceled"))
 				return nil, context.Canceled
 			}
@@ -678,7 +1213,7 @@ func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest)
 		}
 		msg, err := o.consumeMessageVariant(ctx, output.MessageOutput)
 		if err != nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("GenerateDocs: message error: %v", err)))
+			events.LoggerFrom(ctx).Errorf("GenerateDocs: message error: %v", err)
 			continue
 		}
 		if msg == nil {
@@ -686,25 +1221,50 @@ func (o *LLMClient) GenerateDocs(ctx context.Context, req *DocGenerationRequest)
 		}
 		// Capture the message for conversation history
 		conversationHistory = append(conversationHistory, msg)
-		lastMessage = msg.Content
+		summary.observe(msg)
+		if msg.ResponseMeta != nil {
+			if err := o.recordTokenUsage(ctx, msg.ResponseMeta.Usage, msg.ReasoningContent); err != nil {
+				o.storePartialConversationHistory(conversationHistory)
+				events.Emit(ctx, events.LLMEventDone, events.NewError("LLM processing stopped: reasoning budget exceeded"))
+				return nil, err
+			}
+		}
 	}
 
 	// Store conversation history for potential refinement
 	o.conversationHistoryMu.Lock()
 	o.conversationHistory = conversationHistory
 	o.conversationHistoryMu.Unlock()
-	println("=== GenerateDocs: Completed ===")
-	println("GenerateDocs: Stored", len(conversationHistory), "messages in conversation history")
-	for i, msg := range conversationHistory {
-		println("  History", i, "- Role:", msg.Role, "ContentLength:", len(msg.Content), "ToolCalls:", len(msg.ToolCalls))
+	debugConversationHistory(ctx, "GenerateDocs: stored conversation history", conversationHistory)
+
+	finalSummary := o.finalSummary(ctx, &summary, conversationHistory)
+	if !req.chunk.pending() {
+		emitRunComplete(ctx)
 	}
+	return &DocGenerationResponse{Summary: finalSummary}, nil
+}
+
+// PlanDocs runs a documentation generation with the write, edit and delete
+// tools replaced by plan variants, so the agent explores as usual but only
+// records which files it would touch and why. The returned response carries
+// the plan; the documentation root is left untouched.
+func (o *LLMClient) PlanDocs(ctx context.Context, req *DocGenerationRequest) (*DocGenerationResponse, error) {
+	o.planMode = true
+	defer func() { o.planMode = false }()
+	tools.ResetPlannedChanges(ctx)
 
-	events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing complete"))
-	return &DocGenerationResponse{Summary: strings.TrimSpace(lastMessage)}, nil
+	resp, err := o.GenerateDocs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Plan = tools.PlannedChangesForSession(ctx)
+	return resp, nil
 }
 
 func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocGenerationResponse, error) {
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo("DocRefine: initializing"))
+	tools.ResetRunStats(ctx)
+	o.resetReasoningBudget()
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
 	}
@@ -722,6 +1282,8 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 	if err != nil {
 		return nil, err
 	}
+	o.allowedFiles = allowedDocFiles(docRoot, req.AllowedFiles)
+	defer func() { o.allowedFiles = nil }()
 
 	// Always create a new session for refinement, but include conversation history if available
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo("DocRefine: creating refinement session"))
@@ -736,6 +1298,7 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 	if err != nil {
 		return nil, err
 	}
+	systemPrompt += o.disabledToolsInstructions()
 
 	if resources.projectInstrErr != nil {
 		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("unable to load repo LLM instructions: %v", resources.projectInstrErr)))
@@ -774,17 +1337,15 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 		ProjectInstr:  resources.projectInstr,
 		SpecificInstr: req.SpecificInstr,
 		ExtraContext:  extraContext,
+		SourceBranch:  req.SourceBranch,
+		Sections:      o.promptSections,
 	})
 
+	o.compactConversationHistory(ctx)
 	conversationHistory, historyAdjusted := o.conversationHistoryForRun(prompt)
 
-	println("=== DocRefine: Checking conversation history ===")
-	println("conversationHistory length:", len(conversationHistory))
-
-	if len(conversationHistory) > 0 {
-		println("DocRefine: will include", len(conversationHistory), "previous messages for context")
-	} else {
-		println("DocRefine: no conversation history available, starting fresh")
+	if len(conversationHistory) == 0 {
+		events.Emit(ctx, events.LLMEventTool, events.NewDebug("DocRefine: no conversation history available, starting fresh"))
 	}
 
 	if historyAdjusted {
@@ -802,6 +1363,7 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 	b.WriteString("<user_instruction>\n")
 	b.WriteString(strings.TrimSpace(req.Instruction))
 	b.WriteString("\n</user_instruction>")
+	writeAllowedFilesSection(&b, req.AllowedFiles)
 
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{Agent: agent, EnableStreaming: true})
 
@@ -810,14 +1372,9 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 
 	if len(conversationHistory) > 0 {
 		// Include the previous conversation history for context
-		println("DocRefine: Including", len(conversationHistory), "previous messages for context")
 		messages = make([]adk.Message, len(conversationHistory))
 		copy(messages, conversationHistory)
-
-		// Print the history being included
-		for i, msg := range conversationHistory {
-			println("  History", i, "- Role:", msg.Role, "ContentLength:", len(msg.Content), "ToolCalls:", len(msg.ToolCalls))
-		}
+		debugConversationHistory(ctx, "DocRefine: including previous conversation history", conversationHistory)
 	}
 
 	// Append the new user instruction
@@ -827,14 +1384,16 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 	}
 	messages = append(messages, newUserMessage)
 
-	println("DocRefine: Total messages being sent to LLM:", len(messages))
-	println("  Last message (new instruction) - Role:", newUserMessage.Role, "ContentLength:", len(newUserMessage.Content))
+	if events.DebugEnabled() {
+		events.Emit(ctx, events.LLMEventTool, events.NewDebug(fmt.Sprintf(
+			"DocRefine: sending %d messages; new instruction length %d", len(messages), len(newUserMessage.Content))))
+	}
 
 	// Use Run instead of Query to pass the full message history
 	iter := runner.Run(ctx, messages)
 
 	var newMessages []adk.Message
-	var lastMessage string
+	var summary runSummary
 	for {
 		event, ok := iter.Next()
 		if !ok {
@@ -842,6 +1401,11 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 		}
 		if event.Err != nil {
 			if errors.Is(event.Err, context.Canceled) {
+				o.storePartialConversationHistory(append(messages, newMessages...))
+				if o.IsPaused() {
+					events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing paused"))
+					return nil, context.Canceled
+				}
 				events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing canceled"))
 				return nil, context.Canceled
 			}
@@ -854,7 +1418,7 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 		}
 		msg, err := o.consumeMessageVariant(ctx, output.MessageOutput)
 		if err != nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("DocRefine: message error: %v", err)))
+			events.LoggerFrom(ctx).Errorf("DocRefine: message error: %v", err)
 			continue
 		}
 		if msg == nil {
@@ -862,7 +1426,14 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 		}
 		// Capture only the NEW messages from this round (assistant responses)
 		newMessages = append(newMessages, msg)
-		lastMessage = msg.Content
+		summary.observe(msg)
+		if msg.ResponseMeta != nil {
+			if err := o.recordTokenUsage(ctx, msg.ResponseMeta.Usage, msg.ReasoningContent); err != nil {
+				o.storePartialConversationHistory(append(messages, newMessages...))
+				events.Emit(ctx, events.LLMEventDone, events.NewError("LLM processing stopped: reasoning budget exceeded"))
+				return nil, err
+			}
+		}
 	}
 
 	// Update conversation history: keep all messages we sent + append new responses
@@ -872,11 +1443,17 @@ func (o *LLMClient) DocRefine(ctx context.Context, req *DocRefineRequest) (*DocG
 	o.conversationHistory = append(messages, newMessages...)
 	totalMessages := len(o.conversationHistory)
 	o.conversationHistoryMu.Unlock()
-	println("DocRefine: Updated conversation history, now contains", totalMessages, "messages")
-	println("  (sent", len(messages), "messages, got", len(newMessages), "new responses)")
+	if events.DebugEnabled() {
+		events.Emit(ctx, events.LLMEventTool, events.NewDebug(fmt.Sprintf(
+			"DocRefine: conversation history now contains %d messages (sent %d, got %d new responses)",
+			totalMessages, len(messages), len(newMessages))))
+	}
 
-	events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing complete"))
-	return &DocGenerationResponse{Summary: strings.TrimSpace(lastMessage)}, nil
+	finalSummary := o.finalSummary(ctx, &summary, append([]adk.Message{newUserMessage}, newMessages...))
+	if !o.BootstrapPending() {
+		emitRunComplete(ctx)
+	}
+	return &DocGenerationResponse{Summary: finalSummary}, nil
 }
 
 func (o *LLMClient) generateDocsAgentic(ctx context.Context, req *DocGenerationRequest, docRoot string, codeRoot string, resources *docSessionResources, systemInstr string) (*DocGenerationResponse, error) {
@@ -896,24 +1473,7 @@ func (o *LLMClient) generateDocsAgentic(ctx context.Context, req *DocGenerationR
 		return nil, err
 	}
 
-	changedList := "(none)"
-	if len(req.ChangedFiles) > 0 {
-		var changed strings.Builder
-		for _, f := range req.ChangedFiles {
-			changed.WriteString("- ")
-			changed.WriteString(filepath.ToSlash(f))
-			changed.WriteString("\n")
-		}
-		changedList = strings.TrimSpace(changed.String())
-	}
-
-	extraContext := map[string]string{
-		"Source branch": req.SourceBranch,
-		"Target branch": req.TargetBranch,
-	}
-	if commit := strings.TrimSpace(req.SourceCommit); commit != "" {
-		extraContext["Source commit"] = commit
-	}
+	extraContext := docGenerationContext(req)
 
 	prompt := buildPromptWithInstructions(ctx, promptBuilderConfig{
 		ProjectName:   req.ProjectName,
@@ -924,17 +1484,15 @@ func (o *LLMClient) generateDocsAgentic(ctx context.Context, req *DocGenerationR
 		ProjectInstr:  resources.projectInstr,
 		SpecificInstr: req.SpecificInstr,
 		ExtraContext:  extraContext,
+		SourceBranch:  req.SourceBranch,
+		ChangedFiles:  req.ChangedFiles,
+		Sections:      o.promptSections,
 	})
 
 	var promptBuilder strings.Builder
 	promptBuilder.WriteString(prompt)
-	promptBuilder.WriteString("# Changed Files\n")
-	promptBuilder.WriteString(changedList)
-	promptBuilder.WriteString("\n\n")
-	promptBuilder.WriteString("# Code Changes\n")
-	promptBuilder.WriteString("<git_diff>\n")
-	promptBuilder.WriteString(req.Diff)
-	promptBuilder.WriteString("\n</git_diff>")
+	writeCodeContext(&promptBuilder, req, o.promptSections)
+	o.writeExistingDocsContext(ctx, &promptBuilder, req, docRoot)
 
 	userQuery := schema.UserAgenticMessage(promptBuilder.String())
 	conversationHistory := []*schema.AgenticMessage{userQuery}
@@ -942,7 +1500,7 @@ func (o *LLMClient) generateDocsAgentic(ctx context.Context, req *DocGenerationR
 	runner := adk.NewTypedRunner(adk.TypedRunnerConfig[*schema.AgenticMessage]{Agent: agent, EnableStreaming: true})
 	iter := runner.Query(ctx, promptBuilder.String())
 
-	var lastMessage string
+	var summary runSummary
 	for {
 		event, ok := iter.Next()
 		if !ok {
@@ -950,6 +1508,11 @@ func (o *LLMClient) generateDocsAgentic(ctx context.Context, req *DocGenerationR
 		}
 		if event.Err != nil {
 			if errors.Is(event.Err, context.Canceled) {
+				o.storePartialAgenticConversationHistory(conversationHistory)
+				if o.IsPaused() {
+					events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing paused"))
+					return nil, context.Canceled
+				}
 				events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing canceled"))
 				return nil, context.Canceled
 			}
@@ -961,20 +1524,30 @@ func (o *LLMClient) generateDocsAgentic(ctx context.Context, req *DocGenerationR
 		}
 		msg, consumeErr := o.consumeAgenticMessageVariant(ctx, event.Output.MessageOutput)
 		if consumeErr != nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("GenerateDocs: message error: %v", consumeErr)))
+			events.LoggerFrom(ctx).Errorf("GenerateDocs: message error: %v", consumeErr)
 			continue
 		}
 		if msg == nil {
 			continue
 		}
 		conversationHistory = append(conversationHistory, msg)
-		lastMessage = agenticTextContent(msg)
+		summary.observeAgentic(msg)
+		if msg.ResponseMeta != nil {
+			if err := o.recordTokenUsage(ctx, msg.ResponseMeta.TokenUsage, ""); err != nil {
+				o.storePartialAgenticConversationHistory(conversationHistory)
+				events.Emit(ctx, events.LLMEventDone, events.NewError("LLM processing stopped: reasoning budget exceeded"))
+				return nil, err
+			}
+		}
 	}
 
 	o.storeAgenticConversationHistory(conversationHistory)
 
-	events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing complete"))
-	return &DocGenerationResponse{Summary: strings.TrimSpace(lastMessage)}, nil
+	finalSummary, structured := o.finalAgenticSummary(ctx, &summary, conversationHistory)
+	if !req.chunk.pending() {
+		emitRunComplete(ctx)
+	}
+	return &DocGenerationResponse{Summary: finalSummary, Structured: structured}, nil
 }
 
 func (o *LLMClient) docRefineAgentic(ctx context.Context, req *DocRefineRequest, docRoot string, codeRoot string, resources *docSessionResources, systemPrompt string) (*DocGenerationResponse, error) {
@@ -1007,8 +1580,11 @@ func (o *LLMClient) docRefineAgentic(ctx context.Context, req *DocRefineRequest,
 		ProjectInstr:  resources.projectInstr,
 		SpecificInstr: req.SpecificInstr,
 		ExtraContext:  extraContext,
+		SourceBranch:  req.SourceBranch,
+		Sections:      o.promptSections,
 	})
 
+	o.compactConversationHistory(ctx)
 	conversationHistory := o.agenticConversationHistoryForRun(prompt)
 
 	var b strings.Builder
@@ -1020,6 +1596,7 @@ func (o *LLMClient) docRefineAgentic(ctx context.Context, req *DocRefineRequest,
 	b.WriteString("<user_instruction>\n")
 	b.WriteString(strings.TrimSpace(req.Instruction))
 	b.WriteString("\n</user_instruction>")
+	writeAllowedFilesSection(&b, req.AllowedFiles)
 
 	messages := make([]*schema.AgenticMessage, 0, len(conversationHistory)+1)
 	messages = append(messages, conversationHistory...)
@@ -1030,7 +1607,7 @@ func (o *LLMClient) docRefineAgentic(ctx context.Context, req *DocRefineRequest,
 	iter := runner.Run(ctx, messages)
 
 	var newMessages []*schema.AgenticMessage
-	var lastMessage string
+	var summary runSummary
 	for {
 		event, ok := iter.Next()
 		if !ok {
@@ -1038,6 +1615,11 @@ func (o *LLMClient) docRefineAgentic(ctx context.Context, req *DocRefineRequest,
 		}
 		if event.Err != nil {
 			if errors.Is(event.Err, context.Canceled) {
+				o.storePartialAgenticConversationHistory(append(messages, newMessages...))
+				if o.IsPaused() {
+					events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing paused"))
+					return nil, context.Canceled
+				}
 				events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing canceled"))
 				return nil, context.Canceled
 			}
@@ -1049,20 +1631,30 @@ func (o *LLMClient) docRefineAgentic(ctx context.Context, req *DocRefineRequest,
 		}
 		msg, consumeErr := o.consumeAgenticMessageVariant(ctx, event.Output.MessageOutput)
 		if consumeErr != nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("DocRefine: message error: %v", consumeErr)))
+			events.LoggerFrom(ctx).Errorf("DocRefine: message error: %v", consumeErr)
 			continue
 		}
 		if msg == nil {
 			continue
 		}
 		newMessages = append(newMessages, msg)
-		lastMessage = agenticTextContent(msg)
+		summary.observeAgentic(msg)
+		if msg.ResponseMeta != nil {
+			if err := o.recordTokenUsage(ctx, msg.ResponseMeta.TokenUsage, ""); err != nil {
+				o.storePartialAgenticConversationHistory(append(messages, newMessages...))
+				events.Emit(ctx, events.LLMEventDone, events.NewError("LLM processing stopped: reasoning budget exceeded"))
+				return nil, err
+			}
+		}
 	}
 
 	o.storeAgenticConversationHistory(append(messages, newMessages...))
 
-	events.Emit(ctx, events.LLMEventDone, events.NewInfo("LLM processing complete"))
-	return &DocGenerationResponse{Summary: strings.TrimSpace(lastMessage)}, nil
+	finalSummary, structured := o.finalAgenticSummary(ctx, &summary, append([]*schema.AgenticMessage{newUserMessage}, newMessages...))
+	if !o.BootstrapPending() {
+		emitRunComplete(ctx)
+	}
+	return &DocGenerationResponse{Summary: finalSummary, Structured: structured}, nil
 }
 
 func (o *LLMClient) prepareSnapshots(ctx context.Context) error {
@@ -1093,9 +1685,69 @@ func (o *LLMClient) prepareSnapshots(ctx context.Context) error {
 
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo("Snapshots: documentation tools will use the live workspace"))
 
+	o.docsSnapshot = nil
+	if o.publishedDocsSearch {
+		snapshot, err := o.buildDocsSnapshot()
+		if err != nil {
+			// The published view is optional; the run continues on the live workspace alone
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("Snapshots: published docs search unavailable: %v", err)))
+		} else {
+			o.docsSnapshot = snapshot
+			events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf(
+				"Snapshots: configured published docs snapshot at commit %s",
+				snapshot.CommitHash().String(),
+			)))
+		}
+	}
+	if workspaceID := strings.TrimSpace(o.workspaceID); workspaceID != "" {
+		tools.SetDocsSnapshotForSession(workspaceID, o.docsSnapshot)
+	}
+
 	return nil
 }
 
+// buildDocsSnapshot snapshots the commit checked out in the documentation
+// workspace, i.e. the docs branch as it was before this run made any edits.
+func (o *LLMClient) buildDocsSnapshot() (*tools.GitSnapshot, error) {
+	docRoot := strings.TrimSpace(o.docRoot)
+	if docRoot == "" {
+		return nil, fmt.Errorf("documentation root is not set")
+	}
+	repo, err := git.PlainOpenWithOptions(docRoot, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open documentation repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load documentation worktree: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve documentation branch head: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load documentation branch head: %w", err)
+	}
+	branch := ""
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	return tools.NewGitSnapshot(repo, commit, worktree.Filesystem.Root(), branch)
+}
+
+// SetPublishedDocsSearch enables building a docs branch snapshot for each run
+// so the grep tool can search the published docs with published=true.
+func (o *LLMClient) SetPublishedDocsSearch(enabled bool) {
+	o.publishedDocsSearch = enabled
+}
+
+// SetMaxFileSize sets the largest file the write and edit tools may produce,
+// in bytes; 0 means tools.DefaultMaxFileSize.
+func (o *LLMClient) SetMaxFileSize(bytes int64) {
+	o.maxFileSize = bytes
+}
+
 // recordOpenedFile appends a file path to the session history if not already present.
 func (o *LLMClient) recordOpenedFile(p string) {
 	if o == nil {
@@ -1127,6 +1779,12 @@ func (o *LLMClient) recordOpenedFile(p string) {
 	o.fileOpenHistory = append(o.fileOpenHistory, norm)
 }
 
+// recordWrittenFile marks a file the session wrote as read: the agent knows its
+// content, so changing it again must not require another read.
+func (o *LLMClient) recordWrittenFile(absPath string) {
+	o.recordOpenedFile(absPath)
+}
+
 // hasRead checks if the absolute path has been read in this session.
 func (o *LLMClient) hasRead(absPath string) bool {
 	norm := filepath.ToSlash(strings.TrimSpace(absPath))
@@ -1138,6 +1796,50 @@ func (o *LLMClient) hasRead(absPath string) bool {
 	return slices.Contains(o.fileOpenHistory, norm)
 }
 
+// allowedFilesPolicyError is returned by the write, edit and delete tools for
+// files outside the run's allowlist.
+const allowedFilesPolicyError = "Policy error: only the files listed in the refinement request may be changed"
+
+// allowedDocFiles resolves paths relative to docRoot into the canonical
+// paths editAllowed compares against, so a docRoot reached through a symlink
+// still matches the resolved paths the tools work with.
+func allowedDocFiles(docRoot string, paths []string) []string {
+	var allowed []string
+	for _, p := range paths {
+		trimmed := strings.TrimSpace(p)
+		if trimmed == "" {
+			continue
+		}
+		allowed = append(allowed, filepath.ToSlash(tools.CanonicalPath(filepath.Join(docRoot, filepath.FromSlash(trimmed)))))
+	}
+	return allowed
+}
+
+// editAllowed reports whether the write, edit and delete tools may change
+// absPath. Without an allowlist every file may be changed.
+func (o *LLMClient) editAllowed(absPath string) bool {
+	if len(o.allowedFiles) == 0 {
+		return true
+	}
+	return slices.Contains(o.allowedFiles, filepath.ToSlash(tools.CanonicalPath(absPath)))
+}
+
+// writeAllowedFilesSection tells the agent which files a scoped refinement
+// may change.
+func writeAllowedFilesSection(b *strings.Builder, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	b.WriteString("\n\n# Files to Regenerate\n\n")
+	b.WriteString("Only the following documentation files may be written, edited or deleted in this run; ")
+	b.WriteString("changes to any other file will be rejected. Read other files for context as needed.\n\n")
+	for _, f := range files {
+		b.WriteString("- ")
+		b.WriteString(filepath.ToSlash(strings.TrimSpace(f)))
+		b.WriteString("\n")
+	}
+}
+
 // ResetFileOpenHistory clears the in-memory history for the current client session.
 func (o *LLMClient) ResetFileOpenHistory() {
 	if o == nil {
@@ -1206,9 +1908,9 @@ func (o *LLMClient) consumeStreamingMessage(ctx context.Context, stream adk.Mess
 	defer stream.Close()
 
 	var (
-		chunks           []*schema.Message
-		reasoningBuilder strings.Builder
-		hasReasoning     bool
+		chunks       []*schema.Message
+		relay        = streamRelay{client: o}
+		hasReasoning bool
 	)
 
 	for {
@@ -1223,13 +1925,10 @@ func (o *LLMClient) consumeStreamingMessage(ctx context.Context, stream adk.Mess
 			continue
 		}
 		if chunk.ReasoningContent != "" {
-			if !hasReasoning {
-				o.emitReasoningReset(ctx)
-				hasReasoning = true
-			}
-			_, _ = reasoningBuilder.WriteString(chunk.ReasoningContent)
-			o.emitReasoningUpdate(ctx, reasoningBuilder.String())
+			hasReasoning = true
+			relay.add(ctx, streamNameReasoning, chunk.ReasoningContent)
 		}
+		relay.add(ctx, streamNameContent, chunk.Content)
 		chunks = append(chunks, chunk)
 	}
 
@@ -1247,6 +1946,20 @@ func (o *LLMClient) consumeStreamingMessage(ctx context.Context, stream adk.Mess
 	return msg, nil
 }
 
+// debugConversationHistory reports the role and size of each history message in
+// debug mode. Message contents are never included.
+func debugConversationHistory(ctx context.Context, label string, history []adk.Message) {
+	if !events.DebugEnabled() {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d messages)", label, len(history))
+	for i, msg := range history {
+		fmt.Fprintf(&b, "\n  History %d - Role: %s ContentLength: %d ToolCalls: %d", i, msg.Role, len(msg.Content), len(msg.ToolCalls))
+	}
+	events.Emit(ctx, events.LLMEventTool, events.NewDebug(b.String()))
+}
+
 func (o *LLMClient) consumeAgenticStreamingMessage(ctx context.Context, stream *schema.StreamReader[*schema.AgenticMessage]) (*schema.AgenticMessage, error) {
 	if stream == nil {
 		return nil, nil
@@ -1255,9 +1968,9 @@ func (o *LLMClient) consumeAgenticStreamingMessage(ctx context.Context, stream *
 	defer stream.Close()
 
 	var (
-		chunks           []*schema.AgenticMessage
-		reasoningBuilder strings.Builder
-		hasReasoning     bool
+		chunks       []*schema.AgenticMessage
+		relay        = streamRelay{client: o}
+		hasReasoning bool
 	)
 
 	for {
@@ -1271,13 +1984,17 @@ func (o *LLMClient) consumeAgenticStreamingMessage(ctx context.Context, stream *
 		if chunk == nil {
 			continue
 		}
-		if reasoning := agenticReasoningContent(chunk); reasoning != "" {
-			if !hasReasoning {
-				o.emitReasoningReset(ctx)
+		// Blocks are relayed in order so reasoning and text interleaved
+		// within one chunk keep their sequence
+		for _, block := range chunk.ContentBlocks {
+			switch {
+			case block == nil:
+			case block.Reasoning != nil && block.Reasoning.Text != "":
 				hasReasoning = true
+				relay.add(ctx, streamNameReasoning, block.Reasoning.Text)
+			case block.AssistantGenText != nil:
+				relay.add(ctx, streamNameContent, block.AssistantGenText.Text)
 			}
-			_, _ = reasoningBuilder.WriteString(reasoning)
-			o.emitReasoningUpdate(ctx, reasoningBuilder.String())
 		}
 		chunks = append(chunks, chunk)
 	}
@@ -1333,26 +2050,111 @@ func agenticReasoningContent(msg *schema.AgenticMessage) string {
 }
 
 func (o *LLMClient) emitReasoningReset(ctx context.Context) {
+	o.emitStreamReset(ctx, streamNameReasoning)
+}
+
+func (o *LLMClient) emitReasoningUpdate(ctx context.Context, content string) {
+	o.emitStreamUpdate(ctx, streamNameReasoning, content)
+}
+
+// streamEnabled reports whether events for stream reach the UI. Reasoning is
+// gated by showReasoning; assistant text is always shown.
+func (o *LLMClient) streamEnabled(stream string) bool {
+	return stream != streamNameReasoning || o.showReasoning
+}
+
+func (o *LLMClient) emitStreamReset(ctx context.Context, stream string) {
+	if !o.streamEnabled(stream) {
+		return
+	}
 	evt := events.NewSuccess("")
+	evt.Kind = streamEventKind(stream)
 	evt.Metadata = map[string]string{
-		reasoningMetadataStreamKey: reasoningMetadataStreamName,
-		reasoningMetadataStateKey:  reasoningMetadataReset,
+		streamMetadataKey:      stream,
+		streamMetadataStateKey: streamMetadataReset,
 	}
 	events.Emit(ctx, events.LLMEventTool, evt)
 }
 
-func (o *LLMClient) emitReasoningUpdate(ctx context.Context, content string) {
-	if strings.TrimSpace(content) == "" {
+func (o *LLMClient) emitStreamUpdate(ctx context.Context, stream, content string) {
+	if !o.streamEnabled(stream) || strings.TrimSpace(content) == "" {
 		return
 	}
 	evt := events.NewSuccess(content)
+	evt.Kind = streamEventKind(stream)
 	evt.Metadata = map[string]string{
-		reasoningMetadataStreamKey: reasoningMetadataStreamName,
-		reasoningMetadataStateKey:  reasoningMetadataUpdate,
+		streamMetadataKey:      stream,
+		streamMetadataStateKey: streamMetadataUpdate,
 	}
 	events.Emit(ctx, events.LLMEventTool, evt)
 }
 
+func streamEventKind(stream string) events.EventKind {
+	if stream == streamNameContent {
+		return events.EventKindContent
+	}
+	return events.EventKindReasoning
+}
+
+// streamRelay forwards reasoning and assistant text deltas to the UI while a
+// message streams in. Switching from one stream to the other starts a new
+// reset/update sequence, so interleaved reasoning and text show up as separate
+// blocks in the order the model produced them.
+type streamRelay struct {
+	client *LLMClient
+	active string
+	text   strings.Builder
+}
+
+func (r *streamRelay) add(ctx context.Context, stream, delta string) {
+	if delta == "" || !r.client.streamEnabled(stream) {
+		return
+	}
+	if r.active != stream {
+		r.active = stream
+		r.text.Reset()
+		r.client.emitStreamReset(ctx, stream)
+	}
+	r.text.WriteString(delta)
+	r.client.emitStreamUpdate(ctx, stream, r.text.String())
+}
+
+// toolSucceeded reports whether a tool output's metadata carries no error marker.
+func toolSucceeded(metadata map[string]string) bool {
+	return metadata == nil || metadata["error"] == ""
+}
+
+// emitRunComplete emits the completion event of a successful run, carrying a
+// summary of the files changed, tool calls made and tokens used.
+func emitRunComplete(ctx context.Context) {
+	stats := tools.RunStatsForSession(ctx)
+	summary := events.RunSummary{
+		FilesCreated:     stats.FilesCreated,
+		FilesModified:    stats.FilesModified,
+		FilesDeleted:     stats.FilesDeleted,
+		ToolCalls:        stats.ToolCalls,
+		PromptTokens:     stats.PromptTokens,
+		CompletionTokens: stats.CompletionTokens,
+		ReasoningTokens:  stats.ReasoningTokens,
+		TotalTokens:      stats.TotalTokens,
+		ElapsedMs:        stats.Elapsed.Milliseconds(),
+	}
+	events.Emit(ctx, events.LLMEventDone, events.NewSuccess("LLM processing complete").WithSummary(summary))
+}
+
+// emitDocsFileChanged reports a successful docs modification with its path
+// relative to the documentation root so the UI can track touched files live.
+func (o *LLMClient) emitDocsFileChanged(ctx context.Context, absPath string, op events.DocsFileOperation, toolName string) {
+	tools.RecordFileChange(ctx, absPath, string(op))
+	rel := absPath
+	if docRoot := strings.TrimSpace(o.docRoot); docRoot != "" {
+		if r, err := filepath.Rel(docRoot, absPath); err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+	events.EmitDocsFileChanged(ctx, filepath.ToSlash(rel), op, toolName)
+}
+
 func (o *LLMClient) captureListing(ctx context.Context, repo tools.Repository) (string, error) {
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("CaptureListing: %s:/", repo)))
 	out, err := tools.ListDirectory(ctx, &tools.ListLSInput{Repository: repo, Path: "."})
@@ -1362,17 +2164,44 @@ func (o *LLMClient) captureListing(ctx context.Context, repo tools.Repository) (
 	return out.Output, nil
 }
 
+// loadIgnoreFiles scopes the session's list, glob and grep tools with the
+// .narrabyteignore file found at each repository root, if any.
+func (o *LLMClient) loadIgnoreFiles(docRoot, codeRoot string) error {
+	workspaceID := strings.TrimSpace(o.workspaceID)
+	roots := map[tools.Repository]string{
+		tools.RepositoryDocs: docRoot,
+		tools.RepositoryCode: codeRoot,
+	}
+	for repo, root := range roots {
+		patterns, err := tools.LoadIgnoreFile(root)
+		if err != nil {
+			return fmt.Errorf("failed to read %s for %s repository: %w", tools.IgnoreFileName, repo, err)
+		}
+		tools.SetRepositoryIgnorePatternsForSession(workspaceID, repo, patterns)
+	}
+	return nil
+}
+
 func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.BaseTool, error) {
 	o.ResetFileOpenHistory()
 	o.docRoot = docRoot
 	o.codeRoot = codeRoot
 	o.SetListDirectoryBaseRoot(docRoot)
+	if workspaceID := strings.TrimSpace(o.workspaceID); workspaceID != "" {
+		tools.SetMaxFileSizeForSession(workspaceID, o.maxFileSize)
+	} else {
+		tools.SetMaxFileSize(o.maxFileSize)
+	}
+	if err := o.loadIgnoreFiles(docRoot, codeRoot); err != nil {
+		return nil, err
+	}
 
 	listDesc := tools.ToolDescription("list_directory_tool")
 	if strings.TrimSpace(listDesc) == "" {
 		listDesc = "lists the contents of a directory"
 	}
 	listWithPolicy := func(ctx context.Context, in *tools.ListLSInput) (string, error) {
+		recordToolCall(ctx, "list")
 		if in == nil {
 			in = &tools.ListLSInput{Repository: tools.RepositoryDocs}
 		}
@@ -1398,8 +2227,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		readDesc = "reads the contents of a file"
 	}
 	readWithPolicy := func(ctx context.Context, in *tools.ReadFileInput) (*tools.ReadFileOutput, error) {
+		recordToolCall(ctx, "read")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("ReadFile(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("ReadFile(policy): input is required").WithTool(events.EventKindPolicy, "read"))
 			return &tools.ReadFileOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]string{"error": "format_error"},
@@ -1437,8 +2267,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		writeDesc = "write or create a file within the documentation repository"
 	}
 	writeWithPolicy := func(ctx context.Context, in *tools.WriteFileInput) (*tools.WriteFileOutput, error) {
+		recordToolCall(ctx, "write")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("WriteFile(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("WriteFile(policy): input is required").WithTool(events.EventKindPolicy, "write"))
 			return &tools.WriteFileOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]string{"error": "format_error"},
@@ -1447,11 +2278,19 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 
 		// Check read-before-write policy for existing files
 		absPath, resolveErr := tools.ResolveRepositoryPath(ctx, in.Repository, in.FilePath)
+		if resolveErr == nil && !o.editAllowed(absPath) {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("WriteFile(policy): policy violation - file is not in the allowed list").WithTool(events.EventKindPolicy, "write"))
+			return &tools.WriteFileOutput{
+				Title:    tools.FormatDisplayPath(in.Repository, in.FilePath),
+				Output:   allowedFilesPolicyError,
+				Metadata: map[string]string{"error": "policy_violation"},
+			}, nil
+		}
 		if resolveErr == nil {
 			if st, err := os.Stat(absPath); err == nil && !st.IsDir() {
 				if !o.hasRead(absPath) {
 					displayPath := tools.FormatDisplayPath(in.Repository, in.FilePath)
-					events.Emit(ctx, events.LLMEventTool, events.NewWarn("WriteFile(policy): policy violation - must read before write"))
+					events.Emit(ctx, events.LLMEventTool, events.NewWarn("WriteFile(policy): policy violation - must read before write").WithTool(events.EventKindPolicy, "write"))
 					return &tools.WriteFileOutput{
 						Title:    displayPath,
 						Output:   "Policy error: must read the file before writing",
@@ -1471,6 +2310,16 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 			return out, err
 		}
 		events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Write file", "write", displayPath))
+		if resolveErr == nil && out != nil && toolSucceeded(out.Metadata) {
+			op := events.DocsFileCreated
+			if out.Metadata["exists"] == "true" {
+				op = events.DocsFileModified
+			}
+			if out.Metadata["status"] != "unchanged" {
+				o.emitDocsFileChanged(ctx, absPath, op, "write")
+			}
+			o.recordWrittenFile(absPath)
+		}
 		return out, nil
 	}
 	writeTool, err := einoUtils.InferTool("write_file_tool", writeDesc, writeWithPolicy)
@@ -1483,8 +2332,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		editDesc = "edit a file using context-aware string replacement"
 	}
 	editWithPolicy := func(ctx context.Context, in *tools.EditInput) (*tools.EditOutput, error) {
+		recordToolCall(ctx, "edit")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("EditFile(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("EditFile(policy): input is required").WithTool(events.EventKindPolicy, "edit"))
 			return &tools.EditOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]string{"error": "format_error"},
@@ -1492,12 +2342,22 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		}
 
 		// Check read-before-write policy for existing files
+		existed := false
 		absPath, resolveErr := tools.ResolveRepositoryPath(ctx, in.Repository, in.FilePath)
+		if resolveErr == nil && !o.editAllowed(absPath) {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("EditFile(policy): policy violation - file is not in the allowed list").WithTool(events.EventKindPolicy, "edit"))
+			return &tools.EditOutput{
+				Title:    tools.FormatDisplayPath(in.Repository, in.FilePath),
+				Output:   allowedFilesPolicyError,
+				Metadata: map[string]string{"error": "policy_violation"},
+			}, nil
+		}
 		if resolveErr == nil {
 			if st, err := os.Stat(absPath); err == nil && !st.IsDir() {
+				existed = true
 				if !o.hasRead(absPath) {
 					displayPath := tools.FormatDisplayPath(in.Repository, in.FilePath)
-					events.Emit(ctx, events.LLMEventTool, events.NewWarn("EditFile(policy): policy violation - must read before edit"))
+					events.Emit(ctx, events.LLMEventTool, events.NewWarn("EditFile(policy): policy violation - must read before edit").WithTool(events.EventKindPolicy, "edit"))
 					return &tools.EditOutput{
 						Title:    displayPath,
 						Output:   "Policy error: must read the file before editing",
@@ -1517,6 +2377,14 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 			return out, err
 		}
 		events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Edit file", "edit", displayPath))
+		if resolveErr == nil && out != nil && toolSucceeded(out.Metadata) {
+			op := events.DocsFileCreated
+			if existed {
+				op = events.DocsFileModified
+			}
+			o.emitDocsFileChanged(ctx, absPath, op, "edit")
+			o.recordWrittenFile(absPath)
+		}
 		return out, nil
 	}
 	editTool, err := einoUtils.InferTool("edit_tool", editDesc, editWithPolicy)
@@ -1530,8 +2398,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		multiEditDesc = "apply multiple edits to a single file in one operation"
 	}
 	multiEditWithPolicy := func(ctx context.Context, in *tools.MultiEditInput) (*tools.MultiEditOutput, error) {
+		recordToolCall(ctx, "multiedit")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("MultiEdit(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("MultiEdit(policy): input is required").WithTool(events.EventKindPolicy, "multiedit"))
 			return &tools.MultiEditOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]string{"error": "format_error"},
@@ -1539,12 +2408,22 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		}
 
 		// Check read-before-write policy for existing files
+		existed := false
 		absPath, resolveErr := tools.ResolveRepositoryPath(ctx, in.Repository, in.FilePath)
+		if resolveErr == nil && !o.editAllowed(absPath) {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("MultiEdit(policy): policy violation - file is not in the allowed list").WithTool(events.EventKindPolicy, "multiedit"))
+			return &tools.MultiEditOutput{
+				Title:    tools.FormatDisplayPath(in.Repository, in.FilePath),
+				Output:   allowedFilesPolicyError,
+				Metadata: map[string]string{"error": "policy_violation"},
+			}, nil
+		}
 		if resolveErr == nil {
 			if st, err := os.Stat(absPath); err == nil && !st.IsDir() {
+				existed = true
 				if !o.hasRead(absPath) {
 					displayPath := tools.FormatDisplayPath(in.Repository, in.FilePath)
-					events.Emit(ctx, events.LLMEventTool, events.NewWarn("MultiEdit(policy): policy violation - must read before edit"))
+					events.Emit(ctx, events.LLMEventTool, events.NewWarn("MultiEdit(policy): policy violation - must read before edit").WithTool(events.EventKindPolicy, "multiedit"))
 					return &tools.MultiEditOutput{
 						Title:    displayPath,
 						Output:   "Policy error: must read the file before editing",
@@ -1564,6 +2443,14 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 			return out, err
 		}
 		events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "MultiEdit file", "multiedit", displayPath))
+		if resolveErr == nil && out != nil && toolSucceeded(out.Metadata) {
+			op := events.DocsFileCreated
+			if existed {
+				op = events.DocsFileModified
+			}
+			o.emitDocsFileChanged(ctx, absPath, op, "multiedit")
+			o.recordWrittenFile(absPath)
+		}
 		return out, nil
 	}
 	multiEditTool, err := einoUtils.InferTool("multiedit_tool", multiEditDesc, multiEditWithPolicy)
@@ -1603,15 +2490,16 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		return &input, nil
 	}
 	todoWriteWithPolicy := func(ctx context.Context, in *tools.TodoWriteInput) (*tools.TodoWriteOutput, error) {
+		recordToolCall(ctx, "todo_write")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("TodoWrite(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("TodoWrite(policy): input is required").WithTool(events.EventKindPolicy, "todo_write"))
 			return &tools.TodoWriteOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]any{"error": "format_error"},
 			}, nil
 		}
 
-		events.Emit(ctx, events.LLMEventTool, events.NewInfo("TodoWrite: updating task list"))
+		events.Emit(ctx, events.LLMEventTool, events.NewInfo("TodoWrite: updating task list").WithTool(events.EventKindLog, "todo_write"))
 
 		// Check if we're reducing the todo count (potential accidental deletion)
 		sessionID := tools.SessionIDFromContext(ctx)
@@ -1621,20 +2509,20 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 			if len(existing) > 0 && len(in.Todos) < len(existing) {
 				events.Emit(ctx, events.LLMEventTool, events.NewWarn(
 					fmt.Sprintf("TodoWrite: Reducing todo count from %d to %d tasks. Ensure this is intentional - remember this tool replaces the entire list.",
-						len(existing), len(in.Todos))))
+						len(existing), len(in.Todos))).WithTool(events.EventKindLog, "todo_write"))
 			}
 		}
 
 		out, err := tools.WriteTodo(ctx, in)
 		if err != nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("TodoWrite: error: %v", err)))
+			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("TodoWrite: error: %v", err)).WithTool(events.EventKindTool, "todo_write"))
 			return nil, err
 		}
 		// Emit the todo update as a special event with metadata
 		evt := events.NewToolEvent(events.EventSuccess, fmt.Sprintf("TodoWrite: %s", out.Title), "todo_write", "")
 		if out.Metadata != nil {
 			if todos, ok := out.Metadata["todos"].([]tools.Todo); ok {
-				events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("TodoWrite: %s", out.Title)))
+				events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("TodoWrite: %s", out.Title)).WithTool(events.EventKindLog, "todo_write"))
 				// Convert tools.Todo to events.TodoItem for emission
 				todoItems := make([]events.TodoItem, len(todos))
 				for i, todo := range todos {
@@ -1646,6 +2534,7 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 				}
 				// Emit a special todo update event that frontend can listen to
 				events.EmitTodoUpdate(ctx, todoItems)
+				emitTodoProgress(ctx, todos)
 
 				// Add serialized todos to event metadata for history display
 				if todosJSON, err := json.Marshal(todos); err == nil {
@@ -1673,14 +2562,15 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		todoReadDesc = "read the current task list (ALWAYS call this before todo_write_tool to avoid deleting tasks)"
 	}
 	todoReadWithPolicy := func(ctx context.Context, in *tools.TodoReadInput) (*tools.TodoReadOutput, error) {
-		events.Emit(ctx, events.LLMEventTool, events.NewInfo("TodoRead: reading task list"))
+		recordToolCall(ctx, "todo_read")
+		events.Emit(ctx, events.LLMEventTool, events.NewInfo("TodoRead: reading task list").WithTool(events.EventKindLog, "todo_read"))
 		// Handle nil input (tool called with no arguments)
 		if in == nil {
 			in = &tools.TodoReadInput{}
 		}
 		out, err := tools.ReadTodo(ctx, in)
 		if err != nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("TodoRead: error: %v", err)))
+			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("TodoRead: error: %v", err)).WithTool(events.EventKindTool, "todo_read"))
 			return nil, err
 		}
 
@@ -1703,8 +2593,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		deleteDesc = "delete a file from the documentation repository"
 	}
 	deleteWithPolicy := func(ctx context.Context, in *tools.DeleteFileInput) (*tools.DeleteFileOutput, error) {
+		recordToolCall(ctx, "delete")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("DeleteFile(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("DeleteFile(policy): input is required").WithTool(events.EventKindPolicy, "delete"))
 			return &tools.DeleteFileOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]string{"error": "format_error"},
@@ -1713,11 +2604,19 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 
 		// Check read-before-delete policy for existing files
 		absPath, resolveErr := tools.ResolveRepositoryPath(ctx, in.Repository, in.FilePath)
+		if resolveErr == nil && !o.editAllowed(absPath) {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("DeleteFile(policy): policy violation - file is not in the allowed list").WithTool(events.EventKindPolicy, "delete"))
+			return &tools.DeleteFileOutput{
+				Title:    tools.FormatDisplayPath(in.Repository, in.FilePath),
+				Output:   allowedFilesPolicyError,
+				Metadata: map[string]string{"error": "policy_violation"},
+			}, nil
+		}
 		if resolveErr == nil {
 			if st, err := os.Stat(absPath); err == nil && !st.IsDir() {
 				if !o.hasRead(absPath) {
 					displayPath := tools.FormatDisplayPath(in.Repository, in.FilePath)
-					events.Emit(ctx, events.LLMEventTool, events.NewWarn("DeleteFile(policy): policy violation - must read before delete"))
+					events.Emit(ctx, events.LLMEventTool, events.NewWarn("DeleteFile(policy): policy violation - must read before delete").WithTool(events.EventKindPolicy, "delete"))
 					return &tools.DeleteFileOutput{
 						Title:    displayPath,
 						Output:   "Policy error: must read the file before deleting",
@@ -1737,6 +2636,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 			return out, err
 		}
 		events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Delete file", "delete", displayPath))
+		if resolveErr == nil && out != nil && toolSucceeded(out.Metadata) {
+			o.emitDocsFileChanged(ctx, absPath, events.DocsFileDeleted, "delete")
+		}
 		return out, nil
 	}
 	deleteTool, err := einoUtils.InferTool("delete_file_tool", deleteDesc, deleteWithPolicy)
@@ -1750,8 +2652,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		globDesc = "find files matching a glob pattern within a repository"
 	}
 	globWithPolicy := func(ctx context.Context, in *tools.GlobInput) (*tools.GlobOutput, error) {
+		recordToolCall(ctx, "glob")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("Glob(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("Glob(policy): input is required").WithTool(events.EventKindPolicy, "glob"))
 			return &tools.GlobOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]string{"error": "format_error"},
@@ -1787,8 +2690,9 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		grepDesc = "search for a regex pattern within files in a repository"
 	}
 	grepWithPolicy := func(ctx context.Context, in *tools.GrepInput) (*tools.GrepOutput, error) {
+		recordToolCall(ctx, "grep")
 		if in == nil {
-			events.Emit(ctx, events.LLMEventTool, events.NewError("Grep(policy): input is required"))
+			events.Emit(ctx, events.LLMEventTool, events.NewError("Grep(policy): input is required").WithTool(events.EventKindPolicy, "grep"))
 			return &tools.GrepOutput{
 				Output:   "Format error: input is required",
 				Metadata: map[string]string{"error": "format_error"},
@@ -1818,12 +2722,124 @@ func (o *LLMClient) initDocumentationTools(docRoot, codeRoot string) ([]tool.Bas
 		return nil, err
 	}
 
-	return []tool.BaseTool{listTool, readTool, writeTool, editTool, multiEditTool, todoWriteTool, todoReadTool, deleteTool, globTool, grepTool}, nil
+	// Inspect asset tool - report image dimensions, size and type without reading content
+	inspectDesc := tools.ToolDescription("inspect_asset_tool")
+	if strings.TrimSpace(inspectDesc) == "" {
+		inspectDesc = "report the type, dimensions and size of a file without reading its content"
+	}
+	inspectWithPolicy := func(ctx context.Context, in *tools.InspectAssetInput) (*tools.InspectAssetOutput, error) {
+		recordToolCall(ctx, "inspect")
+		if in == nil {
+			events.Emit(ctx, events.LLMEventTool, events.NewError("InspectAsset(policy): input is required").WithTool(events.EventKindPolicy, "inspect"))
+			return &tools.InspectAssetOutput{
+				Output:   "Format error: input is required",
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+
+		out, err := tools.InspectAsset(ctx, in)
+		displayPath := ""
+		if out != nil {
+			displayPath = out.Title
+		}
+		if err != nil {
+			events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventError, "Inspect asset", "inspect", displayPath))
+			return out, err
+		}
+		events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Inspect asset", "inspect", displayPath))
+		return out, nil
+	}
+	inspectTool, err := einoUtils.InferTool("inspect_asset_tool", inspectDesc, inspectWithPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blame tool - attribute lines of a code file to the commits that last changed them
+	blameDesc := tools.ToolDescription("blame_tool")
+	if strings.TrimSpace(blameDesc) == "" {
+		blameDesc = "show which commit last changed each line of a code file"
+	}
+	blameWithPolicy := func(ctx context.Context, in *tools.BlameInput) (*tools.BlameOutput, error) {
+		recordToolCall(ctx, "blame")
+		if in == nil {
+			events.Emit(ctx, events.LLMEventTool, events.NewError("Blame(policy): input is required").WithTool(events.EventKindPolicy, "blame"))
+			return &tools.BlameOutput{
+				Output:   "Format error: input is required",
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+
+		out, err := tools.Blame(ctx, in)
+		displayPath := ""
+		if out != nil {
+			displayPath = out.Title
+		}
+		if err != nil {
+			events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventError, "Blame", "blame", displayPath))
+			return out, err
+		}
+		events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Blame", "blame", displayPath))
+		return out, nil
+	}
+	blameTool, err := einoUtils.InferTool("blame_tool", blameDesc, blameWithPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.planMode {
+		planTools, err := initPlanTools()
+		if err != nil {
+			return nil, err
+		}
+		return o.filterAllowedTools(append([]tool.BaseTool{listTool, readTool, todoWriteTool, todoReadTool, globTool, grepTool, inspectTool, blameTool}, planTools...))
+	}
+
+	return o.filterAllowedTools([]tool.BaseTool{listTool, readTool, writeTool, editTool, multiEditTool, todoWriteTool, todoReadTool, deleteTool, globTool, grepTool, inspectTool, blameTool})
+}
+
+// initPlanTools builds the plan variants of the write, edit and delete tools.
+// They keep the regular tool names so the system prompt applies unchanged.
+func initPlanTools() ([]tool.BaseTool, error) {
+	variants := []struct {
+		name      string
+		descKey   string
+		operation string
+		title     string
+	}{
+		{"write_file_tool", "plan_write_file_tool", tools.PlanOperationCreate, "Plan write"},
+		{"edit_tool", "plan_edit_file_tool", tools.PlanOperationModify, "Plan edit"},
+		{"delete_file_tool", "plan_delete_file_tool", tools.PlanOperationDelete, "Plan delete"},
+	}
+	planTools := make([]tool.BaseTool, 0, len(variants))
+	for _, v := range variants {
+		planWithPolicy := func(ctx context.Context, in *tools.PlanChangeInput) (*tools.PlanChangeOutput, error) {
+			recordToolCall(ctx, "plan")
+			out, err := tools.PlanChange(ctx, v.operation, in)
+			displayPath := ""
+			if out != nil {
+				displayPath = out.Title
+			}
+			if err != nil || (out != nil && !toolSucceeded(out.Metadata)) {
+				events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventError, v.title, "plan", displayPath))
+				return out, err
+			}
+			events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, v.title, "plan", displayPath))
+			return out, nil
+		}
+		planTool, err := einoUtils.InferTool(v.name, tools.ToolDescription(v.descKey), planWithPolicy)
+		if err != nil {
+			return nil, err
+		}
+		planTools = append(planTools, planTool)
+	}
+	return planTools, nil
 }
 
 // loadRepoLLMInstructions scans the documentation repository's .narrabyte directory
-// for a file beginning with "llm_instructions" and returns its contents.
-func (o *LLMClient) loadRepoLLMInstructions(docRoot string) (string, error) {
+// for files beginning with "llm_instructions" and returns their contents. When
+// several exist they are concatenated in name order, separated by
+// llmInstructionsSeparator, so instructions can be split by topic.
+func (o *LLMClient) loadRepoLLMInstructions(ctx context.Context, docRoot string) (string, error) {
 	docRoot = strings.TrimSpace(docRoot)
 	if docRoot == "" {
 		return "", nil
@@ -1836,25 +2852,41 @@ func (o *LLMClient) loadRepoLLMInstructions(docRoot string) (string, error) {
 		}
 		return "", err
 	}
-	var candidate string
+	// os.ReadDir returns entries sorted by filename
+	var (
+		names    []string
+		contents []string
+	)
 	for _, e := range entries {
 		if e.IsDir() {
 			continue
 		}
 		name := e.Name()
-		if strings.HasPrefix(name, llmInstructionsNamePrefix) {
-			candidate = filepath.Join(dir, name)
-			break
+		if !strings.HasPrefix(name, llmInstructionsNamePrefix) {
+			continue
 		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		if isLLMInstructionsTemplate(string(data)) {
+			events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("LLMInstructions: skipped %s, which is still the unedited template", name)))
+			continue
+		}
+		names = append(names, name)
+		contents = append(contents, string(data))
 	}
-	if candidate == "" {
+	if len(names) == 0 {
 		return "", nil
 	}
-	data, err := os.ReadFile(candidate)
-	if err != nil {
-		return "", err
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("LLMInstructions: loaded %s", strings.Join(names, ", "))))
+	if len(contents) == 1 {
+		return contents[0], nil
 	}
-	return string(data), nil
+	for i := range contents {
+		contents[i] = strings.TrimSpace(contents[i])
+	}
+	return strings.Join(contents, llmInstructionsSeparator), nil
 }
 
 type persistableMessage struct {
@@ -1921,19 +2953,59 @@ func (o *LLMClient) ConversationHistoryJSON() (string, error) {
 }
 
 // LoadConversationHistoryJSON restores conversation history from a JSON array
-// created by ConversationHistoryJSON. It replaces any existing history.
-func (o *LLMClient) LoadConversationHistoryJSON(jsonStr string) error {
+// created by ConversationHistoryJSON. It replaces any existing history. A
+// history over limit keeps its first message and most recent turns; the
+// number of messages dropped from the middle is returned.
+func (o *LLMClient) LoadConversationHistoryJSON(jsonStr string, limit HistoryLimit) (int, error) {
+	if strings.TrimSpace(jsonStr) == "" {
+		return 0, nil
+	}
+	history, err := parseConversationHistoryJSON(jsonStr)
+	if err != nil {
+		return 0, err
+	}
+	history, dropped := trimHistoryToLimit(history, limit)
+
+	// Validate that first message is a user message (required by Anthropic and good practice for all providers)
+	if len(history) > 0 && history[0].Role != schema.User {
+		return 0, fmt.Errorf("invalid conversation history: first message must be a user message (got %s)", history[0].Role)
+	}
+
+	o.conversationHistoryMu.Lock()
+	defer o.conversationHistoryMu.Unlock()
+	o.conversationHistory = history
+	o.agenticHistory = agenticHistoryFromMessages(history)
+	return dropped, nil
+}
+
+// RestoreConversationHistoryJSON restores conversation history like
+// LoadConversationHistoryJSON, but repairs the message ordering instead of
+// rejecting it. Leading non-system messages are dropped until the first user
+// message, and a synthetic user message built from fallbackFirstUser is
+// prepended when none exists. Used when moving a conversation between
+// providers with different ordering requirements.
+func (o *LLMClient) RestoreConversationHistoryJSON(jsonStr string, fallbackFirstUser string) error {
 	if strings.TrimSpace(jsonStr) == "" {
 		return nil
 	}
-	var msgs []persistableMessage
-	if err := json.Unmarshal([]byte(jsonStr), &msgs); err != nil {
+	history, err := parseConversationHistoryJSON(jsonStr)
+	if err != nil {
 		return err
 	}
+	history, _ = normalizeConversationHistory(history, fallbackFirstUser)
+
 	o.conversationHistoryMu.Lock()
 	defer o.conversationHistoryMu.Unlock()
-	o.conversationHistory = nil
-	o.agenticHistory = nil
+	o.conversationHistory = history
+	o.agenticHistory = agenticHistoryFromMessages(history)
+	return nil
+}
+
+func parseConversationHistoryJSON(jsonStr string) ([]adk.Message, error) {
+	var msgs []persistableMessage
+	if err := json.Unmarshal([]byte(jsonStr), &msgs); err != nil {
+		return nil, err
+	}
 
 	var history []adk.Message
 	for _, pm := range msgs {
@@ -1979,15 +3051,7 @@ func (o *LLMClient) LoadConversationHistoryJSON(jsonStr string) error {
 
 		history = append(history, msg)
 	}
-
-	// Validate that first message is a user message (required by Anthropic and good practice for all providers)
-	if len(history) > 0 && history[0].Role != schema.User {
-		return fmt.Errorf("invalid conversation history: first message must be a user message (got %s)", history[0].Role)
-	}
-
-	o.conversationHistory = history
-	o.agenticHistory = agenticHistoryFromMessages(history)
-	return nil
+	return history, nil
 }
 
 // HasConversationHistory reports whether any conversation history is present.
@@ -2085,6 +3149,54 @@ func (o *LLMClient) storeAgenticConversationHistory(history []*schema.AgenticMes
 	}
 }
 
+// storePartialConversationHistory keeps the history of an interrupted run,
+// dropping a trailing tool-call round whose results never arrived so the
+// history can be replayed to the provider on resume.
+func (o *LLMClient) storePartialConversationHistory(history []adk.Message) {
+	trimmed := trimDanglingToolCalls(history)
+	o.conversationHistoryMu.Lock()
+	defer o.conversationHistoryMu.Unlock()
+	o.conversationHistory = trimmed
+	o.agenticHistory = agenticHistoryFromMessages(trimmed)
+}
+
+// storePartialAgenticConversationHistory keeps the history of an interrupted
+// agentic run. The agentic history is rebuilt from the text-only conversion so
+// unanswered function-call blocks are not replayed.
+func (o *LLMClient) storePartialAgenticConversationHistory(history []*schema.AgenticMessage) {
+	converted := make([]adk.Message, 0, len(history))
+	for _, msg := range history {
+		if m := agenticToMessage(msg); m != nil {
+			converted = append(converted, m)
+		}
+	}
+	o.storePartialConversationHistory(converted)
+}
+
+// trimDanglingToolCalls truncates history at the last assistant message whose
+// tool calls are not all answered by subsequent tool messages.
+func trimDanglingToolCalls(history []adk.Message) []adk.Message {
+	for i := len(history) - 1; i >= 0; i-- {
+		msg := history[i]
+		if msg == nil || msg.Role != schema.Assistant || len(msg.ToolCalls) == 0 {
+			continue
+		}
+		answered := make(map[string]bool, len(msg.ToolCalls))
+		for _, later := range history[i+1:] {
+			if later != nil && later.Role == schema.Tool {
+				answered[later.ToolCallID] = true
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			if !answered[tc.ID] {
+				return history[:i]
+			}
+		}
+		break
+	}
+	return history
+}
+
 func agenticHistoryFromMessages(history []adk.Message) []*schema.AgenticMessage {
 	agenticHistory := make([]*schema.AgenticMessage, 0, len(history))
 	for _, msg := range history {
@@ -2097,25 +3209,36 @@ func agenticHistoryFromMessages(history []adk.Message) []*schema.AgenticMessage
 	return agenticHistory
 }
 
+// normalizeConversationHistory makes the first message after any leading
+// system messages a user message, as providers require. Leading system
+// messages are hoisted out before the check, so a dropped or inserted user
+// turn never ends up ahead of them. Non-system messages before the first
+// user turn are dropped, and fallbackFirstUser is inserted when there is no
+// user turn at all.
 func normalizeConversationHistory(history []adk.Message, fallbackFirstUser string) ([]adk.Message, bool) {
 	if len(history) == 0 {
 		return history, false
 	}
 
-	firstUserIdx := -1
-	for i, msg := range history {
-		if msg.Role == schema.User {
-			firstUserIdx = i
-			break
-		}
+	leading := 0
+	for leading < len(history) && history[leading] != nil && history[leading].Role == schema.System {
+		leading++
 	}
-
-	if firstUserIdx == 0 {
+	system, rest := history[:leading], history[leading:]
+	if len(rest) > 0 && rest[0] != nil && rest[0].Role == schema.User {
 		return history, false
 	}
 
 	normalized := make([]adk.Message, 0, len(history)+1)
-	changed := false
+	normalized = append(normalized, system...)
+
+	firstUserIdx := -1
+	for i, msg := range rest {
+		if msg != nil && msg.Role == schema.User {
+			firstUserIdx = i
+			break
+		}
+	}
 
 	if firstUserIdx == -1 {
 		trimmed := strings.TrimSpace(fallbackFirstUser)
@@ -2123,21 +3246,17 @@ func normalizeConversationHistory(history []adk.Message, fallbackFirstUser strin
 			trimmed = "Previous session restored without the original user prompt. Continue the documentation workflow from the latest assistant response."
 		}
 		normalized = append(normalized, &schema.Message{Role: schema.User, Content: trimmed})
-		normalized = append(normalized, history...)
+		normalized = append(normalized, rest...)
 		return normalized, true
 	}
 
-	for _, msg := range history[:firstUserIdx] {
-		if msg.Role == schema.System {
+	// Later system messages are hoisted too; everything else before the
+	// first user turn is dropped
+	for _, msg := range rest[:firstUserIdx] {
+		if msg != nil && msg.Role == schema.System {
 			normalized = append(normalized, msg)
-			continue
 		}
-		changed = true
-	}
-
-	normalized = append(normalized, history[firstUserIdx:]...)
-	if !changed && len(normalized) == len(history) {
-		return history, false
 	}
+	normalized = append(normalized, rest[firstUserIdx:]...)
 	return normalized, true
 }
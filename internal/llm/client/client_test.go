@@ -1,12 +1,28 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/tools"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"github.com/openai/openai-go/v3/responses"
+	"google.golang.org/genai"
 )
 
 func msg(role schema.RoleType, content string) adk.Message {
@@ -50,6 +66,46 @@ func TestNormalizeConversationHistory_AllowsLeadingSystem(t *testing.T) {
 	}
 }
 
+func TestNormalizeConversationHistory_HoistsSystemAboveFallbackUser(t *testing.T) {
+	original := []adk.Message{
+		msg(schema.System, "sys"),
+		msg(schema.Assistant, "reply"),
+	}
+
+	result, changed := normalizeConversationHistory(original, "fallback message")
+
+	if !changed {
+		t.Fatalf("expected change when the system-led history has no user turn")
+	}
+	if len(result) != 3 {
+		t.Fatalf("unexpected length: got %d want 3", len(result))
+	}
+	if result[0].Role != schema.System || result[1].Role != schema.User || result[1].Content != "fallback message" || result[2].Content != "reply" {
+		t.Fatalf("expected system, fallback user, assistant; got %v", result)
+	}
+}
+
+func TestNormalizeConversationHistory_DropsAssistantAfterLeadingSystem(t *testing.T) {
+	original := []adk.Message{
+		msg(schema.System, "sys"),
+		msg(schema.Assistant, "intro"),
+		msg(schema.User, "question"),
+		msg(schema.Assistant, "answer"),
+	}
+
+	result, changed := normalizeConversationHistory(original, "fallback")
+
+	if !changed {
+		t.Fatalf("expected change when an assistant message follows the leading system")
+	}
+	if len(result) != 3 {
+		t.Fatalf("unexpected length: got %d want 3", len(result))
+	}
+	if result[0].Role != schema.System || result[1].Role != schema.User || result[1].Content != "question" {
+		t.Fatalf("expected system then the first user turn, got %v", result)
+	}
+}
+
 func TestNormalizeConversationHistory_DropsLeadingAssistant(t *testing.T) {
 	original := []adk.Message{
 		msg(schema.Assistant, "intro"),
@@ -134,6 +190,162 @@ func TestOpenAIResponsesReasoning_MapsHigh(t *testing.T) {
 	}
 }
 
+func TestClaudeThinking_ShowReasoningEnablesLowEffort(t *testing.T) {
+	if cfg := claudeThinking("low", nil, false); cfg.Enable {
+		t.Fatalf("expected thinking disabled for low effort without reasoning display")
+	}
+	cfg := claudeThinking("low", nil, true)
+	if !cfg.Enable || cfg.BudgetTokens != minThinkingBudgetTokens {
+		t.Fatalf("unexpected thinking config: %+v", cfg)
+	}
+	disabled := false
+	if cfg := claudeThinking("high", &disabled, true); cfg.Enable {
+		t.Fatalf("expected Thinking=false to override reasoning display")
+	}
+}
+
+func TestGeminiThinking_IncludesThoughtsOnlyWhenShown(t *testing.T) {
+	if _, include := geminiThinking("medium", nil, false); include {
+		t.Fatalf("expected thoughts to be excluded when reasoning is hidden")
+	}
+	budget, include := geminiThinking("low", nil, true)
+	if !include || budget == nil || *budget != minThinkingBudgetTokens {
+		t.Fatalf("unexpected thinking config: budget=%v include=%v", budget, include)
+	}
+	disabled := false
+	budget, include = geminiThinking("medium", &disabled, true)
+	if include || budget == nil || *budget != 0 {
+		t.Fatalf("expected Thinking=false to disable thinking: budget=%v include=%v", budget, include)
+	}
+}
+
+func TestEmitReasoning_SuppressedWhenHidden(t *testing.T) {
+	var emitted int
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) { emitted++ }
+	defer func() { events.Emit = previous }()
+
+	hidden := &LLMClient{}
+	hidden.broadcastReasoningContent(context.Background(), &schema.Message{ReasoningContent: "thinking"})
+	if emitted != 0 {
+		t.Fatalf("expected no reasoning events, got %d", emitted)
+	}
+
+	shown := &LLMClient{showReasoning: true}
+	shown.broadcastReasoningContent(context.Background(), &schema.Message{ReasoningContent: "thinking"})
+	if emitted != 2 {
+		t.Fatalf("expected reset and update events, got %d", emitted)
+	}
+}
+
+func TestConsumeStreamingMessage_StreamsInterleavedContent(t *testing.T) {
+	var got []string
+	previous := events.Emit
+	events.Emit = func(_ context.Context, _ string, evt events.ToolEvent) {
+		got = append(got, fmt.Sprintf("%s:%s:%s", evt.Kind, evt.Metadata[streamMetadataStateKey], evt.Message))
+	}
+	defer func() { events.Emit = previous }()
+
+	chunks := []*schema.Message{
+		{Role: schema.Assistant, ReasoningContent: "Look"},
+		{Role: schema.Assistant, ReasoningContent: "ing"},
+		{Role: schema.Assistant, Content: "Updated "},
+		{Role: schema.Assistant, Content: "the guide."},
+		{Role: schema.Assistant, ReasoningContent: "Done"},
+		{Role: schema.Assistant, Content: " Nothing else."},
+	}
+	o := &LLMClient{showReasoning: true}
+	msg, err := o.consumeStreamingMessage(context.Background(), schema.StreamReaderFromArray(chunks))
+	if err != nil {
+		t.Fatalf("consumeStreamingMessage: %v", err)
+	}
+	if msg.Content != "Updated the guide. Nothing else." {
+		t.Fatalf("unexpected concatenated content %q", msg.Content)
+	}
+	want := []string{
+		"reasoning:reset:", "reasoning:update:Look", "reasoning:update:Looking",
+		"content:reset:", "content:update:Updated ", "content:update:Updated the guide.",
+		"reasoning:reset:", "reasoning:update:Done",
+		"content:reset:", "content:update: Nothing else.",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected events:\n got %q\nwant %q", got, want)
+	}
+
+	got = nil
+	hidden := &LLMClient{}
+	if _, err := hidden.consumeStreamingMessage(context.Background(), schema.StreamReaderFromArray(chunks)); err != nil {
+		t.Fatalf("consumeStreamingMessage: %v", err)
+	}
+	want = []string{"content:reset:", "content:update:Updated ", "content:update:Updated the guide.", "content:update:Updated the guide. Nothing else."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected hidden reasoning not to split the content stream:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestRecordTokenUsage_ActsOnceWhenBudgetCrossed(t *testing.T) {
+	var warnings int
+	previous := events.Emit
+	events.Emit = func(_ context.Context, _ string, evt events.ToolEvent) {
+		if evt.Type == events.EventWarn {
+			warnings++
+		}
+	}
+	defer func() { events.Emit = previous }()
+
+	sessionID := "reasoning-budget-disable"
+	ctx := tools.ContextWithSession(context.Background(), sessionID)
+	defer tools.ClearSession(sessionID)
+
+	c := &LLMClient{}
+	c.budget.disableOptions = []model.Option{model.WithTemperature(0)}
+	c.SetReasoningBudget(100, ReasoningBudgetDisable)
+	usage := func(reasoning int) *schema.TokenUsage {
+		return &schema.TokenUsage{CompletionTokensDetails: schema.CompletionTokensDetails{ReasoningTokens: reasoning}}
+	}
+
+	for _, reasoning := range []int{60, 60, 60} {
+		if err := c.recordTokenUsage(ctx, usage(reasoning), ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("expected a single warning, got %d", warnings)
+	}
+	if !c.budget.thinkingOff.Load() {
+		t.Fatalf("expected thinking to be switched off")
+	}
+	if got := tools.RunStatsForSession(ctx).ReasoningTokens; got != 180 {
+		t.Fatalf("expected 180 reasoning tokens, got %d", got)
+	}
+
+	wrapped := newBudgetedChatModel(nil, &c.budget).(*budgetedChatModel)
+	if got := len(wrapped.options(nil)); got != 1 {
+		t.Fatalf("expected the disable option to be appended, got %d options", got)
+	}
+	c.resetReasoningBudget()
+	if got := len(wrapped.options(nil)); got != 0 {
+		t.Fatalf("expected no options after reset, got %d", got)
+	}
+}
+
+func TestRecordTokenUsage_AbortEstimatesUnreportedReasoning(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	sessionID := "reasoning-budget-abort"
+	ctx := tools.ContextWithSession(context.Background(), sessionID)
+	defer tools.ClearSession(sessionID)
+
+	c := &LLMClient{}
+	c.SetReasoningBudget(10, ReasoningBudgetAbort)
+	err := c.recordTokenUsage(ctx, &schema.TokenUsage{}, strings.Repeat("think ", 10))
+	if err == nil || !strings.HasPrefix(err.Error(), "ERR_REASONING_BUDGET:") {
+		t.Fatalf("expected a reasoning budget error, got %v", err)
+	}
+}
+
 func TestAgenticTextContent_ExtractsAssistantText(t *testing.T) {
 	msg := &schema.AgenticMessage{
 		Role: schema.AgenticRoleTypeAssistant,
@@ -178,3 +390,1367 @@ func TestAgenticReasoningContent_PreservesLeadingSpaces(t *testing.T) {
 		t.Fatalf("unexpected reasoning content: got %q", got)
 	}
 }
+
+func TestLoadConversationHistoryJSON_RejectsLeadingAssistant(t *testing.T) {
+	c := &LLMClient{}
+	raw := `[{"role":"assistant","content":"intro"},{"role":"user","content":"question"}]`
+
+	if _, err := c.LoadConversationHistoryJSON(raw, HistoryLimit{}); err == nil {
+		t.Fatalf("expected ordering error")
+	}
+	if c.HasConversationHistory() {
+		t.Fatalf("expected history to stay empty after rejected load")
+	}
+}
+
+func TestRestoreConversationHistoryJSON_RepairsOrdering(t *testing.T) {
+	c := &LLMClient{}
+	raw := `[{"role":"assistant","content":"intro"},{"role":"user","content":"question"},{"role":"assistant","content":"answer"}]`
+
+	if err := c.RestoreConversationHistoryJSON(raw, "fallback"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.LastAssistantMessage(); got != "answer" {
+		t.Fatalf("unexpected last assistant message: %q", got)
+	}
+	if _, err := c.LoadConversationHistoryJSON(mustHistoryJSON(t, c), HistoryLimit{}); err != nil {
+		t.Fatalf("expected repaired history to load strictly: %v", err)
+	}
+}
+
+func TestSplitHistoryForCompaction_KeepsRecentTurns(t *testing.T) {
+	history := []adk.Message{
+		msg(schema.User, "one"), msg(schema.Assistant, "a1"),
+		msg(schema.User, "two"), msg(schema.Assistant, "a2"),
+		msg(schema.User, "three"), msg(schema.Assistant, "a3"),
+	}
+
+	if _, _, ok := splitHistoryForCompaction(history, HistoryCompaction{MaxMessages: 6, KeepTurns: 1}); ok {
+		t.Fatalf("expected no compaction within the limits")
+	}
+	older, recent, ok := splitHistoryForCompaction(history, HistoryCompaction{MaxChars: 10, KeepTurns: 2})
+	if !ok {
+		t.Fatalf("expected compaction over the character limit")
+	}
+	if len(older) != 2 || len(recent) != 4 || recent[0].Content != "two" {
+		t.Fatalf("unexpected split: %d older, %d recent starting with %q", len(older), len(recent), recent[0].Content)
+	}
+	if _, _, ok := splitHistoryForCompaction(history, HistoryCompaction{MaxMessages: 1, KeepTurns: 3}); ok {
+		t.Fatalf("expected no compaction without older turns")
+	}
+}
+
+type summaryChatModel struct {
+	model.ToolCallingChatModel
+	input []*schema.Message
+}
+
+func (m *summaryChatModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.input = input
+	return schema.AssistantMessage("- renamed the install guide", nil), nil
+}
+
+func TestCompactConversationHistory_SummarizesOlderTurns(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	chat := &summaryChatModel{}
+	c := &LLMClient{chatModel: chat}
+	c.SetHistoryCompaction(HistoryCompaction{MaxMessages: 4, KeepTurns: 1})
+	c.conversationHistory = []adk.Message{
+		msg(schema.User, "rename the install guide"),
+		{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{ID: "1", Function: schema.FunctionCall{Name: "move_file", Arguments: `{"from":"install.md"}`}}}},
+		{Role: schema.Tool, ToolCallID: "1", ToolName: "move_file", Content: "moved"},
+		msg(schema.Assistant, "renamed"),
+		msg(schema.User, "now fix the links"),
+		msg(schema.Assistant, "fixed"),
+	}
+
+	c.compactConversationHistory(context.Background())
+
+	if len(chat.input) != 2 || !strings.Contains(chat.input[1].Content, "move_file") {
+		t.Fatalf("expected the older turns in the summary request, got %v", chat.input)
+	}
+	history := c.conversationHistory
+	if len(history) != 2 || history[0].Role != schema.User || history[1].Content != "fixed" {
+		t.Fatalf("unexpected compacted history: %v", history)
+	}
+	if !strings.Contains(history[0].Content, "- renamed the install guide") || !strings.HasSuffix(history[0].Content, "now fix the links") {
+		t.Fatalf("expected the summary before the kept user message, got %q", history[0].Content)
+	}
+	if len(c.agenticHistory) != 2 {
+		t.Fatalf("expected agentic history to follow, got %d messages", len(c.agenticHistory))
+	}
+	if _, err := c.LoadConversationHistoryJSON(mustHistoryJSON(t, c), HistoryLimit{}); err != nil {
+		t.Fatalf("expected compacted history to load strictly: %v", err)
+	}
+}
+
+func TestCompactConversationHistory_UsesSummarizerModel(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	session := &summaryChatModel{}
+	summarizer := &summaryChatModel{}
+	c := &LLMClient{chatModel: session}
+	c.SetHistoryCompaction(HistoryCompaction{MaxMessages: 2, KeepTurns: 1, Summarizer: &LLMClient{chatModel: summarizer}})
+	c.conversationHistory = []adk.Message{
+		msg(schema.User, "rename the install guide"),
+		msg(schema.Assistant, "renamed"),
+		msg(schema.User, "now fix the links"),
+		msg(schema.Assistant, "fixed"),
+	}
+
+	c.compactConversationHistory(context.Background())
+
+	if session.input != nil {
+		t.Fatalf("expected the session model not to write the summary, got %v", session.input)
+	}
+	if len(summarizer.input) != 2 || !strings.Contains(summarizer.input[1].Content, "rename the install guide") {
+		t.Fatalf("expected the summarizer to get the older turns, got %v", summarizer.input)
+	}
+	if !strings.Contains(c.conversationHistory[0].Content, "- renamed the install guide") {
+		t.Fatalf("expected the summarizer's summary in the history, got %q", c.conversationHistory[0].Content)
+	}
+}
+
+func TestRunSummary_SkipsEmptyFinalStreamedMessage(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	o := &LLMClient{}
+	meaningful := "Updated the installation guide for the new CLI flags and added a troubleshooting section for proxy errors."
+	streamed := [][]*schema.Message{
+		{{Role: schema.Assistant, Content: "I'll read the guide first."}, {Role: schema.Assistant, ToolCalls: []schema.ToolCall{{ID: "1", Function: schema.FunctionCall{Name: "read_file_tool"}}}}},
+		{{Role: schema.Assistant, Content: meaningful[:40]}, {Role: schema.Assistant, Content: meaningful[40:]}},
+		{{Role: schema.Assistant, Content: ""}, {Role: schema.Assistant, Content: "  "}},
+	}
+	var summary runSummary
+	var history []adk.Message
+	for _, chunks := range streamed {
+		m, err := o.consumeStreamingMessage(context.Background(), schema.StreamReaderFromArray(chunks))
+		if err != nil {
+			t.Fatalf("consumeStreamingMessage: %v", err)
+		}
+		history = append(history, m)
+		summary.observe(m)
+	}
+
+	if got := o.finalSummary(context.Background(), &summary, history); got != meaningful {
+		t.Fatalf("expected the earlier substantive message, got %q", got)
+	}
+
+	// A thin summary is replaced by an explicit summary turn when enabled
+	chat := &summaryChatModel{}
+	o = &LLMClient{chatModel: chat}
+	o.SetFinalSummaryTurn(true)
+	thin := runSummary{text: "Done."}
+	if got := o.finalSummary(context.Background(), &thin, history); got != "- renamed the install guide" {
+		t.Fatalf("expected the requested summary, got %q", got)
+	}
+	if len(chat.input) != 2 || !strings.Contains(chat.input[1].Content, "read_file_tool") {
+		t.Fatalf("expected the run transcript in the summary request, got %v", chat.input)
+	}
+	chat.input = nil
+	if got := o.finalSummary(context.Background(), &summary, history); got != meaningful || chat.input != nil {
+		t.Fatalf("expected a substantive summary to be kept without asking the model, got %q", got)
+	}
+}
+
+func mustHistoryJSON(t *testing.T, c *LLMClient) string {
+	t.Helper()
+	out, err := c.ConversationHistoryJSON()
+	if err != nil {
+		t.Fatalf("failed to serialize history: %v", err)
+	}
+	return out
+}
+
+func TestTrimHistoryToLimit_KeepsFirstMessageAndRecentTurns(t *testing.T) {
+	call := &schema.Message{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{ID: "call-1"}}}
+	result := &schema.Message{Role: schema.Tool, ToolCallID: "call-1", Content: "file"}
+	history := []adk.Message{
+		msg(schema.User, "one"), msg(schema.Assistant, "a1"),
+		msg(schema.User, "two"), msg(schema.Assistant, "a2"),
+		msg(schema.User, "three"), call, result, msg(schema.Assistant, "a3"),
+	}
+
+	// Exactly at the limit nothing is dropped
+	if got, dropped := trimHistoryToLimit(history, HistoryLimit{MaxMessages: 8}); dropped != 0 || len(got) != 8 {
+		t.Fatalf("expected history at the limit to be kept, got %d messages and %d dropped", len(got), dropped)
+	}
+
+	// One over the limit drops only the first reply
+	if got, dropped := trimHistoryToLimit(history, HistoryLimit{MaxMessages: 7}); dropped != 1 || got[1].Content != "two" {
+		t.Fatalf("expected only the first reply to be dropped, got %d dropped", dropped)
+	}
+
+	// The cut falls on a turn boundary rather than inside the middle turn
+	trimmed, dropped := trimHistoryToLimit(history, HistoryLimit{MaxMessages: 6})
+	if dropped != 3 || len(trimmed) != 5 {
+		t.Fatalf("expected 3 dropped and 5 kept, got %d dropped and %d kept", dropped, len(trimmed))
+	}
+	if trimmed[0].Content != "one" || trimmed[1].Content != "three" || trimmed[2] != call || trimmed[3] != result {
+		t.Fatalf("expected the first message followed by the last turn, got %+v", trimmed)
+	}
+
+	// A limit only the latest turn fits in still keeps that whole turn
+	if got, dropped := trimHistoryToLimit(history, HistoryLimit{MaxMessages: 2}); dropped != 3 || len(got) != 5 {
+		t.Fatalf("expected the latest turn to be kept whole, got %d messages and %d dropped", len(got), dropped)
+	}
+
+	// Character limits trim at the same boundaries
+	if got, dropped := trimHistoryToLimit(history, HistoryLimit{MaxChars: len("one") + len("twoa2") + len("threefilea3")}); dropped != 1 || got[1].Content != "two" {
+		t.Fatalf("expected the char limit to drop only the first reply, got %d dropped", dropped)
+	}
+
+	// A single turn cannot be trimmed
+	single := []adk.Message{msg(schema.User, "one"), call, result, msg(schema.Assistant, "a1")}
+	if got, dropped := trimHistoryToLimit(single, HistoryLimit{MaxMessages: 2}); dropped != 0 || len(got) != 4 {
+		t.Fatalf("expected a single turn to be left alone, got %d messages and %d dropped", len(got), dropped)
+	}
+}
+
+func TestLoadConversationHistoryJSON_TrimsOverLimit(t *testing.T) {
+	source := &LLMClient{}
+	source.conversationHistory = []adk.Message{
+		msg(schema.User, "one"), msg(schema.Assistant, "a1"),
+		msg(schema.User, "two"), msg(schema.Assistant, "a2"),
+		msg(schema.User, "three"), msg(schema.Assistant, "a3"),
+	}
+	raw := mustHistoryJSON(t, source)
+
+	c := &LLMClient{}
+	dropped, err := c.LoadConversationHistoryJSON(raw, HistoryLimit{MaxMessages: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dropped != 3 {
+		t.Fatalf("expected 3 dropped messages, got %d", dropped)
+	}
+	if len(c.conversationHistory) != 3 || c.conversationHistory[0].Content != "one" || c.conversationHistory[1].Content != "three" {
+		t.Fatalf("unexpected trimmed history: %+v", c.conversationHistory)
+	}
+	if len(c.agenticHistory) != 3 {
+		t.Fatalf("expected agentic history to follow, got %d messages", len(c.agenticHistory))
+	}
+
+	leading := `[{"role":"assistant","content":"intro"},{"role":"user","content":"q1"},{"role":"user","content":"q2"}]`
+	if _, err := c.LoadConversationHistoryJSON(leading, HistoryLimit{MaxMessages: 2}); err == nil {
+		t.Fatalf("expected ordering error after trimming")
+	}
+}
+
+func TestTrimDanglingToolCalls_DropsUnansweredRound(t *testing.T) {
+	call := &schema.Message{
+		Role:      schema.Assistant,
+		ToolCalls: []schema.ToolCall{{ID: "call-1"}, {ID: "call-2"}},
+	}
+	history := []adk.Message{
+		msg(schema.User, "question"),
+		msg(schema.Assistant, "thinking"),
+		call,
+		&schema.Message{Role: schema.Tool, ToolCallID: "call-1", Content: "result"},
+	}
+
+	result := trimDanglingToolCalls(history)
+
+	if len(result) != 2 {
+		t.Fatalf("unexpected length: got %d want 2", len(result))
+	}
+}
+
+func TestTrimDanglingToolCalls_KeepsAnsweredRound(t *testing.T) {
+	call := &schema.Message{
+		Role:      schema.Assistant,
+		ToolCalls: []schema.ToolCall{{ID: "call-1"}},
+	}
+	history := []adk.Message{
+		msg(schema.User, "question"),
+		call,
+		&schema.Message{Role: schema.Tool, ToolCallID: "call-1", Content: "result"},
+		msg(schema.Assistant, "done"),
+	}
+
+	result := trimDanglingToolCalls(history)
+
+	if len(result) != len(history) {
+		t.Fatalf("unexpected length: got %d want %d", len(result), len(history))
+	}
+}
+
+func TestInitDocumentationTools_AppliesDocsIgnoreFile(t *testing.T) {
+	docRoot := t.TempDir()
+	codeRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(docRoot, "archive"), 0o755); err != nil {
+		t.Fatalf("mkdir archive: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docRoot, "archive", "old.md"), []byte("# Old\n"), 0o644); err != nil {
+		t.Fatalf("write old.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docRoot, "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatalf("write guide.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docRoot, tools.IgnoreFileName), []byte("# vendored docs\narchive/\n"), 0o644); err != nil {
+		t.Fatalf("write ignore file: %v", err)
+	}
+
+	o := &LLMClient{workspaceID: "ignore-file-session"}
+	defer tools.ClearSession(o.workspaceID)
+	if _, err := o.initDocumentationTools(docRoot, codeRoot); err != nil {
+		t.Fatalf("initDocumentationTools: %v", err)
+	}
+
+	ctx := tools.ContextWithSession(context.Background(), o.workspaceID)
+	out, err := tools.ListDirectory(ctx, &tools.ListLSInput{Repository: tools.RepositoryDocs, Path: "."})
+	if err != nil {
+		t.Fatalf("ListDirectory: %v", err)
+	}
+	if strings.Contains(out.Output, "archive") {
+		t.Fatalf("expected archive to be hidden by %s; output: %s", tools.IgnoreFileName, out.Output)
+	}
+	if !strings.Contains(out.Output, "guide.md") {
+		t.Fatalf("expected guide.md to be listed; output: %s", out.Output)
+	}
+	if got := tools.RepositoryIgnorePatternsForSession(o.workspaceID, tools.RepositoryCode); len(got) != 0 {
+		t.Fatalf("expected no code ignore patterns, got %v", got)
+	}
+}
+
+func TestLoadRepoLLMInstructions_MergesFilesInNameOrder(t *testing.T) {
+	docRoot := t.TempDir()
+	dir := filepath.Join(docRoot, ".narrabyte")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		"llm_instructions.style.md": "Use sentence case.\n",
+		"llm_instructions.api.md":   "Document every endpoint.\n",
+		"notes.md":                  "not an instruction file",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	var loaded string
+	previous := events.Emit
+	events.Emit = func(_ context.Context, _ string, evt events.ToolEvent) { loaded = evt.Message }
+	defer func() { events.Emit = previous }()
+
+	o := &LLMClient{}
+	got, err := o.loadRepoLLMInstructions(context.Background(), docRoot)
+	if err != nil {
+		t.Fatalf("loadRepoLLMInstructions: %v", err)
+	}
+	want := "Document every endpoint." + llmInstructionsSeparator + "Use sentence case."
+	if got != want {
+		t.Fatalf("unexpected instructions: %q", got)
+	}
+	if loaded != "LLMInstructions: loaded llm_instructions.api.md, llm_instructions.style.md" {
+		t.Fatalf("unexpected event: %q", loaded)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "llm_instructions.api.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	got, err = o.loadRepoLLMInstructions(context.Background(), docRoot)
+	if err != nil {
+		t.Fatalf("loadRepoLLMInstructions: %v", err)
+	}
+	if got != "Use sentence case.\n" {
+		t.Fatalf("expected single file contents unchanged, got %q", got)
+	}
+}
+
+func TestLoadRepoLLMInstructions_SkipsUneditedTemplate(t *testing.T) {
+	docRoot := t.TempDir()
+	dir := filepath.Join(docRoot, ".narrabyte")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	template := strings.ReplaceAll(LLMInstructionsTemplate(), "\n", "\r\n")
+	if err := os.WriteFile(filepath.Join(dir, "llm_instructions.md"), []byte(template), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	o := &LLMClient{}
+	got, err := o.loadRepoLLMInstructions(context.Background(), docRoot)
+	if err != nil {
+		t.Fatalf("loadRepoLLMInstructions: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("expected the template to be skipped, got %q", got)
+	}
+
+	edited := LLMInstructionsTemplate() + "\nAlways link to the changelog.\n"
+	if err := os.WriteFile(filepath.Join(dir, "llm_instructions.md"), []byte(edited), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got, _ = o.loadRepoLLMInstructions(context.Background(), docRoot); got != edited {
+		t.Fatalf("expected the edited file to be loaded, got %q", got)
+	}
+}
+
+func TestExpandInstructionVars_SubstitutesKnownValues(t *testing.T) {
+	cfg := promptBuilderConfig{
+		ProjectName:  "narrabyte",
+		SourceBranch: "feature/login",
+		ChangedFiles: []ChangedFile{{Path: "internal/auth.go", Status: "modified"}, {Path: "web/login.tsx", Status: "added"}},
+	}
+	got := expandInstructionVars("Document {{project}} changes on {{ source_branch }}: {{changed_files}} ({{date}})", instructionVars(cfg))
+	want := "Document narrabyte changes on feature/login: internal/auth.go, web/login.tsx (" + time.Now().Format("2006-01-02") + ")"
+	if got != want {
+		t.Fatalf("unexpected expansion:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestFormatChangedFiles_MarksDeletionsAndRenames(t *testing.T) {
+	got := formatChangedFiles([]ChangedFile{
+		{Path: "internal/auth.go", Status: "modified"},
+		{Path: "internal/legacy.go", Status: "deleted"},
+		{Path: "internal/login.go", OldPath: "internal/session.go", Status: "renamed"},
+	})
+	want := "- internal/auth.go\n- internal/legacy.go (deleted)\n- internal/login.go (renamed from internal/session.go)"
+	if got != want {
+		t.Fatalf("unexpected changed files:\n got: %q\nwant: %q", got, want)
+	}
+	if formatChangedFiles(nil) != "(none)" {
+		t.Fatalf("expected a placeholder without changed files")
+	}
+}
+
+func TestFormatFileGroups_SplitsByDirectoryAndSize(t *testing.T) {
+	files := []ChangedFile{
+		{Path: "internal/b.go"},
+		{Path: "main.go"},
+		{Path: "internal/a.go"},
+		{Path: "internal/c.go"},
+		{Path: "cmd/run.go"},
+	}
+	got := formatFileGroups(files, 2)
+	want := "## Group 1: cmd (1 files)\n- cmd/run.go\n\n" +
+		"## Group 2: internal (2 files)\n- internal/a.go\n- internal/b.go\n\n" +
+		"## Group 3: internal (1 files)\n- internal/c.go\n\n" +
+		"## Group 4: . (1 files)\n- main.go"
+	if got != want {
+		t.Fatalf("unexpected groups:\n got: %q\nwant: %q", got, want)
+	}
+
+}
+
+func TestBootstrapChunks_PacksWholeGroups(t *testing.T) {
+	files := []ChangedFile{
+		{Path: "cmd/run.go"},
+		{Path: "internal/a.go"},
+		{Path: "internal/b.go"},
+		{Path: "internal/c.go"},
+		{Path: "main.go"},
+	}
+	chunks := bootstrapChunks(files, 2, 3)
+	var got []string
+	for _, chunk := range chunks {
+		var paths []string
+		for _, f := range chunk {
+			paths = append(paths, f.Path)
+		}
+		got = append(got, strings.Join(paths, ","))
+	}
+	want := []string{"cmd/run.go,internal/a.go,internal/b.go", "internal/c.go,main.go"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected chunks %q, want %q", got, want)
+	}
+	if n := len(bootstrapChunks(files, 2, 10)); n != 1 {
+		t.Fatalf("expected a small codebase to fit one run, got %d parts", n)
+	}
+}
+
+func TestWriteCodeContext_BootstrapChunkCarriesEarlierParts(t *testing.T) {
+	var b strings.Builder
+	writeCodeContext(&b, &DocGenerationRequest{
+		FullBootstrap: true,
+		ChangedFiles:  []ChangedFile{{Path: "internal/c.go"}},
+		chunk:         &bootstrapChunk{index: 1, total: 2, totalFiles: 5, previous: "## Part 1 of 2 (cmd)\nAdded docs/cli.md."},
+	}, PromptSections{})
+	got := b.String()
+	for _, want := range []string{
+		"# Bootstrap Progress\nThis is part 2 of 2 of a full bootstrap of 5 code files.",
+		"<previous_parts>\n## Part 1 of 2 (cmd)\nAdded docs/cli.md.\n</previous_parts>",
+		"# Codebase Files\n## Group 1: internal (1 files)\n- internal/c.go",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in chunk context, got %q", want, got)
+		}
+	}
+	if (&bootstrapChunk{index: 1, total: 2}).pending() || !(&bootstrapChunk{index: 0, total: 2}).pending() {
+		t.Fatalf("only parts before the last should be pending")
+	}
+}
+
+func TestWriteCodeContext_FullBootstrapOmitsDiff(t *testing.T) {
+	var b strings.Builder
+	writeCodeContext(&b, &DocGenerationRequest{FullBootstrap: true, ChangedFiles: []ChangedFile{{Path: "main.go"}}}, PromptSections{})
+	got := b.String()
+	if !strings.HasPrefix(got, "# Codebase Files\n") || strings.Contains(got, "<git_diff>") {
+		t.Fatalf("unexpected full bootstrap context: %q", got)
+	}
+
+	b.Reset()
+	writeCodeContext(&b, &DocGenerationRequest{Diff: "diff --git a/main.go b/main.go", ChangedFiles: []ChangedFile{{Path: "main.go"}}}, PromptSections{})
+	if !strings.Contains(b.String(), "# Changed Files\n- main.go\n\n# Code Changes\n<git_diff>\ndiff --git") {
+		t.Fatalf("unexpected diff context: %q", b.String())
+	}
+}
+
+func TestDocGenerationContext_WorktreeDropsTargetBranch(t *testing.T) {
+	got := docGenerationContext(&DocGenerationRequest{SourceBranch: "main", TargetBranch: "main", Worktree: true})
+	if _, ok := got["Target branch"]; ok {
+		t.Fatalf("worktree runs have no target branch: %v", got)
+	}
+	if got["Source branch"] != "main" || !strings.Contains(got["Code changes"], "uncommitted") {
+		t.Fatalf("unexpected worktree context: %v", got)
+	}
+
+	got = docGenerationContext(&DocGenerationRequest{SourceBranch: "feature", TargetBranch: "main"})
+	if got["Target branch"] != "main" || got["Code changes"] != "" {
+		t.Fatalf("unexpected branch diff context: %v", got)
+	}
+}
+
+func TestExpandInstructionVars_LeavesMissingAndUnknownLiteral(t *testing.T) {
+	vars := instructionVars(promptBuilderConfig{ProjectName: "narrabyte"})
+	got := expandInstructionVars("{{project}} / {{changed_files}} / {{audience}} / {{source_branch}}", vars)
+	want := "narrabyte / {{changed_files}} / {{audience}} / {{source_branch}}"
+	if got != want {
+		t.Fatalf("unexpected expansion: %q", got)
+	}
+}
+
+func TestBuildPromptWithInstructions_ExpandsSpecificInstructions(t *testing.T) {
+	prompt := buildPromptWithInstructions(context.Background(), promptBuilderConfig{
+		ProjectName:   "narrabyte",
+		SourceBranch:  "main",
+		SpecificInstr: "<DOCUMENTATION_TEMPLATE>Release notes for {{project}} ({{source_branch}})</DOCUMENTATION_TEMPLATE>",
+	})
+	if !strings.Contains(prompt, "Release notes for narrabyte (main)") {
+		t.Fatalf("expected expanded instructions in prompt:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "tagged sections encode user guidance") {
+		t.Fatalf("expected tag guidance in prompt:\n%s", prompt)
+	}
+}
+
+func TestBuildPromptWithInstructions_OmitsDisabledSections(t *testing.T) {
+	cfg := promptBuilderConfig{
+		ProjectName:  "narrabyte",
+		DocListing:   "docs/index.md",
+		CodeListing:  "main.go",
+		ExtraContext: map[string]string{"Source branch": "main", sourceCommitContextKey: "0123456 (Add retries)"},
+	}
+	prompt := buildPromptWithInstructions(context.Background(), cfg)
+	for _, section := range []string{"<documentation_repo_listing>", "<codebase_repo_listing>", "Source commit: 0123456"} {
+		if !strings.Contains(prompt, section) {
+			t.Fatalf("expected %q in the default prompt:\n%s", section, prompt)
+		}
+	}
+
+	cfg.Sections = PromptSections{OmitCodeListing: true, OmitCommitContext: true}
+	prompt = buildPromptWithInstructions(context.Background(), cfg)
+	if strings.Contains(prompt, "<codebase_repo_listing>") || strings.Contains(prompt, "main.go") || strings.Contains(prompt, "Source commit") {
+		t.Fatalf("expected the code listing and commit context to be omitted:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "<documentation_repo_listing>\ndocs/index.md") || !strings.Contains(prompt, "Source branch: main") {
+		t.Fatalf("expected the docs listing and branch context to stay:\n%s", prompt)
+	}
+
+	cfg.Sections.OmitDocsListing = true
+	if prompt = buildPromptWithInstructions(context.Background(), cfg); strings.Contains(prompt, "# Repository Structure") {
+		t.Fatalf("expected no repository structure without listings:\n%s", prompt)
+	}
+
+	var b strings.Builder
+	writeCodeContext(&b, &DocGenerationRequest{
+		Diff:          "diff --git a/main.go b/main.go",
+		ChangedFiles:  []ChangedFile{{Path: "main.go"}},
+		CommitHistory: []CommitMessage{{Hash: "0123456789abcdef", Message: "Add retries"}},
+	}, PromptSections{OmitCommitContext: true})
+	if strings.Contains(b.String(), "# Commit History") {
+		t.Fatalf("expected the commit history to be omitted: %q", b.String())
+	}
+}
+
+func TestBuildPromptWithInstructions_TruncatesOversizedListings(t *testing.T) {
+	var listing strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&listing, "src/file%03d.go\n", i)
+	}
+	prompt := buildPromptWithInstructions(context.Background(), promptBuilderConfig{
+		ProjectName: "narrabyte",
+		DocListing:  "docs/index.md",
+		CodeListing: listing.String(),
+		Sections:    PromptSections{MaxListingChars: 160},
+	})
+	start := strings.Index(prompt, "<codebase_repo_listing>\n")
+	end := strings.Index(prompt, "\n</codebase_repo_listing>")
+	if start < 0 || end < 0 {
+		t.Fatalf("expected a code listing:\n%s", prompt)
+	}
+	got := prompt[start+len("<codebase_repo_listing>\n") : end]
+	want := strings.Join(strings.Split(listing.String(), "\n")[:10], "\n") +
+		"\n(listing truncated at 160 characters; 90 more line(s) are not shown, use the list and glob tools to explore them)"
+	if got != want {
+		t.Fatalf("unexpected truncated listing:\n got: %q\nwant: %q", got, want)
+	}
+	if !strings.Contains(prompt, "<documentation_repo_listing>\ndocs/index.md\n") {
+		t.Fatalf("expected a listing under the limit to be kept whole:\n%s", prompt)
+	}
+}
+
+func TestEditAllowed_LimitsChangesToAllowedFiles(t *testing.T) {
+	root := t.TempDir()
+	o := &LLMClient{}
+	if !o.editAllowed(filepath.Join(root, "any.md")) {
+		t.Fatalf("expected every file to be allowed without an allowlist")
+	}
+
+	o.allowedFiles = allowedDocFiles(root, []string{"guide.md", "api/index.mdx", " "})
+	if !o.editAllowed(filepath.Join(root, "guide.md")) {
+		t.Fatalf("expected guide.md to be allowed")
+	}
+	if !o.editAllowed(filepath.Join(root, "api", "..", "api", "index.mdx")) {
+		t.Fatalf("expected api/index.mdx to be allowed")
+	}
+	if o.editAllowed(filepath.Join(root, "other.md")) {
+		t.Fatalf("expected other.md to be rejected")
+	}
+}
+
+func TestEditAllowed_MatchesThroughSymlinkedRoot(t *testing.T) {
+	target := t.TempDir()
+	link := filepath.Join(t.TempDir(), "docs")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	o := &LLMClient{allowedFiles: allowedDocFiles(link, []string{"guide.md", "new.md"})}
+	for _, p := range []string{
+		filepath.Join(target, "guide.md"),
+		filepath.Join(link, "guide.md"),
+		filepath.Join(target, "new.md"),
+	} {
+		if !o.editAllowed(p) {
+			t.Fatalf("expected %s to be allowed", p)
+		}
+	}
+	if o.editAllowed(filepath.Join(target, "other.md")) {
+		t.Fatalf("expected other.md to be rejected")
+	}
+}
+
+func TestTodoProgress_IgnoresCancelledTodos(t *testing.T) {
+	completed, total := todoProgress([]tools.Todo{
+		{Content: "a", Status: tools.TodoStatusCompleted},
+		{Content: "b", Status: tools.TodoStatusInProgress},
+		{Content: "c", Status: tools.TodoStatusCancelled},
+		{Content: "d", Status: tools.TodoStatusPending},
+	})
+	if completed != 1 || total != 3 {
+		t.Fatalf("todoProgress = %d/%d, want 1/3", completed, total)
+	}
+}
+
+func TestEstimatedProgress_GrowsWithoutReachingCap(t *testing.T) {
+	if got := estimatedProgress(0); got != 0 {
+		t.Fatalf("estimatedProgress(0) = %d, want 0", got)
+	}
+	if got := estimatedProgress(typicalRunToolCalls); got != maxEstimatedProgress/2 {
+		t.Fatalf("estimatedProgress(%d) = %d, want %d", typicalRunToolCalls, got, maxEstimatedProgress/2)
+	}
+	prev := 0
+	for _, calls := range []int{1, 10, 100, 10000} {
+		got := estimatedProgress(calls)
+		if got < prev || got >= maxEstimatedProgress {
+			t.Fatalf("estimatedProgress(%d) = %d, want between %d and %d", calls, got, prev, maxEstimatedProgress)
+		}
+		prev = got
+	}
+}
+
+func TestEditPolicy_AllowsEditingFileWrittenThisSession(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	docRoot := t.TempDir()
+	o := &LLMClient{workspaceID: "written-file-session"}
+	defer tools.ClearSession(o.workspaceID)
+	docTools, err := o.initDocumentationTools(docRoot, t.TempDir())
+	if err != nil {
+		t.Fatalf("initDocumentationTools: %v", err)
+	}
+	tools.SetDocsRootForSession(o.workspaceID, docRoot)
+	ctx := tools.ContextWithSession(context.Background(), o.workspaceID)
+
+	run := func(name, args string) string {
+		t.Helper()
+		for _, bt := range docTools {
+			info, err := bt.Info(ctx)
+			if err != nil || info.Name != name {
+				continue
+			}
+			out, err := bt.(tool.InvokableTool).InvokableRun(ctx, args)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			return out
+		}
+		t.Fatalf("tool %s not found", name)
+		return ""
+	}
+
+	run("write_file_tool", `{"repository":"docs","file_path":"guide.md","content":"# Guide\n\nDraft\n"}`)
+	out := run("edit_tool", `{"repository":"docs","file_path":"guide.md","old_string":"Draft","new_string":"Final"}`)
+	if strings.Contains(out, "policy_violation") {
+		t.Fatalf("expected the edit to be allowed, got %s", out)
+	}
+	data, err := os.ReadFile(filepath.Join(docRoot, "guide.md"))
+	if err != nil {
+		t.Fatalf("read guide.md: %v", err)
+	}
+	if string(data) != "# Guide\n\nFinal\n" {
+		t.Fatalf("unexpected content %q", data)
+	}
+}
+
+func TestDocsFileTools_EmitOperationAndRelativePath(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+	type fileEvent struct {
+		path string
+		op   events.DocsFileOperation
+		tool string
+	}
+	var emitted []fileEvent
+	previousFile := events.EmitDocsFileChanged
+	events.EmitDocsFileChanged = func(_ context.Context, path string, op events.DocsFileOperation, tool string) {
+		emitted = append(emitted, fileEvent{path, op, tool})
+	}
+	defer func() { events.EmitDocsFileChanged = previousFile }()
+
+	docRoot := t.TempDir()
+	o := &LLMClient{workspaceID: "docs-file-events-session"}
+	defer tools.ClearSession(o.workspaceID)
+	docTools, err := o.initDocumentationTools(docRoot, t.TempDir())
+	if err != nil {
+		t.Fatalf("initDocumentationTools: %v", err)
+	}
+	tools.SetDocsRootForSession(o.workspaceID, docRoot)
+	ctx := tools.ContextWithSession(context.Background(), o.workspaceID)
+
+	run := func(name, args string) {
+		t.Helper()
+		for _, bt := range docTools {
+			info, err := bt.Info(ctx)
+			if err != nil || info.Name != name {
+				continue
+			}
+			out, err := bt.(tool.InvokableTool).InvokableRun(ctx, args)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if strings.Contains(out, "policy_violation") {
+				t.Fatalf("%s was refused: %s", name, out)
+			}
+			return
+		}
+		t.Fatalf("tool %s not found", name)
+	}
+
+	run("write_file_tool", `{"repository":"docs","file_path":"guides/setup.md","content":"# Setup\n\nDraft\n"}`)
+	run("edit_tool", `{"repository":"docs","file_path":"guides/setup.md","old_string":"Draft","new_string":"Final"}`)
+	run("write_file_tool", `{"repository":"docs","file_path":"guides/setup.md","content":"# Setup\n\nRewritten\n"}`)
+	run("delete_file_tool", `{"repository":"docs","file_path":"guides/setup.md"}`)
+
+	want := []fileEvent{
+		{"guides/setup.md", events.DocsFileCreated, "write"},
+		{"guides/setup.md", events.DocsFileModified, "edit"},
+		{"guides/setup.md", events.DocsFileModified, "write"},
+		{"guides/setup.md", events.DocsFileDeleted, "delete"},
+	}
+	if !slices.Equal(emitted, want) {
+		t.Fatalf("unexpected docs file events:\n got %+v\nwant %+v", emitted, want)
+	}
+}
+
+func TestInitDocumentationTools_AllowlistDropsDisabledTools(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	docRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(docRoot, "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatalf("write guide.md: %v", err)
+	}
+	o := &LLMClient{workspaceID: "tool-allowlist-session"}
+	defer tools.ClearSession(o.workspaceID)
+	o.SetAllowedTools([]string{"list_directory_tool", "read_file_tool", "edit_tool"})
+	docTools, err := o.initDocumentationTools(docRoot, t.TempDir())
+	if err != nil {
+		t.Fatalf("initDocumentationTools: %v", err)
+	}
+	tools.SetDocsRootForSession(o.workspaceID, docRoot)
+	ctx := tools.ContextWithSession(context.Background(), o.workspaceID)
+
+	var names []string
+	for _, bt := range docTools {
+		info, err := bt.Info(ctx)
+		if err != nil {
+			t.Fatalf("Info: %v", err)
+		}
+		names = append(names, info.Name)
+	}
+	if strings.Join(names, ",") != "list_directory_tool,read_file_tool,edit_tool" {
+		t.Fatalf("unexpected tools %v", names)
+	}
+
+	node, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: docTools})
+	if err != nil {
+		t.Fatalf("NewToolNode: %v", err)
+	}
+	_, err = node.Invoke(ctx, &schema.Message{
+		Role: schema.Assistant,
+		ToolCalls: []schema.ToolCall{{
+			ID:       "call-1",
+			Function: schema.FunctionCall{Name: "delete_file_tool", Arguments: `{"repository":"docs","file_path":"guide.md"}`},
+		}},
+	})
+	if err == nil {
+		t.Fatal("expected calling a disabled tool to fail")
+	}
+	if _, err := os.Stat(filepath.Join(docRoot, "guide.md")); err != nil {
+		t.Fatalf("expected guide.md to survive: %v", err)
+	}
+
+	instr := o.disabledToolsInstructions()
+	if !strings.Contains(instr, "delete_file_tool") || strings.Contains(instr, " edit_tool") {
+		t.Fatalf("unexpected disabled tools instructions %q", instr)
+	}
+}
+
+func TestInitDocumentationTools_EmptyAllowlistRegistersEveryTool(t *testing.T) {
+	o := &LLMClient{workspaceID: "no-tool-allowlist-session"}
+	defer tools.ClearSession(o.workspaceID)
+	docTools, err := o.initDocumentationTools(t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Fatalf("initDocumentationTools: %v", err)
+	}
+	if len(docTools) != len(DocumentationToolNames) {
+		t.Fatalf("expected %d tools, got %d", len(DocumentationToolNames), len(docTools))
+	}
+	if instr := o.disabledToolsInstructions(); instr != "" {
+		t.Fatalf("expected no disabled tools instructions, got %q", instr)
+	}
+}
+
+func TestWriteCodeContext_IncludesCommitHistory(t *testing.T) {
+	var b strings.Builder
+	writeCodeContext(&b, &DocGenerationRequest{
+		Diff:         "diff --git a/main.go b/main.go",
+		ChangedFiles: []ChangedFile{{Path: "main.go"}},
+		CommitHistory: []CommitMessage{
+			{Hash: "0123456789abcdef", Author: "Jane", Message: "Add retries\n\nUploads now retry on timeouts."},
+			{Hash: "fedcba9876543210", Message: strings.Repeat("x", commitHistoryMaxMessageChars+10)},
+		},
+	}, PromptSections{})
+	got := b.String()
+	want := "# Commit History\n- 0123456 Add retries (Jane)\n  Uploads now retry on timeouts.\n- fedcba9 " + strings.Repeat("x", commitHistoryMaxMessageChars) + "...\n\n# Changed Files\n"
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("unexpected commit history context: %q", got)
+	}
+}
+
+func TestNewGeminiClient_SendsCustomSafetySettings(t *testing.T) {
+	var body struct {
+		SafetySettings []struct {
+			Category  string `json:"category"`
+			Threshold string `json:"threshold"`
+		} `json:"safetySettings"`
+		SystemInstruction struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"systemInstruction"`
+		Contents []struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"contents"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}]}`)
+	}))
+	defer server.Close()
+	t.Setenv("GOOGLE_GEMINI_BASE_URL", server.URL)
+
+	c, err := NewGeminiClient(context.Background(), "test-key", GeminiModelOptions{
+		Model: "gemini-test",
+		SafetySettings: []*genai.SafetySetting{
+			{Category: genai.HarmCategoryDangerousContent, Threshold: genai.HarmBlockThresholdBlockNone},
+		},
+		SystemInstruction: "Write in British English.",
+	})
+	if err != nil {
+		t.Fatalf("NewGeminiClient: %v", err)
+	}
+	if _, err := c.chatModel.Generate(context.Background(), []*schema.Message{
+		schema.SystemMessage("You write documentation."),
+		schema.UserMessage("hi"),
+	}); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	if len(body.SafetySettings) != 1 {
+		t.Fatalf("expected 1 safety setting, got %+v", body.SafetySettings)
+	}
+	if got := body.SafetySettings[0]; got.Category != string(genai.HarmCategoryDangerousContent) || got.Threshold != string(genai.HarmBlockThresholdBlockNone) {
+		t.Fatalf("unexpected safety setting: %+v", got)
+	}
+	if parts := body.SystemInstruction.Parts; len(parts) != 1 || parts[0].Text != "You write documentation.\n\nWrite in British English." {
+		t.Fatalf("expected the instruction in the request's system instruction, got %+v", parts)
+	}
+	if len(body.Contents) != 1 || body.Contents[0].Parts[0].Text != "hi" {
+		t.Fatalf("expected the prompt contents to be left alone, got %+v", body.Contents)
+	}
+}
+
+func TestDefaultGeminiSafetySettings_BlockOnlyHigh(t *testing.T) {
+	for _, setting := range DefaultGeminiSafetySettings() {
+		if setting.Threshold != genai.HarmBlockThresholdBlockOnlyHigh {
+			t.Fatalf("expected BLOCK_ONLY_HIGH for %s, got %s", setting.Category, setting.Threshold)
+		}
+	}
+}
+
+type replyAgenticModel struct {
+	model.AgenticModel
+	reply string
+	opts  []model.Option
+}
+
+func (m *replyAgenticModel) Generate(_ context.Context, _ []*schema.AgenticMessage, opts ...model.Option) (*schema.AgenticMessage, error) {
+	m.opts = opts
+	return &schema.AgenticMessage{
+		Role: schema.AgenticRoleTypeAssistant,
+		ContentBlocks: []*schema.ContentBlock{
+			schema.NewContentBlock(&schema.AssistantGenText{Text: m.reply}),
+		},
+	}, nil
+}
+
+func TestFinalAgenticSummary_ParsesStructuredSummary(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	agentic := &replyAgenticModel{reply: `{"summary":"Renamed the install guide.","files":[{"path":"install.md","rationale":"The CLI was renamed."},{"path":" ","rationale":""}],"follow_ups":["Update the screenshots"]}`}
+	c := &LLMClient{agenticModel: agentic, structuredSummary: true}
+	summary := runSummary{text: "Done."}
+
+	text, structured := c.finalAgenticSummary(context.Background(), &summary, []*schema.AgenticMessage{schema.UserAgenticMessage("rename the install guide")})
+	if text != "Renamed the install guide." {
+		t.Fatalf("unexpected summary: %q", text)
+	}
+	if structured == nil || len(structured.Files) != 1 || structured.Files[0].Path != "install.md" {
+		t.Fatalf("unexpected structured summary: %+v", structured)
+	}
+	if len(structured.FollowUps) != 1 || structured.FollowUps[0] != "Update the screenshots" {
+		t.Fatalf("unexpected follow-ups: %+v", structured.FollowUps)
+	}
+	if len(agentic.opts) != 1 {
+		t.Fatalf("expected the structured output option to be sent, got %d options", len(agentic.opts))
+	}
+}
+
+func TestFinalAgenticSummary_FallsBackWhenStructuredSummaryIsInvalid(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	c := &LLMClient{agenticModel: &replyAgenticModel{reply: "I updated the install guide."}, structuredSummary: true}
+	summary := runSummary{text: "Updated the install guide for the renamed CLI."}
+
+	text, structured := c.finalAgenticSummary(context.Background(), &summary, nil)
+	if structured != nil {
+		t.Fatalf("expected no structured summary, got %+v", structured)
+	}
+	if text != summary.text {
+		t.Fatalf("expected the tracked summary, got %q", text)
+	}
+}
+
+func TestExistingDocsContext_RanksPathMatchesThenSymbolMentions(t *testing.T) {
+	docRoot := t.TempDir()
+	pages := map[string]string{
+		"auth/index.md":   "# Auth\n",
+		"guide.mdx":       "Call `NewWidget` once.\n",
+		"reference.md":    "NewWidget and RenderWidget\nRenderWidget again\n",
+		"large.md":        "NewWidget\n" + strings.Repeat("x", 500),
+		"unrelated.md":    "# Nothing here\n",
+		"notes/widget.go": "package notes // NewWidget",
+	}
+	for rel, content := range pages {
+		path := filepath.Join(docRoot, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", rel, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	sessionID := "existing-docs-session"
+	tools.SetDocsRootForSession(sessionID, docRoot)
+	defer tools.ClearSession(sessionID)
+	ctx := tools.ContextWithSession(context.Background(), sessionID)
+
+	req := &DocGenerationRequest{
+		ChangedFiles: []ChangedFile{{Path: "internal/auth.go", Status: "modified"}, {Path: "ui/index.ts", Status: "modified"}},
+		Diff:         "+++ b/internal/widget.go\n+func NewWidget() *Widget {\n-func RenderWidget(w *Widget) {\n context func Unchanged() {",
+	}
+	got := existingDocsContext(ctx, docRoot, req, 200)
+	var paths []string
+	for _, page := range got {
+		paths = append(paths, page.Path)
+	}
+	want := []string{"auth/index.md", "reference.md", "guide.mdx"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected pages %v, got %v", want, paths)
+	}
+	if got[1].Content != pages["reference.md"] {
+		t.Fatalf("expected the page content to be included, got %q", got[1].Content)
+	}
+}
+
+func TestWriteExistingDocsContext_DisabledByDefault(t *testing.T) {
+	docRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(docRoot, "auth.md"), []byte("# Auth\n"), 0o644); err != nil {
+		t.Fatalf("write auth.md: %v", err)
+	}
+	req := &DocGenerationRequest{ChangedFiles: []ChangedFile{{Path: "auth.go", Status: "added"}}}
+
+	o := &LLMClient{}
+	var b strings.Builder
+	o.writeExistingDocsContext(context.Background(), &b, req, docRoot)
+	if b.Len() != 0 {
+		t.Fatalf("expected no existing docs section when disabled, got %q", b.String())
+	}
+
+	o.SetExistingDocsContext(1000)
+	o.writeExistingDocsContext(context.Background(), &b, req, docRoot)
+	if !strings.Contains(b.String(), "# Existing Documentation\n") || !strings.Contains(b.String(), "<doc_page path=\"auth.md\">\n# Auth\n") {
+		t.Fatalf("expected auth.md in the existing docs section, got %q", b.String())
+	}
+}
+
+// cancelingChatModel answers the first turn with a tool call and cancels the
+// run from the second, as a user pressing stop while the model works would.
+type cancelingChatModel struct {
+	model.ToolCallingChatModel
+	turns  int
+	cancel func()
+}
+
+func (m *cancelingChatModel) WithTools(_ []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func (m *cancelingChatModel) Stream(ctx context.Context, _ []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	m.turns++
+	if m.turns == 1 {
+		call := &schema.Message{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{ID: "call-1", Type: "function", Function: schema.FunctionCall{Name: "todo_read_tool", Arguments: "{}"}}}}
+		return schema.StreamReaderFromArray([]*schema.Message{call}), nil
+	}
+	m.cancel()
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGenerateDocs_CancelKeepsPartialHistory(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	docRoot := t.TempDir()
+	codeRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(docRoot, "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatalf("write guide.md: %v", err)
+	}
+
+	chat := &cancelingChatModel{}
+	o := &LLMClient{chatModel: chat}
+	chat.cancel = func() { o.CancelStream() }
+	ctx := o.StartStream(context.Background(), "cancel-session")
+	defer o.StopStream()
+
+	_, err := o.GenerateDocs(ctx, &DocGenerationRequest{
+		ProjectName:       "demo",
+		CodebasePath:      codeRoot,
+		DocumentationPath: docRoot,
+		SourceBranch:      "feature",
+		TargetBranch:      "main",
+		Diff:              "+func NewWidget() {}",
+		ChangedFiles:      []ChangedFile{{Path: "widget.go", Status: "added"}},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to be canceled, got %v", err)
+	}
+	if !o.IsCanceled() || o.IsPaused() {
+		t.Fatalf("expected the run to be flagged as canceled, not paused")
+	}
+
+	history := o.conversationHistory
+	if len(history) != 3 {
+		t.Fatalf("expected the user prompt and the answered tool call round, got %d messages: %v", len(history), history)
+	}
+	if history[0].Role != schema.User || len(history[1].ToolCalls) != 1 || history[2].Role != schema.Tool {
+		t.Fatalf("unexpected partial history: %v", history)
+	}
+	if _, err := o.TodosJSON(); err != nil {
+		t.Fatalf("expected the todo session to survive the cancellation: %v", err)
+	}
+}
+
+// promptRecordingChatModel answers every turn with a fixed reply and records
+// the last user prompt of each call.
+type promptRecordingChatModel struct {
+	model.ToolCallingChatModel
+	prompts []string
+}
+
+func (m *promptRecordingChatModel) WithTools(_ []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func (m *promptRecordingChatModel) Stream(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	for i := len(input) - 1; i >= 0; i-- {
+		if input[i].Role == schema.User {
+			m.prompts = append(m.prompts, input[i].Content)
+			break
+		}
+	}
+	reply := fmt.Sprintf("Documented part %d in docs/part-%d.md.", len(m.prompts), len(m.prompts))
+	return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage(reply, nil)}), nil
+}
+
+func TestGenerateDocs_FullBootstrapRunsEachChunk(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	var files []ChangedFile
+	for i := 0; i < fullBootstrapChunkFiles; i++ {
+		files = append(files, ChangedFile{Path: fmt.Sprintf("api/file%03d.go", i)})
+	}
+	files = append(files, ChangedFile{Path: "web/app.ts"})
+
+	chat := &promptRecordingChatModel{}
+	o := &LLMClient{chatModel: chat}
+	ctx := o.StartStream(context.Background(), "bootstrap-chunks-session")
+	defer o.StopStream()
+
+	resp, err := o.GenerateDocs(ctx, &DocGenerationRequest{
+		ProjectName:       "demo",
+		CodebasePath:      t.TempDir(),
+		DocumentationPath: t.TempDir(),
+		SourceBranch:      "main",
+		FullBootstrap:     true,
+		ChangedFiles:      files,
+	})
+	if err != nil {
+		t.Fatalf("GenerateDocs: %v", err)
+	}
+	if len(chat.prompts) != 2 {
+		t.Fatalf("expected one run per part, got %d", len(chat.prompts))
+	}
+	if strings.Contains(chat.prompts[0], "web/app.ts") || !strings.Contains(chat.prompts[0], "This is part 1 of 2") {
+		t.Fatalf("expected the first part to list only its own files")
+	}
+	second := chat.prompts[1]
+	if strings.Contains(second, "api/file000.go") || !strings.Contains(second, "- web/app.ts") {
+		t.Fatalf("expected the second part to list only its own files")
+	}
+	if !strings.Contains(second, "Documented part 1 in docs/part-1.md.") {
+		t.Fatalf("expected the first part's summary to be carried forward, got %q", second)
+	}
+	if !strings.Contains(resp.Summary, "Part 1 of 2 (api)") || !strings.Contains(resp.Summary, "Documented part 2 in docs/part-2.md.") {
+		t.Fatalf("expected the summary to cover every part, got %q", resp.Summary)
+	}
+}
+
+// pausingChatModel pauses the run on its first call and waits for the
+// cancellation, then behaves like promptRecordingChatModel.
+type pausingChatModel struct {
+	promptRecordingChatModel
+	pause  func()
+	paused bool
+}
+
+func (m *pausingChatModel) WithTools(_ []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func (m *pausingChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	if !m.paused {
+		m.paused = true
+		m.pause()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return m.promptRecordingChatModel.Stream(ctx, input, opts...)
+}
+
+func TestContinueBootstrap_DocumentsThePartsLeftByAPausedRun(t *testing.T) {
+	previous := events.Emit
+	events.Emit = func(context.Context, string, events.ToolEvent) {}
+	defer func() { events.Emit = previous }()
+
+	var files []ChangedFile
+	for i := 0; i < fullBootstrapChunkFiles; i++ {
+		files = append(files, ChangedFile{Path: fmt.Sprintf("api/file%03d.go", i)})
+	}
+	files = append(files, ChangedFile{Path: "web/app.ts"})
+	req := &DocGenerationRequest{
+		ProjectName:       "demo",
+		CodebasePath:      t.TempDir(),
+		DocumentationPath: t.TempDir(),
+		SourceBranch:      "main",
+		SpecificInstr:     "Keep it short.",
+		FullBootstrap:     true,
+		ChangedFiles:      files,
+	}
+
+	chat := &pausingChatModel{}
+	o := &LLMClient{chatModel: chat}
+	chat.pause = func() { o.PauseStream() }
+	ctx := o.StartStream(context.Background(), "bootstrap-pause-session")
+	if _, err := o.GenerateDocs(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to be paused, got %v", err)
+	}
+	saved, err := o.BootstrapProgressJSON()
+	if err != nil || saved == "" {
+		t.Fatalf("expected the paused bootstrap to report its progress, got %q (%v)", saved, err)
+	}
+	o.StopStream()
+
+	// The resumed run finishes part 1 through a refinement, then goes on
+	resumed := &LLMClient{chatModel: chat}
+	ctx = resumed.StartStream(context.Background(), "bootstrap-pause-session")
+	defer resumed.StopStream()
+	if err := resumed.RestoreBootstrapProgressJSON(saved); err != nil {
+		t.Fatalf("RestoreBootstrapProgressJSON: %v", err)
+	}
+	if !resumed.BootstrapPending() {
+		t.Fatalf("expected part 2 to be pending")
+	}
+	resp, err := resumed.ContinueBootstrap(ctx, &DocGenerationRequest{
+		ProjectName:       "demo",
+		CodebasePath:      req.CodebasePath,
+		DocumentationPath: req.DocumentationPath,
+		SourceBranch:      "main",
+	}, &DocGenerationResponse{Summary: "Finished the API pages."})
+	if err != nil {
+		t.Fatalf("ContinueBootstrap: %v", err)
+	}
+	if len(chat.prompts) != 1 {
+		t.Fatalf("expected only the remaining part to run, got %d runs", len(chat.prompts))
+	}
+	prompt := chat.prompts[0]
+	if !strings.Contains(prompt, "This is part 2 of 2") || !strings.Contains(prompt, "- web/app.ts") || strings.Contains(prompt, "api/file000.go") {
+		t.Fatalf("expected the second part's files, got %q", prompt)
+	}
+	if !strings.Contains(prompt, "Finished the API pages.") || !strings.Contains(prompt, "Keep it short.") {
+		t.Fatalf("expected the finished part's summary and the saved instructions to be carried, got %q", prompt)
+	}
+	if !strings.Contains(resp.Summary, "Part 1 of 2 (api)\nFinished the API pages.") || !strings.Contains(resp.Summary, "Part 2 of 2 (web)") {
+		t.Fatalf("expected the summary to cover every part, got %q", resp.Summary)
+	}
+	if resumed.BootstrapPending() {
+		t.Fatalf("expected no parts to be left")
+	}
+	if left, _ := resumed.BootstrapProgressJSON(); left != "" {
+		t.Fatalf("expected the progress to be cleared, got %q", left)
+	}
+}
+
+func TestContinueBootstrap_KeepsTheStructuredSummaryOfTheLastPart(t *testing.T) {
+	o := &LLMClient{}
+	o.setBootstrapProgress(&BootstrapProgress{
+		Part:      1,
+		Label:     "Part 2 of 2 (web)",
+		Total:     2,
+		Summaries: []string{"## Part 1 of 2 (api)\nDocumented the API."},
+	})
+	structured := &StructuredSummary{Summary: "Documented the web app."}
+
+	resp, err := o.ContinueBootstrap(context.Background(), &DocGenerationRequest{}, &DocGenerationResponse{Summary: "Documented the web app.", Structured: structured})
+	if err != nil {
+		t.Fatalf("ContinueBootstrap: %v", err)
+	}
+	if resp.Structured != structured {
+		t.Fatalf("expected the last part's structured summary, got %+v", resp.Structured)
+	}
+	if !strings.Contains(resp.Summary, "Documented the API.") || !strings.Contains(resp.Summary, "## Part 2 of 2 (web)\nDocumented the web app.") {
+		t.Fatalf("expected the summary to cover every part, got %q", resp.Summary)
+	}
+}
+
+func TestPauseStream_ReleasesRootsButKeepsTodos(t *testing.T) {
+	workspaceID := "pause-releases-roots"
+	tools.SetDocsRootForSession(workspaceID, t.TempDir())
+	tools.GetTodoSession(workspaceID).UpdateTodos([]tools.Todo{{Content: "Document the API", ActiveForm: "Documenting the API", Status: tools.TodoStatusInProgress}})
+	defer tools.ClearSession(workspaceID)
+	defer tools.ClearTodoSession(workspaceID)
+
+	c := &LLMClient{running: true, workspaceID: workspaceID}
+	if !c.PauseStream() {
+		t.Fatalf("expected the active run to pause")
+	}
+
+	if root := tools.DocsRootForSession(workspaceID); root != "" {
+		t.Fatalf("expected the paused run's roots to be released, got %q", root)
+	}
+	if todos := tools.GetTodoSession(workspaceID).GetTodos(); len(todos) != 1 {
+		t.Fatalf("expected the paused run's todo list to be kept, got %v", todos)
+	}
+}
+
+func TestStartStreamRedactsTheAPIKeyFromRunLogs(t *testing.T) {
+	recorder := events.NewRecorder(4)
+	o := &LLMClient{Key: "sk-live-123"}
+	ctx := o.StartStream(events.WithRecorder(context.Background(), recorder), "redact-session")
+	defer o.StopStream()
+
+	events.LoggerFrom(ctx).Errorf("GenerateDocs: message error: 401 for key sk-live-123")
+
+	recorded := recorder.Drain()
+	if len(recorded) != 1 {
+		t.Fatalf("expected one logged event, got %+v", recorded)
+	}
+	if strings.Contains(recorded[0].Message, "sk-live-123") || !strings.Contains(recorded[0].Message, "[REDACTED]") {
+		t.Fatalf("expected the API key to be redacted, got %q", recorded[0].Message)
+	}
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/tools"
+)
+
+// maxExistingDocPages caps how many pages are included as context, however
+// much of the character budget is left.
+const maxExistingDocPages = 8
+
+// maxContextSymbols caps how many symbol names from the diff are searched for.
+const maxContextSymbols = 20
+
+// docPageExtensions are the files considered documentation pages.
+var docPageExtensions = map[string]bool{".md": true, ".mdx": true}
+
+// declaredSymbolPattern matches the names declared on diff lines in the
+// languages the codebase is most likely written in.
+var declaredSymbolPattern = regexp.MustCompile(`\b(?:func|type|class|interface|struct|enum|def|function|const|let|var)\s+(?:\([^)]*\)\s*)?([A-Za-z_][A-Za-z0-9_]{3,})`)
+
+// existingDocPage is a documentation page included as context, with its path
+// relative to the documentation root.
+type existingDocPage struct {
+	Path    string
+	Content string
+}
+
+// SetExistingDocsContext makes GenerateDocs include the current content of the
+// documentation pages most likely related to the changed code, up to maxChars
+// characters in total, so the model edits them in place. 0 disables it.
+func (o *LLMClient) SetExistingDocsContext(maxChars int) {
+	if maxChars < 0 {
+		maxChars = 0
+	}
+	o.existingDocsChars = maxChars
+}
+
+// writeExistingDocsContext appends the "Existing Documentation" section when
+// the client includes existing pages as context and any were found. Full
+// bootstrap runs have no changed code areas to relate pages to.
+func (o *LLMClient) writeExistingDocsContext(ctx context.Context, b *strings.Builder, req *DocGenerationRequest, docRoot string) {
+	if o.existingDocsChars <= 0 || req.FullBootstrap {
+		return
+	}
+	pages := existingDocsContext(ctx, docRoot, req, o.existingDocsChars)
+	if len(pages) == 0 {
+		events.Emit(ctx, events.LLMEventTool, events.NewInfo("ExistingDocs: no related pages found"))
+		return
+	}
+	paths := make([]string, 0, len(pages))
+	b.WriteString("\n\n# Existing Documentation\n")
+	b.WriteString("These pages likely document the changed code. Update them in place where they are affected instead of writing new pages.\n")
+	for _, page := range pages {
+		paths = append(paths, page.Path)
+		b.WriteString(fmt.Sprintf("\n<doc_page path=%q>\n", page.Path))
+		b.WriteString(page.Content)
+		b.WriteString("\n</doc_page>\n")
+	}
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("ExistingDocs: included %d page(s) as context: %s", len(pages), strings.Join(paths, ", "))))
+}
+
+// existingDocsContext finds the pages under docRoot most likely related to
+// the changed code and reads them until maxChars is spent. Pages whose path
+// names a changed file rank first, then pages mentioning the most symbols
+// declared in the diff; a page that does not fit the remaining budget is
+// skipped in favour of smaller ones.
+func existingDocsContext(ctx context.Context, docRoot string, req *DocGenerationRequest, maxChars int) []existingDocPage {
+	ranked := pagesMatchingPaths(docRoot, changedFileStems(req.ChangedFiles))
+	seen := make(map[string]bool, len(ranked))
+	for _, path := range ranked {
+		seen[path] = true
+	}
+	for _, path := range pagesMentioningSymbols(ctx, docRoot, diffSymbols(req.Diff)) {
+		if !seen[path] {
+			seen[path] = true
+			ranked = append(ranked, path)
+		}
+	}
+
+	var pages []existingDocPage
+	remaining := maxChars
+	for _, rel := range ranked {
+		if len(pages) == maxExistingDocPages {
+			break
+		}
+		data, err := os.ReadFile(filepath.Join(docRoot, filepath.FromSlash(rel)))
+		if err != nil || len(data) == 0 || len(data) > remaining {
+			continue
+		}
+		remaining -= len(data)
+		pages = append(pages, existingDocPage{Path: rel, Content: string(data)})
+	}
+	return pages
+}
+
+// changedFileStems returns the lowercased base names, without extension, of
+// the files a diff added, modified or renamed, skipping generic names such
+// as index that would match unrelated pages.
+func changedFileStems(files []ChangedFile) map[string]bool {
+	stems := make(map[string]bool)
+	for _, f := range files {
+		if f.Status == "deleted" {
+			continue
+		}
+		base := filepath.Base(filepath.ToSlash(f.Path))
+		stem := strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
+		switch stem {
+		case "", "index", "main", "mod", "init", "__init__", "readme":
+			continue
+		}
+		if len(stem) >= 3 {
+			stems[stem] = true
+		}
+	}
+	return stems
+}
+
+// pagesMatchingPaths lists, sorted, the documentation pages whose base name
+// or parent directory matches one of stems.
+func pagesMatchingPaths(docRoot string, stems map[string]bool) []string {
+	if len(stems) == 0 {
+		return nil
+	}
+	var matches []string
+	_ = filepath.WalkDir(docRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != docRoot && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(name))
+		if !docPageExtensions[ext] {
+			return nil
+		}
+		stem := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+		parent := strings.ToLower(filepath.Base(filepath.Dir(path)))
+		if stems[stem] || (stem == "index" && stems[parent]) {
+			if rel, relErr := filepath.Rel(docRoot, path); relErr == nil {
+				matches = append(matches, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	sort.Strings(matches)
+	return matches
+}
+
+// diffSymbols returns the names declared on the added and removed lines of
+// diff, in order of first appearance and capped at maxContextSymbols.
+func diffSymbols(diff string) []string {
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(diff, "\n") {
+		if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "-") {
+			continue
+		}
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		for _, m := range declaredSymbolPattern.FindAllStringSubmatch(line[1:], -1) {
+			if name := m[1]; !seen[name] {
+				seen[name] = true
+				symbols = append(symbols, name)
+				if len(symbols) == maxContextSymbols {
+					return symbols
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// pagesMentioningSymbols greps the documentation for symbols with the grep
+// tool and returns the matching pages, relative to docRoot, ordered by how
+// many matching lines each has.
+func pagesMentioningSymbols(ctx context.Context, docRoot string, symbols []string) []string {
+	if len(symbols) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(symbols))
+	for i, s := range symbols {
+		quoted[i] = regexp.QuoteMeta(s)
+	}
+	out, err := tools.Grep(ctx, &tools.GrepInput{
+		Repository: tools.RepositoryDocs,
+		Pattern:    `\b(?:` + strings.Join(quoted, "|") + `)\b`,
+		Include:    "*.{md,mdx}",
+	})
+	if err != nil || out == nil || out.Metadata["error"] != "" {
+		return nil
+	}
+	return rankGrepFiles(docRoot, out.Output)
+}
+
+// rankGrepFiles parses the grep tool's output into the files it matched,
+// relative to docRoot, with the files matching the most lines first.
+func rankGrepFiles(docRoot string, output string) []string {
+	counts := make(map[string]int)
+	var order []string
+	current := ""
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "  Line "):
+			if current != "" {
+				counts[current]++
+			}
+		case strings.HasSuffix(line, ":") && !strings.HasPrefix(line, " "):
+			current = ""
+			rel, err := filepath.Rel(docRoot, strings.TrimSuffix(line, ":"))
+			if err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			current = filepath.ToSlash(rel)
+			if _, ok := counts[current]; !ok {
+				counts[current] = 0
+				order = append(order, current)
+			}
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]] > counts[order[j]]
+	})
+	return order
+}
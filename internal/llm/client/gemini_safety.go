@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"google.golang.org/genai"
+)
+
+// geminiSafetyCategories are the harm categories Gemini filters text on.
+var geminiSafetyCategories = []genai.HarmCategory{
+	genai.HarmCategoryHarassment,
+	genai.HarmCategoryHateSpeech,
+	genai.HarmCategorySexuallyExplicit,
+	genai.HarmCategoryDangerousContent,
+}
+
+// DefaultGeminiSafetySettings blocks only content Gemini rates as highly
+// likely to be harmful in the harassment, hate speech, sexually explicit and
+// dangerous content categories.
+//
+// Gemini's own defaults block from medium probability, which regularly stops
+// legitimate documentation mid-run: security advisories, exploit mitigations,
+// or a page explaining a destructive CLI flag all read as "dangerous content"
+// to the filter. A blocked response ends the run with an error rather than a
+// partial answer, so a false positive costs the whole run. BLOCK_NONE or OFF
+// would avoid that entirely but drop the filter altogether, leaving
+// moderation to the model alone; BLOCK_ONLY_HIGH keeps the filter for
+// clearly harmful output while tolerating technical writing.
+func DefaultGeminiSafetySettings() []*genai.SafetySetting {
+	return GeminiSafetySettingsAt(genai.HarmBlockThresholdBlockOnlyHigh)
+}
+
+// GeminiSafetySettingsAt applies threshold to every harm category
+// DefaultGeminiSafetySettings covers.
+func GeminiSafetySettingsAt(threshold genai.HarmBlockThreshold) []*genai.SafetySetting {
+	settings := make([]*genai.SafetySetting, 0, len(geminiSafetyCategories))
+	for _, category := range geminiSafetyCategories {
+		settings = append(settings, &genai.SafetySetting{
+			Category:  category,
+			Threshold: threshold,
+		})
+	}
+	return settings
+}
+
+// GeminiSafetySettingsFor returns the settings for a threshold name stored in
+// the app settings, such as "BLOCK_NONE", applied to every category. An empty
+// name returns nil, which NewGeminiClient reads as DefaultGeminiSafetySettings.
+func GeminiSafetySettingsFor(threshold string) ([]*genai.SafetySetting, error) {
+	threshold = strings.TrimSpace(threshold)
+	switch genai.HarmBlockThreshold(threshold) {
+	case "":
+		return nil, nil
+	case genai.HarmBlockThresholdBlockLowAndAbove,
+		genai.HarmBlockThresholdBlockMediumAndAbove,
+		genai.HarmBlockThresholdBlockOnlyHigh,
+		genai.HarmBlockThresholdBlockNone,
+		genai.HarmBlockThresholdOff:
+		return GeminiSafetySettingsAt(genai.HarmBlockThreshold(threshold)), nil
+	}
+	return nil, fmt.Errorf("unknown Gemini safety threshold %q", threshold)
+}
+
+// geminiSystemInstructionModel adds a fixed instruction to the system message
+// of every request. The Gemini model sends the leading system message as the
+// request's system instruction, so the instruction reaches the genai config
+// without being part of any prompt a run builds.
+type geminiSystemInstructionModel struct {
+	inner       model.ToolCallingChatModel
+	instruction string
+}
+
+// withGeminiSystemInstruction wraps inner so every request carries
+// instruction; an empty instruction returns inner unchanged.
+func withGeminiSystemInstruction(inner model.ToolCallingChatModel, instruction string) model.ToolCallingChatModel {
+	instruction = strings.TrimSpace(instruction)
+	if instruction == "" {
+		return inner
+	}
+	return &geminiSystemInstructionModel{inner: inner, instruction: instruction}
+}
+
+func (m *geminiSystemInstructionModel) input(input []*schema.Message) []*schema.Message {
+	if len(input) > 0 && input[0] != nil && input[0].Role == schema.System {
+		system := *input[0]
+		system.Content = strings.TrimRight(system.Content, "\n") + "\n\n" + m.instruction
+		return append([]*schema.Message{&system}, input[1:]...)
+	}
+	return append([]*schema.Message{schema.SystemMessage(m.instruction)}, input...)
+}
+
+func (m *geminiSystemInstructionModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return m.inner.Generate(ctx, m.input(input), opts...)
+}
+
+func (m *geminiSystemInstructionModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return m.inner.Stream(ctx, m.input(input), opts...)
+}
+
+func (m *geminiSystemInstructionModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	inner, err := m.inner.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &geminiSystemInstructionModel{inner: inner, instruction: m.instruction}, nil
+}
+
+// IsCallbacksEnabled defers to the wrapped model so callbacks are not run twice.
+func (m *geminiSystemInstructionModel) IsCallbacksEnabled() bool {
+	if checker, ok := m.inner.(components.Checker); ok {
+		return checker.IsCallbacksEnabled()
+	}
+	return false
+}
+
+// GetType reports the wrapped model's type for callback handlers.
+func (m *geminiSystemInstructionModel) GetType() string {
+	if typer, ok := m.inner.(components.Typer); ok {
+		return typer.GetType()
+	}
+	return "GeminiSystemInstructionModel"
+}
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/tools"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// HistoryCompaction configures when a refinement conversation is summarized
+// before it is resent to the model.
+type HistoryCompaction struct {
+	// MaxMessages and MaxChars trigger compaction once the stored history
+	// exceeds either of them; 0 disables that limit.
+	MaxMessages int
+	MaxChars    int
+	// KeepTurns is how many of the most recent user turns, with the replies
+	// and tool calls that followed them, are kept verbatim.
+	KeepTurns int
+	// Summarizer writes the summary of the compacted turns; nil uses the
+	// session's own model.
+	Summarizer *LLMClient
+}
+
+const (
+	// compactionMessageChars caps how much of one message goes into the
+	// transcript that is summarized.
+	compactionMessageChars = 2000
+	// compactionToolChars caps tool call arguments and tool results.
+	compactionToolChars = 300
+)
+
+// SetHistoryCompaction sets when DocRefine compacts the conversation history.
+func (o *LLMClient) SetHistoryCompaction(cfg HistoryCompaction) {
+	o.conversationHistoryMu.Lock()
+	defer o.conversationHistoryMu.Unlock()
+	o.compaction = cfg
+}
+
+// compactConversationHistory replaces the turns before the most recent
+// KeepTurns with a summary written by the model, folded into the first kept
+// user message so the history still starts with a user message. The stored
+// history is replaced, so the compacted form is what gets persisted. Failures
+// are reported and leave the history as it was.
+func (o *LLMClient) compactConversationHistory(ctx context.Context) {
+	o.conversationHistoryMu.Lock()
+	cfg := o.compaction
+	history := append([]adk.Message(nil), o.conversationHistory...)
+	o.conversationHistoryMu.Unlock()
+
+	older, recent, ok := splitHistoryForCompaction(history, cfg)
+	if !ok {
+		return
+	}
+
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("DocRefine: compacting %d earlier messages", len(older))))
+	summary, err := o.summarizeHistory(ctx, older, cfg.Summarizer)
+	if err != nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("DocRefine: history compaction failed, sending the full history: %v", err)))
+		return
+	}
+	compacted := compactedHistory(summary, recent)
+
+	o.conversationHistoryMu.Lock()
+	defer o.conversationHistoryMu.Unlock()
+	if len(o.conversationHistory) != len(history) {
+		// The history changed while the summary was written
+		return
+	}
+	o.conversationHistory = compacted
+	o.agenticHistory = agenticHistoryFromMessages(compacted)
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("DocRefine: compacted history from %d to %d messages", len(history), len(compacted))))
+}
+
+// HistoryLimit caps the conversation history restored from storage; 0
+// disables a limit.
+type HistoryLimit struct {
+	MaxMessages int
+	MaxChars    int
+}
+
+// exceeded reports whether history is over either limit.
+func (l HistoryLimit) exceeded(history []adk.Message) bool {
+	if l.MaxMessages > 0 && len(history) > l.MaxMessages {
+		return true
+	}
+	return l.MaxChars > 0 && historyChars(history) > l.MaxChars
+}
+
+// trimHistoryToLimit keeps the first message and the most recent whole turns
+// that fit within limit, dropping the turns in between, and returns how many
+// messages were dropped. Turns start at a user message, so tool calls stay
+// with their results. The latest turn is always kept, even when it alone is
+// over the limit.
+func trimHistoryToLimit(history []adk.Message, limit HistoryLimit) ([]adk.Message, int) {
+	if len(history) == 0 || !limit.exceeded(history) {
+		return history, 0
+	}
+	cut := -1
+	for i := len(history) - 1; i > 0; i-- {
+		if history[i] == nil || history[i].Role != schema.User {
+			continue
+		}
+		kept := append([]adk.Message{history[0]}, history[i:]...)
+		if cut != -1 && limit.exceeded(kept) {
+			break
+		}
+		cut = i
+	}
+	if cut <= 1 {
+		return history, 0
+	}
+	trimmed := make([]adk.Message, 0, 1+len(history)-cut)
+	trimmed = append(trimmed, history[0])
+	trimmed = append(trimmed, history[cut:]...)
+	return trimmed, cut - 1
+}
+
+func historyChars(history []adk.Message) int {
+	chars := 0
+	for _, msg := range history {
+		if msg != nil {
+			chars += len(msg.Content)
+		}
+	}
+	return chars
+}
+
+// splitHistoryForCompaction splits history at the start of the KeepTurns-th
+// most recent user turn when it exceeds the configured limits. It reports
+// false when no limit is exceeded or there are no older turns to summarize.
+func splitHistoryForCompaction(history []adk.Message, cfg HistoryCompaction) ([]adk.Message, []adk.Message, bool) {
+	if !(HistoryLimit{MaxMessages: cfg.MaxMessages, MaxChars: cfg.MaxChars}).exceeded(history) {
+		return nil, nil, false
+	}
+
+	var turnStarts []int
+	for i, msg := range history {
+		if msg != nil && msg.Role == schema.User {
+			turnStarts = append(turnStarts, i)
+		}
+	}
+	keep := max(cfg.KeepTurns, 1)
+	if len(turnStarts) <= keep {
+		return nil, nil, false
+	}
+	cut := turnStarts[len(turnStarts)-keep]
+	return history[:cut], history[cut:], true
+}
+
+// compactedHistory prepends summary to the first message of recent, which is
+// always a user message, leaving the original message untouched.
+func compactedHistory(summary string, recent []adk.Message) []adk.Message {
+	first := *recent[0]
+	first.Content = fmt.Sprintf("<earlier_conversation_summary>\n%s\n</earlier_conversation_summary>\n\n%s", strings.TrimSpace(summary), first.Content)
+	compacted := make([]adk.Message, 0, len(recent))
+	compacted = append(compacted, &first)
+	return append(compacted, recent[1:]...)
+}
+
+// summarizeHistory asks the summarization model, without tools, to summarize
+// older turns.
+func (o *LLMClient) summarizeHistory(ctx context.Context, older []adk.Message, summarizer *LLMClient) (string, error) {
+	instructions, err := o.loadPrompt("compact_history.txt")
+	if err != nil {
+		return "", err
+	}
+	if summarizer == nil {
+		summarizer = o
+	}
+	summary, err := summarizer.generateText(ctx, instructions, compactionTranscript(older))
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(summary) == "" {
+		return "", fmt.Errorf("model returned an empty summary")
+	}
+	return summary, nil
+}
+
+// generateText sends instructions and input to the model without tools and
+// returns its text reply, recording the tokens used.
+func (o *LLMClient) generateText(ctx context.Context, instructions, input string, opts ...model.Option) (string, error) {
+	if o.usesAgenticModel() {
+		resp, err := o.agenticModel.Generate(ctx, []*schema.AgenticMessage{
+			schema.SystemAgenticMessage(instructions),
+			schema.UserAgenticMessage(input),
+		}, opts...)
+		if err != nil {
+			return "", err
+		}
+		if resp.ResponseMeta != nil && resp.ResponseMeta.TokenUsage != nil {
+			usage := resp.ResponseMeta.TokenUsage
+			tools.RecordTokenUsage(ctx, usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+		}
+		return agenticTextContent(resp), nil
+	}
+	if o.chatModel == nil {
+		return "", fmt.Errorf("llm client has no model configured")
+	}
+	resp, err := o.chatModel.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(instructions),
+		schema.UserMessage(input),
+	}, opts...)
+	if err != nil {
+		return "", err
+	}
+	if resp.ResponseMeta != nil && resp.ResponseMeta.Usage != nil {
+		usage := resp.ResponseMeta.Usage
+		tools.RecordTokenUsage(ctx, usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	}
+	return resp.Content, nil
+}
+
+// compactionTranscript renders messages as plain text for summarization,
+// truncating long messages, tool arguments and tool results.
+func compactionTranscript(messages []adk.Message) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		switch msg.Role {
+		case schema.Tool:
+			fmt.Fprintf(&b, "[tool result %s]\n%s\n\n", msg.ToolName, truncateRunes(msg.Content, compactionToolChars))
+			continue
+		case schema.User:
+			b.WriteString("[user]\n")
+		case schema.Assistant:
+			b.WriteString("[assistant]\n")
+		default:
+			fmt.Fprintf(&b, "[%s]\n", msg.Role)
+		}
+		if content := strings.TrimSpace(msg.Content); content != "" {
+			b.WriteString(truncateRunes(content, compactionMessageChars))
+			b.WriteByte('\n')
+		}
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(&b, "-> %s(%s)\n", call.Function.Name, truncateRunes(call.Function.Arguments, compactionToolChars))
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Variables that generation-specific instructions (typically a template from
+// the Templates service) may reference as {{name}}.
+const (
+	InstructionVarProject      = "project"
+	InstructionVarSourceBranch = "source_branch"
+	InstructionVarChangedFiles = "changed_files"
+	InstructionVarDate         = "date"
+)
+
+var instructionVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// instructionVars returns the values available to instruction templates for a
+// prompt. Variables without a value for this run are omitted.
+func instructionVars(cfg promptBuilderConfig) map[string]string {
+	vars := map[string]string{
+		InstructionVarDate: time.Now().Format("2006-01-02"),
+	}
+	if project := strings.TrimSpace(cfg.ProjectName); project != "" {
+		vars[InstructionVarProject] = project
+	}
+	if branch := strings.TrimSpace(cfg.SourceBranch); branch != "" {
+		vars[InstructionVarSourceBranch] = branch
+	}
+	if len(cfg.ChangedFiles) > 0 {
+		files := make([]string, len(cfg.ChangedFiles))
+		for i, f := range cfg.ChangedFiles {
+			files[i] = filepath.ToSlash(f.Path)
+		}
+		vars[InstructionVarChangedFiles] = strings.Join(files, ", ")
+	}
+	return vars
+}
+
+// expandInstructionVars replaces {{name}} placeholders with their values.
+// Unknown variables, and known ones without a value for this run, are left
+// as written so a template never silently loses text.
+func expandInstructionVars(text string, vars map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	return instructionVarPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := instructionVarPattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
@@ -0,0 +1,28 @@
+package client
+
+import "strings"
+
+// llmInstructionsTemplateFile is the embedded starting point for a
+// repository's .narrabyte/llm_instructions.md.
+const llmInstructionsTemplateFile = "prompts/llm_instructions_template.txt"
+
+// LLMInstructionsTemplate returns the placeholder instructions written by
+// ClientService.InitNarrabyteConfig. Runs ignore a file that still matches it.
+func LLMInstructionsTemplate() string {
+	data, err := embeddedPrompts.ReadFile(llmInstructionsTemplateFile)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// isLLMInstructionsTemplate reports whether content is the unedited template,
+// ignoring surrounding whitespace and line endings.
+func isLLMInstructionsTemplate(content string) bool {
+	template := normalizeInstructions(LLMInstructionsTemplate())
+	return template != "" && normalizeInstructions(content) == template
+}
+
+func normalizeInstructions(content string) string {
+	return strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "\n"))
+}
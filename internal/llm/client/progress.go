@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/tools"
+)
+
+const (
+	// typicalRunToolCalls is the number of tool calls at which the estimate
+	// for runs without a todo list reaches half of maxEstimatedProgress.
+	typicalRunToolCalls = 30
+	// maxEstimatedProgress caps the estimate for runs without a todo list, since
+	// the number of calls says nothing about how close the run is to done.
+	maxEstimatedProgress = 90
+)
+
+// todoProgress returns how many todos are completed out of those still
+// planned. Cancelled todos count toward neither.
+func todoProgress(todos []tools.Todo) (completed, total int) {
+	for _, todo := range todos {
+		switch todo.Status {
+		case tools.TodoStatusCancelled:
+			continue
+		case tools.TodoStatusCompleted:
+			completed++
+		}
+		total++
+	}
+	return completed, total
+}
+
+// estimatedProgress guesses a run's progress in percent from its tool call
+// count. It approaches maxEstimatedProgress without reaching it.
+func estimatedProgress(calls int) int {
+	if calls <= 0 {
+		return 0
+	}
+	return maxEstimatedProgress * calls / (calls + typicalRunToolCalls)
+}
+
+// emitTodoProgress reports the run's progress as the share of completed todos.
+func emitTodoProgress(ctx context.Context, todos []tools.Todo) {
+	completed, total := todoProgress(todos)
+	if total == 0 {
+		return
+	}
+	events.EmitProgress(ctx, completed*100/total, completed, total, false)
+}
+
+// recordToolCall counts a tool call for the run and, until the agent starts a
+// todo list, reports progress estimated from the number of calls so far.
+func recordToolCall(ctx context.Context, toolName string) {
+	tools.RecordToolCall(ctx, toolName)
+	stats := tools.RunStatsForSession(ctx)
+	if stats.ToolCalls["todo_write"] > 0 {
+		return
+	}
+	calls := 0
+	for _, count := range stats.ToolCalls {
+		calls += count
+	}
+	events.EmitProgress(ctx, estimatedProgress(calls), calls, 0, true)
+}
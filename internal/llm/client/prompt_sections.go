@@ -0,0 +1,54 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// sourceCommitContextKey is the "Project Context" entry naming the source
+// commit; it is part of the commit context PromptSections can leave out.
+const sourceCommitContextKey = "Source commit"
+
+// PromptSections selects the optional sections of the prompts a client
+// builds. The zero value includes everything without a size limit.
+type PromptSections struct {
+	// OmitCodeListing and OmitDocsListing leave the codebase and documentation
+	// directory listings out of "Repository Structure"
+	OmitCodeListing bool
+	OmitDocsListing bool
+	// OmitCommitContext leaves out the source commit and the "Commit History"
+	OmitCommitContext bool
+	// MaxListingChars truncates each directory listing to about this many
+	// characters, with a note saying so; 0 means no limit
+	MaxListingChars int
+}
+
+// SetPromptSections sets the optional prompt sections of every later run.
+func (o *LLMClient) SetPromptSections(sections PromptSections) {
+	if sections.MaxListingChars < 0 {
+		sections.MaxListingChars = 0
+	}
+	o.promptSections = sections
+}
+
+// truncateListing cuts listing to at most maxChars characters at a line
+// boundary and notes how much was left out. 0 leaves it whole.
+func truncateListing(listing string, maxChars int) string {
+	if maxChars <= 0 || len(listing) <= maxChars {
+		return listing
+	}
+	cut := strings.LastIndexByte(listing[:maxChars], '\n')
+	if cut <= 0 {
+		cut = maxChars
+		for cut > 0 && !utf8.RuneStart(listing[cut]) {
+			cut--
+		}
+	}
+	rest := strings.TrimPrefix(listing[cut:], "\n")
+	omitted := strings.Count(rest, "\n")
+	if rest != "" && !strings.HasSuffix(rest, "\n") {
+		omitted++
+	}
+	return fmt.Sprintf("%s\n(listing truncated at %d characters; %d more line(s) are not shown, use the list and glob tools to explore them)", strings.TrimRight(listing[:cut], "\n"), maxChars, omitted)
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	goopenai "github.com/meguminnnnnnnnn/go-openai"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+// ProviderErrorKind says what went wrong when talking to a model provider, so
+// the UI can suggest a fix instead of showing the raw response.
+type ProviderErrorKind string
+
+const (
+	ProviderErrorInvalidKey    ProviderErrorKind = "invalid_key"
+	ProviderErrorQuota         ProviderErrorKind = "insufficient_quota"
+	ProviderErrorModelNotFound ProviderErrorKind = "model_not_found"
+	ProviderErrorRateLimited   ProviderErrorKind = "rate_limited"
+	ProviderErrorNetwork       ProviderErrorKind = "network"
+)
+
+// ProviderError is a provider failure that has been classified. It formats as
+// ERR_PROVIDER:<kind>:<provider>:<reason> so the frontend can parse it.
+type ProviderError struct {
+	Kind      ProviderErrorKind
+	Provider  string
+	Retryable bool
+	Err       error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("ERR_PROVIDER:%s:%s:%v", e.Kind, e.Provider, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// providerClassifiers inspect the SDK error types of each provider. Providers
+// without an entry, such as OpenAI-compatible gateways, use the OpenAI one.
+var providerClassifiers = map[string]func(err error) (ProviderErrorKind, bool){
+	"openai":    classifyOpenAIError,
+	"anthropic": classifyAnthropicError,
+	"gemini":    classifyGeminiError,
+}
+
+// ClassifyProviderError wraps err in a ProviderError when it is recognised as
+// one of the known failure kinds for providerID; label names the provider in
+// the resulting message. Unrecognised errors are returned unchanged.
+func ClassifyProviderError(providerID, label string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *ProviderError
+	if errors.As(err, &classified) {
+		return err
+	}
+	classify, ok := providerClassifiers[strings.ToLower(strings.TrimSpace(providerID))]
+	if !ok {
+		classify = classifyOpenAIError
+	}
+	kind, ok := classify(err)
+	if !ok {
+		kind, ok = classifyNetworkError(err)
+	}
+	if !ok {
+		return err
+	}
+	if strings.TrimSpace(label) == "" {
+		label = providerID
+	}
+	return &ProviderError{
+		Kind:      kind,
+		Provider:  label,
+		Retryable: kind == ProviderErrorRateLimited || kind == ProviderErrorNetwork,
+		Err:       err,
+	}
+}
+
+func classifyOpenAIError(err error) (ProviderErrorKind, bool) {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return classifyChatCompletionsError(err)
+	}
+	return classifyOpenAICode(apiErr.Code, apiErr.StatusCode)
+}
+
+// classifyChatCompletionsError handles the error types of the client behind
+// OpenAI-compatible gateways, which call Chat Completions.
+func classifyChatCompletionsError(err error) (ProviderErrorKind, bool) {
+	var apiErr *goopenai.APIError
+	if errors.As(err, &apiErr) {
+		code, _ := apiErr.Code.(string)
+		return classifyOpenAICode(code, apiErr.HTTPStatusCode)
+	}
+	var reqErr *goopenai.RequestError
+	if errors.As(err, &reqErr) {
+		return classifyStatus(reqErr.HTTPStatusCode)
+	}
+	return "", false
+}
+
+func classifyOpenAICode(code string, status int) (ProviderErrorKind, bool) {
+	switch code {
+	case "invalid_api_key":
+		return ProviderErrorInvalidKey, true
+	case "insufficient_quota":
+		return ProviderErrorQuota, true
+	case "model_not_found":
+		return ProviderErrorModelNotFound, true
+	}
+	return classifyStatus(status)
+}
+
+func classifyAnthropicError(err error) (ProviderErrorKind, bool) {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return "", false
+	}
+	switch apiErr.Type() {
+	case anthropic.ErrorTypeAuthenticationError, anthropic.ErrorTypePermissionError:
+		return ProviderErrorInvalidKey, true
+	case anthropic.ErrorTypeBillingError:
+		return ProviderErrorQuota, true
+	case anthropic.ErrorTypeNotFoundError:
+		return ProviderErrorModelNotFound, true
+	case anthropic.ErrorTypeRateLimitError, anthropic.ErrorTypeOverloadedError:
+		return ProviderErrorRateLimited, true
+	}
+	// Anthropic reports an exhausted credit balance as an invalid request
+	if apiErr.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.RawJSON()), "credit balance") {
+		return ProviderErrorQuota, true
+	}
+	// 529 is Anthropic's status for an overloaded API
+	if apiErr.StatusCode == 529 {
+		return ProviderErrorRateLimited, true
+	}
+	return classifyStatus(apiErr.StatusCode)
+}
+
+func classifyGeminiError(err error) (ProviderErrorKind, bool) {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		var apiErrPtr *genai.APIError
+		if !errors.As(err, &apiErrPtr) || apiErrPtr == nil {
+			return "", false
+		}
+		apiErr = *apiErrPtr
+	}
+	// Gemini rejects a bad key with a 400 rather than a 401
+	if apiErr.Code == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.Message), "api key not valid") {
+		return ProviderErrorInvalidKey, true
+	}
+	if apiErr.Code == http.StatusTooManyRequests && strings.Contains(strings.ToLower(apiErr.Message), "billing") {
+		return ProviderErrorQuota, true
+	}
+	return classifyStatus(apiErr.Code)
+}
+
+// classifyStatus maps the HTTP statuses providers agree on.
+func classifyStatus(status int) (ProviderErrorKind, bool) {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ProviderErrorInvalidKey, true
+	case http.StatusPaymentRequired:
+		return ProviderErrorQuota, true
+	case http.StatusNotFound:
+		return ProviderErrorModelNotFound, true
+	case http.StatusTooManyRequests:
+		return ProviderErrorRateLimited, true
+	default:
+		return "", false
+	}
+}
+
+func classifyNetworkError(err error) (ProviderErrorKind, bool) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ProviderErrorNetwork, true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ProviderErrorNetwork, true
+	}
+	return "", false
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+func openAIError(status int, code string) error {
+	return &openai.Error{
+		Code:       code,
+		StatusCode: status,
+		Request:    httptest.NewRequest(http.MethodPost, "https://api.openai.com/v1/responses", nil),
+		Response:   &http.Response{StatusCode: status},
+	}
+}
+
+func anthropicError(t *testing.T, status int, body string) error {
+	t.Helper()
+	apiErr := &anthropic.Error{}
+	if err := apiErr.UnmarshalJSON([]byte(body)); err != nil {
+		t.Fatalf("unmarshal anthropic error: %v", err)
+	}
+	apiErr.StatusCode = status
+	apiErr.Request = httptest.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", nil)
+	apiErr.Response = &http.Response{StatusCode: status}
+	return apiErr
+}
+
+func TestClassifyProviderError(t *testing.T) {
+	cases := []struct {
+		name      string
+		provider  string
+		err       error
+		kind      ProviderErrorKind
+		retryable bool
+	}{
+		{"openai invalid key", "openai", openAIError(401, "invalid_api_key"), ProviderErrorInvalidKey, false},
+		{"openai quota", "openai", openAIError(429, "insufficient_quota"), ProviderErrorQuota, false},
+		{"openai rate limit", "openai", openAIError(429, "rate_limit_exceeded"), ProviderErrorRateLimited, true},
+		{"openai missing model", "openai", openAIError(404, "model_not_found"), ProviderErrorModelNotFound, false},
+		{"compatible gateway uses openai shapes", "litellm", openAIError(401, ""), ProviderErrorInvalidKey, false},
+		{"anthropic invalid key", "anthropic", anthropicError(t, 401, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`), ProviderErrorInvalidKey, false},
+		{"anthropic credit balance", "anthropic", anthropicError(t, 400, `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low to access the Anthropic API."}}`), ProviderErrorQuota, false},
+		{"anthropic missing model", "anthropic", anthropicError(t, 404, `{"type":"error","error":{"type":"not_found_error","message":"model: claude-unknown"}}`), ProviderErrorModelNotFound, false},
+		{"anthropic overloaded", "anthropic", anthropicError(t, 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`), ProviderErrorRateLimited, true},
+		{"gemini invalid key", "gemini", genai.APIError{Code: 400, Status: "INVALID_ARGUMENT", Message: "API key not valid. Please pass a valid API key."}, ProviderErrorInvalidKey, false},
+		{"gemini missing model", "gemini", genai.APIError{Code: 404, Status: "NOT_FOUND", Message: "models/gemini-unknown is not found"}, ProviderErrorModelNotFound, false},
+		{"gemini rate limit", "gemini", genai.APIError{Code: 429, Status: "RESOURCE_EXHAUSTED", Message: "Resource has been exhausted"}, ProviderErrorRateLimited, true},
+		{"network", "anthropic", &url.Error{Op: "Post", URL: "https://api.anthropic.com", Err: &net.DNSError{Err: "no such host", Name: "api.anthropic.com"}}, ProviderErrorNetwork, true},
+		{"timeout", "openai", context.DeadlineExceeded, ProviderErrorNetwork, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("failed to create response: %w", tc.err)
+			err := ClassifyProviderError(tc.provider, "Label", wrapped)
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("expected a ProviderError, got %v", err)
+			}
+			if providerErr.Kind != tc.kind {
+				t.Fatalf("kind = %q, want %q", providerErr.Kind, tc.kind)
+			}
+			if providerErr.Retryable != tc.retryable {
+				t.Fatalf("retryable = %v, want %v", providerErr.Retryable, tc.retryable)
+			}
+			if providerErr.Provider != "Label" {
+				t.Fatalf("provider = %q, want Label", providerErr.Provider)
+			}
+			if !errors.Is(err, wrapped) {
+				t.Fatalf("expected the original error to stay reachable")
+			}
+			if !strings.HasPrefix(err.Error(), "ERR_PROVIDER:"+string(tc.kind)+":Label:") {
+				t.Fatalf("unexpected message: %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestClassifyProviderError_LeavesUnknownErrors(t *testing.T) {
+	plain := errors.New("convert response fail")
+	if err := ClassifyProviderError("openai", "OpenAI", plain); err != plain {
+		t.Fatalf("expected unrecognised error unchanged, got %v", err)
+	}
+	serverErr := openAIError(500, "")
+	if err := ClassifyProviderError("openai", "OpenAI", serverErr); err != serverErr {
+		t.Fatalf("expected server error unchanged, got %v", err)
+	}
+	if err := ClassifyProviderError("openai", "OpenAI", context.Canceled); err != context.Canceled {
+		t.Fatalf("expected cancellation unchanged, got %v", err)
+	}
+	if ClassifyProviderError("openai", "OpenAI", nil) != nil {
+		t.Fatalf("expected nil for nil error")
+	}
+}
+
+func TestPing_ClaudeCapsOutputWithThinkingOff(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","model":"claude-test","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":1}}`)
+	}))
+	defer server.Close()
+	t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+
+	c, err := NewClaudeClient(context.Background(), "test-key", ClaudeModelOptions{Model: "claude-test", ReasoningEffort: "high"})
+	if err != nil {
+		t.Fatalf("NewClaudeClient: %v", err)
+	}
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	if got, _ := body["max_tokens"].(float64); got != pingMaxTokens {
+		t.Fatalf("expected max_tokens %d, got %v", pingMaxTokens, body["max_tokens"])
+	}
+	if thinking, ok := body["thinking"].(map[string]any); ok && thinking["type"] != "disabled" {
+		t.Fatalf("expected thinking off for ping, got %v", thinking)
+	}
+}
+
+func TestPing_GeminiLooksUpModelAndClassifiesInvalidKey(t *testing.T) {
+	var gotMethod, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`)
+	}))
+	defer server.Close()
+	t.Setenv("GOOGLE_GEMINI_BASE_URL", server.URL)
+
+	c, err := NewGeminiClient(context.Background(), "bad-key", GeminiModelOptions{Model: "gemini-test"})
+	if err != nil {
+		t.Fatalf("NewGeminiClient: %v", err)
+	}
+	err = ClassifyProviderError("gemini", "Gemini", c.Ping(context.Background()))
+
+	if gotMethod != http.MethodGet || !strings.HasSuffix(gotPath, "/models/gemini-test") {
+		t.Fatalf("expected a model lookup, got %s %s", gotMethod, gotPath)
+	}
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Kind != ProviderErrorInvalidKey {
+		t.Fatalf("expected invalid key error, got %v", err)
+	}
+}
+
+func TestPing_OpenAICompatibleCapsOutputAndClassifiesInvalidKey(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"Incorrect API key provided.","type":"invalid_request_error","code":"invalid_api_key"}}`)
+	}))
+	defer server.Close()
+
+	c, err := NewOpenAICompatibleClient(context.Background(), "bad-key", OpenAICompatibleModelOptions{Model: "gateway-model", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAICompatibleClient: %v", err)
+	}
+	err = ClassifyProviderError("litellm", "LiteLLM", c.Ping(context.Background()))
+
+	if body["max_tokens"] == nil && body["max_completion_tokens"] == nil {
+		t.Fatalf("expected ping to cap output tokens, got %v", body)
+	}
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Kind != ProviderErrorInvalidKey {
+		t.Fatalf("expected invalid key error, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/tools"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Actions taken once a run's reasoning tokens exceed the model's budget.
+const (
+	ReasoningBudgetWarn    = "warn"
+	ReasoningBudgetDisable = "disable"
+	ReasoningBudgetAbort   = "abort"
+)
+
+// IsReasoningBudgetAction reports whether action is one of the budget actions.
+func IsReasoningBudgetAction(action string) bool {
+	switch action {
+	case ReasoningBudgetWarn, ReasoningBudgetDisable, ReasoningBudgetAbort:
+		return true
+	default:
+		return false
+	}
+}
+
+// reasoningBudget limits the reasoning tokens a single run may spend.
+type reasoningBudget struct {
+	tokens int
+	action string
+	// disableOptions switch thinking off for one model call; empty when the
+	// provider cannot change thinking between calls
+	disableOptions []model.Option
+	// thinkingOff is set once a "disable" budget is exceeded and cleared at
+	// the start of each run
+	thinkingOff atomic.Bool
+}
+
+// SetReasoningBudget limits the reasoning tokens of each run to tokens, taking
+// action once the limit is crossed. A budget of 0 disables the check.
+func (o *LLMClient) SetReasoningBudget(tokens int, action string) {
+	action = strings.ToLower(strings.TrimSpace(action))
+	if !IsReasoningBudgetAction(action) {
+		action = ReasoningBudgetWarn
+	}
+	o.budget.tokens = max(tokens, 0)
+	o.budget.action = action
+	o.budget.thinkingOff.Store(false)
+}
+
+// resetReasoningBudget re-enables thinking at the start of a run.
+func (o *LLMClient) resetReasoningBudget() {
+	o.budget.thinkingOff.Store(false)
+}
+
+// recordTokenUsage adds a model response's token usage to the run statistics
+// and enforces the reasoning budget. A non-nil error means the run must stop.
+// Providers that do not report reasoning tokens, such as Claude, are charged
+// an estimate based on the length of reasoningText.
+func (o *LLMClient) recordTokenUsage(ctx context.Context, usage *schema.TokenUsage, reasoningText string) error {
+	if usage == nil {
+		return nil
+	}
+	tools.RecordTokenUsage(ctx, usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	reasoning := usage.CompletionTokensDetails.ReasoningTokens
+	if reasoning == 0 {
+		reasoning = estimateTokens(reasoningText)
+	}
+	if reasoning <= 0 {
+		return nil
+	}
+	total := tools.RecordReasoningTokens(ctx, reasoning)
+	limit := o.budget.tokens
+	if limit <= 0 || total <= limit || total-reasoning > limit {
+		// Only act when this response crosses the limit
+		return nil
+	}
+
+	switch o.budget.action {
+	case ReasoningBudgetAbort:
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Reasoning budget exceeded: %d of %d tokens used, stopping the run", total, limit)))
+		return fmt.Errorf("ERR_REASONING_BUDGET:reasoning used %d tokens, over the budget of %d", total, limit)
+	case ReasoningBudgetDisable:
+		if len(o.budget.disableOptions) > 0 {
+			o.budget.thinkingOff.Store(true)
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("Reasoning budget exceeded: %d of %d tokens used, thinking is off for the rest of the run", total, limit)))
+			return nil
+		}
+		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("Reasoning budget exceeded: %d of %d tokens used; this provider cannot turn thinking off during a run", total, limit)))
+	default:
+		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("Reasoning budget exceeded: %d of %d tokens used", total, limit)))
+	}
+	return nil
+}
+
+// estimateTokens approximates the token count of text at four characters per token.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// budgetedChatModel appends the budget's disable options to every call made
+// after a "disable" budget has been exceeded.
+type budgetedChatModel struct {
+	inner  model.ToolCallingChatModel
+	budget *reasoningBudget
+}
+
+func newBudgetedChatModel(inner model.ToolCallingChatModel, budget *reasoningBudget) model.ToolCallingChatModel {
+	return &budgetedChatModel{inner: inner, budget: budget}
+}
+
+func (m *budgetedChatModel) options(opts []model.Option) []model.Option {
+	if len(m.budget.disableOptions) == 0 || !m.budget.thinkingOff.Load() {
+		return opts
+	}
+	return append(append([]model.Option(nil), opts...), m.budget.disableOptions...)
+}
+
+func (m *budgetedChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return m.inner.Generate(ctx, input, m.options(opts)...)
+}
+
+func (m *budgetedChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return m.inner.Stream(ctx, input, m.options(opts)...)
+}
+
+func (m *budgetedChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	inner, err := m.inner.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return newBudgetedChatModel(inner, m.budget), nil
+}
+
+// IsCallbacksEnabled defers to the wrapped model so callbacks are not run twice.
+func (m *budgetedChatModel) IsCallbacksEnabled() bool {
+	if checker, ok := m.inner.(components.Checker); ok {
+		return checker.IsCallbacksEnabled()
+	}
+	return false
+}
+
+// GetType reports the wrapped model's type for callback handlers.
+func (m *budgetedChatModel) GetType() string {
+	if typer, ok := m.inner.(components.Typer); ok {
+		return typer.GetType()
+	}
+	return "BudgetedChatModel"
+}
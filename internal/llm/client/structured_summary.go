@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"narrabyte/internal/events"
+
+	"github.com/cloudwego/eino-ext/components/model/agenticopenai"
+	"github.com/cloudwego/eino/adk"
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// StructuredSummary is the closing summary of a run as returned by a model
+// using structured outputs, with the rationale for each changed file and
+// suggested follow-up work next to the free-text summary.
+type StructuredSummary struct {
+	Summary   string          `json:"summary"`
+	Files     []FileRationale `json:"files"`
+	FollowUps []string        `json:"follow_ups"`
+}
+
+// FileRationale explains why a documentation file was changed.
+type FileRationale struct {
+	Path      string `json:"path"`
+	Rationale string `json:"rationale"`
+}
+
+// structuredSummarySchema is the JSON schema sent with strict structured
+// outputs, which require every property to be listed as required.
+var structuredSummarySchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"summary": map[string]any{"type": "string"},
+		"files": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":      map[string]any{"type": "string"},
+					"rationale": map[string]any{"type": "string"},
+				},
+				"required":             []string{"path", "rationale"},
+				"additionalProperties": false,
+			},
+		},
+		"follow_ups": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+	"required":             []string{"summary", "files", "follow_ups"},
+	"additionalProperties": false,
+}
+
+// requestStructuredSummary asks the model, without tools, for a
+// StructuredSummary of a run's transcript.
+func (o *LLMClient) requestStructuredSummary(ctx context.Context, history []adk.Message) (*StructuredSummary, error) {
+	instructions, err := o.loadPrompt("structured_summary.txt")
+	if err != nil {
+		return nil, err
+	}
+	text, err := o.generateText(ctx, instructions, compactionTranscript(history), agenticopenai.WithResponsesText(&responses.ResponseTextConfigParam{
+		Format: responses.ResponseFormatTextConfigUnionParam{
+			OfJSONSchema: &responses.ResponseFormatTextJSONSchemaConfigParam{
+				Name:   "documentation_run_summary",
+				Schema: structuredSummarySchema,
+				Strict: param.NewOpt(true),
+			},
+		},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return parseStructuredSummary(text)
+}
+
+// parseStructuredSummary decodes a model's structured summary, dropping
+// blank entries. A summary without text is an error so callers fall back to
+// the free-text summary.
+func parseStructuredSummary(text string) (*StructuredSummary, error) {
+	var parsed StructuredSummary
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse structured summary: %w", err)
+	}
+	parsed.Summary = strings.TrimSpace(parsed.Summary)
+	if parsed.Summary == "" {
+		return nil, fmt.Errorf("model returned an empty summary")
+	}
+	files := parsed.Files[:0]
+	for _, file := range parsed.Files {
+		file.Path = strings.TrimSpace(file.Path)
+		file.Rationale = strings.TrimSpace(file.Rationale)
+		if file.Path != "" {
+			files = append(files, file)
+		}
+	}
+	parsed.Files = files
+	followUps := parsed.FollowUps[:0]
+	for _, followUp := range parsed.FollowUps {
+		if followUp = strings.TrimSpace(followUp); followUp != "" {
+			followUps = append(followUps, followUp)
+		}
+	}
+	parsed.FollowUps = followUps
+	return &parsed, nil
+}
+
+// structuredRunSummary returns the structured summary of a run when the
+// client requests one, or nil when it does not or the request fails, in
+// which case the free-text summary is used.
+func (o *LLMClient) structuredRunSummary(ctx context.Context, history []adk.Message) *StructuredSummary {
+	if !o.structuredSummary {
+		return nil
+	}
+	structured, err := o.requestStructuredSummary(ctx, history)
+	if err != nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("StructuredSummary: falling back to the free-text summary: %v", err)))
+		return nil
+	}
+	return structured
+}
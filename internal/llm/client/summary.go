@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"narrabyte/internal/events"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+// minSummaryChars is the length below which a run's summary is considered
+// thin, such as a bare "Done." after the last tool call.
+const minSummaryChars = 80
+
+// runSummary tracks the last substantive assistant message of a run: one
+// with text that is not just the wrapper around a tool call. It replaces the
+// last message as the run summary, which is often empty or a terse
+// acknowledgment of a tool result.
+type runSummary struct {
+	text string
+}
+
+func (r *runSummary) observe(msg *schema.Message) {
+	if msg == nil || msg.Role != schema.Assistant || len(msg.ToolCalls) > 0 {
+		return
+	}
+	if content := strings.TrimSpace(msg.Content); content != "" {
+		r.text = content
+	}
+}
+
+func (r *runSummary) observeAgentic(msg *schema.AgenticMessage) {
+	if msg == nil || msg.Role != schema.AgenticRoleTypeAssistant {
+		return
+	}
+	var texts []string
+	for _, block := range msg.ContentBlocks {
+		if block == nil {
+			continue
+		}
+		if block.FunctionToolCall != nil {
+			return
+		}
+		if block.AssistantGenText != nil {
+			texts = append(texts, block.AssistantGenText.Text)
+		}
+	}
+	if content := strings.TrimSpace(strings.Join(texts, "\n")); content != "" {
+		r.text = content
+	}
+}
+
+func (r *runSummary) thin() bool {
+	return utf8.RuneCountInString(r.text) < minSummaryChars
+}
+
+// SetFinalSummaryTurn makes runs whose summary is thin ask the model, without
+// tools, for an explicit summary of the transcript before they return.
+func (o *LLMClient) SetFinalSummaryTurn(enabled bool) {
+	o.finalSummaryTurn = enabled
+}
+
+// finalSummary returns the summary of a run whose messages are history. When
+// the tracked summary is thin and the final summary turn is enabled, the
+// model is asked for one; should that fail, the tracked summary is kept.
+func (o *LLMClient) finalSummary(ctx context.Context, summary *runSummary, history []adk.Message) string {
+	if !o.finalSummaryTurn || !summary.thin() {
+		return summary.text
+	}
+	text, err := o.requestSummary(ctx, history)
+	if err != nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("FinalSummary: unable to request a summary: %v", err)))
+		return summary.text
+	}
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo("FinalSummary: requested a summary from the model"))
+	return text
+}
+
+// requestSummary asks the model, without tools, to summarize a run's transcript.
+func (o *LLMClient) requestSummary(ctx context.Context, history []adk.Message) (string, error) {
+	instructions, err := o.loadPrompt("final_summary.txt")
+	if err != nil {
+		return "", err
+	}
+	text, err := o.generateText(ctx, instructions, compactionTranscript(history))
+	if err != nil {
+		return "", err
+	}
+	if text = strings.TrimSpace(text); text == "" {
+		return "", fmt.Errorf("model returned an empty summary")
+	}
+	return text, nil
+}
+
+// finalAgenticSummary is finalSummary for an agentic model's history. When
+// the client requests structured summaries, it is asked for one first and its
+// text becomes the summary.
+func (o *LLMClient) finalAgenticSummary(ctx context.Context, summary *runSummary, history []*schema.AgenticMessage) (string, *StructuredSummary) {
+	if !o.structuredSummary && (!o.finalSummaryTurn || !summary.thin()) {
+		return summary.text, nil
+	}
+	messages := make([]adk.Message, 0, len(history))
+	for _, msg := range history {
+		if converted := agenticToMessage(msg); converted != nil {
+			messages = append(messages, converted)
+		}
+	}
+	if structured := o.structuredRunSummary(ctx, messages); structured != nil {
+		return structured.Summary, structured
+	}
+	return o.finalSummary(ctx, summary, messages), nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// DocumentationToolNames lists every tool initDocumentationTools can
+// register. Project tool allowlists name tools from this list.
+var DocumentationToolNames = []string{
+	"list_directory_tool",
+	"read_file_tool",
+	"write_file_tool",
+	"edit_tool",
+	"multiedit_tool",
+	"todo_write_tool",
+	"todo_read_tool",
+	"delete_file_tool",
+	"glob_tool",
+	"grep_tool",
+	"inspect_asset_tool",
+	"blame_tool",
+}
+
+// IsDocumentationTool reports whether name is one of DocumentationToolNames.
+func IsDocumentationTool(name string) bool {
+	for _, known := range DocumentationToolNames {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAllowedTools restricts the tools registered for each run to names. An
+// empty list registers every tool; otherwise anything unlisted, including
+// delete_file_tool, is left out.
+func (o *LLMClient) SetAllowedTools(names []string) {
+	o.allowedTools = nil
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			o.allowedTools = append(o.allowedTools, name)
+		}
+	}
+}
+
+func (o *LLMClient) toolAllowed(name string) bool {
+	if len(o.allowedTools) == 0 {
+		return true
+	}
+	for _, allowed := range o.allowedTools {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// filterAllowedTools drops the tools the allowlist does not permit. Plan
+// variants keep the names of the tools they replace, so they follow the same
+// rule.
+func (o *LLMClient) filterAllowedTools(toolList []tool.BaseTool) ([]tool.BaseTool, error) {
+	if len(o.allowedTools) == 0 {
+		return toolList, nil
+	}
+	filtered := make([]tool.BaseTool, 0, len(toolList))
+	for _, t := range toolList {
+		info, err := t.Info(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		if o.toolAllowed(info.Name) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// disabledToolsInstructions tells the model which tools the project has
+// turned off, so it works around them instead of calling them. It is empty
+// when every tool is available.
+func (o *LLMClient) disabledToolsInstructions() string {
+	if len(o.allowedTools) == 0 {
+		return ""
+	}
+	var disabled []string
+	for _, name := range DocumentationToolNames {
+		if !o.toolAllowed(name) {
+			disabled = append(disabled, name)
+		}
+	}
+	if len(disabled) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n# Disabled tools\nThis project has disabled the following tools: %s. They are not available in this session, so do not try to call them. If the task needs one of them, for example to delete a page, leave that change out and mention it in your final summary instead.\n", strings.Join(disabled, ", "))
+}
@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"narrabyte/internal/events"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// blameLineLimit caps how many lines one blame call returns.
+	blameLineLimit = 200
+	// blameTextChars caps the source text shown for each line.
+	blameTextChars = 120
+)
+
+// BlameInput defines the parameters for the blame tool.
+type BlameInput struct {
+	// FilePath is the path to the file relative to the codebase repository root.
+	FilePath string `json:"file_path" jsonschema:"description=The path to the file relative to the codebase repository root (e.g. 'internal/auth/session.go'). NEVER use absolute paths."`
+	// StartLine is the first line to blame (1-based).
+	StartLine int `json:"start_line,omitempty" jsonschema:"description=The first line to blame, 1-based (defaults to 1)"`
+	// EndLine is the last line to blame, inclusive.
+	EndLine int `json:"end_line,omitempty" jsonschema:"description=The last line to blame, inclusive (defaults to 200 lines after start_line)"`
+}
+
+type BlameOutput struct {
+	Title    string            `json:"title"`
+	Output   string            `json:"output"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Blame attributes a region of a code file to the commits that last changed
+// each line. It blames the code snapshot when one is configured and the
+// repository HEAD otherwise, so uncommitted edits are never attributed.
+func Blame(ctx context.Context, in *BlameInput) (*BlameOutput, error) {
+	if in == nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewError("Blame: input is required"))
+		return &BlameOutput{
+			Output:   "Format error: input is required",
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	pathArg := strings.TrimSpace(in.FilePath)
+	if pathArg == "" {
+		events.Emit(ctx, events.LLMEventTool, events.NewError("Blame: file_path is required"))
+		return &BlameOutput{
+			Output:   "Format error: file_path is required",
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	displayPath := FormatDisplayPath(RepositoryCode, pathArg)
+	if in.StartLine < 0 || in.EndLine < 0 || (in.EndLine > 0 && in.EndLine < max(in.StartLine, 1)) {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Blame: invalid line range %d-%d", in.StartLine, in.EndLine)))
+		return &BlameOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: invalid line range %d-%d; lines are 1-based and end_line must not be before start_line", in.StartLine, in.EndLine),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	absPath, err := ResolveRepositoryPath(ctx, RepositoryCode, pathArg)
+	if err != nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Blame: %v", err)))
+		return &BlameOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: %v", err),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	repo, commit, rel, err := blameTarget(ctx, absPath)
+	if err != nil {
+		if errors.Is(err, ErrSnapshotEscapes) {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("Blame: path escapes git snapshot root"))
+			return &BlameOutput{
+				Title:    displayPath,
+				Output:   "Format error: path escapes the configured project root",
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Blame: %v", err)))
+		return &BlameOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Error: the codebase is not a readable git repository: %v", err),
+			Metadata: map[string]string{"error": "no_repository"},
+		}, nil
+	}
+
+	file, err := commit.File(rel)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("Blame: '%s' not found", displayPath)))
+			return &BlameOutput{
+				Title:    displayPath,
+				Output:   fmt.Sprintf("Error: file not found at commit %s: %s", shortHash(commit.Hash), displayPath),
+				Metadata: map[string]string{"error": "not_found"},
+			}, nil
+		}
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Blame: %v", err)))
+		return &BlameOutput{Title: displayPath}, err
+	}
+	if binary, _ := file.IsBinary(); binary {
+		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("Blame: '%s' is binary", displayPath)))
+		return &BlameOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: cannot blame a binary file: %s", displayPath),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result, err := git.Blame(commit, rel)
+	if err != nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Blame: %v", err)))
+		return &BlameOutput{Title: displayPath}, err
+	}
+
+	total := len(result.Lines)
+	start := max(in.StartLine, 1)
+	if total == 0 || start > total {
+		events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("Blame: line %d is past the end of '%s'", start, displayPath)))
+		return &BlameOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: start_line %d is past the end of the file (%d lines)", start, total),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	end := in.EndLine
+	if end == 0 || end > total {
+		end = total
+	}
+	limited := false
+	if end-start+1 > blameLineLimit {
+		end = start + blameLineLimit - 1
+		limited = true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Blame of %s lines %d-%d of %d at commit %s\n\n", displayPath, start, end, total, shortHash(commit.Hash))
+	var order []plumbing.Hash
+	seen := make(map[plumbing.Hash]bool)
+	for i := start; i <= end; i++ {
+		line := result.Lines[i-1]
+		author := line.AuthorName
+		if author == "" {
+			author = line.Author
+		}
+		fmt.Fprintf(&b, "%6d %s %s %s | %s\n", i, shortHash(line.Hash), line.Date.Format("2006-01-02"), author, truncateBlameText(line.Text))
+		if !seen[line.Hash] {
+			seen[line.Hash] = true
+			order = append(order, line.Hash)
+		}
+	}
+	if limited {
+		fmt.Fprintf(&b, "(limited to %d lines; use start_line=%d to continue)\n", blameLineLimit, end+1)
+	}
+
+	b.WriteString("\nCommits:\n")
+	for _, hash := range order {
+		c, err := repo.CommitObject(hash)
+		if err != nil {
+			fmt.Fprintf(&b, "%s\n", shortHash(hash))
+			continue
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		fmt.Fprintf(&b, "%s %s %s: %s\n", shortHash(hash), c.Author.When.Format("2006-01-02"), c.Author.Name, subject)
+	}
+
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("Blame: %d lines of '%s' from %d commits", end-start+1, displayPath, len(order))))
+	return &BlameOutput{
+		Title:  displayPath,
+		Output: b.String(),
+		Metadata: map[string]string{
+			"commit":  commit.Hash.String(),
+			"lines":   fmt.Sprintf("%d", end-start+1),
+			"commits": fmt.Sprintf("%d", len(order)),
+			"limited": fmt.Sprintf("%v", limited),
+		},
+	}, nil
+}
+
+// blameTarget returns the repository, the commit to blame at and the path of
+// absPath relative to the repository root.
+func blameTarget(ctx context.Context, absPath string) (*git.Repository, *object.Commit, string, error) {
+	if snapshot := currentGitSnapshot(ctx); snapshot != nil {
+		rel, err := snapshot.relativeFromAbs(absPath)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return snapshot.repo, snapshot.commit, rel, nil
+	}
+
+	repo, err := git.PlainOpenWithOptions(getCodeRoot(ctx), &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	rel, err := filepath.Rel(worktree.Filesystem.Root(), absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, nil, "", ErrSnapshotEscapes
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return repo, commit, filepath.ToSlash(rel), nil
+}
+
+func shortHash(hash plumbing.Hash) string {
+	return hash.String()[:7]
+}
+
+func truncateBlameText(text string) string {
+	text = strings.TrimRight(text, "\r")
+	if utf8.RuneCountInString(text) <= blameTextChars {
+		return text
+	}
+	return string([]rune(text)[:blameTextChars]) + "…"
+}
@@ -2,7 +2,9 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -13,7 +15,16 @@ type baseContext struct {
 	docsRoot string
 	codeRoot string
 	snapshot *GitSnapshot
-	ignores  []string
+	// docsSnapshot is the committed docs branch, searched when a tool asks for published docs
+	docsSnapshot *GitSnapshot
+	ignores      []string
+	// repoIgnores holds patterns that apply to one repository only, e.g. from .narrabyteignore
+	repoIgnores map[Repository][]string
+	stats       *RunStats
+	// plan collects the changes proposed by plan tools instead of writing them
+	plan []PlannedChange
+	// maxFileSize caps files written by WriteFile and Edit; 0 means DefaultMaxFileSize
+	maxFileSize int64
 }
 
 var (
@@ -22,6 +33,11 @@ var (
 	sessionContexts = make(map[string]*baseContext)
 )
 
+// ErrNoSession is returned when a tool resolves a repository path without a
+// session while session-scoped runs are configured. Falling back to the
+// default roots then could hand one run another run's workspace.
+var ErrNoSession = errors.New("tool invoked without a session while session runs are active")
+
 type contextKey string
 
 const sessionIDKey contextKey = "narrabyte/tools/session"
@@ -71,6 +87,28 @@ func ensureSessionContext(sessionID string) *baseContext {
 	return ctx
 }
 
+// sessionRootsActive reports whether any session has a repository root
+// configured, i.e. whether session-scoped runs may be in progress.
+func sessionRootsActive() bool {
+	contextMu.RLock()
+	defer contextMu.RUnlock()
+	for _, ctx := range sessionContexts {
+		if ctx.root != "" || ctx.docsRoot != "" || ctx.codeRoot != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSessionScope rejects default-context access from ctx while session
+// runs are active; calls carrying a session ID only ever see that session.
+func checkSessionScope(ctx context.Context) error {
+	if SessionIDFromContext(ctx) == "" && sessionRootsActive() {
+		return ErrNoSession
+	}
+	return nil
+}
+
 func lookupSessionContext(sessionID string) *baseContext {
 	if strings.TrimSpace(sessionID) == "" {
 		return defaultContext
@@ -144,13 +182,44 @@ func GetScopedIgnorePatternsForSession(sessionID string) []string {
 	return nil
 }
 
-// scopedIgnorePatterns resolves the effective ignore pattern list for ctx.
-func scopedIgnorePatterns(ctx context.Context) []string {
+// SetRepositoryIgnorePatternsForSession configures ignore patterns that only apply
+// to repo in a specific session. An empty session ID configures the defaults.
+func SetRepositoryIgnorePatternsForSession(sessionID string, repo Repository, patterns []string) {
+	ctx := ensureSessionContext(sessionID)
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	if len(patterns) == 0 {
+		delete(ctx.repoIgnores, repo)
+		return
+	}
+	if ctx.repoIgnores == nil {
+		ctx.repoIgnores = make(map[Repository][]string)
+	}
+	ctx.repoIgnores[repo] = append([]string{}, patterns...)
+}
+
+// RepositoryIgnorePatternsForSession returns the ignore patterns configured for repo in a session.
+func RepositoryIgnorePatternsForSession(sessionID string, repo Repository) []string {
+	ctx := lookupSessionContext(sessionID)
+	if ctx == nil {
+		return nil
+	}
+	contextMu.RLock()
+	defer contextMu.RUnlock()
+	if len(ctx.repoIgnores[repo]) == 0 {
+		return nil
+	}
+	return append([]string{}, ctx.repoIgnores[repo]...)
+}
+
+// scopedIgnorePatterns resolves the effective ignore pattern list for repo in ctx.
+func scopedIgnorePatterns(ctx context.Context, repo Repository) []string {
 	sessionID := SessionIDFromContext(ctx)
-	if ignores := GetScopedIgnorePatternsForSession(sessionID); len(ignores) > 0 {
-		return ignores
+	ignores := GetScopedIgnorePatternsForSession(sessionID)
+	if len(ignores) == 0 {
+		ignores = GetScopedIgnorePatterns()
 	}
-	return GetScopedIgnorePatterns()
+	return append(ignores, RepositoryIgnorePatternsForSession(sessionID, repo)...)
 }
 
 // SetGitSnapshot binds the default Git snapshot for read operations.
@@ -172,14 +241,76 @@ func GitSnapshotForSession(sessionID string) *GitSnapshot {
 	return nil
 }
 
-// currentGitSnapshot resolves the snapshot to use for ctx.
+// currentGitSnapshot resolves the snapshot to use for ctx. A session never
+// falls back to the default snapshot.
 func currentGitSnapshot(ctx context.Context) *GitSnapshot {
-	if snap := GitSnapshotForSession(SessionIDFromContext(ctx)); snap != nil {
-		return snap
+	if sessionID := SessionIDFromContext(ctx); sessionID != "" {
+		return GitSnapshotForSession(sessionID)
+	}
+	if checkSessionScope(ctx) != nil {
+		return nil
 	}
 	return defaultContext.snapshot
 }
 
+// SetDocsSnapshotForSession binds a snapshot of the committed docs branch for a session.
+func SetDocsSnapshotForSession(sessionID string, snapshot *GitSnapshot) {
+	ctx := ensureSessionContext(sessionID)
+	ctx.docsSnapshot = snapshot
+}
+
+// DocsSnapshotForSession returns the docs branch snapshot associated with a session.
+func DocsSnapshotForSession(sessionID string) *GitSnapshot {
+	if ctx := lookupSessionContext(sessionID); ctx != nil {
+		return ctx.docsSnapshot
+	}
+	return nil
+}
+
+// currentDocsSnapshot resolves the docs branch snapshot to use for ctx. A
+// session never falls back to the default snapshot.
+func currentDocsSnapshot(ctx context.Context) *GitSnapshot {
+	if sessionID := SessionIDFromContext(ctx); sessionID != "" {
+		return DocsSnapshotForSession(sessionID)
+	}
+	if checkSessionScope(ctx) != nil {
+		return nil
+	}
+	return defaultContext.docsSnapshot
+}
+
+// snapshotForRepository resolves the snapshot that snapshot-backed reads of repo use for ctx.
+func snapshotForRepository(ctx context.Context, repo Repository) *GitSnapshot {
+	if repo == RepositoryDocs {
+		return currentDocsSnapshot(ctx)
+	}
+	return currentGitSnapshot(ctx)
+}
+
+// DefaultMaxFileSize is the largest file WriteFile and Edit write when no
+// other limit is configured.
+const DefaultMaxFileSize int64 = 4 << 20
+
+// SetMaxFileSize sets the default file size limit of WriteFile and Edit;
+// 0 restores DefaultMaxFileSize.
+func SetMaxFileSize(bytes int64) {
+	defaultContext.maxFileSize = bytes
+}
+
+// SetMaxFileSizeForSession sets the file size limit for a specific session.
+func SetMaxFileSizeForSession(sessionID string, bytes int64) {
+	ctx := ensureSessionContext(sessionID)
+	ctx.maxFileSize = bytes
+}
+
+// maxFileSize resolves the file size limit for ctx.
+func maxFileSize(ctx context.Context) int64 {
+	if c := lookupSessionContext(SessionIDFromContext(ctx)); c != nil && c.maxFileSize > 0 {
+		return c.maxFileSize
+	}
+	return DefaultMaxFileSize
+}
+
 // ClearSession releases per-session state.
 func ClearSession(sessionID string) {
 	if strings.TrimSpace(sessionID) == "" {
@@ -190,6 +321,22 @@ func ClearSession(sessionID string) {
 	contextMu.Unlock()
 }
 
+// ReleaseSessionRoots unsets the repository roots of a session while keeping
+// the rest of its state. A paused or canceled run releases them at once, so
+// the session no longer counts as active for checkSessionScope.
+func ReleaseSessionRoots(sessionID string) {
+	if strings.TrimSpace(sessionID) == "" {
+		return
+	}
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	if ctx, ok := sessionContexts[sessionID]; ok {
+		ctx.root = ""
+		ctx.docsRoot = ""
+		ctx.codeRoot = ""
+	}
+}
+
 // SetDocsRootForSession sets the documentation repository root for a specific session.
 func SetDocsRootForSession(sessionID, root string) {
 	ctx := ensureSessionContext(sessionID)
@@ -236,6 +383,10 @@ func ResolveRepositoryPath(ctx context.Context, repo Repository, relPath string)
 		rel = "."
 	}
 
+	if err := checkSessionScope(ctx); err != nil {
+		return "", err
+	}
+
 	// Reject absolute paths
 	if filepath.IsAbs(rel) {
 		return "", fmt.Errorf("absolute paths are not allowed; use a relative path within the repository (got: %s)", rel)
@@ -266,6 +417,9 @@ func ResolveRepositoryPath(ctx context.Context, repo Repository, relPath string)
 
 // GetRepositoryRoot returns the root path for a repository in the given context.
 func GetRepositoryRoot(ctx context.Context, repo Repository) (string, error) {
+	if err := checkSessionScope(ctx); err != nil {
+		return "", err
+	}
 	switch repo {
 	case RepositoryDocs:
 		root := getDocsRoot(ctx)
@@ -319,11 +473,11 @@ func safeJoinUnderBase(base, p string) (abs string, ok bool) {
 	if err != nil {
 		return "", false
 	}
-	// Resolve symlinks for the candidate path
-	evalCandidate, err := filepath.EvalSymlinks(absCandidate)
+	// Resolve symlinks for the candidate path so a link inside base cannot
+	// point the caller outside of it
+	evalCandidate, err := evalSymlinksAllowMissing(absCandidate)
 	if err != nil {
-		// If symlink evaluation fails (e.g., file doesn't exist yet), fall back to absolute path
-		evalCandidate = absCandidate
+		return "", false
 	}
 
 	// Ensure evalCandidate is within evalBase
@@ -335,12 +489,53 @@ func safeJoinUnderBase(base, p string) (abs string, ok bool) {
 		return absCandidate, true
 	}
 	// If rel starts with ".." then it escapes
-	if len(relToBase) >= 2 && relToBase[:2] == ".." {
+	if relToBase == ".." || strings.HasPrefix(relToBase, ".."+string(filepath.Separator)) {
 		return "", false
 	}
 	return absCandidate, true
 }
 
+// CanonicalPath returns p as an absolute path with its symlinks resolved, the
+// form safeJoinUnderBase compares paths in, so two paths reaching the same file
+// through different links compare equal. Trailing components that do not exist
+// yet are kept as they are.
+func CanonicalPath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.Clean(p)
+	}
+	resolved, err := evalSymlinksAllowMissing(abs)
+	if err != nil {
+		return abs
+	}
+	return resolved
+}
+
+// evalSymlinksAllowMissing resolves the symlinks in path like
+// filepath.EvalSymlinks, but trailing components that do not exist yet, such
+// as a file about to be written, are appended to their resolved parent. A
+// path that exists but cannot be resolved, e.g. a dangling symlink whose
+// target a write would create, is an error.
+func evalSymlinksAllowMissing(path string) (string, error) {
+	var missing []string
+	current := path
+	for {
+		resolved, err := filepath.EvalSymlinks(current)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if _, statErr := os.Lstat(current); statErr == nil {
+			return "", err
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(current)}, missing...)
+		current = parent
+	}
+}
+
 func formatSnapshotInfo(snapshot *GitSnapshot) string {
 	if snapshot == nil {
 		return "no-snapshot"
@@ -26,6 +26,10 @@ type EditInput struct {
 	NewString string `json:"new_string" jsonschema:"description=The replacement text"`
 	// ReplaceAll replaces all occurrences of old_string instead of just the first.
 	ReplaceAll bool `json:"replace_all,omitempty" jsonschema:"description=Replace all occurrences of old_string instead of a single instance"`
+	// Append adds NewString to the end of the file, creating it if needed; OldString must be empty.
+	Append bool `json:"append,omitempty" jsonschema:"description=Add new_string to the end of the file instead of replacing text; creates the file if it does not exist. old_string must be empty."`
+	// Prepend adds NewString to the start of the file, creating it if needed; OldString must be empty.
+	Prepend bool `json:"prepend,omitempty" jsonschema:"description=Add new_string to the start of the file instead of replacing text; creates the file if it does not exist. old_string must be empty."`
 }
 
 type EditOutput struct {
@@ -117,6 +121,19 @@ func Edit(ctx context.Context, in *EditInput) (*EditOutput, error) {
 		}, nil
 	}
 
+	if formatErr := insertModeError(in); formatErr != "" {
+		events.Emit(ctx, events.LLMEventTool, events.NewError("Edit: "+formatErr))
+		return &EditOutput{
+			Title:  "",
+			Output: "Format error: " + formatErr,
+			Metadata: map[string]string{
+				"error":       "format_error",
+				"replaced":    "false",
+				"occurrences": "0",
+			},
+		}, nil
+	}
+
 	// Resolve path using the repository-scoped resolver
 	abs, err := ResolveRepositoryPath(ctx, in.Repository, pathArg)
 	if err != nil {
@@ -182,7 +199,12 @@ func Edit(ctx context.Context, in *EditInput) (*EditOutput, error) {
 	var contentNew string
 	replacedCount := 1
 
-	if in.OldString == "" {
+	if in.Append || in.Prepend {
+		if existing, readErr := os.ReadFile(abs); readErr == nil {
+			contentOld = string(existing)
+		}
+		contentNew = insertContent(contentOld, in.NewString, in.Prepend)
+	} else if in.OldString == "" {
 		if existing, readErr := os.ReadFile(abs); readErr == nil {
 			contentOld = string(existing)
 		}
@@ -237,6 +259,19 @@ func Edit(ctx context.Context, in *EditInput) (*EditOutput, error) {
 		replacedCount = occ
 	}
 
+	if limit := maxFileSize(ctx); int64(len(contentNew)) > limit {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Edit: result of %d bytes exceeds the %d byte limit", len(contentNew), limit)))
+		return &EditOutput{
+			Title:  displayPath,
+			Output: fileSizeError(int64(len(contentNew)), limit),
+			Metadata: map[string]string{
+				"error":       "format_error",
+				"replaced":    "false",
+				"occurrences": "0",
+			},
+		}, nil
+	}
+
 	if err := os.WriteFile(abs, []byte(contentNew), 0o644); err != nil {
 		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("Edit: write error: %v", err)))
 		return nil, err
@@ -262,6 +297,44 @@ func Edit(ctx context.Context, in *EditInput) (*EditOutput, error) {
 	}, nil
 }
 
+// insertModeError describes why an append or prepend edit is malformed, or
+// returns "" when it is valid or in is a regular edit.
+func insertModeError(in *EditInput) string {
+	if !in.Append && !in.Prepend {
+		return ""
+	}
+	switch {
+	case in.Append && in.Prepend:
+		return "append and prepend cannot both be set"
+	case in.OldString != "":
+		return "old_string must be empty when appending or prepending"
+	case in.ReplaceAll:
+		return "replace_all cannot be combined with append or prepend"
+	case in.NewString == "":
+		return "new_string is required when appending or prepending"
+	}
+	return ""
+}
+
+// insertContent adds text to the end of content, or to its start when
+// prepend is set, starting a new line between them when neither side
+// already does.
+func insertContent(content, text string, prepend bool) string {
+	if content == "" {
+		return text
+	}
+	if prepend {
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		return text + content
+	}
+	if !strings.HasSuffix(content, "\n") && !strings.HasPrefix(text, "\n") {
+		content += "\n"
+	}
+	return content + text
+}
+
 func replaceContent(content, old, newVal string, replaceAll bool) (string, int, error) {
 	if old == newVal {
 		return "", 0, fmt.Errorf("old_string and new_string must be different")
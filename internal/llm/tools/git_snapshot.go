@@ -199,6 +199,33 @@ func (s *GitSnapshot) readFile(rel string) ([]byte, bool, error) {
 	return data, isBinary, nil
 }
 
+// openFile opens rel for streaming and returns its size without reading the
+// blob into memory.
+func (s *GitSnapshot) openFile(rel string) (io.ReadCloser, int64, error) {
+	if s == nil {
+		return nil, 0, fmt.Errorf("git snapshot not configured")
+	}
+	cleaned := strings.TrimSpace(rel)
+	if cleaned == "" || cleaned == "." {
+		return nil, 0, ErrSnapshotDirectory
+	}
+	if subtree, err := s.treeFor(cleaned); err == nil && subtree != nil {
+		return nil, 0, ErrSnapshotDirectory
+	}
+	file, err := s.commit.File(path.Clean(cleaned))
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, 0, ErrSnapshotNotFound
+		}
+		return nil, 0, err
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, 0, err
+	}
+	return reader, file.Size, nil
+}
+
 func (s *GitSnapshot) suggestions(dirRel, baseName string, limit int) []string {
 	tree, err := s.treeFor(dirRel)
 	if err != nil {
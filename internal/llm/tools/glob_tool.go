@@ -102,7 +102,7 @@ func Glob(ctx context.Context, in *GlobInput) (*GlobOutput, error) {
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("Glob: searching in '%s'", displayPath)))
 
 	ignorePatterns := append([]string{}, DefaultIgnorePatterns...)
-	ignorePatterns = append(ignorePatterns, scopedIgnorePatterns(ctx)...)
+	ignorePatterns = append(ignorePatterns, scopedIgnorePatterns(ctx, in.Repository)...)
 
 	type fileInfo struct {
 		path  string
@@ -29,6 +29,8 @@ type GrepInput struct {
 	Path string `json:"path,omitempty" jsonschema:"description=Relative directory within the repository to search. Omit or use empty string for repository root. NEVER use absolute paths."`
 	// Include is an optional file glob to include (e.g. "*.js", "*.{ts,tsx}").
 	Include string `json:"include,omitempty" jsonschema:"description=Optional file pattern to include in the search (e.g. \"*.js\", \"*.{ts,tsx}\")"`
+	// Published searches the committed docs branch instead of the in-progress workspace. Docs repository only.
+	Published bool `json:"published,omitempty" jsonschema:"description=Docs repository only: search the committed docs branch as published before this run instead of the current workspace with your edits"`
 }
 
 type GrepOutput struct {
@@ -124,7 +126,7 @@ func Grep(ctx context.Context, in *GrepInput) (*GrepOutput, error) {
 	}
 
 	ignorePatterns := append([]string{}, DefaultIgnorePatterns...)
-	ignorePatterns = append(ignorePatterns, scopedIgnorePatterns(ctx)...)
+	ignorePatterns = append(ignorePatterns, scopedIgnorePatterns(ctx, in.Repository)...)
 
 	// Check for context cancellation early
 	if ctx != nil {
@@ -159,9 +161,28 @@ func Grep(ctx context.Context, in *GrepInput) (*GrepOutput, error) {
 	}
 	var matches []match
 
-	// Use git snapshot only for code repository when a snapshot is configured
-	if in.Repository == RepositoryCode {
-		if snapshot := currentGitSnapshot(ctx); snapshot != nil {
+	published := in.Repository == RepositoryDocs && in.Published
+	scope := ""
+	if published {
+		if currentDocsSnapshot(ctx) == nil {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("Grep: published docs snapshot is not available"))
+			return &GrepOutput{
+				Title:  displayPath,
+				Output: "Format error: searching the published docs branch is not enabled for this session",
+				Metadata: map[string]string{
+					"error":     "format_error",
+					"matches":   "0",
+					"truncated": "false",
+				},
+			}, nil
+		}
+		scope = " in the published docs branch"
+	}
+
+	// Use git snapshot for the code repository when a snapshot is configured,
+	// and for the docs repository when the published branch is requested
+	if in.Repository == RepositoryCode || published {
+		if snapshot := snapshotForRepository(ctx, in.Repository); snapshot != nil {
 			rel, relErr := snapshot.relativeFromAbs(searchPath)
 			if relErr != nil {
 				if errors.Is(relErr, ErrSnapshotEscapes) {
@@ -472,7 +493,7 @@ buildOutput:
 	}
 
 	var outLines []string
-	outLines = append(outLines, fmt.Sprintf("Found %d matches", len(matches)))
+	outLines = append(outLines, fmt.Sprintf("Found %d matches%s", len(matches), scope))
 	current := ""
 	for _, m := range matches {
 		if m.path != current {
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the per-repository file listing paths the agent should not see.
+const IgnoreFileName = ".narrabyteignore"
+
+// LoadIgnoreFile reads the .narrabyteignore file at root and returns its
+// patterns. The syntax follows .gitignore: blank lines and '#' comments are
+// skipped, a trailing '/' matches directories only, and '*' / '?' wildcards
+// match within a single path segment. A leading '/' is accepted but patterns
+// are matched at any depth, and negated '!' patterns are not supported and are
+// skipped. A missing file yields no patterns.
+func LoadIgnoreFile(root string) ([]string, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, nil
+	}
+	f, err := os.Open(filepath.Join(root, IgnoreFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
@@ -0,0 +1,304 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"narrabyte/internal/events"
+)
+
+// assetSniffSize is how much of a file is buffered to detect its type. Image
+// headers, including the root element of most SVG files, fit well within it.
+const assetSniffSize = 4096
+
+// InspectAssetInput defines the parameters for the inspect asset tool.
+type InspectAssetInput struct {
+	// Repository specifies which repository the path is relative to.
+	Repository Repository `json:"repository" jsonschema:"enum=docs,enum=code,description=Which repository the path is relative to: 'docs' for documentation repository or 'code' for the codebase repository"`
+	// FilePath is the relative path to the file within the specified repository.
+	FilePath string `json:"file_path" jsonschema:"description=The path to the file relative to the repository root (e.g. 'public/images/diagram.png'). NEVER use absolute paths."`
+}
+
+type InspectAssetOutput struct {
+	Title    string            `json:"title"`
+	Output   string            `json:"output"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// assetInfo is what inspectAssetHeader learns from the start of a file.
+type assetInfo struct {
+	mimeType      string
+	width, height float64
+	hasDimensions bool
+}
+
+// InspectAsset reports the type, dimensions and size of a file without
+// returning its content. Only the header is decoded, so large assets are cheap
+// to inspect.
+func InspectAsset(ctx context.Context, in *InspectAssetInput) (*InspectAssetOutput, error) {
+	if in == nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewError("InspectAsset: input is required"))
+		return &InspectAssetOutput{
+			Output:   "Format error: input is required",
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	if !in.Repository.IsValid() {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("InspectAsset: invalid repository '%s'", in.Repository)))
+		return &InspectAssetOutput{
+			Output:   fmt.Sprintf("Format error: invalid repository '%s'; must be 'docs' or 'code'", in.Repository),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	pathArg := strings.TrimSpace(in.FilePath)
+	if pathArg == "" {
+		events.Emit(ctx, events.LLMEventTool, events.NewError("InspectAsset: file_path is required"))
+		return &InspectAssetOutput{
+			Output:   "Format error: file_path is required",
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	displayPath := FormatDisplayPath(in.Repository, pathArg)
+	absPath, err := ResolveRepositoryPath(ctx, in.Repository, pathArg)
+	if err != nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("InspectAsset: %v", err)))
+		return &InspectAssetOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: %v", err),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	reader, size, err := openAsset(ctx, in.Repository, absPath)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSnapshotNotFound), errors.Is(err, os.ErrNotExist):
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn(fmt.Sprintf("InspectAsset: '%s' not found", displayPath)))
+			return &InspectAssetOutput{
+				Title:    displayPath,
+				Output:   fmt.Sprintf("Error: file not found: %s", displayPath),
+				Metadata: map[string]string{"error": "not_found"},
+			}, nil
+		case errors.Is(err, ErrSnapshotDirectory):
+			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("InspectAsset: '%s' is a directory", displayPath)))
+			return &InspectAssetOutput{
+				Title:    displayPath,
+				Output:   fmt.Sprintf("Format error: path is a directory, not a file: %s", displayPath),
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		case errors.Is(err, ErrSnapshotEscapes):
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("InspectAsset: path escapes git snapshot root"))
+			return &InspectAssetOutput{
+				Title:    displayPath,
+				Output:   "Format error: path escapes the configured project root",
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("InspectAsset: %v", err)))
+		return &InspectAssetOutput{Title: displayPath}, err
+	}
+	defer reader.Close()
+
+	info := inspectAssetHeader(reader, absPath)
+	meta := map[string]string{
+		"type":       info.mimeType,
+		"size_bytes": strconv.FormatInt(size, 10),
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Type: %s\n", info.mimeType)
+	if info.hasDimensions {
+		width := strconv.FormatFloat(info.width, 'f', -1, 64)
+		height := strconv.FormatFloat(info.height, 'f', -1, 64)
+		meta["width"] = width
+		meta["height"] = height
+		fmt.Fprintf(&b, "Dimensions: %sx%s\n", width, height)
+	}
+	fmt.Fprintf(&b, "Size: %d bytes\n", size)
+
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("InspectAsset: inspected '%s' (%s)", displayPath, info.mimeType)))
+	return &InspectAssetOutput{
+		Title:    displayPath,
+		Output:   b.String(),
+		Metadata: meta,
+	}, nil
+}
+
+// openAsset opens absPath from the code snapshot when one is configured and
+// from disk otherwise.
+func openAsset(ctx context.Context, repo Repository, absPath string) (io.ReadCloser, int64, error) {
+	if repo == RepositoryCode {
+		if snapshot := currentGitSnapshot(ctx); snapshot != nil {
+			rel, err := snapshot.relativeFromAbs(absPath)
+			if err != nil {
+				return nil, 0, err
+			}
+			return snapshot.openFile(rel)
+		}
+	}
+	st, err := os.Stat(absPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if st.IsDir() {
+		return nil, 0, ErrSnapshotDirectory
+	}
+	f, err := os.Open(absPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	return f, st.Size(), nil
+}
+
+// inspectAssetHeader detects the type of the file r reads from and, for
+// images, its dimensions. name is only used to recognise SVG files whose root
+// element lies beyond the sniffed header.
+func inspectAssetHeader(r io.Reader, name string) assetInfo {
+	br := bufio.NewReaderSize(r, assetSniffSize)
+	head, _ := br.Peek(assetSniffSize)
+
+	switch {
+	case len(head) >= 30 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		info := assetInfo{mimeType: "image/webp"}
+		info.width, info.height, info.hasDimensions = webpDimensions(head)
+		return info
+	case len(head) >= 26 && string(head[0:2]) == "BM":
+		info := assetInfo{mimeType: "image/bmp"}
+		info.width, info.height, info.hasDimensions = bmpDimensions(head)
+		return info
+	case isSVG(head, name):
+		info := assetInfo{mimeType: "image/svg+xml"}
+		info.width, info.height, info.hasDimensions = svgDimensions(head)
+		return info
+	}
+
+	if cfg, format, err := image.DecodeConfig(br); err == nil {
+		return assetInfo{
+			mimeType:      "image/" + format,
+			width:         float64(cfg.Width),
+			height:        float64(cfg.Height),
+			hasDimensions: true,
+		}
+	}
+	return assetInfo{mimeType: http.DetectContentType(head)}
+}
+
+func webpDimensions(head []byte) (float64, float64, bool) {
+	switch string(head[12:16]) {
+	case "VP8 ":
+		// Lossy: 14-bit sizes follow the frame tag and start code
+		if head[23] != 0x9d || head[24] != 0x01 || head[25] != 0x2a {
+			return 0, 0, false
+		}
+		w := binary.LittleEndian.Uint16(head[26:28]) & 0x3fff
+		h := binary.LittleEndian.Uint16(head[28:30]) & 0x3fff
+		return float64(w), float64(h), true
+	case "VP8L":
+		// Lossless: both sizes minus one packed into 14 bits each
+		if head[20] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(head[21:25])
+		return float64(bits&0x3fff + 1), float64((bits>>14)&0x3fff + 1), true
+	case "VP8X":
+		// Extended: 24-bit canvas sizes minus one
+		w := uint32(head[24]) | uint32(head[25])<<8 | uint32(head[26])<<16
+		h := uint32(head[27]) | uint32(head[28])<<8 | uint32(head[29])<<16
+		return float64(w + 1), float64(h + 1), true
+	}
+	return 0, 0, false
+}
+
+func bmpDimensions(head []byte) (float64, float64, bool) {
+	if binary.LittleEndian.Uint32(head[14:18]) == 12 {
+		// OS/2 BITMAPCOREHEADER uses 16-bit sizes
+		w := int16(binary.LittleEndian.Uint16(head[18:20]))
+		h := int16(binary.LittleEndian.Uint16(head[20:22]))
+		return absFloat(float64(w)), absFloat(float64(h)), true
+	}
+	// Negative heights mark top-down bitmaps
+	w := int32(binary.LittleEndian.Uint32(head[18:22]))
+	h := int32(binary.LittleEndian.Uint32(head[22:26]))
+	return absFloat(float64(w)), absFloat(float64(h)), true
+}
+
+func isSVG(head []byte, name string) bool {
+	if strings.EqualFold(filepath.Ext(name), ".svg") {
+		return true
+	}
+	return bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
+
+// svgDimensions reads the width and height of the root <svg> element, falling
+// back to its viewBox when they are missing or relative.
+func svgDimensions(head []byte) (float64, float64, bool) {
+	dec := xml.NewDecoder(bytes.NewReader(head))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, 0, false
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "svg" {
+			continue
+		}
+		var widthAttr, heightAttr, viewBox string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "width":
+				widthAttr = attr.Value
+			case "height":
+				heightAttr = attr.Value
+			case "viewBox":
+				viewBox = attr.Value
+			}
+		}
+		w, wok := svgLength(widthAttr)
+		h, hok := svgLength(heightAttr)
+		if wok && hok {
+			return w, h, true
+		}
+		fields := strings.FieldsFunc(viewBox, func(r rune) bool { return r == ' ' || r == ',' })
+		if len(fields) == 4 {
+			vw, werr := strconv.ParseFloat(fields[2], 64)
+			vh, herr := strconv.ParseFloat(fields[3], 64)
+			if werr == nil && herr == nil && vw > 0 && vh > 0 {
+				return vw, vh, true
+			}
+		}
+		return 0, 0, false
+	}
+}
+
+// svgLength parses an absolute SVG length in user units or pixels.
+func svgLength(value string) (float64, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
+
+func absFloat(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
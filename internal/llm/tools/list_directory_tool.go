@@ -53,6 +53,10 @@ type ListLSInput struct {
 	Path string `json:"path,omitempty" jsonschema:"description=The path to the directory relative to the repository root (e.g. 'src' or 'api'). NEVER use absolute paths. Omit or use empty string for repository root."`
 	// Ignore is a list of glob-like patterns to ignore.
 	Ignore []string `json:"ignore,omitempty" jsonschema:"description=List of glob-like patterns to ignore"`
+	// Depth limits how many directory levels below Path are listed; 0 lists every level.
+	Depth int `json:"depth,omitempty" jsonschema:"description=How many directory levels below the path to list, including directories themselves (e.g. 1 lists only the direct children). Omit to list files at every level"`
+	// Tree renders the listing with tree connectors and includes directories.
+	Tree bool `json:"tree,omitempty" jsonschema:"description=Render a tree view with connectors that also shows directories; defaults to 3 levels when no depth is given"`
 }
 
 type ListDirectoryOutput struct {
@@ -114,11 +118,23 @@ func ListDirectory(ctx context.Context, in *ListLSInput) (*ListDirectoryOutput,
 
 	// Compose ignore patterns
 	patterns := append([]string{}, DefaultIgnorePatterns...)
-	patterns = append(patterns, scopedIgnorePatterns(ctx)...)
+	patterns = append(patterns, scopedIgnorePatterns(ctx, in.Repository)...)
 	if len(in.Ignore) > 0 {
 		patterns = append(patterns, in.Ignore...)
 	}
 
+	if in.Depth < 0 {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("ListDirectory: invalid depth %d", in.Depth)))
+		return &ListDirectoryOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: depth must be 0 or greater, got %d", in.Depth),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	if in.Tree || in.Depth > 0 {
+		return listDirectoryTree(ctx, in, searchPath, displayPath, patterns)
+	}
+
 	var (
 		files   []string
 		limited bool
@@ -365,7 +381,7 @@ func matchIgnoredDir(relDir string, patterns []string) bool {
 				return true
 			}
 			for _, s := range segs {
-				if s == dirPat {
+				if matchIgnoreSegment(s, dirPat) {
 					return true
 				}
 			}
@@ -402,10 +418,24 @@ func matchIgnoredFile(relFile string, patterns []string) bool {
 				return true
 			}
 		}
+		// Wildcard basename match (e.g. "*.pdf") when pattern has no '/'
+		if strings.ContainsAny(p, "*?[") && !strings.Contains(p, "/") && matchIgnoreSegment(base, p) {
+			return true
+		}
 	}
 	return false
 }
 
+// matchIgnoreSegment reports whether a single path segment matches pattern,
+// which may use '*', '?' and '[...]' wildcards.
+func matchIgnoreSegment(segment, pattern string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return segment == pattern
+	}
+	ok, err := path.Match(pattern, segment)
+	return err == nil && ok
+}
+
 func collectFilesFromSnapshot(ctx context.Context, snapshot *GitSnapshot, rel string, patterns []string) ([]string, bool, error) {
 	normalized := strings.TrimSpace(rel)
 	if normalized == "" {
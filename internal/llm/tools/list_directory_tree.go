@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/events"
+)
+
+const (
+	// defaultTreeDepth is used when a tree is requested without a depth.
+	defaultTreeDepth = 3
+	// treeEntryLimit caps the files and directories of a depth-limited listing.
+	treeEntryLimit = 300
+)
+
+// listTreeNode is a file or directory of a depth-limited listing.
+type listTreeNode struct {
+	name     string
+	dir      bool
+	children []*listTreeNode
+}
+
+// listTreeEntry is one entry returned by a listTreeReader.
+type listTreeEntry struct {
+	name string
+	dir  bool
+}
+
+// listTreeReader lists the entries of rel, a slash-separated path relative to
+// the listed directory.
+type listTreeReader func(rel string) ([]listTreeEntry, error)
+
+// listDirectoryTree renders searchPath down to in.Depth levels, including
+// directories, and stops after treeEntryLimit entries.
+func listDirectoryTree(ctx context.Context, in *ListLSInput, searchPath, displayPath string, patterns []string) (*ListDirectoryOutput, error) {
+	depth := in.Depth
+	if depth == 0 {
+		depth = defaultTreeDepth
+	}
+
+	var read listTreeReader
+	if snapshot := currentGitSnapshot(ctx); in.Repository == RepositoryCode && snapshot != nil {
+		rel, relErr := snapshot.relativeFromAbs(searchPath)
+		if relErr != nil {
+			if errors.Is(relErr, ErrSnapshotEscapes) {
+				events.Emit(ctx, events.LLMEventTool, events.NewWarn("ListDirectory: path escapes git snapshot root"))
+				return &ListDirectoryOutput{
+					Title:    displayPath,
+					Output:   "Format error: path escapes the configured project root",
+					Metadata: map[string]string{"error": "format_error"},
+				}, nil
+			}
+			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("ListDirectory: snapshot rel path error: %v", relErr)))
+			return &ListDirectoryOutput{
+				Title:    displayPath,
+				Output:   "Format error: failed to resolve path within repository snapshot",
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+		if _, err := snapshot.treeFor(rel); err != nil {
+			events.Emit(ctx, events.LLMEventTool, events.NewWarn("ListDirectory: directory not found in snapshot"))
+			return &ListDirectoryOutput{
+				Title:    displayPath,
+				Output:   fmt.Sprintf("Format error: directory does not exist in the repository snapshot: %s", displayPath),
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+		read = func(sub string) ([]listTreeEntry, error) {
+			entries, err := snapshot.list(joinCommitPath(rel, sub))
+			if err != nil {
+				return nil, err
+			}
+			out := make([]listTreeEntry, 0, len(entries))
+			for _, entry := range entries {
+				if entry.IsDir() || entry.IsFile() {
+					out = append(out, listTreeEntry{name: entry.Name, dir: entry.IsDir()})
+				}
+			}
+			return out, nil
+		}
+	} else {
+		info, statErr := os.Stat(searchPath)
+		if statErr != nil {
+			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("ListDirectory: stat error: %v", statErr)))
+			return &ListDirectoryOutput{
+				Title:    displayPath,
+				Output:   fmt.Sprintf("Format error: directory does not exist or is not accessible: %s", displayPath),
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+		if !info.IsDir() {
+			events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("ListDirectory: not a directory: %s", displayPath)))
+			return &ListDirectoryOutput{
+				Title:    displayPath,
+				Output:   fmt.Sprintf("Format error: path is not a directory: %s", displayPath),
+				Metadata: map[string]string{"error": "format_error"},
+			}, nil
+		}
+		read = func(sub string) ([]listTreeEntry, error) {
+			entries, err := os.ReadDir(filepath.Join(searchPath, filepath.FromSlash(sub)))
+			if err != nil {
+				return nil, err
+			}
+			out := make([]listTreeEntry, 0, len(entries))
+			for _, entry := range entries {
+				out = append(out, listTreeEntry{name: entry.Name(), dir: entry.IsDir()})
+			}
+			return out, nil
+		}
+	}
+
+	root, count, files, limited, err := collectListTree(ctx, read, patterns, depth)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("ListDirectory: traversal error: %v", err)))
+		return &ListDirectoryOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: failed to traverse directory: %v", err),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(displayPath)
+	b.WriteByte('\n')
+	if in.Tree {
+		renderListTree(&b, root.children, "")
+	} else {
+		renderListIndented(&b, root.children, 1)
+	}
+	if limited {
+		fmt.Fprintf(&b, "(truncated after %d entries; list a subdirectory or lower the depth to see more)\n", treeEntryLimit)
+	}
+
+	events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventInfo, fmt.Sprintf("ListDirectory: done, %d entries to depth %d listed for '%s'", count, depth, displayPath), "list", displayPath))
+	return &ListDirectoryOutput{
+		Title:  displayPath,
+		Output: b.String(),
+		Metadata: map[string]string{
+			"entries_count": fmt.Sprintf("%d", count),
+			"files_count":   fmt.Sprintf("%d", files),
+			"depth":         fmt.Sprintf("%d", depth),
+			"limited":       fmt.Sprintf("%v", limited),
+		},
+	}, nil
+}
+
+// collectListTree walks breadth-first so that, when the entry limit is hit,
+// the shallow levels are complete and only the deepest ones are cut.
+func collectListTree(ctx context.Context, read listTreeReader, patterns []string, depth int) (*listTreeNode, int, int, bool, error) {
+	root := &listTreeNode{dir: true}
+	type pending struct {
+		node  *listTreeNode
+		rel   string
+		level int
+	}
+	queue := []pending{{node: root, rel: "", level: 0}}
+	count, files := 0, 0
+	for len(queue) > 0 {
+		if ctx != nil {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, 0, false, err
+			}
+		}
+		current := queue[0]
+		queue = queue[1:]
+
+		entries, err := read(current.rel)
+		if err != nil {
+			if current.node == root {
+				return nil, 0, 0, false, err
+			}
+			// Unreadable subdirectories are shown without children
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].dir != entries[j].dir {
+				return entries[i].dir
+			}
+			return entries[i].name < entries[j].name
+		})
+		for _, entry := range entries {
+			rel := joinCommitPath(current.rel, entry.name)
+			if entry.dir && matchIgnoredDir(rel, patterns) {
+				continue
+			}
+			if !entry.dir && matchIgnoredFile(rel, patterns) {
+				continue
+			}
+			if count >= treeEntryLimit {
+				return root, count, files, true, nil
+			}
+			node := &listTreeNode{name: entry.name, dir: entry.dir}
+			current.node.children = append(current.node.children, node)
+			count++
+			if !entry.dir {
+				files++
+				continue
+			}
+			if current.level+1 < depth {
+				queue = append(queue, pending{node: node, rel: rel, level: current.level + 1})
+			}
+		}
+	}
+	return root, count, files, false, nil
+}
+
+// renderListTree draws nodes with box-drawing connectors.
+func renderListTree(b *strings.Builder, nodes []*listTreeNode, prefix string) {
+	for i, node := range nodes {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+		b.WriteString(prefix)
+		b.WriteString(connector)
+		b.WriteString(listTreeName(node))
+		b.WriteByte('\n')
+		renderListTree(b, node.children, prefix+childPrefix)
+	}
+}
+
+// renderListIndented draws nodes in the indented style of the flat listing.
+func renderListIndented(b *strings.Builder, nodes []*listTreeNode, level int) {
+	for _, node := range nodes {
+		b.WriteString(strings.Repeat("  ", level))
+		b.WriteString(listTreeName(node))
+		b.WriteByte('\n')
+		renderListIndented(b, node.children, level+1)
+	}
+}
+
+func listTreeName(node *listTreeNode) string {
+	if node.dir {
+		return node.name + "/"
+	}
+	return node.name
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"narrabyte/internal/events"
+)
+
+// Operations recorded by the plan tools.
+const (
+	PlanOperationCreate = "create"
+	PlanOperationModify = "modify"
+	PlanOperationDelete = "delete"
+)
+
+// PlanChangeInput is the input of the plan variants of the write, edit and
+// delete tools used when a run only proposes changes.
+type PlanChangeInput struct {
+	// Repository must be "docs" - only documentation changes can be planned.
+	Repository Repository `json:"repository" jsonschema:"enum=docs,description=Must be 'docs' - only documentation changes can be planned"`
+	// FilePath is the relative path to the file within the docs repository.
+	FilePath string `json:"file_path" jsonschema:"description=The path to the file relative to the docs repository root (e.g. 'api/endpoints.md'). NEVER use absolute paths."`
+	// Rationale explains why the change is needed.
+	Rationale string `json:"rationale" jsonschema:"description=Why this file needs the change, in one or two sentences tied to the code changes"`
+}
+
+type PlanChangeOutput struct {
+	Title    string            `json:"title"`
+	Output   string            `json:"output"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// PlannedChange is one documentation change a plan run intends to make.
+type PlannedChange struct {
+	Path      string `json:"path"`
+	Operation string `json:"operation"`
+	Rationale string `json:"rationale"`
+}
+
+// PlanChange records the intended operation for in without touching the
+// repository. A write to a file that already exists is recorded as a
+// modification; planning the same path again replaces the earlier entry.
+func PlanChange(ctx context.Context, operation string, in *PlanChangeInput) (*PlanChangeOutput, error) {
+	if in == nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewError("PlanChange: input is required"))
+		return &PlanChangeOutput{
+			Output:   "Format error: input is required",
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	if in.Repository != RepositoryDocs {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("PlanChange: repository must be 'docs', got '%s'", in.Repository)))
+		return &PlanChangeOutput{
+			Output:   fmt.Sprintf("Format error: only changes to the 'docs' repository can be planned, got '%s'", in.Repository),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+	pathArg := strings.TrimSpace(in.FilePath)
+	if pathArg == "" {
+		events.Emit(ctx, events.LLMEventTool, events.NewError("PlanChange: file_path is required"))
+		return &PlanChangeOutput{
+			Output:   "Format error: file_path is required",
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	displayPath := FormatDisplayPath(in.Repository, pathArg)
+	absPath, err := ResolveRepositoryPath(ctx, in.Repository, pathArg)
+	if err != nil {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("PlanChange: %v", err)))
+		return &PlanChangeOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Format error: %v", err),
+			Metadata: map[string]string{"error": "format_error"},
+		}, nil
+	}
+
+	exists := false
+	if st, err := os.Stat(absPath); err == nil && !st.IsDir() {
+		exists = true
+	}
+	switch {
+	case operation == PlanOperationCreate && exists:
+		operation = PlanOperationModify
+	case operation != PlanOperationCreate && !exists && !plannedCreate(ctx, pathArg):
+		return &PlanChangeOutput{
+			Title:    displayPath,
+			Output:   fmt.Sprintf("Error: file not found: %s", displayPath),
+			Metadata: map[string]string{"error": "not_found"},
+		}, nil
+	}
+
+	recordPlannedChange(ctx, PlannedChange{
+		Path:      pathArg,
+		Operation: operation,
+		Rationale: strings.TrimSpace(in.Rationale),
+	})
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("PlanChange: %s '%s'", operation, displayPath)))
+	return &PlanChangeOutput{
+		Title:    displayPath,
+		Output:   fmt.Sprintf("Planned %s of %s. Nothing was written; continue planning.", operation, displayPath),
+		Metadata: map[string]string{"operation": operation},
+	}, nil
+}
+
+func recordPlannedChange(ctx context.Context, change PlannedChange) {
+	base := ensureSessionContext(SessionIDFromContext(ctx))
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	for i, existing := range base.plan {
+		if existing.Path != change.Path {
+			continue
+		}
+		if existing.Operation == PlanOperationCreate {
+			switch change.Operation {
+			case PlanOperationDelete:
+				// Deleting a page the plan creates cancels it out
+				base.plan = append(base.plan[:i], base.plan[i+1:]...)
+				return
+			case PlanOperationModify:
+				change.Operation = PlanOperationCreate
+			}
+		}
+		base.plan[i] = change
+		return
+	}
+	base.plan = append(base.plan, change)
+}
+
+// plannedCreate reports whether the session already plans to create path.
+func plannedCreate(ctx context.Context, path string) bool {
+	base := ensureSessionContext(SessionIDFromContext(ctx))
+	contextMu.RLock()
+	defer contextMu.RUnlock()
+	for _, change := range base.plan {
+		if change.Path == path && change.Operation == PlanOperationCreate {
+			return true
+		}
+	}
+	return false
+}
+
+// ResetPlannedChanges clears the planned changes of the session in ctx.
+func ResetPlannedChanges(ctx context.Context) {
+	base := ensureSessionContext(SessionIDFromContext(ctx))
+	contextMu.Lock()
+	base.plan = nil
+	contextMu.Unlock()
+}
+
+// PlannedChangesForSession returns the changes planned so far in ctx's session.
+func PlannedChangesForSession(ctx context.Context) []PlannedChange {
+	base := ensureSessionContext(SessionIDFromContext(ctx))
+	contextMu.RLock()
+	defer contextMu.RUnlock()
+	return append([]PlannedChange(nil), base.plan...)
+}
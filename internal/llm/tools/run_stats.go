@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RunStats accumulates what a single generation run did: tool calls by tool
+// name, documentation file changes and model token usage.
+type RunStats struct {
+	mu               sync.Mutex
+	startedAt        time.Time
+	toolCalls        map[string]int
+	fileChanges      map[string]string // net change of each documentation file, by path
+	promptTokens     int
+	completionTokens int
+	reasoningTokens  int
+	totalTokens      int
+}
+
+// RunStatsSnapshot is a point-in-time copy of RunStats.
+type RunStatsSnapshot struct {
+	ToolCalls        map[string]int
+	FilesCreated     int
+	FilesModified    int
+	FilesDeleted     int
+	PromptTokens     int
+	CompletionTokens int
+	ReasoningTokens  int
+	TotalTokens      int
+	Elapsed          time.Duration
+}
+
+func newRunStats() *RunStats {
+	return &RunStats{startedAt: time.Now(), toolCalls: make(map[string]int), fileChanges: make(map[string]string)}
+}
+
+// runStats returns the stats for the session in ctx, creating them on first use.
+func runStats(ctx context.Context) *RunStats {
+	base := ensureSessionContext(SessionIDFromContext(ctx))
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	if base.stats == nil {
+		base.stats = newRunStats()
+	}
+	return base.stats
+}
+
+// ResetRunStats starts a fresh set of run statistics for the session in ctx.
+func ResetRunStats(ctx context.Context) {
+	base := ensureSessionContext(SessionIDFromContext(ctx))
+	contextMu.Lock()
+	base.stats = newRunStats()
+	contextMu.Unlock()
+}
+
+// RecordToolCall counts one invocation of the named tool.
+func RecordToolCall(ctx context.Context, toolName string) {
+	stats := runStats(ctx)
+	stats.mu.Lock()
+	stats.toolCalls[toolName]++
+	stats.mu.Unlock()
+}
+
+// RecordFileChange records a change of the documentation file at path. op is
+// one of "created", "modified" or "deleted"; anything else is ignored. Each
+// file counts once with its net change over the run: a file created and then
+// edited stays created, one created and then deleted is not counted, and one
+// deleted and then written again counts as modified.
+func RecordFileChange(ctx context.Context, path string, op string) {
+	if op != "created" && op != "modified" && op != "deleted" {
+		return
+	}
+	stats := runStats(ctx)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	prev, seen := stats.fileChanges[path]
+	switch {
+	case !seen:
+		stats.fileChanges[path] = op
+	case prev == "created" && op == "deleted":
+		delete(stats.fileChanges, path)
+	case prev == "created":
+		// Still a new file
+	case prev == "deleted" && op != "deleted":
+		stats.fileChanges[path] = "modified"
+	default:
+		stats.fileChanges[path] = op
+	}
+}
+
+// RecordTokenUsage adds the token usage reported for one model response.
+func RecordTokenUsage(ctx context.Context, prompt, completion, total int) {
+	stats := runStats(ctx)
+	stats.mu.Lock()
+	stats.promptTokens += prompt
+	stats.completionTokens += completion
+	stats.totalTokens += total
+	stats.mu.Unlock()
+}
+
+// RecordReasoningTokens adds the reasoning tokens reported for one model
+// response and returns the run's total so far.
+func RecordReasoningTokens(ctx context.Context, tokens int) int {
+	stats := runStats(ctx)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.reasoningTokens += tokens
+	return stats.reasoningTokens
+}
+
+// RunStatsForSession returns a snapshot of the statistics gathered for the
+// session in ctx since the last ResetRunStats.
+func RunStatsForSession(ctx context.Context) RunStatsSnapshot {
+	stats := runStats(ctx)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	calls := make(map[string]int, len(stats.toolCalls))
+	for name, count := range stats.toolCalls {
+		calls[name] = count
+	}
+	files := make(map[string]int, 3)
+	for _, op := range stats.fileChanges {
+		files[op]++
+	}
+	return RunStatsSnapshot{
+		ToolCalls:        calls,
+		FilesCreated:     files["created"],
+		FilesModified:    files["modified"],
+		FilesDeleted:     files["deleted"],
+		PromptTokens:     stats.promptTokens,
+		CompletionTokens: stats.completionTokens,
+		ReasoningTokens:  stats.reasoningTokens,
+		TotalTokens:      stats.totalTokens,
+		Elapsed:          time.Since(stats.startedAt),
+	}
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"narrabyte/internal/events"
@@ -77,6 +78,16 @@ func WriteFile(ctx context.Context, in *WriteFileInput) (*WriteFileOutput, error
 	}
 
 	displayPath := FormatDisplayPath(in.Repository, pathArg)
+	if limit := maxFileSize(ctx); int64(len(in.Content)) > limit {
+		events.Emit(ctx, events.LLMEventTool, events.NewError(fmt.Sprintf("WriteFile: content of %d bytes exceeds the %d byte limit", len(in.Content), limit)))
+		return &WriteFileOutput{
+			Title:  displayPath,
+			Output: fileSizeError(int64(len(in.Content)), limit),
+			Metadata: map[string]string{
+				"error": "format_error",
+			},
+		}, nil
+	}
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("WriteFile: writing '%s'", displayPath)))
 
 	dir := filepath.Dir(absPath)
@@ -92,8 +103,29 @@ func WriteFile(ctx context.Context, in *WriteFileInput) (*WriteFileOutput, error
 	}
 
 	existed := false
+	var existingSize int64
 	if st, err := os.Stat(absPath); err == nil && !st.IsDir() {
 		existed = true
+		existingSize = st.Size()
+	}
+
+	// Rewriting a file with its current content is a no-op; skipping it keeps
+	// the file out of the run's changes
+	if existed && existingSize == int64(len(in.Content)) {
+		if current, err := os.ReadFile(absPath); err == nil && bytes.Equal(current, []byte(in.Content)) {
+			outputMsg := fmt.Sprintf("File unchanged, content is identical: %s", displayPath)
+			events.Emit(ctx, events.LLMEventTool, events.NewInfo(fmt.Sprintf("WriteFile: skipped '%s', content is unchanged", displayPath)))
+			events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventInfo, fmt.Sprintf("WriteFile: done for '%s'", displayPath), "write", displayPath))
+			return &WriteFileOutput{
+				Title:  displayPath,
+				Output: outputMsg,
+				Metadata: map[string]string{
+					"filepath": displayPath,
+					"exists":   "true",
+					"status":   "unchanged",
+				},
+			}, nil
+		}
 	}
 
 	if err := os.WriteFile(absPath, []byte(in.Content), 0o644); err != nil {
@@ -108,10 +140,13 @@ func WriteFile(ctx context.Context, in *WriteFileInput) (*WriteFileOutput, error
 	}
 
 	outputMsg := ""
+	status := ""
 	if existed {
 		outputMsg = fmt.Sprintf("Overwrote file: %s", displayPath)
+		status = "overwritten"
 	} else {
 		outputMsg = fmt.Sprintf("Created file: %s", displayPath)
+		status = "created"
 	}
 	events.Emit(ctx, events.LLMEventTool, events.NewInfo(outputMsg))
 	events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventInfo, fmt.Sprintf("WriteFile: done for '%s'", displayPath), "write", displayPath))
@@ -122,6 +157,12 @@ func WriteFile(ctx context.Context, in *WriteFileInput) (*WriteFileOutput, error
 		Metadata: map[string]string{
 			"filepath": displayPath,
 			"exists":   fmt.Sprintf("%v", existed),
+			"status":   status,
 		},
 	}, nil
 }
+
+// fileSizeError tells the agent that content of size bytes is over the limit.
+func fileSizeError(size, limit int64) string {
+	return fmt.Sprintf("Format error: the file would be %d bytes, over the %d byte limit for a single file; split the content into smaller files", size, limit)
+}
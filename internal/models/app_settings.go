@@ -2,11 +2,102 @@ package models
 
 const DefaultModelKeyValue = "openai:gpt-5.5"
 
+const DefaultEventCoalesceWindowMs = 1500
+
+// The documentation repository is cloned from a local path, so a clone only
+// runs long when that path sits on a slow or hung network mount.
+const (
+	DefaultCloneRetries        = 2
+	DefaultCloneTimeoutSeconds = 60
+)
+
+const DefaultMaxDocFileKB = 4096
+
+const DefaultCommitHistoryLimit = 20
+
+// Refinement history is compacted past either limit, keeping the latest turns.
+const (
+	DefaultHistoryCompactMessages = 80
+	DefaultHistoryCompactChars    = 200000
+	DefaultHistoryKeepTurns       = 4
+)
+
 type AppSettings struct {
 	ID              uint   `gorm:"primaryKey"` // single-row table (ID=1)
 	Version         int    `gorm:"not null;default:1"`
 	Theme           string `gorm:"not null;default:system"` // "light" | "dark" | "system"
 	Locale          string `gorm:"not null"`
 	DefaultModelKey string `gorm:"size:255;default:'openai:gpt-5.5'"`
-	UpdatedAt       string `gorm:"not null"` // ISO string format
+	// TempBaseDir is where temporary docs workspaces are created; empty means os.TempDir()
+	TempBaseDir string `gorm:"size:1024"`
+	// RetainFailedWorkspaces keeps the temp workspace of a failed run for debugging
+	RetainFailedWorkspaces bool `gorm:"not null;default:false"`
+	// EventCoalesceWindowMs merges bursts of repeated tool events shown in the UI; 0 disables it
+	EventCoalesceWindowMs int `gorm:"not null;default:1500"`
+	// VerboseEvents sends every raw event to the UI, bypassing coalescing
+	VerboseEvents bool `gorm:"not null;default:false"`
+	// DebugLogging emits debug diagnostics (conversation history sizes, git status) to the log
+	DebugLogging bool `gorm:"not null;default:false"`
+	// RecordSessionEvents stores a capped event log with each generation session
+	RecordSessionEvents bool `gorm:"not null;default:false"`
+	// PublishedDocsSearch lets the agent grep the committed docs branch alongside its in-progress edits
+	PublishedDocsSearch bool `gorm:"not null;default:false"`
+	// GenerateWithoutChanges runs generation even when the branches have no code changes,
+	// e.g. to regenerate docs from the current state; otherwise such runs fail with ERR_NO_CHANGES
+	GenerateWithoutChanges bool `gorm:"not null;default:false"`
+	// DetachedDocCommits makes CommitDocs commit in a temp worktree when the docs branch is
+	// checked out, so the user's HEAD and index are left untouched
+	DetachedDocCommits bool `gorm:"not null;default:false"`
+	// FinalSummaryTurn asks the model for an explicit summary, without tools, when a run
+	// ends without a substantive assistant message to use as its summary
+	FinalSummaryTurn bool `gorm:"not null;default:false"`
+	// StructuredSummary makes OpenAI runs end with a structured summary listing why each file
+	// changed and suggested follow-ups; other providers keep the free-text summary
+	StructuredSummary bool `gorm:"not null;default:false"`
+	// HistoryCompactMessages and HistoryCompactChars summarize older refinement turns once the
+	// conversation history exceeds either of them; 0 disables that limit
+	HistoryCompactMessages int `gorm:"not null;default:80"`
+	HistoryCompactChars    int `gorm:"not null;default:200000"`
+	// HistoryKeepTurns is how many recent user turns are kept verbatim when compacting
+	HistoryKeepTurns int `gorm:"not null;default:4"`
+	// HistoryCompactModelKey is the model that writes the summary of compacted turns, e.g. a
+	// cheaper one; empty, or a model that cannot be used, falls back to the session's model
+	HistoryCompactModelKey string `gorm:"size:255;not null;default:''"`
+	// HistoryLoadMessages and HistoryLoadChars trim a restored session history to its first
+	// message and most recent turns when it exceeds either of them; 0 disables that limit
+	HistoryLoadMessages int `gorm:"not null;default:0"`
+	HistoryLoadChars    int `gorm:"not null;default:0"`
+	// CloneRetries is how many times a failed shallow clone into a temp workspace is
+	// retried, with backoff, before falling back to a full clone
+	CloneRetries int `gorm:"not null;default:2"`
+	// CloneTimeoutSeconds bounds each clone attempt; 0 disables the timeout
+	CloneTimeoutSeconds int `gorm:"not null;default:60"`
+	// MaxDocFileKB is the largest documentation file the agent may write or edit into
+	MaxDocFileKB int `gorm:"not null;default:4096"`
+	// MaxConcurrentRuns and MaxRunsPerProvider cap how many documentation runs stream from a
+	// model at once, in total and per provider; 0 disables that cap
+	MaxConcurrentRuns  int `gorm:"not null;default:0"`
+	MaxRunsPerProvider int `gorm:"not null;default:0"`
+	// RejectExcessRuns fails runs over a cap with ERR_TOO_MANY_RUNS instead of queueing them
+	RejectExcessRuns bool `gorm:"not null;default:false"`
+	// CommitHistoryLimit caps how many commit messages between the target and source
+	// branches are shown to the model as context; 0 leaves the commit history out
+	CommitHistoryLimit int `gorm:"not null;default:20"`
+	// ExistingDocsContextChars includes the current content of the doc pages most likely
+	// related to the changed code in the generation prompt, up to this many characters;
+	// 0 leaves them out
+	ExistingDocsContextChars int `gorm:"not null;default:0"`
+	// UpdateSubmodulePointer commits the new documentation commit as the submodule pointer
+	// in the superproject after CommitDocs, when the documentation repository is a submodule
+	UpdateSubmodulePointer bool `gorm:"not null;default:false"`
+	// PreserveManualEdits regenerates into an existing docs branch instead of refusing it,
+	// merging the manual edits made on it since its last generated commit
+	PreserveManualEdits bool `gorm:"not null;default:false"`
+	// GeminiSafetyThreshold is the block threshold, e.g. "BLOCK_NONE", Gemini models apply
+	// to every harm category; empty keeps the documentation default of BLOCK_ONLY_HIGH
+	GeminiSafetyThreshold string `gorm:"size:64;not null;default:''"`
+	// GeminiSystemInstruction is sent to Gemini models as part of the system instruction
+	// of every request, e.g. a house style; empty sends none
+	GeminiSystemInstruction string `gorm:"type:text"`
+	UpdatedAt               string `gorm:"not null"` // ISO string format
 }
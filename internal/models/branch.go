@@ -7,3 +7,76 @@ type BranchInfo struct {
 	Name           string    `json:"name"`
 	LastCommitDate time.Time `json:"lastCommitDate"`
 }
+
+// DocsBranchInfo describes a documentation branch and the session that owns it.
+type DocsBranchInfo struct {
+	Name           string    `json:"name"`
+	LastCommitDate time.Time `json:"lastCommitDate"`
+	// SessionID is 0 when no session owns the branch
+	SessionID uint `json:"sessionId"`
+	// Base is the branch Ahead is counted against, empty when none resolves
+	Base  string `json:"base"`
+	Ahead int    `json:"ahead"`
+}
+
+// AheadBehind compares two revisions from their merge base: Ahead counts the
+// commits only the compared revision has, Behind those only the base has.
+type AheadBehind struct {
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+	// MergeBase is the common ancestor, empty when the histories are unrelated
+	MergeBase string `json:"mergeBase"`
+}
+
+// BlameLine attributes one line of a file to the commit that last changed it.
+type BlameLine struct {
+	// LineNumber is 1-based
+	LineNumber int       `json:"lineNumber"`
+	Hash       string    `json:"hash"`
+	Author     string    `json:"author"`
+	Email      string    `json:"email"`
+	Date       time.Time `json:"date"`
+	Text       string    `json:"text"`
+}
+
+// FileAtRef is the content of a file as of a commit.
+type FileAtRef struct {
+	Path string `json:"path"`
+	// Hash is the commit the file was read at
+	Hash   string `json:"hash"`
+	Size   int64  `json:"size"`
+	Binary bool   `json:"binary"`
+	// Content is left empty for binary files
+	Content string `json:"content"`
+}
+
+// CommitInfo describes a single commit.
+type CommitInfo struct {
+	Hash      string    `json:"hash"`
+	ShortHash string    `json:"shortHash"`
+	Message   string    `json:"message"`
+	Author    string    `json:"author"`
+	Email     string    `json:"email"`
+	Date      time.Time `json:"date"`
+	// FilesChanged counts the files changed relative to the first parent
+	FilesChanged int `json:"filesChanged"`
+}
+
+// RemoteInfo is a remote configured in a repository.
+type RemoteInfo struct {
+	Name string `json:"name"`
+	// URLs lists the remote's URLs; the first is used to fetch and push
+	URLs []string `json:"urls"`
+}
+
+// RemoteStatus is the outcome of a remote connectivity check.
+type RemoteStatus struct {
+	Remote string `json:"remote"`
+	URL    string `json:"url"`
+	// Reachable is true when the remote answered and accepted the credentials
+	Reachable bool `json:"reachable"`
+	// AuthFailed is true when the remote answered but rejected the credentials
+	AuthFailed bool `json:"authFailed"`
+	// Error describes why the remote is unreachable, empty when it is reachable
+	Error string `json:"error,omitempty"`
+}
@@ -6,6 +6,29 @@ type DocChangedFile struct {
 	Status string `json:"status"`
 }
 
+// StatusSummary is the working tree status of the files under a path prefix.
+type StatusSummary struct {
+	// Clean is set when no file under the prefix has changes
+	Clean bool             `json:"clean"`
+	Files []DocChangedFile `json:"files"`
+}
+
+// FileDiffStat counts the lines added and deleted in one file of a diff.
+// Status is "added", "modified", "deleted" or "renamed"; Path is the new path
+// of renamed files.
+type FileDiffStat struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Status    string `json:"status"`
+}
+
+// DocChangeRationale explains why a run changed a documentation file.
+type DocChangeRationale struct {
+	Path      string `json:"path"`
+	Rationale string `json:"rationale"`
+}
+
 // DocGenerationResult captures the outcome of a documentation generation run.
 type DocGenerationResult struct {
 	SessionID      uint             `json:"sessionId"`
@@ -16,8 +39,107 @@ type DocGenerationResult struct {
 	DocsInCodeRepo bool             `json:"docsInCodeRepo"`
 	Files          []DocChangedFile `json:"files"`
 	Diff           string           `json:"diff"`
-	Summary        string           `json:"summary"`
-	ChatMessages   []ChatMessage    `json:"chatMessages,omitempty"`
+	// PendingDiff layers uncommitted edits in the checked-out docs branch's
+	// worktree on top of Diff; empty when there are none
+	PendingDiff string `json:"pendingDiff,omitempty"`
+	// DiffStat summarizes Diff per file for the changed-files overview
+	DiffStat []FileDiffStat `json:"diffStat"`
+	Summary  string         `json:"summary"`
+	// SummaryFiles and FollowUps come from a structured summary; they are
+	// empty when the run ended with a free-text summary
+	SummaryFiles []DocChangeRationale `json:"summaryFiles,omitempty"`
+	FollowUps    []string             `json:"followUps,omitempty"`
+	ChatMessages []ChatMessage        `json:"chatMessages,omitempty"`
+	Paused       bool                 `json:"paused,omitempty"`
+	// FullBootstrap is set for runs that documented the whole codebase instead of a diff
+	FullBootstrap bool `json:"fullBootstrap,omitempty"`
+	// FromWorktree is set for runs that documented uncommitted working tree changes
+	FromWorktree bool `json:"fromWorktree,omitempty"`
+	// FilesystemTarget is set when the changes were written directly to the
+	// documentation directory; there is no docs branch or diff to review
+	FilesystemTarget bool `json:"filesystemTarget,omitempty"`
+	// FrontmatterWarnings lists frontmatter problems found in changed pages, keyed by file path
+	FrontmatterWarnings map[string][]string `json:"frontmatterWarnings,omitempty"`
+	// ComponentWarnings lists MDX components used in changed pages that are neither imported nor allowed
+	ComponentWarnings []MDXComponentWarning `json:"componentWarnings,omitempty"`
+	// RouteTree previews the Fumadocs navigation of the docs branch; nil for non-Fumadocs projects
+	RouteTree *RouteNode `json:"routeTree,omitempty"`
+	// PreservedEdits lists the files whose manual edits on the docs branch were carried
+	// into a regeneration run with PreserveManualEdits
+	PreservedEdits []string `json:"preservedEdits,omitempty"`
+	// MergeConflicts lists the files where those manual edits and the regenerated content
+	// could not be merged; conflicting regions are marked in the file for review
+	MergeConflicts []DocMergeConflict `json:"mergeConflicts,omitempty"`
+}
+
+// DocPlanResult is the outcome of a plan run: the documentation changes the
+// agent proposes for a branch diff. Nothing is written and no session is
+// stored, so Plan is always true to tell it apart from a real run.
+type DocPlanResult struct {
+	SessionKey   string             `json:"sessionKey"`
+	Plan         bool               `json:"plan"`
+	Branch       string             `json:"branch"`
+	TargetBranch string             `json:"targetBranch"`
+	Changes      []DocPlannedChange `json:"changes"`
+	Summary      string             `json:"summary"`
+}
+
+// DocPlannedChange is one file a plan run intends to touch. Operation is one
+// of "create", "modify" or "delete".
+type DocPlannedChange struct {
+	Path      string `json:"path"`
+	Operation string `json:"operation"`
+	Rationale string `json:"rationale"`
+}
+
+// MDXComponentWarning reports a JSX component an MDX page uses without it
+// being imported or provided by the docs site.
+type MDXComponentWarning struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Component string `json:"component"`
+	Message   string `json:"message"`
+}
+
+// DocMergeConflict reports a file where manual edits on a docs branch and a
+// regeneration changed the same content. Regions counts the conflict-marked
+// regions in the file; it is 0 when one side deleted the file or it is binary.
+type DocMergeConflict struct {
+	Path    string `json:"path"`
+	Regions int    `json:"regions,omitempty"`
+	Message string `json:"message"`
+}
+
+// RouteNode is an entry in a Fumadocs navigation tree. Type is one of
+// "root", "folder", "page", "separator" or "link". URLs are relative to the
+// docs base URL and paths to the content directory; folders have a URL only
+// when they contain an index page.
+type RouteNode struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	URL      string      `json:"url,omitempty"`
+	Path     string      `json:"path,omitempty"`
+	Children []RouteNode `json:"children,omitempty"`
+}
+
+// ContentRootCandidate is a directory that looks like a Fumadocs content
+// root. Path is relative to the probed repository and Confidence ranges from
+// 0 to 1; Reasons lists the evidence found.
+type ContentRootCandidate struct {
+	Path       string   `json:"path"`
+	Confidence float64  `json:"confidence"`
+	Reasons    []string `json:"reasons"`
+}
+
+// ContentRootDetection is the outcome of probing a repository for its
+// Fumadocs content root. Best and Confidence repeat the first candidate and
+// are empty when none was found; Ambiguous is set when the best candidates
+// are too close to pick one.
+type ContentRootDetection struct {
+	Best       string                 `json:"best"`
+	Confidence float64                `json:"confidence"`
+	Ambiguous  bool                   `json:"ambiguous"`
+	Candidates []ContentRootCandidate `json:"candidates"`
 }
 
 // ChatMessage represents a simple user/assistant exchange used by the refinement chat UI.
@@ -26,3 +148,20 @@ type ChatMessage struct {
 	Content   string `json:"content"`
 	CreatedAt string `json:"createdAt,omitempty"`
 }
+
+// DocsExport holds the documentation files a session changed, exported
+// without committing them.
+type DocsExport struct {
+	Format string `json:"format"`
+	// Paths lists every exported file, repository-relative and sorted
+	Paths []string `json:"paths"`
+	// Files maps each path to its content at the docs branch head; set for the
+	// "files" format, which leaves binary files out
+	Files map[string]string `json:"files,omitempty"`
+	// Binary lists the binary files left out of Files
+	Binary []string `json:"binary,omitempty"`
+	// Deleted lists the files the docs branch removes; they have no content
+	Deleted []string `json:"deleted,omitempty"`
+	// ArchivePath is where the "zip" format wrote the archive
+	ArchivePath string `json:"archivePath,omitempty"`
+}
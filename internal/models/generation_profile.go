@@ -0,0 +1,22 @@
+package models
+
+// GenerationProfile is a named set of run parameters a project reuses, so a
+// documentation run can be started from it instead of configuring the model,
+// template and instructions each time.
+type GenerationProfile struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	ProjectID uint   `gorm:"not null;uniqueIndex:idx_profile_project_name" json:"projectId"`
+	Name      string `gorm:"size:255;not null;uniqueIndex:idx_profile_project_name" json:"name"`
+	// ModelKey is the model runs use; empty means the project's default model
+	ModelKey string `gorm:"size:255" json:"modelKey"`
+	// TemplateID is the template sent as the documentation guidelines; zero
+	// means none
+	TemplateID uint `json:"templateId"`
+	// ExtraInstructions are sent with every run, ahead of the instructions
+	// given when the run is started
+	ExtraInstructions string `gorm:"type:text" json:"extraInstructions"`
+	// ExcludedPatterns is a comma-separated list of glob patterns whose files
+	// are left out of the code diff, on top of the lock and generated files
+	// every diff leaves out
+	ExcludedPatterns string `gorm:"type:text" json:"excludedPatterns"`
+}
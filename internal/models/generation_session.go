@@ -12,6 +12,30 @@ type GenerationSession struct {
 	DocsBranch       string `gorm:"size:255;index:idx_session_project_docs,unique"`
 	MessagesJSON     string `gorm:"type:text"`
 	ChatMessagesJSON string `gorm:"type:text"`
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	TodosJSON        string `gorm:"type:text"`
+	// BootstrapProgressJSON is the progress a paused or canceled chunked full
+	// bootstrap saved, so resuming it documents the parts it had left
+	BootstrapProgressJSON string `gorm:"type:text"`
+	EventLogJSON          string `gorm:"type:text"`
+	Paused                bool   `gorm:"default:false"`
+	Canceled              bool   `gorm:"default:false"` // set with Paused when the saved run was stopped rather than paused
+	FullBootstrap         bool   `gorm:"default:false"` // documented the whole codebase instead of a diff
+	FromWorktree          bool   `gorm:"default:false"` // documented uncommitted working tree changes instead of a branch diff
+	// LastSummary is the summary of the session's latest completed run: its
+	// last substantive assistant message rather than a trailing tool acknowledgment
+	LastSummary string `gorm:"type:text"`
+	// IdempotencyKey is the client-supplied key of the call that started the
+	// session; nil when none was given so unkeyed sessions never collide
+	IdempotencyKey *string `gorm:"size:255;uniqueIndex"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// SessionEventLogEntry is one recorded event in a generation session's event log.
+type SessionEventLogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Kind      string    `json:"kind"`
+	ToolName  string    `json:"toolName,omitempty"`
+	Message   string    `json:"message"`
 }
@@ -1,5 +1,9 @@
 package models
 
+// ProviderKindOpenAICompatible marks models served by an OpenAI-compatible
+// endpoint at a custom base URL rather than by a built-in provider.
+const ProviderKindOpenAICompatible = "openai-compatible"
+
 // LLMModel represents a single language model option exposed to the UI.
 type LLMModel struct {
 	Key             string `json:"key"`
@@ -10,6 +14,34 @@ type LLMModel struct {
 	ReasoningEffort string `json:"reasoningEffort,omitempty"`
 	Thinking        *bool  `json:"thinking,omitempty"`
 	Enabled         bool   `json:"enabled"`
+	Priority        int    `json:"priority"` // higher values are preferred within a provider
+	ProviderKind    string `json:"providerKind,omitempty"`
+	BaseURL         string `json:"baseUrl,omitempty"`
+	// ShowReasoning streams the provider's reasoning content to the UI. It is
+	// off until the user turns it on, which also enables thinking for providers
+	// that support it.
+	ShowReasoning bool `json:"showReasoning"`
+	// ReasoningTokenBudget caps the reasoning tokens of a single run; 0 means no limit.
+	ReasoningTokenBudget int `json:"reasoningTokenBudget"`
+	// ReasoningBudgetAction is "warn", "disable" (turn thinking off for the rest
+	// of the run where the provider allows it) or "abort".
+	ReasoningBudgetAction string `json:"reasoningBudgetAction"`
+	// Capabilities lists what the model supports; options it cannot honor
+	// are dropped before a run starts
+	Capabilities ModelCapabilities `json:"capabilities"`
+}
+
+// ModelCapabilities describes the features a model supports.
+type ModelCapabilities struct {
+	// Thinking is true when the model accepts an extended thinking toggle
+	Thinking bool `json:"thinking"`
+	// ReasoningEffort is true when the model accepts a reasoning effort level
+	ReasoningEffort bool `json:"reasoningEffort"`
+	// StructuredOutput is true when the model can reply in a JSON schema
+	StructuredOutput bool `json:"structuredOutput"`
+	Vision           bool `json:"vision"`
+	// MaxContextTokens is the model's context window; 0 means unknown
+	MaxContextTokens int `json:"maxContextTokens"`
 }
 
 // LLMModelGroup groups models by their provider for presentation.
@@ -4,10 +4,25 @@ import "time"
 
 // ModelSetting persists per-model enablement toggles.
 type ModelSetting struct {
-	ID        uint      `gorm:"primaryKey"`
-	Provider  string    `gorm:"size:50;not null;index:idx_model_provider"`
-	ModelKey  string    `gorm:"size:255;not null;uniqueIndex"`
-	Enabled   bool      `gorm:"not null;default:true"`
-	CreatedAt time.Time `gorm:"not null"`
-	UpdatedAt time.Time `gorm:"not null"`
+	ID       uint   `gorm:"primaryKey"`
+	Provider string `gorm:"size:50;not null;index:idx_model_provider"`
+	ModelKey string `gorm:"size:255;not null;uniqueIndex"`
+	Enabled  bool   `gorm:"not null;default:true"`
+	Priority int    `gorm:"not null;default:0"`
+	// ShowReasoning is nil until the user changes it; models hide reasoning by default.
+	ShowReasoning *bool
+	// ReasoningTokenBudget caps the reasoning tokens of a single run; 0 means no limit.
+	ReasoningTokenBudget int `gorm:"not null;default:0"`
+	// ReasoningBudgetAction is what happens once the budget is exceeded: "warn", "disable" or "abort".
+	ReasoningBudgetAction string `gorm:"size:20"`
+	// Definition fields are only set for models added at runtime; bundled models
+	// take their definition from the embedded models asset.
+	DisplayName     string `gorm:"size:255"`
+	APIName         string `gorm:"size:255"`
+	ReasoningEffort string `gorm:"size:20"`
+	Thinking        *bool
+	ProviderKind    string    `gorm:"size:50"`
+	BaseURL         string    `gorm:"size:1024"`
+	CreatedAt       time.Time `gorm:"not null"`
+	UpdatedAt       time.Time `gorm:"not null"`
 }
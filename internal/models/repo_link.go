@@ -7,6 +7,48 @@ type RepoLink struct {
 	ProjectName             string
 	DocumentationBaseBranch string
 	Index                   int `json:"index"`
+	// MaintainFumadocsMeta keeps Fumadocs meta.json page lists in sync with
+	// pages added or removed by a generation run
+	MaintainFumadocsMeta bool `gorm:"default:false"`
+	// AllowedMDXComponents is a comma-separated list of custom MDX components
+	// the docs site provides globally, on top of the Fumadocs defaults
+	AllowedMDXComponents string
+	// CodeScopePath limits the code diff to a subtree of the codebase
+	// repository, relative to its root; empty means the whole repository
+	CodeScopePath string
+	// FilesystemTarget writes generated documentation straight into
+	// DocumentationRepo, which then need not be a git repository; runs create
+	// no docs branch or commit and leave committing to the user
+	FilesystemTarget bool `gorm:"default:false"`
+	// DefaultModelKey is the model runs use when none is given; empty means
+	// every run must name one
+	DefaultModelKey string
+	// AllowedTools is a comma-separated allowlist of the documentation tools
+	// runs may use; empty means every tool. Unlisted tools, delete_file_tool
+	// included, are not registered
+	AllowedTools string
+	// PromptOmitCodeListing, PromptOmitDocsListing and PromptOmitCommitContext
+	// leave the codebase listing, the documentation listing and the source
+	// commit and commit history out of generation prompts
+	PromptOmitCodeListing   bool `gorm:"default:false"`
+	PromptOmitDocsListing   bool `gorm:"default:false"`
+	PromptOmitCommitContext bool `gorm:"default:false"`
+	// PromptMaxListingChars truncates each directory listing in the prompt to
+	// about this many characters; 0 means no limit
+	PromptMaxListingChars int `gorm:"default:0"`
+}
+
+// NarrabyteConfigInit reports what ClientService.InitNarrabyteConfig did with
+// a project's .narrabyte directory.
+type NarrabyteConfigInit struct {
+	// Path is the instructions file that was created; empty otherwise
+	Path string `json:"path"`
+	// Existed is set when the project already had an instructions file
+	Existed bool `json:"existed"`
+	// Created is set when the template was written to Path
+	Created bool `json:"created"`
+	// ReadOnly is set when the documentation repository cannot be written to
+	ReadOnly bool `json:"readOnly"`
 }
 
 type RepoLinkOrderUpdate struct {
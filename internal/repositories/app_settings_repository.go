@@ -28,12 +28,20 @@ func (r *appSettingsRepository) Get(ctx context.Context) (*models.AppSettings, e
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// Return default settings if not found
 			return &models.AppSettings{
-				ID:              1,
-				Version:         1,
-				Theme:           "system",
-				Locale:          "en",
-				DefaultModelKey: models.DefaultModelKeyValue,
-				UpdatedAt:       "", // empty string represents zero time
+				ID:                     1,
+				Version:                1,
+				Theme:                  "system",
+				Locale:                 "en",
+				DefaultModelKey:        models.DefaultModelKeyValue,
+				EventCoalesceWindowMs:  models.DefaultEventCoalesceWindowMs,
+				CloneRetries:           models.DefaultCloneRetries,
+				CloneTimeoutSeconds:    models.DefaultCloneTimeoutSeconds,
+				MaxDocFileKB:           models.DefaultMaxDocFileKB,
+				CommitHistoryLimit:     models.DefaultCommitHistoryLimit,
+				HistoryCompactMessages: models.DefaultHistoryCompactMessages,
+				HistoryCompactChars:    models.DefaultHistoryCompactChars,
+				HistoryKeepTurns:       models.DefaultHistoryKeepTurns,
+				UpdatedAt:              "", // empty string represents zero time
 			}, nil
 		}
 		return nil, err
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"narrabyte/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type GenerationProfileRepository interface {
+	Get(ctx context.Context, id uint) (*models.GenerationProfile, error)
+	ListByProject(ctx context.Context, projectID uint) ([]*models.GenerationProfile, error)
+	Create(ctx context.Context, profile *models.GenerationProfile) error
+	Update(ctx context.Context, profile *models.GenerationProfile) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type generationProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewGenerationProfileRepository(db *gorm.DB) GenerationProfileRepository {
+	return &generationProfileRepository{db: db}
+}
+
+func (r *generationProfileRepository) Get(ctx context.Context, id uint) (*models.GenerationProfile, error) {
+	var profile models.GenerationProfile
+	if err := r.db.WithContext(ctx).First(&profile, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("generation profile %d not found: %w", id, err)
+		}
+		return nil, fmt.Errorf("getting generation profile %d: %w", id, err)
+	}
+	return &profile, nil
+}
+
+func (r *generationProfileRepository) ListByProject(ctx context.Context, projectID uint) ([]*models.GenerationProfile, error) {
+	var list []*models.GenerationProfile
+	if err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Order("name").Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("listing generation profiles of project %d: %w", projectID, err)
+	}
+	return list, nil
+}
+
+func (r *generationProfileRepository) Create(ctx context.Context, profile *models.GenerationProfile) error {
+	if err := r.db.WithContext(ctx).Create(profile).Error; err != nil {
+		return fmt.Errorf("creating generation profile: %w", err)
+	}
+	return nil
+}
+
+func (r *generationProfileRepository) Update(ctx context.Context, profile *models.GenerationProfile) error {
+	if err := r.db.WithContext(ctx).Save(profile).Error; err != nil {
+		return fmt.Errorf("updating generation profile %d: %w", profile.ID, err)
+	}
+	return nil
+}
+
+func (r *generationProfileRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&models.GenerationProfile{}, id).Error; err != nil {
+		return fmt.Errorf("deleting generation profile %d: %w", id, err)
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ type GenerationSessionRepository interface {
 	ListByProject(projectID uint) ([]models.GenerationSession, error)
 	GetByID(id uint) (*models.GenerationSession, error)
 	GetByDocsBranch(projectID uint, docsBranch string) (*models.GenerationSession, error)
+	GetByIdempotencyKey(key string) (*models.GenerationSession, error)
 	Create(session *models.GenerationSession) error
 	UpdateByID(id uint, updates map[string]interface{}) error
 	DeleteByID(id uint) error
@@ -60,6 +61,18 @@ func (r *generationSessionRepository) GetByDocsBranch(projectID uint, docsBranch
 	return &sess, nil
 }
 
+func (r *generationSessionRepository) GetByIdempotencyKey(key string) (*models.GenerationSession, error) {
+	var sess models.GenerationSession
+	res := r.db.Where("idempotency_key = ?", key).Take(&sess)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, res.Error
+	}
+	return &sess, nil
+}
+
 func (r *generationSessionRepository) Create(session *models.GenerationSession) error {
 	if session.ProjectID == 0 {
 		return fmt.Errorf("projectID is required")
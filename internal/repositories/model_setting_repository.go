@@ -12,6 +12,11 @@ type ModelSettingRepository interface {
 	List() ([]models.ModelSetting, error)
 	GetByKey(modelKey string) (*models.ModelSetting, error)
 	Upsert(modelKey, provider string, enabled bool) (*models.ModelSetting, error)
+	Save(setting *models.ModelSetting) (*models.ModelSetting, error)
+	SetPriority(modelKey, provider string, priority int) (*models.ModelSetting, error)
+	SetEnabledForKeys(modelKeys []string, enabled bool) error
+	SetShowReasoning(modelKey, provider string, show bool) (*models.ModelSetting, error)
+	SetReasoningBudget(modelKey, provider string, tokens int, action string) (*models.ModelSetting, error)
 	SetProviderEnabled(provider string, enabled bool) error
 }
 
@@ -52,21 +57,111 @@ func (r *modelSettingRepository) Upsert(modelKey, provider string, enabled bool)
 	if provider == "" {
 		return nil, fmt.Errorf("provider is required")
 	}
-	record := models.ModelSetting{
-		ModelKey: modelKey,
-		Provider: provider,
-		Enabled:  enabled,
+	return r.upsert(modelKey, provider, map[string]interface{}{"enabled": enabled})
+}
+
+// upsert sets the given columns on the setting for modelKey. A missing setting
+// is inserted disabled unless columns says otherwise, so changing an option of
+// a model the user never turned on does not enable it. The insert goes through
+// a map because gorm replaces a false Enabled field with the column default.
+func (r *modelSettingRepository) upsert(modelKey, provider string, columns map[string]interface{}) (*models.ModelSetting, error) {
+	values := map[string]interface{}{
+		"model_key":  modelKey,
+		"provider":   provider,
+		"enabled":    false,
+		"created_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+	}
+	updates := map[string]interface{}{"updated_at": gorm.Expr("CURRENT_TIMESTAMP")}
+	for column, value := range columns {
+		values[column] = value
+		updates[column] = value
+	}
+	if err := r.db.Model(&models.ModelSetting{}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "model_key"}},
+		DoUpdates: clause.Assignments(updates),
+	}).Create(values).Error; err != nil {
+		return nil, err
+	}
+	return r.GetByKey(modelKey)
+}
+
+// Save inserts or updates a setting together with its model definition fields.
+func (r *modelSettingRepository) Save(setting *models.ModelSetting) (*models.ModelSetting, error) {
+	if setting == nil {
+		return nil, fmt.Errorf("setting is required")
+	}
+	if setting.ModelKey == "" {
+		return nil, fmt.Errorf("model key is required")
+	}
+	if setting.Provider == "" {
+		return nil, fmt.Errorf("provider is required")
 	}
 	if err := r.db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "model_key"}},
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"enabled":    enabled,
-			"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+			"provider":         setting.Provider,
+			"enabled":          setting.Enabled,
+			"priority":         setting.Priority,
+			"display_name":     setting.DisplayName,
+			"api_name":         setting.APIName,
+			"reasoning_effort": setting.ReasoningEffort,
+			"thinking":         setting.Thinking,
+			"provider_kind":    setting.ProviderKind,
+			"base_url":         setting.BaseURL,
+			"updated_at":       gorm.Expr("CURRENT_TIMESTAMP"),
 		}),
-	}).Create(&record).Error; err != nil {
+	}).Create(setting).Error; err != nil {
 		return nil, err
 	}
-	return &record, nil
+	return setting, nil
+}
+
+func (r *modelSettingRepository) SetPriority(modelKey, provider string, priority int) (*models.ModelSetting, error) {
+	if modelKey == "" {
+		return nil, fmt.Errorf("model key is required")
+	}
+	if provider == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	return r.upsert(modelKey, provider, map[string]interface{}{
+		"priority": priority,
+	})
+}
+
+func (r *modelSettingRepository) SetShowReasoning(modelKey, provider string, show bool) (*models.ModelSetting, error) {
+	if modelKey == "" {
+		return nil, fmt.Errorf("model key is required")
+	}
+	if provider == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	return r.upsert(modelKey, provider, map[string]interface{}{
+		"show_reasoning": show,
+	})
+}
+
+func (r *modelSettingRepository) SetReasoningBudget(modelKey, provider string, tokens int, action string) (*models.ModelSetting, error) {
+	if modelKey == "" {
+		return nil, fmt.Errorf("model key is required")
+	}
+	if provider == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	return r.upsert(modelKey, provider, map[string]interface{}{
+		"reasoning_token_budget":  tokens,
+		"reasoning_budget_action": action,
+	})
+}
+
+// SetEnabledForKeys updates the enabled flag for several models in one statement.
+func (r *modelSettingRepository) SetEnabledForKeys(modelKeys []string, enabled bool) error {
+	if len(modelKeys) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.ModelSetting{}).
+		Where("model_key IN ?", modelKeys).
+		Update("enabled", enabled).Error
 }
 
 func (r *modelSettingRepository) SetProviderEnabled(provider string, enabled bool) error {
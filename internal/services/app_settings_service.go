@@ -3,9 +3,14 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/client"
 	"narrabyte/internal/models"
 	"narrabyte/internal/repositories"
 )
@@ -14,6 +19,26 @@ type AppSettingsService interface {
 	Get() (*models.AppSettings, error)
 	Update(theme, locale string) (*models.AppSettings, error)
 	SetDefaultModel(modelKey string) (*models.AppSettings, error)
+	SetWorkspaceOptions(tempBaseDir string, retainFailedWorkspaces bool) (*models.AppSettings, error)
+	SetEventOptions(coalesceWindowMs int, verbose bool) (*models.AppSettings, error)
+	SetDebugLogging(enabled bool) (*models.AppSettings, error)
+	SetRecordSessionEvents(enabled bool) (*models.AppSettings, error)
+	SetPublishedDocsSearch(enabled bool) (*models.AppSettings, error)
+	SetGenerateWithoutChanges(enabled bool) (*models.AppSettings, error)
+	SetDetachedDocCommits(enabled bool) (*models.AppSettings, error)
+	SetFinalSummaryTurn(enabled bool) (*models.AppSettings, error)
+	SetStructuredSummary(enabled bool) (*models.AppSettings, error)
+	SetHistoryCompaction(maxMessages, maxChars, keepTurns int) (*models.AppSettings, error)
+	SetHistoryCompactModel(modelKey string) (*models.AppSettings, error)
+	SetHistoryLoadLimit(maxMessages, maxChars int) (*models.AppSettings, error)
+	SetRunLimits(maxRuns, maxPerProvider int, reject bool) (*models.AppSettings, error)
+	SetCloneOptions(retries, timeoutSeconds int) (*models.AppSettings, error)
+	SetMaxDocFileSize(kb int) (*models.AppSettings, error)
+	SetCommitHistoryLimit(limit int) (*models.AppSettings, error)
+	SetExistingDocsContext(maxChars int) (*models.AppSettings, error)
+	SetUpdateSubmodulePointer(enabled bool) (*models.AppSettings, error)
+	SetPreserveManualEdits(enabled bool) (*models.AppSettings, error)
+	SetGeminiOptions(safetyThreshold, systemInstruction string) (*models.AppSettings, error)
 	Startup(ctx context.Context)
 }
 
@@ -24,6 +49,9 @@ type appSettingsService struct {
 
 func (s *appSettingsService) Startup(ctx context.Context) {
 	s.context = ctx
+	if current, err := s.appSettings.Get(context.Background()); err == nil {
+		applyEventOptions(current)
+	}
 }
 
 func NewAppSettingsService(appSettings repositories.AppSettingsRepository) AppSettingsService {
@@ -83,3 +111,440 @@ func (s *appSettingsService) SetDefaultModel(modelKey string) (*models.AppSettin
 
 	return current, nil
 }
+
+func (s *appSettingsService) SetWorkspaceOptions(tempBaseDir string, retainFailedWorkspaces bool) (*models.AppSettings, error) {
+	tempBaseDir = strings.TrimSpace(tempBaseDir)
+	if tempBaseDir != "" {
+		if !filepath.IsAbs(tempBaseDir) {
+			return nil, errors.New("temp directory must be an absolute path")
+		}
+		info, err := os.Stat(tempBaseDir)
+		if err != nil {
+			return nil, errors.New("temp directory does not exist")
+		}
+		if !info.IsDir() {
+			return nil, errors.New("temp directory must be a directory")
+		}
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.TempBaseDir = tempBaseDir
+	current.RetainFailedWorkspaces = retainFailedWorkspaces
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+const maxEventCoalesceWindowMs = 10000
+
+func (s *appSettingsService) SetEventOptions(coalesceWindowMs int, verbose bool) (*models.AppSettings, error) {
+	if coalesceWindowMs < 0 || coalesceWindowMs > maxEventCoalesceWindowMs {
+		return nil, fmt.Errorf("coalesce window must be between 0 and %d ms", maxEventCoalesceWindowMs)
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.EventCoalesceWindowMs = coalesceWindowMs
+	current.VerboseEvents = verbose
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	applyEventOptions(current)
+	return current, nil
+}
+
+func (s *appSettingsService) SetDebugLogging(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.DebugLogging = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	applyEventOptions(current)
+	return current, nil
+}
+
+func (s *appSettingsService) SetRecordSessionEvents(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.RecordSessionEvents = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetPublishedDocsSearch(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.PublishedDocsSearch = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetGenerateWithoutChanges(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.GenerateWithoutChanges = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetDetachedDocCommits(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.DetachedDocCommits = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetFinalSummaryTurn(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.FinalSummaryTurn = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetStructuredSummary(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.StructuredSummary = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetRunLimits(maxRuns, maxPerProvider int, reject bool) (*models.AppSettings, error) {
+	if maxRuns < 0 || maxPerProvider < 0 {
+		return nil, fmt.Errorf("run limits must not be negative")
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.MaxConcurrentRuns = maxRuns
+	current.MaxRunsPerProvider = maxPerProvider
+	current.RejectExcessRuns = reject
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetHistoryCompaction(maxMessages, maxChars, keepTurns int) (*models.AppSettings, error) {
+	if maxMessages < 0 || maxChars < 0 {
+		return nil, fmt.Errorf("history compaction limits must not be negative")
+	}
+	if keepTurns < 1 {
+		return nil, fmt.Errorf("at least one recent turn must be kept when compacting history")
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.HistoryCompactMessages = maxMessages
+	current.HistoryCompactChars = maxChars
+	current.HistoryKeepTurns = keepTurns
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// SetHistoryCompactModel sets the model that summarizes compacted history; an
+// empty key summarizes with the session's own model.
+func (s *appSettingsService) SetHistoryCompactModel(modelKey string) (*models.AppSettings, error) {
+	modelKey = strings.TrimSpace(modelKey)
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.HistoryCompactModelKey = modelKey
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetHistoryLoadLimit(maxMessages, maxChars int) (*models.AppSettings, error) {
+	if maxMessages < 0 || maxChars < 0 {
+		return nil, fmt.Errorf("history load limits must not be negative")
+	}
+	if maxMessages == 1 {
+		return nil, fmt.Errorf("history load limit must keep more than the first message")
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.HistoryLoadMessages = maxMessages
+	current.HistoryLoadChars = maxChars
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+const (
+	maxCloneRetries        = 10
+	maxCloneTimeoutSeconds = 3600
+)
+
+func (s *appSettingsService) SetCloneOptions(retries, timeoutSeconds int) (*models.AppSettings, error) {
+	if retries < 0 || retries > maxCloneRetries {
+		return nil, fmt.Errorf("clone retries must be between 0 and %d", maxCloneRetries)
+	}
+	if timeoutSeconds < 0 || timeoutSeconds > maxCloneTimeoutSeconds {
+		return nil, fmt.Errorf("clone timeout must be between 0 and %d seconds", maxCloneTimeoutSeconds)
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.CloneRetries = retries
+	current.CloneTimeoutSeconds = timeoutSeconds
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+const maxDocFileKB = 100 * 1024
+
+func (s *appSettingsService) SetMaxDocFileSize(kb int) (*models.AppSettings, error) {
+	if kb < 1 || kb > maxDocFileKB {
+		return nil, fmt.Errorf("maximum file size must be between 1 and %d KB", maxDocFileKB)
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.MaxDocFileKB = kb
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+const maxCommitHistoryLimit = 200
+
+func (s *appSettingsService) SetCommitHistoryLimit(limit int) (*models.AppSettings, error) {
+	if limit < 0 || limit > maxCommitHistoryLimit {
+		return nil, fmt.Errorf("commit history limit must be between 0 and %d", maxCommitHistoryLimit)
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.CommitHistoryLimit = limit
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+const maxExistingDocsContextChars = 200000
+
+func (s *appSettingsService) SetExistingDocsContext(maxChars int) (*models.AppSettings, error) {
+	if maxChars < 0 || maxChars > maxExistingDocsContextChars {
+		return nil, fmt.Errorf("existing docs context must be between 0 and %d characters", maxExistingDocsContextChars)
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.ExistingDocsContextChars = maxChars
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetUpdateSubmodulePointer(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.UpdateSubmodulePointer = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetPreserveManualEdits(enabled bool) (*models.AppSettings, error) {
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.PreserveManualEdits = enabled
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+func (s *appSettingsService) SetGeminiOptions(safetyThreshold, systemInstruction string) (*models.AppSettings, error) {
+	safetyThreshold = strings.TrimSpace(safetyThreshold)
+	if _, err := client.GeminiSafetySettingsFor(safetyThreshold); err != nil {
+		return nil, err
+	}
+
+	// Get current settings
+	current, err := s.appSettings.Get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	current.GeminiSafetyThreshold = safetyThreshold
+	current.GeminiSystemInstruction = strings.TrimSpace(systemInstruction)
+	current.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if err := s.appSettings.Update(context.Background(), current); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// applyEventOptions configures debug events and event coalescing from the stored settings.
+func applyEventOptions(settings *models.AppSettings) {
+	events.SetDebugEnabled(settings.DebugLogging)
+	events.SetCoalesceOptions(events.CoalesceOptions{
+		Window:  time.Duration(settings.EventCoalesceWindowMs) * time.Millisecond,
+		Verbose: settings.VerboseEvents,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"narrabyte/internal/models"
 	"narrabyte/internal/utils"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -23,6 +25,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -36,6 +39,9 @@ type sessionRuntime struct {
 	providerID    string
 	providerLabel string
 	targetBranch  string
+	// warnings name the options dropped because the model does not support
+	// them; they are emitted once the run's session context exists
+	warnings []string
 }
 
 type ClientService struct {
@@ -45,11 +51,18 @@ type ClientService struct {
 	keyringService         *KeyringService
 	generationSessions     GenerationSessionService
 	modelConfigs           ModelConfigService
+	appSettings            AppSettingsService
+	templates              TemplateService
+	profiles               GenerationProfileService
+	fumadocs               *FumadocsService
 	sessionMu              sync.RWMutex
 	sessionRuntimes        map[string]*sessionRuntime // sessionKey -> runtime
 	tabBoundSessions       map[uint]bool              // sessionID -> is bound to a tab
 	docsBranchesMu         sync.Mutex
 	inProgressDocsBranches map[string]bool
+	idempotentMu           sync.Mutex
+	idempotentRuns         map[string]*idempotentRun // idempotency key -> run in flight
+	runs                   runLimiter
 }
 
 func (s *ClientService) Startup(ctx context.Context) error {
@@ -72,16 +85,21 @@ func (s *ClientService) Startup(ctx context.Context) error {
 	return nil
 }
 
-func NewClientService(repoLinks RepoLinkService, gitService *GitService, keyringService *KeyringService, genSessions GenerationSessionService, modelConfigs ModelConfigService) *ClientService {
+func NewClientService(repoLinks RepoLinkService, gitService *GitService, keyringService *KeyringService, genSessions GenerationSessionService, modelConfigs ModelConfigService, appSettings AppSettingsService, templates TemplateService, profiles GenerationProfileService, fumadocs *FumadocsService) *ClientService {
 	return &ClientService{
 		repoLinks:              repoLinks,
 		gitService:             gitService,
 		keyringService:         keyringService,
 		generationSessions:     genSessions,
 		modelConfigs:           modelConfigs,
+		appSettings:            appSettings,
+		templates:              templates,
+		profiles:               profiles,
+		fumadocs:               fumadocs,
 		sessionRuntimes:        make(map[string]*sessionRuntime),
 		tabBoundSessions:       make(map[uint]bool),
 		inProgressDocsBranches: make(map[string]bool),
+		idempotentRuns:         make(map[string]*idempotentRun),
 	}
 }
 
@@ -90,11 +108,29 @@ type docRepoConfig struct {
 	DocsPath       string
 	DocsRelative   string
 	SharedWithCode bool
+	// Filesystem is set for documentation directories written to directly,
+	// without a git repository; see RepoLink.FilesystemTarget
+	Filesystem bool
+	// WorkspaceBase is the directory temp workspaces are created under; empty means os.TempDir()
+	WorkspaceBase string
+	// CloneRetries and CloneTimeout control cloning into a temp workspace; see cloneDocRepo
+	CloneRetries int
+	CloneTimeout time.Duration
+	// SuperprojectRoot and SubmodulePath are set when the documentation repository is a
+	// submodule: the root of the repository containing it and its path there
+	SuperprojectRoot string
+	SubmodulePath    string
 }
 
 type tempDocWorkspace struct {
 	repoPath string
 	docsPath string
+	// sharedStore is true for linked worktrees that share the main repository's objects
+	sharedStore bool
+	// snapshot holds the content hash of every file copied into a filesystem
+	// workspace, keyed by its path relative to the docs root, so the sync
+	// writes back only what the run changed
+	snapshot map[string][sha256.Size]byte
 }
 
 func makeSessionKey(sessionID uint) string {
@@ -155,6 +191,24 @@ func (s *ClientService) CheckDocsBranchAvailability(projectID uint, sourceBranch
 	if docRepoPath == "" {
 		return fmt.Errorf("documentation repository is not configured")
 	}
+	if project.FilesystemTarget {
+		// No docs branch is created; only one run may own the session name
+		docsBranch := strings.TrimSpace(docsBranchOverride)
+		if docsBranch == "" {
+			docsBranch = documentationBranchName(sourceBranch)
+		}
+		existingSession, err := s.generationSessions.GetByDocsBranch(projectID, docsBranch)
+		if err != nil {
+			return fmt.Errorf("failed to check for existing session: %w", err)
+		}
+		if existingSession != nil {
+			return fmt.Errorf("ERR_SESSION_EXISTS:%s", docsBranch)
+		}
+		if s.isDocsBranchInProgress(docsBranch) {
+			return fmt.Errorf("ERR_DOCS_GENERATION_IN_PROGRESS:%s", docsBranch)
+		}
+		return nil
+	}
 
 	docRepo, err := s.gitService.Open(docRepoPath)
 	if err != nil {
@@ -181,9 +235,47 @@ func (s *ClientService) CheckDocsBranchAvailability(projectID uint, sourceBranch
 		return fmt.Errorf("ERR_SESSION_EXISTS_SUGGEST:%s:%s", docsBranch, suggested)
 	}
 
+	// With PreserveManualEdits an existing docs branch is regenerated, not a conflict
+	editedHead, err := s.editedDocsHead(docRepo, docsBranch)
+	if err != nil {
+		return err
+	}
+	if !editedHead.IsZero() {
+		return nil
+	}
 	return s.ensureDocsBranchAvailable(docRepo, docsBranch, projectID)
 }
 
+// CreateDocumentationBaseBranch creates the project's documentation base
+// branch in its documentation repository from fromRef (HEAD when empty) when
+// it does not exist yet. Generation reports a missing base branch with
+// ERR_DOCS_BASE_BRANCH_MISSING so the UI can offer this.
+func (s *ClientService) CreateDocumentationBaseBranch(projectID uint, fromRef string) error {
+	project, _, docCfg, err := s.prepareProjectRepos(projectID)
+	if err != nil {
+		return err
+	}
+	if docCfg.SharedWithCode {
+		return fmt.Errorf("documentation lives in the codebase repository and has no separate base branch")
+	}
+	if docCfg.Filesystem {
+		return fmt.Errorf("documentation is written directly to a directory and has no base branch")
+	}
+	branch := strings.TrimSpace(project.DocumentationBaseBranch)
+	if branch == "" {
+		return fmt.Errorf("documentation base branch is not configured for project '%s'", project.ProjectName)
+	}
+
+	docRepo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open documentation repository: %w", err)
+	}
+	if _, err := s.gitService.EnsureBranch(docRepo, branch, fromRef); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *ClientService) prepareProjectRepos(projectID uint) (*models.RepoLink, string, *docRepoConfig, error) {
 	project, err := s.repoLinks.Get(projectID)
 	if err != nil {
@@ -207,7 +299,7 @@ func (s *ClientService) prepareProjectRepos(projectID uint) (*models.RepoLink, s
 	if !utils.HasGitRepo(codeRepoPath) {
 		return nil, "", nil, fmt.Errorf("codebase repository is not a git repository: %s", codeRepoPath)
 	}
-	if !utils.HasGitRepo(docRepoPath) {
+	if !project.FilesystemTarget && !utils.HasGitRepo(docRepoPath) {
 		return nil, "", nil, fmt.Errorf("documentation repository is not a git repository: %s", docRepoPath)
 	}
 
@@ -220,83 +312,206 @@ func (s *ClientService) prepareProjectRepos(projectID uint) (*models.RepoLink, s
 		return nil, "", nil, fmt.Errorf("codebase repository is not a git repository: %s", codeRepoPath)
 	}
 
-	docCfg, err := newDocRepoConfig(docRepoPath, codeRepoRoot)
+	var docCfg *docRepoConfig
+	if project.FilesystemTarget {
+		docCfg, err = newFilesystemDocConfig(docRepoPath)
+	} else {
+		docCfg, err = newDocRepoConfig(docRepoPath, codeRepoRoot)
+	}
 	if err != nil {
 		return nil, "", nil, err
 	}
+	settings := s.workspaceSettings()
+	docCfg.WorkspaceBase = settings.TempBaseDir
+	docCfg.CloneRetries = settings.CloneRetries
+	docCfg.CloneTimeout = time.Duration(settings.CloneTimeoutSeconds) * time.Second
 
 	return project, codeRepoRoot, docCfg, nil
 }
 
-func (s *ClientService) instantiateLLMClient(modelKey string) (*client.LLMClient, *models.LLMModel, error) {
-	if s.context == nil {
-		return nil, nil, fmt.Errorf("client service not initialized")
+// geminiModelOptions builds the options of a Gemini client for model from
+// the Gemini safety threshold and system instruction in settings.
+func geminiModelOptions(model *models.LLMModel, settings models.AppSettings) (client.GeminiModelOptions, error) {
+	safety, err := client.GeminiSafetySettingsFor(settings.GeminiSafetyThreshold)
+	if err != nil {
+		return client.GeminiModelOptions{}, err
+	}
+	return client.GeminiModelOptions{
+		Model:             model.APIName,
+		ReasoningEffort:   model.ReasoningEffort,
+		Thinking:          model.Thinking,
+		ShowReasoning:     model.ShowReasoning,
+		SafetySettings:    safety,
+		SystemInstruction: settings.GeminiSystemInstruction,
+	}, nil
+}
+
+// instantiateLLMClient builds a client for modelKey, logging construction
+// failures to the session of ctx. A non-zero projectID lets a project-scoped
+// API key take precedence over the global one and applies the project's tool
+// allowlist. The returned warnings name the options dropped because the model
+// does not support them.
+func (s *ClientService) instantiateLLMClient(ctx context.Context, projectID uint, modelKey string) (*client.LLMClient, *models.LLMModel, []string, error) {
+	if ctx == nil {
+		return nil, nil, nil, fmt.Errorf("client service not initialized")
 	}
 	if s.keyringService == nil {
-		return nil, nil, fmt.Errorf("keyring service not configured")
+		return nil, nil, nil, fmt.Errorf("keyring service not configured")
 	}
 
 	model, err := s.modelConfigs.GetModel(modelKey)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if model == nil {
-		return nil, nil, fmt.Errorf("model %s not found", modelKey)
+		return nil, nil, nil, fmt.Errorf("model %s not found", modelKey)
 	}
 	if !model.Enabled {
-		return nil, nil, fmt.Errorf("model %s is disabled", model.DisplayName)
+		return nil, nil, nil, fmt.Errorf("model %s is disabled", model.DisplayName)
 	}
 
 	providerID := strings.TrimSpace(model.ProviderID)
 	if providerID == "" {
-		return nil, nil, fmt.Errorf("model %s is missing provider information", model.DisplayName)
+		return nil, nil, nil, fmt.Errorf("model %s is missing provider information", model.DisplayName)
 	}
 
-	apiKey, err := s.keyringService.GetApiKey(providerID)
+	apiKey, err := s.keyringService.ResolveApiKey(providerID, projectID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get API key for %s: %w", providerID, err)
+		return nil, nil, nil, fmt.Errorf("failed to get API key for %s: %w", providerID, err)
 	}
 	if apiKey == "" {
-		return nil, nil, fmt.Errorf("API key for %s is not configured", providerID)
+		return nil, nil, nil, fmt.Errorf("API key for %s is not configured", providerID)
 	}
 
+	settings := s.workspaceSettings()
+	warnings := DropUnsupportedOptions(model, &settings)
 	var (
 		llmClient *client.LLMClient
 		createErr error
 	)
-	switch providerID {
-	case "anthropic":
-		llmClient, createErr = client.NewClaudeClient(s.context, apiKey, client.ClaudeModelOptions{
-			Model:           model.APIName,
-			ReasoningEffort: model.ReasoningEffort,
+	switch {
+	case model.ProviderKind == models.ProviderKindOpenAICompatible:
+		llmClient, createErr = client.NewOpenAICompatibleClient(ctx, apiKey, client.OpenAICompatibleModelOptions{
+			Model:         model.APIName,
+			BaseURL:       model.BaseURL,
+			ShowReasoning: model.ShowReasoning,
 		})
-	case "openai":
-		llmClient, createErr = client.NewOpenAIClient(s.context, apiKey, client.OpenAIModelOptions{
+	case providerID == "anthropic":
+		llmClient, createErr = client.NewClaudeClient(ctx, apiKey, client.ClaudeModelOptions{
 			Model:           model.APIName,
 			ReasoningEffort: model.ReasoningEffort,
+			Thinking:        model.Thinking,
+			ShowReasoning:   model.ShowReasoning,
 		})
-	case "gemini":
-		llmClient, createErr = client.NewGeminiClient(s.context, apiKey, client.GeminiModelOptions{
-			Model:           model.APIName,
-			ReasoningEffort: model.ReasoningEffort,
+	case providerID == "openai":
+		llmClient, createErr = client.NewOpenAIClient(ctx, apiKey, client.OpenAIModelOptions{
+			Model:             model.APIName,
+			ReasoningEffort:   model.ReasoningEffort,
+			ShowReasoning:     model.ShowReasoning,
+			StructuredSummary: settings.StructuredSummary,
 		})
+	case providerID == "gemini":
+		opts, err := geminiModelOptions(model, settings)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		llmClient, createErr = client.NewGeminiClient(ctx, apiKey, opts)
 	default:
-		return nil, nil, fmt.Errorf("unsupported provider: %s", providerID)
+		return nil, nil, nil, fmt.Errorf("unsupported provider: %s", providerID)
 	}
 
 	if createErr != nil {
-		return nil, nil, fmt.Errorf("failed to create %s client: %w", providerID, createErr)
+		return nil, nil, nil, fmt.Errorf("failed to create %s client: %w", providerID, createErr)
+	}
+	llmClient.SetPublishedDocsSearch(settings.PublishedDocsSearch)
+	llmClient.SetMaxFileSize(int64(settings.MaxDocFileKB) * 1024)
+	llmClient.SetFinalSummaryTurn(settings.FinalSummaryTurn)
+	llmClient.SetExistingDocsContext(settings.ExistingDocsContextChars)
+	llmClient.SetReasoningBudget(model.ReasoningTokenBudget, model.ReasoningBudgetAction)
+	compaction := client.HistoryCompaction{
+		MaxMessages: settings.HistoryCompactMessages,
+		MaxChars:    settings.HistoryCompactChars,
+		KeepTurns:   settings.HistoryKeepTurns,
+	}
+	if compactKey := strings.TrimSpace(settings.HistoryCompactModelKey); compactKey != "" && compactKey != model.Key {
+		summarizer, _, _, err := s.instantiateLLMClient(ctx, projectID, compactKey)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("history compaction model %s is unavailable, summarizing with %s: %v", compactKey, model.DisplayName, err))
+		} else {
+			compaction.Summarizer = summarizer
+		}
+	}
+	llmClient.SetHistoryCompaction(compaction)
+	if projectID != 0 && s.repoLinks != nil {
+		project, err := s.repoLinks.Get(projectID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get project: %w", err)
+		}
+		if project != nil {
+			llmClient.SetAllowedTools(ParseToolAllowlist(project.AllowedTools))
+			llmClient.SetPromptSections(client.PromptSections{
+				OmitCodeListing:   project.PromptOmitCodeListing,
+				OmitDocsListing:   project.PromptOmitDocsListing,
+				OmitCommitContext: project.PromptOmitCommitContext,
+				MaxListingChars:   project.PromptMaxListingChars,
+			})
+		}
+	}
+
+	return llmClient, model, warnings, nil
+}
+
+const modelValidationTimeout = 30 * time.Second
+
+// ValidateModel instantiates the client for a model and issues a minimal
+// request to confirm the API key and model name are accepted by the provider.
+// Failures the provider explains, such as a rejected key, are returned as a
+// client.ProviderError; anything else is reported as
+// ERR_MODEL_VALIDATION_FAILED:<modelKey>:<reason> so the UI can surface them
+// before a generation run is started.
+func (s *ClientService) ValidateModel(modelKey string) error {
+	modelKey = strings.TrimSpace(modelKey)
+	if modelKey == "" {
+		return fmt.Errorf("model is required")
+	}
+	llmClient, modelInfo, warnings, err := s.instantiateLLMClient(s.context, 0, modelKey)
+	if err != nil {
+		return fmt.Errorf("ERR_MODEL_VALIDATION_FAILED:%s:%v", modelKey, err)
+	}
+	for _, warning := range warnings {
+		events.Emit(s.context, events.LLMEventTool, events.NewWarn(warning))
+	}
+
+	ctx, cancel := context.WithTimeout(s.context, modelValidationTimeout)
+	defer cancel()
+	if err := llmClient.Ping(ctx); err != nil {
+		var providerErr *client.ProviderError
+		if errors.As(classifyModelError(modelInfo, err), &providerErr) {
+			return providerErr
+		}
+		return fmt.Errorf("ERR_MODEL_VALIDATION_FAILED:%s:%v", modelKey, err)
 	}
+	return nil
+}
 
-	return llmClient, model, nil
+// classifyModelError turns a provider failure from a call to modelInfo's
+// provider into a client.ProviderError when it is one the UI can explain.
+func classifyModelError(modelInfo *models.LLMModel, err error) error {
+	if modelInfo == nil {
+		return err
+	}
+	return client.ClassifyProviderError(modelInfo.ProviderID, modelInfo.ProviderName, err)
 }
 
-func (s *ClientService) newSessionRuntime(modelKey string) (*sessionRuntime, *models.LLMModel, error) {
+// newSessionRuntime builds the runtime of a session on modelKey. ctx carries
+// the session when it is already known, so construction failures are logged
+// to it; the runtime's warnings are left for emitRuntimeWarnings.
+func (s *ClientService) newSessionRuntime(ctx context.Context, projectID uint, modelKey string) (*sessionRuntime, *models.LLMModel, error) {
 	modelKey = strings.TrimSpace(modelKey)
 	if modelKey == "" {
 		return nil, nil, fmt.Errorf("model is required")
 	}
-	llmClient, modelInfo, err := s.instantiateLLMClient(modelKey)
+	llmClient, modelInfo, warnings, err := s.instantiateLLMClient(ctx, projectID, modelKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -311,10 +526,19 @@ func (s *ClientService) newSessionRuntime(modelKey string) (*sessionRuntime, *mo
 		modelDisplay:  modelInfo.DisplayName,
 		providerID:    providerID,
 		providerLabel: providerLabel,
+		warnings:      warnings,
 	}
 	return runtime, modelInfo, nil
 }
 
+// emitRuntimeWarnings reports the warnings of a new runtime to its session.
+func emitRuntimeWarnings(ctx context.Context, sessionKey string, runtime *sessionRuntime) {
+	for _, warning := range runtime.warnings {
+		emitSessionWarn(ctx, sessionKey, warning)
+	}
+	runtime.warnings = nil
+}
+
 func (s *ClientService) getSessionRuntime(sessionKey string) (*sessionRuntime, bool) {
 	s.sessionMu.RLock()
 	defer s.sessionMu.RUnlock()
@@ -361,6 +585,120 @@ func (s *ClientService) isDocsBranchInProgress(docsBranch string) bool {
 	return s.inProgressDocsBranches[docsBranch]
 }
 
+// idempotencyWindow is how long an idempotency key keeps returning the
+// session it started rather than letting a new run begin.
+const idempotencyWindow = 10 * time.Minute
+
+// idempotentRun is a keyed generation in flight; done is closed once result
+// and err are set.
+type idempotentRun struct {
+	done   chan struct{}
+	result *models.DocGenerationResult
+	err    error
+}
+
+// runIdempotent calls generate at most once per idempotency key so a retried
+// request cannot start a duplicate session. A call arriving while the first
+// is still running waits for it and shares its outcome; a later call within
+// idempotencyWindow gets the stored session. A failed run releases its key,
+// so retrying it starts a fresh run. An empty key always generates.
+func (s *ClientService) runIdempotent(key string, generate func(key string) (*models.DocGenerationResult, error)) (*models.DocGenerationResult, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return generate("")
+	}
+
+	s.idempotentMu.Lock()
+	if run, ok := s.idempotentRuns[key]; ok {
+		s.idempotentMu.Unlock()
+		<-run.done
+		return run.result, run.err
+	}
+	run := &idempotentRun{done: make(chan struct{})}
+	s.idempotentRuns[key] = run
+	s.idempotentMu.Unlock()
+
+	defer func() {
+		s.idempotentMu.Lock()
+		delete(s.idempotentRuns, key)
+		s.idempotentMu.Unlock()
+		close(run.done)
+	}()
+
+	run.result, run.err = s.replayIdempotentRun(key)
+	if run.err == nil && run.result == nil {
+		run.result, run.err = generate(key)
+		if run.err != nil {
+			s.releaseIdempotencyKey(key)
+		}
+	}
+	return run.result, run.err
+}
+
+// releaseIdempotencyKey detaches key from the session a failed run left
+// behind, so the key no longer replays it.
+func (s *ClientService) releaseIdempotencyKey(key string) {
+	session, err := s.generationSessions.GetByIdempotencyKey(key)
+	if err != nil || session == nil {
+		return
+	}
+	_ = s.generationSessions.UpdateByID(session.ID, map[string]interface{}{
+		"idempotency_key": nil,
+	})
+}
+
+// replayIdempotentRun returns the result of the session stored under key when
+// it was created within idempotencyWindow, and nil when a new run should
+// start. An expired key is released so the new session can take it over.
+func (s *ClientService) replayIdempotentRun(key string) (*models.DocGenerationResult, error) {
+	session, err := s.generationSessions.GetByIdempotencyKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	if session == nil {
+		return nil, nil
+	}
+	if time.Since(session.CreatedAt) > idempotencyWindow {
+		if err := s.generationSessions.UpdateByID(session.ID, map[string]interface{}{
+			"idempotency_key": nil,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to release idempotency key: %w", err)
+		}
+		return nil, nil
+	}
+	if s.isDocsBranchInProgress(session.DocsBranch) {
+		// Still generating elsewhere; report the session so the caller can follow it
+		return &models.DocGenerationResult{
+			SessionID:     session.ID,
+			Branch:        session.SourceBranch,
+			TargetBranch:  session.TargetBranch,
+			DocsBranch:    session.DocsBranch,
+			FullBootstrap: session.FullBootstrap,
+			FromWorktree:  session.FromWorktree,
+		}, nil
+	}
+	return s.LoadGenerationSession(session.ID)
+}
+
+// createIdempotentSession creates session under idempotencyKey. If another
+// run stored the key first, the unique constraint rejects the row and that
+// run's result is returned instead of a new session.
+func (s *ClientService) createIdempotentSession(session *models.GenerationSession, idempotencyKey string) (*models.GenerationSession, *models.DocGenerationResult, error) {
+	if idempotencyKey != "" {
+		session.IdempotencyKey = &idempotencyKey
+	}
+	created, err := s.generationSessions.Create(session)
+	if err == nil {
+		return created, nil, nil
+	}
+	if idempotencyKey != "" {
+		if replay, replayErr := s.replayIdempotentRun(idempotencyKey); replayErr == nil && replay != nil {
+			return nil, replay, nil
+		}
+	}
+	return nil, nil, err
+}
+
 // suggestAlternativeDocsBranch generates an alternative branch name by appending a numeric suffix.
 // It checks existing branches, in-progress generations, and existing sessions to find an available name.
 func (s *ClientService) suggestAlternativeDocsBranch(repo *git.Repository, baseName string, projectID uint) (string, error) {
@@ -415,7 +753,7 @@ func (s *ClientService) ensureRuntimeFromSession(ctx context.Context, session *m
 		return nil, fmt.Errorf("session has no model key configured")
 	}
 
-	runtime, modelInfo, err := s.newSessionRuntime(modelKey)
+	runtime, modelInfo, err := s.newSessionRuntime(events.WithSession(ctx, sessionKey), session.ProjectID, modelKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize LLM client from session: %w", err)
 	}
@@ -424,21 +762,121 @@ func (s *ClientService) ensureRuntimeFromSession(ctx context.Context, session *m
 		runtime.targetBranch = strings.TrimSpace(session.SourceBranch)
 	}
 	s.setSessionRuntime(sessionKey, runtime)
+	emitRuntimeWarnings(ctx, sessionKey, runtime)
 
 	if modelInfo != nil {
 		emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Initialized %s via %s from session", modelInfo.DisplayName, runtime.providerLabel))
 	}
 
 	if session.MessagesJSON != "" {
-		if loadErr := runtime.client.LoadConversationHistoryJSON(session.MessagesJSON); loadErr != nil {
+		if dropped, loadErr := runtime.client.LoadConversationHistoryJSON(session.MessagesJSON, s.historyLoadLimit()); loadErr != nil {
 			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to restore conversation history: %v", loadErr))
 		} else {
+			if dropped > 0 {
+				emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Conversation history exceeded the load limit; dropped %d earlier messages", dropped))
+			}
 			emitSessionInfo(ctx, sessionKey, "Restored LLM conversation history")
 		}
 	}
 	return runtime, nil
 }
 
+// SwitchSessionModel moves an existing session onto a different model. The
+// conversation history of the current runtime (or the persisted history when
+// no runtime is loaded) is transferred to a freshly instantiated client, the
+// session row is updated, and the new runtime replaces the old one.
+func (s *ClientService) SwitchSessionModel(sessionID uint, newModelKey string, sessionKeyOverride string) error {
+	ctx := s.context
+	if ctx == nil {
+		return fmt.Errorf("client service not initialized")
+	}
+	if sessionID == 0 {
+		return fmt.Errorf("session id is required")
+	}
+	newModelKey = strings.TrimSpace(newModelKey)
+	if newModelKey == "" {
+		return fmt.Errorf("model is required")
+	}
+
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	if session == nil {
+		return fmt.Errorf("session not found: %d", sessionID)
+	}
+
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+	if docsBranch != "" && s.isDocsBranchInProgress(docsBranch) {
+		return fmt.Errorf("ERR_DOCS_GENERATION_IN_PROGRESS:%s", docsBranch)
+	}
+
+	sessionKey := resolveSessionKey(sessionKeyOverride, sessionID)
+	historyJSON := session.MessagesJSON
+	targetBranch := strings.TrimSpace(session.TargetBranch)
+	if targetBranch == "" {
+		targetBranch = strings.TrimSpace(session.SourceBranch)
+	}
+	if existing, ok := s.getSessionRuntime(sessionKey); ok && existing != nil && existing.client != nil {
+		if existing.client.IsRunning() {
+			return fmt.Errorf("ERR_DOCS_GENERATION_IN_PROGRESS:%s", docsBranch)
+		}
+		if existing.modelKey == newModelKey {
+			return nil
+		}
+		if existing.client.HasConversationHistory() {
+			if current, jsonErr := existing.client.ConversationHistoryJSON(); jsonErr == nil {
+				historyJSON = current
+			} else {
+				emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to serialize conversation history: %v", jsonErr))
+			}
+		}
+		if strings.TrimSpace(existing.targetBranch) != "" {
+			targetBranch = existing.targetBranch
+		}
+	}
+
+	runtime, modelInfo, err := s.newSessionRuntime(events.WithSession(ctx, sessionKey), session.ProjectID, newModelKey)
+	if err != nil {
+		return fmt.Errorf("failed to initialize LLM client: %w", err)
+	}
+	runtime.targetBranch = targetBranch
+	emitRuntimeWarnings(ctx, sessionKey, runtime)
+
+	if strings.TrimSpace(historyJSON) != "" {
+		dropped, loadErr := runtime.client.LoadConversationHistoryJSON(historyJSON, s.historyLoadLimit())
+		if loadErr != nil {
+			// Providers disagree on which roles may open a conversation; repair
+			// the ordering rather than losing the transcript.
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Conversation history rejected by %s, repairing message order: %v", runtime.providerLabel, loadErr))
+			if restoreErr := runtime.client.RestoreConversationHistoryJSON(historyJSON, ""); restoreErr != nil {
+				runtime.client.StopStream()
+				return fmt.Errorf("failed to transfer conversation history: %w", restoreErr)
+			}
+		} else if dropped > 0 {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Conversation history exceeded the load limit; dropped %d earlier messages", dropped))
+		}
+	}
+
+	updates := map[string]interface{}{
+		"model_key": runtime.modelKey,
+		"provider":  runtime.providerID,
+	}
+	if runtime.client.HasConversationHistory() {
+		if transferred, jsonErr := runtime.client.ConversationHistoryJSON(); jsonErr == nil {
+			updates["messages_json"] = transferred
+		}
+	}
+	if err := s.generationSessions.UpdateByID(sessionID, updates); err != nil {
+		runtime.client.StopStream()
+		return fmt.Errorf("failed to update session model: %w", err)
+	}
+
+	s.setSessionRuntime(sessionKey, runtime)
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Switched session to %s via %s", modelInfo.DisplayName, runtime.providerLabel))
+	return nil
+}
+
 func emitSessionInfo(ctx context.Context, sessionKey string, message string) {
 	evt := events.NewInfo(message)
 	evt.SessionKey = sessionKey
@@ -458,16 +896,134 @@ func emitSessionError(ctx context.Context, sessionKey string, message string) {
 }
 
 func emitSessionDebug(ctx context.Context, sessionKey string, message string) {
-	evt := events.NewInfo(message)
+	evt := events.NewDebug(message)
 	evt.SessionKey = sessionKey
 	events.Emit(ctx, events.LLMEventTool, evt)
 }
 
-func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, targetBranch string, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string) (*models.DocGenerationResult, error) {
+// GenerateDocsWithTemplate starts a documentation run using a stored template
+// as the documentation guidelines. Templates are shared by all projects, so
+// the project and template are each checked for existence but not against
+// one another. userInstructions are optional and sent alongside the template.
+func (s *ClientService) GenerateDocsWithTemplate(projectID uint, sourceBranch string, targetBranch string, modelKey string, templateID uint, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string) (*models.DocGenerationResult, error) {
+	if projectID == 0 {
+		return nil, fmt.Errorf("project id is required")
+	}
+	if templateID == 0 {
+		return nil, fmt.Errorf("template id is required")
+	}
+	if s.templates == nil {
+		return nil, fmt.Errorf("template service not initialized")
+	}
+	project, err := s.repoLinks.Get(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project %d not found", projectID)
+	}
+	tmpl, err := s.templates.GetTemplate(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+	if tmpl == nil {
+		return nil, fmt.Errorf("template %d not found", templateID)
+	}
+	if strings.TrimSpace(tmpl.Content) == "" {
+		return nil, fmt.Errorf("template %q has no content", tmpl.Name)
+	}
+	return s.GenerateDocs(projectID, sourceBranch, targetBranch, modelKey, templateInstructions(tmpl.Content, userInstructions), docsBranchOverride, sessionKeyOverride, idempotencyKey)
+}
+
+// templateInstructions combines template content and user instructions into
+// the tagged form the prompt builder expects, matching the frontend payload.
+func templateInstructions(template string, userInstructions string) string {
+	var sections []string
+	if template = strings.TrimSpace(template); template != "" {
+		sections = append(sections, "<DOCUMENTATION_TEMPLATE>"+template+"</DOCUMENTATION_TEMPLATE>")
+	}
+	if user := strings.TrimSpace(userInstructions); user != "" {
+		sections = append(sections, "<USER_INSTRUCTIONS>"+user+"</USER_INSTRUCTIONS>")
+	}
+	return strings.Join(sections, "")
+}
+
+// GenerateDocs documents the changes between targetBranch and sourceBranch.
+// idempotencyKey is optional; see runIdempotent for how repeated keys behave.
+func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, targetBranch string, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string) (*models.DocGenerationResult, error) {
+	return s.runIdempotent(idempotencyKey, func(key string) (*models.DocGenerationResult, error) {
+		return s.generateDocs(projectID, sourceBranch, targetBranch, modelKey, userInstructions, docsBranchOverride, sessionKeyOverride, key, nil, docsFromDiff)
+	})
+}
+
+// GenerateDocsFull bootstraps documentation from the whole codebase at branch
+// rather than from a diff, for projects that have no docs yet. The agent gets
+// every code file in scope, listed in groups it works through one at a time,
+// and is asked for comprehensive docs. The session is marked FullBootstrap.
+func (s *ClientService) GenerateDocsFull(projectID uint, branch string, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string) (*models.DocGenerationResult, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return nil, fmt.Errorf("branch is required")
+	}
+	return s.runIdempotent(idempotencyKey, func(key string) (*models.DocGenerationResult, error) {
+		return s.generateDocs(projectID, branch, branch, modelKey, userInstructions, docsBranchOverride, sessionKeyOverride, key, nil, docsFullBootstrap)
+	})
+}
+
+// GenerateDocsFromWorktree documents the uncommitted changes, staged or not,
+// in the working tree of the code repository: the diff runs from the head of
+// the checked-out branch to the files on disk, untracked files included. The
+// docs branch is based on that branch as for GenerateDocs, and the session is
+// marked FromWorktree. A clean working tree fails with ERR_NO_CHANGES.
+func (s *ClientService) GenerateDocsFromWorktree(projectID uint, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string) (*models.DocGenerationResult, error) {
+	if projectID == 0 {
+		return nil, fmt.Errorf("project id is required")
+	}
+	_, codeRoot, _, err := s.prepareProjectRepos(projectID)
+	if err != nil {
+		return nil, err
+	}
+	codeRepo, err := s.gitService.Open(codeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open code repository: %w", err)
+	}
+	head, err := codeRepo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD of the code repository: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return nil, fmt.Errorf("the code repository has no branch checked out; check out a branch to document its uncommitted changes")
+	}
+	branch := head.Name().Short()
+	return s.runIdempotent(idempotencyKey, func(key string) (*models.DocGenerationResult, error) {
+		return s.generateDocs(projectID, branch, branch, modelKey, userInstructions, docsBranchOverride, sessionKeyOverride, key, nil, docsFromWorktree)
+	})
+}
+
+// docsRunMode selects what generateDocs documents.
+type docsRunMode int
+
+const (
+	// docsFromDiff documents the changes between the target and source branches
+	docsFromDiff docsRunMode = iota
+	// docsFullBootstrap documents the whole codebase at the source branch
+	docsFullBootstrap
+	// docsFromWorktree documents the uncommitted changes in the code
+	// repository's working tree on top of the source branch
+	docsFromWorktree
+)
+
+// fullBootstrapLargeFileCount is the number of code files above which a full
+// bootstrap warns that it is documented in several runs.
+const fullBootstrapLargeFileCount = 500
+
+func (s *ClientService) generateDocs(projectID uint, sourceBranch string, targetBranch string, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string, excluded []string, mode docsRunMode) (result *models.DocGenerationResult, err error) {
 	ctx := s.context
 	if ctx == nil {
 		return nil, fmt.Errorf("client service not initialized")
 	}
+	full := mode == docsFullBootstrap
+	worktree := mode == docsFromWorktree
 	sourceBranch = strings.TrimSpace(sourceBranch)
 	targetBranch = strings.TrimSpace(targetBranch)
 	modelKey = strings.TrimSpace(modelKey)
@@ -478,11 +1034,12 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 	if sourceBranch == "" || targetBranch == "" {
 		return nil, fmt.Errorf("source and target branches are required")
 	}
-	if sourceBranch == targetBranch {
+	if sourceBranch == targetBranch && mode == docsFromDiff {
 		return nil, fmt.Errorf("source and target branches must differ")
 	}
-	if modelKey == "" {
-		return nil, fmt.Errorf("model is required")
+	modelKey, err = s.resolveRunModelKey(projectID, modelKey)
+	if err != nil {
+		return nil, err
 	}
 
 	// Determine docs branch name early
@@ -500,7 +1057,7 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 		return nil, fmt.Errorf("ERR_SESSION_EXISTS:a session with docsBranch '%s' already exists (ID: %d)", docsBranch, existingSession.ID)
 	}
 
-	runtime, modelInfo, err := s.newSessionRuntime(modelKey)
+	runtime, modelInfo, err := s.newSessionRuntime(events.WithSession(ctx, strings.TrimSpace(sessionKeyOverride)), projectID, modelKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize LLM client: %w", err)
 	}
@@ -510,35 +1067,50 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 
 	// Create session record to get ID
 	session := &models.GenerationSession{
-		ProjectID:    projectID,
-		SourceBranch: sourceBranch,
-		TargetBranch: targetBranch,
-		Provider:     providerID,
-		ModelKey:     runtime.modelKey,
-		DocsBranch:   docsBranch,
-	}
-	session, err = s.generationSessions.Create(session)
+		ProjectID:     projectID,
+		SourceBranch:  sourceBranch,
+		TargetBranch:  targetBranch,
+		Provider:      providerID,
+		ModelKey:      runtime.modelKey,
+		DocsBranch:    docsBranch,
+		FullBootstrap: full,
+		FromWorktree:  worktree,
+	}
+	session, replay, err := s.createIdempotentSession(session, idempotencyKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	if replay != nil {
+		return replay, nil
+	}
 
 	sessionKey := resolveSessionKey(sessionKeyOverride, session.ID)
 	s.setSessionRuntime(sessionKey, runtime)
+	defer func() { s.dropRejectedSession(sessionKey, session.ID, err) }()
+	ctx, flushEventLog := s.startSessionEvents(ctx, sessionKey, session.ID)
+	defer flushEventLog()
+	emitRuntimeWarnings(ctx, sessionKey, runtime)
 
 	project, codeRoot, docCfg, err := s.prepareProjectRepos(projectID)
 	if err != nil {
 		return nil, err
 	}
 
+	runScope := fmt.Sprintf("%s -> %s", targetBranch, sourceBranch)
+	if full {
+		runScope = fmt.Sprintf("whole codebase at %s", sourceBranch)
+	} else if worktree {
+		runScope = fmt.Sprintf("uncommitted changes on %s", sourceBranch)
+	}
 	if strings.TrimSpace(docsBranchOverride) != "" {
 		emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
-			"GenerateDocs: starting for project %s (%s -> %s) using %s via %s into %s",
-			project.ProjectName, targetBranch, sourceBranch, runtime.modelDisplay, runtime.providerLabel, docsBranchOverride,
+			"GenerateDocs: starting for project %s (%s) using %s via %s into %s",
+			project.ProjectName, runScope, runtime.modelDisplay, runtime.providerLabel, docsBranchOverride,
 		))
 	} else {
 		emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
-			"GenerateDocs: starting for project %s (%s -> %s) using %s via %s",
-			project.ProjectName, targetBranch, sourceBranch, runtime.modelDisplay, runtime.providerLabel,
+			"GenerateDocs: starting for project %s (%s) using %s via %s",
+			project.ProjectName, runScope, runtime.modelDisplay, runtime.providerLabel,
 		))
 	}
 
@@ -547,61 +1119,180 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 		return nil, fmt.Errorf("failed to open code repository: %w", err)
 	}
 
-	targetHash, err := resolveBranchHash(codeRepo, targetBranch)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve target branch '%s': %w", targetBranch, err)
-	}
-	sourceHash, err := resolveBranchHash(codeRepo, sourceBranch)
+	sourceRef, err := s.gitService.ResolveRef(codeRepo, sourceBranch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve source branch '%s': %w", sourceBranch, err)
 	}
-
-	diffText, err := s.gitService.DiffBetweenCommits(codeRepo, targetHash.String(), sourceHash.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute branch diff: %w", err)
-	}
-	changedFiles := extractPathsFromDiff(diffText)
-	if len(changedFiles) == 0 {
-		emitSessionInfo(ctx, sessionKey, "GenerateDocs: no code changes detected between branches")
-	}
-
-	docRepo, err := s.gitService.Open(docCfg.RepoRoot)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open documentation repository: %w", err)
-	}
-	docWorktree, err := docRepo.Worktree()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load documentation worktree: %w", err)
-	}
-
-	status, err := docWorktree.Status()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read documentation repo status: %w", err)
-	}
-	if hasDocsChanges(status, docCfg.DocsRelative) {
-		emitSessionWarn(ctx, sessionKey, "Documentation repository has uncommitted changes - these will be preserved")
-	}
+	sourceHash := sourceRef.Hash
+	commitSummary := s.describeSourceCommit(ctx, sessionKey, codeRepo, sourceHash)
 
 	var (
-		baseHash   plumbing.Hash
-		baseBranch string
+		diffText      string
+		changedFiles  []client.ChangedFile
+		commitHistory []client.CommitMessage
 	)
-	if docCfg.SharedWithCode {
-		baseHash = sourceHash
-		baseBranch = sourceBranch
-	} else {
-		baseHash, baseBranch, err = resolveDocumentationBase(project, docRepo)
+	if full {
+		docsInCode := ""
+		if docCfg.SharedWithCode {
+			docsInCode = docCfg.DocsRelative
+		}
+		changedFiles, err = codebaseFiles(ctx, sessionKey, project, codeRoot, codeRepo, sourceHash, docsInCode)
 		if err != nil {
 			return nil, err
 		}
-	}
-
-	// PRE-CHECK: prevent silently overwriting an existing docs/<source> branch
-	if err := s.ensureDocsBranchAvailable(docRepo, docsBranch, projectID); err != nil {
-		// Clean up the session we created since we're failing
-		_ = s.generationSessions.DeleteByID(session.ID)
+		changedFiles = excludeCodeFiles(changedFiles, excluded)
+		if len(changedFiles) == 0 {
+			_ = s.generationSessions.DeleteByID(session.ID)
+			s.setSessionRuntime(sessionKey, nil)
+			return nil, fmt.Errorf("no code files to document on '%s'", sourceBranch)
+		}
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf(
+			"GenerateDocsFull: documenting all %d code files on '%s'; a full run reads far more code than a diff and can take a long time and use many tokens",
+			len(changedFiles), sourceBranch,
+		))
+		if len(changedFiles) > fullBootstrapLargeFileCount {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf(
+				"GenerateDocsFull: %d files is a large bootstrap; they are documented in several consecutive runs that each carry a summary of the earlier ones forward. Set a code scope on the project to narrow it",
+				len(changedFiles),
+			))
+		}
+	} else if worktree {
+		diffText, err = s.gitService.CompareWorktreeToBranch(codeRepo, sourceBranch, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute working tree diff: %w", err)
+		}
+		diffText, err = scopeCodeDiff(ctx, sessionKey, project, codeRoot, filterUnifiedDiff(diffText))
+		if err != nil {
+			return nil, err
+		}
+		diffText = excludeCodeDiff(diffText, excluded)
+		changedFiles = extractPathsFromDiff(diffText)
+	} else {
+		targetRef, err := s.gitService.ResolveRef(codeRepo, targetBranch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve target branch '%s': %w", targetBranch, err)
+		}
+		emitSessionDebug(ctx, sessionKey, fmt.Sprintf("Comparing %s with %s", targetRef.Label, sourceRef.Label))
+
+		diffText, err = s.gitService.DiffBetweenCommits(codeRepo, targetRef.Hash.String(), sourceHash.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute branch diff: %w", err)
+		}
+		diffText, err = scopeCodeDiff(ctx, sessionKey, project, codeRoot, diffText)
+		if err != nil {
+			return nil, err
+		}
+		diffText = excludeCodeDiff(diffText, excluded)
+		changedFiles = extractPathsFromDiff(diffText)
+		commitHistory = s.commitHistory(ctx, sessionKey, codeRepo, targetRef.Hash, sourceHash)
+	}
+	if len(changedFiles) == 0 {
+		// A clean working tree leaves nothing to run on, whatever the setting
+		if worktree || !s.workspaceSettings().GenerateWithoutChanges {
+			// Nothing to document: drop the session before a workspace or model call is made
+			_ = s.generationSessions.DeleteByID(session.ID)
+			s.setSessionRuntime(sessionKey, nil)
+			if worktree {
+				return nil, fmt.Errorf("ERR_NO_CHANGES:no uncommitted changes on '%s'", sourceBranch)
+			}
+			return nil, fmt.Errorf("ERR_NO_CHANGES:no code changes between '%s' and '%s'", targetBranch, sourceBranch)
+		}
+		emitSessionInfo(ctx, sessionKey, "GenerateDocs: no code changes detected between branches; generating from the current state")
+	}
+
+	if docCfg.Filesystem {
+		if err := s.markDocsBranchInProgress(docsBranch); err != nil {
+			return nil, err
+		}
+		defer s.unmarkDocsBranchInProgress(docsBranch)
+
+		result, err = s.runFilesystemDocs(ctx, sessionKey, runtime, project, docCfg, nil, func(streamCtx context.Context, docsPath string) (*client.DocGenerationResponse, error) {
+			return runtime.client.GenerateDocs(streamCtx, &client.DocGenerationRequest{
+				ProjectName:          project.ProjectName,
+				CodebasePath:         codeRoot,
+				DocumentationPath:    docsPath,
+				DocumentationRelPath: docCfg.DocsRelative,
+				SourceBranch:         sourceBranch,
+				TargetBranch:         targetBranch,
+				SourceCommit:         sourceHash.String(),
+				SourceCommitSummary:  commitSummary,
+				Diff:                 diffText,
+				ChangedFiles:         changedFiles,
+				CommitHistory:        commitHistory,
+				SpecificInstr:        userInstructions,
+				FullBootstrap:        full,
+				Worktree:             worktree,
+			})
+		})
+		if err != nil {
+			return nil, classifyModelError(modelInfo, err)
+		}
+		if jsonStr, err := runtime.client.ConversationHistoryJSON(); err == nil {
+			_ = s.generationSessions.UpdateByID(session.ID, map[string]interface{}{
+				"messages_json":      jsonStr,
+				"chat_messages_json": "[]",
+				"last_summary":       result.Summary,
+			})
+		}
+		emitSessionInfo(ctx, sessionKey, "GenerateDocs: completed")
+		result.SessionID = session.ID
+		result.SessionKey = sessionKey
+		result.Branch = sourceBranch
+		result.TargetBranch = targetBranch
+		result.FullBootstrap = full
+		result.FromWorktree = worktree
+		return result, nil
+	}
+
+	docRepo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open documentation repository: %w", err)
+	}
+	docStatus, err := s.gitService.StatusSummary(docRepo, docCfg.DocsRelative)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documentation repo status: %w", err)
+	}
+	if !docStatus.Clean {
+		emitSessionWarn(ctx, sessionKey, "Documentation repository has uncommitted changes - these will be preserved")
+	}
+
+	var (
+		baseHash   plumbing.Hash
+		baseBranch string
+	)
+	if docCfg.SharedWithCode {
+		baseHash = sourceHash
+		baseBranch = sourceBranch
+	} else {
+		baseHash, baseBranch, err = resolveDocumentationBase(project, docRepo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// With PreserveManualEdits an existing docs branch is regenerated from the base
+	// and its manual edits are merged back in before the branch is moved
+	editedHead, err := s.editedDocsHead(docRepo, docsBranch)
+	if err != nil {
+		return nil, err
+	}
+	if editedHead.IsZero() {
+		// PRE-CHECK: prevent silently overwriting an existing docs/<source> branch
+		if err := s.ensureDocsBranchAvailable(docRepo, docsBranch, projectID); err != nil {
+			// Clean up the session we created since we're failing
+			_ = s.generationSessions.DeleteByID(session.ID)
+			return nil, err
+		}
+	}
+
+	streamCtx := runtime.client.StartStream(ctx, sessionKey)
+	defer runtime.client.StopStream()
+
+	release, err := s.acquireRunSlot(streamCtx, sessionKey, runtime)
+	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	// Mark this docs branch as in-progress to prevent concurrent generations
 	if err := s.markDocsBranchInProgress(docsBranch); err != nil {
@@ -614,16 +1305,13 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary documentation workspace: %w", err)
 	}
-	defer cleanup() // Always cleanup temp directory
+	defer func() { s.releaseTempWorkspace(ctx, sessionKey, tempWorkspace, cleanup, err) }()
 
 	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
 		"GenerateDocs: temporary documentation workspace ready for branch '%s'",
 		docsBranch,
 	))
 
-	streamCtx := runtime.client.StartStream(ctx, sessionKey)
-	defer runtime.client.StopStream()
-
 	// Use temporary documentation root for LLM operations
 	llmResult, err := runtime.client.GenerateDocs(streamCtx, &client.DocGenerationRequest{
 		ProjectName:          project.ProjectName,
@@ -633,21 +1321,51 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 		SourceBranch:         sourceBranch,
 		TargetBranch:         targetBranch,
 		SourceCommit:         sourceHash.String(),
+		SourceCommitSummary:  commitSummary,
 		Diff:                 diffText,
 		ChangedFiles:         changedFiles,
+		CommitHistory:        commitHistory,
 		SpecificInstr:        userInstructions,
+		FullBootstrap:        full,
+		Worktree:             worktree,
 	})
+	// The manual edits are merged in after the generated output is committed and
+	// committed apart from it, so the next regeneration still sees them as manual
+	var (
+		preservedEdits []string
+		mergeConflicts []models.DocMergeConflict
+	)
+	carryEdits := func() error {
+		var mergeErr error
+		preservedEdits, mergeConflicts, mergeErr = carryManualEdits(ctx, sessionKey, docRepo, tempWorkspace, docCfg.DocsRelative, baseHash, editedHead)
+		if mergeErr != nil {
+			return fmt.Errorf("failed to merge manual edits: %w", mergeErr)
+		}
+		return nil
+	}
 	if err != nil {
-		return nil, err
+		// The partial output replaces the docs branch too, so it keeps the manual edits as well
+		if runtime.client.IsPaused() {
+			return s.persistPausedRun(ctx, sessionKey, session, runtime, tempWorkspace, docRepo, docCfg, baseBranch, baseHash, carryEdits)
+		}
+		if runtime.client.IsCanceled() {
+			return nil, s.persistCanceledRun(ctx, sessionKey, session, runtime, tempWorkspace, docRepo, docCfg, baseHash, carryEdits)
+		}
+		return nil, classifyModelError(modelInfo, err)
 	}
 
+	s.maintainFumadocsMeta(ctx, sessionKey, project, tempWorkspace, docCfg.DocsRelative, nil)
+
 	// Propagate changes from temporary repository back to main repository
-	files, err := propagateDocChanges(ctx, sessionKey, tempWorkspace, docRepo, docsBranch, docCfg.DocsRelative)
+	files, err := propagateDocChangesWithEdits(ctx, sessionKey, tempWorkspace, docRepo, docsBranch, docCfg.DocsRelative, carryEdits)
 	if err != nil {
 		return nil, fmt.Errorf("failed to propagate documentation changes: %w", err)
 	}
+	routeTree := s.buildRouteTree(ctx, sessionKey, tempWorkspace.docsPath)
+	frontmatterWarnings := s.checkFrontmatter(ctx, sessionKey, tempWorkspace, files)
+	componentWarnings := s.lintMDXComponents(ctx, sessionKey, project, tempWorkspace, files)
 
-	branchCreated, err := ensureDocsBranchExists(docRepo, docsBranch, baseHash)
+	branchCreated, err := s.ensureDocsBranchExists(docRepo, docsBranch, baseHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare documentation branch '%s': %w", docsBranch, err)
 	}
@@ -661,14 +1379,15 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 			_ = s.generationSessions.UpdateByID(session.ID, map[string]interface{}{
 				"messages_json":      jsonStr,
 				"chat_messages_json": "[]",
+				"last_summary":       llmResult.Summary,
 			})
 		}
 	}
 
 	// Generate diff between the new docs branch and its base branch
-	docDiff, err := s.gitService.DiffBetweenBranches(docRepo, baseBranch, docsBranch)
+	docDiff, diffStat, err := s.documentationDiff(docRepo, baseBranch, docsBranch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate documentation diff: %w", err)
+		return nil, err
 	}
 
 	emitSessionInfo(ctx, sessionKey, "GenerateDocs: completed")
@@ -677,16 +1396,188 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 	if llmResult != nil {
 		summary = llmResult.Summary
 	}
-	return &models.DocGenerationResult{
-		SessionID:      session.ID,
-		SessionKey:     sessionKey,
-		Branch:         sourceBranch,
-		TargetBranch:   targetBranch,
-		DocsBranch:     docsBranch,
-		DocsInCodeRepo: docCfg.SharedWithCode,
-		Files:          files,
-		Diff:           docDiff,
-		Summary:        summary,
+	result = &models.DocGenerationResult{
+		SessionID:           session.ID,
+		SessionKey:          sessionKey,
+		Branch:              sourceBranch,
+		TargetBranch:        targetBranch,
+		DocsBranch:          docsBranch,
+		DocsInCodeRepo:      docCfg.SharedWithCode,
+		Files:               files,
+		Diff:                docDiff,
+		DiffStat:            diffStat,
+		Summary:             summary,
+		FrontmatterWarnings: frontmatterWarnings,
+		ComponentWarnings:   componentWarnings,
+		RouteTree:           routeTree,
+		FullBootstrap:       full,
+		FromWorktree:        worktree,
+		PreservedEdits:      preservedEdits,
+		MergeConflicts:      mergeConflicts,
+	}
+	applyStructuredSummary(result, llmResult)
+	return result, nil
+}
+
+// PlanDocs runs the documentation agent in plan mode: it explores the code
+// diff and the docs as GenerateDocs would, but the write, edit and delete
+// tools only record the intended change. No session record is created, the
+// docs branch is not touched and the temporary workspace is always discarded.
+// The session key is prefixed with "plan:" so its events are not mistaken for
+// a real run.
+func (s *ClientService) PlanDocs(projectID uint, sourceBranch string, targetBranch string, modelKey string, userInstructions string, sessionKeyOverride string) (*models.DocPlanResult, error) {
+	ctx := s.context
+	if ctx == nil {
+		return nil, fmt.Errorf("client service not initialized")
+	}
+	sourceBranch = strings.TrimSpace(sourceBranch)
+	targetBranch = strings.TrimSpace(targetBranch)
+	modelKey = strings.TrimSpace(modelKey)
+	if projectID == 0 {
+		return nil, fmt.Errorf("project id is required")
+	}
+	if sourceBranch == "" || targetBranch == "" {
+		return nil, fmt.Errorf("source and target branches are required")
+	}
+	if sourceBranch == targetBranch {
+		return nil, fmt.Errorf("source and target branches must differ")
+	}
+	modelKey, err := s.resolveRunModelKey(projectID, modelKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionKey := strings.TrimSpace(sessionKeyOverride)
+	if sessionKey == "" {
+		sessionKey = fmt.Sprintf("plan:%d:%s", projectID, generateUniqueID())
+	}
+	runtime, _, err := s.newSessionRuntime(events.WithSession(ctx, sessionKey), projectID, modelKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize LLM client: %w", err)
+	}
+	runtime.targetBranch = targetBranch
+	s.setSessionRuntime(sessionKey, runtime)
+	defer s.setSessionRuntime(sessionKey, nil)
+	emitRuntimeWarnings(ctx, sessionKey, runtime)
+
+	project, codeRoot, docCfg, err := s.prepareProjectRepos(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
+		"PlanDocs: planning for project %s (%s -> %s) using %s via %s; no files will be written",
+		project.ProjectName, targetBranch, sourceBranch, runtime.modelDisplay, runtime.providerLabel,
+	))
+
+	codeRepo, err := s.gitService.Open(codeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open code repository: %w", err)
+	}
+	targetRef, err := s.gitService.ResolveRef(codeRepo, targetBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve target branch '%s': %w", targetBranch, err)
+	}
+	sourceRef, err := s.gitService.ResolveRef(codeRepo, sourceBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source branch '%s': %w", sourceBranch, err)
+	}
+	targetHash, sourceHash := targetRef.Hash, sourceRef.Hash
+	emitSessionDebug(ctx, sessionKey, fmt.Sprintf("Comparing %s with %s", targetRef.Label, sourceRef.Label))
+	commitSummary := s.describeSourceCommit(ctx, sessionKey, codeRepo, sourceHash)
+	commitHistory := s.commitHistory(ctx, sessionKey, codeRepo, targetHash, sourceHash)
+
+	diffText, err := s.gitService.DiffBetweenCommits(codeRepo, targetHash.String(), sourceHash.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute branch diff: %w", err)
+	}
+	diffText, err = scopeCodeDiff(ctx, sessionKey, project, codeRoot, diffText)
+	if err != nil {
+		return nil, err
+	}
+	changedFiles := extractPathsFromDiff(diffText)
+	if len(changedFiles) == 0 {
+		emitSessionInfo(ctx, sessionKey, "PlanDocs: no code changes detected between branches")
+	}
+
+	streamCtx := runtime.client.StartStream(ctx, sessionKey)
+	defer runtime.client.StopStream()
+
+	release, err := s.acquireRunSlot(streamCtx, sessionKey, runtime)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// The agent reads the docs as they are on the base branch; the plan tools
+	// never write, but the workspace is still isolated and thrown away
+	var (
+		tempWorkspace tempDocWorkspace
+		cleanup       func()
+	)
+	if docCfg.Filesystem {
+		tempWorkspace, cleanup, err = createFilesystemDocWorkspace(ctx, sessionKey, docCfg)
+	} else {
+		docRepo, openErr := s.gitService.Open(docCfg.RepoRoot)
+		if openErr != nil {
+			return nil, fmt.Errorf("failed to open documentation repository: %w", openErr)
+		}
+
+		var (
+			baseHash   plumbing.Hash
+			baseBranch string
+		)
+		if docCfg.SharedWithCode {
+			baseHash = sourceHash
+			baseBranch = sourceBranch
+		} else {
+			baseHash, baseBranch, err = resolveDocumentationBase(project, docRepo)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tempWorkspace, cleanup, err = createTempDocRepo(ctx, sessionKey, docCfg, documentationBranchName(sourceBranch), baseBranch, baseHash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary documentation workspace: %w", err)
+	}
+	defer cleanup()
+
+	llmResult, err := runtime.client.PlanDocs(streamCtx, &client.DocGenerationRequest{
+		ProjectName:          project.ProjectName,
+		CodebasePath:         codeRoot,
+		DocumentationPath:    tempWorkspace.docsPath,
+		DocumentationRelPath: docCfg.DocsRelative,
+		SourceBranch:         sourceBranch,
+		TargetBranch:         targetBranch,
+		SourceCommit:         sourceHash.String(),
+		SourceCommitSummary:  commitSummary,
+		Diff:                 diffText,
+		ChangedFiles:         changedFiles,
+		CommitHistory:        commitHistory,
+		SpecificInstr:        userInstructions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.DocPlannedChange, 0, len(llmResult.Plan))
+	for _, change := range llmResult.Plan {
+		changes = append(changes, models.DocPlannedChange{
+			Path:      change.Path,
+			Operation: change.Operation,
+			Rationale: change.Rationale,
+		})
+	}
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("PlanDocs: completed with %d planned change(s)", len(changes)))
+
+	return &models.DocPlanResult{
+		SessionKey:   sessionKey,
+		Plan:         true,
+		Branch:       sourceBranch,
+		TargetBranch: targetBranch,
+		Changes:      changes,
+		Summary:      llmResult.Summary,
 	}, nil
 }
 
@@ -694,6 +1585,32 @@ func (s *ClientService) GenerateDocs(projectID uint, sourceBranch string, target
 // for a given session. It reuses the same toolset as GenerateDocs but focuses
 // on targeted edits directed by the user's request.
 func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKeyOverride string) (*models.DocGenerationResult, error) {
+	return s.refineDocs(sessionID, instruction, sessionKeyOverride, false, nil)
+}
+
+// RefineDocsFiles regenerates only the listed documentation files of a
+// session, optionally guided by an instruction. Paths are as reported in the
+// session's changed files; the agent's write, edit and delete tools reject
+// every other file.
+func (s *ClientService) RefineDocsFiles(sessionID uint, paths []string, instruction string) (*models.DocGenerationResult, error) {
+	var files []string
+	for _, p := range paths {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			files = append(files, trimmed)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("at least one file is required")
+	}
+	if strings.TrimSpace(instruction) == "" {
+		instruction = "Regenerate the listed documentation files so they accurately describe the current code."
+	}
+	return s.refineDocs(sessionID, instruction, "", false, files)
+}
+
+// refineDocs runs a refinement for a session. When onlyFiles is set, only
+// those repository-relative documentation files may be changed.
+func (s *ClientService) refineDocs(sessionID uint, instruction string, sessionKeyOverride string, resume bool, onlyFiles []string) (result *models.DocGenerationResult, err error) {
 	ctx := s.context
 	if ctx == nil {
 		return nil, fmt.Errorf("client service not initialized")
@@ -730,6 +1647,9 @@ func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKe
 	}
 	defer s.unmarkDocsBranchInProgress(docsBranch)
 
+	ctx, flushEventLog := s.startSessionEvents(ctx, sessionKey, sessionID)
+	defer flushEventLog()
+
 	runtime, err := s.ensureRuntimeFromSession(ctx, session, sessionKey)
 	if err != nil {
 		return nil, err
@@ -744,6 +1664,50 @@ func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKe
 	if err != nil {
 		return nil, err
 	}
+	allowedFiles, err := docsRelativePaths(onlyFiles, docCfg.DocsRelative)
+	if err != nil {
+		return nil, err
+	}
+
+	if docCfg.Filesystem {
+		existingChat := s.loadStoredChatMessagesFromSession(session)
+		result, err = s.runFilesystemDocs(ctx, sessionKey, runtime, project, docCfg, allowedFiles, func(streamCtx context.Context, docsPath string) (*client.DocGenerationResponse, error) {
+			return runtime.client.DocRefine(streamCtx, &client.DocRefineRequest{
+				ProjectName:          project.ProjectName,
+				CodebasePath:         codeRoot,
+				DocumentationPath:    docsPath,
+				DocumentationRelPath: docCfg.DocsRelative,
+				SourceBranch:         sourceBranch,
+				Instruction:          instruction,
+				AllowedFiles:         allowedFiles,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		chatUserText := instruction
+		if resume {
+			chatUserText = ""
+		}
+		chatMessages := appendChatMessages(existingChat, chatUserText, result.Summary)
+		if jsonStr, err := runtime.client.ConversationHistoryJSON(); err == nil {
+			_ = s.generationSessions.UpdateByID(sessionID, map[string]interface{}{
+				"messages_json":      jsonStr,
+				"chat_messages_json": marshalChatMessages(chatMessages),
+				"todos_json":         "",
+				"paused":             false,
+				"canceled":           false,
+				"last_summary":       result.Summary,
+			})
+		}
+		emitSessionInfo(ctx, sessionKey, "RefineDocs: completed")
+		result.SessionID = sessionID
+		result.SessionKey = sessionKey
+		result.Branch = sourceBranch
+		result.TargetBranch = strings.TrimSpace(session.TargetBranch)
+		result.ChatMessages = chatMessages
+		return result, nil
+	}
 
 	// Open documentation repo and ensure the docs branch exists
 	docRepo, err := s.gitService.Open(docCfg.RepoRoot)
@@ -757,10 +1721,11 @@ func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKe
 	)
 	if docCfg.SharedWithCode {
 		baseBranch = sourceBranch
-		baseHash, err = resolveBranchHash(docRepo, sourceBranch)
+		sourceRef, err := s.gitService.ResolveRef(docRepo, sourceBranch)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve source branch '%s': %w", sourceBranch, err)
 		}
+		baseHash = sourceRef.Hash
 	} else {
 		baseHash, baseBranch, err = resolveDocumentationBase(project, docRepo)
 		if err != nil {
@@ -773,34 +1738,44 @@ func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKe
 
 	existingChat := s.loadStoredChatMessagesFromSession(session)
 
-	refName := plumbing.NewBranchReferenceName(docsBranch)
 	// Ensure the docs branch exists in the main repo; if not, create it off base
-	if _, err := docRepo.Reference(refName, true); err != nil {
-		if errors.Is(err, plumbing.ErrReferenceNotFound) {
-			// Create docs branch pointing to base commit
-			if err := docRepo.Storer.SetReference(plumbing.NewHashReference(refName, baseHash)); err != nil {
-				return nil, fmt.Errorf("failed to create documentation branch '%s': %w", docsBranch, err)
-			}
-			emitSessionInfo(ctx, sessionKey, fmt.Sprintf("RefineDocs: created missing docs branch '%s' from '%s'", docsBranch, baseBranch))
-		} else {
-			return nil, fmt.Errorf("failed to resolve documentation branch '%s': %w", docsBranch, err)
-		}
+	branchCreated, err := s.ensureDocsBranchExists(docRepo, docsBranch, baseHash)
+	if err != nil {
+		return nil, err
+	}
+	if branchCreated {
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf("RefineDocs: created missing docs branch '%s' from '%s'", docsBranch, baseBranch))
+	}
+
+	streamCtx := runtime.client.StartStream(ctx, sessionKey)
+	defer runtime.client.StopStream()
+
+	release, err := s.acquireRunSlot(streamCtx, sessionKey, runtime)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	// Create a temporary workspace checked out to the current docs branch head
 	tempWorkspace, cleanup, err := createTempDocRepoAtBranchHead(ctx, sessionKey, docCfg, docsBranch, baseBranch, baseHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary documentation workspace: %w", err)
 	}
-	defer cleanup()
+	defer func() { s.releaseTempWorkspace(ctx, sessionKey, tempWorkspace, cleanup, err) }()
 
 	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
 		"RefineDocs: temporary documentation workspace ready for branch '%s'",
 		docsBranch,
 	))
 
-	streamCtx := runtime.client.StartStream(ctx, sessionKey)
-	defer runtime.client.StopStream()
+	if session.Paused {
+		if err := runtime.client.RestoreTodosJSON(session.TodosJSON); err != nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to restore paused todo list: %v", err))
+		}
+		if err := runtime.client.RestoreBootstrapProgressJSON(session.BootstrapProgressJSON); err != nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to restore the bootstrap parts left to document: %v", err))
+		}
+	}
 
 	// Run the refinement agent focused on applying user edits
 	llmResult, err := runtime.client.DocRefine(streamCtx, &client.DocRefineRequest{
@@ -810,8 +1785,27 @@ func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKe
 		DocumentationRelPath: docCfg.DocsRelative,
 		SourceBranch:         sourceBranch,
 		Instruction:          instruction,
+		AllowedFiles:         allowedFiles,
 	})
+	if err == nil {
+		// A resumed chunked bootstrap goes on with the parts after the one it
+		// finished; any other run's result is returned as is
+		llmResult, err = runtime.client.ContinueBootstrap(streamCtx, &client.DocGenerationRequest{
+			ProjectName:          project.ProjectName,
+			CodebasePath:         codeRoot,
+			DocumentationPath:    tempWorkspace.docsPath,
+			DocumentationRelPath: docCfg.DocsRelative,
+			SourceBranch:         sourceBranch,
+			TargetBranch:         strings.TrimSpace(session.TargetBranch),
+		}, llmResult)
+	}
 	if err != nil {
+		if runtime.client.IsPaused() {
+			return s.persistPausedRun(ctx, sessionKey, session, runtime, tempWorkspace, docRepo, docCfg, baseBranch, baseHash, nil)
+		}
+		if runtime.client.IsCanceled() {
+			return nil, s.persistCanceledRun(ctx, sessionKey, session, runtime, tempWorkspace, docRepo, docCfg, baseHash, nil)
+		}
 		return nil, err
 	}
 
@@ -819,27 +1813,42 @@ func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKe
 	if llmResult != nil {
 		assistantSummary = llmResult.Summary
 	}
-	chatMessages := appendChatMessages(existingChat, instruction, assistantSummary)
+	chatUserText := instruction
+	if resume {
+		// The resume prompt is internal; only surface the assistant reply in chat
+		chatUserText = ""
+	}
+	chatMessages := appendChatMessages(existingChat, chatUserText, assistantSummary)
 	chatMessagesJSON := marshalChatMessages(chatMessages)
 
+	s.maintainFumadocsMeta(ctx, sessionKey, project, tempWorkspace, docCfg.DocsRelative, allowedFiles)
+
 	// Propagate changes back to the main documentation repository
 	files, err := propagateDocChanges(ctx, sessionKey, tempWorkspace, docRepo, docsBranch, docCfg.DocsRelative)
 	if err != nil {
 		return nil, fmt.Errorf("failed to propagate documentation changes: %w", err)
 	}
+	routeTree := s.buildRouteTree(ctx, sessionKey, tempWorkspace.docsPath)
+	frontmatterWarnings := s.checkFrontmatter(ctx, sessionKey, tempWorkspace, files)
+	componentWarnings := s.lintMDXComponents(ctx, sessionKey, project, tempWorkspace, files)
 
 	// Update diff between base branch and docs branch for UI preview
-	docDiff, err := s.gitService.DiffBetweenBranches(docRepo, baseBranch, docsBranch)
+	docDiff, diffStat, err := s.documentationDiff(docRepo, baseBranch, docsBranch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate documentation diff: %w", err)
+		return nil, err
 	}
 
 	// Save conversation history and chat messages to session
 	if runtime.client != nil {
 		if jsonStr, err := runtime.client.ConversationHistoryJSON(); err == nil {
 			_ = s.generationSessions.UpdateByID(sessionID, map[string]interface{}{
-				"messages_json":      jsonStr,
-				"chat_messages_json": chatMessagesJSON,
+				"messages_json":           jsonStr,
+				"chat_messages_json":      chatMessagesJSON,
+				"todos_json":              "",
+				"bootstrap_progress_json": "",
+				"paused":                  false,
+				"canceled":                false,
+				"last_summary":            assistantSummary,
 			})
 		}
 	}
@@ -850,22 +1859,28 @@ func (s *ClientService) RefineDocs(sessionID uint, instruction string, sessionKe
 	if llmResult != nil {
 		summary = llmResult.Summary
 	}
-	return &models.DocGenerationResult{
-		SessionID:      sessionID,
-		SessionKey:     sessionKey,
-		Branch:         sourceBranch,
-		TargetBranch:   baseBranch,
-		DocsBranch:     docsBranch,
-		DocsInCodeRepo: docCfg.SharedWithCode,
-		Files:          files,
-		Diff:           docDiff,
-		Summary:        summary,
-		ChatMessages:   chatMessages,
-	}, nil
+	result = &models.DocGenerationResult{
+		SessionID:           sessionID,
+		SessionKey:          sessionKey,
+		Branch:              sourceBranch,
+		TargetBranch:        baseBranch,
+		DocsBranch:          docsBranch,
+		DocsInCodeRepo:      docCfg.SharedWithCode,
+		Files:               files,
+		Diff:                docDiff,
+		DiffStat:            diffStat,
+		Summary:             summary,
+		ChatMessages:        chatMessages,
+		RouteTree:           routeTree,
+		FrontmatterWarnings: frontmatterWarnings,
+		ComponentWarnings:   componentWarnings,
+	}
+	applyStructuredSummary(result, llmResult)
+	return result, nil
 }
 
 // MergeDocsIntoSource fast-forwards the source code branch to include the latest
-// documentation commit generated on docs/<sourceBranch>. Only supported when
+// documentation commit on the session's docs branch. Only supported when
 // documentation lives within the code repository.
 func (s *ClientService) MergeDocsIntoSource(sessionID uint) error {
 	ctx := s.context
@@ -892,6 +1907,9 @@ func (s *ClientService) MergeDocsIntoSource(sessionID uint) error {
 	if err != nil {
 		return err
 	}
+	if docCfg.Filesystem {
+		return fmt.Errorf("documentation is written directly to a directory; there is no docs branch to merge")
+	}
 	if !docCfg.SharedWithCode {
 		return fmt.Errorf("documentation repository is separate; merge into source branch is not supported")
 	}
@@ -916,7 +1934,7 @@ func (s *ClientService) MergeDocsIntoSource(sessionID uint) error {
 		}
 	}
 
-	docsBranch := documentationBranchName(sourceBranch)
+	docsBranch := sessionDocsBranch(session)
 	docRefName := plumbing.NewBranchReferenceName(docsBranch)
 	sourceRefName := plumbing.NewBranchReferenceName(sourceBranch)
 
@@ -944,16 +1962,12 @@ func (s *ClientService) MergeDocsIntoSource(sessionID uint) error {
 		return fmt.Errorf("failed to resolve source branch '%s': %w", sourceBranch, err)
 	}
 
-	sourceCommit, err := repo.CommitObject(sourceRef.Hash())
-	if err != nil {
-		return fmt.Errorf("failed to load source commit: %w", err)
-	}
-
-	isAncestor, err := sourceCommit.IsAncestor(docCommit)
+	// The source branch can be fast-forwarded only if it is the merge base
+	mergeBase, err := s.gitService.MergeBase(repo, sourceBranch, docsBranch)
 	if err != nil {
 		return fmt.Errorf("failed to verify branch ancestry: %w", err)
 	}
-	if !isAncestor {
+	if mergeBase != sourceRef.Hash().String() {
 		return fmt.Errorf("source branch '%s' has diverged since documentation was generated", sourceBranch)
 	}
 
@@ -979,12 +1993,392 @@ func (s *ClientService) MergeDocsIntoSource(sessionID uint) error {
 	return nil
 }
 
+// DocsBranchAheadBehind compares a session's docs branch with its source
+// branch so the merge dialog can tell whether a fast-forward is possible:
+// Ahead is the number of documentation commits to merge and Behind how far
+// the source branch has moved on since the docs were generated. Docs kept in
+// their own repository are compared with the documentation base branch.
+func (s *ClientService) DocsBranchAheadBehind(sessionID uint) (*models.AheadBehind, error) {
+	if sessionID == 0 {
+		return nil, fmt.Errorf("session ID is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found")
+	}
+
+	project, _, docCfg, err := s.prepareProjectRepos(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if docCfg.Filesystem {
+		return nil, fmt.Errorf("documentation is written directly to a directory; there is no docs branch")
+	}
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	// Same branch pair MergeDocsIntoSource fast-forwards; a separate docs
+	// repository does not have the source branch
+	baseBranch := strings.TrimSpace(session.SourceBranch)
+	if !docCfg.SharedWithCode {
+		if _, baseBranch, err = resolveDocumentationBase(project, repo); err != nil {
+			return nil, err
+		}
+	}
+	return s.gitService.AheadBehind(repo, baseBranch, sessionDocsBranch(session))
+}
+
+// DocsBranchLog pages through the commit history of a session's docs branch,
+// newest first, so the session view can list the commits behind the docs.
+func (s *ClientService) DocsBranchLog(sessionID uint, limit, skip int) ([]models.CommitInfo, error) {
+	if sessionID == 0 {
+		return nil, fmt.Errorf("session ID is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found")
+	}
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+	if docsBranch == "" {
+		return nil, fmt.Errorf("session has no docs branch")
+	}
+
+	_, _, docCfg, err := s.prepareProjectRepos(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if docCfg.Filesystem {
+		return nil, fmt.Errorf("documentation is written directly to a directory; there is no docs branch")
+	}
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return s.gitService.Log(repo, docsBranch, limit, skip)
+}
+
+// RenameDocsBranch renames a session's docs branch in the documentation
+// repository and records the new name on the session, e.g. to match a pull
+// request naming convention without generating again. Both names are held
+// in progress while renaming so no run can start on either.
+func (s *ClientService) RenameDocsBranch(sessionID uint, newName string) error {
+	if sessionID == 0 {
+		return fmt.Errorf("session ID is required")
+	}
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("docs branch is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return fmt.Errorf("session not found")
+	}
+	oldName := strings.TrimSpace(session.DocsBranch)
+	if oldName == "" {
+		return fmt.Errorf("session has no docs branch")
+	}
+	if oldName == newName {
+		return nil
+	}
+
+	if err := s.markDocsBranchInProgress(oldName); err != nil {
+		return err
+	}
+	defer s.unmarkDocsBranchInProgress(oldName)
+	if err := s.markDocsBranchInProgress(newName); err != nil {
+		return err
+	}
+	defer s.unmarkDocsBranchInProgress(newName)
+
+	existingSession, err := s.generationSessions.GetByDocsBranch(session.ProjectID, newName)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing session: %w", err)
+	}
+	if existingSession != nil {
+		return fmt.Errorf("ERR_SESSION_EXISTS:%d:%s", existingSession.ID, newName)
+	}
+
+	_, _, docCfg, err := s.prepareProjectRepos(session.ProjectID)
+	if err != nil {
+		return err
+	}
+	if docCfg.Filesystem {
+		return fmt.Errorf("documentation is written directly to a directory; there is no docs branch")
+	}
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	if err := s.gitService.RenameBranch(repo, oldName, newName); err != nil {
+		return err
+	}
+
+	if err := s.generationSessions.UpdateByID(sessionID, map[string]interface{}{
+		"docs_branch": newName,
+	}); err != nil {
+		// Keep the branch and the session in agreement
+		_ = s.gitService.RenameBranch(repo, newName, oldName)
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	return nil
+}
+
+// CherryPickDocsCommit applies a commit from another docs branch, such as a
+// typo fixed by hand, onto a session's docs branch and returns the new commit
+// hash. Conflicts are reported as a *CherryPickConflictError and leave the
+// branch untouched.
+func (s *ClientService) CherryPickDocsCommit(sessionID uint, commitHash string) (string, error) {
+	if sessionID == 0 {
+		return "", fmt.Errorf("session ID is required")
+	}
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" {
+		return "", fmt.Errorf("commit is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return "", fmt.Errorf("session not found")
+	}
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+	if docsBranch == "" {
+		return "", fmt.Errorf("session has no docs branch")
+	}
+
+	if err := s.markDocsBranchInProgress(docsBranch); err != nil {
+		return "", err
+	}
+	defer s.unmarkDocsBranchInProgress(docsBranch)
+
+	_, _, docCfg, err := s.prepareProjectRepos(session.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if docCfg.Filesystem {
+		return "", fmt.Errorf("documentation is written directly to a directory; there is no docs branch")
+	}
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	hash, err := s.gitService.CherryPick(repo, docsBranch, commitHash)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// RevertDocsCommit undoes a commit on a session's docs branch, such as a
+// generated commit the user regrets, by committing its inverse on top so the
+// history is kept. It returns the new commit hash. Conflicts with later
+// commits are reported as a *RevertConflictError and leave the branch
+// untouched.
+func (s *ClientService) RevertDocsCommit(sessionID uint, commitHash string) (string, error) {
+	if sessionID == 0 {
+		return "", fmt.Errorf("session ID is required")
+	}
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" {
+		return "", fmt.Errorf("commit is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return "", fmt.Errorf("session not found")
+	}
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+	if docsBranch == "" {
+		return "", fmt.Errorf("session has no docs branch")
+	}
+
+	if err := s.markDocsBranchInProgress(docsBranch); err != nil {
+		return "", err
+	}
+	defer s.unmarkDocsBranchInProgress(docsBranch)
+
+	_, _, docCfg, err := s.prepareProjectRepos(session.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if docCfg.Filesystem {
+		return "", fmt.Errorf("documentation is written directly to a directory; there is no docs branch")
+	}
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	hash, err := s.gitService.Revert(repo, docsBranch, commitHash)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// ArchiveDocsBranch archives a session's docs branch with
+// GitService.ArchiveBranch, typically once its docs are merged, and returns the
+// archive tag. The session is kept so the branch can be restored.
+func (s *ClientService) ArchiveDocsBranch(sessionID uint) (string, error) {
+	session, repo, docsBranch, err := s.openSessionDocsBranch(sessionID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.markDocsBranchInProgress(docsBranch); err != nil {
+		return "", err
+	}
+	defer s.unmarkDocsBranchInProgress(docsBranch)
+
+	tag, err := s.gitService.ArchiveBranch(repo, docsBranch)
+	if err != nil {
+		return "", err
+	}
+	emitSessionInfo(s.context, makeSessionKey(session.ID), fmt.Sprintf("Archived docs branch '%s' as tag '%s'", docsBranch, tag))
+	return tag, nil
+}
+
+// RestoreDocsBranch recreates a session's docs branch from its most recent
+// archive tag.
+func (s *ClientService) RestoreDocsBranch(sessionID uint) error {
+	_, repo, docsBranch, err := s.openSessionDocsBranch(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := s.markDocsBranchInProgress(docsBranch); err != nil {
+		return err
+	}
+	defer s.unmarkDocsBranchInProgress(docsBranch)
+
+	tag, err := latestArchiveTag(repo, docsBranch)
+	if err != nil {
+		return err
+	}
+	if tag == "" {
+		return fmt.Errorf("docs branch '%s' has not been archived", docsBranch)
+	}
+	_, err = s.gitService.RestoreArchivedBranch(repo, tag)
+	return err
+}
+
+// openSessionDocsBranch loads a session and opens the repository holding its
+// docs branch.
+func (s *ClientService) openSessionDocsBranch(sessionID uint) (*models.GenerationSession, *git.Repository, string, error) {
+	if sessionID == 0 {
+		return nil, nil, "", fmt.Errorf("session ID is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil, nil, "", fmt.Errorf("session not found")
+	}
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+	if docsBranch == "" {
+		return nil, nil, "", fmt.Errorf("session has no docs branch")
+	}
+	_, _, docCfg, err := s.prepareProjectRepos(session.ProjectID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if docCfg.Filesystem {
+		return nil, nil, "", fmt.Errorf("documentation is written directly to a directory; there is no docs branch")
+	}
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to open repository: %w", err)
+	}
+	return session, repo, docsBranch, nil
+}
+
+// ListDocsBranches lists the documentation branches in a project's
+// documentation repository with the session owning each one. Branches under
+// docs/ are included, as are branches a session was renamed to. Ahead counts
+// the commits a branch has over its session's source branch, or over the
+// documentation base branch when there is no session.
+func (s *ClientService) ListDocsBranches(projectID uint) ([]models.DocsBranchInfo, error) {
+	if projectID == 0 {
+		return nil, fmt.Errorf("project id is required")
+	}
+	project, _, docCfg, err := s.prepareProjectRepos(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if docCfg.Filesystem {
+		return nil, fmt.Errorf("documentation is written directly to a directory; there is no docs branch")
+	}
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	branches, err := s.gitService.ListBranches(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	sessions, err := s.generationSessions.List(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list generation sessions: %w", err)
+	}
+	owners := make(map[string]models.GenerationSession, len(sessions))
+	for _, session := range sessions {
+		if docsBranch := strings.TrimSpace(session.DocsBranch); docsBranch != "" {
+			owners[docsBranch] = session
+		}
+	}
+	docsBase := strings.TrimSpace(project.DocumentationBaseBranch)
+
+	result := make([]models.DocsBranchInfo, 0)
+	for _, branch := range branches {
+		session, owned := owners[branch.Name]
+		if !owned && !strings.HasPrefix(branch.Name, "docs/") {
+			continue
+		}
+		info := models.DocsBranchInfo{
+			Name:           branch.Name,
+			LastCommitDate: branch.LastCommitDate,
+		}
+		base := docsBase
+		if owned {
+			info.SessionID = session.ID
+			if source := strings.TrimSpace(session.SourceBranch); source != "" {
+				if exists, _ := s.gitService.BranchExists(repo, source); exists {
+					base = source
+				}
+			}
+		}
+		if base != "" && base != branch.Name {
+			// A missing base only leaves Ahead unset
+			if counts, abErr := s.gitService.AheadBehind(repo, base, branch.Name); abErr == nil {
+				info.Base = base
+				info.Ahead = counts.Ahead
+			}
+		}
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
 func createTempDocWorkspace(ctx context.Context, sessionKey string, cfg *docRepoConfig, branch string, baseBranch string, baseHash plumbing.Hash, checkoutHead bool) (workspace tempDocWorkspace, cleanup func(), err error) {
 	if cfg == nil {
 		return tempDocWorkspace{}, nil, fmt.Errorf("documentation repository configuration is required")
 	}
-	repoPath, cleanup := newTempRepoDir(ctx, sessionKey)
-	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Creating temporary docs workspace at %s", repoPath))
+	if repoPath, cleanup, ok := createLinkedDocWorktree(ctx, sessionKey, cfg, branch, baseBranch, baseHash, checkoutHead); ok {
+		return finishTempDocWorkspace(ctx, sessionKey, cfg, branch, repoPath, cleanup, true)
+	}
 
 	cloneOpts := &git.CloneOptions{
 		URL:          cfg.RepoRoot,
@@ -998,16 +2392,9 @@ func createTempDocWorkspace(ctx context.Context, sessionKey string, cfg *docRepo
 		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(baseBranch)
 	}
 
-	tempRepo, err := git.PlainClone(repoPath, false, cloneOpts)
+	tempRepo, repoPath, cleanup, err := cloneDocRepo(ctx, sessionKey, cfg, cloneOpts)
 	if err != nil {
-		cleanup()
-		repoPath, cleanup = newTempRepoDir(ctx, sessionKey)
-		emitSessionWarn(ctx, sessionKey, "Shallow clone failed; retrying with full clone")
-		tempRepo, err = git.PlainClone(repoPath, false, &git.CloneOptions{URL: cfg.RepoRoot, Progress: nil})
-		if err != nil {
-			cleanup()
-			return tempDocWorkspace{}, nil, fmt.Errorf("failed to clone repository to temp location: %w", err)
-		}
+		return tempDocWorkspace{}, nil, err
 	}
 
 	wt, err := tempRepo.Worktree()
@@ -1062,6 +2449,101 @@ func createTempDocWorkspace(ctx context.Context, sessionKey string, cfg *docRepo
 		}
 	}
 
+	return finishTempDocWorkspace(ctx, sessionKey, cfg, branch, repoPath, cleanup, false)
+}
+
+// plainCloneContext is git.PlainCloneContext; tests replace it to make clone
+// attempts fail.
+var plainCloneContext = git.PlainCloneContext
+
+// cloneRetryBackoff is the wait before the first clone retry. It doubles with
+// every further retry.
+var cloneRetryBackoff = 500 * time.Millisecond
+
+// cloneDocRepo clones the documentation repository into a new temp directory.
+// The shallow clone in opts is retried cfg.CloneRetries times with backoff
+// before falling back to a full clone, and every attempt is bounded by
+// cfg.CloneTimeout so a hung mount cannot stall the run.
+func cloneDocRepo(ctx context.Context, sessionKey string, cfg *docRepoConfig, opts *git.CloneOptions) (*git.Repository, string, func(), error) {
+	attempts := max(cfg.CloneRetries, 0) + 1
+	backoff := cloneRetryBackoff
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		repoPath, cleanup := newTempRepoDir(ctx, sessionKey, cfg.WorkspaceBase)
+		if attempt == 1 {
+			emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Creating temporary docs workspace at %s", repoPath))
+		}
+		repo, err := cloneWithTimeout(ctx, cfg.CloneTimeout, repoPath, cleanup, opts)
+		if err == nil {
+			return repo, repoPath, cleanup, nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", nil, fmt.Errorf("failed to clone repository to temp location: %w", ctx.Err())
+		}
+		lastErr = err
+		if attempt == attempts {
+			break
+		}
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Clone attempt %d of %d failed (%v); retrying in %s", attempt, attempts, err, backoff))
+		select {
+		case <-ctx.Done():
+			return nil, "", nil, fmt.Errorf("failed to clone repository to temp location: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Shallow clone failed (%v); retrying with full clone", lastErr))
+	repoPath, cleanup := newTempRepoDir(ctx, sessionKey, cfg.WorkspaceBase)
+	repo, err := cloneWithTimeout(ctx, cfg.CloneTimeout, repoPath, cleanup, &git.CloneOptions{URL: cfg.RepoRoot, Progress: nil})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to clone repository to temp location: %w", err)
+	}
+	return repo, repoPath, cleanup, nil
+}
+
+// cloneWithTimeout clones into repoPath, giving up after timeout when it is
+// positive. A failed clone's directory is removed with cleanup; after a
+// timeout that happens once the abandoned clone returns, since go-git does
+// not notice cancellation while blocked on the file system.
+func cloneWithTimeout(ctx context.Context, timeout time.Duration, repoPath string, cleanup func(), opts *git.CloneOptions) (*git.Repository, error) {
+	cloneCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		cloneCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	type cloneResult struct {
+		repo *git.Repository
+		err  error
+	}
+	done := make(chan cloneResult, 1)
+	go func() {
+		repo, err := plainCloneContext(cloneCtx, repoPath, false, opts)
+		done <- cloneResult{repo: repo, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			cleanup()
+		}
+		return res.repo, res.err
+	case <-cloneCtx.Done():
+		go func() {
+			<-done
+			cleanup()
+		}()
+		if ctx.Err() == nil {
+			return nil, fmt.Errorf("clone timed out after %s", timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// finishTempDocWorkspace resolves the docs path inside a freshly prepared workspace
+// and copies the .narrabyte instructions into it.
+func finishTempDocWorkspace(ctx context.Context, sessionKey string, cfg *docRepoConfig, branch string, repoPath string, cleanup func(), sharedStore bool) (tempDocWorkspace, func(), error) {
 	tempDocsPath := repoPath
 	if cfg.DocsRelative != "." {
 		tempDocsPath = filepath.Join(repoPath, cfg.DocsRelative)
@@ -1073,7 +2555,7 @@ func createTempDocWorkspace(ctx context.Context, sessionKey string, cfg *docRepo
 	}
 
 	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Temporary docs workspace ready: branch '%s' at %s", branch, repoPath))
-	return tempDocWorkspace{repoPath: repoPath, docsPath: tempDocsPath}, cleanup, nil
+	return tempDocWorkspace{repoPath: repoPath, docsPath: tempDocsPath, sharedStore: sharedStore}, cleanup, nil
 }
 
 // createTempDocRepoAtBranchHead clones the documentation repository into a temp directory
@@ -1082,6 +2564,36 @@ func createTempDocRepoAtBranchHead(ctx context.Context, sessionKey string, cfg *
 	return createTempDocWorkspace(ctx, sessionKey, cfg, branch, baseBranch, baseHash, true)
 }
 
+// resolveRunModelKey returns modelKey, or when it is empty the project's
+// default model. A project default that is no longer available is replaced by
+// the preferred enabled model of its provider.
+func (s *ClientService) resolveRunModelKey(projectID uint, modelKey string) (string, error) {
+	if modelKey = strings.TrimSpace(modelKey); modelKey != "" {
+		return modelKey, nil
+	}
+	project, err := s.repoLinks.Get(projectID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load project: %w", err)
+	}
+	defaultKey := ""
+	if project != nil {
+		defaultKey = strings.TrimSpace(project.DefaultModelKey)
+	}
+	if defaultKey == "" {
+		return "", fmt.Errorf("model is required: project %d has no default model", projectID)
+	}
+	if s.modelConfigs != nil {
+		if model, err := s.modelConfigs.GetModel(defaultKey); err == nil && model != nil && model.Enabled {
+			return defaultKey, nil
+		}
+	}
+	provider, _, _ := strings.Cut(defaultKey, ":")
+	if fallback, err := s.findDefaultModelForProvider(provider); err == nil && fallback != nil {
+		return fallback.Key, nil
+	}
+	return "", fmt.Errorf("model is required: the project default model %s is not available and %s has no enabled models", defaultKey, provider)
+}
+
 func (s *ClientService) findDefaultModelForProvider(provider string) (*models.LLMModel, error) {
 	if s.modelConfigs == nil {
 		return nil, fmt.Errorf("model configuration service not available")
@@ -1098,6 +2610,7 @@ func (s *ClientService) findDefaultModelForProvider(provider string) (*models.LL
 		if strings.TrimSpace(group.ProviderID) != provider {
 			continue
 		}
+		// Models are already ordered by priority, so the first enabled one is the preferred default
 		sorted := group.Models
 		for i := range sorted {
 			if sorted[i].Enabled {
@@ -1143,36 +2656,14 @@ func (s *ClientService) CommitDocs(projectID uint, sessionID uint, files []strin
 		return fmt.Errorf("project not found")
 	}
 
-	docRepoPath := strings.TrimSpace(project.DocumentationRepo)
-	if docRepoPath == "" {
-		return fmt.Errorf("project documentation repository is not configured")
-	}
-	if !utils.DirectoryExists(docRepoPath) {
-		return fmt.Errorf("documentation repository path does not exist: %s", docRepoPath)
-	}
-	if !utils.HasGitRepo(docRepoPath) {
-		return fmt.Errorf("documentation repository is not a git repository: %s", docRepoPath)
+	if project.FilesystemTarget {
+		return fmt.Errorf("documentation is written directly to a directory; commit the files there yourself")
 	}
 
-	codeRepoRoot := ""
-	if codeRepoPath := strings.TrimSpace(project.CodebaseRepo); codeRepoPath != "" {
-		if utils.DirectoryExists(codeRepoPath) && utils.HasGitRepo(codeRepoPath) {
-			if abs, absErr := filepath.Abs(codeRepoPath); absErr == nil {
-				if root, ok := utils.FindGitRepoRoot(abs); ok {
-					codeRepoRoot = root
-				}
-			}
-		}
-	}
-	docCfg, err := newDocRepoConfig(docRepoPath, codeRepoRoot)
+	repo, docCfg, err := s.openProjectDocRepo(project)
 	if err != nil {
 		return err
 	}
-
-	repo, err := s.gitService.Open(docCfg.RepoRoot)
-	if err != nil {
-		return fmt.Errorf("failed to open documentation repository: %w", err)
-	}
 	worktree, err := repo.Worktree()
 	if err != nil {
 		return fmt.Errorf("failed to load documentation worktree: %w", err)
@@ -1220,6 +2711,17 @@ func (s *ClientService) CommitDocs(projectID uint, sessionID uint, files []strin
 		return fmt.Errorf("no documentation changes found to commit")
 	}
 
+	message := fmt.Sprintf("Add documentation for %s", docsBranch)
+	if s.workspaceSettings().DetachedDocCommits {
+		if head, err := repo.Head(); err == nil && head.Name() == refName {
+			if err := s.commitDocsDetached(ctx, sessionKey, repo, docCfg, docsBranch, normalized, message); err != nil {
+				return err
+			}
+			s.updateDocsSubmodulePointer(ctx, sessionKey, repo, docCfg, docsBranch)
+			return nil
+		}
+	}
+
 	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
 		"CommitDocs: staging %d documentation file(s) for branch '%s'",
 		len(normalized), docsBranch,
@@ -1229,17 +2731,391 @@ func (s *ClientService) CommitDocs(projectID uint, sessionID uint, files []strin
 		return fmt.Errorf("failed to stage documentation changes: %w", err)
 	}
 
-	message := fmt.Sprintf("Add documentation for %s", docsBranch)
 	if _, err := s.gitService.Commit(repo, message); err != nil {
+		// Leave the index as it was rather than half-staged for the next attempt.
+		if unstageErr := s.gitService.Unstage(repo, normalized); unstageErr != nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("CommitDocs: failed to unstage documentation changes: %v", unstageErr))
+		}
+		return fmt.Errorf("failed to commit documentation changes: %w", err)
+	}
+
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
+		"CommitDocs: committed documentation updates to '%s'",
+		docsBranch,
+	))
+	s.updateDocsSubmodulePointer(ctx, sessionKey, repo, docCfg, docsBranch)
+
+	return nil
+}
+
+// updateDocsSubmodulePointer points the superproject of a documentation
+// submodule at the head of docsBranch once docs were committed there, when
+// UpdateSubmodulePointer is enabled. The documentation commit already
+// succeeded, so a failure here is only reported.
+func (s *ClientService) updateDocsSubmodulePointer(ctx context.Context, sessionKey string, repo *git.Repository, docCfg *docRepoConfig, docsBranch string) {
+	if docCfg.SubmodulePath == "" || !s.workspaceSettings().UpdateSubmodulePointer {
+		return
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(docsBranch), true)
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("CommitDocs: failed to resolve '%s' to update the submodule pointer: %v", docsBranch, err))
+		return
+	}
+	superRepo, err := s.gitService.Open(docCfg.SuperprojectRoot)
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("CommitDocs: failed to open the superproject: %v", err))
+		return
+	}
+	hash, err := s.gitService.UpdateSubmodulePointer(superRepo, docCfg.SubmodulePath, ref.Hash().String())
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("CommitDocs: failed to update submodule '%s' in the superproject: %v", docCfg.SubmodulePath, err))
+		return
+	}
+	if !hash.IsZero() {
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
+			"CommitDocs: updated submodule '%s' in the superproject to %s",
+			docCfg.SubmodulePath, ref.Hash().String()[:8],
+		))
+	}
+}
+
+// commitDocsDetached commits files from the main worktree inside a temp
+// workspace checked out to docsBranch, then moves the branch to the new
+// commit. HEAD and the working files are never touched and only the index
+// entries of the committed files are updated, so unrelated local edits on the
+// checked-out docs branch stay as they were.
+func (s *ClientService) commitDocsDetached(ctx context.Context, sessionKey string, repo *git.Repository, docCfg *docRepoConfig, docsBranch string, files []string, message string) error {
+	refName := plumbing.NewBranchReferenceName(docsBranch)
+	oldRef, err := repo.Reference(refName, true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve documentation branch '%s': %w", docsBranch, err)
+	}
+
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
+		"CommitDocs: committing %d documentation file(s) to '%s' in a temporary workspace",
+		len(files), docsBranch,
+	))
+	workspace, cleanup, err := createTempDocRepoAtBranchHead(ctx, sessionKey, docCfg, docsBranch, "", plumbing.ZeroHash)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	for _, rel := range files {
+		if err := copyWorktreeFile(filepath.Join(docCfg.RepoRoot, rel), filepath.Join(workspace.repoPath, rel)); err != nil {
+			return fmt.Errorf("failed to copy '%s' into temporary workspace: %w", rel, err)
+		}
+	}
+	tempRepo, err := openTempDocRepo(workspace)
+	if err != nil {
+		return fmt.Errorf("failed to open temp repository: %w", err)
+	}
+	if err := s.gitService.StageFiles(tempRepo, files); err != nil {
+		return fmt.Errorf("failed to stage documentation changes: %w", err)
+	}
+	commitHash, err := s.gitService.Commit(tempRepo, message)
+	if err != nil {
 		return fmt.Errorf("failed to commit documentation changes: %w", err)
 	}
 
-	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
-		"CommitDocs: committed documentation updates to '%s'",
-		docsBranch,
-	))
+	if !workspace.sharedStore {
+		if err := transferGitObjects(ctx, sessionKey, tempRepo, repo, commitHash); err != nil {
+			return fmt.Errorf("failed to transfer git objects to main repository: %w", err)
+		}
+	}
+	if err := verifyCommitComplete(ctx, sessionKey, repo, commitHash); err != nil {
+		return fmt.Errorf("documentation commit is incomplete in main repository; branch '%s' was not updated: %w", docsBranch, err)
+	}
+	// Refuse to move the branch if it changed while the commit was being made
+	if err := repo.Storer.CheckAndSetReference(plumbing.NewHashReference(refName, commitHash), oldRef); err != nil {
+		return fmt.Errorf("failed to update branch '%s': %w", docsBranch, err)
+	}
+	// The branch is checked out, so without this the index would still hold
+	// the old content and the next commit would revert the documentation
+	if err := syncIndexToCommit(repo, docCfg.RepoRoot, commitHash, files); err != nil {
+		return fmt.Errorf("documentation was committed to '%s' but the index could not be updated: %w", docsBranch, err)
+	}
+
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
+		"CommitDocs: committed documentation updates to '%s'",
+		docsBranch,
+	))
+	return nil
+}
+
+// syncIndexToCommit points the index entries of files at their content in
+// commit, taking the stat data from the working files under root, and drops
+// the entries of files the commit deleted.
+func syncIndexToCommit(repo *git.Repository, root string, commitHash plumbing.Hash, files []string) error {
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return fmt.Errorf("failed to load commit %s: %w", commitHash.String(), err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to load tree: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	for _, file := range files {
+		name := filepath.ToSlash(file)
+		treeEntry, err := tree.FindEntry(name)
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			if _, err := idx.Remove(name); err != nil && !errors.Is(err, index.ErrEntryNotFound) {
+				return fmt.Errorf("failed to remove '%s' from the index: %w", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read '%s' from commit: %w", name, err)
+		}
+		entry, err := idx.Entry(name)
+		if errors.Is(err, index.ErrEntryNotFound) {
+			entry = idx.Add(name)
+		} else if err != nil {
+			return fmt.Errorf("failed to read index entry for '%s': %w", name, err)
+		}
+		entry.Hash = treeEntry.Hash
+		entry.Mode = treeEntry.Mode
+		if info, err := os.Lstat(filepath.Join(root, file)); err == nil {
+			entry.ModifiedAt = info.ModTime()
+			entry.Size = uint32(info.Size())
+		}
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// copyWorktreeFile mirrors src at dst, keeping its permissions; a missing src
+// removes dst so deletions are committed too.
+func copyWorktreeFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chmod(dst, info.Mode().Perm())
+}
+
+// DiscardStagedDocs unstages the documentation changes a session made that are
+// staged in its documentation repository, leaving the files themselves as they
+// are. It is the cancel path of the commit workflow.
+func (s *ClientService) DiscardStagedDocs(sessionID uint) error {
+	ctx := s.context
+	if ctx == nil {
+		return fmt.Errorf("client service not initialized")
+	}
+	if sessionID == 0 {
+		return fmt.Errorf("session id is required")
+	}
+
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	if session == nil {
+		return fmt.Errorf("session not found: %d", sessionID)
+	}
+	project, err := s.repoLinks.Get(session.ProjectID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return fmt.Errorf("project not found")
+	}
+	if project.FilesystemTarget {
+		return fmt.Errorf("documentation is written directly to a directory; nothing is staged")
+	}
+
+	repo, docCfg, err := s.openProjectDocRepo(project)
+	if err != nil {
+		return err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to load documentation worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to read documentation repo status: %w", err)
+	}
+
+	// Only the session's pages are unstaged, so other staged changes survive even
+	// when the docs are the root of the code repository
+	docFiles, err := s.sessionDocFiles(repo, project, docCfg, session)
+	if err != nil {
+		return err
+	}
+	var staged []string
+	for path, st := range status {
+		if st.Staging == git.Unmodified || st.Staging == git.Untracked {
+			continue
+		}
+		rel := filepath.ToSlash(path)
+		if !docFiles[rel] {
+			continue
+		}
+		staged = append(staged, rel)
+	}
+	if len(staged) == 0 {
+		return nil
+	}
+	sort.Strings(staged)
+
+	if err := s.gitService.Unstage(repo, staged); err != nil {
+		return fmt.Errorf("failed to unstage documentation changes: %w", err)
+	}
+	emitSessionInfo(ctx, makeSessionKey(sessionID), fmt.Sprintf(
+		"DiscardStagedDocs: unstaged %d documentation file(s)", len(staged),
+	))
+	return nil
+}
+
+// sessionDocFiles returns the repository-relative paths under the docs
+// directory that the session's docs branch changes against its base.
+func (s *ClientService) sessionDocFiles(repo *git.Repository, project *models.RepoLink, docCfg *docRepoConfig, session *models.GenerationSession) (map[string]bool, error) {
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+	if docsBranch == "" {
+		return nil, fmt.Errorf("session %d has no documentation branch", session.ID)
+	}
+	baseBranch := strings.TrimSpace(session.SourceBranch)
+	if !docCfg.SharedWithCode {
+		var err error
+		if _, baseBranch, err = resolveDocumentationBase(project, repo); err != nil {
+			return nil, err
+		}
+	}
+	stats, err := s.gitService.DiffStat(repo, baseBranch, docsBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the session's documentation changes: %w", err)
+	}
+	prefix := filepath.ToSlash(filepath.Clean(docCfg.DocsRelative))
+	if prefix == "." {
+		prefix = ""
+	}
+	files := make(map[string]bool, len(stats))
+	for _, stat := range stats {
+		if path := filepath.ToSlash(stat.Path); hasPathPrefix(path, prefix) {
+			files[path] = true
+		}
+	}
+	return files, nil
+}
+
+// DocFileAtBase returns a documentation file of a session as it is on the
+// branch its docs are compared against, so reviews can show the "before"
+// version of a page. path is repository-relative, as in the session's changed
+// files. It returns nil when the file does not exist there yet.
+func (s *ClientService) DocFileAtBase(sessionID uint, path string) (*models.FileAtRef, error) {
+	if sessionID == 0 {
+		return nil, fmt.Errorf("session id is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found: %d", sessionID)
+	}
+	project, err := s.repoLinks.Get(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found")
+	}
+	if project.FilesystemTarget {
+		return nil, fmt.Errorf("documentation is written directly to a directory; there is no base version")
+	}
+
+	repo, docCfg, err := s.openProjectDocRepo(project)
+	if err != nil {
+		return nil, err
+	}
+	baseHash, err := s.sessionDocsBase(session, project, repo, docCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.gitService.FileAtRef(repo, baseHash.String(), path)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// sessionDocsBase resolves the commit a session's docs branch is compared
+// against: the source branch when docs live in the code repository, the
+// documentation base branch otherwise.
+func (s *ClientService) sessionDocsBase(session *models.GenerationSession, project *models.RepoLink, repo *git.Repository, docCfg *docRepoConfig) (plumbing.Hash, error) {
+	if docCfg.SharedWithCode {
+		sourceBranch := strings.TrimSpace(session.SourceBranch)
+		ref, err := s.gitService.ResolveRef(repo, sourceBranch)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to resolve source branch '%s': %w", sourceBranch, err)
+		}
+		return ref.Hash, nil
+	}
+	baseHash, _, err := resolveDocumentationBase(project, repo)
+	return baseHash, err
+}
+
+// openProjectDocRepo opens the git repository holding the documentation of a
+// project.
+func (s *ClientService) openProjectDocRepo(project *models.RepoLink) (*git.Repository, *docRepoConfig, error) {
+	docRepoPath := strings.TrimSpace(project.DocumentationRepo)
+	if docRepoPath == "" {
+		return nil, nil, fmt.Errorf("project documentation repository is not configured")
+	}
+	if !utils.DirectoryExists(docRepoPath) {
+		return nil, nil, fmt.Errorf("documentation repository path does not exist: %s", docRepoPath)
+	}
+	if !utils.HasGitRepo(docRepoPath) {
+		return nil, nil, fmt.Errorf("documentation repository is not a git repository: %s", docRepoPath)
+	}
+
+	codeRepoRoot := ""
+	if codeRepoPath := strings.TrimSpace(project.CodebaseRepo); codeRepoPath != "" {
+		if utils.DirectoryExists(codeRepoPath) && utils.HasGitRepo(codeRepoPath) {
+			if abs, absErr := filepath.Abs(codeRepoPath); absErr == nil {
+				if root, ok := utils.FindGitRepoRoot(abs); ok {
+					codeRepoRoot = root
+				}
+			}
+		}
+	}
+	docCfg, err := newDocRepoConfig(docRepoPath, codeRepoRoot)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return nil
+	repo, err := s.gitService.Open(docCfg.RepoRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open documentation repository: %w", err)
+	}
+	return repo, docCfg, nil
 }
 
 func (s *ClientService) LoadGenerationSession(sessionID uint) (*models.DocGenerationResult, error) {
@@ -1291,6 +3167,22 @@ func (s *ClientService) LoadGenerationSession(sessionID uint) (*models.DocGenera
 		project.ProjectName, targetBranch, sourceBranch,
 	))
 
+	if project.FilesystemTarget {
+		// Changes were written straight to the directory; there is nothing to diff
+		summary := restoredSummary(session, runtime)
+		emitSessionInfo(ctx, sessionKey, "LoadSession: session restored successfully")
+		return &models.DocGenerationResult{
+			SessionID:        sessionID,
+			SessionKey:       sessionKey,
+			Branch:           sourceBranch,
+			TargetBranch:     targetBranch,
+			FilesystemTarget: true,
+			Files:            []models.DocChangedFile{},
+			Summary:          summary,
+			ChatMessages:     parseChatMessagesJSON(session.ChatMessagesJSON),
+		}, nil
+	}
+
 	codeRepoPath := strings.TrimSpace(project.CodebaseRepo)
 	docRepoPath := strings.TrimSpace(project.DocumentationRepo)
 	if codeRepoPath == "" || docRepoPath == "" {
@@ -1340,26 +3232,25 @@ func (s *ClientService) LoadGenerationSession(sessionID uint) (*models.DocGenera
 		}
 	}
 
-	docWorktree, err := docRepo.Worktree()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load documentation worktree: %w", err)
-	}
-	docStatus, err := docWorktree.Status()
+	docStatus, err := s.gitService.StatusSummary(docRepo, docCfg.DocsRelative)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read documentation repo status: %w", err)
 	}
+	files := docStatus.Files
 
-	files := collectDocChangedFiles(docStatus, docCfg.DocsRelative)
-
-	docDiff, err := s.gitService.DiffBetweenBranches(docRepo, baseBranch, docsBranch)
+	docDiff, diffStat, err := s.documentationDiff(docRepo, baseBranch, docsBranch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate documentation diff: %w", err)
+		return nil, err
 	}
-
-	summary := ""
-	if runtime.client != nil {
-		summary = strings.TrimSpace(runtime.client.LastAssistantMessage())
+	pendingDiff := ""
+	if !docStatus.Clean && docsBranchCheckedOut(docRepo, docsBranch) {
+		pendingDiff, err = s.gitService.CompareWorktreeToBranch(docRepo, docsBranch, docCfg.DocsRelative)
+		if err != nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("LoadSession: unable to diff uncommitted documentation edits: %v", err))
+		}
 	}
+
+	summary := restoredSummary(session, runtime)
 	if summary == "" {
 		summary = "Restored from previous session"
 	}
@@ -1377,11 +3268,39 @@ func (s *ClientService) LoadGenerationSession(sessionID uint) (*models.DocGenera
 		DocsInCodeRepo: docCfg.SharedWithCode,
 		Files:          files,
 		Diff:           docDiff,
+		PendingDiff:    pendingDiff,
+		DiffStat:       diffStat,
 		Summary:        summary,
 		ChatMessages:   chatMessages,
+		Paused:         session.Paused,
+		FullBootstrap:  session.FullBootstrap,
+		FromWorktree:   session.FromWorktree,
 	}, nil
 }
 
+// restoredSummary returns the summary to show for a reloaded session: the
+// one recorded for its latest run, or for sessions saved before summaries
+// were recorded, the last assistant message of the restored conversation.
+func restoredSummary(session *models.GenerationSession, runtime *sessionRuntime) string {
+	if summary := strings.TrimSpace(session.LastSummary); summary != "" {
+		return summary
+	}
+	if runtime != nil && runtime.client != nil {
+		return strings.TrimSpace(runtime.client.LastAssistantMessage())
+	}
+	return ""
+}
+
+// docsBranchCheckedOut reports whether docsBranch is the branch checked out
+// in the documentation repository, so its worktree edits belong to it.
+func docsBranchCheckedOut(docRepo *git.Repository, docsBranch string) bool {
+	head, err := docRepo.Head()
+	return err == nil && head.Name() == plumbing.NewBranchReferenceName(docsBranch)
+}
+
+// StopStream cancels the active run of a session. A run canceled while the
+// model is working keeps its partial history and documentation changes, as
+// a paused run does, so ResumeCanceledRun can continue it.
 func (s *ClientService) StopStream(sessionID uint, sessionKeyOverride string) {
 	if s == nil || sessionID == 0 {
 		return
@@ -1391,13 +3310,188 @@ func (s *ClientService) StopStream(sessionID uint, sessionKeyOverride string) {
 	if !ok || runtime == nil || runtime.client == nil {
 		return
 	}
-	wasRunning := runtime.client.IsRunning()
-	runtime.client.StopStream()
-	if wasRunning && s.context != nil {
+	// A run in flight is canceled rather than torn down, so it can save its
+	// progress for ResumeCanceledRun as it unwinds; its own deferred
+	// StopStream then clears the session
+	if !runtime.client.CancelStream() {
+		runtime.client.StopStream()
+		return
+	}
+	if s.context != nil {
 		emitSessionWarn(s.context, sessionKey, "Cancel requested: stopping LLM session")
 	}
 }
 
+// resumeInstruction is sent as the refinement request when a paused run is resumed.
+const resumeInstruction = "The previous run was paused before it finished. Review your todo list and the documentation changes made so far, then continue the documentation work from where you left off."
+
+// resumeCanceledInstruction is sent instead when the run was canceled.
+const resumeCanceledInstruction = "The previous run was canceled before it finished. Review your todo list and the documentation changes made so far, then continue the documentation work from where it stopped."
+
+// PauseSession interrupts the active run of a session without discarding its
+// progress. The run unwinds through persistPausedRun, which commits the
+// workspace to the docs branch and stores the partial conversation history
+// and todo list on the session row.
+//
+// State that survives an app restart: docs branch commits, conversation
+// history, the todo list, the parts a chunked full bootstrap has left and
+// the paused flag. State kept only in memory: the
+// runtime's read-before-write history, which is reset when the run resumes in
+// a fresh workspace cloned at the docs branch head.
+func (s *ClientService) PauseSession(sessionID uint, sessionKeyOverride string) error {
+	if s.context == nil {
+		return fmt.Errorf("client service not initialized")
+	}
+	if sessionID == 0 {
+		return fmt.Errorf("session id is required")
+	}
+	sessionKey := resolveSessionKey(sessionKeyOverride, sessionID)
+	runtime, ok := s.getSessionRuntime(sessionKey)
+	if !ok || runtime == nil || runtime.client == nil || !runtime.client.PauseStream() {
+		return fmt.Errorf("session %d has no active run to pause", sessionID)
+	}
+	emitSessionWarn(s.context, sessionKey, "Pause requested: saving LLM session progress")
+	return nil
+}
+
+// ResumeSession continues a paused session from its docs branch head,
+// restoring the stored conversation history and todo list.
+func (s *ClientService) ResumeSession(sessionID uint, sessionKeyOverride string) (*models.DocGenerationResult, error) {
+	return s.resumeSavedRun(sessionID, sessionKeyOverride, "")
+}
+
+// ResumeCanceledRun continues a run stopped with StopStream from the progress
+// it saved: its docs branch head, partial conversation history and todo list.
+// A non-empty instruction is passed to the model with the request to
+// continue, e.g. to correct course before the run picks up again.
+func (s *ClientService) ResumeCanceledRun(sessionID uint, instruction string) (*models.DocGenerationResult, error) {
+	return s.resumeSavedRun(sessionID, "", instruction)
+}
+
+// resumeSavedRun continues a session from the progress its paused or canceled
+// run saved. The prompt tells the model how the run stopped, with instruction
+// appended when set.
+func (s *ClientService) resumeSavedRun(sessionID uint, sessionKeyOverride string, instruction string) (*models.DocGenerationResult, error) {
+	if sessionID == 0 {
+		return nil, fmt.Errorf("session id is required")
+	}
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found: %d", sessionID)
+	}
+	if !session.Paused {
+		return nil, fmt.Errorf("session %d has no saved progress to resume", sessionID)
+	}
+	prompt := resumeInstruction
+	if session.Canceled {
+		prompt = resumeCanceledInstruction
+	}
+	if extra := strings.TrimSpace(instruction); extra != "" {
+		prompt += "\n\nWhile continuing, also follow this instruction: " + extra
+	}
+	return s.refineDocs(sessionID, prompt, sessionKeyOverride, true, nil)
+}
+
+// persistCanceledRun saves the progress of a run stopped with StopStream the
+// same way persistPausedRun does, then reports the cancellation so callers
+// still see the run end as canceled.
+func (s *ClientService) persistCanceledRun(ctx context.Context, sessionKey string, session *models.GenerationSession, runtime *sessionRuntime, workspace tempDocWorkspace, docRepo *git.Repository, docCfg *docRepoConfig, baseHash plumbing.Hash, carryEdits func() error) error {
+	if _, err := s.savePartialRun(ctx, sessionKey, session, runtime, workspace, docRepo, docCfg.DocsRelative, baseHash, true, carryEdits); err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to save progress of the canceled run: %v", err))
+		return context.Canceled
+	}
+	emitSessionInfo(ctx, sessionKey, "Session canceled: progress saved")
+	return fmt.Errorf("run canceled, progress saved: %w", context.Canceled)
+}
+
+// persistPausedRun saves the progress of a run interrupted by PauseSession:
+// workspace changes are propagated to the docs branch and the partial history
+// and todo list are stored so ResumeSession can pick up after a restart.
+// carryEdits, when set, merges manual edits of a regenerated docs branch into
+// the workspace; see propagateDocChangesWithEdits.
+func (s *ClientService) persistPausedRun(ctx context.Context, sessionKey string, session *models.GenerationSession, runtime *sessionRuntime, workspace tempDocWorkspace, docRepo *git.Repository, docCfg *docRepoConfig, baseBranch string, baseHash plumbing.Hash, carryEdits func() error) (*models.DocGenerationResult, error) {
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+
+	files, err := s.savePartialRun(ctx, sessionKey, session, runtime, workspace, docRepo, docCfg.DocsRelative, baseHash, false, carryEdits)
+	if err != nil {
+		return nil, err
+	}
+
+	docDiff, diffStat, err := s.documentationDiff(docRepo, baseBranch, docsBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	emitSessionInfo(ctx, sessionKey, "Session paused: progress saved")
+
+	summary := strings.TrimSpace(runtime.client.LastAssistantMessage())
+	if summary == "" {
+		summary = "Paused before completion"
+	}
+	targetBranch := strings.TrimSpace(session.TargetBranch)
+	if targetBranch == "" {
+		targetBranch = baseBranch
+	}
+	return &models.DocGenerationResult{
+		SessionID:      session.ID,
+		SessionKey:     sessionKey,
+		Branch:         strings.TrimSpace(session.SourceBranch),
+		TargetBranch:   targetBranch,
+		DocsBranch:     docsBranch,
+		DocsInCodeRepo: docCfg.SharedWithCode,
+		Files:          files,
+		Diff:           docDiff,
+		DiffStat:       diffStat,
+		Summary:        summary,
+		ChatMessages:   parseChatMessagesJSON(session.ChatMessagesJSON),
+		Paused:         true,
+	}, nil
+}
+
+// savePartialRun commits the workspace of an interrupted run to the docs
+// branch and stores the run's partial history and todo list on the session,
+// flagging it as paused, and canceled when it was stopped, so it can be
+// resumed.
+func (s *ClientService) savePartialRun(ctx context.Context, sessionKey string, session *models.GenerationSession, runtime *sessionRuntime, workspace tempDocWorkspace, docRepo *git.Repository, docsRelative string, baseHash plumbing.Hash, canceled bool, carryEdits func() error) ([]models.DocChangedFile, error) {
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+
+	files, err := propagateDocChangesWithEdits(ctx, sessionKey, workspace, docRepo, docsBranch, docsRelative, carryEdits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save paused documentation changes: %w", err)
+	}
+	if _, err := s.ensureDocsBranchExists(docRepo, docsBranch, baseHash); err != nil {
+		return nil, fmt.Errorf("failed to prepare documentation branch '%s': %w", docsBranch, err)
+	}
+
+	updates := map[string]interface{}{
+		"paused":   true,
+		"canceled": canceled,
+	}
+	if jsonStr, err := runtime.client.ConversationHistoryJSON(); err == nil {
+		updates["messages_json"] = jsonStr
+	}
+	if todosJSON, err := runtime.client.TodosJSON(); err == nil {
+		updates["todos_json"] = todosJSON
+	} else {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to capture todo list: %v", err))
+	}
+	if progressJSON, err := runtime.client.BootstrapProgressJSON(); err == nil {
+		updates["bootstrap_progress_json"] = progressJSON
+	} else {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to capture bootstrap progress: %v", err))
+	}
+	if strings.TrimSpace(session.ChatMessagesJSON) == "" {
+		updates["chat_messages_json"] = "[]"
+	}
+	if err := s.generationSessions.UpdateByID(session.ID, updates); err != nil {
+		return nil, fmt.Errorf("failed to save paused session: %w", err)
+	}
+	return files, nil
+}
+
 // BindSessionToTab marks a session as bound to a UI tab
 func (s *ClientService) BindSessionToTab(sessionID uint) error {
 	if sessionID == 0 {
@@ -1432,18 +3526,23 @@ func (s *ClientService) IsSessionInTab(sessionID uint) bool {
 
 // SessionInfo represents information about a generation session
 type SessionInfo struct {
-	ID           uint   `json:"id"`
-	SessionKey   string `json:"sessionKey"`
-	ProjectID    uint   `json:"projectId"`
-	SourceBranch string `json:"sourceBranch"`
-	TargetBranch string `json:"targetBranch"`
-	ModelKey     string `json:"modelKey"`
-	Provider     string `json:"provider"`
-	DocsBranch   string `json:"docsBranch"`
-	InTab        bool   `json:"inTab"`
-	IsRunning    bool   `json:"isRunning"`
-	CreatedAt    string `json:"createdAt"`
-	UpdatedAt    string `json:"updatedAt"`
+	ID            uint   `json:"id"`
+	SessionKey    string `json:"sessionKey"`
+	ProjectID     uint   `json:"projectId"`
+	SourceBranch  string `json:"sourceBranch"`
+	TargetBranch  string `json:"targetBranch"`
+	ModelKey      string `json:"modelKey"`
+	Provider      string `json:"provider"`
+	DocsBranch    string `json:"docsBranch"`
+	InTab         bool   `json:"inTab"`
+	IsRunning     bool   `json:"isRunning"`
+	FullBootstrap bool   `json:"fullBootstrap"`
+	FromWorktree  bool   `json:"fromWorktree"`
+	// Status is one of the SessionStatus values, derived from the session's
+	// runtime and its docs branch when the sessions are listed
+	Status    string `json:"status"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
 }
 
 // GetAvailableTabSessions returns sessions for a project
@@ -1458,8 +3557,6 @@ func (s *ClientService) GetAvailableTabSessions(projectID uint) ([]SessionInfo,
 	}
 
 	s.sessionMu.RLock()
-	defer s.sessionMu.RUnlock()
-
 	availableSessions := make([]SessionInfo, 0)
 	for _, session := range sessions {
 		sessionKey := makeSessionKey(session.ID)
@@ -1476,21 +3573,25 @@ func (s *ClientService) GetAvailableTabSessions(projectID uint) ([]SessionInfo,
 		docsBranch := strings.TrimSpace(session.DocsBranch)
 
 		availableSessions = append(availableSessions, SessionInfo{
-			ID:           session.ID,
-			SessionKey:   sessionKey,
-			ProjectID:    projectID,
-			SourceBranch: strings.TrimSpace(session.SourceBranch),
-			TargetBranch: strings.TrimSpace(session.TargetBranch),
-			ModelKey:     strings.TrimSpace(session.ModelKey),
-			Provider:     strings.TrimSpace(session.Provider),
-			DocsBranch:   docsBranch,
-			InTab:        inTab,
-			IsRunning:    isRunning,
-			CreatedAt:    session.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:    session.UpdatedAt.Format(time.RFC3339),
+			ID:            session.ID,
+			SessionKey:    sessionKey,
+			ProjectID:     projectID,
+			SourceBranch:  strings.TrimSpace(session.SourceBranch),
+			TargetBranch:  strings.TrimSpace(session.TargetBranch),
+			ModelKey:      strings.TrimSpace(session.ModelKey),
+			Provider:      strings.TrimSpace(session.Provider),
+			DocsBranch:    docsBranch,
+			InTab:         inTab,
+			IsRunning:     isRunning,
+			FullBootstrap: session.FullBootstrap,
+			FromWorktree:  session.FromWorktree,
+			CreatedAt:     session.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:     session.UpdatedAt.Format(time.RFC3339),
 		})
 	}
+	s.sessionMu.RUnlock()
 
+	s.setSessionStatuses(projectID, availableSessions)
 	return availableSessions, nil
 }
 
@@ -1554,63 +3655,151 @@ func resolveDocumentationBase(project *models.RepoLink, repo *git.Repository) (p
 	}
 	hash, err := resolveBranchHash(repo, branch)
 	if err != nil {
+		if _, refErr := repo.Reference(plumbing.NewBranchReferenceName(branch), true); errors.Is(refErr, plumbing.ErrReferenceNotFound) {
+			// Offer to create the branch from HEAD; see CreateDocumentationBaseBranch
+			if from := suggestedBaseBranchStart(repo); from != "" {
+				return plumbing.Hash{}, "", fmt.Errorf("ERR_DOCS_BASE_BRANCH_MISSING_SUGGEST:%s:%s", branch, from)
+			}
+			return plumbing.Hash{}, "", fmt.Errorf("ERR_DOCS_BASE_BRANCH_MISSING:%s", branch)
+		}
 		return plumbing.Hash{}, "", fmt.Errorf("failed to resolve documentation base branch '%s': %w", branch, err)
 	}
 	return hash, branch, nil
 }
 
-func ensureDocsBranchExists(repo *git.Repository, branch string, baseHash plumbing.Hash) (bool, error) {
+// suggestedBaseBranchStart names what a missing documentation base branch
+// can be created from: the checked-out branch, or HEAD when it is detached.
+// It is empty when the repository has no commits.
+func suggestedBaseBranchStart(repo *git.Repository) string {
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	if head.Name().IsBranch() {
+		return head.Name().Short()
+	}
+	return "HEAD"
+}
+
+// ensureDocsBranchExists creates branch at baseHash unless it already exists
+// and reports whether it was created.
+func (s *ClientService) ensureDocsBranchExists(repo *git.Repository, branch string, baseHash plumbing.Hash) (bool, error) {
 	if repo == nil {
 		return false, fmt.Errorf("documentation repository is required")
 	}
-	branch = strings.TrimSpace(branch)
-	if branch == "" {
-		return false, fmt.Errorf("branch name is required")
-	}
-	refName := plumbing.NewBranchReferenceName(branch)
-	if _, err := repo.Reference(refName, true); err == nil {
-		return false, nil
-	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+	exists, err := s.gitService.BranchExists(repo, branch)
+	if err != nil {
 		return false, fmt.Errorf("failed to resolve documentation branch '%s': %w", branch, err)
 	}
-
-	if baseHash == plumbing.ZeroHash {
-		return false, fmt.Errorf("cannot create documentation branch '%s': base hash is empty", branch)
+	if exists {
+		return false, nil
 	}
-
-	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, baseHash)); err != nil {
-		return false, fmt.Errorf("failed to create documentation branch '%s': %w", branch, err)
+	if err := s.gitService.CreateBranch(repo, branch, baseHash, false); err != nil {
+		return false, fmt.Errorf("failed to create documentation branch: %w", err)
 	}
-
 	return true, nil
 }
 
-func extractPathsFromDiff(diff string) []string {
-	seen := map[string]struct{}{}
+// extractPathsFromDiff lists the files changed by a git diff, sorted by path.
+// Headers are read up to the first hunk, so renames and binary or mode-only
+// changes without ---/+++ lines are included, and deletions keep their path.
+func extractPathsFromDiff(diff string) []client.ChangedFile {
+	byPath := map[string]client.ChangedFile{}
+	var current *client.ChangedFile
+	inHeader := false
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if current.Status == "deleted" {
+			current.Path = current.OldPath
+		}
+		if current.Status != "renamed" {
+			current.OldPath = ""
+		}
+		if current.Path != "" {
+			byPath[current.Path] = *current
+		}
+		current = nil
+	}
+
 	scanner := bufio.NewScanner(strings.NewReader(diff))
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.HasPrefix(line, "+++") {
-			path := strings.TrimSpace(strings.TrimPrefix(line, "+++"))
-			if path == "+" {
-				continue
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			oldPath, newPath := splitDiffGitHeader(strings.TrimPrefix(line, "diff --git "))
+			current = &client.ChangedFile{Path: newPath, OldPath: oldPath, Status: "modified"}
+			inHeader = true
+			continue
+		}
+		if current == nil || !inHeader {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+		case strings.HasPrefix(line, "new file mode"):
+			current.Status = "added"
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.Status = "deleted"
+		case strings.HasPrefix(line, "rename from "):
+			current.OldPath = diffPath(strings.TrimPrefix(line, "rename from "), "")
+			current.Status = "renamed"
+		case strings.HasPrefix(line, "rename to "):
+			current.Path = diffPath(strings.TrimPrefix(line, "rename to "), "")
+			current.Status = "renamed"
+		case strings.HasPrefix(line, "--- "):
+			if p := strings.TrimPrefix(line, "--- "); p == "/dev/null" {
+				current.Status = "added"
+			} else {
+				current.OldPath = diffPath(p, "a/")
 			}
-			path = strings.TrimPrefix(path, "b/")
-
-			if path == "/dev/null" || path == "" {
-				continue
+		case strings.HasPrefix(line, "+++ "):
+			if p := strings.TrimPrefix(line, "+++ "); p == "/dev/null" {
+				current.Status = "deleted"
+			} else {
+				current.Path = diffPath(p, "b/")
 			}
-			path = filepath.ToSlash(path)
-			seen[path] = struct{}{}
 		}
 	}
+	flush()
 
-	paths := make([]string, 0, len(seen))
-	for p := range seen {
-		paths = append(paths, p)
+	files := make([]client.ChangedFile, 0, len(byPath))
+	for _, f := range byPath {
+		files = append(files, f)
 	}
-	sort.Strings(paths)
-	return paths
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// splitDiffGitHeader splits the "a/<old> b/<new>" part of a diff --git line.
+// Paths are not quoted, so when " b/" occurs more than once the split that
+// gives equal paths is preferred, as it does for every change but a rename.
+func splitDiffGitHeader(rest string) (string, string) {
+	if !strings.HasPrefix(rest, "a/") {
+		return "", ""
+	}
+	oldPath, newPath := "", ""
+	for i := 0; i+3 <= len(rest); i++ {
+		if rest[i:i+3] != " b/" {
+			continue
+		}
+		a, b := rest[2:i], rest[i+3:]
+		if a == b {
+			return diffPath(a, ""), diffPath(b, "")
+		}
+		if oldPath == "" && newPath == "" {
+			oldPath, newPath = diffPath(a, ""), diffPath(b, "")
+		}
+	}
+	return oldPath, newPath
+}
+
+// diffPath trims a diff path and its a/ or b/ prefix and uses forward slashes.
+func diffPath(p, prefix string) string {
+	p = strings.TrimPrefix(strings.TrimSpace(p), prefix)
+	return filepath.ToSlash(p)
 }
 
 func newDocRepoConfig(docPath, codeRepoRoot string) (*docRepoConfig, error) {
@@ -1622,6 +3811,9 @@ func newDocRepoConfig(docPath, codeRepoRoot string) (*docRepoConfig, error) {
 	if !ok {
 		return nil, fmt.Errorf("documentation repository is not a git repository: %s", docPath)
 	}
+	if err := checkDocSubmoduleInitialized(root, absDoc); err != nil {
+		return nil, err
+	}
 	rel, err := filepath.Rel(root, absDoc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve documentation path relative to repository root: %w", err)
@@ -1634,23 +3826,186 @@ func newDocRepoConfig(docPath, codeRepoRoot string) (*docRepoConfig, error) {
 		rel = "."
 	}
 	shared := codeRepoRoot != "" && utils.SamePath(root, codeRepoRoot)
+	superRoot, submodulePath := docSuperproject(root)
 	return &docRepoConfig{
-		RepoRoot:       root,
-		DocsPath:       absDoc,
-		DocsRelative:   rel,
-		SharedWithCode: shared,
+		RepoRoot:         root,
+		DocsPath:         absDoc,
+		DocsRelative:     rel,
+		SharedWithCode:   shared,
+		SuperprojectRoot: superRoot,
+		SubmodulePath:    submodulePath,
 	}, nil
 }
 
+// resolveCodeScope validates a code scope path against the code repository
+// root and returns it slash-separated and relative to that root. The scope may
+// be given relative to the root or as an absolute path, and must name an
+// existing directory inside the repository. An empty scope, or one naming the
+// root itself, means the whole repository and resolves to "".
+func resolveCodeScope(codeRepoRoot, scope string) (string, error) {
+	scope = strings.TrimSpace(scope)
+	if scope == "" {
+		return "", nil
+	}
+	abs := scope
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(codeRepoRoot, filepath.FromSlash(scope))
+	}
+	rel, err := filepath.Rel(codeRepoRoot, filepath.Clean(abs))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve code scope relative to repository root: %w", err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("code scope %q is outside the codebase repository", scope)
+	}
+	if !utils.DirectoryExists(filepath.Join(codeRepoRoot, rel)) {
+		return "", fmt.Errorf("code scope directory does not exist: %s", scope)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// scopeCodeDiff restricts a code diff to the project's CodeScopePath so the
+// model only sees changes to the package being documented.
+func scopeCodeDiff(ctx context.Context, sessionKey string, project *models.RepoLink, codeRepoRoot string, diffText string) (string, error) {
+	scope, err := resolveCodeScope(codeRepoRoot, project.CodeScopePath)
+	if err != nil {
+		return "", err
+	}
+	if scope == "" {
+		return diffText, nil
+	}
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Limiting code changes to '%s'", scope))
+	return scopeUnifiedDiff(diffText, scope), nil
+}
+
+// commitHistory lists the commits from target to source for the prompt, capped
+// by the CommitHistoryLimit setting. The history is optional context, so a
+// failure to read it is logged rather than returned.
+func (s *ClientService) commitHistory(ctx context.Context, sessionKey string, repo *git.Repository, target, source plumbing.Hash) []client.CommitMessage {
+	limit := s.workspaceSettings().CommitHistoryLimit
+	if limit <= 0 {
+		return nil
+	}
+	commits, err := s.gitService.CommitLog(repo, target.String(), source.String(), limit)
+	if err != nil {
+		emitSessionDebug(ctx, sessionKey, fmt.Sprintf("Unable to read commit history: %v", err))
+		return nil
+	}
+	history := make([]client.CommitMessage, 0, len(commits))
+	for _, c := range commits {
+		history = append(history, client.CommitMessage{Hash: c.Hash, Author: c.Author, Message: c.Message})
+	}
+	return history
+}
+
+// applyStructuredSummary copies the file rationales and follow-ups of a run's
+// structured summary, if it ended with one, onto result.
+func applyStructuredSummary(result *models.DocGenerationResult, llmResult *client.DocGenerationResponse) {
+	if result == nil || llmResult == nil || llmResult.Structured == nil {
+		return
+	}
+	for _, file := range llmResult.Structured.Files {
+		result.SummaryFiles = append(result.SummaryFiles, models.DocChangeRationale{Path: file.Path, Rationale: file.Rationale})
+	}
+	result.FollowUps = append(result.FollowUps, llmResult.Structured.FollowUps...)
+}
+
+// commitSummaryMaxSubject bounds the commit subject quoted in the prompt.
+const commitSummaryMaxSubject = 100
+
+// describeSourceCommit summarizes commit in one line for the prompt. It is
+// empty when the commit cannot be read, leaving the prompt with only the hash.
+func (s *ClientService) describeSourceCommit(ctx context.Context, sessionKey string, repo *git.Repository, commit plumbing.Hash) string {
+	info, err := s.gitService.ShowCommit(repo, commit.String())
+	if err != nil {
+		emitSessionDebug(ctx, sessionKey, fmt.Sprintf("Unable to read source commit %s: %v", commit.String(), err))
+		return ""
+	}
+	return formatCommitSummary(info)
+}
+
+// formatCommitSummary renders info as `"<subject>" by <author> on <date>, N files changed`.
+func formatCommitSummary(info *models.CommitInfo) string {
+	subject, _, _ := strings.Cut(info.Message, "\n")
+	subject = strings.TrimSpace(subject)
+	if runes := []rune(subject); len(runes) > commitSummaryMaxSubject {
+		subject = string(runes[:commitSummaryMaxSubject-3]) + "..."
+	}
+	files := "files"
+	if info.FilesChanged == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%q by %s on %s, %d %s changed", subject, strings.TrimSpace(info.Author), info.Date.Format("2006-01-02"), info.FilesChanged, files)
+}
+
+// codebaseFiles lists the code files at commit for a full bootstrap run,
+// leaving out the files excluded from diffs, anything outside the project's
+// CodeScopePath and the documentation under docsRelative, which is empty
+// unless the docs live in the code repository.
+func codebaseFiles(ctx context.Context, sessionKey string, project *models.RepoLink, codeRepoRoot string, repo *git.Repository, commit plumbing.Hash, docsRelative string) ([]client.ChangedFile, error) {
+	scope, err := resolveCodeScope(codeRepoRoot, project.CodeScopePath)
+	if err != nil {
+		return nil, err
+	}
+	docsPrefix := filepath.ToSlash(filepath.Clean(docsRelative))
+	if docsPrefix == "." {
+		docsPrefix = ""
+	}
+	if scope != "" {
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Limiting code files to '%s'", scope))
+	}
+	c, err := repo.CommitObject(commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit %s: %w", commit.String(), err)
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tree of %s: %w", commit.String(), err)
+	}
+	entries, err := treeFiles(tree)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]client.ChangedFile, 0, len(entries))
+	for name, entry := range entries {
+		if entry.Mode == filemode.Submodule || shouldExclude(name) {
+			continue
+		}
+		if scope != "" && !pathInScope(name, scope) {
+			continue
+		}
+		if docsPrefix != "" && hasPathPrefix(name, docsPrefix) {
+			continue
+		}
+		files = append(files, client.ChangedFile{Path: name})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
 func documentationBranchName(sourceBranch string) string {
 	trimmed := strings.TrimSpace(sourceBranch)
 	if trimmed == "" {
 		return "docs"
 	}
-	cleaned := strings.ReplaceAll(trimmed, " ", "-")
+	// Source revisions such as HEAD~2 contain characters git forbids in branch names
+	cleaned := strings.NewReplacer(" ", "-", "~", "-", "^", "-", ":", "-").Replace(trimmed)
 	return fmt.Sprintf("docs/%s", cleaned)
 }
 
+// sessionDocsBranch returns the docs branch recorded on a session, which may
+// have been renamed since generation, falling back to the derived name for
+// sessions created before the branch was recorded.
+func sessionDocsBranch(session *models.GenerationSession) string {
+	if docsBranch := strings.TrimSpace(session.DocsBranch); docsBranch != "" {
+		return docsBranch
+	}
+	return documentationBranchName(session.SourceBranch)
+}
+
 func parseChatMessagesJSON(raw string) []models.ChatMessage {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -1725,45 +4080,180 @@ func (s *ClientService) loadStoredChatMessagesFromSession(session *models.Genera
 	if session == nil {
 		return nil
 	}
-	return parseChatMessagesJSON(session.ChatMessagesJSON)
+	return parseChatMessagesJSON(session.ChatMessagesJSON)
+}
+
+const (
+	tempRepoDirPrefix       = "narrabyte-docs-"
+	retainedWorkspaceMarker = ".narrabyte-retained"
+)
+
+func newTempRepoDir(ctx context.Context, sessionKey string, baseDir string) (string, func()) {
+	baseDir = strings.TrimSpace(baseDir)
+	if baseDir == "" {
+		baseDir = os.TempDir()
+	}
+	// Retained workspaces can outlive the process, so never reuse an existing path
+	repoPath := filepath.Join(baseDir, tempRepoDirPrefix+generateUniqueID())
+	for {
+		if _, err := os.Lstat(repoPath); os.IsNotExist(err) {
+			break
+		}
+		repoPath = filepath.Join(baseDir, tempRepoDirPrefix+generateUniqueID())
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(repoPath); err != nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to cleanup temp directory %s: %v", repoPath, err))
+		}
+	}
+	return repoPath, cleanup
+}
+
+// workspaceSettings returns the temp workspace preferences from app settings,
+// falling back to defaults when settings are unavailable.
+func (s *ClientService) workspaceSettings() models.AppSettings {
+	if s.appSettings == nil {
+		return models.AppSettings{}
+	}
+	settings, err := s.appSettings.Get()
+	if err != nil || settings == nil {
+		return models.AppSettings{}
+	}
+	return *settings
+}
+
+// historyLoadLimit returns the configured limit for restored conversation histories.
+func (s *ClientService) historyLoadLimit() client.HistoryLimit {
+	settings := s.workspaceSettings()
+	return client.HistoryLimit{
+		MaxMessages: settings.HistoryLoadMessages,
+		MaxChars:    settings.HistoryLoadChars,
+	}
+}
+
+// startSessionEvents scopes ctx to a run of sessionID. The run's completion
+// event is flagged as background when the session is not bound to a tab, and
+// when session event logs are enabled an event recorder is attached. The
+// returned flush appends what was recorded to the session's stored log and
+// must run after the last event of the run.
+func (s *ClientService) startSessionEvents(ctx context.Context, sessionKey string, sessionID uint) (context.Context, func()) {
+	ctx = events.WithTabCheck(ctx, func() bool { return s.IsSessionInTab(sessionID) })
+	if !s.workspaceSettings().RecordSessionEvents {
+		return ctx, func() {}
+	}
+	recorder := events.NewRecorder(MaxSessionEventLogEntries)
+	flush := func() {
+		recorded := recorder.Drain()
+		entries := make([]models.SessionEventLogEntry, 0, len(recorded))
+		for _, evt := range recorded {
+			entries = append(entries, models.SessionEventLogEntry{
+				Timestamp: evt.Timestamp,
+				Type:      string(evt.Type),
+				Kind:      string(evt.Kind),
+				ToolName:  evt.ToolName,
+				Message:   evt.Message,
+			})
+		}
+		if err := s.generationSessions.AppendEventLog(sessionID, entries); err != nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to save session event log: %v", err))
+		}
+	}
+	return events.WithRecorder(ctx, recorder), flush
 }
 
-func newTempRepoDir(ctx context.Context, sessionKey string) (string, func()) {
-	tempID := generateUniqueID()
-	repoPath := filepath.Join(os.TempDir(), fmt.Sprintf("narrabyte-docs-%s", tempID))
-	cleanup := func() {
-		if err := os.RemoveAll(repoPath); err != nil {
-			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Failed to cleanup temp directory %s: %v", repoPath, err))
+// releaseTempWorkspace removes a temp workspace once a run finishes. When the
+// run failed and RetainFailedWorkspaces is enabled the directory is kept and
+// marked so CleanupRetainedWorkspaces can sweep it later. A canceled run did
+// not fail, so its workspace is always removed.
+func (s *ClientService) releaseTempWorkspace(ctx context.Context, sessionKey string, workspace tempDocWorkspace, cleanup func(), runErr error) {
+	if runErr != nil && !errors.Is(runErr, context.Canceled) && workspace.repoPath != "" && s.workspaceSettings().RetainFailedWorkspaces {
+		marker := filepath.Join(workspace.repoPath, retainedWorkspaceMarker)
+		if err := os.WriteFile(marker, []byte(time.Now().UTC().Format(time.RFC3339)), 0o644); err == nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Run failed; retained temporary workspace at %s", workspace.repoPath))
+			return
 		}
 	}
-	return repoPath, cleanup
+	cleanup()
 }
 
-func hasDocsChanges(status git.Status, docsRelative string) bool {
-	if docsRelative == "." {
-		return !status.IsClean()
+// CleanupRetainedWorkspaces removes workspaces retained from failed runs that
+// are older than maxAgeHours (0 removes all of them) and returns how many were removed.
+func (s *ClientService) CleanupRetainedWorkspaces(maxAgeHours int) (int, error) {
+	if maxAgeHours < 0 {
+		return 0, fmt.Errorf("max age must not be negative")
 	}
-	base := filepath.ToSlash(filepath.Clean(docsRelative))
-	if base == "." {
-		return !status.IsClean()
+	baseDir := strings.TrimSpace(s.workspaceSettings().TempBaseDir)
+	if baseDir == "" {
+		baseDir = os.TempDir()
 	}
-	prefix := base
-	if !strings.HasSuffix(prefix, "/") {
-		prefix += "/"
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read temp directory: %w", err)
 	}
-	for path, st := range status {
-		if st == nil {
+
+	cutoff := time.Now().Add(-time.Duration(maxAgeHours) * time.Hour)
+	removed := 0
+	pruned := make(map[string]bool)
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), tempRepoDirPrefix) {
 			continue
 		}
-		if st.Staging == git.Unmodified && st.Worktree == git.Unmodified {
+		dir := filepath.Join(baseDir, entry.Name())
+		// Only sweep directories explicitly retained; active runs carry no marker
+		info, err := os.Stat(filepath.Join(dir, retainedWorkspaceMarker))
+		if err != nil || info.ModTime().After(cutoff) {
 			continue
 		}
-		rel := filepath.ToSlash(path)
-		if rel == base || strings.HasPrefix(rel, prefix) {
-			return true
+		commonDir := linkedWorktreeCommonDir(dir)
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", dir, err))
+			continue
+		}
+		removed++
+		if commonDir != "" {
+			pruned[commonDir] = true
+		}
+	}
+	// A removed linked worktree leaves its entry in the repository it belongs to
+	for commonDir := range pruned {
+		if err := pruneLinkedWorktrees(commonDir); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", commonDir, err))
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// PruneRemoteBranches fetches remote in a project's codebase repository, and
+// in its documentation repository when that is a separate git repository,
+// dropping remote-tracking branches deleted upstream. It returns the pruned
+// branch names so the UI can report what was cleaned.
+func (s *ClientService) PruneRemoteBranches(projectID uint, remote string) ([]string, error) {
+	if projectID == 0 {
+		return nil, fmt.Errorf("project id is required")
+	}
+	_, codeRoot, docCfg, err := s.prepareProjectRepos(projectID)
+	if err != nil {
+		return nil, err
+	}
+	roots := []string{codeRoot}
+	if !docCfg.Filesystem && filepath.Clean(docCfg.RepoRoot) != filepath.Clean(codeRoot) {
+		roots = append(roots, docCfg.RepoRoot)
+	}
+
+	pruned := []string{}
+	for _, root := range roots {
+		repo, err := s.gitService.Open(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open repository: %w", err)
+		}
+		names, err := s.gitService.FetchPrune(repo, remote)
+		if err != nil {
+			return nil, err
 		}
+		pruned = append(pruned, names...)
 	}
-	return false
+	return pruned, nil
 }
 
 func addDocsChanges(wt *git.Worktree, docsRelative string) error {
@@ -1780,42 +4270,42 @@ func addDocsChanges(wt *git.Worktree, docsRelative string) error {
 	return nil
 }
 
-func collectDocChangedFiles(status git.Status, docsRelative string) []models.DocChangedFile {
-	files := make([]models.DocChangedFile, 0)
-	base := filepath.ToSlash(filepath.Clean(docsRelative))
-	if base == "." {
-		base = ""
-	}
-	prefix := base
-	if prefix != "" && !strings.HasSuffix(prefix, "/") {
-		prefix += "/"
+// debugDocStatus reports the git status code of each file in debug mode.
+func debugDocStatus(ctx context.Context, sessionKey string, status git.Status) {
+	if !events.DebugEnabled() {
+		return
 	}
 	for path, st := range status {
 		if st == nil {
 			continue
 		}
-		fmt.Printf("DEBUG: File %s, Worktree: %v, Staging: %v\n", path, st.Worktree, st.Staging)
-		if st.Staging == git.Unmodified && st.Worktree == git.Unmodified {
-			continue
-		}
-		rel := filepath.ToSlash(path)
-		switch {
-		case base == "":
-			// Docs root is the repository root; include everything
-		case rel == base:
-			// Exact directory match (e.g., docs folder itself)
-		default:
-			if !strings.HasPrefix(rel, prefix) {
-				continue
+		emitSessionDebug(ctx, sessionKey, fmt.Sprintf("Docs status: %s worktree=%c staging=%c", path, st.Worktree, st.Staging))
+	}
+}
+
+// docsRelativePaths converts repository-relative documentation paths into
+// paths relative to the documentation directory, rejecting paths outside it.
+func docsRelativePaths(paths []string, docsRelative string) ([]string, error) {
+	base := filepath.ToSlash(filepath.Clean(docsRelative))
+	if base == "." {
+		base = ""
+	}
+	rels := make([]string, 0, len(paths))
+	for _, p := range paths {
+		rel := path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+		if base != "" {
+			trimmed, ok := strings.CutPrefix(rel, base+"/")
+			if !ok {
+				return nil, fmt.Errorf("file %s is outside the documentation directory", p)
 			}
+			rel = trimmed
 		}
-		files = append(files, models.DocChangedFile{
-			Path:   rel,
-			Status: describeStatus(*st),
-		})
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || strings.HasPrefix(rel, "/") {
+			return nil, fmt.Errorf("file %s is outside the documentation directory", p)
+		}
+		rels = append(rels, rel)
 	}
-	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
-	return files
+	return rels, nil
 }
 
 // generateUniqueID creates a unique identifier for temporary directories
@@ -1920,14 +4410,153 @@ func removeNarrabyteDir(ctx context.Context, sessionKey string, docsPath string)
 	return nil
 }
 
+// maintainFumadocsMeta reconciles the meta.json files of directories the run
+// touched when the project opted in, so the updates are committed alongside
+// the generated pages. allowedFiles, when set, is the allowlist of a scoped
+// refinement: a meta.json it does not list is left alone and reported.
+// Failures are reported but never fail the run.
+func (s *ClientService) maintainFumadocsMeta(ctx context.Context, sessionKey string, project *models.RepoLink, workspace tempDocWorkspace, docsRelative string, allowedFiles []string) {
+	if s.fumadocs == nil || project == nil || !project.MaintainFumadocsMeta {
+		return
+	}
+	repo, err := openTempDocRepo(workspace)
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Fumadocs meta: failed to open workspace: %v", err))
+		return
+	}
+	status, err := s.gitService.StatusSummary(repo, docsRelative)
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Fumadocs meta: failed to read status: %v", err))
+		return
+	}
+	changed := status.Files
+	if len(allowedFiles) > 0 {
+		changed = withAllowedMetaFiles(ctx, sessionKey, workspace, changed, docsRelative, allowedFiles)
+	}
+	updated, err := s.fumadocs.ReconcileMetaFiles(workspace.repoPath, changed)
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Fumadocs meta: %v", err))
+	}
+	for _, file := range updated {
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Fumadocs meta: updated %s", file.Path))
+	}
+}
+
+// withAllowedMetaFiles drops the changed pages whose directory's meta.json is
+// not in allowedFiles, which are relative to the docs directory, so a scoped
+// refinement never rewrites it. Each existing meta.json left alone is
+// reported once.
+func withAllowedMetaFiles(ctx context.Context, sessionKey string, workspace tempDocWorkspace, changed []models.DocChangedFile, docsRelative string, allowedFiles []string) []models.DocChangedFile {
+	base := filepath.ToSlash(filepath.Clean(docsRelative))
+	allowed := make(map[string]bool, len(allowedFiles))
+	for _, file := range allowedFiles {
+		allowed[path.Join(base, file)] = true
+	}
+	kept := make([]models.DocChangedFile, 0, len(changed))
+	skipped := make(map[string]bool)
+	for _, file := range changed {
+		meta := path.Join(path.Dir(filepath.ToSlash(file.Path)), FumadocsMetaFile)
+		if allowed[meta] {
+			kept = append(kept, file)
+			continue
+		}
+		if skipped[meta] || !isFumadocsPage(file.Path) {
+			continue
+		}
+		skipped[meta] = true
+		if _, err := os.Stat(filepath.Join(workspace.repoPath, filepath.FromSlash(meta))); err == nil {
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Fumadocs meta: not updating %s; it is not among the files this refinement may change", meta))
+		}
+	}
+	return kept
+}
+
+// checkFrontmatter validates the frontmatter of the pages a run changed and
+// reports each problem as a warning. The changed paths are relative to the
+// workspace repository, so pages are read from workspace.repoPath. Pages are
+// never rewritten. Projects that are not Fumadocs sites are not checked, since
+// their pages need no title.
+func (s *ClientService) checkFrontmatter(ctx context.Context, sessionKey string, workspace tempDocWorkspace, files []models.DocChangedFile) map[string][]string {
+	if s.fumadocs == nil || len(files) == 0 {
+		return nil
+	}
+	if _, ok := FumadocsContentDir(workspace.docsPath); !ok {
+		return nil
+	}
+	violations, err := s.fumadocs.ValidateFrontmatter(workspace.repoPath, files)
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Frontmatter: %v", err))
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(violations))
+	for path := range violations {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Frontmatter: %s: %s", path, strings.Join(violations[path], "; ")))
+	}
+	return violations
+}
+
+// lintMDXComponents reports JSX components in the pages a run changed that are
+// neither imported nor provided by the docs site. Pages are never rewritten.
+// Like checkFrontmatter, it only runs for Fumadocs sites.
+func (s *ClientService) lintMDXComponents(ctx context.Context, sessionKey string, project *models.RepoLink, workspace tempDocWorkspace, files []models.DocChangedFile) []models.MDXComponentWarning {
+	if s.fumadocs == nil || project == nil || len(files) == 0 {
+		return nil
+	}
+	if _, ok := FumadocsContentDir(workspace.docsPath); !ok {
+		return nil
+	}
+	warnings, err := s.fumadocs.LintMDXComponents(workspace.repoPath, files, ParseMDXComponentList(project.AllowedMDXComponents))
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("MDX components: %v", err))
+	}
+	for _, w := range warnings {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("MDX components: %s:%d: %s", w.Path, w.Line, w.Message))
+	}
+	if len(warnings) == 0 {
+		return nil
+	}
+	return warnings
+}
+
+// buildRouteTree previews the Fumadocs navigation of the workspace docs.
+// Projects that are not Fumadocs sites get no tree.
+func (s *ClientService) buildRouteTree(ctx context.Context, sessionKey string, docsPath string) *models.RouteNode {
+	if s.fumadocs == nil {
+		return nil
+	}
+	tree, err := s.fumadocs.BuildRouteTree(docsPath)
+	if err != nil {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Route tree: %v", err))
+		return nil
+	}
+	return tree
+}
+
 // propagateDocChanges commits documentation changes in the temp repository and updates
 // the branch reference in the main repository to point to the new commit.
 // Returns the list of files that were changed (added/modified/etc).
 func propagateDocChanges(ctx context.Context, sessionKey string, workspace tempDocWorkspace, mainRepo *git.Repository, branch string, docsRelative string) ([]models.DocChangedFile, error) {
+	return propagateDocChangesWithEdits(ctx, sessionKey, workspace, mainRepo, branch, docsRelative, nil)
+}
+
+// propagateDocChangesWithEdits is propagateDocChanges for a generation run
+// that keeps manual edits: the generated changes are committed marked with
+// generatedDocsTrailer, then carryEdits runs and whatever it changes in the
+// workspace is committed on top under manualEditsCommitMessage. The generated
+// commit so stays the merge base of the next regeneration, which then still
+// sees the edits as manual. Refinements pass a nil carryEdits; their commit
+// is not marked, so their changes are carried over like manual edits.
+func propagateDocChangesWithEdits(ctx context.Context, sessionKey string, workspace tempDocWorkspace, mainRepo *git.Repository, branch string, docsRelative string, carryEdits func() error) ([]models.DocChangedFile, error) {
 	emitSessionInfo(ctx, sessionKey, "Propagating documentation changes back to main repository")
 
 	// Open temporary repository
-	tempRepo, err := git.PlainOpen(workspace.repoPath)
+	tempRepo, err := openTempDocRepo(workspace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open temp repository: %w", err)
 	}
@@ -1942,41 +4571,44 @@ func propagateDocChanges(ctx context.Context, sessionKey string, workspace tempD
 		return nil, err
 	}
 
-	// Check if there are any changes to commit
-	status, err := tempWT.Status()
+	// Create commit with generated documentation
+	message := generatedDocsCommitMessage
+	if carryEdits != nil {
+		message += "\n\n" + generatedDocsTrailer
+	}
+	changedFiles, commitHash, err := commitWorkspaceDocs(ctx, sessionKey, tempWT, docsRelative, message)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get temp repository status: %w", err)
+		return nil, err
+	}
+	if carryEdits != nil {
+		if err := carryEdits(); err != nil {
+			return nil, err
+		}
+		editedFiles, editsHash, err := commitWorkspaceDocs(ctx, sessionKey, tempWT, docsRelative, manualEditsCommitMessage)
+		if err != nil {
+			return nil, err
+		}
+		if !editsHash.IsZero() {
+			changedFiles = mergeChangedFiles(changedFiles, editedFiles)
+			commitHash = editsHash
+		}
 	}
 
-	changedFiles := collectDocChangedFiles(status, docsRelative)
-
-	if !hasDocsChanges(status, docsRelative) {
+	if commitHash.IsZero() {
 		emitSessionInfo(ctx, sessionKey, "No documentation changes to propagate")
 		return nil, nil
 	}
 
-	// Add all changes
-	if err := addDocsChanges(tempWT, docsRelative); err != nil {
-		return nil, err
-	}
-
-	// Create commit with generated documentation
-	commitHash, err := tempWT.Commit("Generated documentation updates", &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  "Narrabyte Documentation Generator",
-			Email: "docs@narrabyte.ai",
-			When:  time.Now(),
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to commit changes in temp repository: %w", err)
+	// Transfer git objects from temp repository to main repository; linked worktrees already share them
+	if !workspace.sharedStore {
+		if err := transferGitObjects(ctx, sessionKey, tempRepo, mainRepo, commitHash); err != nil {
+			return nil, fmt.Errorf("failed to transfer git objects to main repository: %w", err)
+		}
 	}
 
-	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Created documentation commit: %s", commitHash.String()[:8]))
-
-	// Transfer git objects from temp repository to main repository
-	if err := transferGitObjects(ctx, sessionKey, tempRepo, mainRepo, commitHash); err != nil {
-		return nil, fmt.Errorf("failed to transfer git objects to main repository: %w", err)
+	// Only move the branch once every object it needs is present; the ref update is the final step
+	if err := verifyCommitComplete(ctx, sessionKey, mainRepo, commitHash); err != nil {
+		return nil, fmt.Errorf("documentation commit is incomplete in main repository; branch '%s' was not updated: %w", branch, err)
 	}
 
 	// Update the branch reference in main repository to point to new commit
@@ -1990,137 +4622,93 @@ func propagateDocChanges(ctx context.Context, sessionKey string, workspace tempD
 	return changedFiles, nil
 }
 
-// transferGitObjects transfers all git objects (commit, tree, blobs) from source to target repository
-// This ensures the target repository has all objects needed to checkout the commit
-func transferGitObjects(ctx context.Context, sessionKey string, sourceRepo, targetRepo *git.Repository, commitHash plumbing.Hash) error {
-	emitSessionInfo(ctx, sessionKey, "Transferring git objects to main repository")
-
-	// Get the commit object from source repository
-	commit, err := sourceRepo.CommitObject(commitHash)
-	if err != nil {
-		return fmt.Errorf("failed to get commit object: %w", err)
-	}
-
-	// Transfer the commit object
-	if err := transferObject(sourceRepo, targetRepo, commitHash, plumbing.CommitObject); err != nil {
-		return fmt.Errorf("failed to transfer commit object: %w", err)
-	}
-
-	// Get and transfer the tree object
-	tree, err := commit.Tree()
+// commitWorkspaceDocs commits the documentation changes of the temp
+// worktree with message. It returns the changed files and the new commit, or
+// a zero hash when there was nothing to commit.
+func commitWorkspaceDocs(ctx context.Context, sessionKey string, tempWT *git.Worktree, docsRelative string, message string) ([]models.DocChangedFile, plumbing.Hash, error) {
+	status, err := tempWT.Status()
 	if err != nil {
-		return fmt.Errorf("failed to get tree from commit: %w", err)
-	}
-
-	if err := transferTreeRecursively(sourceRepo, targetRepo, tree.Hash); err != nil {
-		return fmt.Errorf("failed to transfer tree objects: %w", err)
+		return nil, plumbing.ZeroHash, fmt.Errorf("failed to get temp repository status: %w", err)
 	}
 
-	// Transfer parent commits if they don't exist in target
-	for _, parentHash := range commit.ParentHashes {
-		if exists, _ := objectExists(targetRepo, parentHash); !exists {
-			if err := transferGitObjects(ctx, sessionKey, sourceRepo, targetRepo, parentHash); err != nil {
-				// Log warning but continue - parent might be from a different branch
-				emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Could not transfer parent commit %s: %v", parentHash.String()[:8], err))
-			}
-		}
+	debugDocStatus(ctx, sessionKey, status)
+	docStatus := summarizeStatus(status, docsRelative)
+	if docStatus.Clean {
+		return nil, plumbing.ZeroHash, nil
 	}
 
-	emitSessionInfo(ctx, sessionKey, "Git objects transfer completed")
-	return nil
-}
-
-// transferObject transfers a single git object from source to target repository
-func transferObject(sourceRepo, targetRepo *git.Repository, hash plumbing.Hash, objType plumbing.ObjectType) error {
-	// Check if object already exists in target
-	if exists, _ := objectExists(targetRepo, hash); exists {
-		return nil
+	if err := addDocsChanges(tempWT, docsRelative); err != nil {
+		return nil, plumbing.ZeroHash, err
 	}
-
-	// Get encoded object from source
-	encodedObj, err := sourceRepo.Storer.EncodedObject(objType, hash)
+	commitHash, err := tempWT.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Narrabyte Documentation Generator",
+			Email: "docs@narrabyte.ai",
+			When:  time.Now(),
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get encoded object %s: %w", hash.String(), err)
+		return nil, plumbing.ZeroHash, fmt.Errorf("failed to commit changes in temp repository: %w", err)
 	}
 
-	// Store encoded object in target
-	_, err = targetRepo.Storer.SetEncodedObject(encodedObj)
-	return err
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Created documentation commit: %s", commitHash.String()[:8]))
+	return docStatus.Files, commitHash, nil
 }
 
-// transferTreeRecursively transfers a tree and all its contents (blobs and subtrees)
-func transferTreeRecursively(sourceRepo, targetRepo *git.Repository, treeHash plumbing.Hash) error {
-	// Check if tree already exists
-	if exists, _ := objectExists(targetRepo, treeHash); exists {
-		return nil
-	}
-
-	// Transfer the tree object itself
-	if err := transferObject(sourceRepo, targetRepo, treeHash, plumbing.TreeObject); err != nil {
-		return err
-	}
-
-	// Get tree to iterate through entries
-	tree, err := sourceRepo.TreeObject(treeHash)
-	if err != nil {
-		return fmt.Errorf("failed to get tree object: %w", err)
+// mergeChangedFiles combines the files changed by two consecutive commits
+// into the change the pair makes. A file added by the first commit stays
+// added unless the second deletes it again.
+func mergeChangedFiles(first, second []models.DocChangedFile) []models.DocChangedFile {
+	byPath := make(map[string]string, len(first)+len(second))
+	for _, f := range first {
+		byPath[f.Path] = f.Status
 	}
-
-	// Transfer all entries (blobs and subtrees)
-	for _, entry := range tree.Entries {
-		switch entry.Mode {
-		case filemode.Regular, filemode.Executable, filemode.Symlink:
-			// Transfer blob
-			if err := transferObject(sourceRepo, targetRepo, entry.Hash, plumbing.BlobObject); err != nil {
-				return fmt.Errorf("failed to transfer blob %s: %w", entry.Hash.String(), err)
-			}
-		case filemode.Dir:
-			// Recursively transfer subtree
-			if err := transferTreeRecursively(sourceRepo, targetRepo, entry.Hash); err != nil {
-				return fmt.Errorf("failed to transfer subtree %s: %w", entry.Hash.String(), err)
+	for _, f := range second {
+		prev, ok := byPath[f.Path]
+		switch {
+		case !ok:
+			byPath[f.Path] = f.Status
+		case prev == "added" || prev == "untracked":
+			if f.Status == "deleted" {
+				delete(byPath, f.Path)
 			}
+		case prev == "deleted" && f.Status != "deleted":
+			byPath[f.Path] = "modified"
+		default:
+			byPath[f.Path] = f.Status
 		}
 	}
-
-	return nil
+	files := make([]models.DocChangedFile, 0, len(byPath))
+	for path, status := range byPath {
+		files = append(files, models.DocChangedFile{Path: path, Status: status})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
 }
 
-// objectExists checks if an object exists in the repository
-func objectExists(repo *git.Repository, hash plumbing.Hash) (bool, error) {
-	_, err := repo.Object(plumbing.AnyObject, hash)
+// documentationDiff returns the diff between the base branch and the docs
+// branch together with its per-file stat.
+func (s *ClientService) documentationDiff(docRepo *git.Repository, baseBranch, docsBranch string) (string, []models.FileDiffStat, error) {
+	docDiff, err := s.gitService.DiffBetweenBranches(docRepo, baseBranch, docsBranch)
 	if err != nil {
-		if err == plumbing.ErrObjectNotFound {
-			return false, nil
-		}
-		return false, err
+		return "", nil, fmt.Errorf("failed to generate documentation diff: %w", err)
 	}
-	return true, nil
+	diffStat, err := s.gitService.DiffStat(docRepo, baseBranch, docsBranch)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to compute documentation diff stat: %w", err)
+	}
+	return docDiff, diffStat, nil
 }
 
-func describeStatus(st git.FileStatus) string {
-	code := st.Worktree
-	if code == git.Unmodified {
-		code = st.Staging
-	}
-	switch code {
-	case git.Added:
-		return "added"
-	case git.Untracked:
-		return "untracked"
-	case git.Modified:
-		return "modified"
-	case git.Deleted:
-		return "deleted"
-	case git.Renamed:
-		return "renamed"
-	case git.Copied:
-		return "copied"
-	default:
-		return "changed"
-	}
+// GenerateDocsFromBranch applies userInstructions to the docs of branch
+// without a diff. idempotencyKey is optional; see runIdempotent.
+func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string) (*models.DocGenerationResult, error) {
+	return s.runIdempotent(idempotencyKey, func(key string) (*models.DocGenerationResult, error) {
+		return s.generateDocsFromBranch(projectID, branch, modelKey, userInstructions, docsBranchOverride, sessionKeyOverride, key)
+	})
 }
 
-func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string) (*models.DocGenerationResult, error) {
+func (s *ClientService) generateDocsFromBranch(projectID uint, branch string, modelKey string, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string) (result *models.DocGenerationResult, err error) {
 	ctx := s.context
 	if ctx == nil {
 		return nil, fmt.Errorf("client service not initialized")
@@ -2134,8 +4722,9 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 	if branch == "" {
 		return nil, fmt.Errorf("branch is required")
 	}
-	if modelKey == "" {
-		return nil, fmt.Errorf("model is required")
+	modelKey, err = s.resolveRunModelKey(projectID, modelKey)
+	if err != nil {
+		return nil, err
 	}
 
 	// Determine docs branch name early
@@ -2153,7 +4742,7 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 		return nil, fmt.Errorf("ERR_SESSION_EXISTS:a session with docsBranch '%s' already exists (ID: %d)", docsBranch, existingSession.ID)
 	}
 
-	runtime, modelInfo, err := s.newSessionRuntime(modelKey)
+	runtime, modelInfo, err := s.newSessionRuntime(events.WithSession(ctx, strings.TrimSpace(sessionKeyOverride)), projectID, modelKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize LLM client: %w", err)
 	}
@@ -2163,6 +4752,69 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 		return nil, err
 	}
 
+	if docCfg.Filesystem {
+		providerID := strings.TrimSpace(runtime.providerID)
+		if providerID == "" && modelInfo != nil {
+			providerID = strings.TrimSpace(modelInfo.ProviderID)
+		}
+		session, replay, err := s.createIdempotentSession(&models.GenerationSession{
+			ProjectID:    projectID,
+			SourceBranch: branch,
+			Provider:     providerID,
+			ModelKey:     runtime.modelKey,
+			DocsBranch:   docsBranch,
+		}, idempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session: %w", err)
+		}
+		if replay != nil {
+			return replay, nil
+		}
+		sessionKey := resolveSessionKey(sessionKeyOverride, session.ID)
+		s.setSessionRuntime(sessionKey, runtime)
+		defer func() { s.dropRejectedSession(sessionKey, session.ID, err) }()
+		ctx, flushEventLog := s.startSessionEvents(ctx, sessionKey, session.ID)
+		defer flushEventLog()
+		emitRuntimeWarnings(ctx, sessionKey, runtime)
+
+		if err := s.markDocsBranchInProgress(docsBranch); err != nil {
+			return nil, err
+		}
+		defer s.unmarkDocsBranchInProgress(docsBranch)
+
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
+			"GenerateDocsFromBranch: starting for project %s on branch %s using %s via %s into %s",
+			project.ProjectName, branch, runtime.modelDisplay, runtime.providerLabel, docCfg.DocsPath,
+		))
+		result, err = s.runFilesystemDocs(ctx, sessionKey, runtime, project, docCfg, nil, func(streamCtx context.Context, docsPath string) (*client.DocGenerationResponse, error) {
+			return runtime.client.DocRefine(streamCtx, &client.DocRefineRequest{
+				ProjectName:          project.ProjectName,
+				CodebasePath:         codeRoot,
+				DocumentationPath:    docsPath,
+				DocumentationRelPath: docCfg.DocsRelative,
+				SourceBranch:         branch,
+				Instruction:          userInstructions,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		chatMessages := appendChatMessages(nil, userInstructions, result.Summary)
+		if jsonStr, err := runtime.client.ConversationHistoryJSON(); err == nil {
+			_ = s.generationSessions.UpdateByID(session.ID, map[string]interface{}{
+				"messages_json":      jsonStr,
+				"chat_messages_json": marshalChatMessages(chatMessages),
+				"last_summary":       result.Summary,
+			})
+		}
+		emitSessionInfo(ctx, sessionKey, "GenerateDocsFromBranch: completed")
+		result.SessionID = session.ID
+		result.SessionKey = sessionKey
+		result.Branch = branch
+		result.ChatMessages = chatMessages
+		return result, nil
+	}
+
 	// Open documentation repo
 	docRepo, err := s.gitService.Open(docCfg.RepoRoot)
 	if err != nil {
@@ -2200,20 +4852,36 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 		ModelKey:     runtime.modelKey,
 		DocsBranch:   docsBranch,
 	}
-	session, err = s.generationSessions.Create(session)
+	session, replay, err := s.createIdempotentSession(session, idempotencyKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	if replay != nil {
+		return replay, nil
+	}
 
 	sessionKey := resolveSessionKey(sessionKeyOverride, session.ID)
 	s.setSessionRuntime(sessionKey, runtime)
+	defer func() { s.dropRejectedSession(sessionKey, session.ID, err) }()
 	runtime.targetBranch = baseBranch
+	ctx, flushEventLog := s.startSessionEvents(ctx, sessionKey, session.ID)
+	defer flushEventLog()
+	emitRuntimeWarnings(ctx, sessionKey, runtime)
 
 	if err := s.ensureDocsBranchAvailable(docRepo, docsBranch, projectID); err != nil {
 		_ = s.generationSessions.DeleteByID(session.ID)
 		return nil, err
 	}
 
+	streamCtx := runtime.client.StartStream(ctx, sessionKey)
+	defer runtime.client.StopStream()
+
+	release, err := s.acquireRunSlot(streamCtx, sessionKey, runtime)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// Mark this docs branch as in-progress to prevent concurrent generations
 	if err := s.markDocsBranchInProgress(docsBranch); err != nil {
 		return nil, err
@@ -2225,24 +4893,19 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 		project.ProjectName, branch, runtime.modelDisplay, runtime.providerLabel, docsBranch,
 	))
 
-	refName := plumbing.NewBranchReferenceName(docsBranch)
-	if _, err := docRepo.Reference(refName, true); err != nil {
-		if errors.Is(err, plumbing.ErrReferenceNotFound) {
-			// Create docs branch pointing to base commit
-			if err := docRepo.Storer.SetReference(plumbing.NewHashReference(refName, baseHash)); err != nil {
-				return nil, fmt.Errorf("failed to create documentation branch '%s': %w", docsBranch, err)
-			}
-			emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Created docs branch '%s' from '%s'", docsBranch, baseBranch))
-		} else {
-			return nil, fmt.Errorf("failed to resolve documentation branch '%s': %w", docsBranch, err)
-		}
+	branchCreated, err := s.ensureDocsBranchExists(docRepo, docsBranch, baseHash)
+	if err != nil {
+		return nil, err
+	}
+	if branchCreated {
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Created docs branch '%s' from '%s'", docsBranch, baseBranch))
 	}
 
 	tempWorkspace, cleanup, err := createTempDocRepoAtBranchHead(ctx, sessionKey, docCfg, docsBranch, baseBranch, baseHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary documentation workspace: %w", err)
 	}
-	defer cleanup()
+	defer func() { s.releaseTempWorkspace(ctx, sessionKey, tempWorkspace, cleanup, err) }()
 
 	emitSessionInfo(ctx, sessionKey, fmt.Sprintf(
 		"GenerateDocsFromBranch: temporary documentation workspace ready for branch '%s'",
@@ -2252,9 +4915,6 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 	// New session, no existing chat
 	var existingChat []models.ChatMessage
 
-	streamCtx := runtime.client.StartStream(ctx, sessionKey)
-	defer runtime.client.StopStream()
-
 	llmResult, err := runtime.client.DocRefine(streamCtx, &client.DocRefineRequest{
 		ProjectName:          project.ProjectName,
 		CodebasePath:         codeRoot,
@@ -2280,19 +4940,25 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 			_ = s.generationSessions.UpdateByID(session.ID, map[string]interface{}{
 				"messages_json":      jsonStr,
 				"chat_messages_json": chatMessagesJSON,
+				"last_summary":       assistantSummary,
 			})
 		}
 	}
 
+	s.maintainFumadocsMeta(ctx, sessionKey, project, tempWorkspace, docCfg.DocsRelative, nil)
+
 	// Propagate changes from temporary repository back to main repository
 	files, err := propagateDocChanges(ctx, sessionKey, tempWorkspace, docRepo, docsBranch, docCfg.DocsRelative)
 	if err != nil {
 		return nil, fmt.Errorf("failed to propagate documentation changes: %w", err)
 	}
+	routeTree := s.buildRouteTree(ctx, sessionKey, tempWorkspace.docsPath)
+	frontmatterWarnings := s.checkFrontmatter(ctx, sessionKey, tempWorkspace, files)
+	componentWarnings := s.lintMDXComponents(ctx, sessionKey, project, tempWorkspace, files)
 
-	docDiff, err := s.gitService.DiffBetweenBranches(docRepo, baseBranch, docsBranch)
+	docDiff, diffStat, err := s.documentationDiff(docRepo, baseBranch, docsBranch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate documentation diff: %w", err)
+		return nil, err
 	}
 
 	emitSessionInfo(ctx, sessionKey, "GenerateDocsFromBranch: completed")
@@ -2301,16 +4967,22 @@ func (s *ClientService) GenerateDocsFromBranch(projectID uint, branch string, mo
 	if llmResult != nil {
 		summary = llmResult.Summary
 	}
-	return &models.DocGenerationResult{
-		SessionID:      session.ID,
-		SessionKey:     sessionKey,
-		Branch:         branch,
-		TargetBranch:   baseBranch,
-		DocsBranch:     docsBranch,
-		DocsInCodeRepo: docCfg.SharedWithCode,
-		Files:          files,
-		Diff:           docDiff,
-		Summary:        summary,
-		ChatMessages:   chatMessages,
-	}, nil
+	result = &models.DocGenerationResult{
+		SessionID:           session.ID,
+		SessionKey:          sessionKey,
+		Branch:              branch,
+		TargetBranch:        baseBranch,
+		DocsBranch:          docsBranch,
+		DocsInCodeRepo:      docCfg.SharedWithCode,
+		Files:               files,
+		Diff:                docDiff,
+		DiffStat:            diffStat,
+		Summary:             summary,
+		ChatMessages:        chatMessages,
+		RouteTree:           routeTree,
+		FrontmatterWarnings: frontmatterWarnings,
+		ComponentWarnings:   componentWarnings,
+	}
+	applyStructuredSummary(result, llmResult)
+	return result, nil
 }
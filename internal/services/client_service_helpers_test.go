@@ -1,9 +1,26 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/client"
+	"narrabyte/internal/models"
+	"narrabyte/internal/tests/mocks"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 func TestDocumentationBranchName(t *testing.T) {
@@ -16,6 +33,7 @@ func TestDocumentationBranchName(t *testing.T) {
 		{"trimmed", " feature/docs ", "docs/feature/docs"},
 		{"spaces", "update docs", "docs/update-docs"},
 		{"path", "feature/foo", "docs/feature/foo"},
+		{"revision", "HEAD~2", "docs/HEAD-2"},
 	}
 
 	for _, tc := range cases {
@@ -25,7 +43,26 @@ func TestDocumentationBranchName(t *testing.T) {
 	}
 }
 
-func TestHasDocsChanges(t *testing.T) {
+func TestTemplateInstructions(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		user     string
+		expected string
+	}{
+		{"template only", " Use a changelog format ", "", "<DOCUMENTATION_TEMPLATE>Use a changelog format</DOCUMENTATION_TEMPLATE>"},
+		{"with user", "Be terse", " Cover the CLI ", "<DOCUMENTATION_TEMPLATE>Be terse</DOCUMENTATION_TEMPLATE><USER_INSTRUCTIONS>Cover the CLI</USER_INSTRUCTIONS>"},
+		{"empty", "  ", "", ""},
+	}
+
+	for _, tc := range cases {
+		if got := templateInstructions(tc.template, tc.user); got != tc.expected {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestSummarizeStatusDetectsDocsChanges(t *testing.T) {
 	status := git.Status{
 		"docs/index.md": {
 			Staging:  git.Modified,
@@ -37,15 +74,15 @@ func TestHasDocsChanges(t *testing.T) {
 		},
 	}
 
-	if !hasDocsChanges(status, "docs") {
+	if summarizeStatus(status, "docs").Clean {
 		t.Fatal("expected docs changes to be detected")
 	}
-	if hasDocsChanges(status, "guides") {
+	if !summarizeStatus(status, "guides").Clean {
 		t.Fatal("did not expect detection for unrelated directory")
 	}
 }
 
-func TestCollectDocChangedFilesFiltersOutsideDocs(t *testing.T) {
+func TestSummarizeStatusFiltersOutsideDocs(t *testing.T) {
 	status := git.Status{
 		"docs/index.md": {
 			Staging:  git.Modified,
@@ -55,13 +92,17 @@ func TestCollectDocChangedFilesFiltersOutsideDocs(t *testing.T) {
 			Staging:  git.Added,
 			Worktree: git.Added,
 		},
+		"docs-old/index.md": {
+			Staging:  git.Modified,
+			Worktree: git.Modified,
+		},
 		"src/main.go": {
 			Staging:  git.Modified,
 			Worktree: git.Modified,
 		},
 	}
 
-	files := collectDocChangedFiles(status, "docs")
+	files := summarizeStatus(status, "docs").Files
 	if len(files) != 2 {
 		t.Fatalf("expected 2 doc files, got %d", len(files))
 	}
@@ -70,4 +111,1249 @@ func TestCollectDocChangedFilesFiltersOutsideDocs(t *testing.T) {
 			t.Fatalf("unexpected path included: %s", file.Path)
 		}
 	}
+	if files := summarizeStatus(status, ".").Files; len(files) != 4 {
+		t.Fatalf("expected the repository root to include all 4 files, got %d", len(files))
+	}
+}
+
+func TestLinkedDocWorktreePropagatesWithoutTransfer(t *testing.T) {
+	if gitExecutable() == "" {
+		t.Skip("git binary not available")
+	}
+
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "index.md"), []byte("# Docs\n"), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := wt.Add("docs/index.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	baseHash, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	cfg, err := newDocRepoConfig(filepath.Join(root, "docs"), "")
+	if err != nil {
+		t.Fatalf("doc config: %v", err)
+	}
+	cfg.WorkspaceBase = t.TempDir()
+
+	ctx := context.Background()
+	workspace, cleanup, err := createTempDocRepo(ctx, "test", cfg, "docs/feature", "main", baseHash)
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	if !workspace.sharedStore {
+		t.Fatal("expected a linked worktree sharing the object store")
+	}
+
+	if err := os.WriteFile(filepath.Join(workspace.docsPath, "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatalf("write guide: %v", err)
+	}
+	files, err := propagateDocChanges(ctx, "test", workspace, repo, "docs/feature", cfg.DocsRelative)
+	if err != nil {
+		t.Fatalf("propagate: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "docs/guide.md" {
+		t.Fatalf("unexpected changed files: %+v", files)
+	}
+
+	cleanup()
+	if _, err := os.Stat(workspace.repoPath); !os.IsNotExist(err) {
+		t.Fatalf("expected worktree to be removed, stat err: %v", err)
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	if err != nil {
+		t.Fatalf("docs branch missing: %v", err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("commit not in main store: %v", err)
+	}
+	if _, err := commit.File("docs/guide.md"); err != nil {
+		t.Fatalf("expected guide in docs branch: %v", err)
+	}
+}
+
+func TestCloneDocRepoRetriesFailedAndTimedOutAttempts(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "index.md"), []byte("# Docs\n"), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := wt.Add("docs/index.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	cfg, err := newDocRepoConfig(filepath.Join(root, "docs"), "")
+	if err != nil {
+		t.Fatalf("doc config: %v", err)
+	}
+	cfg.WorkspaceBase = t.TempDir()
+	cfg.CloneRetries = 2
+	cfg.CloneTimeout = 50 * time.Millisecond
+
+	// The first attempt fails outright and the second hangs past the timeout
+	var mu sync.Mutex
+	var depths []int
+	restoreClone, restoreBackoff := plainCloneContext, cloneRetryBackoff
+	t.Cleanup(func() { plainCloneContext, cloneRetryBackoff = restoreClone, restoreBackoff })
+	cloneRetryBackoff = time.Millisecond
+	plainCloneContext = func(ctx context.Context, path string, isBare bool, o *git.CloneOptions) (*git.Repository, error) {
+		mu.Lock()
+		depths = append(depths, o.Depth)
+		attempt := len(depths)
+		mu.Unlock()
+		switch attempt {
+		case 1:
+			return nil, errors.New("transient failure")
+		case 2:
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return git.PlainCloneContext(ctx, path, isBare, o)
+	}
+
+	cloned, repoPath, cleanup, err := cloneDocRepo(context.Background(), "test", cfg, &git.CloneOptions{
+		URL:          cfg.RepoRoot,
+		Depth:        1,
+		SingleBranch: true,
+	})
+	if err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	defer cleanup()
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(depths, []int{1, 1, 1}) {
+		t.Fatalf("expected three shallow attempts, got depths %v", depths)
+	}
+	if _, err := cloned.Head(); err != nil {
+		t.Fatalf("cloned repo has no HEAD: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "docs", "index.md")); err != nil {
+		t.Fatalf("expected docs in clone: %v", err)
+	}
+}
+
+func TestFilesystemDocWorkspaceSyncsChanges(t *testing.T) {
+	docs := t.TempDir()
+	for path, content := range map[string]string{
+		"index.md":                   "# Docs\n",
+		"guides/old.md":              "# Old\n",
+		"guides/setup.md":            "# Setup\n",
+		".narrabyte/instructions.md": "Be brief.\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(docs, path)), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(docs, path), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	cfg, err := newFilesystemDocConfig(docs)
+	if err != nil {
+		t.Fatalf("doc config: %v", err)
+	}
+	cfg.WorkspaceBase = t.TempDir()
+
+	ctx := context.Background()
+	workspace, cleanup, err := createFilesystemDocWorkspace(ctx, "test", cfg)
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	defer cleanup()
+	if _, err := os.Stat(filepath.Join(workspace.docsPath, ".narrabyte", "instructions.md")); err != nil {
+		t.Fatalf("expected instructions in workspace: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(workspace.docsPath, "guides", "setup.md"), []byte("# Setup\n\nRun make.\n"), 0o644); err != nil {
+		t.Fatalf("edit setup: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace.docsPath, "guides", "new.md"), []byte("# New\n"), 0o644); err != nil {
+		t.Fatalf("write new: %v", err)
+	}
+	if err := os.Remove(filepath.Join(workspace.docsPath, "guides", "old.md")); err != nil {
+		t.Fatalf("remove old: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(workspace.docsPath, ".narrabyte")); err != nil {
+		t.Fatalf("remove instructions: %v", err)
+	}
+
+	files, err := syncFilesystemDocs(ctx, "test", workspace, cfg.DocsPath)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	got := make([]string, 0, len(files))
+	for _, f := range files {
+		got = append(got, f.Path+" "+f.Status)
+	}
+	want := []string{"guides/new.md added", "guides/old.md deleted", "guides/setup.md modified"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected changed files: got %v, want %v", got, want)
+	}
+
+	setup, err := os.ReadFile(filepath.Join(docs, "guides", "setup.md"))
+	if err != nil || string(setup) != "# Setup\n\nRun make.\n" {
+		t.Fatalf("expected edited setup page, got %q (%v)", setup, err)
+	}
+	if _, err := os.Stat(filepath.Join(docs, "guides", "old.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected old page to be removed, stat err: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(docs, ".narrabyte", "instructions.md")); err != nil {
+		t.Fatalf("expected instructions to be left alone: %v", err)
+	}
+}
+
+func TestFilesystemDocSyncKeepsEditsMadeDuringRun(t *testing.T) {
+	docs := t.TempDir()
+	for path, content := range map[string]string{"index.md": "# Docs\n", "guide.md": "# Guide\n"} {
+		if err := os.WriteFile(filepath.Join(docs, path), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	cfg, err := newFilesystemDocConfig(docs)
+	if err != nil {
+		t.Fatalf("doc config: %v", err)
+	}
+	cfg.WorkspaceBase = t.TempDir()
+
+	ctx := context.Background()
+	workspace, cleanup, err := createFilesystemDocWorkspace(ctx, "test", cfg)
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	defer cleanup()
+
+	// The run edits the index while the user edits the guide and adds a page
+	if err := os.WriteFile(filepath.Join(workspace.docsPath, "index.md"), []byte("# Docs\n\nUpdated.\n"), 0o644); err != nil {
+		t.Fatalf("edit index: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docs, "guide.md"), []byte("# Guide\n\nUser edit.\n"), 0o644); err != nil {
+		t.Fatalf("edit guide: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docs, "notes.md"), []byte("# Notes\n"), 0o644); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+
+	files, err := syncFilesystemDocs(ctx, "test", workspace, cfg.DocsPath)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "index.md" || files[0].Status != "modified" {
+		t.Fatalf("expected only the run's edit to be synced, got %+v", files)
+	}
+	if guide, err := os.ReadFile(filepath.Join(docs, "guide.md")); err != nil || string(guide) != "# Guide\n\nUser edit.\n" {
+		t.Fatalf("expected the user's edit to survive, got %q (%v)", guide, err)
+	}
+	if _, err := os.Stat(filepath.Join(docs, "notes.md")); err != nil {
+		t.Fatalf("expected the page added during the run to be kept: %v", err)
+	}
+}
+
+func TestScopeUnifiedDiff(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/packages/web/src/app.ts b/packages/web/src/app.ts",
+		"--- a/packages/web/src/app.ts",
+		"+++ b/packages/web/src/app.ts",
+		"@@ -1 +1 @@",
+		"-old",
+		"+new",
+		"diff --git a/packages/webhooks/main.go b/packages/webhooks/main.go",
+		"--- a/packages/webhooks/main.go",
+		"+++ b/packages/webhooks/main.go",
+		"@@ -1 +1 @@",
+		"-a",
+		"+b",
+		"diff --git a/packages/web/new.ts b/packages/web/new.ts",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ b/packages/web/new.ts",
+		"@@ -0,0 +1 @@",
+		"+added",
+		"diff --git a/packages/api/old.go b/packages/api/old.go",
+		"deleted file mode 100644",
+		"--- a/packages/api/old.go",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"-removed",
+		"",
+	}, "\n")
+
+	scoped := scopeUnifiedDiff(diff, "packages/web")
+	got := extractPathsFromDiff(scoped)
+	want := []client.ChangedFile{
+		{Path: "packages/web/new.ts", Status: "added"},
+		{Path: "packages/web/src/app.ts", Status: "modified"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("changed files = %v, want %v", got, want)
+	}
+	if strings.Contains(scoped, "webhooks") || strings.Contains(scoped, "packages/api") {
+		t.Fatalf("expected files outside the scope to be dropped:\n%s", scoped)
+	}
+	if scopeUnifiedDiff(diff, "") != diff {
+		t.Fatal("expected empty scope to keep the diff unchanged")
+	}
+}
+
+func TestExtractPathsFromDiff_RenamesAndDeletions(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/internal/auth.go b/internal/login.go",
+		"rename from internal/auth.go",
+		"rename to internal/login.go",
+		"index 1111111..2222222 100644",
+		"--- a/internal/auth.go",
+		"+++ b/internal/login.go",
+		"@@ -1 +1 @@",
+		"-package auth",
+		"+package login",
+		"diff --git a/cmd/old b/cmd/new",
+		"rename from cmd/old",
+		"rename to cmd/new",
+		"diff --git a/internal/legacy.go b/internal/legacy.go",
+		"deleted file mode 100644",
+		"index 3333333..0000000",
+		"--- a/internal/legacy.go",
+		"+++ /dev/null",
+		"@@ -1,2 +0,0 @@",
+		"-package legacy",
+		"--- a/not/a/header.go",
+		"diff --git a/assets/logo.png b/assets/logo.png",
+		"deleted file mode 100644",
+		"index 4444444..0000000",
+		"Binary files a/assets/logo.png and /dev/null differ",
+		"",
+	}, "\n")
+
+	got := extractPathsFromDiff(diff)
+	want := []client.ChangedFile{
+		{Path: "assets/logo.png", Status: "deleted"},
+		{Path: "cmd/new", OldPath: "cmd/old", Status: "renamed"},
+		{Path: "internal/legacy.go", Status: "deleted"},
+		{Path: "internal/login.go", OldPath: "internal/auth.go", Status: "renamed"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("changed files = %v, want %v", got, want)
+	}
+}
+
+func TestResolveCodeScope(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "packages", "web"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	cases := []struct {
+		scope   string
+		want    string
+		wantErr bool
+	}{
+		{scope: "", want: ""},
+		{scope: ".", want: ""},
+		{scope: "packages/web/", want: "packages/web"},
+		{scope: filepath.Join(root, "packages", "web"), want: "packages/web"},
+		{scope: "../outside", wantErr: true},
+		{scope: "packages/missing", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := resolveCodeScope(root, tc.scope)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("resolveCodeScope(%q) expected an error, got %q", tc.scope, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("resolveCodeScope(%q): %v", tc.scope, err)
+		}
+		if got != tc.want {
+			t.Fatalf("resolveCodeScope(%q) = %q, want %q", tc.scope, got, tc.want)
+		}
+	}
+}
+
+func TestCodebaseFilesSkipsExcludedAndOutOfScope(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	for _, rel := range []string{"go.sum", "main.go", "packages/web/app.ts", "packages/web/package-lock.json", "packages/api/server.go", "docs/index.md", "docsite/main.go"} {
+		full := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(rel), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+		if _, err := wt.Add(rel); err != nil {
+			t.Fatalf("add %s: %v", rel, err)
+		}
+	}
+	head, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	paths := func(files []client.ChangedFile) []string {
+		var out []string
+		for _, f := range files {
+			out = append(out, f.Path)
+		}
+		return out
+	}
+	files, err := codebaseFiles(context.Background(), "", &models.RepoLink{}, root, repo, head, "")
+	if err != nil {
+		t.Fatalf("codebaseFiles: %v", err)
+	}
+	if want := []string{"docs/index.md", "docsite/main.go", "main.go", "packages/api/server.go", "packages/web/app.ts"}; !slices.Equal(paths(files), want) {
+		t.Fatalf("unexpected files: %v, want %v", paths(files), want)
+	}
+
+	files, err = codebaseFiles(context.Background(), "", &models.RepoLink{}, root, repo, head, "docs")
+	if err != nil {
+		t.Fatalf("codebaseFiles: %v", err)
+	}
+	if want := []string{"docsite/main.go", "main.go", "packages/api/server.go", "packages/web/app.ts"}; !slices.Equal(paths(files), want) {
+		t.Fatalf("unexpected files with docs in the code repo: %v, want %v", paths(files), want)
+	}
+
+	files, err = codebaseFiles(context.Background(), "", &models.RepoLink{CodeScopePath: "packages/web"}, root, repo, head, "")
+	if err != nil {
+		t.Fatalf("codebaseFiles: %v", err)
+	}
+	if want := []string{"packages/web/app.ts"}; !slices.Equal(paths(files), want) {
+		t.Fatalf("unexpected scoped files: %v, want %v", paths(files), want)
+	}
+}
+
+func TestFormatCommitSummary(t *testing.T) {
+	info := &models.CommitInfo{
+		Message:      "Add retry logic\n\nRetries failed uploads.",
+		Author:       "Jane",
+		Date:         time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		FilesChanged: 3,
+	}
+	if got, want := formatCommitSummary(info), `"Add retry logic" by Jane on 2024-05-01, 3 files changed`; got != want {
+		t.Fatalf("formatCommitSummary = %q, want %q", got, want)
+	}
+
+	info.Message = strings.Repeat("x", 150)
+	info.FilesChanged = 1
+	got := formatCommitSummary(info)
+	if !strings.Contains(got, strings.Repeat("x", commitSummaryMaxSubject-3)+`..."`) || strings.Contains(got, strings.Repeat("x", commitSummaryMaxSubject)) {
+		t.Fatalf("formatCommitSummary did not truncate the subject: %q", got)
+	}
+	if !strings.HasSuffix(got, ", 1 file changed") {
+		t.Fatalf("formatCommitSummary = %q, want singular file count", got)
+	}
+}
+
+func TestDocsRelativePaths(t *testing.T) {
+	got, err := docsRelativePaths([]string{"docs/guide.md", "docs/api/index.mdx"}, "docs")
+	if err != nil {
+		t.Fatalf("docsRelativePaths: %v", err)
+	}
+	if want := []string{"guide.md", "api/index.mdx"}; !slices.Equal(got, want) {
+		t.Fatalf("docsRelativePaths = %v, want %v", got, want)
+	}
+
+	got, err = docsRelativePaths([]string{"guide.md"}, ".")
+	if err != nil {
+		t.Fatalf("docsRelativePaths at repo root: %v", err)
+	}
+	if want := []string{"guide.md"}; !slices.Equal(got, want) {
+		t.Fatalf("docsRelativePaths at repo root = %v, want %v", got, want)
+	}
+
+	for _, outside := range []string{"README.md", "docs/../README.md", "docs"} {
+		if _, err := docsRelativePaths([]string{outside}, "docs"); err == nil {
+			t.Fatalf("docsRelativePaths(%q) expected an error", outside)
+		}
+	}
+}
+
+// newDocSubmoduleFixture creates a superproject with a committed docs
+// submodule at "docs", laid out as git submodule absorbgitdirs leaves it: the
+// submodule's .git is a file pointing into the superproject's .git/modules.
+func newDocSubmoduleFixture(t *testing.T) (string, *git.Repository, *git.Repository) {
+	t.Helper()
+	super := t.TempDir()
+	superRepo, err := git.PlainInit(super, false)
+	if err != nil {
+		t.Fatalf("init superproject: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(super, ".gitmodules"), []byte("[submodule \"docs\"]\n\tpath = docs\n\turl = ../docs.git\n"), 0o644); err != nil {
+		t.Fatalf("write .gitmodules: %v", err)
+	}
+	superWt, err := superRepo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := superWt.Add(".gitmodules"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := superWt.Commit("add docs submodule", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit superproject: %v", err)
+	}
+
+	docsDir := filepath.Join(super, "docs")
+	if _, err := git.PlainInit(docsDir, false); err != nil {
+		t.Fatalf("init submodule: %v", err)
+	}
+	gitDir := filepath.Join(super, ".git", "modules", "docs")
+	if err := os.MkdirAll(filepath.Dir(gitDir), 0o755); err != nil {
+		t.Fatalf("mkdir modules: %v", err)
+	}
+	if err := os.Rename(filepath.Join(docsDir, ".git"), gitDir); err != nil {
+		t.Fatalf("absorb git dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, ".git"), []byte("gitdir: ../.git/modules/docs\n"), 0o644); err != nil {
+		t.Fatalf("write .git file: %v", err)
+	}
+	docsRepo, err := git.PlainOpen(docsDir)
+	if err != nil {
+		t.Fatalf("open submodule: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "index.md"), []byte("# Docs\n"), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	docsWt, err := docsRepo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := docsWt.Add("index.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := docsWt.Commit("initial docs", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit submodule: %v", err)
+	}
+	return super, superRepo, docsRepo
+}
+
+func TestNewDocRepoConfigResolvesSubmoduleRoot(t *testing.T) {
+	super, _, _ := newDocSubmoduleFixture(t)
+	if err := os.MkdirAll(filepath.Join(super, "docs", "content"), 0o755); err != nil {
+		t.Fatalf("mkdir content: %v", err)
+	}
+
+	cfg, err := newDocRepoConfig(filepath.Join(super, "docs", "content"), super)
+	if err != nil {
+		t.Fatalf("doc config: %v", err)
+	}
+	if cfg.RepoRoot != filepath.Join(super, "docs") {
+		t.Fatalf("expected the submodule as repository root, got %s", cfg.RepoRoot)
+	}
+	if cfg.DocsRelative != "content" {
+		t.Fatalf("expected docs relative to the submodule, got %s", cfg.DocsRelative)
+	}
+	if cfg.SharedWithCode {
+		t.Fatal("a submodule is not shared with the superproject's code")
+	}
+	if cfg.SuperprojectRoot != super || cfg.SubmodulePath != "docs" {
+		t.Fatalf("unexpected superproject %q and submodule path %q", cfg.SuperprojectRoot, cfg.SubmodulePath)
+	}
+}
+
+func TestNewDocRepoConfigRejectsUninitializedSubmodule(t *testing.T) {
+	super, _, _ := newDocSubmoduleFixture(t)
+	// An uninitialized submodule is an empty directory
+	if err := os.RemoveAll(filepath.Join(super, "docs")); err != nil {
+		t.Fatalf("remove submodule: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(super, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+
+	_, err := newDocRepoConfig(filepath.Join(super, "docs"), super)
+	if err == nil || !strings.Contains(err.Error(), "not initialized") {
+		t.Fatalf("expected an uninitialized submodule error, got %v", err)
+	}
+}
+
+func TestUpdateSubmodulePointerCommitsGitlink(t *testing.T) {
+	_, superRepo, docsRepo := newDocSubmoduleFixture(t)
+	head, err := docsRepo.Head()
+	if err != nil {
+		t.Fatalf("submodule head: %v", err)
+	}
+	svc := NewGitService()
+
+	hash, err := svc.UpdateSubmodulePointer(superRepo, "docs", head.Hash().String())
+	if err != nil {
+		t.Fatalf("update pointer: %v", err)
+	}
+	commit, err := superRepo.CommitObject(hash)
+	if err != nil {
+		t.Fatalf("load commit: %v", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatalf("load tree: %v", err)
+	}
+	entry, err := tree.FindEntry("docs")
+	if err != nil {
+		t.Fatalf("docs entry missing: %v", err)
+	}
+	if entry.Mode != filemode.Submodule || entry.Hash != head.Hash() {
+		t.Fatalf("expected a gitlink to %s, got mode %v hash %s", head.Hash(), entry.Mode, entry.Hash)
+	}
+
+	// Pointing at the same commit again is a no-op
+	again, err := svc.UpdateSubmodulePointer(superRepo, "docs", head.Hash().String())
+	if err != nil {
+		t.Fatalf("update pointer again: %v", err)
+	}
+	if !again.IsZero() {
+		t.Fatalf("expected no commit for an unchanged pointer, got %s", again)
+	}
+}
+
+// newManualEditsFixture commits a docs page at base, a generated update of it
+// and then manual, returning the repository and the base and edited commits.
+func newManualEditsFixture(t *testing.T, manual map[string]string) (*git.Repository, plumbing.Hash, plumbing.Hash) {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	commit := func(message string, files map[string]string) plumbing.Hash {
+		for name, content := range files {
+			path := filepath.Join(root, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("write %s: %v", name, err)
+			}
+			if _, err := wt.Add(name); err != nil {
+				t.Fatalf("add %s: %v", name, err)
+			}
+		}
+		hash, err := wt.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+		return hash
+	}
+
+	base := commit("initial", map[string]string{"docs/guide.md": "# Guide\n\nIntro.\n\nUsage.\n\nEnd.\n"})
+	commit(generatedDocsCommitMessage, map[string]string{"docs/guide.md": "# Guide\n\nIntro.\n\nUsage v1.\n\nEnd.\n"})
+	return repo, base, commit("manual edits", manual)
+}
+
+func TestCarryManualEditsKeepsNonConflictingEdits(t *testing.T) {
+	repo, base, edited := newManualEditsFixture(t, map[string]string{
+		"docs/guide.md": "# Guide\n\nIntro, reviewed.\n\nUsage v1.\n\nEnd.\n",
+		"docs/faq.md":   "# FAQ\n",
+	})
+	// The regeneration starts from the base and rewrites the usage section again
+	workspace := tempDocWorkspace{repoPath: t.TempDir()}
+	if err := os.MkdirAll(filepath.Join(workspace.repoPath, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	guide := filepath.Join(workspace.repoPath, "docs", "guide.md")
+	if err := os.WriteFile(guide, []byte("# Guide\n\nIntro.\n\nUsage v2.\n\nEnd.\n"), 0o644); err != nil {
+		t.Fatalf("write guide: %v", err)
+	}
+
+	preserved, conflicts, err := carryManualEdits(context.Background(), "test", repo, workspace, "docs", base, edited)
+	if err != nil {
+		t.Fatalf("carry manual edits: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", conflicts)
+	}
+	if !slices.Equal(preserved, []string{"docs/faq.md", "docs/guide.md"}) {
+		t.Fatalf("unexpected preserved files: %v", preserved)
+	}
+	data, err := os.ReadFile(guide)
+	if err != nil {
+		t.Fatalf("read guide: %v", err)
+	}
+	if want := "# Guide\n\nIntro, reviewed.\n\nUsage v2.\n\nEnd.\n"; string(data) != want {
+		t.Fatalf("expected the manual edit merged into the regenerated page, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(workspace.repoPath, "docs", "faq.md")); err != nil {
+		t.Fatalf("expected the manually added page to be kept: %v", err)
+	}
+}
+
+func TestCarryManualEditsMarksConflicts(t *testing.T) {
+	repo, base, edited := newManualEditsFixture(t, map[string]string{
+		"docs/guide.md": "# Guide\n\nIntro.\n\nUsage, hand-tuned.\n\nEnd.\n",
+	})
+	workspace := tempDocWorkspace{repoPath: t.TempDir()}
+	if err := os.MkdirAll(filepath.Join(workspace.repoPath, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	guide := filepath.Join(workspace.repoPath, "docs", "guide.md")
+	if err := os.WriteFile(guide, []byte("# Guide\n\nIntro.\n\nUsage v2.\n\nEnd.\n"), 0o644); err != nil {
+		t.Fatalf("write guide: %v", err)
+	}
+
+	preserved, conflicts, err := carryManualEdits(context.Background(), "test", repo, workspace, "docs", base, edited)
+	if err != nil {
+		t.Fatalf("carry manual edits: %v", err)
+	}
+	if len(preserved) != 0 {
+		t.Fatalf("expected nothing merged cleanly, got %v", preserved)
+	}
+	if len(conflicts) != 1 || conflicts[0].Path != "docs/guide.md" || conflicts[0].Regions != 1 {
+		t.Fatalf("expected one conflict region in docs/guide.md, got %+v", conflicts)
+	}
+	data, err := os.ReadFile(guide)
+	if err != nil {
+		t.Fatalf("read guide: %v", err)
+	}
+	want := "# Guide\n\nIntro.\n\n<<<<<<< docs branch (manual edits)\nUsage, hand-tuned.\n=======\nUsage v2.\n>>>>>>> regenerated\n\nEnd.\n"
+	if string(data) != want {
+		t.Fatalf("expected the conflict to be marked, got %q", data)
+	}
+}
+
+// regenerationFixture is a repository whose docs branch is regenerated,
+// refined and edited by hand the way documentation runs and users do.
+type regenerationFixture struct {
+	t    *testing.T
+	repo *git.Repository
+	wt   *git.Worktree
+	cfg  *docRepoConfig
+	base plumbing.Hash
+	root string
+}
+
+const regenerationDocsBranch = "docs/feature"
+
+func newRegenerationFixture(t *testing.T) *regenerationFixture {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	guidePath := filepath.Join(root, "docs", "guide.md")
+	if err := os.MkdirAll(filepath.Dir(guidePath), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(guidePath, []byte("# Guide\n\nIntro.\n\nUsage.\n\nEnd.\n"), 0o644); err != nil {
+		t.Fatalf("write guide: %v", err)
+	}
+	if _, err := wt.Add("docs/guide.md"); err != nil {
+		t.Fatalf("add guide: %v", err)
+	}
+	base, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	cfg, err := newDocRepoConfig(filepath.Join(root, "docs"), "")
+	if err != nil {
+		t.Fatalf("doc config: %v", err)
+	}
+	cfg.WorkspaceBase = t.TempDir()
+	return &regenerationFixture{t: t, repo: repo, wt: wt, cfg: cfg, base: base, root: root}
+}
+
+// regenerate rewrites the usage section from the base, carrying the manual
+// edits of the docs branch, and returns the guide at the new branch head.
+func (f *regenerationFixture) regenerate(usage string) string {
+	f.t.Helper()
+	ctx := context.Background()
+	var edited plumbing.Hash
+	if ref, err := f.repo.Reference(plumbing.NewBranchReferenceName(regenerationDocsBranch), true); err == nil {
+		edited = ref.Hash()
+	}
+	workspace, cleanup, err := createTempDocRepo(ctx, "test", f.cfg, regenerationDocsBranch, "master", f.base)
+	if err != nil {
+		f.t.Fatalf("create workspace: %v", err)
+	}
+	defer cleanup()
+	content := "# Guide\n\nIntro.\n\n" + usage + "\n\nEnd.\n"
+	if err := os.WriteFile(filepath.Join(workspace.docsPath, "guide.md"), []byte(content), 0o644); err != nil {
+		f.t.Fatalf("write guide: %v", err)
+	}
+	carry := func() error {
+		_, _, err := carryManualEdits(ctx, "test", f.repo, workspace, f.cfg.DocsRelative, f.base, edited)
+		return err
+	}
+	if _, err := propagateDocChangesWithEdits(ctx, "test", workspace, f.repo, regenerationDocsBranch, f.cfg.DocsRelative, carry); err != nil {
+		f.t.Fatalf("propagate: %v", err)
+	}
+	return f.guide()
+}
+
+// refine replaces old with new in the guide at the docs branch head, as a
+// refinement run does.
+func (f *regenerationFixture) refine(old, new string) {
+	f.t.Helper()
+	ctx := context.Background()
+	workspace, cleanup, err := createTempDocRepoAtBranchHead(ctx, "test", f.cfg, regenerationDocsBranch, "", plumbing.ZeroHash)
+	if err != nil {
+		f.t.Fatalf("create workspace: %v", err)
+	}
+	defer cleanup()
+	path := filepath.Join(workspace.docsPath, "guide.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		f.t.Fatalf("read guide: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), 0o644); err != nil {
+		f.t.Fatalf("write guide: %v", err)
+	}
+	if _, err := propagateDocChanges(ctx, "test", workspace, f.repo, regenerationDocsBranch, f.cfg.DocsRelative); err != nil {
+		f.t.Fatalf("propagate: %v", err)
+	}
+}
+
+// editByHand commits content as the guide on the docs branch.
+func (f *regenerationFixture) editByHand(content string) {
+	f.t.Helper()
+	if err := f.wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(regenerationDocsBranch)}); err != nil {
+		f.t.Fatalf("checkout docs branch: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(f.root, "docs", "guide.md"), []byte(content), 0o644); err != nil {
+		f.t.Fatalf("edit guide: %v", err)
+	}
+	if _, err := f.wt.Add("docs/guide.md"); err != nil {
+		f.t.Fatalf("add guide: %v", err)
+	}
+	if _, err := f.wt.Commit("manual edits", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		f.t.Fatalf("commit manual edits: %v", err)
+	}
+	if err := f.wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}); err != nil {
+		f.t.Fatalf("checkout master: %v", err)
+	}
+}
+
+// guide returns the guide at the docs branch head.
+func (f *regenerationFixture) guide() string {
+	f.t.Helper()
+	ref, err := f.repo.Reference(plumbing.NewBranchReferenceName(regenerationDocsBranch), true)
+	if err != nil {
+		f.t.Fatalf("docs branch missing: %v", err)
+	}
+	commit, err := f.repo.CommitObject(ref.Hash())
+	if err != nil {
+		f.t.Fatalf("load docs commit: %v", err)
+	}
+	file, err := commit.File("docs/guide.md")
+	if err != nil {
+		f.t.Fatalf("guide missing from docs branch: %v", err)
+	}
+	text, err := file.Contents()
+	if err != nil {
+		f.t.Fatalf("read guide: %v", err)
+	}
+	return text
+}
+
+func TestRegenerationKeepsManualEditsAcrossRegenerations(t *testing.T) {
+	f := newRegenerationFixture(t)
+	f.regenerate("Usage v1.")
+	f.editByHand("# Guide\n\nIntro, reviewed.\n\nUsage v1.\n\nEnd.\n")
+
+	want := "# Guide\n\nIntro, reviewed.\n\nUsage v2.\n\nEnd.\n"
+	if got := f.regenerate("Usage v2."); got != want {
+		t.Fatalf("first regeneration: expected the manual edit kept, got %q", got)
+	}
+	want = "# Guide\n\nIntro, reviewed.\n\nUsage v3.\n\nEnd.\n"
+	if got := f.regenerate("Usage v3."); got != want {
+		t.Fatalf("second regeneration: expected the manual edit kept, got %q", got)
+	}
+}
+
+func TestRegenerationAfterRefineKeepsEarlierManualEdits(t *testing.T) {
+	f := newRegenerationFixture(t)
+	f.regenerate("Usage v1.")
+	f.editByHand("# Guide\n\nIntro, reviewed.\n\nUsage v1.\n\nEnd.\n")
+	// The refinement commit has the generated message but is not a generation
+	f.refine("End.", "End, refined.")
+
+	want := "# Guide\n\nIntro, reviewed.\n\nUsage v2.\n\nEnd, refined.\n"
+	if got := f.regenerate("Usage v2."); got != want {
+		t.Fatalf("expected the manual edit and the refinement kept, got %q", got)
+	}
+}
+
+func TestGeminiModelOptionsFromSettings(t *testing.T) {
+	model := &models.LLMModel{APIName: "gemini-3.5-flash", ReasoningEffort: "medium"}
+
+	opts, err := geminiModelOptions(model, models.AppSettings{})
+	if err != nil {
+		t.Fatalf("geminiModelOptions: %v", err)
+	}
+	if opts.SafetySettings != nil || opts.SystemInstruction != "" {
+		t.Fatalf("expected the client defaults without settings, got %+v", opts)
+	}
+
+	opts, err = geminiModelOptions(model, models.AppSettings{
+		GeminiSafetyThreshold:   "BLOCK_NONE",
+		GeminiSystemInstruction: "Write in British English.",
+	})
+	if err != nil {
+		t.Fatalf("geminiModelOptions: %v", err)
+	}
+	if opts.Model != "gemini-3.5-flash" || opts.SystemInstruction != "Write in British English." {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+	if len(opts.SafetySettings) == 0 {
+		t.Fatalf("expected safety settings from the threshold")
+	}
+	for _, setting := range opts.SafetySettings {
+		if setting.Threshold != "BLOCK_NONE" {
+			t.Fatalf("expected BLOCK_NONE for %s, got %s", setting.Category, setting.Threshold)
+		}
+	}
+
+	if _, err := geminiModelOptions(model, models.AppSettings{GeminiSafetyThreshold: "SOMETIMES"}); err == nil {
+		t.Fatalf("expected an unknown threshold to be rejected")
+	}
+}
+
+func TestPersistCanceledRunSavesProgress(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatalf("write guide: %v", err)
+	}
+	if _, err := wt.Add("docs/guide.md"); err != nil {
+		t.Fatalf("add guide: %v", err)
+	}
+	base, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	var updates map[string]interface{}
+	sessions := NewGenerationSessionService(&mocks.GenerationSessionRepositoryMock{
+		UpdateByIDFunc: func(id uint, u map[string]interface{}) error {
+			if id != 7 {
+				t.Fatalf("expected session 7 to be updated, got %d", id)
+			}
+			updates = u
+			return nil
+		},
+	})
+	s := &ClientService{gitService: NewGitService(), generationSessions: sessions}
+
+	llm := &client.LLMClient{}
+	if err := llm.RestoreConversationHistoryJSON(`[{"role":"user","content":"Document the change"},{"role":"assistant","content":"Halfway there"}]`, ""); err != nil {
+		t.Fatalf("restore history: %v", err)
+	}
+	session := &models.GenerationSession{ID: 7, DocsBranch: "docs/main"}
+	workspace := tempDocWorkspace{repoPath: root, docsPath: filepath.Join(root, "docs")}
+
+	err = s.persistCanceledRun(context.Background(), "session:7", session, &sessionRuntime{client: llm}, workspace, repo, &docRepoConfig{DocsRelative: "docs"}, base, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to still end as canceled, got %v", err)
+	}
+	if updates["paused"] != true || updates["canceled"] != true {
+		t.Fatalf("expected the session flagged as paused and canceled, got %v", updates)
+	}
+	history, _ := updates["messages_json"].(string)
+	if !strings.Contains(history, "Halfway there") {
+		t.Fatalf("expected the partial history to be saved, got %q", history)
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName("docs/main"), true); err != nil {
+		t.Fatalf("expected the docs branch to be created: %v", err)
+	}
+}
+
+func TestCheckFrontmatterSkipsNonFumadocsProjects(t *testing.T) {
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	if err := os.MkdirAll(docs, 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docs, "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatalf("write guide: %v", err)
+	}
+	s := &ClientService{fumadocs: &FumadocsService{}}
+	workspace := tempDocWorkspace{repoPath: root, docsPath: docs}
+	files := []models.DocChangedFile{{Path: "docs/guide.md", Status: "added"}}
+
+	if got := s.checkFrontmatter(context.Background(), "test", workspace, files); got != nil {
+		t.Fatalf("expected no frontmatter checks outside a Fumadocs site, got %v", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(docs, FumadocsMetaFile), []byte(`{"pages":["guide"]}`), 0o644); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+	if got := s.checkFrontmatter(context.Background(), "test", workspace, files); len(got["docs/guide.md"]) == 0 {
+		t.Fatalf("expected the missing title to be reported in a Fumadocs site, got %v", got)
+	}
+}
+
+func TestLintMDXComponentsSkipsNonFumadocsProjects(t *testing.T) {
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	if err := os.MkdirAll(docs, 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docs, "guide.mdx"), []byte("# Guide\n\n<Banner />\n"), 0o644); err != nil {
+		t.Fatalf("write guide: %v", err)
+	}
+	s := &ClientService{fumadocs: &FumadocsService{}}
+	project := &models.RepoLink{}
+	workspace := tempDocWorkspace{repoPath: root, docsPath: docs}
+	files := []models.DocChangedFile{{Path: "docs/guide.mdx", Status: "added"}}
+
+	if got := s.lintMDXComponents(context.Background(), "test", project, workspace, files); got != nil {
+		t.Fatalf("expected no component lint outside a Fumadocs site, got %v", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(docs, FumadocsMetaFile), []byte(`{"pages":["guide"]}`), 0o644); err != nil {
+		t.Fatalf("write meta: %v", err)
+	}
+	if got := s.lintMDXComponents(context.Background(), "test", project, workspace, files); len(got) != 1 || got[0].Component != "Banner" {
+		t.Fatalf("expected the unknown component to be reported in a Fumadocs site, got %v", got)
+	}
+}
+
+func TestEmitRuntimeWarningsRecordsThemForTheSession(t *testing.T) {
+	recorder := events.NewRecorder(10)
+	ctx := events.WithRecorder(context.Background(), recorder)
+	runtime := &sessionRuntime{warnings: []string{"reasoning effort is not supported by this model; ignoring it"}}
+
+	emitRuntimeWarnings(ctx, "session:3", runtime)
+
+	recorded := recorder.Drain()
+	if len(recorded) != 1 || recorded[0].SessionKey != "session:3" || recorded[0].Type != events.EventWarn {
+		t.Fatalf("expected one warning recorded for session:3, got %+v", recorded)
+	}
+	if runtime.warnings != nil {
+		t.Fatalf("expected the warnings to be emitted once, got %v", runtime.warnings)
+	}
+}
+
+func TestResumeRequiresSavedProgress(t *testing.T) {
+	sessions := NewGenerationSessionService(&mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id}, nil
+		},
+	})
+	s := &ClientService{generationSessions: sessions}
+
+	if _, err := s.ResumeSession(7, ""); err == nil || !strings.Contains(err.Error(), "no saved progress") {
+		t.Fatalf("expected ResumeSession to refuse a session without saved progress, got %v", err)
+	}
+	if _, err := s.ResumeCanceledRun(7, "focus on the API pages"); err == nil || !strings.Contains(err.Error(), "no saved progress") {
+		t.Fatalf("expected ResumeCanceledRun to refuse a session without saved progress, got %v", err)
+	}
+}
+
+func TestMaintainFumadocsMetaRespectsRefinementAllowlist(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	docs := filepath.Join(root, "docs")
+	if err := os.MkdirAll(docs, 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	const meta = `{"pages":["index"]}`
+	for name, content := range map[string]string{"index.mdx": "---\ntitle: Home\n---\n", FumadocsMetaFile: meta} {
+		if err := os.WriteFile(filepath.Join(docs, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if _, err := wt.Add("docs"); err != nil {
+		t.Fatalf("add docs: %v", err)
+	}
+	if _, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(docs, "setup.mdx"), []byte("---\ntitle: Setup\n---\n"), 0o644); err != nil {
+		t.Fatalf("write setup: %v", err)
+	}
+
+	s := &ClientService{fumadocs: &FumadocsService{}, gitService: NewGitService()}
+	project := &models.RepoLink{MaintainFumadocsMeta: true}
+	workspace := tempDocWorkspace{repoPath: root, docsPath: docs}
+	recorder := events.NewRecorder(10)
+	ctx := events.WithRecorder(context.Background(), recorder)
+
+	s.maintainFumadocsMeta(ctx, "session:5", project, workspace, "docs", []string{"setup.mdx"})
+	if data, _ := os.ReadFile(filepath.Join(docs, FumadocsMetaFile)); string(data) != meta {
+		t.Fatalf("expected meta.json outside the allowlist to be left alone, got %s", data)
+	}
+	recorded := recorder.Drain()
+	if len(recorded) != 1 || recorded[0].Type != events.EventWarn || !strings.Contains(recorded[0].Message, "docs/meta.json") {
+		t.Fatalf("expected one warning about docs/meta.json, got %+v", recorded)
+	}
+
+	s.maintainFumadocsMeta(ctx, "session:5", project, workspace, "docs", []string{"setup.mdx", FumadocsMetaFile})
+	if data, _ := os.ReadFile(filepath.Join(docs, FumadocsMetaFile)); !strings.Contains(string(data), "setup") {
+		t.Fatalf("expected an allowed meta.json to list the new page, got %s", data)
+	}
+}
+
+// newRetentionFixture returns a docs repository config whose temp workspaces
+// go to their own directory, the base commit to create them at, and a client
+// service with RetainFailedWorkspaces enabled for that directory.
+func newRetentionFixture(t *testing.T) (*docRepoConfig, plumbing.Hash, *ClientService) {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir docs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "index.md"), []byte("# Docs\n"), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := wt.Add("docs/index.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	baseHash, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	cfg, err := newDocRepoConfig(filepath.Join(root, "docs"), "")
+	if err != nil {
+		t.Fatalf("doc config: %v", err)
+	}
+	cfg.WorkspaceBase = t.TempDir()
+	settings := &models.AppSettings{TempBaseDir: cfg.WorkspaceBase, RetainFailedWorkspaces: true}
+	s := &ClientService{appSettings: NewAppSettingsService(&mocks.AppSettingsRepositoryMock{
+		GetFunc: func(ctx context.Context) (*models.AppSettings, error) {
+			copied := *settings
+			return &copied, nil
+		},
+	})}
+	return cfg, baseHash, s
+}
+
+func TestCleanupRetainedWorkspacesPrunesLinkedWorktrees(t *testing.T) {
+	if gitExecutable() == "" {
+		t.Skip("git binary not available")
+	}
+	cfg, baseHash, s := newRetentionFixture(t)
+	ctx := context.Background()
+
+	workspace, cleanup, err := createTempDocRepo(ctx, "test", cfg, "docs/feature", "main", baseHash)
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	if !workspace.sharedStore {
+		t.Fatal("expected a linked worktree")
+	}
+	s.releaseTempWorkspace(ctx, "test", workspace, cleanup, errors.New("model failed"))
+	if _, err := os.Stat(filepath.Join(workspace.repoPath, retainedWorkspaceMarker)); err != nil {
+		t.Fatalf("expected the failed run's workspace to be retained: %v", err)
+	}
+
+	removed, err := s.CleanupRetainedWorkspaces(0)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one retained workspace removed, got %d (%v)", removed, err)
+	}
+	list, err := runGit(ctx, gitExecutable(), cfg.RepoRoot, "worktree", "list", "--porcelain")
+	if err != nil {
+		t.Fatalf("worktree list: %v", err)
+	}
+	if strings.Contains(list, filepath.Base(workspace.repoPath)) {
+		t.Fatalf("expected the removed worktree to be pruned, got:\n%s", list)
+	}
+}
+
+func TestReleaseTempWorkspaceDoesNotRetainCanceledRuns(t *testing.T) {
+	if gitExecutable() == "" {
+		t.Skip("git binary not available")
+	}
+	cfg, baseHash, s := newRetentionFixture(t)
+	ctx := context.Background()
+
+	workspace, cleanup, err := createTempDocRepo(ctx, "test", cfg, "docs/feature", "main", baseHash)
+	if err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	s.releaseTempWorkspace(ctx, "test", workspace, cleanup, fmt.Errorf("run canceled, progress saved: %w", context.Canceled))
+	if _, err := os.Stat(workspace.repoPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the canceled run's workspace to be removed, stat err = %v", err)
+	}
 }
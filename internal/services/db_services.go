@@ -17,6 +17,7 @@ type DbServices struct {
 	GenerationSessions GenerationSessionService
 	Templates          TemplateService
 	ModelConfigs       ModelConfigService
+	Profiles           GenerationProfileService
 }
 
 // NewDbServices constructs the service container using repositories backed by db.
@@ -26,13 +27,17 @@ func NewDbServices(db *gorm.DB, fumaDocService FumadocsService, gitService GitSe
 	genSessionRepo := repositories.NewGenerationSessionRepository(db)
 	templateRepo := repositories.NewTemplateRepository(db)
 	modelSettingRepo := repositories.NewModelSettingRepository(db)
+	profileRepo := repositories.NewGenerationProfileRepository(db)
+	templates := NewTemplateService(templateRepo)
+	modelConfigs := NewModelConfigService(modelSettingRepo)
 
 	return &DbServices{
-		RepoLinks:          NewRepoLinkService(repoLinkRepo, fumaDocService, gitService),
+		RepoLinks:          NewRepoLinkService(repoLinkRepo, fumaDocService, gitService, modelConfigs),
 		AppSettings:        NewAppSettingsService(appSettingsRepo),
 		GenerationSessions: NewGenerationSessionService(genSessionRepo),
-		Templates:          NewTemplateService(templateRepo),
-		ModelConfigs:       NewModelConfigService(modelSettingRepo),
+		Templates:          templates,
+		ModelConfigs:       modelConfigs,
+		Profiles:           NewGenerationProfileService(profileRepo, templates, modelConfigs),
 	}
 }
 
@@ -43,6 +48,9 @@ func (db *DbServices) StartDbServices(ctx context.Context) {
 	if db.Templates != nil {
 		db.Templates.Startup(ctx)
 	}
+	if db.Profiles != nil {
+		db.Profiles.Startup(ctx)
+	}
 	if db.ModelConfigs != nil {
 		if err := db.ModelConfigs.Startup(ctx); err != nil {
 			fmt.Printf("failed to start model config service: %v\n", err)
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/models"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// generatedDocsCommitMessage is the message of the commits documentation runs
+// create on a docs branch.
+const generatedDocsCommitMessage = "Generated documentation updates"
+
+// generatedDocsTrailer marks the commits of generation runs, which refinements
+// share the message with. Commits after the newest marked one are manual edits
+// or refinements and are carried over by the next regeneration.
+const generatedDocsTrailer = "Narrabyte-Generated: true"
+
+// manualEditsCommitMessage is the message of the commit a regeneration makes
+// for the manual edits it carried over, on top of its generated commit.
+const manualEditsCommitMessage = "Carry over manual documentation edits"
+
+// Conflict marker labels for manual edits merged into a regeneration.
+const (
+	manualEditsLabel = "docs branch (manual edits)"
+	regeneratedLabel = "regenerated"
+)
+
+// mergeLinesWithConflicts applies the edits ours and theirs made to base.
+// Edits from each side that touch the same or adjacent lines are merged when
+// they produce the same text and otherwise written as a conflict region with
+// git-style markers labelled oursLabel and theirsLabel. It returns the merged
+// text and the number of conflict regions.
+func mergeLinesWithConflicts(base, ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	type sidedHunk struct {
+		lineHunk
+		ours bool
+	}
+	baseLines := splitLinesKeepEnds(base)
+	var hunks []sidedHunk
+	for _, h := range lineHunks(base, ours) {
+		hunks = append(hunks, sidedHunk{h, true})
+	}
+	for _, h := range lineHunks(base, theirs) {
+		hunks = append(hunks, sidedHunk{h, false})
+	}
+	sort.SliceStable(hunks, func(i, j int) bool { return hunks[i].start < hunks[j].start })
+
+	// regionText applies one side's hunks of a group to base lines [start, end)
+	regionText := func(group []sidedHunk, start, end int, ours bool) (string, bool) {
+		var b strings.Builder
+		pos, edited := start, false
+		for _, h := range group {
+			if h.ours != ours {
+				continue
+			}
+			for ; pos < h.start; pos++ {
+				b.WriteString(baseLines[pos])
+			}
+			for _, line := range h.lines {
+				b.WriteString(line)
+			}
+			pos, edited = h.end, true
+		}
+		for ; pos < end; pos++ {
+			b.WriteString(baseLines[pos])
+		}
+		return b.String(), edited
+	}
+
+	var out strings.Builder
+	conflicts, pos := 0, 0
+	for i := 0; i < len(hunks); {
+		start, end := hunks[i].start, hunks[i].end
+		j := i + 1
+		for ; j < len(hunks) && hunks[j].start <= end; j++ {
+			end = max(end, hunks[j].end)
+		}
+		group := hunks[i:j]
+		i = j
+
+		for ; pos < start; pos++ {
+			out.WriteString(baseLines[pos])
+		}
+		pos = end
+		ourText, ourEdit := regionText(group, start, end, true)
+		theirText, theirEdit := regionText(group, start, end, false)
+		switch {
+		case !theirEdit:
+			out.WriteString(ourText)
+		case !ourEdit || ourText == theirText:
+			out.WriteString(theirText)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + oursLabel + "\n")
+			writeConflictSide(&out, ourText)
+			out.WriteString("=======\n")
+			writeConflictSide(&out, theirText)
+			out.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+	}
+	for ; pos < len(baseLines); pos++ {
+		out.WriteString(baseLines[pos])
+	}
+	return out.String(), conflicts
+}
+
+// writeConflictSide writes one side of a conflict region, ending it with a
+// newline so the next marker starts its own line.
+func writeConflictSide(out *strings.Builder, text string) {
+	out.WriteString(text)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		out.WriteString("\n")
+	}
+}
+
+// editedDocsHead returns the commit an existing docs branch points at when
+// PreserveManualEdits is enabled, so a regeneration merges its manual edits
+// instead of refusing the branch. It is zero when the setting is off, the
+// branch does not exist or another run is writing to it.
+func (s *ClientService) editedDocsHead(docRepo *git.Repository, docsBranch string) (plumbing.Hash, error) {
+	if !s.workspaceSettings().PreserveManualEdits || s.isDocsBranchInProgress(docsBranch) {
+		return plumbing.ZeroHash, nil
+	}
+	ref, err := docRepo.Reference(plumbing.NewBranchReferenceName(docsBranch), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read documentation branch '%s': %w", docsBranch, err)
+	}
+	return ref.Hash(), nil
+}
+
+// lastGeneratedCommit returns the newest commit a generation run created on
+// the first-parent history of head since it forked from base. The changes
+// after it are the manual edits; when there is none, the fork point is
+// returned and every change on the branch counts as one. Branches generated
+// before commits carried generatedDocsTrailer fall back to the newest commit
+// with generatedDocsCommitMessage.
+func lastGeneratedCommit(repo *git.Repository, head, base plumbing.Hash) (plumbing.Hash, error) {
+	headCommit, err := repo.CommitObject(head)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to load commit %s: %w", head.String(), err)
+	}
+	baseCommit, err := repo.CommitObject(base)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to load commit %s: %w", base.String(), err)
+	}
+	bases, err := mergeBases(headCommit, baseCommit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(bases) == 0 {
+		return plumbing.ZeroHash, fmt.Errorf("docs branch and its base have unrelated histories")
+	}
+	fork := bases[0].Hash
+
+	legacy := plumbing.ZeroHash
+	for c := headCommit; c.Hash != fork; {
+		if hasGeneratedDocsTrailer(c.Message) {
+			return c.Hash, nil
+		}
+		if legacy.IsZero() && strings.TrimSpace(c.Message) == generatedDocsCommitMessage {
+			legacy = c.Hash
+		}
+		if c.NumParents() == 0 {
+			break
+		}
+		if c, err = c.Parent(0); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to walk docs branch history: %w", err)
+		}
+	}
+	if !legacy.IsZero() {
+		return legacy, nil
+	}
+	return fork, nil
+}
+
+// hasGeneratedDocsTrailer reports whether generatedDocsTrailer is among the
+// trailers, the last paragraph, of a commit message.
+func hasGeneratedDocsTrailer(message string) bool {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	if len(paragraphs) < 2 {
+		return false
+	}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if strings.TrimSpace(line) == generatedDocsTrailer {
+			return true
+		}
+	}
+	return false
+}
+
+// carryManualEdits merges the manual edits made on the docs branch at edited
+// into the regenerated files in workspace, which was checked out at baseHash.
+// Per file, edits the regeneration did not touch are taken as they are, and
+// files both changed are merged line by line with conflicts marked in the
+// file. It returns the files whose edits carried over cleanly and the
+// conflicts left for review. Nothing is done when edited is zero.
+func carryManualEdits(ctx context.Context, sessionKey string, docRepo *git.Repository, workspace tempDocWorkspace, docsRelative string, baseHash, edited plumbing.Hash) ([]string, []models.DocMergeConflict, error) {
+	if edited.IsZero() {
+		return nil, nil, nil
+	}
+	mergeBase, err := lastGeneratedCommit(docRepo, edited, baseHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mergeBase == edited {
+		emitSessionInfo(ctx, sessionKey, "PreserveManualEdits: docs branch has no manual edits to carry over")
+		return nil, nil, nil
+	}
+	fromCommit, err := docRepo.CommitObject(mergeBase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load commit %s: %w", mergeBase.String(), err)
+	}
+	toCommit, err := docRepo.CommitObject(edited)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load commit %s: %w", edited.String(), err)
+	}
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load tree: %w", err)
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load tree: %w", err)
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to diff manual edits: %w", err)
+	}
+
+	prefix := filepath.ToSlash(filepath.Clean(docsRelative))
+	if prefix == "." {
+		prefix = ""
+	}
+	var (
+		preserved []string
+		conflicts []models.DocMergeConflict
+	)
+	for _, change := range changes {
+		name := changeName(change)
+		if !hasPathPrefix(name, prefix) {
+			continue
+		}
+		conflict, err := carryManualEdit(docRepo, workspace, change)
+		if err != nil {
+			return nil, nil, err
+		}
+		if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+		} else {
+			preserved = append(preserved, name)
+		}
+	}
+	sort.Strings(preserved)
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("PreserveManualEdits: carried manual edits of %d file(s) into the regenerated docs", len(preserved)))
+	for _, c := range conflicts {
+		emitSessionWarn(ctx, sessionKey, fmt.Sprintf("PreserveManualEdits: %s: %s", c.Path, c.Message))
+	}
+	return preserved, conflicts, nil
+}
+
+// carryManualEdit applies one manually edited file to the workspace and
+// returns the conflict it left, if any.
+func carryManualEdit(docRepo *git.Repository, workspace tempDocWorkspace, change *object.Change) (*models.DocMergeConflict, error) {
+	name := changeName(change)
+	hasBase, hasOurs := change.From.Name != "", change.To.Name != ""
+	if hasOurs && !change.To.TreeEntry.Mode.IsFile() {
+		return nil, nil
+	}
+	var base, ours []byte
+	var err error
+	if hasBase {
+		if base, err = readBlob(docRepo.Storer, change.From.TreeEntry.Hash); err != nil {
+			return nil, err
+		}
+	}
+	if hasOurs {
+		if ours, err = readBlob(docRepo.Storer, change.To.TreeEntry.Hash); err != nil {
+			return nil, err
+		}
+	}
+	target := filepath.Join(workspace.repoPath, filepath.FromSlash(name))
+	theirs, err := os.ReadFile(target)
+	hasTheirs := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read regenerated %s: %w", name, err)
+	}
+
+	switch {
+	case hasTheirs == hasBase && bytes.Equal(theirs, base):
+		// The regeneration left the file alone
+		return nil, writeMergedDoc(target, ours, hasOurs)
+	case hasTheirs == hasOurs && bytes.Equal(theirs, ours):
+		return nil, nil
+	case !hasOurs:
+		return &models.DocMergeConflict{Path: name, Message: "deleted on the docs branch but rewritten by the regeneration; the regenerated file is kept"}, nil
+	case !hasTheirs:
+		if err := writeMergedDoc(target, ours, true); err != nil {
+			return nil, err
+		}
+		return &models.DocMergeConflict{Path: name, Message: "edited on the docs branch but deleted by the regeneration; the edited file is restored"}, nil
+	case isBinaryContent(base) || isBinaryContent(ours) || isBinaryContent(theirs):
+		return &models.DocMergeConflict{Path: name, Message: "binary file changed on the docs branch and by the regeneration; the regenerated file is kept"}, nil
+	}
+
+	merged, regions := mergeLinesWithConflicts(string(base), string(ours), string(theirs), manualEditsLabel, regeneratedLabel)
+	if err := writeMergedDoc(target, []byte(merged), true); err != nil {
+		return nil, err
+	}
+	if regions == 0 {
+		return nil, nil
+	}
+	return &models.DocMergeConflict{
+		Path:    name,
+		Regions: regions,
+		Message: fmt.Sprintf("%d region(s) changed on the docs branch and by the regeneration are marked for review", regions),
+	}, nil
+}
+
+// writeMergedDoc writes content to path, or removes path when exists is false.
+func writeMergedDoc(path string, content []byte, exists bool) error {
+	if !exists {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
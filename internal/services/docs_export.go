@@ -0,0 +1,209 @@
+package services
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/models"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Formats accepted by ExportDocsChanges.
+const (
+	DocsExportFiles = "files"
+	DocsExportZip   = "zip"
+)
+
+// ExportDocsChanges collects the documentation files a session's docs branch
+// changed against its base, read at the branch head, as a no-commit way to
+// take the generated docs elsewhere. The "files" format returns them as a
+// path to content map; "zip" writes them to a zip archive at archivePath.
+// paths optionally limits the export to some of the changed files.
+func (s *ClientService) ExportDocsChanges(sessionID uint, format string, paths []string, archivePath string) (*models.DocsExport, error) {
+	if sessionID == 0 {
+		return nil, fmt.Errorf("session id is required")
+	}
+	format = strings.TrimSpace(format)
+	archivePath = strings.TrimSpace(archivePath)
+	switch format {
+	case DocsExportFiles:
+	case DocsExportZip:
+		if archivePath == "" {
+			return nil, fmt.Errorf("archive path is required")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	session, err := s.generationSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found: %d", sessionID)
+	}
+	project, err := s.repoLinks.Get(session.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found")
+	}
+	if project.FilesystemTarget {
+		return nil, fmt.Errorf("documentation is written directly to a directory; there is no docs branch to export")
+	}
+
+	repo, docCfg, err := s.openProjectDocRepo(project)
+	if err != nil {
+		return nil, err
+	}
+	baseHash, err := s.sessionDocsBase(session, project, repo, docCfg)
+	if err != nil {
+		return nil, err
+	}
+	docsBranch := strings.TrimSpace(session.DocsBranch)
+	headRef, err := repo.Reference(plumbing.NewBranchReferenceName(docsBranch), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve documentation branch '%s': %w", docsBranch, err)
+	}
+	headTree, err := commitTree(repo.CommitObject(headRef.Hash()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tree of '%s': %w", docsBranch, err)
+	}
+	changes, err := changedFiles(repo, baseHash, headRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff documentation branch: %w", err)
+	}
+
+	prefix := filepath.ToSlash(filepath.Clean(docCfg.DocsRelative))
+	if prefix == "." {
+		prefix = ""
+	}
+	var wanted map[string]bool
+	if len(paths) > 0 {
+		wanted = make(map[string]bool, len(paths))
+		for _, p := range paths {
+			if p = normalizePathSlashes(strings.TrimSpace(p)); p != "" {
+				wanted[p] = true
+			}
+		}
+	}
+
+	export := &models.DocsExport{Format: format, Paths: []string{}}
+	var written []*object.File
+	for _, change := range changes {
+		name, status := changeStatus(change)
+		if status == "renamed" {
+			// The old path is gone from the branch just like a deleted file
+			if old := normalizePathSlashes(change.From.Name); hasPathPrefix(old, prefix) && (wanted == nil || wanted[old]) {
+				export.Paths = append(export.Paths, old)
+				export.Deleted = append(export.Deleted, old)
+			}
+		}
+		if !hasPathPrefix(name, prefix) || (wanted != nil && !wanted[name]) {
+			continue
+		}
+		export.Paths = append(export.Paths, name)
+		if status == "deleted" {
+			export.Deleted = append(export.Deleted, name)
+			continue
+		}
+		file, err := headTree.File(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", name, err)
+		}
+		written = append(written, file)
+	}
+	sort.Strings(export.Paths)
+	sort.Strings(export.Deleted)
+	sort.Slice(written, func(i, j int) bool { return written[i].Name < written[j].Name })
+
+	if format == DocsExportZip {
+		if err := writeDocsArchive(archivePath, written); err != nil {
+			return nil, err
+		}
+		export.ArchivePath = archivePath
+		return export, nil
+	}
+
+	export.Files = make(map[string]string, len(written))
+	for _, file := range written {
+		binary, err := file.IsBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", file.Name, err)
+		}
+		if binary {
+			export.Binary = append(export.Binary, file.Name)
+			continue
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", file.Name, err)
+		}
+		export.Files[file.Name] = content
+	}
+	return export, nil
+}
+
+// commitTree returns the tree of a commit loaded by repo.CommitObject.
+func commitTree(commit *object.Commit, err error) (*object.Tree, error) {
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// writeDocsArchive writes files into a new zip archive at archivePath. A
+// partly written archive is removed on failure.
+func writeDocsArchive(archivePath string, files []*object.File) (err error) {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write archive: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(archivePath)
+		}
+	}()
+
+	zw := zip.NewWriter(out)
+	for _, file := range files {
+		if err := addArchiveFile(zw, file); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+func addArchiveFile(zw *zip.Writer, file *object.File) error {
+	header := &zip.FileHeader{Name: file.Name, Method: zip.Deflate}
+	if mode, err := file.Mode.ToOSFileMode(); err == nil {
+		header.SetMode(mode)
+	}
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add '%s' to archive: %w", file.Name, err)
+	}
+	r, err := file.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read '%s': %w", file.Name, err)
+	}
+	defer r.Close()
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to add '%s' to archive: %w", file.Name, err)
+	}
+	return nil
+}
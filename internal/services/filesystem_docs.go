@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/llm/client"
+	"narrabyte/internal/models"
+	"narrabyte/internal/utils"
+)
+
+// Filesystem targets are documentation directories outside of git. Runs still
+// edit a temporary copy so a failed or canceled run leaves the directory
+// alone, but the result is synced straight back into it: there is no docs
+// branch, commit or diff, only the list of files the run changed.
+
+// newFilesystemDocConfig describes a documentation directory that is written
+// to directly instead of through a git repository.
+func newFilesystemDocConfig(docPath string) (*docRepoConfig, error) {
+	absDoc, err := filepath.Abs(docPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve documentation path: %w", err)
+	}
+	return &docRepoConfig{
+		RepoRoot:     absDoc,
+		DocsPath:     absDoc,
+		DocsRelative: ".",
+		Filesystem:   true,
+	}, nil
+}
+
+// skipFilesystemDocEntry reports whether a path relative to the docs root is
+// left out of filesystem workspaces and syncs: git metadata and the
+// .narrabyte instructions, which the agent reads but never publishes.
+func skipFilesystemDocEntry(rel string) bool {
+	first, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return first == ".git" || first == ".narrabyte"
+}
+
+// createFilesystemDocWorkspace copies a filesystem documentation target into
+// a temporary directory for the agent to edit.
+func createFilesystemDocWorkspace(ctx context.Context, sessionKey string, cfg *docRepoConfig) (tempDocWorkspace, func(), error) {
+	if cfg == nil || !cfg.Filesystem {
+		return tempDocWorkspace{}, nil, fmt.Errorf("filesystem documentation configuration is required")
+	}
+	repoPath, cleanup := newTempRepoDir(ctx, sessionKey, cfg.WorkspaceBase)
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Copying documentation directory into temporary workspace at %s", repoPath))
+
+	if err := os.MkdirAll(repoPath, 0o755); err != nil {
+		cleanup()
+		return tempDocWorkspace{}, nil, fmt.Errorf("failed to create temporary workspace: %w", err)
+	}
+	files, err := listFilesystemDocs(cfg.DocsPath)
+	if err != nil {
+		cleanup()
+		return tempDocWorkspace{}, nil, err
+	}
+	snapshot := make(map[string][sha256.Size]byte, len(files))
+	for _, rel := range files {
+		data, err := copyFilesystemDoc(filepath.Join(cfg.DocsPath, rel), filepath.Join(repoPath, rel))
+		if err != nil {
+			cleanup()
+			return tempDocWorkspace{}, nil, fmt.Errorf("failed to copy documentation file %s: %w", rel, err)
+		}
+		snapshot[rel] = sha256.Sum256(data)
+	}
+
+	if err := copyNarrabyteDir(ctx, sessionKey, cfg.DocsPath, repoPath); err != nil {
+		cleanup()
+		return tempDocWorkspace{}, nil, err
+	}
+	return tempDocWorkspace{repoPath: repoPath, docsPath: repoPath, snapshot: snapshot}, cleanup, nil
+}
+
+// listFilesystemDocs returns the regular files under root, relative to it.
+func listFilesystemDocs(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if skipFilesystemDocEntry(rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documentation files: %w", err)
+	}
+	return files, nil
+}
+
+// copyFilesystemDoc copies source to target and returns the copied content.
+func copyFilesystemDoc(source, target string) ([]byte, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, err
+	}
+	return data, os.WriteFile(target, data, info.Mode().Perm())
+}
+
+// syncFilesystemDocs writes what a run changed in workspace back into the
+// documentation directory. Changes are taken against the snapshot made when
+// the workspace was created, not the directory as it is now: files the run
+// added or modified are copied over and files it deleted are removed, while
+// anything edited or created in the directory during the run and left alone
+// by the run is kept. It returns the changed files sorted by path.
+func syncFilesystemDocs(ctx context.Context, sessionKey string, workspace tempDocWorkspace, docsPath string) ([]models.DocChangedFile, error) {
+	emitSessionInfo(ctx, sessionKey, "Writing documentation changes to the documentation directory")
+
+	edited, err := listFilesystemDocs(workspace.docsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]models.DocChangedFile, 0)
+	kept := make(map[string]bool, len(edited))
+	for _, rel := range edited {
+		kept[rel] = true
+		source := filepath.Join(workspace.docsPath, rel)
+		target := filepath.Join(docsPath, rel)
+		updated, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read edited file %s: %w", rel, err)
+		}
+		status := "added"
+		if before, ok := workspace.snapshot[rel]; ok {
+			if sha256.Sum256(updated) == before {
+				continue
+			}
+			status = "modified"
+			if current, err := os.ReadFile(target); err == nil && sha256.Sum256(current) != before {
+				emitSessionWarn(ctx, sessionKey, fmt.Sprintf("%s was changed in the documentation directory during the run; the run's version replaces it", filepath.ToSlash(rel)))
+			}
+		}
+		if _, err := copyFilesystemDoc(source, target); err != nil {
+			return nil, fmt.Errorf("failed to write documentation file %s: %w", rel, err)
+		}
+		files = append(files, models.DocChangedFile{Path: filepath.ToSlash(rel), Status: status})
+	}
+	for rel := range workspace.snapshot {
+		if kept[rel] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(docsPath, rel)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove documentation file %s: %w", rel, err)
+		}
+		files = append(files, models.DocChangedFile{Path: filepath.ToSlash(rel), Status: "deleted"})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	if len(files) == 0 {
+		emitSessionInfo(ctx, sessionKey, "No documentation changes to write")
+	} else {
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Wrote %d changed documentation files to %s", len(files), docsPath))
+	}
+	return files, nil
+}
+
+// runFilesystemDocs runs the agent through run against a temporary copy of a
+// filesystem documentation target and syncs its changes into the directory.
+// allowedFiles is the allowlist of a scoped refinement, if any. The returned
+// result carries the changed files and checks; callers fill in the session
+// fields.
+func (s *ClientService) runFilesystemDocs(ctx context.Context, sessionKey string, runtime *sessionRuntime, project *models.RepoLink, docCfg *docRepoConfig, allowedFiles []string, run func(streamCtx context.Context, docsPath string) (*client.DocGenerationResponse, error)) (result *models.DocGenerationResult, err error) {
+	if !utils.DirectoryExists(docCfg.DocsPath) {
+		return nil, fmt.Errorf("documentation directory does not exist: %s", docCfg.DocsPath)
+	}
+	streamCtx := runtime.client.StartStream(ctx, sessionKey)
+	defer runtime.client.StopStream()
+
+	release, err := s.acquireRunSlot(streamCtx, sessionKey, runtime)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	workspace, cleanup, err := createFilesystemDocWorkspace(ctx, sessionKey, docCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary documentation workspace: %w", err)
+	}
+	defer func() { s.releaseTempWorkspace(ctx, sessionKey, workspace, cleanup, err) }()
+
+	llmResult, err := run(streamCtx, workspace.docsPath)
+	if err != nil {
+		if runtime.client.IsPaused() {
+			return nil, fmt.Errorf("pausing is not supported when documentation is written directly to a directory")
+		}
+		return nil, err
+	}
+
+	s.maintainFumadocsMeta(ctx, sessionKey, project, workspace, docCfg.DocsRelative, allowedFiles)
+
+	files, err := syncFilesystemDocs(ctx, sessionKey, workspace, docCfg.DocsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write documentation changes: %w", err)
+	}
+
+	summary := ""
+	if llmResult != nil {
+		summary = llmResult.Summary
+	}
+	result = &models.DocGenerationResult{
+		FilesystemTarget:    true,
+		Files:               files,
+		Summary:             summary,
+		FrontmatterWarnings: s.checkFrontmatter(ctx, sessionKey, workspace, files),
+		ComponentWarnings:   s.lintMDXComponents(ctx, sessionKey, project, workspace, files),
+		RouteTree:           s.buildRouteTree(ctx, sessionKey, workspace.docsPath),
+	}
+	applyStructuredSummary(result, llmResult)
+	return result, nil
+}
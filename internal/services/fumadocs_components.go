@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"narrabyte/internal/models"
+)
+
+// DefaultMDXComponents are the components Fumadocs registers for every MDX
+// page through defaultMdxComponents, so pages may use them without importing.
+var DefaultMDXComponents = []string{
+	"Callout",
+	"Card",
+	"Cards",
+	"CodeBlockTab",
+	"CodeBlockTabs",
+	"CodeBlockTabsList",
+	"CodeBlockTabsTrigger",
+}
+
+var (
+	mdxComponentPattern = regexp.MustCompile(`<([A-Z][A-Za-z0-9_]*)(?:\.[A-Za-z0-9_]+)*[\s/>]`)
+	mdxImportFromRegex  = regexp.MustCompile(`^import\s+([\s\S]+?)\s+from\s+['"][^'"]+['"]`)
+	mdxExportRegex      = regexp.MustCompile(`^export\s+(?:const|let|var|function|class)\s+([A-Za-z_$][A-Za-z0-9_$]*)`)
+	inlineCodePattern   = regexp.MustCompile("`[^`]*`")
+)
+
+// ParseMDXComponentList splits a comma or whitespace separated list of
+// component names, dropping blanks and duplicates.
+func ParseMDXComponentList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t' || r == '\r'
+	})
+	seen := make(map[string]bool)
+	components := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		components = append(components, field)
+	}
+	return components
+}
+
+// LintMDXComponents checks the changed .mdx pages in the repository at
+// repoPath, whose paths are relative to the repository root, for JSX
+// components that are neither imported, defined in the page, part of
+// DefaultMDXComponents nor listed in allowed. Each component is reported once
+// per page at its first use. Code blocks and inline code are ignored, and
+// nothing is rewritten.
+func (f *FumadocsService) LintMDXComponents(repoPath string, files []models.DocChangedFile, allowed []string) ([]models.MDXComponentWarning, error) {
+	known := make(map[string]bool)
+	for _, name := range DefaultMDXComponents {
+		known[name] = true
+	}
+	for _, name := range allowed {
+		known[strings.TrimSpace(name)] = true
+	}
+
+	warnings := make([]models.MDXComponentWarning, 0)
+	for _, file := range files {
+		if file.Status == "deleted" || strings.ToLower(path.Ext(file.Path)) != ".mdx" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(file.Path)))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return warnings, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		warnings = append(warnings, lintMDXPage(file.Path, string(data), known)...)
+	}
+	return warnings, nil
+}
+
+type mdxComponentUse struct {
+	name string
+	line int
+}
+
+func lintMDXPage(filePath string, content string, known map[string]bool) []models.MDXComponentWarning {
+	declared, uses := scanMDX(content)
+	var warnings []models.MDXComponentWarning
+	reported := make(map[string]bool)
+	for _, use := range uses {
+		if known[use.name] || declared[use.name] || reported[use.name] {
+			continue
+		}
+		reported[use.name] = true
+		warnings = append(warnings, models.MDXComponentWarning{
+			Path:      filePath,
+			Line:      use.line,
+			Component: use.name,
+			Message:   fmt.Sprintf("<%s> is not imported and is not a known component", use.name),
+		})
+	}
+	return warnings
+}
+
+// scanMDX returns the identifiers a page imports or exports and the JSX
+// components it uses, skipping frontmatter, fenced code and inline code.
+func scanMDX(content string) (map[string]bool, []mdxComponentUse) {
+	declared := make(map[string]bool)
+	var uses []mdxComponentUse
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	fence := ""
+	var pendingImport []string
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		if pendingImport != nil && trimmed == "" {
+			// ESM blocks end at a blank line
+			pendingImport = nil
+		}
+		if pendingImport != nil || strings.HasPrefix(trimmed, "import ") {
+			pendingImport = append(pendingImport, trimmed)
+			statement := strings.Join(pendingImport, " ")
+			if m := mdxImportFromRegex.FindStringSubmatch(statement); m != nil {
+				for _, name := range importedNames(m[1]) {
+					declared[name] = true
+				}
+				pendingImport = nil
+			} else if strings.Contains(trimmed, ";") {
+				pendingImport = nil
+			}
+			continue
+		}
+		if m := mdxExportRegex.FindStringSubmatch(trimmed); m != nil {
+			declared[m[1]] = true
+		}
+
+		code := inlineCodePattern.ReplaceAllString(line, "")
+		for _, m := range mdxComponentPattern.FindAllStringSubmatch(code+"\n", -1) {
+			uses = append(uses, mdxComponentUse{name: m[1], line: i + 1})
+		}
+	}
+	return declared, uses
+}
+
+// importedNames lists the local bindings of an import clause such as
+// `Foo, { Bar, Baz as Qux }` or `* as Ns`.
+func importedNames(clause string) []string {
+	var names []string
+	clause = strings.TrimSpace(clause)
+	if open := strings.Index(clause, "{"); open >= 0 {
+		end := strings.Index(clause[open:], "}")
+		if end < 0 {
+			end = len(clause) - open
+		}
+		for _, spec := range strings.Split(clause[open+1:open+end], ",") {
+			spec = strings.TrimSpace(spec)
+			if spec == "" {
+				continue
+			}
+			if idx := strings.Index(spec, " as "); idx >= 0 {
+				spec = strings.TrimSpace(spec[idx+4:])
+			}
+			names = append(names, strings.TrimPrefix(spec, "type "))
+		}
+		clause = clause[:open] + clause[min(open+end+1, len(clause)):]
+	}
+	for _, part := range strings.Split(clause, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, "*") {
+			if idx := strings.Index(part, " as "); idx >= 0 {
+				names = append(names, strings.TrimSpace(part[idx+4:]))
+			}
+			continue
+		}
+		names = append(names, part)
+	}
+	return names
+}
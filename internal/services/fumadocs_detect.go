@@ -0,0 +1,192 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/models"
+)
+
+// contentRootMaxDepth bounds how deep DetectContentRoot looks below the
+// repository root, enough for a docs app inside a monorepo (apps/docs/content/docs).
+const contentRootMaxDepth = 6
+
+// contentRootAmbiguity is how close in confidence the two best candidates
+// must be for a detection to be reported as ambiguous.
+const contentRootAmbiguity = 0.2
+
+// Evidence weights for a content root candidate; a candidate's confidence
+// is their sum, capped at 1.
+const (
+	contentRootFromConfig     = 0.6
+	contentRootConventional   = 0.3
+	contentRootHasMeta        = 0.15
+	contentRootHasPages       = 0.1
+	contentRootUnderDocsRoute = 0.15
+)
+
+// sourceConfigNames are the fumadocs-mdx config files that declare where the
+// docs collection lives.
+var sourceConfigNames = []string{"source.config.ts", "source.config.mts", "source.config.js", "source.config.mjs"}
+
+// defineDocsDirPattern captures the dir passed to defineDocs in a source config.
+var defineDocsDirPattern = regexp.MustCompile(`defineDocs\(\s*\{[^}]*?\bdir\s*:\s*['"]([^'"]+)['"]`)
+
+// docsRoutePattern matches the catch-all docs route of a Fumadocs app, for
+// the App Router (app/docs/[[...slug]]/page.tsx) and the Pages Router
+// (pages/docs/[[...slug]].tsx). The first group is the directory holding app
+// or pages, which is the app's root or its src directory.
+var docsRoutePattern = regexp.MustCompile(`^(?:(.*)/)?(?:app/(?:\([^/]+\)/)?docs/\[\[?\.\.\.slug\]\]?/page|pages/docs/\[\[?\.\.\.slug\]\]?)\.(?:tsx|jsx|ts|js)$`)
+
+// contentRootSkippedDirs are never descended into while probing.
+var contentRootSkippedDirs = map[string]bool{
+	"node_modules": true,
+	"dist":         true,
+	"build":        true,
+	"out":          true,
+	"vendor":       true,
+}
+
+// DetectContentRoot probes repoPath for the directory Fumadocs reads pages
+// from, so linking a project can prefill the docs path. Evidence is weighed
+// in this order: the dir a source.config.ts passes to defineDocs, the
+// conventional content/docs and src/content/docs locations, a meta.json and
+// MDX pages inside the directory, and a docs catch-all route of the App or
+// Pages Router in the same app. Every candidate is returned, best first; the
+// detection is ambiguous when the two best are too close to pick one. Best is
+// empty when nothing looks like Fumadocs content.
+func (f *FumadocsService) DetectContentRoot(repoPath string) (*models.ContentRootDetection, error) {
+	root := strings.TrimSpace(repoPath)
+	if root == "" {
+		return nil, fmt.Errorf("repository path is required")
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("repository path does not exist: %s", repoPath)
+	}
+
+	var configs, contentDirs, appRoots []string
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if rel == "." {
+				return nil
+			}
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") || contentRootSkippedDirs[name] || strings.Count(rel, "/") >= contentRootMaxDepth {
+				return filepath.SkipDir
+			}
+			if rel == "content/docs" || strings.HasSuffix(rel, "/content/docs") {
+				contentDirs = append(contentDirs, rel)
+			}
+			return nil
+		}
+		if slices.Contains(sourceConfigNames, entry.Name()) {
+			configs = append(configs, rel)
+		}
+		if match := docsRoutePattern.FindStringSubmatch(rel); match != nil {
+			app := match[1]
+			if app == "src" {
+				app = ""
+			}
+			appRoots = append(appRoots, strings.TrimSuffix(app, "/src"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe %s: %w", repoPath, err)
+	}
+
+	candidates := make(map[string]*models.ContentRootCandidate)
+	add := func(rel string, weight float64, reason string) {
+		c := candidates[rel]
+		if c == nil {
+			c = &models.ContentRootCandidate{Path: rel}
+			candidates[rel] = c
+		}
+		c.Confidence += weight
+		c.Reasons = append(c.Reasons, reason)
+	}
+	for _, config := range configs {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(config)))
+		if err != nil {
+			continue
+		}
+		for _, match := range defineDocsDirPattern.FindAllStringSubmatch(string(data), -1) {
+			rel := path.Join(path.Dir(config), match[1])
+			if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel))); err == nil && info.IsDir() {
+				add(rel, contentRootFromConfig, fmt.Sprintf("%s declares it as the docs directory", config))
+			}
+		}
+	}
+	for _, rel := range contentDirs {
+		add(rel, contentRootConventional, "conventional Fumadocs content location")
+	}
+
+	result := &models.ContentRootDetection{Candidates: make([]models.ContentRootCandidate, 0, len(candidates))}
+	for rel, c := range candidates {
+		dir := filepath.Join(root, filepath.FromSlash(rel))
+		if info, err := os.Stat(filepath.Join(dir, FumadocsMetaFile)); err == nil && !info.IsDir() {
+			add(rel, contentRootHasMeta, "has a meta.json")
+		}
+		if hasMDXPages(dir) {
+			add(rel, contentRootHasPages, "contains MDX pages")
+		}
+		for _, app := range appRoots {
+			if app == "" || hasPathPrefix(rel, app) {
+				add(rel, contentRootUnderDocsRoute, "its app has a docs catch-all route")
+				break
+			}
+		}
+		c.Confidence = math.Min(1, math.Round(c.Confidence*100)/100)
+		result.Candidates = append(result.Candidates, *c)
+	}
+	sort.Slice(result.Candidates, func(i, j int) bool {
+		a, b := result.Candidates[i], result.Candidates[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		return a.Path < b.Path
+	})
+
+	if len(result.Candidates) > 0 {
+		result.Best = result.Candidates[0].Path
+		result.Confidence = result.Candidates[0].Confidence
+	}
+	if len(result.Candidates) > 1 {
+		result.Ambiguous = result.Candidates[0].Confidence-result.Candidates[1].Confidence < contentRootAmbiguity
+	}
+	return result, nil
+}
+
+// hasMDXPages reports whether dir or a folder below it holds an .mdx or .md page.
+func hasMDXPages(dir string) bool {
+	found := false
+	_ = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || found {
+			return filepath.SkipAll
+		}
+		if !entry.IsDir() {
+			if ext := strings.ToLower(filepath.Ext(p)); ext == ".mdx" || ext == ".md" {
+				found = true
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"narrabyte/internal/models"
+)
+
+// ValidateFrontmatter checks the frontmatter of the changed .md/.mdx pages in
+// the repository at repoPath; file paths are relative to the repository root,
+// as git reports them. Each page needs a YAML frontmatter block with a
+// non-empty string title; malformed YAML and duplicate keys are reported too.
+// Problems are returned keyed by file path and nothing is rewritten. Deleted
+// or missing files are skipped.
+func (f *FumadocsService) ValidateFrontmatter(repoPath string, files []models.DocChangedFile) (map[string][]string, error) {
+	violations := make(map[string][]string)
+	for _, file := range files {
+		if file.Status == "deleted" || !isFumadocsPage(file.Path) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(file.Path)))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return violations, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		if problems := frontmatterProblems(data); len(problems) > 0 {
+			violations[file.Path] = problems
+		}
+	}
+	return violations, nil
+}
+
+// frontmatterProblems lists what is wrong with the frontmatter of a page.
+func frontmatterProblems(data []byte) []string {
+	block, ok, err := extractFrontmatter(data)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if !ok {
+		return []string{"missing frontmatter with a title"}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(block, &doc); err != nil {
+		return []string{fmt.Sprintf("malformed YAML: %v", err)}
+	}
+	if len(doc.Content) == 0 {
+		return []string{"missing title"}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []string{"malformed YAML: frontmatter must be a mapping of keys to values"}
+	}
+
+	var problems []string
+	seen := make(map[string]bool)
+	var title *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i].Value
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("duplicate key %q", key))
+			continue
+		}
+		seen[key] = true
+		if key == "title" {
+			title = root.Content[i+1]
+		}
+	}
+	switch {
+	case title == nil:
+		problems = append(problems, "missing title")
+	case title.Kind != yaml.ScalarNode || title.Tag != "!!str" || strings.TrimSpace(title.Value) == "":
+		problems = append(problems, "title must be a non-empty string")
+	}
+	return problems
+}
+
+// extractFrontmatter returns the YAML between the opening and closing "---"
+// lines at the top of a page. ok is false when the page has no frontmatter.
+func extractFrontmatter(data []byte) ([]byte, bool, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	if !scanner.Scan() || strings.TrimRight(scanner.Text(), " \t\r") != "---" {
+		return nil, false, nil
+	}
+	var block bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimRight(line, " \t\r") == "---" {
+			return block.Bytes(), true, nil
+		}
+		block.WriteString(line)
+		block.WriteByte('\n')
+	}
+	return nil, false, errors.New("malformed YAML: frontmatter is not closed with ---")
+}
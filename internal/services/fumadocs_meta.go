@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/models"
+)
+
+// FumadocsMetaFile is the per-directory file Fumadocs reads page ordering from.
+const FumadocsMetaFile = "meta.json"
+
+// ReconcileMetaFiles brings the meta.json files of the directories touched by
+// changed in line with the pages that now exist on disk. Pages changed adds
+// ("added" or "untracked") that are missing from a "pages" array are appended
+// and entries whose page no longer exists are removed. Existing pages the
+// author left out stay unlisted, and the order of the remaining entries is
+// left as the author wrote it.
+// Paths in changed are relative to repoRoot. Directories without a meta.json,
+// or whose pages array uses a "..." rest entry, are left alone. The meta files
+// that were rewritten are returned as changed files.
+func (f *FumadocsService) ReconcileMetaFiles(repoRoot string, changed []models.DocChangedFile) ([]models.DocChangedFile, error) {
+	// created holds the slugs of the pages changed adds, per directory
+	dirs := make(map[string]bool)
+	created := make(map[string][]string)
+	for _, file := range changed {
+		rel := filepath.ToSlash(file.Path)
+		if !isFumadocsPage(rel) {
+			continue
+		}
+		dir := path.Dir(rel)
+		dirs[dir] = true
+		if file.Status == "added" || file.Status == "untracked" {
+			name := path.Base(rel)
+			created[dir] = append(created[dir], strings.TrimSuffix(name, path.Ext(name)))
+		}
+	}
+
+	ordered := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		ordered = append(ordered, dir)
+	}
+	sort.Strings(ordered)
+
+	updated := make([]models.DocChangedFile, 0)
+	for _, dir := range ordered {
+		changedMeta, err := reconcileMetaFile(filepath.Join(repoRoot, filepath.FromSlash(dir)), created[dir])
+		if err != nil {
+			return updated, fmt.Errorf("failed to update %s: %w", path.Join(dir, FumadocsMetaFile), err)
+		}
+		if changedMeta {
+			updated = append(updated, models.DocChangedFile{
+				Path:   path.Join(dir, FumadocsMetaFile),
+				Status: "modified",
+			})
+		}
+	}
+	return updated, nil
+}
+
+func isFumadocsPage(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".mdx"
+}
+
+// reconcileMetaFile updates the pages array of dir's meta.json, adding the
+// created pages it does not list yet, and reports whether the file was
+// rewritten.
+func reconcileMetaFile(dir string, created []string) (bool, error) {
+	metaPath := filepath.Join(dir, FumadocsMetaFile)
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return false, fmt.Errorf("invalid meta.json: %w", err)
+	}
+	rawPages, ok := meta["pages"]
+	if !ok {
+		return false, nil
+	}
+	var pages []string
+	if err := json.Unmarshal(rawPages, &pages); err != nil {
+		return false, fmt.Errorf("invalid pages array: %w", err)
+	}
+	for _, entry := range pages {
+		if isMetaRestEntry(entry) {
+			return false, nil
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	existing := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			existing[name] = true
+			continue
+		}
+		if isFumadocsPage(name) {
+			existing[strings.TrimSuffix(name, path.Ext(name))] = true
+		}
+	}
+	created = slices.Clone(created)
+	sort.Strings(created)
+
+	listed := make(map[string]bool)
+	kept := make([]string, 0, len(pages))
+	for _, entry := range pages {
+		name, isPage := metaPageName(entry)
+		if isPage {
+			if !existing[name] {
+				continue
+			}
+			listed[name] = true
+		}
+		kept = append(kept, entry)
+	}
+	for _, slug := range created {
+		if existing[slug] && !listed[slug] {
+			kept = append(kept, slug)
+			listed[slug] = true
+		}
+	}
+	if slices.Equal(kept, pages) {
+		return false, nil
+	}
+
+	encodedPages, err := json.Marshal(kept)
+	if err != nil {
+		return false, err
+	}
+	meta["pages"] = encodedPages
+	out, err := encodeMetaFile(data, meta)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(metaPath)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(metaPath, out, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isMetaRestEntry reports whether entry is a Fumadocs rest item, which already
+// pulls in every page not listed explicitly.
+func isMetaRestEntry(entry string) bool {
+	return entry == "..." || entry == "z...a" || entry == "a...z"
+}
+
+// metaPageName returns the page or folder an entry refers to. Separators,
+// links, extracted folders ("...name") and excluded items ("!name") are not
+// plain page references and are always kept.
+func metaPageName(entry string) (string, bool) {
+	trimmed := strings.TrimSpace(entry)
+	if trimmed == "" ||
+		strings.HasPrefix(trimmed, "---") ||
+		strings.HasPrefix(trimmed, "[") ||
+		strings.HasPrefix(trimmed, "...") ||
+		strings.HasPrefix(trimmed, "!") {
+		return "", false
+	}
+	return trimmed, true
+}
+
+// encodeMetaFile writes meta back with two-space indentation, keeping the
+// top-level keys in the order they appeared in original.
+func encodeMetaFile(original []byte, meta map[string]json.RawMessage) ([]byte, error) {
+	keys, err := topLevelKeys(original)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, key := range keys {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		var value bytes.Buffer
+		if err := json.Indent(&value, meta[key], "  ", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteString("  ")
+		buf.Write(name)
+		buf.WriteString(": ")
+		buf.Write(value.Bytes())
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+func topLevelKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v", tok)
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"narrabyte/internal/models"
+)
+
+// Route tree node types.
+const (
+	RouteNodeRoot      = "root"
+	RouteNodeFolder    = "folder"
+	RouteNodePage      = "page"
+	RouteNodeSeparator = "separator"
+	RouteNodeLink      = "link"
+)
+
+var metaLinkPattern = regexp.MustCompile(`^\[(.+)\]\((.+)\)$`)
+
+// FumadocsContentDir returns the directory Fumadocs reads pages from for a
+// docs path: content/docs inside a Fumadocs app, or docsPath itself when it
+// already is a content directory with a meta.json. ok is false otherwise.
+func FumadocsContentDir(docsPath string) (string, bool) {
+	content := filepath.Join(docsPath, "content", "docs")
+	if info, err := os.Stat(content); err == nil && info.IsDir() {
+		return content, true
+	}
+	if info, err := os.Stat(filepath.Join(docsPath, FumadocsMetaFile)); err == nil && !info.IsDir() {
+		return docsPath, true
+	}
+	return "", false
+}
+
+// BuildRouteTree walks the Fumadocs content directory of docsPath and returns
+// the navigation a reader would see. Folder order and titles come from
+// meta.json when present; otherwise items are sorted by name. Page titles are
+// read from frontmatter, and a folder's index page becomes the folder's own
+// route. Returns nil when docsPath is not a Fumadocs project.
+func (f *FumadocsService) BuildRouteTree(docsPath string) (*models.RouteNode, error) {
+	contentDir, ok := FumadocsContentDir(docsPath)
+	if !ok {
+		return nil, nil
+	}
+	root, err := buildRouteFolder(contentDir, "")
+	if err != nil {
+		return nil, err
+	}
+	root.Type = RouteNodeRoot
+	if root.Title == "" {
+		root.Title = "Docs"
+	}
+	return &root, nil
+}
+
+type routeMeta struct {
+	Title string   `json:"title"`
+	Pages []string `json:"pages"`
+}
+
+// buildRouteFolder builds the node for the folder at rel inside contentDir.
+func buildRouteFolder(contentDir string, rel string) (models.RouteNode, error) {
+	dir := filepath.Join(contentDir, filepath.FromSlash(rel))
+	folder := models.RouteNode{Type: RouteNodeFolder}
+
+	var meta routeMeta
+	data, err := os.ReadFile(filepath.Join(dir, FumadocsMetaFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return folder, fmt.Errorf("invalid %s: %w", path.Join(rel, FumadocsMetaFile), err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return folder, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return folder, err
+	}
+	items := make(map[string]models.RouteNode)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if entry.IsDir() {
+			child, err := buildRouteFolder(contentDir, path.Join(rel, name))
+			if err != nil {
+				return folder, err
+			}
+			if child.URL == "" && len(child.Children) == 0 {
+				continue
+			}
+			items[name] = child
+			names = append(names, name)
+			continue
+		}
+		if !isFumadocsPage(name) {
+			continue
+		}
+		slug := strings.TrimSuffix(name, path.Ext(name))
+		items[slug] = routePage(dir, rel, name, slug)
+		names = append(names, slug)
+	}
+	sort.Strings(names)
+
+	// A folder's index page is its own route unless meta.json lists it
+	index, hasIndex := items["index"]
+	if rel != "" && hasIndex && !slices.Contains(meta.Pages, "index") {
+		folder.URL = index.URL
+		folder.Path = index.Path
+		delete(items, "index")
+		names = slices.DeleteFunc(names, func(name string) bool { return name == "index" })
+	}
+
+	folder.Title = strings.TrimSpace(meta.Title)
+	if folder.Title == "" && rel != "" {
+		if hasIndex {
+			folder.Title = index.Title
+		} else {
+			folder.Title = routeName(path.Base(rel))
+		}
+	}
+
+	if meta.Pages == nil {
+		for _, name := range names {
+			if item, ok := items[name]; ok {
+				folder.Children = append(folder.Children, item)
+			}
+		}
+		return folder, nil
+	}
+	folder.Children = orderRouteItems(meta.Pages, names, items)
+	return folder, nil
+}
+
+// orderRouteItems lays out a folder's items following a meta.json pages array.
+func orderRouteItems(pages []string, names []string, items map[string]models.RouteNode) []models.RouteNode {
+	referenced := make(map[string]bool)
+	for _, entry := range pages {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case strings.HasPrefix(entry, "!"):
+			referenced[strings.TrimPrefix(entry, "!")] = true
+		case strings.HasPrefix(entry, "...") && len(entry) > 3:
+			referenced[strings.TrimPrefix(entry, "...")] = true
+		default:
+			referenced[entry] = true
+		}
+	}
+	var rest []string
+	for _, name := range names {
+		if !referenced[name] {
+			rest = append(rest, name)
+		}
+	}
+
+	var children []models.RouteNode
+	for _, entry := range pages {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "..." || entry == "a...z":
+			for _, name := range rest {
+				children = append(children, items[name])
+			}
+		case entry == "z...a":
+			for i := len(rest) - 1; i >= 0; i-- {
+				children = append(children, items[rest[i]])
+			}
+		case strings.HasPrefix(entry, "!"):
+			// Excluded from the navigation
+		case strings.HasPrefix(entry, "---"):
+			children = append(children, models.RouteNode{
+				Type:  RouteNodeSeparator,
+				Title: strings.TrimSpace(strings.Trim(entry, "-")),
+			})
+		case strings.HasPrefix(entry, "..."):
+			if item, ok := items[strings.TrimPrefix(entry, "...")]; ok && item.Type == RouteNodeFolder {
+				if item.URL != "" {
+					children = append(children, models.RouteNode{Type: RouteNodePage, Title: item.Title, URL: item.URL, Path: item.Path})
+				}
+				children = append(children, item.Children...)
+			}
+		default:
+			if m := metaLinkPattern.FindStringSubmatch(entry); m != nil {
+				children = append(children, models.RouteNode{Type: RouteNodeLink, Title: m[1], URL: m[2]})
+				continue
+			}
+			if item, ok := items[entry]; ok {
+				children = append(children, item)
+			}
+		}
+	}
+	return children
+}
+
+// routePage builds the node for a page file in the folder at rel.
+func routePage(dir, rel, file, slug string) models.RouteNode {
+	route := path.Join(rel, slug)
+	if slug == "index" {
+		route = rel
+	}
+	title := routeName(slug)
+	if data, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+		if block, ok, err := extractFrontmatter(data); err == nil && ok {
+			var fm struct {
+				Title string `yaml:"title"`
+			}
+			if yaml.Unmarshal(block, &fm) == nil && strings.TrimSpace(fm.Title) != "" {
+				title = strings.TrimSpace(fm.Title)
+			}
+		}
+	}
+	return models.RouteNode{
+		Type:  RouteNodePage,
+		Title: title,
+		URL:   "/" + route,
+		Path:  path.Join(rel, file),
+	}
+}
+
+// routeName derives a display name from a file or folder name the way
+// Fumadocs does when no title is given.
+func routeName(name string) string {
+	name = strings.ReplaceAll(name, "-", " ")
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"narrabyte/internal/models"
+	"narrabyte/internal/repositories"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+type GenerationProfileService interface {
+	GetProfile(id uint) (*models.GenerationProfile, error)
+	ListProfiles(projectID uint) ([]*models.GenerationProfile, error)
+	CreateProfile(p *models.GenerationProfile) (*models.GenerationProfile, error)
+	UpdateProfile(p *models.GenerationProfile) (*models.GenerationProfile, error)
+	DeleteProfile(id uint) error
+	ValidateProfile(p *models.GenerationProfile) error
+	Startup(ctx context.Context)
+}
+
+type generationProfileService struct {
+	repo         repositories.GenerationProfileRepository
+	templates    TemplateService
+	modelConfigs ModelConfigService
+	ctx          context.Context
+}
+
+func (s *generationProfileService) Startup(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// NewGenerationProfileService returns a service storing profiles in repo. The
+// template and model services are used to check what a profile refers to.
+func NewGenerationProfileService(repo repositories.GenerationProfileRepository, templates TemplateService, modelConfigs ModelConfigService) GenerationProfileService {
+	return &generationProfileService{repo: repo, templates: templates, modelConfigs: modelConfigs}
+}
+
+func (s *generationProfileService) GetProfile(id uint) (*models.GenerationProfile, error) {
+	profile, err := s.repo.Get(s.ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: get generation profile %d: %w", id, err)
+	}
+	return profile, nil
+}
+
+func (s *generationProfileService) ListProfiles(projectID uint) ([]*models.GenerationProfile, error) {
+	list, err := s.repo.ListByProject(s.ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("service: list generation profiles: %w", err)
+	}
+	return list, nil
+}
+
+func (s *generationProfileService) CreateProfile(p *models.GenerationProfile) (*models.GenerationProfile, error) {
+	if err := s.ValidateProfile(p); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(s.ctx, p); err != nil {
+		return nil, fmt.Errorf("service: create generation profile: %w", err)
+	}
+	return p, nil
+}
+
+func (s *generationProfileService) UpdateProfile(p *models.GenerationProfile) (*models.GenerationProfile, error) {
+	if err := s.ValidateProfile(p); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(s.ctx, p); err != nil {
+		return nil, fmt.Errorf("service: update generation profile %d: %w", p.ID, err)
+	}
+	return p, nil
+}
+
+func (s *generationProfileService) DeleteProfile(id uint) error {
+	if err := s.repo.Delete(s.ctx, id); err != nil {
+		return fmt.Errorf("service: delete generation profile %d: %w", id, err)
+	}
+	return nil
+}
+
+// ValidateProfile normalizes p and checks that it names a project, that its
+// excluded patterns are valid globs, and that the model and template it
+// refers to exist. Models and templates can be removed after a profile is
+// saved, so runs started from a profile validate it again.
+func (s *generationProfileService) ValidateProfile(p *models.GenerationProfile) error {
+	if p == nil {
+		return fmt.Errorf("profile is required")
+	}
+	if p.ProjectID == 0 {
+		return fmt.Errorf("project id is required")
+	}
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	p.ModelKey = strings.TrimSpace(p.ModelKey)
+	patterns := ParseExcludedPatterns(p.ExcludedPatterns)
+	for _, pattern := range patterns {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("profile %q: invalid excluded pattern %q", p.Name, pattern)
+		}
+	}
+	p.ExcludedPatterns = strings.Join(patterns, ",")
+
+	if p.ModelKey != "" {
+		if s.modelConfigs == nil {
+			return fmt.Errorf("model config service not initialized")
+		}
+		model, err := s.modelConfigs.GetModel(p.ModelKey)
+		if err != nil || model == nil {
+			return fmt.Errorf("profile %q: model %s is not available", p.Name, p.ModelKey)
+		}
+		if !model.Enabled {
+			return fmt.Errorf("profile %q: model %s is disabled", p.Name, p.ModelKey)
+		}
+	}
+	if p.TemplateID != 0 {
+		if s.templates == nil {
+			return fmt.Errorf("template service not initialized")
+		}
+		tmpl, err := s.templates.GetTemplate(p.TemplateID)
+		if err != nil || tmpl == nil {
+			return fmt.Errorf("profile %q: template %d not found", p.Name, p.TemplateID)
+		}
+	}
+	return nil
+}
+
+// ParseExcludedPatterns splits a profile's stored excluded patterns into
+// globs, dropping blanks and duplicates.
+func ParseExcludedPatterns(value string) []string {
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(value, ",") {
+		pattern := normalizePathSlashes(field)
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
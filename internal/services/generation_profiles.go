@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"narrabyte/internal/llm/client"
+	"narrabyte/internal/models"
+)
+
+// GenerateDocsWithProfile starts a documentation run from one of the
+// project's generation profiles, which supplies the model, template, extra
+// instructions and excluded patterns. The profile is validated again first,
+// since its model or template may have been removed since it was saved.
+// userInstructions are optional and follow the profile's own instructions.
+func (s *ClientService) GenerateDocsWithProfile(projectID uint, profileID uint, sourceBranch string, targetBranch string, userInstructions string, docsBranchOverride string, sessionKeyOverride string, idempotencyKey string) (*models.DocGenerationResult, error) {
+	if projectID == 0 {
+		return nil, fmt.Errorf("project id is required")
+	}
+	if profileID == 0 {
+		return nil, fmt.Errorf("profile id is required")
+	}
+	if s.profiles == nil {
+		return nil, fmt.Errorf("generation profile service not initialized")
+	}
+	profile, err := s.profiles.GetProfile(profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile: %w", err)
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("profile %d not found", profileID)
+	}
+	if profile.ProjectID != projectID {
+		return nil, fmt.Errorf("profile %q belongs to another project", profile.Name)
+	}
+	if err := s.profiles.ValidateProfile(profile); err != nil {
+		return nil, err
+	}
+
+	template := ""
+	if profile.TemplateID != 0 {
+		tmpl, err := s.templates.GetTemplate(profile.TemplateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load template: %w", err)
+		}
+		template = tmpl.Content
+	}
+	instructions := strings.TrimSpace(profile.ExtraInstructions)
+	if user := strings.TrimSpace(userInstructions); user != "" {
+		if instructions != "" {
+			instructions += "\n\n"
+		}
+		instructions += user
+	}
+	excluded := ParseExcludedPatterns(profile.ExcludedPatterns)
+	return s.runIdempotent(idempotencyKey, func(key string) (*models.DocGenerationResult, error) {
+		return s.generateDocs(projectID, sourceBranch, targetBranch, profile.ModelKey, templateInstructions(template, instructions), docsBranchOverride, sessionKeyOverride, key, excluded, docsFromDiff)
+	})
+}
+
+// excludeCodeDiff drops the diff segments of files matching patterns, on top
+// of the files every diff leaves out.
+func excludeCodeDiff(diffText string, patterns []string) string {
+	if len(patterns) == 0 {
+		return diffText
+	}
+	return filterDiffSegments(diffText, func(fileA, fileB string) bool {
+		return !(fileA != "" && matchesPathPatterns(fileA, patterns)) && !(fileB != "" && matchesPathPatterns(fileB, patterns))
+	})
+}
+
+// excludeCodeFiles is excludeCodeDiff for the file list of a full bootstrap.
+func excludeCodeFiles(files []client.ChangedFile, patterns []string) []client.ChangedFile {
+	if len(patterns) == 0 {
+		return files
+	}
+	kept := files[:0]
+	for _, file := range files {
+		if !matchesPathPatterns(file.Path, patterns) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
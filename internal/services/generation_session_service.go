@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"narrabyte/internal/models"
 	"narrabyte/internal/repositories"
@@ -13,12 +14,18 @@ type GenerationSessionService interface {
 	List(projectID uint) ([]models.GenerationSession, error)
 	GetByID(id uint) (*models.GenerationSession, error)
 	GetByDocsBranch(projectID uint, docsBranch string) (*models.GenerationSession, error)
+	GetByIdempotencyKey(key string) (*models.GenerationSession, error)
 	Create(session *models.GenerationSession) (*models.GenerationSession, error)
 	UpdateByID(id uint, updates map[string]interface{}) error
 	DeleteByID(id uint) error
 	DeleteAll(projectID uint) error
+	AppendEventLog(id uint, entries []models.SessionEventLogEntry) error
+	GetEventLog(id uint) ([]models.SessionEventLogEntry, error)
 }
 
+// MaxSessionEventLogEntries caps a session's stored event log; older entries are dropped first.
+const MaxSessionEventLogEntries = 1000
+
 type generationSessionService struct {
 	repo repositories.GenerationSessionRepository
 	ctx  context.Context
@@ -51,6 +58,14 @@ func (s *generationSessionService) GetByDocsBranch(projectID uint, docsBranch st
 	return s.repo.GetByDocsBranch(projectID, docsBranch)
 }
 
+func (s *generationSessionService) GetByIdempotencyKey(key string) (*models.GenerationSession, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("idempotency key is required")
+	}
+	return s.repo.GetByIdempotencyKey(key)
+}
+
 func (s *generationSessionService) Create(session *models.GenerationSession) (*models.GenerationSession, error) {
 	if session == nil {
 		return nil, fmt.Errorf("session is required")
@@ -84,3 +99,61 @@ func (s *generationSessionService) DeleteByID(id uint) error {
 func (s *generationSessionService) DeleteAll(projectID uint) error {
 	return s.repo.DeleteByProject(projectID)
 }
+
+// AppendEventLog adds entries to the session's stored event log, keeping only
+// the most recent MaxSessionEventLogEntries.
+func (s *generationSessionService) AppendEventLog(id uint, entries []models.SessionEventLogEntry) error {
+	if id == 0 {
+		return fmt.Errorf("session ID is required")
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	session, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		// The session was discarded during the run; there is nothing to attach the log to
+		return nil
+	}
+	log, err := decodeEventLog(session.EventLogJSON)
+	if err != nil {
+		return err
+	}
+	log = append(log, entries...)
+	if len(log) > MaxSessionEventLogEntries {
+		log = log[len(log)-MaxSessionEventLogEntries:]
+	}
+	data, err := json.Marshal(log)
+	if err != nil {
+		return fmt.Errorf("failed to encode event log: %w", err)
+	}
+	return s.repo.UpdateByID(id, map[string]interface{}{"event_log_json": string(data)})
+}
+
+// GetEventLog returns the recorded events of a session, oldest first.
+func (s *generationSessionService) GetEventLog(id uint) ([]models.SessionEventLogEntry, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("session ID is required")
+	}
+	session, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found: %d", id)
+	}
+	return decodeEventLog(session.EventLogJSON)
+}
+
+func decodeEventLog(raw string) ([]models.SessionEventLogEntry, error) {
+	log := []models.SessionEventLogEntry{}
+	if strings.TrimSpace(raw) == "" {
+		return log, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &log); err != nil {
+		return nil, fmt.Errorf("failed to decode event log: %w", err)
+	}
+	return log, nil
+}
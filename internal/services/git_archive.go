@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const (
+	// archiveTagPrefix starts the names of the tags ArchiveBranch creates
+	archiveTagPrefix = "archive/"
+	// archiveDateLayout formats the archive date that ends those names
+	archiveDateLayout = "2006-01-02"
+)
+
+// ArchiveBranch keeps branch recoverable but out of the branch list: an
+// annotated tag archive/<branch>/<date> is created at its head and the branch
+// ref is deleted. When the branch was already archived that day, a counter is
+// appended to the date. The checked-out branch cannot be archived. It returns
+// the tag name, which RestoreArchivedBranch takes.
+func (g *GitService) ArchiveBranch(repo *git.Repository, branch string) (string, error) {
+	if repo == nil {
+		return "", fmt.Errorf("repo cannot be nil")
+	}
+	name := strings.TrimSpace(branch)
+	if name == "" {
+		return "", fmt.Errorf("branch name is required")
+	}
+	refName := plumbing.NewBranchReferenceName(name)
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return "", fmt.Errorf("branch '%s' does not exist", name)
+		}
+		return "", fmt.Errorf("failed to resolve branch '%s': %w", name, err)
+	}
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference && head.Target() == refName {
+		return "", fmt.Errorf("branch '%s' is checked out; switch to another branch before archiving it", name)
+	}
+
+	signature := signatureFromEnv()
+	base := archiveTagPrefix + name + "/" + signature.When.Format(archiveDateLayout)
+	tag := base
+	for n := 2; ; n++ {
+		if _, err := repo.Tag(tag); errors.Is(err, git.ErrTagNotFound) {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("failed to check tag '%s': %w", tag, err)
+		}
+		tag = fmt.Sprintf("%s-%d", base, n)
+	}
+	if _, err := repo.CreateTag(tag, ref.Hash(), &git.CreateTagOptions{
+		Tagger:  signature,
+		Message: fmt.Sprintf("Archive of branch %s\n", name),
+	}); err != nil {
+		return "", fmt.Errorf("failed to create tag '%s': %w", tag, err)
+	}
+	if err := repo.Storer.RemoveReference(refName); err != nil {
+		_ = repo.DeleteTag(tag)
+		return "", fmt.Errorf("failed to remove branch '%s': %w", name, err)
+	}
+	return tag, nil
+}
+
+// RestoreArchivedBranch recreates the branch an ArchiveBranch tag was made
+// from at the tagged commit, then deletes the tag. It fails when the branch
+// exists again and returns the branch name.
+func (g *GitService) RestoreArchivedBranch(repo *git.Repository, tag string) (string, error) {
+	if repo == nil {
+		return "", fmt.Errorf("repo cannot be nil")
+	}
+	tag = strings.TrimSpace(tag)
+	branch, _, ok := parseArchiveTag(tag)
+	if !ok {
+		return "", fmt.Errorf("'%s' is not an archive tag", tag)
+	}
+	tagRef, err := repo.Tag(tag)
+	if err != nil {
+		if errors.Is(err, git.ErrTagNotFound) {
+			return "", fmt.Errorf("archive tag '%s' does not exist", tag)
+		}
+		return "", fmt.Errorf("failed to resolve tag '%s': %w", tag, err)
+	}
+	commit, err := peelToCommit(repo, tagRef.Hash())
+	if err != nil {
+		return "", err
+	}
+	if err := g.CreateBranch(repo, branch, commit, false); err != nil {
+		return "", err
+	}
+	if err := repo.DeleteTag(tag); err != nil {
+		return "", fmt.Errorf("restored branch '%s' but failed to delete tag '%s': %w", branch, tag, err)
+	}
+	return branch, nil
+}
+
+// latestArchiveTag returns the most recent ArchiveBranch tag of branch, or ""
+// when the branch has not been archived.
+func latestArchiveTag(repo *git.Repository, branch string) (string, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	type archive struct {
+		name string
+		day  time.Time
+		n    int
+	}
+	var archives []archive
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		archived, suffix, ok := parseArchiveTag(name)
+		if !ok || archived != branch {
+			return nil
+		}
+		if len(suffix) < len(archiveDateLayout) {
+			return nil
+		}
+		day, err := time.Parse(archiveDateLayout, suffix[:len(archiveDateLayout)])
+		if err != nil {
+			return nil
+		}
+		n := 1
+		if counter := suffix[len(archiveDateLayout):]; counter != "" {
+			if n, err = strconv.Atoi(strings.TrimPrefix(counter, "-")); err != nil {
+				return nil
+			}
+		}
+		archives = append(archives, archive{name: name, day: day, n: n})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	if len(archives) == 0 {
+		return "", nil
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		if !archives[i].day.Equal(archives[j].day) {
+			return archives[i].day.Before(archives[j].day)
+		}
+		return archives[i].n < archives[j].n
+	})
+	return archives[len(archives)-1].name, nil
+}
+
+// parseArchiveTag splits an archive/<branch>/<date> tag into the branch and
+// the date, including any counter.
+func parseArchiveTag(tag string) (branch, suffix string, ok bool) {
+	rest, found := strings.CutPrefix(tag, archiveTagPrefix)
+	if !found {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, "/")
+	if i <= 0 || i == len(rest)-1 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
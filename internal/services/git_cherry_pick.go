@@ -0,0 +1,437 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// CherryPickConflictError lists the files a cherry-pick could not apply
+// because the target branch changed the same lines, or changed a file the
+// commit replaced outright. It formats as
+// ERR_CHERRY_PICK_CONFLICT:<branch>:<path,path,...> so the UI can list them.
+type CherryPickConflictError struct {
+	Commit string
+	Branch string
+	Paths  []string
+}
+
+func (e *CherryPickConflictError) Error() string {
+	return fmt.Sprintf("ERR_CHERRY_PICK_CONFLICT:%s:%s", e.Branch, strings.Join(e.Paths, ","))
+}
+
+// CherryPick applies the changes commitHash made to its parent onto
+// targetBranch and commits them there with the original author and message.
+// go-git has no cherry-pick, so the commit's tree diff is replayed file by
+// file: files the target left untouched are taken as they are, and files both
+// sides edited are merged line by line. Any overlap aborts the cherry-pick
+// with a *CherryPickConflictError and leaves the branch unchanged. When the
+// target branch is checked out, its worktree must be clean and is updated to
+// the new commit.
+func (g *GitService) CherryPick(repo *git.Repository, targetBranch, commitHash string) (plumbing.Hash, error) {
+	if repo == nil {
+		return plumbing.ZeroHash, fmt.Errorf("repo cannot be nil")
+	}
+	branch := strings.TrimSpace(targetBranch)
+	if branch == "" {
+		return plumbing.ZeroHash, fmt.Errorf("branch name is required")
+	}
+	rev := strings.TrimSpace(commitHash)
+	if rev == "" {
+		return plumbing.ZeroHash, fmt.Errorf("commit is required")
+	}
+
+	commit, parentTree, commitTree, err := loadCommitTrees(repo, rev, "cherry-picked")
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	target, err := loadBranchTarget(repo, branch)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	changes, err := object.DiffTree(parentTree, commitTree)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to diff commit: %w", err)
+	}
+	files, err := treeFiles(target.tree)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	conflicts, applied, err := applyTreeChanges(repo.Storer, files, changes)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(conflicts) > 0 {
+		return plumbing.ZeroHash, &CherryPickConflictError{Commit: commit.Hash.String(), Branch: branch, Paths: conflicts}
+	}
+	if applied == 0 {
+		return plumbing.ZeroHash, fmt.Errorf("the changes of commit %s are already on branch '%s'", commit.Hash.String()[:7], branch)
+	}
+
+	return target.commit(repo, files, &object.Commit{
+		Author:    commit.Author,
+		Committer: *signatureFromEnv(),
+		Message:   strings.TrimRight(commit.Message, "\n") + fmt.Sprintf("\n\n(cherry picked from commit %s)\n", commit.Hash.String()),
+	})
+}
+
+// loadCommitTrees resolves rev to a non-merge commit and returns it with its
+// parent's tree, which is nil for a root commit, and its own tree. action
+// completes the error for merge commits, as in "cannot be <action>".
+func loadCommitTrees(repo *git.Repository, rev, action string) (*object.Commit, *object.Tree, *object.Tree, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to resolve commit '%s': %w", rev, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load commit '%s': %w", rev, err)
+	}
+	if commit.NumParents() > 1 {
+		return nil, nil, nil, fmt.Errorf("commit %s is a merge commit and cannot be %s", commit.Hash.String()[:7], action)
+	}
+	commitTree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load commit tree: %w", err)
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() == 1 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load parent commit: %w", err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load parent tree: %w", err)
+		}
+	}
+	return commit, parentTree, commitTree, nil
+}
+
+// branchTarget is a branch a replayed commit is written to: its current tip
+// and, when the branch is checked out, the clean worktree to update.
+type branchTarget struct {
+	name string
+	ref  *plumbing.Reference
+	head *object.Commit
+	tree *object.Tree
+	wt   *git.Worktree
+}
+
+func loadBranchTarget(repo *git.Repository, branch string) (*branchTarget, error) {
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	ref, err := repo.Reference(branchRef, true)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, fmt.Errorf("branch '%s' does not exist", branch)
+		}
+		return nil, fmt.Errorf("failed to resolve branch '%s': %w", branch, err)
+	}
+	head, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load branch '%s': %w", branch, err)
+	}
+	tree, err := head.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load branch tree: %w", err)
+	}
+
+	var wt *git.Worktree
+	if headRef, err := repo.Storer.Reference(plumbing.HEAD); err == nil && headRef.Type() == plumbing.SymbolicReference && headRef.Target() == branchRef {
+		if wt, err = repo.Worktree(); err != nil && !errors.Is(err, git.ErrIsBareRepository) {
+			return nil, fmt.Errorf("failed to get worktree: %w", err)
+		}
+		if wt != nil {
+			status, err := wt.Status()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get worktree status: %w", err)
+			}
+			if !status.IsClean() {
+				return nil, fmt.Errorf("branch '%s' is checked out with uncommitted changes", branch)
+			}
+		}
+	}
+	return &branchTarget{name: branch, ref: ref, head: head, tree: tree, wt: wt}, nil
+}
+
+// commit writes files as the tree of c, parents it on the branch tip and
+// moves the branch to it, failing if the branch moved in the meantime.
+func (t *branchTarget) commit(repo *git.Repository, files map[string]object.TreeEntry, c *object.Commit) (plumbing.Hash, error) {
+	treeHash, err := writeTree(repo.Storer, files)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	c.TreeHash = treeHash
+	c.ParentHashes = []plumbing.Hash{t.head.Hash}
+	obj := repo.Storer.NewEncodedObject()
+	if err := c.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	newHash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write commit: %w", err)
+	}
+
+	if err := repo.Storer.CheckAndSetReference(plumbing.NewHashReference(t.ref.Name(), newHash), t.ref); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update branch '%s': %w", t.name, err)
+	}
+	if t.wt != nil {
+		if err := t.wt.Reset(&git.ResetOptions{Commit: newHash, Mode: git.HardReset}); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to update worktree: %w", err)
+		}
+	}
+	return newHash, nil
+}
+
+func changeName(change *object.Change) string {
+	if change.To.Name != "" {
+		return change.To.Name
+	}
+	return change.From.Name
+}
+
+// treeFiles flattens tree into its non-directory entries keyed by path.
+func treeFiles(tree *object.Tree) (map[string]object.TreeEntry, error) {
+	files := make(map[string]object.TreeEntry)
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk tree: %w", err)
+		}
+		if entry.Mode == filemode.Dir {
+			continue
+		}
+		files[name] = entry
+	}
+	return files, nil
+}
+
+// applyTreeChanges replays changes onto files. It returns the sorted paths
+// that conflicted and how many changes altered files.
+func applyTreeChanges(s storer.EncodedObjectStorer, files map[string]object.TreeEntry, changes object.Changes) ([]string, int, error) {
+	var conflicts []string
+	applied := 0
+	for _, change := range changes {
+		ok, changed, err := applyTreeChange(s, files, change)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !ok {
+			conflicts = append(conflicts, changeName(change))
+			continue
+		}
+		if changed {
+			applied++
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, applied, nil
+}
+
+// applyTreeChange replays one file change onto files. ok is false when the
+// change conflicts with the target's version of the file; changed is false
+// when the target already had the change.
+func applyTreeChange(s storer.EncodedObjectStorer, files map[string]object.TreeEntry, change *object.Change) (ok bool, changed bool, err error) {
+	name := changeName(change)
+	from, hasFrom := change.From.TreeEntry, change.From.Name != ""
+	to, hasTo := change.To.TreeEntry, change.To.Name != ""
+	current, exists := files[name]
+
+	switch {
+	case hasTo && exists && current.Hash == to.Hash && current.Mode == to.Mode,
+		!hasTo && !exists:
+		return true, false, nil
+	case hasFrom && exists && current.Hash == from.Hash && current.Mode == from.Mode,
+		!hasFrom && !exists:
+		if hasTo {
+			files[name] = object.TreeEntry{Name: path.Base(name), Mode: to.Mode, Hash: to.Hash}
+		} else {
+			delete(files, name)
+		}
+		return true, true, nil
+	case !hasFrom || !hasTo || !exists || !to.Mode.IsFile() || !current.Mode.IsFile():
+		return false, false, nil
+	}
+
+	base, err := readBlob(s, from.Hash)
+	if err != nil {
+		return false, false, err
+	}
+	ours, err := readBlob(s, current.Hash)
+	if err != nil {
+		return false, false, err
+	}
+	theirs, err := readBlob(s, to.Hash)
+	if err != nil {
+		return false, false, err
+	}
+	if isBinaryContent(base) || isBinaryContent(ours) || isBinaryContent(theirs) {
+		return false, false, nil
+	}
+	merged, clean := mergeLines(string(base), string(ours), string(theirs))
+	if !clean {
+		return false, false, nil
+	}
+	hash, err := writeBlob(s, []byte(merged))
+	if err != nil {
+		return false, false, err
+	}
+	files[name] = object.TreeEntry{Name: path.Base(name), Mode: current.Mode, Hash: hash}
+	return true, hash != current.Hash, nil
+}
+
+func readBlob(s storer.EncodedObjectStorer, hash plumbing.Hash) ([]byte, error) {
+	blob, err := object.GetBlob(s, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blob %s: %w", hash.String(), err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", hash.String(), err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func writeBlob(s storer.EncodedObjectStorer, content []byte) (plumbing.Hash, error) {
+	obj := s.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(content)))
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		_ = w.Close()
+		return plumbing.ZeroHash, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write blob: %w", err)
+	}
+	return s.SetEncodedObject(obj)
+}
+
+func isBinaryContent(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// writeTree stores the trees for a flat path-to-entry map and returns the
+// root tree's hash.
+func writeTree(s storer.EncodedObjectStorer, files map[string]object.TreeEntry) (plumbing.Hash, error) {
+	tree := &object.Tree{}
+	subdirs := make(map[string]map[string]object.TreeEntry)
+	for name, entry := range files {
+		dir, rest, nested := strings.Cut(name, "/")
+		if !nested {
+			entry.Name = name
+			tree.Entries = append(tree.Entries, entry)
+			continue
+		}
+		if subdirs[dir] == nil {
+			subdirs[dir] = make(map[string]object.TreeEntry)
+		}
+		subdirs[dir][rest] = entry
+	}
+	for dir, children := range subdirs {
+		hash, err := writeTree(s, children)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash})
+	}
+	sort.Sort(object.TreeEntrySorter(tree.Entries))
+
+	obj := s.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write tree: %w", err)
+	}
+	return hash, nil
+}
+
+// lineHunk replaces base lines [start, end) with lines.
+type lineHunk struct {
+	start, end int
+	lines      []string
+}
+
+// mergeLines applies the edits theirs made to base onto ours. It reports a
+// conflict when an edit from each side touches the same or adjacent lines,
+// unless both sides made the identical edit.
+func mergeLines(base, ours, theirs string) (string, bool) {
+	merged, conflicts := mergeLinesWithConflicts(base, ours, theirs, "ours", "theirs")
+	if conflicts > 0 {
+		return "", false
+	}
+	return merged, true
+}
+
+// lineHunks lists the line edits that turn base into other.
+func lineHunks(base, other string) []lineHunk {
+	dmp := diffmatchpatch.New()
+	baseRunes, otherRunes, lines := dmp.DiffLinesToRunes(base, other)
+	diffs := dmp.DiffMainRunes(baseRunes, otherRunes, false)
+
+	var hunks []lineHunk
+	var current *lineHunk
+	pos := 0
+	for _, d := range diffs {
+		runes := []rune(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+			pos += len(runes)
+		case diffmatchpatch.DiffDelete:
+			if current == nil {
+				current = &lineHunk{start: pos, end: pos}
+			}
+			pos += len(runes)
+			current.end = pos
+		case diffmatchpatch.DiffInsert:
+			if current == nil {
+				current = &lineHunk{start: pos, end: pos}
+			}
+			for _, r := range runes {
+				current.lines = append(current.lines, lines[r])
+			}
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+	return hunks
+}
+
+func splitLinesKeepEnds(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"narrabyte/internal/events"
+	"runtime"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+const (
+	maxTransferWorkers = 8
+	// progressEmitEvery bounds how often transfer progress events are emitted
+	progressEmitEvery = 50
+)
+
+// objectTransferError identifies the exact object that failed to transfer.
+// Objects already copied stay in the target, so a retry only copies what is missing.
+type objectTransferError struct {
+	Hash plumbing.Hash
+	Type plumbing.ObjectType
+	Err  error
+}
+
+func (e *objectTransferError) Error() string {
+	return fmt.Sprintf("failed to transfer %s %s: %v", e.Type, e.Hash.String(), e.Err)
+}
+
+func (e *objectTransferError) Unwrap() error {
+	return e.Err
+}
+
+// transferProgress counts copied objects and emits throttled progress events.
+type transferProgress struct {
+	ctx        context.Context
+	sessionKey string
+	total      int
+	mu         sync.Mutex
+	done       int
+}
+
+func (p *transferProgress) advance() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if p.done == p.total || p.done%progressEmitEvery == 0 {
+		events.EmitGitTransferProgress(p.ctx, p.sessionKey, p.done, p.total)
+	}
+}
+
+// objectTransferPlan lists the objects missing from the target repository,
+// ordered so every object is written after the objects it references.
+type objectTransferPlan struct {
+	blobs   []plumbing.Hash
+	trees   []plumbing.Hash // children before parents
+	commits []plumbing.Hash // parents before children
+}
+
+// transferGitObjects transfers all git objects (commit, tree, blobs) from source to target repository
+// This ensures the target repository has all objects needed to checkout the commit.
+// Objects already present in the target are skipped, so a failed transfer can simply be retried.
+// It never touches references; callers update refs only after verifyCommitComplete succeeds.
+func transferGitObjects(ctx context.Context, sessionKey string, sourceRepo, targetRepo *git.Repository, commitHash plumbing.Hash) error {
+	return transferGitObjectsWithWorkers(ctx, sessionKey, sourceRepo, targetRepo, commitHash, defaultTransferWorkers())
+}
+
+func defaultTransferWorkers() int {
+	return min(runtime.NumCPU(), maxTransferWorkers)
+}
+
+func transferGitObjectsWithWorkers(ctx context.Context, sessionKey string, sourceRepo, targetRepo *git.Repository, commitHash plumbing.Hash, workers int) error {
+	emitSessionInfo(ctx, sessionKey, "Transferring git objects to main repository")
+
+	plan, err := planObjectTransfer(ctx, sessionKey, sourceRepo, targetRepo, commitHash, workers)
+	if err != nil {
+		return err
+	}
+
+	total := len(plan.blobs) + len(plan.trees) + len(plan.commits)
+	progress := &transferProgress{ctx: ctx, sessionKey: sessionKey, total: total}
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Transferring %d git objects (%d blobs, %d trees, %d commits)", total, len(plan.blobs), len(plan.trees), len(plan.commits)))
+
+	// Blobs reference nothing, so they can be copied in any order
+	if err := forEachObjectParallel(sourceRepo, targetRepo, plan.blobs, workers, func(src, dst storer.EncodedObjectStorer, hash plumbing.Hash) error {
+		if err := copyEncodedObject(src, dst, hash, plumbing.BlobObject); err != nil {
+			return err
+		}
+		progress.advance()
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, hash := range plan.trees {
+		if err := copyEncodedObject(sourceRepo.Storer, targetRepo.Storer, hash, plumbing.TreeObject); err != nil {
+			return err
+		}
+		progress.advance()
+	}
+
+	for _, hash := range plan.commits {
+		if err := copyEncodedObject(sourceRepo.Storer, targetRepo.Storer, hash, plumbing.CommitObject); err != nil {
+			return err
+		}
+		progress.advance()
+	}
+
+	emitSessionInfo(ctx, sessionKey, "Git objects transfer completed")
+	return nil
+}
+
+// verifyCommitComplete checks that the commit, its whole tree and its direct parents
+// exist in repo, so a ref can safely be pointed at it. The returned error names the
+// first missing object.
+func verifyCommitComplete(ctx context.Context, sessionKey string, repo *git.Repository, commitHash plumbing.Hash) error {
+	emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Verifying commit %s is complete in main repository", commitHash.String()[:8]))
+
+	commit, err := object.GetCommit(repo.Storer, commitHash)
+	if err != nil {
+		return &objectTransferError{Hash: commitHash, Type: plumbing.CommitObject, Err: err}
+	}
+	for _, parent := range commit.ParentHashes {
+		if !hasObject(repo.Storer, parent) {
+			return &objectTransferError{Hash: parent, Type: plumbing.CommitObject, Err: plumbing.ErrObjectNotFound}
+		}
+	}
+
+	var blobs []plumbing.Hash
+	seen := make(map[plumbing.Hash]struct{})
+	stack := []plumbing.Hash{commit.TreeHash}
+	for len(stack) > 0 {
+		treeHash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		tree, err := object.GetTree(repo.Storer, treeHash)
+		if err != nil {
+			return &objectTransferError{Hash: treeHash, Type: plumbing.TreeObject, Err: err}
+		}
+		for _, entry := range tree.Entries {
+			if _, ok := seen[entry.Hash]; ok {
+				continue
+			}
+			seen[entry.Hash] = struct{}{}
+			switch entry.Mode {
+			case filemode.Regular, filemode.Executable, filemode.Symlink, filemode.Deprecated:
+				blobs = append(blobs, entry.Hash)
+			case filemode.Dir:
+				stack = append(stack, entry.Hash)
+			}
+		}
+	}
+
+	missing, err := missingObjects(repo, repo, blobs, defaultTransferWorkers())
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &objectTransferError{Hash: missing[0], Type: plumbing.BlobObject, Err: plumbing.ErrObjectNotFound}
+	}
+	return nil
+}
+
+// planObjectTransfer walks the commit graph and trees iteratively, collecting
+// every object reachable from commitHash that the target does not already have.
+// Trees already present in the target are assumed complete and not descended into.
+func planObjectTransfer(ctx context.Context, sessionKey string, sourceRepo, targetRepo *git.Repository, commitHash plumbing.Hash, workers int) (*objectTransferPlan, error) {
+	plan := &objectTransferPlan{}
+	seenTrees := make(map[plumbing.Hash]struct{})
+	seenBlobs := make(map[plumbing.Hash]struct{})
+	var candidateBlobs []plumbing.Hash
+	var treeOrder []plumbing.Hash
+
+	pending := []plumbing.Hash{commitHash}
+	seenCommits := map[plumbing.Hash]struct{}{commitHash: {}}
+	for len(pending) > 0 {
+		hash := pending[0]
+		pending = pending[1:]
+
+		if hasObject(targetRepo.Storer, hash) {
+			continue
+		}
+
+		commit, err := sourceRepo.CommitObject(hash)
+		if err != nil {
+			if hash == commitHash {
+				return nil, fmt.Errorf("failed to get commit object: %w", err)
+			}
+			// Log warning but continue - parent might be from a different branch
+			emitSessionWarn(ctx, sessionKey, fmt.Sprintf("Could not transfer parent commit %s: %v", hash.String()[:8], err))
+			continue
+		}
+		plan.commits = append(plan.commits, hash)
+
+		stack := []plumbing.Hash{commit.TreeHash}
+		for len(stack) > 0 {
+			treeHash := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if _, ok := seenTrees[treeHash]; ok {
+				continue
+			}
+			seenTrees[treeHash] = struct{}{}
+			if hasObject(targetRepo.Storer, treeHash) {
+				continue
+			}
+
+			tree, err := object.GetTree(sourceRepo.Storer, treeHash)
+			if err != nil {
+				return nil, &objectTransferError{Hash: treeHash, Type: plumbing.TreeObject, Err: fmt.Errorf("failed to get tree object: %w", err)}
+			}
+			treeOrder = append(treeOrder, treeHash)
+
+			for _, entry := range tree.Entries {
+				switch entry.Mode {
+				case filemode.Regular, filemode.Executable, filemode.Symlink, filemode.Deprecated:
+					if _, ok := seenBlobs[entry.Hash]; !ok {
+						seenBlobs[entry.Hash] = struct{}{}
+						candidateBlobs = append(candidateBlobs, entry.Hash)
+					}
+				case filemode.Dir:
+					stack = append(stack, entry.Hash)
+				}
+			}
+		}
+
+		for _, parent := range commit.ParentHashes {
+			if _, ok := seenCommits[parent]; ok {
+				continue
+			}
+			seenCommits[parent] = struct{}{}
+			pending = append(pending, parent)
+		}
+	}
+
+	// Trees were discovered parents-first; write them children-first
+	for i := len(treeOrder) - 1; i >= 0; i-- {
+		plan.trees = append(plan.trees, treeOrder[i])
+	}
+	// Commits were discovered newest-first; write parents before children
+	for i, j := 0, len(plan.commits)-1; i < j; i, j = i+1, j-1 {
+		plan.commits[i], plan.commits[j] = plan.commits[j], plan.commits[i]
+	}
+
+	missing, err := missingObjects(sourceRepo, targetRepo, candidateBlobs, workers)
+	if err != nil {
+		return nil, err
+	}
+	plan.blobs = missing
+	return plan, nil
+}
+
+// missingObjects checks a batch of hashes against the target in parallel and
+// returns those it does not contain, preserving input order.
+func missingObjects(sourceRepo, targetRepo *git.Repository, hashes []plumbing.Hash, workers int) ([]plumbing.Hash, error) {
+	present := make([]bool, len(hashes))
+	index := make(map[plumbing.Hash]int, len(hashes))
+	for i, hash := range hashes {
+		index[hash] = i
+	}
+	if err := forEachObjectParallel(sourceRepo, targetRepo, hashes, workers, func(_, dst storer.EncodedObjectStorer, hash plumbing.Hash) error {
+		// Each hash maps to its own slot, so concurrent writes never overlap
+		present[index[hash]] = hasObject(dst, hash)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	missing := make([]plumbing.Hash, 0, len(hashes))
+	for i, hash := range hashes {
+		if !present[i] {
+			missing = append(missing, hash)
+		}
+	}
+	return missing, nil
+}
+
+// forEachObjectParallel runs fn for every hash on a bounded pool of workers.
+// go-git's filesystem storage is not safe for concurrent use, so each worker
+// opens its own storage handles; loose object writes are content addressed
+// and land via rename, which keeps concurrent writers safe. Repositories not
+// backed by the filesystem are processed sequentially.
+func forEachObjectParallel(sourceRepo, targetRepo *git.Repository, hashes []plumbing.Hash, workers int, fn func(src, dst storer.EncodedObjectStorer, hash plumbing.Hash) error) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	_, srcOK := sourceRepo.Storer.(*filesystem.Storage)
+	_, dstOK := targetRepo.Storer.(*filesystem.Storage)
+	if workers <= 1 || !srcOK || !dstOK {
+		for _, hash := range hashes {
+			if err := fn(sourceRepo.Storer, targetRepo.Storer, hash); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	workers = min(workers, len(hashes))
+
+	jobs := make(chan plumbing.Hash)
+	done := make(chan struct{})
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			src := workerStorage(sourceRepo)
+			dst := workerStorage(targetRepo)
+			for hash := range jobs {
+				if err := fn(src, dst, hash); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						close(done)
+					})
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, hash := range hashes {
+		select {
+		case jobs <- hash:
+		case <-done:
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
+
+// workerStorage opens an independent storage handle over the repository's .git directory.
+func workerStorage(repo *git.Repository) storer.EncodedObjectStorer {
+	fsStorage := repo.Storer.(*filesystem.Storage)
+	return filesystem.NewStorage(fsStorage.Filesystem(), cache.NewObjectLRUDefault())
+}
+
+// copyEncodedObject transfers a single git object from source to target storage
+func copyEncodedObject(source, target storer.EncodedObjectStorer, hash plumbing.Hash, objType plumbing.ObjectType) error {
+	// Check if object already exists in target
+	if hasObject(target, hash) {
+		return nil
+	}
+
+	// Get encoded object from source
+	encodedObj, err := source.EncodedObject(objType, hash)
+	if err != nil {
+		return &objectTransferError{Hash: hash, Type: objType, Err: fmt.Errorf("failed to get encoded object: %w", err)}
+	}
+
+	// Store encoded object in target
+	if _, err := target.SetEncodedObject(encodedObj); err != nil {
+		return &objectTransferError{Hash: hash, Type: objType, Err: err}
+	}
+	return nil
+}
+
+// hasObject reports whether the storage contains the object, without decoding it.
+func hasObject(s storer.EncodedObjectStorer, hash plumbing.Hash) bool {
+	return s.HasEncodedObject(hash) == nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"narrabyte/internal/events"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// newSyntheticDocsRepo creates a repository with fileCount markdown files spread
+// across nested directories and returns it with the hash of its single commit.
+func newSyntheticDocsRepo(tb testing.TB, fileCount int) (*git.Repository, plumbing.Hash) {
+	tb.Helper()
+	root := tb.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		tb.Fatalf("init source repo: %v", err)
+	}
+	for i := 0; i < fileCount; i++ {
+		dir := filepath.Join(root, "docs", fmt.Sprintf("section-%02d", i%20), fmt.Sprintf("group-%d", i%5))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			tb.Fatalf("mkdir: %v", err)
+		}
+		content := fmt.Sprintf("# Page %d\n\nGenerated content for page %d.\n", i, i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("page-%04d.md", i)), []byte(content), 0o644); err != nil {
+			tb.Fatalf("write file: %v", err)
+		}
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		tb.Fatalf("worktree: %v", err)
+	}
+	if err := wt.AddGlob("docs"); err != nil {
+		tb.Fatalf("add: %v", err)
+	}
+	hash, err := wt.Commit("synthetic docs", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		tb.Fatalf("commit: %v", err)
+	}
+	return repo, hash
+}
+
+func newEmptyTargetRepo(tb testing.TB) (*git.Repository, string) {
+	tb.Helper()
+	root := tb.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		tb.Fatalf("init target repo: %v", err)
+	}
+	return repo, root
+}
+
+func TestTransferGitObjectsTargetCanCheckout(t *testing.T) {
+	source, commitHash := newSyntheticDocsRepo(t, 1000)
+
+	for _, workers := range []int{1, maxTransferWorkers} {
+		target, targetRoot := newEmptyTargetRepo(t)
+		if err := transferGitObjectsWithWorkers(context.Background(), "test", source, target, commitHash, workers); err != nil {
+			t.Fatalf("workers=%d: transfer: %v", workers, err)
+		}
+
+		refName := plumbing.NewBranchReferenceName("docs")
+		if err := target.Storer.SetReference(plumbing.NewHashReference(refName, commitHash)); err != nil {
+			t.Fatalf("workers=%d: set reference: %v", workers, err)
+		}
+		wt, err := target.Worktree()
+		if err != nil {
+			t.Fatalf("workers=%d: worktree: %v", workers, err)
+		}
+		if err := wt.Checkout(&git.CheckoutOptions{Branch: refName, Force: true}); err != nil {
+			t.Fatalf("workers=%d: checkout: %v", workers, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(targetRoot, "docs", "section-07", "group-2", "page-0007.md"))
+		if err != nil {
+			t.Fatalf("workers=%d: read checked out file: %v", workers, err)
+		}
+		if string(data) != "# Page 7\n\nGenerated content for page 7.\n" {
+			t.Fatalf("workers=%d: unexpected content: %q", workers, data)
+		}
+	}
+}
+
+func TestTransferGitObjectsReportsFailedObjectAndResumes(t *testing.T) {
+	source, commitHash := newSyntheticDocsRepo(t, 40)
+	target, _ := newEmptyTargetRepo(t)
+
+	commit, err := source.CommitObject(commitHash)
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	file, err := commit.File("docs/section-03/group-3/page-0003.md")
+	if err != nil {
+		t.Fatalf("file: %v", err)
+	}
+	blobHash := file.Hash.String()
+	sourceRoot, err := source.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	loosePath := filepath.Join(sourceRoot.Filesystem.Root(), ".git", "objects", blobHash[:2], blobHash[2:])
+	blobData, err := os.ReadFile(loosePath)
+	if err != nil {
+		t.Fatalf("read loose object: %v", err)
+	}
+	if err := os.Remove(loosePath); err != nil {
+		t.Fatalf("remove loose object: %v", err)
+	}
+
+	err = transferGitObjectsWithWorkers(context.Background(), "test", source, target, commitHash, maxTransferWorkers)
+	var transferErr *objectTransferError
+	if !errors.As(err, &transferErr) {
+		t.Fatalf("expected objectTransferError, got %v", err)
+	}
+	if transferErr.Hash != file.Hash {
+		t.Fatalf("expected failure on %s, got %s", blobHash, transferErr.Hash)
+	}
+	if err := verifyCommitComplete(context.Background(), "test", target, commitHash); err == nil {
+		t.Fatal("expected verification to fail after a partial transfer")
+	}
+
+	if err := os.WriteFile(loosePath, blobData, 0o444); err != nil {
+		t.Fatalf("restore loose object: %v", err)
+	}
+
+	var lastTransferred, lastTotal int
+	previous := events.EmitGitTransferProgress
+	events.EmitGitTransferProgress = func(_ context.Context, _ string, transferred, total int) {
+		lastTransferred, lastTotal = transferred, total
+	}
+	defer func() { events.EmitGitTransferProgress = previous }()
+
+	if err := transferGitObjectsWithWorkers(context.Background(), "test", source, target, commitHash, 1); err != nil {
+		t.Fatalf("retry transfer: %v", err)
+	}
+	if lastTotal == 0 || lastTransferred != lastTotal {
+		t.Fatalf("expected final progress event, got %d/%d", lastTransferred, lastTotal)
+	}
+	if err := verifyCommitComplete(context.Background(), "test", target, commitHash); err != nil {
+		t.Fatalf("verify after retry: %v", err)
+	}
+}
+
+func BenchmarkTransferGitObjects(b *testing.B) {
+	source, commitHash := newSyntheticDocsRepo(b, 1000)
+
+	for _, bc := range []struct {
+		name    string
+		workers int
+	}{
+		{"sequential", 1},
+		{"parallel", maxTransferWorkers},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				target, _ := newEmptyTargetRepo(b)
+				b.StartTimer()
+				if err := transferGitObjectsWithWorkers(context.Background(), "bench", source, target, commitHash, bc.workers); err != nil {
+					b.Fatalf("transfer: %v", err)
+				}
+			}
+		})
+	}
+}
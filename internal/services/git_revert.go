@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RevertConflictError lists the files a revert could not undo because later
+// commits on the branch changed the same lines again. It formats as
+// ERR_REVERT_CONFLICT:<branch>:<path,path,...> so the UI can list them.
+type RevertConflictError struct {
+	Commit string
+	Branch string
+	Paths  []string
+}
+
+func (e *RevertConflictError) Error() string {
+	return fmt.Sprintf("ERR_REVERT_CONFLICT:%s:%s", e.Branch, strings.Join(e.Paths, ","))
+}
+
+// Revert commits the inverse of the changes commitHash made to its parent on
+// top of branch, keeping the reverted commit in history. The inverse diff is
+// replayed the same way CherryPick replays a commit, so files later commits
+// also edited are merged line by line and any overlap aborts the revert with
+// a *RevertConflictError, leaving the branch unchanged. When the branch is
+// checked out, its worktree must be clean and is updated to the new commit.
+func (g *GitService) Revert(repo *git.Repository, branch, commitHash string) (plumbing.Hash, error) {
+	if repo == nil {
+		return plumbing.ZeroHash, fmt.Errorf("repo cannot be nil")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return plumbing.ZeroHash, fmt.Errorf("branch name is required")
+	}
+	rev := strings.TrimSpace(commitHash)
+	if rev == "" {
+		return plumbing.ZeroHash, fmt.Errorf("commit is required")
+	}
+
+	commit, parentTree, commitTree, err := loadCommitTrees(repo, rev, "reverted")
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	target, err := loadBranchTarget(repo, branch)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	changes, err := object.DiffTree(commitTree, parentTree)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to diff commit: %w", err)
+	}
+	files, err := treeFiles(target.tree)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	conflicts, applied, err := applyTreeChanges(repo.Storer, files, changes)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(conflicts) > 0 {
+		return plumbing.ZeroHash, &RevertConflictError{Commit: commit.Hash.String(), Branch: branch, Paths: conflicts}
+	}
+	if applied == 0 {
+		return plumbing.ZeroHash, fmt.Errorf("the changes of commit %s are not on branch '%s'", commit.Hash.String()[:7], branch)
+	}
+
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	signature := signatureFromEnv()
+	return target.commit(repo, files, &object.Commit{
+		Author:    *signature,
+		Committer: *signature,
+		Message:   fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.\n", subject, commit.Hash.String()),
+	})
+}
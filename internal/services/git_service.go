@@ -5,18 +5,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"narrabyte/internal/events"
 	"narrabyte/internal/models"
 	"narrabyte/internal/utils"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 var excludedPatterns = []string{
@@ -183,6 +193,95 @@ func (g *GitService) Push(repo *git.Repository) error {
 	return repo.Push(&git.PushOptions{RemoteName: "origin"}) //Other options can be added
 }
 
+// PushBranch pushes branch to origin. A branch without an upstream, such as a
+// docs branch pushed for the first time, is then set to track its namesake on
+// origin so later ahead/behind checks compare against it. The push already
+// succeeded by then, so failing to set the upstream is only logged.
+func (g *GitService) PushBranch(repo *git.Repository, branch string) error {
+	if repo == nil {
+		return fmt.Errorf("repo cannot be nil")
+	}
+	name := strings.TrimSpace(branch)
+	if name == "" {
+		return fmt.Errorf("branch name is required")
+	}
+	refName := plumbing.NewBranchReferenceName(name)
+	err := repo.Push(&git.PushOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(refName.String() + ":" + refName.String())},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	if err := g.trackPushedBranch(repo, name, git.DefaultRemoteName); err != nil {
+		events.LoggerFrom(context.Background()).Warnf("Pushed '%s' but failed to set its upstream: %v", name, err)
+	}
+	return nil
+}
+
+// trackPushedBranch sets the upstream of branch to its namesake on remote
+// unless it already has one.
+func (g *GitService) trackPushedBranch(repo *git.Repository, branch, remote string) error {
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository config: %w", err)
+	}
+	if branchCfg, ok := cfg.Branches[branch]; ok && branchCfg.Remote != "" {
+		return nil
+	}
+	return g.SetUpstream(repo, branch, remote, branch)
+}
+
+// SetUpstream makes branch track remoteBranch on remote by writing its
+// branch.<name>.remote and branch.<name>.merge config, as git push -u does.
+// An empty remote means "origin" and an empty remoteBranch means branch.
+func (g *GitService) SetUpstream(repo *git.Repository, branch, remote, remoteBranch string) error {
+	if repo == nil {
+		return fmt.Errorf("repo cannot be nil")
+	}
+	name := strings.TrimSpace(branch)
+	if name == "" {
+		return fmt.Errorf("branch name is required")
+	}
+	remoteName := strings.TrimSpace(remote)
+	if remoteName == "" {
+		remoteName = git.DefaultRemoteName
+	}
+	tracked := strings.TrimSpace(remoteBranch)
+	if tracked == "" {
+		tracked = name
+	}
+	exists, err := g.BranchExists(repo, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("branch '%s' does not exist", name)
+	}
+	if _, err := repo.Remote(remoteName); err != nil {
+		return fmt.Errorf("failed to load remote '%s': %w", remoteName, err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository config: %w", err)
+	}
+	branchCfg, ok := cfg.Branches[name]
+	if !ok {
+		branchCfg = &gitconfig.Branch{Name: name}
+		cfg.Branches[name] = branchCfg
+	}
+	branchCfg.Remote = remoteName
+	branchCfg.Merge = plumbing.NewBranchReferenceName(tracked)
+	if err := branchCfg.Validate(); err != nil {
+		return fmt.Errorf("invalid upstream for '%s': %w", name, err)
+	}
+	if err := repo.Storer.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update branch config: %w", err)
+	}
+	return nil
+}
+
 // Pull changes from remote
 func (g *GitService) Pull(repo *git.Repository) error {
 	w, err := repo.Worktree()
@@ -194,6 +293,170 @@ func (g *GitService) Pull(repo *git.Repository) error {
 	return w.Pull(&git.PullOptions{RemoteName: "origin"}) //Other options can be added
 }
 
+// FetchPrune fetches remote and deletes the remote-tracking branches of
+// branches that no longer exist upstream. It returns the short names of the
+// pruned refs, e.g. "origin/feature", sorted. An empty remote means "origin".
+func (g *GitService) FetchPrune(repo *git.Repository, remote string) ([]string, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	remoteName := strings.TrimSpace(remote)
+	if remoteName == "" {
+		remoteName = git.DefaultRemoteName
+	}
+	if _, err := repo.Remote(remoteName); err != nil {
+		return nil, fmt.Errorf("failed to load remote '%s': %w", remoteName, err)
+	}
+
+	before, err := remoteTrackingRefs(repo, remoteName)
+	if err != nil {
+		return nil, err
+	}
+	// Auth is left to go-git's defaults, as for Push
+	err = repo.Fetch(&git.FetchOptions{RemoteName: remoteName, Prune: true})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to fetch '%s': %w", remoteName, err)
+	}
+	after, err := remoteTrackingRefs(repo, remoteName)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := []string{}
+	for name := range before {
+		if !after[name] {
+			pruned = append(pruned, name.Short())
+		}
+	}
+	sort.Strings(pruned)
+	return pruned, nil
+}
+
+// remoteCheckTimeout bounds ValidateRemote so an unreachable host cannot hang the UI.
+const remoteCheckTimeout = 10 * time.Second
+
+// ValidateRemote checks that remote is reachable and accepts auth by listing
+// its refs, as git ls-remote does. A nil auth leaves credentials to go-git's
+// defaults, as for Push, and an empty remote means "origin". Connection
+// problems are reported in the result; only a missing remote is an error.
+func (g *GitService) ValidateRemote(repo *git.Repository, remote string, auth transport.AuthMethod) (*models.RemoteStatus, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	remoteName := strings.TrimSpace(remote)
+	if remoteName == "" {
+		remoteName = git.DefaultRemoteName
+	}
+	r, err := repo.Remote(remoteName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load remote '%s': %w", remoteName, err)
+	}
+	status := &models.RemoteStatus{Remote: remoteName}
+	if urls := r.Config().URLs; len(urls) > 0 {
+		status.URL = urls[0]
+	}
+
+	ctx := g.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, remoteCheckTimeout)
+	defer cancel()
+	_, err = r.ListContext(ctx, &git.ListOptions{Auth: auth})
+	switch {
+	case err == nil, errors.Is(err, transport.ErrEmptyRemoteRepository):
+		status.Reachable = true
+	case isRemoteAuthError(err):
+		status.AuthFailed = true
+		status.Error = err.Error()
+	case ctx.Err() != nil:
+		status.Error = fmt.Sprintf("timed out after %s", remoteCheckTimeout)
+	default:
+		status.Error = err.Error()
+	}
+	return status, nil
+}
+
+// ValidateRemoteByPath opens the repository at repoPath and calls
+// ValidateRemote with the default credentials.
+func (g *GitService) ValidateRemoteByPath(repoPath, remote string) (*models.RemoteStatus, error) {
+	clean := strings.TrimSpace(repoPath)
+	if clean == "" {
+		return nil, fmt.Errorf("repository path cannot be empty")
+	}
+	repo, err := g.Open(clean)
+	if err != nil {
+		return nil, err
+	}
+	return g.ValidateRemote(repo, remote, nil)
+}
+
+// GetRemotes lists the remotes configured in repo, sorted by name. A
+// repository without remotes yields an empty list.
+func (g *GitService) GetRemotes(repo *git.Repository) ([]models.RemoteInfo, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	infos := make([]models.RemoteInfo, 0, len(remotes))
+	for _, r := range remotes {
+		cfg := r.Config()
+		infos = append(infos, models.RemoteInfo{
+			Name: cfg.Name,
+			URLs: append([]string{}, cfg.URLs...),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// GetRemotesByPath opens the repository at repoPath and calls GetRemotes.
+func (g *GitService) GetRemotesByPath(repoPath string) ([]models.RemoteInfo, error) {
+	clean := strings.TrimSpace(repoPath)
+	if clean == "" {
+		return nil, fmt.Errorf("repository path cannot be empty")
+	}
+	repo, err := g.Open(clean)
+	if err != nil {
+		return nil, err
+	}
+	return g.GetRemotes(repo)
+}
+
+// isRemoteAuthError reports whether err means the remote rejected or asked
+// for credentials. SSH failures carry no sentinel, so their message is checked.
+func isRemoteAuthError(err error) bool {
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) || errors.Is(err, transport.ErrInvalidAuthMethod) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unable to authenticate") || strings.Contains(msg, "permission denied")
+}
+
+// remoteTrackingRefs collects the refs under refs/remotes/<remote>/.
+func remoteTrackingRefs(repo *git.Repository, remote string) (map[plumbing.ReferenceName]bool, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	defer refs.Close()
+	prefix := "refs/remotes/" + remote + "/"
+	names := make(map[plumbing.ReferenceName]bool)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), prefix) && ref.Type() == plumbing.HashReference {
+			names[ref.Name()] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	return names, nil
+}
+
 // Checkout
 func (g *GitService) Checkout(repo *git.Repository, branch string) error {
 
@@ -209,40 +472,232 @@ func (g *GitService) Checkout(repo *git.Repository, branch string) error {
 
 // DiffBetweenCommits returns the patch (diff) between two commits by their hashes.
 func (g *GitService) DiffBetweenCommits(repo *git.Repository, hash1, hash2 string) (string, error) {
-	commit1, err := repo.CommitObject(plumbing.NewHash(hash1))
+	patch, err := commitPatch(repo, plumbing.NewHash(hash1), plumbing.NewHash(hash2))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := patch.Encode(&buf); err != nil {
+		return "", fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	filtered := filterUnifiedDiff(buf.String())
+	return filtered, nil
+}
+
+// DiffStat summarizes the changes between two revisions per file, skipping
+// the same files as the displayed diff. Both revisions are resolved with
+// ResolveRef. Binary files are listed with zero additions and deletions.
+func (g *GitService) DiffStat(repo *git.Repository, base, compare string) ([]models.FileDiffStat, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	baseRef, err := g.ResolveRef(repo, base)
+	if err != nil {
+		return nil, err
+	}
+	compareRef, err := g.ResolveRef(repo, compare)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := changedFiles(repo, baseRef.Hash, compareRef.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]models.FileDiffStat, 0, len(changes))
+	for _, change := range changes {
+		if !keepDiffPaths(normalizePathSlashes(change.From.Name), normalizePathSlashes(change.To.Name)) {
+			continue
+		}
+		patch, err := change.Patch()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get patch: %w", err)
+		}
+		path, status := changeStatus(change)
+		stat := models.FileDiffStat{Path: path, Status: status}
+		for _, fp := range patch.FilePatches() {
+			for _, chunk := range fp.Chunks() {
+				switch chunk.Type() {
+				case diff.Add:
+					stat.Additions += countLines(chunk.Content())
+				case diff.Delete:
+					stat.Deletions += countLines(chunk.Content())
+				}
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// DiffFile returns the unified diff of a single file between two revisions,
+// both resolved with ResolveRef. Only that file's patch is computed. Renames
+// are detected, so a renamed file is found by either its old or new path.
+// The result is empty when the file did not change or is one the displayed
+// diff skips, such as a lock file.
+func (g *GitService) DiffFile(repo *git.Repository, base, compare, path string) (string, error) {
+	if repo == nil {
+		return "", fmt.Errorf("repo cannot be nil")
+	}
+	target := normalizePathSlashes(path)
+	if target == "" {
+		return "", fmt.Errorf("file path is required")
+	}
+	baseRef, err := g.ResolveRef(repo, base)
+	if err != nil {
+		return "", err
+	}
+	compareRef, err := g.ResolveRef(repo, compare)
+	if err != nil {
+		return "", err
+	}
+	baseTree, err := commitTree(repo.CommitObject(baseRef.Hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree of %s: %w", base, err)
+	}
+	compareTree, err := commitTree(repo.CommitObject(compareRef.Hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree of %s: %w", compare, err)
+	}
+	changes, err := object.DiffTreeWithOptions(context.Background(), baseTree, compareTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	for _, change := range changes {
+		fromPath := normalizePathSlashes(change.From.Name)
+		toPath := normalizePathSlashes(change.To.Name)
+		if fromPath != target && toPath != target {
+			continue
+		}
+		if !keepDiffPaths(fromPath, toPath) {
+			return "", nil
+		}
+		patch, err := change.Patch()
+		if err != nil {
+			return "", fmt.Errorf("failed to get patch: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := patch.Encode(&buf); err != nil {
+			return "", fmt.Errorf("failed to encode patch: %w", err)
+		}
+		return buf.String(), nil
+	}
+	return "", nil
+}
+
+// changedFiles lists every file that changed from the commit base to the
+// commit compare, excluded files included. It is the listing behind both the
+// displayed diff stat and the docs export, so the two agree on which files
+// were added, renamed or deleted.
+func changedFiles(repo *git.Repository, base, compare plumbing.Hash) (object.Changes, error) {
+	baseTree, err := commitTree(repo.CommitObject(base))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", base.String(), err)
+	}
+	compareTree, err := commitTree(repo.CommitObject(compare))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", compare.String(), err)
+	}
+	changes, err := object.DiffTree(baseTree, compareTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+	return changes, nil
+}
+
+// changeStatus returns the path a change is listed under and whether the
+// file was "added", "deleted", "renamed" or "modified". Deleted files are
+// listed under their old path, all others under their new one.
+func changeStatus(change *object.Change) (string, string) {
+	fromPath := normalizePathSlashes(change.From.Name)
+	toPath := normalizePathSlashes(change.To.Name)
+	switch {
+	case fromPath == "":
+		return toPath, "added"
+	case toPath == "":
+		return fromPath, "deleted"
+	case fromPath != toPath:
+		return toPath, "renamed"
+	default:
+		return toPath, "modified"
+	}
+}
+
+// commitPatch returns the tree patch from the commit hash1 to the commit hash2.
+func commitPatch(repo *git.Repository, hash1, hash2 plumbing.Hash) (*object.Patch, error) {
+	commit1, err := repo.CommitObject(hash1)
 	if err != nil {
-		return "", fmt.Errorf("failed to get commit1: %w", err)
+		return nil, fmt.Errorf("failed to get commit1: %w", err)
 	}
-	commit2, err := repo.CommitObject(plumbing.NewHash(hash2))
+	commit2, err := repo.CommitObject(hash2)
 	if err != nil {
-		return "", fmt.Errorf("failed to get commit2: %w", err)
+		return nil, fmt.Errorf("failed to get commit2: %w", err)
 	}
 
 	tree1, err := commit1.Tree()
 	if err != nil {
-		return "", fmt.Errorf("failed to get tree1: %w", err)
+		return nil, fmt.Errorf("failed to get tree1: %w", err)
 	}
 	tree2, err := commit2.Tree()
 	if err != nil {
-		return "", fmt.Errorf("failed to get tree2: %w", err)
+		return nil, fmt.Errorf("failed to get tree2: %w", err)
 	}
 
 	patch, err := tree1.Patch(tree2)
 	if err != nil {
-		return "", fmt.Errorf("failed to get patch: %w", err)
+		return nil, fmt.Errorf("failed to get patch: %w", err)
 	}
+	return patch, nil
+}
 
-	var buf bytes.Buffer
-	if err := patch.Encode(&buf); err != nil {
-		return "", fmt.Errorf("failed to encode patch: %w", err)
+// countLines counts the lines of a diff chunk, including a final line without
+// a trailing newline.
+func countLines(content string) int {
+	if content == "" {
+		return 0
 	}
-
-	filtered := filterUnifiedDiff(buf.String())
-	return filtered, nil
+	n := strings.Count(content, "\n")
+	if !strings.HasSuffix(content, "\n") {
+		n++
+	}
+	return n
 }
 
 // filterUnifiedDiff removes segments whose file path matches shouldExclude; preserves unified diff markers.
 func filterUnifiedDiff(diffText string) string {
+	return filterDiffSegments(diffText, keepDiffPaths)
+}
+
+// keepDiffPaths reports whether a change from fileA to fileB is shown; either
+// path may be empty for added or deleted files.
+func keepDiffPaths(fileA, fileB string) bool {
+	return !(fileA != "" && shouldExclude(fileA)) && !(fileB != "" && shouldExclude(fileB))
+}
+
+// scopeUnifiedDiff keeps only the segments touching a file under scope, a
+// slash-separated path relative to the repository root. A segment is kept when
+// either its old or new path is in scope. An empty scope returns diffText unchanged.
+func scopeUnifiedDiff(diffText string, scope string) string {
+	if scope == "" {
+		return diffText
+	}
+	return filterDiffSegments(diffText, func(fileA, fileB string) bool {
+		return pathInScope(fileA, scope) || pathInScope(fileB, scope)
+	})
+}
+
+// pathInScope reports whether the slash-separated path p is scope or lies below it.
+func pathInScope(p string, scope string) bool {
+	p = normalizePathSlashes(p)
+	return p != "" && (p == scope || strings.HasPrefix(p, scope+"/"))
+}
+
+// filterDiffSegments splits a unified diff into per-file segments and keeps
+// those for which keep returns true, given the old and new paths.
+func filterDiffSegments(diffText string, keep func(fileA, fileB string) bool) string {
 	if diffText == "" {
 		return ""
 	}
@@ -252,7 +707,7 @@ func filterUnifiedDiff(diffText string) string {
 	fileA := ""
 	fileB := ""
 	flush := func() {
-		if (fileA != "" && shouldExclude(fileA)) || (fileB != "" && shouldExclude(fileB)) {
+		if !keep(fileA, fileB) {
 			segment = segment[:0]
 			fileA, fileB = "", ""
 			return
@@ -305,12 +760,18 @@ func normalizePathSlashes(p string) string {
 
 // shouldExclude returns true if the provided path matches any of the excludedPatterns.
 func shouldExclude(path string) bool {
+	return matchesPathPatterns(path, excludedPatterns)
+}
+
+// matchesPathPatterns reports whether path matches one of patterns, either by
+// base name or as a glob against the full path or the base name.
+func matchesPathPatterns(path string, patterns []string) bool {
 	p := normalizePathSlashes(path)
 	if p == "" {
 		return false
 	}
 	base := filepath.Base(p)
-	for _, raw := range excludedPatterns {
+	for _, raw := range patterns {
 		pat := normalizePathSlashes(raw)
 		// Preserve previous behavior: exact base-name match anywhere
 		if pat == base {
@@ -328,7 +789,7 @@ func shouldExclude(path string) bool {
 	return false
 }
 
-// DiffBetweenBranches returns the patch (diff) between two branches by name.
+// DiffBetweenBranches returns the patch (diff) between a base revision and a branch.
 func (g *GitService) DiffBetweenBranches(repo *git.Repository, baseBranch, compareBranch string) (string, error) {
 	if repo == nil {
 		return "", fmt.Errorf("repo cannot be nil")
@@ -337,12 +798,10 @@ func (g *GitService) DiffBetweenBranches(repo *git.Repository, baseBranch, compa
 		return "", fmt.Errorf("branch names are required")
 	}
 
-	baseRef, err := repo.Reference(plumbing.NewBranchReferenceName(baseBranch), true)
+	// The base may be any revision, e.g. a tag the docs branch was started from
+	base, err := g.ResolveRef(repo, baseBranch)
 	if err != nil {
-		if errors.Is(err, plumbing.ErrReferenceNotFound) {
-			return "", fmt.Errorf("branch '%s' not found", baseBranch)
-		}
-		return "", fmt.Errorf("failed to resolve branch '%s': %w", baseBranch, err)
+		return "", err
 	}
 
 	compareRef, err := repo.Reference(plumbing.NewBranchReferenceName(compareBranch), true)
@@ -353,7 +812,7 @@ func (g *GitService) DiffBetweenBranches(repo *git.Repository, baseBranch, compa
 		return "", fmt.Errorf("failed to resolve branch '%s': %w", compareBranch, err)
 	}
 
-	return g.DiffBetweenCommits(repo, baseRef.Hash().String(), compareRef.Hash().String())
+	return g.DiffBetweenCommits(repo, base.Hash.String(), compareRef.Hash().String())
 }
 
 // LatestCommit returns the latest commit hash for the given repository path
@@ -512,58 +971,367 @@ func (g *GitService) StageAll(repo *git.Repository) error {
 	return nil
 }
 
-// Commit creates a commit with the provided message using the staged changes.
-func (g *GitService) Commit(repo *git.Repository, message string) (plumbing.Hash, error) {
+// Unstage resets the index entries of the provided paths to HEAD, like
+// `git restore --staged`. The worktree is left untouched.
+func (g *GitService) Unstage(repo *git.Repository, paths []string) error {
 	if repo == nil {
-		return plumbing.ZeroHash, fmt.Errorf("repo cannot be nil")
+		return fmt.Errorf("repo cannot be nil")
 	}
-	if strings.TrimSpace(message) == "" {
-		return plumbing.ZeroHash, fmt.Errorf("commit message cannot be empty")
+
+	var normalized []string
+	for _, path := range paths {
+		clean := strings.TrimSpace(path)
+		if clean == "" {
+			continue
+		}
+		normalized = append(normalized, filepath.ToSlash(strings.ReplaceAll(clean, "\\", "/")))
+	}
+	if len(normalized) == 0 {
+		return nil
 	}
 
-	wt, err := repo.Worktree()
+	hasHead, err := g.hasHeadCommit(repo)
 	if err != nil {
-		return plumbing.ZeroHash, fmt.Errorf("failed to get worktree: %w", err)
+		return err
+	}
+	if !hasHead {
+		// Nothing is committed yet, so every staged entry is new: drop them.
+		return g.removeIndexEntries(repo, normalized)
 	}
 
-	sig := signatureFromEnv()
-	hash, err := wt.Commit(message, &git.CommitOptions{
-		Author:    sig,
-		Committer: sig,
-	})
+	wt, err := repo.Worktree()
 	if err != nil {
-		return plumbing.ZeroHash, fmt.Errorf("failed to create commit: %w", err)
+		return fmt.Errorf("failed to get worktree: %w", err)
 	}
-
-	return hash, nil
+	if err := wt.Restore(&git.RestoreOptions{Staged: true, Files: normalized}); err != nil {
+		return fmt.Errorf("failed to unstage files: %w", err)
+	}
+	return nil
 }
 
-func signatureFromEnv() *object.Signature {
-	name := os.Getenv("GIT_AUTHOR_NAME")
-	if name == "" {
-		name = os.Getenv("GIT_COMMITTER_NAME")
-	}
-	if name == "" {
-		name = "Narrabyte Documentation Generator"
+// ResetIndex resets every index entry to HEAD, like `git reset --mixed`,
+// clearing all staged changes without touching the worktree.
+func (g *GitService) ResetIndex(repo *git.Repository) error {
+	if repo == nil {
+		return fmt.Errorf("repo cannot be nil")
 	}
 
-	email := os.Getenv("GIT_AUTHOR_EMAIL")
-	if email == "" {
-		email = os.Getenv("GIT_COMMITTER_EMAIL")
+	hasHead, err := g.hasHeadCommit(repo)
+	if err != nil {
+		return err
 	}
-	if email == "" {
-		email = "docs@narrabyte.ai"
+	if !hasHead {
+		return g.removeIndexEntries(repo, nil)
 	}
 
-	return &object.Signature{
-		Name:  name,
-		Email: email,
-		When:  time.Now(),
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
 	}
+	if err := wt.Reset(&git.ResetOptions{Mode: git.MixedReset}); err != nil {
+		return fmt.Errorf("failed to reset index: %w", err)
+	}
+	return nil
 }
 
-// GetCurrentBranch returns the name of the current branch for the repository at the given path.
-func (g *GitService) GetCurrentBranch(repoPath string) (string, error) {
+func (g *GitService) hasHeadCommit(repo *git.Repository) (bool, error) {
+	if _, err := repo.Head(); err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return true, nil
+}
+
+// removeIndexEntries drops the given paths from the index, or every entry
+// when paths is empty.
+func (g *GitService) removeIndexEntries(repo *git.Repository, paths []string) error {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	if len(paths) == 0 {
+		idx.Entries = nil
+	} else {
+		for _, path := range paths {
+			if _, err := idx.Remove(path); err != nil && !errors.Is(err, index.ErrEntryNotFound) {
+				return fmt.Errorf("failed to unstage '%s': %w", path, err)
+			}
+		}
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// CleanUntracked removes the untracked files under pathPrefix, like
+// `git clean -f -- <prefix>`, and returns their repository-relative paths.
+// Ignored files are only removed when includeIgnored is set; tracked files and
+// nested repositories are never touched. With dryRun nothing is removed and
+// the returned paths are what would be.
+func (g *GitService) CleanUntracked(repo *git.Repository, pathPrefix string, includeIgnored, dryRun bool) ([]string, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	prefix := path.Clean(normalizePathSlashes(pathPrefix))
+	if prefix == "." || prefix == "/" {
+		prefix = ""
+	}
+	if prefix == ".." || strings.HasPrefix(prefix, "../") || strings.HasPrefix(prefix, "/") {
+		return nil, fmt.Errorf("path prefix '%s' is outside the repository", pathPrefix)
+	}
+	if prefix == ".git" || strings.HasPrefix(prefix, ".git/") {
+		return nil, fmt.Errorf("path prefix '%s' is inside the git directory", pathPrefix)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	root := wt.Filesystem.Root()
+
+	var candidates []string
+	if includeIgnored {
+		candidates, err = untrackedFilesIncludingIgnored(repo, root, prefix)
+	} else {
+		candidates, err = untrackedFiles(wt, prefix)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0, len(candidates))
+	for _, rel := range candidates {
+		if insideNestedRepo(root, rel) {
+			continue
+		}
+		removed = append(removed, rel)
+	}
+	sort.Strings(removed)
+	if dryRun {
+		return removed, nil
+	}
+
+	for _, rel := range removed {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.Remove(abs); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove '%s': %w", rel, err)
+		}
+		pruneEmptyDirs(filepath.Dir(abs), filepath.Join(root, filepath.FromSlash(prefix)))
+	}
+	return removed, nil
+}
+
+// untrackedFiles lists the untracked, non-ignored files under prefix.
+func untrackedFiles(wt *git.Worktree, prefix string) ([]string, error) {
+	status, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	var files []string
+	for p, st := range status {
+		if st.Worktree != git.Untracked {
+			continue
+		}
+		rel := filepath.ToSlash(p)
+		if hasPathPrefix(rel, prefix) {
+			files = append(files, rel)
+		}
+	}
+	return files, nil
+}
+
+// untrackedFilesIncludingIgnored lists every file under prefix that is not in
+// the index, ignored or not.
+func untrackedFilesIncludingIgnored(repo *git.Repository, root, prefix string) ([]string, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	tracked := make(map[string]bool, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		tracked[entry.Name] = true
+	}
+
+	start := filepath.Join(root, filepath.FromSlash(prefix))
+	if _, err := os.Stat(start); os.IsNotExist(err) {
+		return nil, nil
+	}
+	var files []string
+	err = filepath.WalkDir(start, func(p string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !tracked[rel] {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files under '%s': %w", prefix, err)
+	}
+	return files, nil
+}
+
+// StatusSummary returns the working tree status of the files under
+// pathPrefix, a directory relative to the repository root. An empty or "."
+// prefix covers the whole repository. Files are sorted by path.
+func (g *GitService) StatusSummary(repo *git.Repository, pathPrefix string) (*models.StatusSummary, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status: %w", err)
+	}
+	summary := summarizeStatus(status, pathPrefix)
+	return &summary, nil
+}
+
+// summarizeStatus lists the changed files of status under pathPrefix with
+// describeStatus labels.
+func summarizeStatus(status git.Status, pathPrefix string) models.StatusSummary {
+	prefix := filepath.ToSlash(filepath.Clean(pathPrefix))
+	if prefix == "." {
+		prefix = ""
+	}
+	files := make([]models.DocChangedFile, 0)
+	for file, st := range status {
+		if st == nil || (st.Staging == git.Unmodified && st.Worktree == git.Unmodified) {
+			continue
+		}
+		rel := filepath.ToSlash(file)
+		if !hasPathPrefix(rel, prefix) {
+			continue
+		}
+		files = append(files, models.DocChangedFile{Path: rel, Status: describeStatus(*st)})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return models.StatusSummary{Clean: len(files) == 0, Files: files}
+}
+
+// describeStatus labels a file's status, preferring the worktree code over
+// the staged one.
+func describeStatus(st git.FileStatus) string {
+	code := st.Worktree
+	if code == git.Unmodified {
+		code = st.Staging
+	}
+	switch code {
+	case git.Added:
+		return "added"
+	case git.Untracked:
+		return "untracked"
+	case git.Modified:
+		return "modified"
+	case git.Deleted:
+		return "deleted"
+	case git.Renamed:
+		return "renamed"
+	case git.Copied:
+		return "copied"
+	default:
+		return "changed"
+	}
+}
+
+// hasPathPrefix reports whether the slash-separated rel is prefix or lies
+// under it. An empty prefix matches everything.
+func hasPathPrefix(rel, prefix string) bool {
+	return prefix == "" || rel == prefix || strings.HasPrefix(rel, prefix+"/")
+}
+
+// insideNestedRepo reports whether rel lies in a directory below root that
+// holds its own .git, i.e. another repository.
+func insideNestedRepo(root, rel string) bool {
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), ".git")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneEmptyDirs removes dir and its parents while they are empty, stopping
+// at stop, which is kept.
+func pruneEmptyDirs(dir, stop string) {
+	stop = filepath.Clean(stop)
+	for dir = filepath.Clean(dir); dir != stop && strings.HasPrefix(dir, stop); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+// Commit creates a commit with the provided message using the staged changes.
+func (g *GitService) Commit(repo *git.Repository, message string) (plumbing.Hash, error) {
+	if repo == nil {
+		return plumbing.ZeroHash, fmt.Errorf("repo cannot be nil")
+	}
+	if strings.TrimSpace(message) == "" {
+		return plumbing.ZeroHash, fmt.Errorf("commit message cannot be empty")
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	sig := signatureFromEnv()
+	hash, err := wt.Commit(message, &git.CommitOptions{
+		Author:    sig,
+		Committer: sig,
+	})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	return hash, nil
+}
+
+func signatureFromEnv() *object.Signature {
+	name := os.Getenv("GIT_AUTHOR_NAME")
+	if name == "" {
+		name = os.Getenv("GIT_COMMITTER_NAME")
+	}
+	if name == "" {
+		name = "Narrabyte Documentation Generator"
+	}
+
+	email := os.Getenv("GIT_AUTHOR_EMAIL")
+	if email == "" {
+		email = os.Getenv("GIT_COMMITTER_EMAIL")
+	}
+	if email == "" {
+		email = "docs@narrabyte.ai"
+	}
+
+	return &object.Signature{
+		Name:  name,
+		Email: email,
+		When:  time.Now(),
+	}
+}
+
+// GetCurrentBranch returns the name of the current branch for the repository at the given path.
+func (g *GitService) GetCurrentBranch(repoPath string) (string, error) {
 	if repoPath == "" {
 		return "", fmt.Errorf("repository path cannot be empty")
 	}
@@ -641,6 +1409,571 @@ func (g *GitService) BranchExists(repo *git.Repository, branch string) (bool, er
 	return false, fmt.Errorf("failed to check branch '%s': %w", name, err)
 }
 
+// CreateBranch creates the local branch name pointing at start, which must be
+// a commit in repo. An existing branch is an error unless force is set, in
+// which case it is moved to start.
+func (g *GitService) CreateBranch(repo *git.Repository, name string, start plumbing.Hash, force bool) error {
+	exists, err := g.BranchExists(repo, name)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if exists && !force {
+		return fmt.Errorf("branch '%s' already exists", name)
+	}
+	refName := plumbing.NewBranchReferenceName(name)
+	if err := refName.Validate(); err != nil {
+		return fmt.Errorf("invalid branch name '%s': %w", name, err)
+	}
+	if start.IsZero() {
+		return fmt.Errorf("cannot create branch '%s': start commit is empty", name)
+	}
+	if _, err := repo.CommitObject(start); err != nil {
+		return fmt.Errorf("cannot create branch '%s': failed to load commit %s: %w", name, start, err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, start)); err != nil {
+		return fmt.Errorf("failed to create branch '%s': %w", name, err)
+	}
+	return nil
+}
+
+// EnsureBranch creates a local branch pointing at fromRef when it does not
+// exist yet and reports whether it was created. An empty fromRef or "HEAD"
+// starts the branch at HEAD; anything else is resolved with ResolveRef. An
+// existing branch is left untouched.
+func (g *GitService) EnsureBranch(repo *git.Repository, branch, fromRef string) (bool, error) {
+	exists, err := g.BranchExists(repo, branch)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	name := strings.TrimSpace(branch)
+	if err := plumbing.NewBranchReferenceName(name).Validate(); err != nil {
+		return false, fmt.Errorf("invalid branch name '%s': %w", name, err)
+	}
+
+	var start plumbing.Hash
+	from := strings.TrimSpace(fromRef)
+	if from == "" || from == "HEAD" {
+		head, err := repo.Head()
+		if err != nil {
+			if errors.Is(err, plumbing.ErrReferenceNotFound) {
+				return false, fmt.Errorf("cannot create branch '%s': the repository has no commits yet", name)
+			}
+			return false, fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		start = head.Hash()
+	} else {
+		resolved, err := g.ResolveRef(repo, from)
+		if err != nil {
+			return false, fmt.Errorf("cannot create branch '%s': %w", name, err)
+		}
+		start = resolved.Hash
+	}
+
+	if err := g.CreateBranch(repo, name, start, false); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResolvedRef is a revision resolved to a commit by ResolveRef.
+type ResolvedRef struct {
+	Hash plumbing.Hash `json:"hash"`
+	// Label describes what the revision matched, e.g. "tag 'v1.2.0'"
+	Label string `json:"label"`
+}
+
+// ResolveRef resolves ref to a commit, trying in order a local branch, a tag,
+// a remote-tracking branch and finally any revision git understands, such as
+// a full or short SHA or HEAD~2. Annotated tags are peeled to their commit.
+func (g *GitService) ResolveRef(repo *git.Repository, ref string) (*ResolvedRef, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	name := strings.TrimSpace(ref)
+	if name == "" {
+		return nil, fmt.Errorf("revision is required")
+	}
+
+	candidates := []struct {
+		kind    string
+		refName plumbing.ReferenceName
+	}{
+		{"branch", plumbing.NewBranchReferenceName(name)},
+		{"tag", plumbing.NewTagReferenceName(name)},
+		{"remote branch", plumbing.ReferenceName("refs/remotes/" + name)},
+	}
+	for _, c := range candidates {
+		r, err := repo.Reference(c.refName, true)
+		if err != nil {
+			if errors.Is(err, plumbing.ErrReferenceNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to resolve %s '%s': %w", c.kind, name, err)
+		}
+		hash, err := peelToCommit(repo, r.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s '%s': %w", c.kind, name, err)
+		}
+		return &ResolvedRef{Hash: hash, Label: fmt.Sprintf("%s '%s'", c.kind, name)}, nil
+	}
+
+	if hash, err := repo.ResolveRevision(plumbing.Revision(name)); err == nil {
+		commit, err := peelToCommit(repo, *hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve revision '%s': %w", name, err)
+		}
+		return &ResolvedRef{Hash: commit, Label: fmt.Sprintf("revision '%s'", name)}, nil
+	}
+	return nil, fmt.Errorf("'%s' not found (tried branch, tag, remote branch and revision)", name)
+}
+
+// MergeBase returns the hash of the best common ancestor of a and b, both
+// resolved with ResolveRef, as git merge-base a b would. Revisions with
+// unrelated histories are an error.
+func (g *GitService) MergeBase(repo *git.Repository, a, b string) (string, error) {
+	if repo == nil {
+		return "", fmt.Errorf("repo cannot be nil")
+	}
+	aRef, err := g.ResolveRef(repo, a)
+	if err != nil {
+		return "", err
+	}
+	bRef, err := g.ResolveRef(repo, b)
+	if err != nil {
+		return "", err
+	}
+	aCommit, err := repo.CommitObject(aRef.Hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to load commit for %s: %w", aRef.Label, err)
+	}
+	bCommit, err := repo.CommitObject(bRef.Hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to load commit for %s: %w", bRef.Label, err)
+	}
+	bases, err := mergeBases(aCommit, bCommit)
+	if err != nil {
+		return "", err
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("%s and %s have unrelated histories", aRef.Label, bRef.Label)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+// mergeBases returns the best common ancestors of a and b, none when their
+// histories are unrelated.
+func mergeBases(a, b *object.Commit) ([]*object.Commit, error) {
+	bases, err := a.MergeBase(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base: %w", err)
+	}
+	return bases, nil
+}
+
+// AheadBehind reports how many commits compare has that base lacks (ahead)
+// and how many base has that compare lacks (behind), counted from their merge
+// base. Both revisions are resolved with ResolveRef.
+func (g *GitService) AheadBehind(repo *git.Repository, base, compare string) (*models.AheadBehind, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	baseRef, err := g.ResolveRef(repo, base)
+	if err != nil {
+		return nil, err
+	}
+	compareRef, err := g.ResolveRef(repo, compare)
+	if err != nil {
+		return nil, err
+	}
+	baseCommit, err := repo.CommitObject(baseRef.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit for %s: %w", baseRef.Label, err)
+	}
+	compareCommit, err := repo.CommitObject(compareRef.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit for %s: %w", compareRef.Label, err)
+	}
+
+	bases, err := mergeBases(baseCommit, compareCommit)
+	if err != nil {
+		return nil, err
+	}
+	// Everything reachable from a merge base is shared by both sides
+	shared := make(map[plumbing.Hash]bool)
+	for _, mb := range bases {
+		if err := walkCommits(mb, shared, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &models.AheadBehind{}
+	if len(bases) > 0 {
+		result.MergeBase = bases[0].Hash.String()
+	}
+	if result.Ahead, err = countCommitsFrom(compareCommit, shared); err != nil {
+		return nil, err
+	}
+	if result.Behind, err = countCommitsFrom(baseCommit, shared); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// IsBranchMerged reports whether branch has been merged into into: whether
+// the tip of branch is reachable from the tip of into. Both must be local
+// branches; a branch counts as merged into itself.
+func (g *GitService) IsBranchMerged(repo *git.Repository, branch, into string) (bool, error) {
+	if repo == nil {
+		return false, fmt.Errorf("repo cannot be nil")
+	}
+	branch = strings.TrimSpace(branch)
+	into = strings.TrimSpace(into)
+	if branch == "" || into == "" {
+		return false, fmt.Errorf("branch names are required")
+	}
+	branchRef, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve branch '%s': %w", branch, err)
+	}
+	intoRef, err := repo.Reference(plumbing.NewBranchReferenceName(into), true)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve branch '%s': %w", into, err)
+	}
+	return isAncestorCommit(repo, branchRef.Hash(), intoRef.Hash())
+}
+
+// isAncestorCommit reports whether ancestor is reachable from descendant,
+// counting a commit as its own ancestor like git merge-base --is-ancestor.
+func isAncestorCommit(repo *git.Repository, ancestor, descendant plumbing.Hash) (bool, error) {
+	ancestorCommit, err := repo.CommitObject(ancestor)
+	if err != nil {
+		return false, fmt.Errorf("failed to load commit %s: %w", ancestor, err)
+	}
+	descendantCommit, err := repo.CommitObject(descendant)
+	if err != nil {
+		return false, fmt.Errorf("failed to load commit %s: %w", descendant, err)
+	}
+	return ancestorCommit.IsAncestor(descendantCommit)
+}
+
+// Blame attributes every line of path, a slash-separated path relative to the
+// repository root, to the commit that last changed it as of rev. rev is
+// resolved with ResolveRef.
+func (g *GitService) Blame(repo *git.Repository, rev, path string) ([]models.BlameLine, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	path = normalizePathSlashes(path)
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	ref, err := g.ResolveRef(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(ref.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit for %s: %w", ref.Label, err)
+	}
+	result, err := git.Blame(commit, path)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("file '%s' not found at %s", path, ref.Label)
+		}
+		return nil, fmt.Errorf("failed to blame '%s': %w", path, err)
+	}
+
+	lines := make([]models.BlameLine, 0, len(result.Lines))
+	for i, line := range result.Lines {
+		lines = append(lines, models.BlameLine{
+			LineNumber: i + 1,
+			Hash:       line.Hash.String(),
+			Author:     line.AuthorName,
+			Email:      line.Author,
+			Date:       line.Date,
+			Text:       line.Text,
+		})
+	}
+	return lines, nil
+}
+
+// FileAtRef reads a file as of rev, which is resolved with ResolveRef. Missing
+// files yield an error wrapping object.ErrFileNotFound; directories and
+// submodules are rejected. Binary files are returned without their content.
+func (g *GitService) FileAtRef(repo *git.Repository, rev, path string) (*models.FileAtRef, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	path = strings.Trim(normalizePathSlashes(path), "/")
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	ref, err := g.ResolveRef(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(ref.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit for %s: %w", ref.Label, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tree for %s: %w", ref.Label, err)
+	}
+
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, fmt.Errorf("file '%s' not found at %s: %w", path, ref.Label, object.ErrFileNotFound)
+		}
+		return nil, fmt.Errorf("failed to look up '%s' at %s: %w", path, ref.Label, err)
+	}
+	if !entry.Mode.IsFile() {
+		if entry.Mode == filemode.Dir {
+			return nil, fmt.Errorf("'%s' is a directory at %s", path, ref.Label)
+		}
+		return nil, fmt.Errorf("'%s' is not a regular file at %s", path, ref.Label)
+	}
+
+	file, err := tree.TreeEntryFile(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s' at %s: %w", path, ref.Label, err)
+	}
+	result := &models.FileAtRef{Path: path, Hash: ref.Hash.String(), Size: file.Size}
+	binary, err := file.IsBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s' at %s: %w", path, ref.Label, err)
+	}
+	if binary {
+		result.Binary = true
+		return result, nil
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s' at %s: %w", path, ref.Label, err)
+	}
+	result.Content = content
+	return result, nil
+}
+
+// ShowCommit returns the message, author, date and changed-file count of rev,
+// which is resolved with ResolveRef. Files are counted against the first
+// parent, or against an empty tree for a root commit.
+func (g *GitService) ShowCommit(repo *git.Repository, rev string) (*models.CommitInfo, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	ref, err := g.ResolveRef(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(ref.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit for %s: %w", ref.Label, err)
+	}
+	return commitInfo(commit)
+}
+
+// CommitLog lists the commits compare has that base lacks, newest first, as
+// git log base..compare would. Shared history is bounded by the merge base.
+// At most limit commits are returned; limit <= 0 returns them all.
+func (g *GitService) CommitLog(repo *git.Repository, base, compare string, limit int) ([]models.CommitInfo, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	baseRef, err := g.ResolveRef(repo, base)
+	if err != nil {
+		return nil, err
+	}
+	compareRef, err := g.ResolveRef(repo, compare)
+	if err != nil {
+		return nil, err
+	}
+	baseCommit, err := repo.CommitObject(baseRef.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit for %s: %w", baseRef.Label, err)
+	}
+	compareCommit, err := repo.CommitObject(compareRef.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit for %s: %w", compareRef.Label, err)
+	}
+
+	bases, err := mergeBases(baseCommit, compareCommit)
+	if err != nil {
+		return nil, err
+	}
+	shared := make(map[plumbing.Hash]bool)
+	for _, mb := range bases {
+		if err := walkCommits(mb, shared, nil); err != nil {
+			return nil, err
+		}
+	}
+	unique := make(map[plumbing.Hash]bool)
+	if err := walkCommits(compareCommit, unique, shared); err != nil {
+		return nil, err
+	}
+	want := len(unique)
+	if limit > 0 && limit < want {
+		want = limit
+	}
+	if want == 0 {
+		return []models.CommitInfo{}, nil
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: compareCommit.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log of %s: %w", compareRef.Label, err)
+	}
+	defer iter.Close()
+	result := make([]models.CommitInfo, 0, want)
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if !unique[commit.Hash] {
+			return nil
+		}
+		info, err := commitInfo(commit)
+		if err != nil {
+			return err
+		}
+		result = append(result, *info)
+		if len(result) == want {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Log pages through the history of branch, newest first: skip commits are
+// passed over and at most limit are returned. limit <= 0 returns the rest of
+// the history. branch is resolved with ResolveRef.
+func (g *GitService) Log(repo *git.Repository, branch string, limit, skip int) ([]models.CommitInfo, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repo cannot be nil")
+	}
+	if skip < 0 {
+		return nil, fmt.Errorf("skip must not be negative")
+	}
+	ref, err := g.ResolveRef(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := repo.Log(&git.LogOptions{From: ref.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log of %s: %w", ref.Label, err)
+	}
+	defer iter.Close()
+
+	result := []models.CommitInfo{}
+	seen := 0
+	err = iter.ForEach(func(commit *object.Commit) error {
+		seen++
+		if seen <= skip {
+			return nil
+		}
+		info, err := commitInfo(commit)
+		if err != nil {
+			return err
+		}
+		result = append(result, *info)
+		if limit > 0 && len(result) == limit {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// commitInfo describes commit, counting the files it changed relative to its
+// first parent.
+func commitInfo(commit *object.Commit) (*models.CommitInfo, error) {
+	short := commit.Hash.String()[:7]
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tree for %s: %w", short, err)
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load parent of %s: %w", short, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to load parent tree of %s: %w", short, err)
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s: %w", short, err)
+	}
+	return &models.CommitInfo{
+		Hash:         commit.Hash.String(),
+		ShortHash:    short,
+		Message:      strings.TrimSpace(commit.Message),
+		Author:       commit.Author.Name,
+		Email:        commit.Author.Email,
+		Date:         commit.Author.When,
+		FilesChanged: len(changes),
+	}, nil
+}
+
+// countCommitsFrom counts the commits reachable from tip that are not in stop.
+func countCommitsFrom(tip *object.Commit, stop map[plumbing.Hash]bool) (int, error) {
+	seen := make(map[plumbing.Hash]bool)
+	if err := walkCommits(tip, seen, stop); err != nil {
+		return 0, err
+	}
+	return len(seen), nil
+}
+
+// walkCommits adds start and its ancestors to seen, not descending into commits in stop.
+func walkCommits(start *object.Commit, seen map[plumbing.Hash]bool, stop map[plumbing.Hash]bool) error {
+	pending := []*object.Commit{start}
+	for len(pending) > 0 {
+		commit := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[commit.Hash] || stop[commit.Hash] {
+			continue
+		}
+		seen[commit.Hash] = true
+		err := commit.Parents().ForEach(func(parent *object.Commit) error {
+			pending = append(pending, parent)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to walk history from %s: %w", commit.Hash.String()[:7], err)
+		}
+	}
+	return nil
+}
+
+// peelToCommit follows annotated tags until it reaches a commit hash.
+func peelToCommit(repo *git.Repository, hash plumbing.Hash) (plumbing.Hash, error) {
+	for {
+		tag, err := repo.TagObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			break
+		}
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		hash = tag.Target
+	}
+	if _, err := repo.CommitObject(hash); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%s is not a commit: %w", hash.String()[:7], err)
+	}
+	return hash, nil
+}
+
 // DeleteBranch deletes a local branch reference from the repository.
 // It does not modify the working tree and will fail if the reference cannot be removed.
 func (g *GitService) DeleteBranch(repo *git.Repository, branch string) error {
@@ -655,6 +1988,126 @@ func (g *GitService) DeleteBranch(repo *git.Repository, branch string) error {
 	return repo.Storer.RemoveReference(refName)
 }
 
+// RenameBranch renames a local branch: the new ref is created at the old
+// ref's commit and the old ref is removed. HEAD and the branch's upstream
+// configuration follow the rename. It fails when newName already exists.
+func (g *GitService) RenameBranch(repo *git.Repository, oldName, newName string) error {
+	if repo == nil {
+		return fmt.Errorf("repo cannot be nil")
+	}
+	from := strings.TrimSpace(oldName)
+	to := strings.TrimSpace(newName)
+	if from == "" || to == "" {
+		return fmt.Errorf("branch name is required")
+	}
+	if from == to {
+		return nil
+	}
+	fromRef := plumbing.NewBranchReferenceName(from)
+	toRef := plumbing.NewBranchReferenceName(to)
+	if err := toRef.Validate(); err != nil {
+		return fmt.Errorf("invalid branch name '%s': %w", to, err)
+	}
+
+	ref, err := repo.Reference(fromRef, true)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return fmt.Errorf("branch '%s' does not exist", from)
+		}
+		return fmt.Errorf("failed to resolve branch '%s': %w", from, err)
+	}
+	exists, err := g.BranchExists(repo, to)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("branch '%s' already exists", to)
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(toRef, ref.Hash())); err != nil {
+		return fmt.Errorf("failed to create branch '%s': %w", to, err)
+	}
+	if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil && head.Type() == plumbing.SymbolicReference && head.Target() == fromRef {
+		if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, toRef)); err != nil {
+			_ = repo.Storer.RemoveReference(toRef)
+			return fmt.Errorf("failed to move HEAD to '%s': %w", to, err)
+		}
+	}
+	if err := repo.Storer.RemoveReference(fromRef); err != nil {
+		return fmt.Errorf("failed to remove branch '%s': %w", from, err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository config: %w", err)
+	}
+	if branchCfg, ok := cfg.Branches[from]; ok {
+		delete(cfg.Branches, from)
+		branchCfg.Name = to
+		cfg.Branches[to] = branchCfg
+		if err := repo.Storer.SetConfig(cfg); err != nil {
+			return fmt.Errorf("failed to update branch config: %w", err)
+		}
+	}
+	return nil
+}
+
+// HasUnpushedCommits reports whether branch has commits its remote-tracking
+// branch lacks. The branch's configured upstream takes precedence over remote,
+// and an empty remote means "origin". It is false when the remote or the
+// tracking branch does not exist.
+func (g *GitService) HasUnpushedCommits(repo *git.Repository, branch, remote string) (bool, error) {
+	if repo == nil {
+		return false, fmt.Errorf("repo cannot be nil")
+	}
+	name := strings.TrimSpace(branch)
+	if name == "" {
+		return false, fmt.Errorf("branch name is required")
+	}
+	remoteName := strings.TrimSpace(remote)
+	if remoteName == "" {
+		remoteName = git.DefaultRemoteName
+	}
+	trackedBranch := name
+	if cfg, err := repo.Branch(name); err == nil && cfg.Remote != "" && cfg.Merge != "" {
+		remoteName = cfg.Remote
+		trackedBranch = cfg.Merge.Short()
+	}
+
+	if _, err := repo.Remote(remoteName); err != nil {
+		if errors.Is(err, git.ErrRemoteNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load remote '%s': %w", remoteName, err)
+	}
+	trackingRef := plumbing.NewRemoteReferenceName(remoteName, trackedBranch)
+	if _, err := repo.Reference(trackingRef, true); err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to resolve '%s': %w", trackingRef.Short(), err)
+	}
+
+	result, err := g.AheadBehind(repo, trackingRef.Short(), name)
+	if err != nil {
+		return false, err
+	}
+	return result.Ahead > 0, nil
+}
+
+// HasUnpushedCommitsByPath opens the repository at repoPath and calls HasUnpushedCommits.
+func (g *GitService) HasUnpushedCommitsByPath(repoPath, branch, remote string) (bool, error) {
+	clean := strings.TrimSpace(repoPath)
+	if clean == "" {
+		return false, fmt.Errorf("repository path cannot be empty")
+	}
+	repo, err := g.Open(clean)
+	if err != nil {
+		return false, err
+	}
+	return g.HasUnpushedCommits(repo, branch, remote)
+}
+
 // DeleteBranchByPath opens the repository at the given path (resolving nested directories)
 // and deletes the specified local branch reference.
 func (g *GitService) DeleteBranchByPath(repoPath string, branch string) error {
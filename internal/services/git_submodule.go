@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"narrabyte/internal/utils"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// submodulePaths reads the paths of the submodules declared in the
+// .gitmodules file of the repository at root, slash-separated and sorted.
+// A repository without the file has none.
+func submodulePaths(root string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, ".gitmodules"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+	modules := gitconfig.NewModules()
+	if err := modules.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("failed to parse .gitmodules: %w", err)
+	}
+	paths := make([]string, 0, len(modules.Submodules))
+	for _, sm := range modules.Submodules {
+		if p := strings.Trim(filepath.ToSlash(strings.TrimSpace(sm.Path)), "/"); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// checkDocSubmoduleInitialized fails when absDoc lies inside a submodule of
+// the repository at root. An initialized submodule is a repository root of
+// its own, so reaching the superproject means the submodule was never checked
+// out and its directory is empty.
+func checkDocSubmoduleInitialized(root, absDoc string) error {
+	paths, err := submodulePaths(root)
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+	rel, err := filepath.Rel(root, absDoc)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	for _, p := range paths {
+		if rel == p || strings.HasPrefix(rel, p+"/") {
+			return fmt.Errorf("documentation path %s is inside the submodule '%s', which is not initialized; run 'git submodule update --init %s' first", absDoc, p, p)
+		}
+	}
+	return nil
+}
+
+// docSuperproject returns the root of the superproject the repository at root
+// is a submodule of, and the submodule's path within it. Both are empty for a
+// repository that is not a submodule, including linked worktrees, whose .git
+// is a file as well.
+func docSuperproject(root string) (string, string) {
+	if !utils.IsGitDirFile(filepath.Join(root, ".git")) {
+		return "", ""
+	}
+	super, ok := utils.FindGitRepoRoot(filepath.Dir(root))
+	if !ok {
+		return "", ""
+	}
+	rel, err := filepath.Rel(super, root)
+	if err != nil {
+		return "", ""
+	}
+	rel = filepath.ToSlash(rel)
+	paths, err := submodulePaths(super)
+	if err != nil || !slices.Contains(paths, rel) {
+		return "", ""
+	}
+	return super, rel
+}
+
+// UpdateSubmodulePointer points the submodule at path, slash-separated and
+// relative to the root of repo, at commit and commits that on the current
+// branch of repo. Changes already staged in repo are not swept into the
+// commit: the update is refused while there are any. A submodule already at
+// commit returns plumbing.ZeroHash without committing.
+func (g *GitService) UpdateSubmodulePointer(repo *git.Repository, path, commit string) (plumbing.Hash, error) {
+	if repo == nil {
+		return plumbing.ZeroHash, fmt.Errorf("repo cannot be nil")
+	}
+	path = strings.Trim(filepath.ToSlash(strings.TrimSpace(path)), "/")
+	if path == "" {
+		return plumbing.ZeroHash, fmt.Errorf("submodule path is required")
+	}
+	commit = strings.TrimSpace(commit)
+	if !plumbing.IsHash(commit) {
+		return plumbing.ZeroHash, fmt.Errorf("'%s' is not a full commit hash", commit)
+	}
+	target := plumbing.NewHash(commit)
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read status: %w", err)
+	}
+	for file, st := range status {
+		if filepath.ToSlash(file) == path {
+			continue
+		}
+		if st.Staging != git.Unmodified && st.Staging != git.Untracked {
+			return plumbing.ZeroHash, fmt.Errorf("'%s' has staged changes; commit or unstage them before updating submodule '%s'", file, path)
+		}
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read index: %w", err)
+	}
+	entry, err := idx.Entry(path)
+	switch {
+	case errors.Is(err, index.ErrEntryNotFound):
+		entry = idx.Add(path)
+	case err != nil:
+		return plumbing.ZeroHash, fmt.Errorf("failed to read index entry for '%s': %w", path, err)
+	case entry.Mode != filemode.Submodule:
+		return plumbing.ZeroHash, fmt.Errorf("'%s' is not a submodule", path)
+	case entry.Hash == target:
+		return plumbing.ZeroHash, nil
+	}
+	previous := *entry
+	entry.Hash = target
+	entry.Mode = filemode.Submodule
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write index: %w", err)
+	}
+
+	hash, err := g.Commit(repo, fmt.Sprintf("Update %s submodule to %s", path, target.String()[:8]))
+	if err != nil {
+		// Put the index entry back so the failed update leaves nothing staged
+		if previous.Hash.IsZero() {
+			_, _ = idx.Remove(path)
+		} else {
+			*entry = previous
+		}
+		_ = repo.Storer.SetIndex(idx)
+		return plumbing.ZeroHash, err
+	}
+	return hash, nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// CompareWorktreeToBranch returns the patch from branch to the files on disk
+// under pathPrefix, a directory relative to the repository root; an empty or
+// "." prefix covers the whole repository. Staged and unstaged edits are both
+// included, since only the file contents matter, as are untracked files that
+// are not ignored. Tracked files missing from disk show as deleted. The
+// comparison is built in memory and writes nothing to the repository.
+func (g *GitService) CompareWorktreeToBranch(repo *git.Repository, branch, pathPrefix string) (string, error) {
+	if repo == nil {
+		return "", fmt.Errorf("repo cannot be nil")
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return "", fmt.Errorf("branch name is required")
+	}
+	prefix := path.Clean(normalizePathSlashes(pathPrefix))
+	if prefix == "." || prefix == "/" {
+		prefix = ""
+	}
+	if prefix == ".." || strings.HasPrefix(prefix, "../") || strings.HasPrefix(prefix, "/") {
+		return "", fmt.Errorf("path prefix '%s' is outside the repository", pathPrefix)
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return "", fmt.Errorf("branch '%s' not found", branch)
+		}
+		return "", fmt.Errorf("failed to resolve branch '%s': %w", branch, err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to load commit of branch '%s': %w", branch, err)
+	}
+
+	objects := &overlayObjects{EncodedObjectStorer: repo.Storer, mem: memory.NewStorage()}
+	branchTree, err := object.GetTree(objects, commit.TreeHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to load tree of branch '%s': %w", branch, err)
+	}
+	files, err := treeFiles(branchTree)
+	if err != nil {
+		return "", err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	paths, err := worktreePaths(repo, wt, prefix)
+	if err != nil {
+		return "", err
+	}
+	for name := range files {
+		if hasPathPrefix(name, prefix) {
+			delete(files, name)
+		}
+	}
+	root := wt.Filesystem.Root()
+	for _, rel := range paths {
+		entry, ok, err := worktreeEntry(objects, root, rel)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			files[rel] = entry
+		}
+	}
+
+	treeHash, err := writeTree(objects, files)
+	if err != nil {
+		return "", err
+	}
+	worktreeTree, err := object.GetTree(objects, treeHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to load worktree tree: %w", err)
+	}
+	patch, err := branchTree.Patch(worktreeTree)
+	if err != nil {
+		return "", fmt.Errorf("failed to get patch: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := patch.Encode(&buf); err != nil {
+		return "", fmt.Errorf("failed to encode patch: %w", err)
+	}
+	return filterUnifiedDiff(buf.String()), nil
+}
+
+// worktreePaths lists the files under prefix that make up the worktree: the
+// paths in the index and the untracked files that are not ignored.
+func worktreePaths(repo *git.Repository, wt *git.Worktree, prefix string) ([]string, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	var paths []string
+	for _, entry := range idx.Entries {
+		if hasPathPrefix(entry.Name, prefix) {
+			paths = append(paths, entry.Name)
+		}
+	}
+	untracked, err := untrackedFiles(wt, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return append(paths, untracked...), nil
+}
+
+// worktreeEntry stores the file at rel as a blob and returns its tree entry,
+// or false when the file is not on disk.
+func worktreeEntry(s storer.EncodedObjectStorer, root, rel string) (object.TreeEntry, bool, error) {
+	abs := filepath.Join(root, filepath.FromSlash(rel))
+	info, err := os.Lstat(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return object.TreeEntry{}, false, nil
+		}
+		return object.TreeEntry{}, false, fmt.Errorf("failed to stat '%s': %w", rel, err)
+	}
+	var (
+		content []byte
+		mode    = filemode.Regular
+	)
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(abs)
+		if err != nil {
+			return object.TreeEntry{}, false, fmt.Errorf("failed to read link '%s': %w", rel, err)
+		}
+		content, mode = []byte(filepath.ToSlash(target)), filemode.Symlink
+	case info.IsDir():
+		// A submodule or nested repository; its contents are not compared
+		return object.TreeEntry{}, false, nil
+	default:
+		if content, err = os.ReadFile(abs); err != nil {
+			return object.TreeEntry{}, false, fmt.Errorf("failed to read '%s': %w", rel, err)
+		}
+		if info.Mode()&0o111 != 0 {
+			mode = filemode.Executable
+		}
+	}
+	hash, err := writeBlob(s, content)
+	if err != nil {
+		return object.TreeEntry{}, false, err
+	}
+	return object.TreeEntry{Mode: mode, Hash: hash}, true, nil
+}
+
+// overlayObjects reads objects from the repository but keeps the ones written
+// through it in memory.
+type overlayObjects struct {
+	storer.EncodedObjectStorer
+	mem *memory.Storage
+}
+
+func (o *overlayObjects) NewEncodedObject() plumbing.EncodedObject {
+	return o.mem.NewEncodedObject()
+}
+
+func (o *overlayObjects) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	return o.mem.SetEncodedObject(obj)
+}
+
+func (o *overlayObjects) EncodedObject(t plumbing.ObjectType, hash plumbing.Hash) (plumbing.EncodedObject, error) {
+	if obj, err := o.mem.EncodedObject(t, hash); err == nil {
+		return obj, nil
+	}
+	return o.EncodedObjectStorer.EncodedObject(t, hash)
+}
+
+func (o *overlayObjects) HasEncodedObject(hash plumbing.Hash) error {
+	if err := o.mem.HasEncodedObject(hash); err == nil {
+		return nil
+	}
+	return o.EncodedObjectStorer.HasEncodedObject(hash)
+}
@@ -1,11 +1,15 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/zalando/go-keyring"
 )
@@ -19,6 +23,13 @@ func GetOS() string {
 type KeyringService struct {
 }
 
+// ApiKeyFingerprintResult identifies a stored key without exposing it
+type ApiKeyFingerprintResult struct {
+	Provider    string `json:"provider"`
+	Fingerprint string `json:"fingerprint"`
+	Length      int    `json:"length"`
+}
+
 func NewKeyringService() *KeyringService {
 	return &KeyringService{}
 }
@@ -39,6 +50,91 @@ func (s *KeyringService) StoreApiKey(provider string, apiKey []byte) error {
 	return s.addProvider(provider)
 }
 
+// Warning codes returned by SetApiKey when a key does not look like it belongs to the provider
+const (
+	ApiKeyWarningUnexpectedFormat = "WARN_API_KEY_UNEXPECTED_FORMAT"
+	ApiKeyWarningOtherProvider    = "WARN_API_KEY_OTHER_PROVIDER"
+	ApiKeyWarningWhitespace       = "WARN_API_KEY_WHITESPACE"
+)
+
+// apiKeyRule describes the loose shape of a provider's API keys. Rules are
+// heuristics only: a mismatch produces a warning, never a hard rejection.
+type apiKeyRule struct {
+	provider  string
+	prefixes  []string
+	minLength int
+}
+
+var apiKeyRules = []apiKeyRule{
+	{provider: "anthropic", prefixes: []string{"sk-ant-"}, minLength: 20},
+	{provider: "openai", prefixes: []string{"sk-"}, minLength: 20},
+	{provider: "gemini", prefixes: []string{"AIza"}, minLength: 30},
+}
+
+// ApiKeyValidationResult reports whether a key was stored and, if it looked
+// wrong for the provider, a warning code and message the UI can show
+type ApiKeyValidationResult struct {
+	Stored      bool   `json:"stored"`
+	WarningCode string `json:"warningCode,omitempty"`
+	Warning     string `json:"warning,omitempty"`
+}
+
+// SetApiKey validates apiKey against the provider's expected shape before
+// storing it. When the key looks wrong it is not stored and a warning is
+// returned instead, unless override is true, in which case it is stored anyway.
+func (s *KeyringService) SetApiKey(provider string, apiKey string, override bool) (*ApiKeyValidationResult, error) {
+	if provider == "" {
+		return nil, errors.New("provider is required")
+	}
+	if apiKey == "" {
+		return nil, errors.New("API key is empty")
+	}
+
+	result := &ApiKeyValidationResult{}
+	result.WarningCode, result.Warning = checkApiKeyFormat(provider, apiKey)
+	if result.WarningCode != "" && !override {
+		return result, nil
+	}
+
+	if err := s.StoreApiKey(provider, []byte(apiKey)); err != nil {
+		return nil, err
+	}
+	result.Stored = true
+	return result, nil
+}
+
+// checkApiKeyFormat returns a warning code and message when apiKey does not
+// match the provider's rule, or empty strings when it looks fine or the
+// provider has no rule.
+func checkApiKeyFormat(provider string, apiKey string) (string, string) {
+	if strings.TrimSpace(apiKey) != apiKey || strings.ContainsAny(apiKey, " \t\r\n") {
+		return ApiKeyWarningWhitespace, "The key contains whitespace; check that it was pasted correctly"
+	}
+
+	// The most specific prefix wins so sk-ant- keys are attributed to Anthropic rather than OpenAI
+	detected, detectedLen := "", 0
+	for _, rule := range apiKeyRules {
+		for _, prefix := range rule.prefixes {
+			if strings.HasPrefix(apiKey, prefix) && len(prefix) > detectedLen {
+				detected, detectedLen = rule.provider, len(prefix)
+			}
+		}
+	}
+	if detected != "" && detected != provider {
+		return ApiKeyWarningOtherProvider, "This looks like a " + detected + " key, not a " + provider + " key"
+	}
+
+	for _, rule := range apiKeyRules {
+		if rule.provider != provider {
+			continue
+		}
+		if detected == "" || len(apiKey) < rule.minLength {
+			return ApiKeyWarningUnexpectedFormat, "This key does not match the usual format for " + provider + " (expected it to start with " + strings.Join(rule.prefixes, " or ") + ")"
+		}
+	}
+	return "", ""
+}
+
 func (s *KeyringService) GetApiKey(provider string) (string, error) {
 	if provider == "" {
 		return "", errors.New("provider is required")
@@ -46,19 +142,134 @@ func (s *KeyringService) GetApiKey(provider string) (string, error) {
 	return keyring.Get(serviceName, provider)
 }
 
+// DeleteApiKey removes the stored key for provider. Deleting a key that is
+// already absent is not an error.
 func (s *KeyringService) DeleteApiKey(provider string) error {
 	if provider == "" {
 		return errors.New("provider is required")
 	}
 
 	err := keyring.Delete(serviceName, provider)
-	if err != nil {
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
 		return err
 	}
 
 	return s.removeProvider(provider)
 }
 
+// ApiKeyFingerprint returns the first 8 hex characters of the SHA-256 of the
+// stored key along with its length, so the UI can show that a key is configured
+// and notice when it changes. The plaintext key is never returned.
+func (s *KeyringService) ApiKeyFingerprint(provider string) (*ApiKeyFingerprintResult, error) {
+	if provider == "" {
+		return nil, errors.New("provider is required")
+	}
+	key, err := keyring.Get(serviceName, provider)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiKeyFingerprintResult{
+		Provider:    provider,
+		Fingerprint: apiKeyFingerprint(key),
+		Length:      len(key),
+	}, nil
+}
+
+func apiKeyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// HasApiKey reports whether a key is currently stored for provider.
+func (s *KeyringService) HasApiKey(provider string) bool {
+	if provider == "" {
+		return false
+	}
+	_, err := keyring.Get(serviceName, provider)
+	return err == nil
+}
+
+// ListStoredProviders returns the provider IDs that currently have a key in the keyring.
+func (s *KeyringService) ListStoredProviders() []string {
+	providers, err := s.loadProviders()
+	if err != nil {
+		return []string{}
+	}
+
+	stored := []string{}
+	for _, provider := range providers {
+		if isProjectKeyID(provider) {
+			continue
+		}
+		if s.HasApiKey(provider) {
+			stored = append(stored, provider)
+		}
+	}
+	return stored
+}
+
+// projectKeyID returns the keyring account used for a project-scoped key, e.g. anthropic:project:3
+func projectKeyID(provider string, projectID uint) string {
+	return provider + ":project:" + strconv.FormatUint(uint64(projectID), 10)
+}
+
+func isProjectKeyID(id string) bool {
+	return strings.Contains(id, ":project:")
+}
+
+// SetProjectApiKey stores a key that only applies to the given project and
+// takes precedence over the global key for that provider.
+func (s *KeyringService) SetProjectApiKey(provider string, projectID uint, apiKey []byte) error {
+	if projectID == 0 {
+		return errors.New("project id is required")
+	}
+	if provider == "" {
+		return errors.New("provider is required")
+	}
+	return s.StoreApiKey(projectKeyID(provider, projectID), apiKey)
+}
+
+// GetProjectApiKey returns the project-scoped key without falling back to the global one.
+func (s *KeyringService) GetProjectApiKey(provider string, projectID uint) (string, error) {
+	if projectID == 0 {
+		return "", errors.New("project id is required")
+	}
+	if provider == "" {
+		return "", errors.New("provider is required")
+	}
+	return keyring.Get(serviceName, projectKeyID(provider, projectID))
+}
+
+// DeleteProjectApiKey removes a project-scoped key; the global key is left untouched.
+func (s *KeyringService) DeleteProjectApiKey(provider string, projectID uint) error {
+	if projectID == 0 {
+		return errors.New("project id is required")
+	}
+	if provider == "" {
+		return errors.New("provider is required")
+	}
+	return s.DeleteApiKey(projectKeyID(provider, projectID))
+}
+
+// ResolveApiKey returns the key to use for provider within a project: the
+// project-scoped key when one is stored, otherwise the global key. A zero
+// projectID always resolves to the global key.
+func (s *KeyringService) ResolveApiKey(provider string, projectID uint) (string, error) {
+	if provider == "" {
+		return "", errors.New("provider is required")
+	}
+	if projectID != 0 {
+		key, err := keyring.Get(serviceName, projectKeyID(provider, projectID))
+		if err == nil && key != "" {
+			return key, nil
+		}
+		if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return "", err
+		}
+	}
+	return s.GetApiKey(provider)
+}
+
 func (s *KeyringService) ListApiKeys() ([]map[string]string, error) {
 	providers, err := s.loadProviders()
 	if err != nil {
@@ -67,6 +278,9 @@ func (s *KeyringService) ListApiKeys() ([]map[string]string, error) {
 
 	var results []map[string]string
 	for _, provider := range providers {
+		if isProjectKeyID(provider) {
+			continue
+		}
 		_, err := keyring.Get(serviceName, provider)
 		if err != nil {
 			continue
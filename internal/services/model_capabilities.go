@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+
+	"narrabyte/internal/models"
+)
+
+// providerCapabilities are the capabilities assumed for a provider's models
+// when the modelCapabilities of the models asset do not list the model.
+var providerCapabilities = map[string]models.ModelCapabilities{
+	"openai": {
+		ReasoningEffort:  true,
+		StructuredOutput: true,
+		Vision:           true,
+		MaxContextTokens: 400000,
+	},
+	"anthropic": {
+		Thinking:         true,
+		ReasoningEffort:  true,
+		Vision:           true,
+		MaxContextTokens: 200000,
+	},
+	"gemini": {
+		Thinking:         true,
+		ReasoningEffort:  true,
+		Vision:           true,
+		MaxContextTokens: 1048576,
+	},
+}
+
+// defaultModelCapabilities returns the capabilities assumed for a model of
+// providerID. Models behind an OpenAI-compatible endpoint, and providers
+// without an entry, are assumed to support none of the optional features.
+func defaultModelCapabilities(providerID, providerKind string) models.ModelCapabilities {
+	if providerKind == models.ProviderKindOpenAICompatible {
+		return models.ModelCapabilities{}
+	}
+	return providerCapabilities[providerID]
+}
+
+// DropUnsupportedOptions turns off the options of model and settings that
+// model's capabilities do not cover, so the provider does not reject them
+// mid-run, and returns a warning for each option that was set and dropped.
+// Thinking is always disabled explicitly for models without it, as some
+// clients otherwise derive it from the reasoning effort.
+func DropUnsupportedOptions(model *models.LLMModel, settings *models.AppSettings) []string {
+	if model == nil {
+		return nil
+	}
+	caps := model.Capabilities
+	var warnings []string
+	if !caps.Thinking {
+		if model.Thinking != nil && *model.Thinking {
+			warnings = append(warnings, fmt.Sprintf("%s does not support thinking; it is turned off", model.DisplayName))
+		}
+		off := false
+		model.Thinking = &off
+	}
+	if !caps.ReasoningEffort && model.ReasoningEffort != "" {
+		warnings = append(warnings, fmt.Sprintf("%s does not support a reasoning effort; ignoring %q", model.DisplayName, model.ReasoningEffort))
+		model.ReasoningEffort = ""
+	}
+	if settings == nil {
+		return warnings
+	}
+	if !caps.StructuredOutput && settings.StructuredSummary {
+		// Only OpenAI runs request a structured summary; the others ignore the setting
+		if model.ProviderID == "openai" && model.ProviderKind != models.ProviderKindOpenAICompatible {
+			warnings = append(warnings, fmt.Sprintf("%s does not support structured output; using a free-text summary", model.DisplayName))
+		}
+		settings.StructuredSummary = false
+	}
+	// The existing docs context may take at most about a quarter of the
+	// context window, at roughly four characters per token
+	if limit := caps.MaxContextTokens; limit > 0 && settings.ExistingDocsContextChars > limit {
+		warnings = append(warnings, fmt.Sprintf("%s has a %d token context; limiting existing docs context to %d characters", model.DisplayName, limit, limit))
+		settings.ExistingDocsContextChars = limit
+	}
+	return warnings
+}
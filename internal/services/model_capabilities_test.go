@@ -0,0 +1,32 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"narrabyte/internal/assets"
+)
+
+func TestModelCapabilitiesAssetOnlyOverridesCatalogModels(t *testing.T) {
+	var parsed rawModelFile
+	if err := json.Unmarshal(assets.ModelsData, &parsed); err != nil {
+		t.Fatalf("parse models asset: %v", err)
+	}
+	catalog := make(map[string]bool)
+	for _, provider := range parsed.Providers {
+		for _, mdl := range provider.Models {
+			catalog[strings.TrimSpace(mdl.Key)] = true
+		}
+	}
+	for key, caps := range parsed.ModelCapabilities {
+		if !catalog[key] {
+			t.Errorf("modelCapabilities lists %s, which is not in the catalog", key)
+			continue
+		}
+		providerID, _, _ := strings.Cut(key, ":")
+		if caps == providerCapabilities[providerID] {
+			t.Errorf("modelCapabilities entry for %s repeats the %s defaults", key, providerID)
+		}
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"narrabyte/internal/assets"
+	"narrabyte/internal/llm/client"
 	"narrabyte/internal/models"
 	"narrabyte/internal/repositories"
+	"net/http"
+	"net/url"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type ModelConfigService interface {
@@ -18,6 +22,13 @@ type ModelConfigService interface {
 	SetModelEnabled(modelKey string, enabled bool) (*models.LLMModel, error)
 	SetProviderEnabled(provider string, enabled bool) ([]models.LLMModel, error)
 	GetModel(modelKey string) (*models.LLMModel, error)
+	SeedDefaultModels(providerID string) ([]models.LLMModel, error)
+	SetModelPriority(modelKey string, priority int) (*models.LLMModel, error)
+	SetModelShowReasoning(modelKey string, show bool) (*models.LLMModel, error)
+	SetModelReasoningBudget(modelKey string, tokens int, action string) (*models.LLMModel, error)
+	SetModelsEnabled(modelKeys []string, enabled bool) ([]models.LLMModel, error)
+	RegisterCustomModel(providerID, displayName, apiName, baseURL string) (*models.LLMModel, error)
+	CheckEndpoint(baseURL string) error
 }
 
 type modelConfigService struct {
@@ -29,6 +40,16 @@ type modelConfigService struct {
 	providerNames map[string]string
 	models        map[string]*catalogModel
 	settings      map[string]bool
+	priorities    map[string]int
+	showReasoning map[string]bool
+	budgets       map[string]reasoningBudgetSetting
+	capabilities  map[string]models.ModelCapabilities
+}
+
+// reasoningBudgetSetting is the reasoning token budget configured for a model.
+type reasoningBudgetSetting struct {
+	tokens int
+	action string
 }
 
 type catalogModel struct {
@@ -41,10 +62,16 @@ type catalogModel struct {
 	ReasoningEffort string
 	Thinking        *bool
 	DefaultEnabled  bool
+	ProviderKind    string
+	BaseURL         string
+	Capabilities    models.ModelCapabilities
 }
 
 type rawModelFile struct {
 	Providers []rawProvider `json:"providers"`
+	// ModelCapabilities lists what each model supports, keyed by model key;
+	// models without an entry get their provider's defaults
+	ModelCapabilities map[string]models.ModelCapabilities `json:"modelCapabilities"`
 }
 
 type rawProvider struct {
@@ -60,6 +87,20 @@ type rawModel struct {
 	ReasoningEffort string `json:"reasoningEffort,omitempty"`
 	Thinking        *bool  `json:"thinking,omitempty"`
 	Enabled         *bool  `json:"enabled,omitempty"`
+	ProviderKind    string `json:"providerKind,omitempty"`
+	BaseURL         string `json:"baseUrl,omitempty"`
+	// Capabilities overrides the provider's default capabilities for this model
+	Capabilities *models.ModelCapabilities `json:"capabilities,omitempty"`
+}
+
+// defaultModelSeeds is the curated set of models SeedDefaultModels installs for
+// each provider, by key. Every seed must be a model of the bundled catalog,
+// which supplies its display name, API name and reasoning defaults; update it
+// alongside the models asset when recommendations change.
+var defaultModelSeeds = map[string][]string{
+	"openai":    {"openai:gpt-5.5", "openai:gpt-5.4-mini"},
+	"anthropic": {"anthropic:claude-sonnet-5", "anthropic:claude-opus-4-8"},
+	"gemini":    {"gemini:gemini-3.1-pro-preview", "gemini:gemini-3.5-flash"},
 }
 
 type resolvedModelKey struct {
@@ -72,6 +113,10 @@ func NewModelConfigService(repo repositories.ModelSettingRepository) ModelConfig
 		repo:          repo,
 		models:        make(map[string]*catalogModel),
 		settings:      make(map[string]bool),
+		priorities:    make(map[string]int),
+		showReasoning: make(map[string]bool),
+		budgets:       make(map[string]reasoningBudgetSetting),
+		capabilities:  make(map[string]models.ModelCapabilities),
 		providerNames: make(map[string]string),
 		mu:            sync.RWMutex{},
 	}
@@ -88,6 +133,9 @@ func (s *modelConfigService) Startup(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	for key, caps := range parsed.ModelCapabilities {
+		s.capabilities[strings.TrimSpace(key)] = caps
+	}
 	s.providerOrder = make([]string, 0, len(parsed.Providers))
 	for _, provider := range parsed.Providers {
 		providerID := strings.TrimSpace(provider.ID)
@@ -98,24 +146,7 @@ func (s *modelConfigService) Startup(ctx context.Context) error {
 		s.providerNames[providerID] = providerName
 		s.providerOrder = append(s.providerOrder, providerID)
 		for _, mdl := range provider.Models {
-			key := strings.TrimSpace(mdl.Key)
-			if key == "" {
-				continue
-			}
-			defaultEnabled := true
-			if mdl.Enabled != nil {
-				defaultEnabled = *mdl.Enabled
-			}
-			s.models[key] = &catalogModel{
-				Key:             key,
-				ProviderID:      providerID,
-				Provider:        providerName,
-				DisplayName:     strings.TrimSpace(mdl.DisplayName),
-				APIName:         strings.TrimSpace(mdl.APIName),
-				ReasoningEffort: strings.TrimSpace(mdl.ReasoningEffort),
-				Thinking:        mdl.Thinking,
-				DefaultEnabled:  defaultEnabled,
-			}
+			s.registerModelLocked(providerID, mdl)
 		}
 	}
 
@@ -126,6 +157,28 @@ func (s *modelConfigService) Startup(ctx context.Context) error {
 	}
 	for _, setting := range existing {
 		s.settings[setting.ModelKey] = setting.Enabled
+		s.priorities[setting.ModelKey] = setting.Priority
+		if setting.ShowReasoning != nil {
+			s.showReasoning[setting.ModelKey] = *setting.ShowReasoning
+		}
+		if setting.ReasoningTokenBudget > 0 {
+			s.budgets[setting.ModelKey] = reasoningBudgetSetting{
+				tokens: setting.ReasoningTokenBudget,
+				action: setting.ReasoningBudgetAction,
+			}
+		}
+		// Restore models added at runtime that the bundled catalog does not know about
+		if _, ok := s.models[setting.ModelKey]; !ok && strings.TrimSpace(setting.APIName) != "" {
+			s.registerModelLocked(setting.Provider, rawModel{
+				Key:             setting.ModelKey,
+				DisplayName:     setting.DisplayName,
+				APIName:         setting.APIName,
+				ReasoningEffort: setting.ReasoningEffort,
+				Thinking:        setting.Thinking,
+				ProviderKind:    setting.ProviderKind,
+				BaseURL:         setting.BaseURL,
+			})
+		}
 	}
 	for key, def := range s.models {
 		if _, ok := s.settings[key]; !ok {
@@ -156,9 +209,7 @@ func (s *modelConfigService) ListModelGroups() ([]models.LLMModelGroup, error) {
 			}
 			modelsForProvider = append(modelsForProvider, s.toLLMModel(mdl))
 		}
-		sort.SliceStable(modelsForProvider, func(i, j int) bool {
-			return strings.ToLower(modelsForProvider[i].DisplayName) < strings.ToLower(modelsForProvider[j].DisplayName)
-		})
+		sortLLMModels(modelsForProvider)
 		group.Models = modelsForProvider
 		groups = append(groups, group)
 	}
@@ -208,9 +259,133 @@ func (s *modelConfigService) SetProviderEnabled(provider string, enabled bool) (
 		s.settings[mdl.Key] = enabled
 		updated = append(updated, s.toLLMModel(mdl))
 	}
-	sort.SliceStable(updated, func(i, j int) bool {
-		return strings.ToLower(updated[i].DisplayName) < strings.ToLower(updated[j].DisplayName)
-	})
+	sortLLMModels(updated)
+	return updated, nil
+}
+
+// SetModelPriority sets how strongly a model is preferred within its provider.
+// Higher priorities sort first and are picked as the provider's default model.
+func (s *modelConfigService) SetModelPriority(modelKey string, priority int) (*models.LLMModel, error) {
+	modelKey = strings.TrimSpace(modelKey)
+	if modelKey == "" {
+		return nil, fmt.Errorf("model key is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	catalog, ok := s.models[modelKey]
+	if !ok {
+		return nil, fmt.Errorf("model %s not found", modelKey)
+	}
+
+	if _, err := s.repo.SetPriority(modelKey, catalog.ProviderID, priority); err != nil {
+		return nil, err
+	}
+	s.priorities[modelKey] = priority
+	model := s.toLLMModel(catalog)
+	return &model, nil
+}
+
+// SetModelShowReasoning controls whether a model's reasoning content is streamed
+// to the UI. See LLMClient.showReasoning for how this maps onto each provider.
+func (s *modelConfigService) SetModelShowReasoning(modelKey string, show bool) (*models.LLMModel, error) {
+	modelKey = strings.TrimSpace(modelKey)
+	if modelKey == "" {
+		return nil, fmt.Errorf("model key is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	catalog, ok := s.models[modelKey]
+	if !ok {
+		return nil, fmt.Errorf("model %s not found", modelKey)
+	}
+
+	if _, err := s.repo.SetShowReasoning(modelKey, catalog.ProviderID, show); err != nil {
+		return nil, err
+	}
+	s.showReasoning[modelKey] = show
+	model := s.toLLMModel(catalog)
+	return &model, nil
+}
+
+// SetModelReasoningBudget caps the reasoning tokens a single run of the model
+// may spend. action is what happens once the cap is crossed and defaults to
+// "warn"; a budget of 0 removes the cap.
+func (s *modelConfigService) SetModelReasoningBudget(modelKey string, tokens int, action string) (*models.LLMModel, error) {
+	modelKey = strings.TrimSpace(modelKey)
+	if modelKey == "" {
+		return nil, fmt.Errorf("model key is required")
+	}
+	if tokens < 0 {
+		return nil, fmt.Errorf("reasoning budget must be 0 or greater")
+	}
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action == "" {
+		action = client.ReasoningBudgetWarn
+	}
+	if !client.IsReasoningBudgetAction(action) {
+		return nil, fmt.Errorf("unknown reasoning budget action %q", action)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	catalog, ok := s.models[modelKey]
+	if !ok {
+		return nil, fmt.Errorf("model %s not found", modelKey)
+	}
+
+	if _, err := s.repo.SetReasoningBudget(modelKey, catalog.ProviderID, tokens, action); err != nil {
+		return nil, err
+	}
+	if tokens == 0 {
+		delete(s.budgets, modelKey)
+	} else {
+		s.budgets[modelKey] = reasoningBudgetSetting{tokens: tokens, action: action}
+	}
+	model := s.toLLMModel(catalog)
+	return &model, nil
+}
+
+// SetModelsEnabled enables or disables several models at once. All keys are
+// validated before anything is persisted.
+func (s *modelConfigService) SetModelsEnabled(modelKeys []string, enabled bool) ([]models.LLMModel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(modelKeys))
+	seen := make(map[string]struct{}, len(modelKeys))
+	for _, key := range modelKeys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if _, ok := s.models[key]; !ok {
+			return nil, fmt.Errorf("model %s not found", key)
+		}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one model key is required")
+	}
+
+	if err := s.repo.SetEnabledForKeys(keys, enabled); err != nil {
+		return nil, err
+	}
+
+	updated := make([]models.LLMModel, 0, len(keys))
+	for _, key := range keys {
+		s.settings[key] = enabled
+		updated = append(updated, s.toLLMModel(s.models[key]))
+	}
+	sortLLMModels(updated)
 	return updated, nil
 }
 
@@ -235,6 +410,182 @@ func (s *modelConfigService) GetModel(modelKey string) (*models.LLMModel, error)
 	return &model, nil
 }
 
+// SeedDefaultModels installs the curated default models for a provider by
+// enabling those that are not enabled yet, and returns the models it enabled.
+// Seeds missing from the bundled catalog are skipped, so a retired model is
+// never installed.
+func (s *modelConfigService) SeedDefaultModels(providerID string) ([]models.LLMModel, error) {
+	providerID = strings.TrimSpace(providerID)
+	if providerID == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	seeds, ok := defaultModelSeeds[providerID]
+	if !ok {
+		return nil, fmt.Errorf("no default models defined for provider %s", providerID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := make([]models.LLMModel, 0, len(seeds))
+	for _, key := range seeds {
+		catalog, ok := s.models[key]
+		if !ok || catalog.ProviderID != providerID || s.settings[key] {
+			continue
+		}
+		if _, err := s.repo.Upsert(key, providerID, true); err != nil {
+			return nil, fmt.Errorf("seed model %s: %w", key, err)
+		}
+		s.settings[key] = true
+		created = append(created, s.toLLMModel(catalog))
+	}
+	return created, nil
+}
+
+const endpointCheckTimeout = 10 * time.Second
+
+// RegisterCustomModel adds a model served by an OpenAI-compatible endpoint
+// (LiteLLM, vLLM, OpenRouter, ...). Its API key is looked up in the keyring
+// under providerID, like built-in providers.
+func (s *modelConfigService) RegisterCustomModel(providerID, displayName, apiName, baseURL string) (*models.LLMModel, error) {
+	providerID = strings.TrimSpace(providerID)
+	apiName = strings.TrimSpace(apiName)
+	displayName = strings.TrimSpace(displayName)
+	if providerID == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	if strings.Contains(providerID, ":") {
+		return nil, fmt.Errorf("provider must not contain ':'")
+	}
+	if apiName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+	normalizedURL, err := normalizeEndpointURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if displayName == "" {
+		displayName = apiName
+	}
+	key := providerID + ":" + apiName
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.models[key]; ok && existing.ProviderKind != models.ProviderKindOpenAICompatible {
+		return nil, fmt.Errorf("model %s already exists", key)
+	}
+	if _, err := s.repo.Save(&models.ModelSetting{
+		ModelKey:     key,
+		Provider:     providerID,
+		Enabled:      true,
+		Priority:     s.priorities[key],
+		DisplayName:  displayName,
+		APIName:      apiName,
+		ProviderKind: models.ProviderKindOpenAICompatible,
+		BaseURL:      normalizedURL,
+	}); err != nil {
+		return nil, fmt.Errorf("save custom model %s: %w", key, err)
+	}
+	mdl := s.registerModelLocked(providerID, rawModel{
+		Key:          key,
+		DisplayName:  displayName,
+		APIName:      apiName,
+		ProviderKind: models.ProviderKindOpenAICompatible,
+		BaseURL:      normalizedURL,
+	})
+	s.settings[key] = true
+	model := s.toLLMModel(mdl)
+	return &model, nil
+}
+
+// CheckEndpoint confirms an OpenAI-compatible base URL is reachable by requesting
+// its model list. Any HTTP response counts, since the request is unauthenticated.
+func (s *modelConfigService) CheckEndpoint(baseURL string) error {
+	normalizedURL, err := normalizeEndpointURL(baseURL)
+	if err != nil {
+		return err
+	}
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, endpointCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, normalizedURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("endpoint %s is not reachable: %w", normalizedURL, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// normalizeEndpointURL checks that raw is an absolute http(s) URL and strips any trailing slash.
+func normalizeEndpointURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("base URL is required")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("base URL is not a valid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("base URL must use http or https")
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("base URL must include a host")
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("base URL must not include a query or fragment")
+	}
+	return strings.TrimRight(parsed.String(), "/"), nil
+}
+
+// registerModelLocked adds a model definition to the in-memory catalog. Callers must hold s.mu.
+func (s *modelConfigService) registerModelLocked(providerID string, mdl rawModel) *catalogModel {
+	key := strings.TrimSpace(mdl.Key)
+	if key == "" {
+		return nil
+	}
+	if _, ok := s.providerNames[providerID]; !ok {
+		s.providerNames[providerID] = providerID
+		s.providerOrder = append(s.providerOrder, providerID)
+	}
+	defaultEnabled := true
+	if mdl.Enabled != nil {
+		defaultEnabled = *mdl.Enabled
+	}
+	providerKind := strings.TrimSpace(mdl.ProviderKind)
+	capabilities, ok := s.capabilities[key]
+	if !ok || providerKind == models.ProviderKindOpenAICompatible {
+		capabilities = defaultModelCapabilities(providerID, providerKind)
+	}
+	if mdl.Capabilities != nil {
+		capabilities = *mdl.Capabilities
+	}
+	catalog := &catalogModel{
+		Key:             key,
+		ProviderID:      providerID,
+		Provider:        s.providerNames[providerID],
+		DisplayName:     strings.TrimSpace(mdl.DisplayName),
+		APIName:         strings.TrimSpace(mdl.APIName),
+		ReasoningEffort: strings.TrimSpace(mdl.ReasoningEffort),
+		Thinking:        mdl.Thinking,
+		DefaultEnabled:  defaultEnabled,
+		ProviderKind:    providerKind,
+		BaseURL:         strings.TrimSpace(mdl.BaseURL),
+		Capabilities:    capabilities,
+	}
+	s.models[key] = catalog
+	return catalog
+}
+
 func (s *modelConfigService) providerName(providerID string) string {
 	if name, ok := s.providerNames[providerID]; ok && strings.TrimSpace(name) != "" {
 		return name
@@ -244,18 +595,37 @@ func (s *modelConfigService) providerName(providerID string) string {
 
 func (s *modelConfigService) toLLMModel(mdl *catalogModel) models.LLMModel {
 	enabled := s.settings[mdl.Key]
+	// Reasoning display is opt-in: showing it turns thinking on for "low" effort
+	showReasoning := s.showReasoning[mdl.Key]
 	return models.LLMModel{
-		Key:             mdl.Key,
-		DisplayName:     mdl.DisplayName,
-		APIName:         mdl.APIName,
-		ProviderID:      mdl.ProviderID,
-		ProviderName:    mdl.Provider,
-		ReasoningEffort: mdl.ReasoningEffort,
-		Thinking:        mdl.Thinking,
-		Enabled:         enabled,
+		Key:                   mdl.Key,
+		DisplayName:           mdl.DisplayName,
+		APIName:               mdl.APIName,
+		ProviderID:            mdl.ProviderID,
+		ProviderName:          mdl.Provider,
+		ReasoningEffort:       mdl.ReasoningEffort,
+		Thinking:              mdl.Thinking,
+		Enabled:               enabled,
+		Priority:              s.priorities[mdl.Key],
+		ProviderKind:          mdl.ProviderKind,
+		BaseURL:               mdl.BaseURL,
+		ShowReasoning:         showReasoning,
+		ReasoningTokenBudget:  s.budgets[mdl.Key].tokens,
+		ReasoningBudgetAction: s.budgets[mdl.Key].action,
+		Capabilities:          mdl.Capabilities,
 	}
 }
 
+// sortLLMModels orders models by descending priority, then by display name.
+func sortLLMModels(list []models.LLMModel) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Priority != list[j].Priority {
+			return list[i].Priority > list[j].Priority
+		}
+		return strings.ToLower(list[i].DisplayName) < strings.ToLower(list[j].DisplayName)
+	})
+}
+
 func resolveModelKey(modelKey string) resolvedModelKey {
 	trimmed := strings.TrimSpace(modelKey)
 	if trimmed == "" {
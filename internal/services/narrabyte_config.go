@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"narrabyte/internal/llm/client"
+	"narrabyte/internal/models"
+	"narrabyte/internal/utils"
+)
+
+// InitNarrabyteConfig scaffolds .narrabyte/llm_instructions.md in the
+// project's documentation repository from client.LLMInstructionsTemplate, so
+// new projects start with the instructions file runs load. An existing
+// instructions file, whatever its extension, is never overwritten, and a
+// repository that cannot be written to is reported rather than failing.
+func (s *ClientService) InitNarrabyteConfig(projectID uint) (*models.NarrabyteConfigInit, error) {
+	if projectID == 0 {
+		return nil, fmt.Errorf("project id is required")
+	}
+	project, err := s.repoLinks.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found")
+	}
+	docRepo := strings.TrimSpace(project.DocumentationRepo)
+	if docRepo == "" || !utils.DirectoryExists(docRepo) {
+		return nil, fmt.Errorf("documentation repository path does not exist: %s", docRepo)
+	}
+
+	dir := filepath.Join(docRepo, ".narrabyte")
+	file := filepath.Join(dir, llmInstructionsBaseName+".md")
+	existed, err := s.repoLinks.CheckLLMInstructions(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if existed {
+		return &models.NarrabyteConfigInit{Existed: true}, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		if isReadOnlyError(err) {
+			return &models.NarrabyteConfigInit{ReadOnly: true}, nil
+		}
+		return nil, fmt.Errorf("failed to create .narrabyte directory: %w", err)
+	}
+	// O_EXCL keeps a file created since the check above
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		switch {
+		case errors.Is(err, fs.ErrExist):
+			return &models.NarrabyteConfigInit{Existed: true}, nil
+		case isReadOnlyError(err):
+			return &models.NarrabyteConfigInit{ReadOnly: true}, nil
+		}
+		return nil, fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	_, writeErr := f.WriteString(client.LLMInstructionsTemplate())
+	if closeErr := f.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		_ = os.Remove(file)
+		return nil, fmt.Errorf("failed to write %s: %w", file, writeErr)
+	}
+	return &models.NarrabyteConfigInit{Path: file, Created: true}, nil
+}
+
+// isReadOnlyError reports whether err means the file system refused a write,
+// either for lack of permission or because it is mounted read-only.
+func isReadOnlyError(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"narrabyte/internal/llm/client"
 	"narrabyte/internal/models"
 	"narrabyte/internal/repositories"
 	"narrabyte/internal/utils"
@@ -46,12 +47,20 @@ type RepoLinkService interface {
 	ImportLLMInstructions(id uint, llmInstructionsPath string) error
 	Delete(id uint) error
 	ValidateDirectory(path string) (*DirectoryValidationResult, error)
+	SetMaintainFumadocsMeta(id uint, enabled bool) error
+	SetAllowedMDXComponents(id uint, components []string) error
+	SetCodeScopePath(id uint, scope string) error
+	SetFilesystemTarget(id uint, enabled bool) error
+	SetDefaultModelKey(id uint, modelKey string) error
+	SetAllowedTools(id uint, toolNames []string) error
+	SetPromptSections(id uint, omitCodeListing, omitDocsListing, omitCommitContext bool, maxListingChars int) error
 }
 
 type repoLinkService struct {
 	repoLinks       repositories.RepoLinkRepository
 	fumadocsService FumadocsService
 	gitService      GitService
+	modelConfigs    ModelConfigService
 	context         context.Context
 }
 
@@ -59,8 +68,8 @@ func (s *repoLinkService) Startup(ctx context.Context) {
 	s.context = ctx
 }
 
-func NewRepoLinkService(repoLinks repositories.RepoLinkRepository, fumaDocsService FumadocsService, gitService GitService) RepoLinkService {
-	return &repoLinkService{repoLinks: repoLinks, fumadocsService: fumaDocsService, gitService: gitService}
+func NewRepoLinkService(repoLinks repositories.RepoLinkRepository, fumaDocsService FumadocsService, gitService GitService, modelConfigs ModelConfigService) RepoLinkService {
+	return &repoLinkService{repoLinks: repoLinks, fumadocsService: fumaDocsService, gitService: gitService, modelConfigs: modelConfigs}
 }
 
 func (s *repoLinkService) Register(projectName, documentationRepo, codebaseRepo, documentationBaseBranch string, initFumaDocs bool) (*models.RepoLink, error) {
@@ -270,7 +279,7 @@ func (s *repoLinkService) UpdateProjectPaths(id uint, docRepo, codebaseRepo stri
 	}
 
 	if docRepo != "" {
-		if !utils.HasGitRepo(docRepo) {
+		if !project.FilesystemTarget && !utils.HasGitRepo(docRepo) {
 			return errors.New("missing_git_repo: documentation")
 		}
 		if !utils.DirectoryExists(docRepo) {
@@ -289,6 +298,12 @@ func (s *repoLinkService) UpdateProjectPaths(id uint, docRepo, codebaseRepo stri
 		project.CodebaseRepo = codebaseRepo
 	}
 
+	if project.FilesystemTarget {
+		// Documentation is written straight to the directory; no base branch applies
+		project.DocumentationBaseBranch = ""
+		return s.repoLinks.Update(context.Background(), project)
+	}
+
 	sameRepo, err := sameGitRepository(project.DocumentationRepo, project.CodebaseRepo)
 	if err != nil {
 		return fmt.Errorf("failed to check git repositories: %w", err)
@@ -321,6 +336,167 @@ func (s *repoLinkService) ImportLLMInstructions(id uint, llmInstructionsPath str
 	return nil
 }
 
+// SetMaintainFumadocsMeta toggles meta.json maintenance after generation runs for a project
+func (s *repoLinkService) SetMaintainFumadocsMeta(id uint, enabled bool) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", id)
+	}
+	project.MaintainFumadocsMeta = enabled
+	return s.repoLinks.Update(context.Background(), project)
+}
+
+// SetAllowedMDXComponents stores the custom MDX components generated pages may use without importing
+func (s *repoLinkService) SetAllowedMDXComponents(id uint, components []string) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", id)
+	}
+	project.AllowedMDXComponents = strings.Join(ParseMDXComponentList(strings.Join(components, ",")), ",")
+	return s.repoLinks.Update(context.Background(), project)
+}
+
+// SetCodeScopePath restricts the code changes a project's runs look at to a
+// subtree of its codebase repository. An empty scope clears the restriction.
+func (s *repoLinkService) SetCodeScopePath(id uint, scope string) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", id)
+	}
+	codeRepoRoot := ""
+	if strings.TrimSpace(scope) != "" {
+		abs, err := filepath.Abs(strings.TrimSpace(project.CodebaseRepo))
+		if err != nil {
+			return fmt.Errorf("failed to resolve codebase path: %w", err)
+		}
+		root, ok := utils.FindGitRepoRoot(abs)
+		if !ok {
+			return fmt.Errorf("codebase repository is not a git repository: %s", project.CodebaseRepo)
+		}
+		codeRepoRoot = root
+	}
+	resolved, err := resolveCodeScope(codeRepoRoot, scope)
+	if err != nil {
+		return err
+	}
+	project.CodeScopePath = resolved
+	return s.repoLinks.Update(context.Background(), project)
+}
+
+// SetFilesystemTarget switches a project between committing generated
+// documentation to a docs branch and writing it straight into its
+// documentation directory. Turning it off requires the directory to be in a
+// git repository again.
+func (s *repoLinkService) SetFilesystemTarget(id uint, enabled bool) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", id)
+	}
+	if !enabled && !utils.HasGitRepo(project.DocumentationRepo) {
+		return errors.New("missing_git_repo: documentation")
+	}
+	project.FilesystemTarget = enabled
+	return s.repoLinks.Update(context.Background(), project)
+}
+
+// SetDefaultModelKey sets the model generation runs for the project use when
+// none is given. An empty key clears it; any other key must name an enabled
+// model.
+func (s *repoLinkService) SetDefaultModelKey(id uint, modelKey string) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", id)
+	}
+	modelKey = strings.TrimSpace(modelKey)
+	if modelKey != "" {
+		if s.modelConfigs == nil {
+			return fmt.Errorf("model config service not initialized")
+		}
+		model, err := s.modelConfigs.GetModel(modelKey)
+		if err != nil || model == nil {
+			return fmt.Errorf("model %s is not available", modelKey)
+		}
+		if !model.Enabled {
+			return fmt.Errorf("model %s is disabled", modelKey)
+		}
+	}
+	project.DefaultModelKey = modelKey
+	return s.repoLinks.Update(context.Background(), project)
+}
+
+// SetAllowedTools limits the documentation tools the project's runs may use
+// to toolNames. An empty list clears the allowlist and enables every tool;
+// once set, delete_file_tool is only available when listed.
+func (s *repoLinkService) SetAllowedTools(id uint, toolNames []string) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", id)
+	}
+	allowed := ParseToolAllowlist(strings.Join(toolNames, ","))
+	for _, name := range allowed {
+		if !client.IsDocumentationTool(name) {
+			return fmt.Errorf("unknown tool: %s", name)
+		}
+	}
+	project.AllowedTools = strings.Join(allowed, ",")
+	return s.repoLinks.Update(context.Background(), project)
+}
+
+// SetPromptSections chooses which optional sections the project's generation
+// prompts include and bounds their directory listings to maxListingChars
+// characters each; 0 means no limit.
+func (s *repoLinkService) SetPromptSections(id uint, omitCodeListing, omitDocsListing, omitCommitContext bool, maxListingChars int) error {
+	if maxListingChars < 0 {
+		return fmt.Errorf("max listing size cannot be negative")
+	}
+	project, err := s.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", id)
+	}
+	project.PromptOmitCodeListing = omitCodeListing
+	project.PromptOmitDocsListing = omitDocsListing
+	project.PromptOmitCommitContext = omitCommitContext
+	project.PromptMaxListingChars = maxListingChars
+	return s.repoLinks.Update(context.Background(), project)
+}
+
+// ParseToolAllowlist splits a stored tool allowlist into names, dropping
+// blanks and duplicates. An empty value yields nil, meaning every tool.
+func ParseToolAllowlist(value string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(value, ",") {
+		name := strings.TrimSpace(field)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
 // Delete deletes a project by ID
 func (s *repoLinkService) Delete(id uint) error {
 	return s.repoLinks.Delete(context.Background(), id)
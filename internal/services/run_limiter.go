@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// runLimits caps how many documentation runs stream from a model at once, in
+// total and per provider; zero leaves a cap off. Runs over a cap wait for a
+// slot unless reject is set, in which case they fail with ERR_TOO_MANY_RUNS.
+type runLimits struct {
+	total       int
+	perProvider int
+	reject      bool
+}
+
+// runLimiter counts the runs holding a slot. Only runs that are streaming
+// hold one, so paused or finished sessions do not count against the caps.
+type runLimiter struct {
+	mu         sync.Mutex
+	total      int
+	byProvider map[string]int
+	// released is closed and replaced each time a slot frees up, waking the
+	// runs waiting for one
+	released chan struct{}
+}
+
+// acquire takes a slot for a run against providerID, waiting while the caps
+// are reached until a slot frees up or ctx is done. queued is called once,
+// with the reason, when the run has to wait. The returned func releases the
+// slot and is safe to call more than once.
+func (l *runLimiter) acquire(ctx context.Context, providerID string, limits runLimits, queued func(reason string)) (func(), error) {
+	notified := false
+	for {
+		l.mu.Lock()
+		reason := l.fullLocked(providerID, limits)
+		if reason == "" {
+			if l.byProvider == nil {
+				l.byProvider = make(map[string]int)
+			}
+			l.total++
+			l.byProvider[providerID]++
+			l.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(func() { l.release(providerID) }) }, nil
+		}
+		if limits.reject {
+			l.mu.Unlock()
+			return nil, fmt.Errorf("ERR_TOO_MANY_RUNS:%s", reason)
+		}
+		if l.released == nil {
+			l.released = make(chan struct{})
+		}
+		wait := l.released
+		l.mu.Unlock()
+
+		if !notified && queued != nil {
+			queued(reason)
+			notified = true
+		}
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// fullLocked describes the cap a new run against providerID would exceed, or
+// returns an empty string when a slot is free.
+func (l *runLimiter) fullLocked(providerID string, limits runLimits) string {
+	if limits.total > 0 && l.total >= limits.total {
+		return fmt.Sprintf("%d documentation runs are already running (limit %d)", l.total, limits.total)
+	}
+	if running := l.byProvider[providerID]; limits.perProvider > 0 && running >= limits.perProvider {
+		return fmt.Sprintf("%d %s runs are already running (limit %d per provider)", running, providerID, limits.perProvider)
+	}
+	return ""
+}
+
+func (l *runLimiter) release(providerID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total--
+	if l.byProvider[providerID]--; l.byProvider[providerID] <= 0 {
+		delete(l.byProvider, providerID)
+	}
+	if l.released != nil {
+		close(l.released)
+		l.released = nil
+	}
+}
+
+// acquireRunSlot takes a run slot for runtime's provider under the configured
+// caps, emitting an event on the session when the run is queued. ctx should
+// be the run's stream context, so stopping a queued run stops its wait.
+// Callers take the slot before cloning a workspace, so a queued run holds none.
+func (s *ClientService) acquireRunSlot(ctx context.Context, sessionKey string, runtime *sessionRuntime) (func(), error) {
+	settings := s.workspaceSettings()
+	limits := runLimits{
+		total:       settings.MaxConcurrentRuns,
+		perProvider: settings.MaxRunsPerProvider,
+		reject:      settings.RejectExcessRuns,
+	}
+	return s.runs.acquire(ctx, runtime.providerID, limits, func(reason string) {
+		emitSessionInfo(ctx, sessionKey, fmt.Sprintf("Run queued: %s; it starts once one of them finishes", reason))
+	})
+}
+
+// dropRejectedSession deletes the session a new run created when err rejects
+// the run for want of a slot, so retrying it does not find its docs branch
+// taken by a session that never ran.
+func (s *ClientService) dropRejectedSession(sessionKey string, sessionID uint, err error) {
+	if err == nil || !strings.HasPrefix(err.Error(), "ERR_TOO_MANY_RUNS:") {
+		return
+	}
+	_ = s.generationSessions.DeleteByID(sessionID)
+	s.setSessionRuntime(sessionKey, nil)
+}
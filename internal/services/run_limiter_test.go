@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunLimiterRejectsRunsOverTheCap(t *testing.T) {
+	var limiter runLimiter
+	limits := runLimits{total: 2, reject: true}
+
+	first, err := limiter.acquire(context.Background(), "openai", limits, nil)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if _, err := limiter.acquire(context.Background(), "gemini", limits, nil); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if _, err := limiter.acquire(context.Background(), "anthropic", limits, nil); err == nil || !strings.HasPrefix(err.Error(), "ERR_TOO_MANY_RUNS:") {
+		t.Fatalf("expected ERR_TOO_MANY_RUNS, got %v", err)
+	}
+
+	first()
+	first()
+	if _, err := limiter.acquire(context.Background(), "anthropic", limits, nil); err != nil {
+		t.Fatalf("expected a free slot after a release, got %v", err)
+	}
+}
+
+func TestRunLimiterQueuesRunsUntilASlotFrees(t *testing.T) {
+	var limiter runLimiter
+	limits := runLimits{total: 3, perProvider: 1}
+
+	release, err := limiter.acquire(context.Background(), "openai", limits, nil)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if _, err := limiter.acquire(context.Background(), "gemini", limits, nil); err != nil {
+		t.Fatalf("another provider should not be capped: %v", err)
+	}
+
+	queued := make(chan string, 1)
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := limiter.acquire(context.Background(), "openai", limits, func(reason string) { queued <- reason })
+		acquired <- err
+	}()
+
+	select {
+	case reason := <-queued:
+		if !strings.Contains(reason, "openai") {
+			t.Fatalf("expected the provider cap in the reason, got %q", reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the run to be queued")
+	}
+	select {
+	case err := <-acquired:
+		t.Fatalf("expected the run to wait, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("queued run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the queued run to start after a release")
+	}
+}
+
+func TestRunLimiterStopsWaitingWhenCanceled(t *testing.T) {
+	var limiter runLimiter
+	limits := runLimits{total: 1}
+	if _, err := limiter.acquire(context.Background(), "openai", limits, nil); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.acquire(ctx, "openai", limits, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// Session statuses reported in SessionInfo.Status.
+const (
+	// SessionStatusRunning is a session whose model is generating or refining
+	SessionStatusRunning = "running"
+	// SessionStatusReady is a session whose docs branch is waiting to be merged
+	SessionStatusReady = "ready"
+	// SessionStatusMerged is a session whose docs branch has been merged
+	SessionStatusMerged = "merged"
+	// SessionStatusStale is a session whose docs branch is missing or can no
+	// longer be fast-forwarded into the branch it is merged into
+	SessionStatusStale = "stale"
+)
+
+// setSessionStatuses fills in the Status of a project's sessions. A docs
+// branch is merged into the source branch when the docs share the code
+// repository, as MergeDocsIntoSource does, and into the documentation base
+// branch otherwise. Documentation written straight to a directory has no
+// branch and is always ready.
+func (s *ClientService) setSessionStatuses(projectID uint, sessions []SessionInfo) {
+	var repo *git.Repository
+	project, _, docCfg, err := s.prepareProjectRepos(projectID)
+	if err == nil && !docCfg.Filesystem {
+		repo, err = s.gitService.Open(docCfg.RepoRoot)
+	}
+
+	for i := range sessions {
+		info := &sessions[i]
+		switch {
+		case info.IsRunning:
+			info.Status = SessionStatusRunning
+		case err != nil:
+			info.Status = SessionStatusStale
+		case docCfg.Filesystem:
+			info.Status = SessionStatusReady
+		default:
+			into := strings.TrimSpace(project.DocumentationBaseBranch)
+			if docCfg.SharedWithCode {
+				into = info.SourceBranch
+			}
+			docsBranch := info.DocsBranch
+			if docsBranch == "" {
+				docsBranch = documentationBranchName(info.SourceBranch)
+			}
+			info.Status = s.docsBranchStatus(repo, docsBranch, into)
+		}
+	}
+}
+
+// docsBranchStatus compares docsBranch with the branch it is merged into: it
+// is merged once into contains it, ready while it can still be fast-forwarded
+// into into, and stale when either branch is missing or into has diverged.
+func (s *ClientService) docsBranchStatus(repo *git.Repository, docsBranch, into string) string {
+	merged, err := s.gitService.IsBranchMerged(repo, docsBranch, into)
+	if err != nil {
+		return SessionStatusStale
+	}
+	if merged {
+		return SessionStatusMerged
+	}
+	fastForward, err := s.gitService.IsBranchMerged(repo, into, docsBranch)
+	if err != nil || !fastForward {
+		return SessionStatusStale
+	}
+	return SessionStatusReady
+}
@@ -1,13 +1,17 @@
 package integration_tests
 
 import (
+	"fmt"
+	"narrabyte/internal/models"
 	"narrabyte/internal/services"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
@@ -175,6 +179,683 @@ func TestDiffBetweenBranches_ReturnsErrorForMissingBranch(t *testing.T) {
 	assert.Contains(t, err.Error(), "branch 'missing-branch' not found")
 }
 
+func TestResolveRef_BranchTagRemoteAndRevision(t *testing.T) {
+	dir, err := os.MkdirTemp("", "gitservicetest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	gs := services.NewGitService()
+	repo, err := gs.Init(dir)
+	assert.NoError(t, err)
+
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	author := &object.Signature{Name: "Test", Email: "test@example.com"}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one"), 0644))
+	_, err = w.Add("a.txt")
+	assert.NoError(t, err)
+	first, err := w.Commit("first", &git.CommitOptions{Author: author})
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two"), 0644))
+	_, err = w.Add("a.txt")
+	assert.NoError(t, err)
+	second, err := w.Commit("second", &git.CommitOptions{Author: author})
+	assert.NoError(t, err)
+
+	_, err = repo.CreateTag("v1.0.0", first, &git.CreateTagOptions{Tagger: author, Message: "release"})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/main", first)))
+
+	cases := []struct {
+		ref   string
+		hash  plumbing.Hash
+		label string
+	}{
+		{"master", second, "branch 'master'"},
+		{"v1.0.0", first, "tag 'v1.0.0'"},
+		{"origin/main", first, "remote branch 'origin/main'"},
+		{first.String()[:7], first, "revision '" + first.String()[:7] + "'"},
+		{"HEAD~1", first, "revision 'HEAD~1'"},
+	}
+	for _, tc := range cases {
+		resolved, err := gs.ResolveRef(repo, tc.ref)
+		assert.NoError(t, err, tc.ref)
+		if assert.NotNil(t, resolved, tc.ref) {
+			assert.Equal(t, tc.hash, resolved.Hash, tc.ref)
+			assert.Equal(t, tc.label, resolved.Label, tc.ref)
+		}
+	}
+
+	_, err = gs.ResolveRef(repo, "missing")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tried branch, tag, remote branch and revision")
+}
+
+// newAheadBehindRepo creates a repository with one commit on master and
+// returns it with a helper that commits a change to the checked out branch.
+func newAheadBehindRepo(t *testing.T) (*git.Repository, func(name string) plumbing.Hash) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	commit := func(name string) plumbing.Hash {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name+".txt"), []byte(name), 0644))
+		_, err := w.Add(name + ".txt")
+		assert.NoError(t, err)
+		hash, err := w.Commit(name, &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+		})
+		assert.NoError(t, err)
+		return hash
+	}
+	commit("seed")
+	return repo, commit
+}
+
+func checkoutNewBranch(t *testing.T, repo *git.Repository, branch string) {
+	t.Helper()
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true}))
+}
+
+func TestAheadBehind_FastForwardable(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+	commit("docs-2")
+	commit("docs-3")
+
+	result, err := services.NewGitService().AheadBehind(repo, "master", "docs/master")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Ahead)
+	assert.Equal(t, 0, result.Behind)
+	master, err := repo.Reference(plumbing.NewBranchReferenceName("master"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, master.Hash().String(), result.MergeBase)
+}
+
+func TestAheadBehind_Diverged(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}))
+	commit("source-1")
+	commit("source-2")
+
+	result, err := services.NewGitService().AheadBehind(repo, "master", "docs/master")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Ahead)
+	assert.Equal(t, 2, result.Behind)
+}
+
+func TestAheadBehind_Identical(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+
+	result, err := services.NewGitService().AheadBehind(repo, "master", "docs/master")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Ahead)
+	assert.Equal(t, 0, result.Behind)
+	assert.NotEmpty(t, result.MergeBase)
+}
+
+func TestMergeBase_SharedHistory(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	forkPoint := commit("fork")
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+	checkoutBranch(t, repo, "master")
+	commit("source-1")
+	svc := services.NewGitService()
+
+	base, err := svc.MergeBase(repo, "master", "docs/master")
+	assert.NoError(t, err)
+	assert.Equal(t, forkPoint.String(), base)
+	base, err = svc.MergeBase(repo, "docs/master", "master")
+	assert.NoError(t, err)
+	assert.Equal(t, forkPoint.String(), base)
+}
+
+func TestMergeBase_UnrelatedHistories(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	// An orphan branch shares no commits with master
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("orphan"))))
+	commit("orphan-1")
+
+	_, err := services.NewGitService().MergeBase(repo, "master", "orphan")
+	assert.ErrorContains(t, err, "unrelated histories")
+}
+
+func TestIsBranchMerged_UnmergedAndFastForwarded(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	docsHead := commit("docs-1")
+	svc := services.NewGitService()
+
+	merged, err := svc.IsBranchMerged(repo, "docs/master", "master")
+	assert.NoError(t, err)
+	assert.False(t, merged)
+	// The other way round, master is contained in the docs branch
+	merged, err = svc.IsBranchMerged(repo, "master", "docs/master")
+	assert.NoError(t, err)
+	assert.True(t, merged)
+
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), docsHead)))
+	merged, err = svc.IsBranchMerged(repo, "docs/master", "master")
+	assert.NoError(t, err)
+	assert.True(t, merged)
+}
+
+func TestIsBranchMerged_DivergedAndMissingBranches(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+	checkoutBranch(t, repo, "master")
+	commit("source-1")
+	svc := services.NewGitService()
+
+	merged, err := svc.IsBranchMerged(repo, "docs/master", "master")
+	assert.NoError(t, err)
+	assert.False(t, merged)
+	merged, err = svc.IsBranchMerged(repo, "master", "docs/master")
+	assert.NoError(t, err)
+	assert.False(t, merged)
+
+	_, err = svc.IsBranchMerged(repo, "docs/missing", "master")
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+}
+
+func TestCreateBranch_CreatesAtStartCommit(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	first := commit("first")
+	commit("second")
+
+	assert.NoError(t, services.NewGitService().CreateBranch(repo, "docs/feature", first, false))
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, first, ref.Hash())
+}
+
+func TestCreateBranch_ExistingBranchIsAnError(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	first := commit("first")
+	head := commit("second")
+	svc := services.NewGitService()
+	assert.NoError(t, svc.CreateBranch(repo, "docs/feature", first, false))
+
+	err := svc.CreateBranch(repo, "docs/feature", head, false)
+	assert.ErrorContains(t, err, "already exists")
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, first, ref.Hash())
+}
+
+func TestCreateBranch_ForceMovesExistingBranch(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	first := commit("first")
+	head := commit("second")
+	svc := services.NewGitService()
+	assert.NoError(t, svc.CreateBranch(repo, "docs/feature", first, false))
+
+	assert.NoError(t, svc.CreateBranch(repo, "docs/feature", head, true))
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+
+	assert.ErrorContains(t, svc.CreateBranch(repo, "docs/other", plumbing.ZeroHash, true), "start commit is empty")
+}
+
+func TestEnsureBranch_CreatesFromRefAndKeepsExisting(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	first := commit("first")
+	head := commit("second")
+	svc := services.NewGitService()
+
+	created, err := svc.EnsureBranch(repo, "docs", "")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("docs"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+
+	created, err = svc.EnsureBranch(repo, "docs", first.String())
+	assert.NoError(t, err)
+	assert.False(t, created)
+	ref, err = repo.Reference(plumbing.NewBranchReferenceName("docs"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+
+	created, err = svc.EnsureBranch(repo, "docs-old", first.String())
+	assert.NoError(t, err)
+	assert.True(t, created)
+	ref, err = repo.Reference(plumbing.NewBranchReferenceName("docs-old"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, first, ref.Hash())
+
+	_, err = svc.EnsureBranch(repo, "docs-missing", "no-such-ref")
+	assert.Error(t, err)
+	exists, err := svc.BranchExists(repo, "docs-missing")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestEnsureBranch_FailsWithoutCommits(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	assert.NoError(t, err)
+
+	_, err = services.NewGitService().EnsureBranch(repo, "docs", "")
+	assert.ErrorContains(t, err, "no commits")
+}
+
+func TestRenameBranch_MovesRefHeadAndUpstream(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/feature")
+	head := commit("docs")
+	assert.NoError(t, repo.CreateBranch(&config.Branch{
+		Name:   "docs/feature",
+		Remote: "origin",
+		Merge:  plumbing.NewBranchReferenceName("docs/feature"),
+	}))
+	svc := services.NewGitService()
+
+	assert.ErrorContains(t, svc.RenameBranch(repo, "docs/feature", "master"), "already exists")
+	assert.ErrorContains(t, svc.RenameBranch(repo, "docs/missing", "docs/other"), "does not exist")
+
+	assert.NoError(t, svc.RenameBranch(repo, "docs/feature", "docs/pr-42"))
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("docs/pr-42"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+	exists, err := svc.BranchExists(repo, "docs/feature")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	headRef, err := repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, plumbing.NewBranchReferenceName("docs/pr-42"), headRef.Name())
+
+	cfg, err := repo.Config()
+	assert.NoError(t, err)
+	assert.NotContains(t, cfg.Branches, "docs/feature")
+	if assert.Contains(t, cfg.Branches, "docs/pr-42") {
+		assert.Equal(t, "origin", cfg.Branches["docs/pr-42"].Remote)
+	}
+}
+
+func TestStatusSummary_RootScopedDocs(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	dir := w.Filesystem.Root()
+	gs := services.NewGitService()
+
+	summary, err := gs.StatusSummary(repo, ".")
+	assert.NoError(t, err)
+	assert.True(t, summary.Clean)
+	assert.Empty(t, summary.Files)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "seed.txt"), []byte("changed"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "guides"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "guides", "intro.md"), []byte("# Intro"), 0644))
+
+	summary, err = gs.StatusSummary(repo, "")
+	assert.NoError(t, err)
+	assert.False(t, summary.Clean)
+	assert.Equal(t, []models.DocChangedFile{
+		{Path: "guides/intro.md", Status: "untracked"},
+		{Path: "seed.txt", Status: "modified"},
+	}, summary.Files)
+}
+
+func TestStatusSummary_SubdirectoryScopedDocs(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	dir := w.Filesystem.Root()
+	for _, sub := range []string{"docs/api", "docs-old"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "api", "index.md"), []byte("# API"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs-old", "index.md"), []byte("# Old"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "seed.txt"), []byte("changed"), 0644))
+	gs := services.NewGitService()
+	assert.NoError(t, gs.StageFiles(repo, []string{"docs/api/index.md"}))
+
+	summary, err := gs.StatusSummary(repo, "docs")
+	assert.NoError(t, err)
+	assert.False(t, summary.Clean)
+	assert.Equal(t, []models.DocChangedFile{{Path: "docs/api/index.md", Status: "added"}}, summary.Files)
+
+	summary, err = gs.StatusSummary(repo, "guides")
+	assert.NoError(t, err)
+	assert.True(t, summary.Clean)
+}
+
+func TestUnstage_ClearsIndexAndKeepsWorktree(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	dir := w.Filesystem.Root()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "seed.txt"), []byte("changed"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+
+	gs := services.NewGitService()
+	assert.NoError(t, gs.StageFiles(repo, []string{"seed.txt", "new.txt"}))
+	status, err := w.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, git.Modified, status.File("seed.txt").Staging)
+	assert.Equal(t, git.Added, status.File("new.txt").Staging)
+
+	assert.NoError(t, gs.Unstage(repo, []string{"seed.txt", "new.txt"}))
+	status, err = w.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, git.Unmodified, status.File("seed.txt").Staging)
+	assert.Equal(t, git.Modified, status.File("seed.txt").Worktree)
+	assert.Equal(t, git.Untracked, status.File("new.txt").Staging)
+	data, err := os.ReadFile(filepath.Join(dir, "seed.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", string(data))
+}
+
+func TestResetIndex_ClearsAllStagedEntries(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	dir := w.Filesystem.Root()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "seed.txt"), []byte("changed"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+
+	gs := services.NewGitService()
+	assert.NoError(t, gs.StageAll(repo))
+	assert.NoError(t, gs.ResetIndex(repo))
+
+	status, err := w.Status()
+	assert.NoError(t, err)
+	for path, st := range status {
+		assert.Contains(t, []git.StatusCode{git.Unmodified, git.Untracked}, st.Staging, path)
+	}
+	assert.Equal(t, git.Modified, status.File("seed.txt").Worktree)
+}
+
+func TestUnstage_WithoutCommits(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+
+	gs := services.NewGitService()
+	assert.NoError(t, gs.StageFiles(repo, []string{"new.txt"}))
+	assert.NoError(t, gs.Unstage(repo, []string{"new.txt"}))
+
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	status, err := w.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, git.Untracked, status.File("new.txt").Staging)
+}
+
+func TestCleanUntracked_RemovesOnlyUntrackedFilesUnderPrefix(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	root := w.Filesystem.Root()
+	write := func(name, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0644))
+	}
+	write(".gitignore", "*.tmp\n")
+	write("docs/guide.md", "guide")
+	_, err = w.Add(".")
+	assert.NoError(t, err)
+	_, err = w.Commit("docs", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+
+	write("docs/new.md", "new")
+	write("docs/sub/generated.md", "generated")
+	write("docs/cache.tmp", "cache")
+	write("notes.txt", "outside the prefix")
+
+	gs := services.NewGitService()
+	planned, err := gs.CleanUntracked(repo, "docs/", false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs/new.md", "docs/sub/generated.md"}, planned)
+	assert.FileExists(t, filepath.Join(root, "docs", "new.md"))
+
+	removed, err := gs.CleanUntracked(repo, "docs", false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, planned, removed)
+	assert.NoFileExists(t, filepath.Join(root, "docs", "new.md"))
+	assert.NoDirExists(t, filepath.Join(root, "docs", "sub"))
+	assert.FileExists(t, filepath.Join(root, "docs", "guide.md"))
+	assert.FileExists(t, filepath.Join(root, "docs", "cache.tmp"))
+	assert.FileExists(t, filepath.Join(root, "notes.txt"))
+
+	planned, err = gs.CleanUntracked(repo, "docs", true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs/cache.tmp"}, planned)
+
+	_, err = gs.CleanUntracked(repo, "../elsewhere", false, true)
+	assert.Error(t, err)
+}
+
+func TestDiffStat_CountsLinesAndSkipsExcludedFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	write := func(name, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	commitAll := func(message string) {
+		assert.NoError(t, w.AddWithOptions(&git.AddOptions{All: true}))
+		_, err := w.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+		})
+		assert.NoError(t, err)
+	}
+
+	write("docs/guide.md", "one\ntwo\nthree\n")
+	write("docs/old.md", "gone\n")
+	commitAll("seed")
+
+	checkoutNewBranch(t, repo, "docs/master")
+	write("docs/guide.md", "one\n2\nthree\nfour")
+	assert.NoError(t, os.Remove(filepath.Join(dir, "docs/old.md")))
+	write("docs/new.md", "a\nb\n")
+	write("package-lock.json", "{}\n")
+	commitAll("docs")
+
+	stats, err := services.NewGitService().DiffStat(repo, "master", "docs/master")
+	assert.NoError(t, err)
+
+	byPath := make(map[string]string)
+	for _, stat := range stats {
+		byPath[stat.Path] = fmt.Sprintf("%s +%d -%d", stat.Status, stat.Additions, stat.Deletions)
+	}
+	assert.Equal(t, map[string]string{
+		"docs/guide.md": "modified +2 -1",
+		"docs/old.md":   "deleted +0 -1",
+		"docs/new.md":   "added +2 -0",
+	}, byPath)
+}
+
+func TestBlame_AttributesLinesToCommits(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "feature")
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(w.Filesystem.Root(), "seed.txt"), []byte("intro\nseed"), 0644))
+	_, err = w.Add("seed.txt")
+	assert.NoError(t, err)
+	extended, err := w.Commit("extend seed", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+
+	gs := services.NewGitService()
+	lines, err := gs.Blame(repo, "feature", "seed.txt")
+	assert.NoError(t, err)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, 1, lines[0].LineNumber)
+	assert.Equal(t, "intro", lines[0].Text)
+	assert.Equal(t, extended.String(), lines[0].Hash)
+	assert.Equal(t, "Test", lines[0].Author)
+	assert.Equal(t, "test@example.com", lines[0].Email)
+	assert.Equal(t, "seed", lines[1].Text)
+	assert.NotEqual(t, extended.String(), lines[1].Hash)
+
+	_, err = gs.Blame(repo, "feature", "missing.txt")
+	assert.Error(t, err)
+}
+
+func TestFileAtRef_ReadsPreviousVersion(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	root := w.Filesystem.Root()
+	seedHead, err := repo.Head()
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "seed.txt"), []byte("seed v2"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "assets"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "assets", "logo.bin"), []byte{0x89, 'P', 'N', 'G', 0x00, 0x01}, 0644))
+	_, err = w.Add(".")
+	assert.NoError(t, err)
+	commit("next")
+
+	gs := services.NewGitService()
+	file, err := gs.FileAtRef(repo, "HEAD~1", "seed.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "seed", file.Content)
+	assert.Equal(t, "seed.txt", file.Path)
+	assert.Equal(t, seedHead.Hash().String(), file.Hash)
+	assert.False(t, file.Binary)
+
+	file, err = gs.FileAtRef(repo, "HEAD", "seed.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "seed v2", file.Content)
+
+	file, err = gs.FileAtRef(repo, "master", "assets/logo.bin")
+	assert.NoError(t, err)
+	assert.True(t, file.Binary)
+	assert.Empty(t, file.Content)
+	assert.Equal(t, int64(6), file.Size)
+
+	_, err = gs.FileAtRef(repo, "HEAD~1", "assets/logo.bin")
+	assert.ErrorIs(t, err, object.ErrFileNotFound)
+
+	_, err = gs.FileAtRef(repo, "HEAD", "assets")
+	assert.ErrorContains(t, err, "is a directory")
+}
+
+func TestHasUnpushedCommits_LocalOnlyBranch(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+
+	gs := services.NewGitService()
+	unpushed, err := gs.HasUnpushedCommits(repo, "docs/master", "")
+	assert.NoError(t, err)
+	assert.False(t, unpushed, "no remote configured")
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{t.TempDir()}})
+	assert.NoError(t, err)
+	unpushed, err = gs.HasUnpushedCommits(repo, "docs/master", "origin")
+	assert.NoError(t, err)
+	assert.False(t, unpushed, "no remote-tracking branch")
+}
+
+func TestHasUnpushedCommits_PushedBranch(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+
+	remoteDir := t.TempDir()
+	_, err := git.PlainInit(remoteDir, true)
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"refs/heads/docs/master:refs/heads/docs/master"},
+	}))
+	err = repo.Fetch(&git.FetchOptions{RemoteName: "origin"})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	gs := services.NewGitService()
+	unpushed, err := gs.HasUnpushedCommits(repo, "docs/master", "origin")
+	assert.NoError(t, err)
+	assert.False(t, unpushed)
+
+	commit("docs-2")
+	unpushed, err = gs.HasUnpushedCommits(repo, "docs/master", "origin")
+	assert.NoError(t, err)
+	assert.True(t, unpushed)
+}
+
+func TestSetUpstream_WritesTrackingConfig(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+	_, err := repo.CreateRemote(&config.RemoteConfig{Name: "upstream", URLs: []string{t.TempDir()}})
+	assert.NoError(t, err)
+	gs := services.NewGitService()
+
+	assert.ErrorContains(t, gs.SetUpstream(repo, "docs/missing", "upstream", ""), "does not exist")
+	assert.Error(t, gs.SetUpstream(repo, "docs/master", "nowhere", ""))
+
+	assert.NoError(t, gs.SetUpstream(repo, "docs/master", "upstream", "docs/pr-7"))
+	branch, err := repo.Branch("docs/master")
+	assert.NoError(t, err)
+	assert.Equal(t, "upstream", branch.Remote)
+	assert.Equal(t, plumbing.NewBranchReferenceName("docs/pr-7"), branch.Merge)
+}
+
+func TestPushBranch_TracksOnlyThePushedBranch(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/master")
+	commit("docs-1")
+	remoteDir := t.TempDir()
+	_, err := git.PlainInit(remoteDir, true)
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}})
+	assert.NoError(t, err)
+	// master already exists on origin without being tracked
+	assert.NoError(t, repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"refs/heads/master:refs/heads/master"},
+	}))
+	gs := services.NewGitService()
+
+	assert.NoError(t, gs.PushBranch(repo, "docs/master"))
+	branch, err := repo.Branch("docs/master")
+	assert.NoError(t, err)
+	assert.Equal(t, "origin", branch.Remote)
+	assert.Equal(t, plumbing.NewBranchReferenceName("docs/master"), branch.Merge)
+	_, err = repo.Branch("master")
+	assert.ErrorIs(t, err, git.ErrBranchNotFound, "branches that were not pushed must keep their config")
+
+	commit("docs-2")
+	unpushed, err := gs.HasUnpushedCommits(repo, "docs/master", "")
+	assert.NoError(t, err)
+	assert.True(t, unpushed)
+}
+
 func TestStageFilesAndCommit_UsesDefaultSignature(t *testing.T) {
 	dir, err := os.MkdirTemp("", "gitservicetest")
 	assert.NoError(t, err)
@@ -649,3 +1330,534 @@ func TestDiffBetweenCommits_ExcludesDistLocalesPbGoAndGenerated(t *testing.T) {
 	// Included file should be present
 	assert.Contains(t, diff, "src/app/main.go")
 }
+
+// commitDocFile writes content to rel on the checked out branch and commits it.
+func commitDocFile(t *testing.T, repo *git.Repository, rel, content string) plumbing.Hash {
+	t.Helper()
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	full := filepath.Join(w.Filesystem.Root(), rel)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+	assert.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	_, err = w.Add(filepath.ToSlash(rel))
+	assert.NoError(t, err)
+	hash, err := w.Commit("update "+rel, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+	return hash
+}
+
+func checkoutBranch(t *testing.T, repo *git.Repository, branch string) {
+	t.Helper()
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch)}))
+}
+
+// newCherryPickRepo commits a guide with a typo on master and fixes the typo
+// on docs/feature-a after an unrelated edit. It returns the fix commit.
+func newCherryPickRepo(t *testing.T) (*git.Repository, plumbing.Hash) {
+	t.Helper()
+	repo, _ := newAheadBehindRepo(t)
+	commitDocFile(t, repo, "guides/intro.md", "one\ntow\nthree\nfour\nfive\n")
+	checkoutNewBranch(t, repo, "docs/feature-a")
+	commitDocFile(t, repo, "guides/intro.md", "one\ntow\nthree\nfour\nfive, expanded\n")
+	fix := commitDocFile(t, repo, "guides/intro.md", "one\ntwo\nthree\nfour\nfive, expanded\n")
+	checkoutBranch(t, repo, "master")
+	return repo, fix
+}
+
+func TestCherryPick_AppliesFixOntoCheckedOutBranch(t *testing.T) {
+	repo, fix := newCherryPickRepo(t)
+	checkoutNewBranch(t, repo, "docs/feature-b")
+	commitDocFile(t, repo, "guides/intro.md", "one\ntow\nthree\nfour\nfive, feature b\n")
+	commitDocFile(t, repo, "guides/other.md", "other\n")
+	tip, err := repo.Head()
+	assert.NoError(t, err)
+	svc := services.NewGitService()
+
+	picked, err := svc.CherryPick(repo, "docs/feature-b", fix.String())
+	assert.NoError(t, err)
+
+	commit, err := repo.CommitObject(picked)
+	assert.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{tip.Hash()}, commit.ParentHashes)
+	assert.Contains(t, commit.Message, "(cherry picked from commit "+fix.String()+")")
+	file, err := commit.File("guides/intro.md")
+	assert.NoError(t, err)
+	content, err := file.Contents()
+	assert.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\nfour\nfive, feature b\n", content)
+	_, err = commit.File("guides/other.md")
+	assert.NoError(t, err)
+
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	onDisk, err := os.ReadFile(filepath.Join(w.Filesystem.Root(), "guides", "intro.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(onDisk))
+	status, err := w.Status()
+	assert.NoError(t, err)
+	assert.True(t, status.IsClean())
+
+	_, err = svc.CherryPick(repo, "docs/feature-b", fix.String())
+	assert.ErrorContains(t, err, "already on branch")
+}
+
+func TestCherryPick_ReportsConflicts(t *testing.T) {
+	repo, fix := newCherryPickRepo(t)
+	checkoutNewBranch(t, repo, "docs/feature-c")
+	commitDocFile(t, repo, "guides/intro.md", "one\ntoo\nthree\nfour\nfive\n")
+	checkoutBranch(t, repo, "master")
+	tip, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature-c"), true)
+	assert.NoError(t, err)
+
+	_, err = services.NewGitService().CherryPick(repo, "docs/feature-c", fix.String())
+	var conflict *services.CherryPickConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, "docs/feature-c", conflict.Branch)
+		assert.Equal(t, []string{"guides/intro.md"}, conflict.Paths)
+		assert.Equal(t, "ERR_CHERRY_PICK_CONFLICT:docs/feature-c:guides/intro.md", conflict.Error())
+	}
+	after, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature-c"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Hash(), after.Hash())
+}
+
+func TestRevert_UndoesCommitOnCheckedOutBranch(t *testing.T) {
+	repo, fix := newCherryPickRepo(t)
+	checkoutBranch(t, repo, "docs/feature-a")
+	commitDocFile(t, repo, "guides/other.md", "other\n")
+	tip, err := repo.Head()
+	assert.NoError(t, err)
+	svc := services.NewGitService()
+
+	reverted, err := svc.Revert(repo, "docs/feature-a", fix.String())
+	assert.NoError(t, err)
+
+	commit, err := repo.CommitObject(reverted)
+	assert.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{tip.Hash()}, commit.ParentHashes)
+	assert.Equal(t, "Revert \"update guides/intro.md\"\n\nThis reverts commit "+fix.String()+".\n", commit.Message)
+	file, err := commit.File("guides/intro.md")
+	assert.NoError(t, err)
+	content, err := file.Contents()
+	assert.NoError(t, err)
+	assert.Equal(t, "one\ntow\nthree\nfour\nfive, expanded\n", content)
+	_, err = commit.File("guides/other.md")
+	assert.NoError(t, err)
+
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	onDisk, err := os.ReadFile(filepath.Join(w.Filesystem.Root(), "guides", "intro.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(onDisk))
+	status, err := w.Status()
+	assert.NoError(t, err)
+	assert.True(t, status.IsClean())
+
+	_, err = svc.Revert(repo, "docs/feature-a", fix.String())
+	assert.ErrorContains(t, err, "are not on branch")
+}
+
+func TestRevert_ReportsConflictsWhenBranchAdvanced(t *testing.T) {
+	repo, fix := newCherryPickRepo(t)
+	checkoutBranch(t, repo, "docs/feature-a")
+	commitDocFile(t, repo, "guides/intro.md", "one\ntwo, reworded\nthree\nfour\nfive, expanded\n")
+	checkoutBranch(t, repo, "master")
+	tip, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature-a"), true)
+	assert.NoError(t, err)
+
+	_, err = services.NewGitService().Revert(repo, "docs/feature-a", fix.String())
+	var conflict *services.RevertConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, fix.String(), conflict.Commit)
+		assert.Equal(t, []string{"guides/intro.md"}, conflict.Paths)
+		assert.Equal(t, "ERR_REVERT_CONFLICT:docs/feature-a:guides/intro.md", conflict.Error())
+	}
+	after, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature-a"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Hash(), after.Hash())
+}
+
+// newWorktreeCompareRepo commits two guides under docs and a file outside it
+// on a checked-out docs/feature branch.
+func newWorktreeCompareRepo(t *testing.T) (*git.Repository, string) {
+	t.Helper()
+	repo, _ := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/feature")
+	commitDocFile(t, repo, "docs/staged.md", "staged\n")
+	commitDocFile(t, repo, "docs/unstaged.md", "unstaged\n")
+	commitDocFile(t, repo, "outside.md", "outside\n")
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	return repo, w.Filesystem.Root()
+}
+
+func TestCompareWorktreeToBranch_IncludesStagedUnstagedAndUntracked(t *testing.T) {
+	repo, root := newWorktreeCompareRepo(t)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "docs", "staged.md"), []byte("staged\nstaged edit\n"), 0644))
+	_, err = w.Add("docs/staged.md")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "docs", "unstaged.md"), []byte("unstaged\nunstaged edit\n"), 0644))
+	untracked := []byte("untracked page\n")
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "docs", "new.md"), untracked, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "outside.md"), []byte("outside edit\n"), 0644))
+
+	diff, err := services.NewGitService().CompareWorktreeToBranch(repo, "docs/feature", "docs")
+	assert.NoError(t, err)
+
+	assert.Contains(t, diff, "diff --git a/docs/staged.md b/docs/staged.md")
+	assert.Contains(t, diff, "+staged edit")
+	assert.Contains(t, diff, "diff --git a/docs/unstaged.md b/docs/unstaged.md")
+	assert.Contains(t, diff, "+unstaged edit")
+	assert.Contains(t, diff, "diff --git a/docs/new.md b/docs/new.md")
+	assert.Contains(t, diff, "+untracked page")
+	assert.NotContains(t, diff, "outside.md")
+	// The comparison is built in memory, so the untracked file is not stored
+	assert.Error(t, repo.Storer.HasEncodedObject(plumbing.ComputeHash(plumbing.BlobObject, untracked)))
+}
+
+func TestCompareWorktreeToBranch_ReportsDeletionsAndSkipsIgnored(t *testing.T) {
+	repo, root := newWorktreeCompareRepo(t)
+	commitDocFile(t, repo, ".gitignore", "*.log\n")
+	assert.NoError(t, os.Remove(filepath.Join(root, "docs", "unstaged.md")))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "docs", "build.log"), []byte("noise\n"), 0644))
+
+	diff, err := services.NewGitService().CompareWorktreeToBranch(repo, "docs/feature", "docs")
+	assert.NoError(t, err)
+
+	assert.Contains(t, diff, "diff --git a/docs/unstaged.md b/docs/unstaged.md")
+	assert.Contains(t, diff, "deleted file mode")
+	assert.NotContains(t, diff, "build.log")
+	assert.NotContains(t, diff, "docs/staged.md")
+}
+
+func TestCompareWorktreeToBranch_CleanWorktreeHasNoDiff(t *testing.T) {
+	repo, _ := newWorktreeCompareRepo(t)
+
+	diff, err := services.NewGitService().CompareWorktreeToBranch(repo, "docs/feature", "docs")
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	_, err = services.NewGitService().CompareWorktreeToBranch(repo, "docs/missing", "docs")
+	assert.Error(t, err)
+}
+
+func TestShowCommit_ReturnsMetadata(t *testing.T) {
+	dir := t.TempDir()
+	gs := services.NewGitService()
+	repo, err := gs.Init(dir)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	_, err = w.Add("a.txt")
+	assert.NoError(t, err)
+	when := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	root, err := w.Commit("initial commit\n", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: when},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a2"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0644))
+	_, err = w.Add(".")
+	assert.NoError(t, err)
+	second, err := w.Commit("Update a and add b\n\nLonger body.", &git.CommitOptions{
+		Author: &object.Signature{Name: "Other", Email: "other@example.com", When: when.Add(time.Hour)},
+	})
+	assert.NoError(t, err)
+
+	info, err := gs.ShowCommit(repo, second.String())
+	assert.NoError(t, err)
+	assert.Equal(t, second.String(), info.Hash)
+	assert.Equal(t, "Update a and add b\n\nLonger body.", info.Message)
+	assert.Equal(t, "Other", info.Author)
+	assert.Equal(t, "other@example.com", info.Email)
+	assert.True(t, info.Date.Equal(when.Add(time.Hour)))
+	assert.Equal(t, 2, info.FilesChanged)
+
+	info, err = gs.ShowCommit(repo, root.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "initial commit", info.Message)
+	assert.True(t, info.Date.Equal(when))
+	assert.Equal(t, 1, info.FilesChanged)
+
+	_, err = gs.ShowCommit(repo, "does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestCommitLog_StopsAtMergeBase(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	commit("base")
+	checkoutNewBranch(t, repo, "feature")
+	first := commit("feature-1")
+	second := commit("feature-2")
+	third := commit("feature-3")
+	checkoutBranch(t, repo, "master")
+	commit("master-only")
+	svc := services.NewGitService()
+
+	commits, err := svc.CommitLog(repo, "master", "feature", 0)
+	assert.NoError(t, err)
+	hashes := make([]string, 0, len(commits))
+	for _, c := range commits {
+		hashes = append(hashes, c.Hash)
+	}
+	assert.Equal(t, []string{third.String(), second.String(), first.String()}, hashes)
+	assert.Equal(t, "feature-3", commits[0].Message)
+	assert.Equal(t, 1, commits[0].FilesChanged)
+
+	limited, err := svc.CommitLog(repo, "master", "feature", 2)
+	assert.NoError(t, err)
+	assert.Len(t, limited, 2)
+	assert.Equal(t, third.String(), limited[0].Hash)
+
+	none, err := svc.CommitLog(repo, "feature", "master~1", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestFetchPrune_RemovesDeletedRemoteBranches(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	commit("base")
+
+	remoteDir := t.TempDir()
+	remote, err := git.PlainInit(remoteDir, true)
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs: []config.RefSpec{
+			"refs/heads/master:refs/heads/master",
+			"refs/heads/master:refs/heads/stale",
+		},
+	}))
+	err = repo.Fetch(&git.FetchOptions{RemoteName: "origin"})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		t.Fatalf("fetch: %v", err)
+	}
+	_, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", "stale"), true)
+	assert.NoError(t, err)
+
+	assert.NoError(t, remote.Storer.RemoveReference(plumbing.NewBranchReferenceName("stale")))
+
+	gs := services.NewGitService()
+	pruned, err := gs.FetchPrune(repo, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"origin/stale"}, pruned)
+	_, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", "stale"), true)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+	_, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", "master"), true)
+	assert.NoError(t, err)
+
+	pruned, err = gs.FetchPrune(repo, "origin")
+	assert.NoError(t, err)
+	assert.Empty(t, pruned)
+
+	_, err = gs.FetchPrune(repo, "upstream")
+	assert.Error(t, err)
+}
+
+func TestLog_PaginatesNewestFirst(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	var hashes []plumbing.Hash
+	for _, name := range []string{"one", "two", "three", "four"} {
+		hashes = append(hashes, commit(name))
+	}
+	gs := services.NewGitService()
+
+	all, err := gs.Log(repo, "master", 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, all, 5, "four commits plus the seed")
+	assert.Equal(t, hashes[3].String(), all[0].Hash)
+	assert.Equal(t, hashes[3].String()[:7], all[0].ShortHash)
+	assert.Equal(t, "four", all[0].Message)
+	assert.Equal(t, "Test", all[0].Author)
+	assert.Equal(t, "seed", all[4].Message)
+
+	page, err := gs.Log(repo, "master", 2, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, hashes[2].String(), page[0].Hash)
+		assert.Equal(t, hashes[1].String(), page[1].Hash)
+	}
+
+	last, err := gs.Log(repo, "master", 2, 4)
+	assert.NoError(t, err)
+	if assert.Len(t, last, 1) {
+		assert.Equal(t, "seed", last[0].Message)
+	}
+
+	past, err := gs.Log(repo, "master", 2, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, past)
+
+	_, err = gs.Log(repo, "master", 2, -1)
+	assert.Error(t, err)
+	_, err = gs.Log(repo, "missing", 2, 0)
+	assert.Error(t, err)
+}
+
+func TestValidateRemote_ReportsReachability(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	remoteDir := t.TempDir()
+	_, err := git.PlainInit(remoteDir, true)
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}})
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "gone", URLs: []string{filepath.Join(t.TempDir(), "missing")}})
+	assert.NoError(t, err)
+	gs := services.NewGitService()
+
+	status, err := gs.ValidateRemote(repo, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "origin", status.Remote)
+	assert.Equal(t, remoteDir, status.URL)
+	assert.True(t, status.Reachable, "an empty remote is still reachable")
+	assert.False(t, status.AuthFailed)
+	assert.Empty(t, status.Error)
+
+	status, err = gs.ValidateRemote(repo, "gone", nil)
+	assert.NoError(t, err)
+	assert.False(t, status.Reachable)
+	assert.False(t, status.AuthFailed)
+	assert.NotEmpty(t, status.Error)
+
+	_, err = gs.ValidateRemote(repo, "upstream", nil)
+	assert.Error(t, err)
+}
+
+func TestDiffFile_ReturnsSingleFilePatch(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	commitDocFile(t, repo, "docs/guide.md", "one\ntwo\n")
+	commitDocFile(t, repo, "docs/other.md", "same\n")
+	checkoutNewBranch(t, repo, "docs/master")
+	commitDocFile(t, repo, "docs/guide.md", "one\n2\n")
+	commitDocFile(t, repo, "yarn.lock", "lock\n")
+	gs := services.NewGitService()
+
+	changed, err := gs.DiffFile(repo, "master", "docs/master", "docs/guide.md")
+	assert.NoError(t, err)
+	assert.Contains(t, changed, "diff --git a/docs/guide.md b/docs/guide.md")
+	assert.Contains(t, changed, "-two\n+2\n")
+	assert.NotContains(t, changed, "yarn.lock")
+
+	unchanged, err := gs.DiffFile(repo, "master", "docs/master", "docs/other.md")
+	assert.NoError(t, err)
+	assert.Empty(t, unchanged)
+
+	excluded, err := gs.DiffFile(repo, "master", "docs/master", "yarn.lock")
+	assert.NoError(t, err)
+	assert.Empty(t, excluded, "excluded files are not diffed")
+
+	_, err = gs.DiffFile(repo, "master", "docs/master", " ")
+	assert.Error(t, err)
+}
+
+func TestDiffFile_FindsRenamedFileByEitherPath(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	commitDocFile(t, repo, "docs/old.md", "line one\nline two\nline three\nline four\n")
+	checkoutNewBranch(t, repo, "docs/master")
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, err = w.Remove("docs/old.md")
+	assert.NoError(t, err)
+	commitDocFile(t, repo, "docs/new.md", "line one\nline two\nline three\nline 4\n")
+	gs := services.NewGitService()
+
+	for _, path := range []string{"docs/old.md", "docs/new.md"} {
+		renamed, err := gs.DiffFile(repo, "master", "docs/master", path)
+		assert.NoError(t, err)
+		assert.Contains(t, renamed, "diff --git a/docs/old.md b/docs/new.md", path)
+		assert.Contains(t, renamed, "-line four\n+line 4\n", path)
+	}
+}
+
+func TestGetRemotes_ListsConfiguredRemotes(t *testing.T) {
+	repo, _ := newAheadBehindRepo(t)
+	gs := services.NewGitService()
+
+	remotes, err := gs.GetRemotes(repo)
+	assert.NoError(t, err)
+	assert.Empty(t, remotes, "a repository without remotes has none to list")
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "upstream", URLs: []string{"https://example.com/upstream.git"}})
+	assert.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"git@example.com:fork.git", "https://example.com/fork.git"}})
+	assert.NoError(t, err)
+
+	remotes, err = gs.GetRemotes(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RemoteInfo{
+		{Name: "origin", URLs: []string{"git@example.com:fork.git", "https://example.com/fork.git"}},
+		{Name: "upstream", URLs: []string{"https://example.com/upstream.git"}},
+	}, remotes)
+
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	byPath, err := gs.GetRemotesByPath(wt.Filesystem.Root())
+	assert.NoError(t, err)
+	assert.Equal(t, remotes, byPath)
+
+	_, err = gs.GetRemotes(nil)
+	assert.Error(t, err)
+}
+
+func TestArchiveBranch_RoundTripThroughTag(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/feature")
+	docsHead := commit("docs-1")
+	checkoutBranch(t, repo, "master")
+	svc := services.NewGitService()
+
+	tag, err := svc.ArchiveBranch(repo, "docs/feature")
+	assert.NoError(t, err)
+	assert.Equal(t, "archive/docs/feature/"+time.Now().Format("2006-01-02"), tag)
+	exists, err := svc.BranchExists(repo, "docs/feature")
+	assert.NoError(t, err)
+	assert.False(t, exists, "the archived branch should be deleted")
+	tagRef, err := repo.Tag(tag)
+	assert.NoError(t, err)
+	tagObject, err := repo.TagObject(tagRef.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, docsHead, tagObject.Target)
+
+	branch, err := svc.RestoreArchivedBranch(repo, tag)
+	assert.NoError(t, err)
+	assert.Equal(t, "docs/feature", branch)
+	restored, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, docsHead, restored.Hash())
+	_, err = repo.Tag(tag)
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestArchiveBranch_NumbersRepeatArchivesAndKeepsCheckedOutBranch(t *testing.T) {
+	repo, commit := newAheadBehindRepo(t)
+	checkoutNewBranch(t, repo, "docs/feature")
+	commit("docs-1")
+	svc := services.NewGitService()
+
+	_, err := svc.ArchiveBranch(repo, "docs/feature")
+	assert.ErrorContains(t, err, "is checked out")
+
+	checkoutBranch(t, repo, "master")
+	first, err := svc.ArchiveBranch(repo, "docs/feature")
+	assert.NoError(t, err)
+	// A branch of the same name is generated and archived again the same day
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("docs/feature"), commit("docs-2"))))
+
+	second, err := svc.ArchiveBranch(repo, "docs/feature")
+	assert.NoError(t, err)
+	assert.Equal(t, first+"-2", second)
+
+	_, err = svc.RestoreArchivedBranch(repo, "docs/feature")
+	assert.ErrorContains(t, err, "is not an archive tag")
+}
@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+	"narrabyte/internal/models"
+)
+
+type GenerationProfileRepositoryMock struct {
+	GetFunc           func(ctx context.Context, id uint) (*models.GenerationProfile, error)
+	ListByProjectFunc func(ctx context.Context, projectID uint) ([]*models.GenerationProfile, error)
+	CreateFunc        func(ctx context.Context, profile *models.GenerationProfile) error
+	UpdateFunc        func(ctx context.Context, profile *models.GenerationProfile) error
+	DeleteFunc        func(ctx context.Context, id uint) error
+}
+
+func (m *GenerationProfileRepositoryMock) Get(ctx context.Context, id uint) (*models.GenerationProfile, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *GenerationProfileRepositoryMock) ListByProject(ctx context.Context, projectID uint) ([]*models.GenerationProfile, error) {
+	if m.ListByProjectFunc != nil {
+		return m.ListByProjectFunc(ctx, projectID)
+	}
+	return []*models.GenerationProfile{}, nil
+}
+
+func (m *GenerationProfileRepositoryMock) Create(ctx context.Context, profile *models.GenerationProfile) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, profile)
+	}
+	return nil
+}
+
+func (m *GenerationProfileRepositoryMock) Update(ctx context.Context, profile *models.GenerationProfile) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, profile)
+	}
+	return nil
+}
+
+func (m *GenerationProfileRepositoryMock) Delete(ctx context.Context, id uint) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
@@ -5,13 +5,14 @@ import (
 )
 
 type GenerationSessionRepositoryMock struct {
-	ListByProjectFunc   func(projectID uint) ([]models.GenerationSession, error)
-	GetByIDFunc         func(id uint) (*models.GenerationSession, error)
-	GetByDocsBranchFunc func(projectID uint, docsBranch string) (*models.GenerationSession, error)
-	CreateFunc          func(session *models.GenerationSession) error
-	UpdateByIDFunc      func(id uint, updates map[string]interface{}) error
-	DeleteByIDFunc      func(id uint) error
-	DeleteByProjectFunc func(projectID uint) error
+	ListByProjectFunc       func(projectID uint) ([]models.GenerationSession, error)
+	GetByIDFunc             func(id uint) (*models.GenerationSession, error)
+	GetByDocsBranchFunc     func(projectID uint, docsBranch string) (*models.GenerationSession, error)
+	GetByIdempotencyKeyFunc func(key string) (*models.GenerationSession, error)
+	CreateFunc              func(session *models.GenerationSession) error
+	UpdateByIDFunc          func(id uint, updates map[string]interface{}) error
+	DeleteByIDFunc          func(id uint) error
+	DeleteByProjectFunc     func(projectID uint) error
 }
 
 func (m *GenerationSessionRepositoryMock) ListByProject(projectID uint) ([]models.GenerationSession, error) {
@@ -35,6 +36,13 @@ func (m *GenerationSessionRepositoryMock) GetByDocsBranch(projectID uint, docsBr
 	return nil, nil
 }
 
+func (m *GenerationSessionRepositoryMock) GetByIdempotencyKey(key string) (*models.GenerationSession, error) {
+	if m.GetByIdempotencyKeyFunc != nil {
+		return m.GetByIdempotencyKeyFunc(key)
+	}
+	return nil, nil
+}
+
 func (m *GenerationSessionRepositoryMock) Create(session *models.GenerationSession) error {
 	if m.CreateFunc != nil {
 		return m.CreateFunc(session)
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"narrabyte/internal/models"
+)
+
+type ModelSettingRepositoryMock struct {
+	ListFunc               func() ([]models.ModelSetting, error)
+	GetByKeyFunc           func(modelKey string) (*models.ModelSetting, error)
+	UpsertFunc             func(modelKey, provider string, enabled bool) (*models.ModelSetting, error)
+	SaveFunc               func(setting *models.ModelSetting) (*models.ModelSetting, error)
+	SetProviderEnabledFunc func(provider string, enabled bool) error
+	SetPriorityFunc        func(modelKey, provider string, priority int) (*models.ModelSetting, error)
+	SetEnabledForKeysFunc  func(modelKeys []string, enabled bool) error
+	SetShowReasoningFunc   func(modelKey, provider string, show bool) (*models.ModelSetting, error)
+	SetReasoningBudgetFunc func(modelKey, provider string, tokens int, action string) (*models.ModelSetting, error)
+}
+
+func (m *ModelSettingRepositoryMock) List() ([]models.ModelSetting, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc()
+	}
+	return []models.ModelSetting{}, nil
+}
+
+func (m *ModelSettingRepositoryMock) GetByKey(modelKey string) (*models.ModelSetting, error) {
+	if m.GetByKeyFunc != nil {
+		return m.GetByKeyFunc(modelKey)
+	}
+	return nil, nil
+}
+
+func (m *ModelSettingRepositoryMock) Upsert(modelKey, provider string, enabled bool) (*models.ModelSetting, error) {
+	if m.UpsertFunc != nil {
+		return m.UpsertFunc(modelKey, provider, enabled)
+	}
+	return &models.ModelSetting{ModelKey: modelKey, Provider: provider, Enabled: enabled}, nil
+}
+
+func (m *ModelSettingRepositoryMock) Save(setting *models.ModelSetting) (*models.ModelSetting, error) {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(setting)
+	}
+	return setting, nil
+}
+
+func (m *ModelSettingRepositoryMock) SetProviderEnabled(provider string, enabled bool) error {
+	if m.SetProviderEnabledFunc != nil {
+		return m.SetProviderEnabledFunc(provider, enabled)
+	}
+	return nil
+}
+
+func (m *ModelSettingRepositoryMock) SetPriority(modelKey, provider string, priority int) (*models.ModelSetting, error) {
+	if m.SetPriorityFunc != nil {
+		return m.SetPriorityFunc(modelKey, provider, priority)
+	}
+	return &models.ModelSetting{ModelKey: modelKey, Provider: provider, Priority: priority}, nil
+}
+
+func (m *ModelSettingRepositoryMock) SetEnabledForKeys(modelKeys []string, enabled bool) error {
+	if m.SetEnabledForKeysFunc != nil {
+		return m.SetEnabledForKeysFunc(modelKeys, enabled)
+	}
+	return nil
+}
+
+func (m *ModelSettingRepositoryMock) SetShowReasoning(modelKey, provider string, show bool) (*models.ModelSetting, error) {
+	if m.SetShowReasoningFunc != nil {
+		return m.SetShowReasoningFunc(modelKey, provider, show)
+	}
+	return &models.ModelSetting{ModelKey: modelKey, Provider: provider, ShowReasoning: &show}, nil
+}
+
+func (m *ModelSettingRepositoryMock) SetReasoningBudget(modelKey, provider string, tokens int, action string) (*models.ModelSetting, error) {
+	if m.SetReasoningBudgetFunc != nil {
+		return m.SetReasoningBudgetFunc(modelKey, provider, tokens, action)
+	}
+	return &models.ModelSetting{ModelKey: modelKey, Provider: provider, ReasoningTokenBudget: tokens, ReasoningBudgetAction: action}, nil
+}
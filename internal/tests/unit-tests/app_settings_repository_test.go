@@ -0,0 +1,31 @@
+package unit_tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"narrabyte/internal/database"
+	"narrabyte/internal/models"
+	"narrabyte/internal/repositories"
+)
+
+func TestAppSettingsRepository_GetDefaultsOnEmptyDatabase(t *testing.T) {
+	db, err := database.Init(database.Config{Path: filepath.Join(t.TempDir(), "narrabyte.db")})
+	if !assert.NoError(t, err) {
+		return
+	}
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	defer sqlDB.Close()
+
+	settings, err := repositories.NewAppSettingsRepository(db).Get(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.DefaultHistoryCompactMessages, settings.HistoryCompactMessages)
+		assert.Equal(t, models.DefaultHistoryCompactChars, settings.HistoryCompactChars)
+		assert.Equal(t, models.DefaultHistoryKeepTurns, settings.HistoryKeepTurns)
+		assert.Equal(t, models.DefaultCommitHistoryLimit, settings.CommitHistoryLimit)
+	}
+}
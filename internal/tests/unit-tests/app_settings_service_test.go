@@ -3,10 +3,12 @@ package unit_tests
 import (
 	"context"
 	"errors"
+	"narrabyte/internal/events"
 	"narrabyte/internal/models"
 	"narrabyte/internal/services"
 	"narrabyte/internal/tests/mocks"
 	"narrabyte/internal/utils"
+	"path/filepath"
 	"testing"
 )
 
@@ -160,3 +162,198 @@ func TestAppSettingsService_Update_UpdateError(t *testing.T) {
 	_, err := service.Update("dark", "fr")
 	utils.Equal(t, err.Error(), "update error")
 }
+
+func TestAppSettingsService_SetWorkspaceOptions_Success(t *testing.T) {
+	ctx := context.Background()
+	tempDir := t.TempDir()
+
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.TempBaseDir, tempDir)
+		utils.Equal(t, settings.RetainFailedWorkspaces, true)
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(ctx)
+
+	updatedSettings, err := service.SetWorkspaceOptions("  "+tempDir+"  ", true)
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.TempBaseDir, tempDir)
+	utils.Equal(t, updatedSettings.RetainFailedWorkspaces, true)
+}
+
+func TestAppSettingsService_SetWorkspaceOptions_MissingDirectory(t *testing.T) {
+	ctx := context.Background()
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		t.Fatal("Update should not be called for an invalid directory")
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(ctx)
+
+	_, err := service.SetWorkspaceOptions(missing, false)
+	utils.Equal(t, err.Error(), "temp directory does not exist")
+}
+
+func TestAppSettingsService_SetEventOptions_Success(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.EventCoalesceWindowMs, 500)
+		utils.Equal(t, settings.VerboseEvents, true)
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	updatedSettings, err := service.SetEventOptions(500, true)
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.EventCoalesceWindowMs, 500)
+	utils.Equal(t, updatedSettings.VerboseEvents, true)
+}
+
+func TestAppSettingsService_SetEventOptions_InvalidWindow(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		t.Fatal("Update should not be called for an invalid window")
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	_, err := service.SetEventOptions(-1, false)
+	utils.Equal(t, err.Error(), "coalesce window must be between 0 and 10000 ms")
+}
+
+func TestAppSettingsService_SetDebugLogging(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.DebugLogging, true)
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+	defer events.SetDebugEnabled(false)
+
+	updatedSettings, err := service.SetDebugLogging(true)
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.DebugLogging, true)
+	utils.Equal(t, events.DebugEnabled(), true)
+}
+
+func TestAppSettingsService_SetHistoryCompactModel(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.HistoryCompactModelKey, "openai:gpt-5-mini")
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	updatedSettings, err := service.SetHistoryCompactModel("  openai:gpt-5-mini  ")
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.HistoryCompactModelKey, "openai:gpt-5-mini")
+}
+
+func TestAppSettingsService_SetCloneOptions(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.CloneRetries, 3)
+		utils.Equal(t, settings.CloneTimeoutSeconds, 10)
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	updatedSettings, err := service.SetCloneOptions(3, 10)
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.CloneRetries, 3)
+	utils.Equal(t, updatedSettings.CloneTimeoutSeconds, 10)
+
+	_, err = service.SetCloneOptions(-1, 10)
+	utils.Equal(t, err.Error(), "clone retries must be between 0 and 10")
+	_, err = service.SetCloneOptions(1, 7200)
+	utils.Equal(t, err.Error(), "clone timeout must be between 0 and 3600 seconds")
+}
+
+func TestAppSettingsService_SetMaxDocFileSize(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.MaxDocFileKB, 512)
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	updatedSettings, err := service.SetMaxDocFileSize(512)
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.MaxDocFileKB, 512)
+
+	_, err = service.SetMaxDocFileSize(0)
+	utils.Equal(t, err.Error(), "maximum file size must be between 1 and 102400 KB")
+}
+
+func TestAppSettingsService_SetCommitHistoryLimit(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.CommitHistoryLimit, 50)
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	updatedSettings, err := service.SetCommitHistoryLimit(50)
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.CommitHistoryLimit, 50)
+
+	_, err = service.SetCommitHistoryLimit(201)
+	utils.Equal(t, err.Error(), "commit history limit must be between 0 and 200")
+}
+
+func TestAppSettingsService_SetExistingDocsContext(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.ExistingDocsContextChars, 20000)
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	updatedSettings, err := service.SetExistingDocsContext(20000)
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.ExistingDocsContextChars, 20000)
+
+	_, err = service.SetExistingDocsContext(-1)
+	utils.Equal(t, err.Error(), "existing docs context must be between 0 and 200000 characters")
+}
+
+func TestAppSettingsService_SetGeminiOptions(t *testing.T) {
+	mockRepo := &mocks.AppSettingsRepositoryMock{}
+	mockRepo.UpdateFunc = func(c context.Context, settings *models.AppSettings) error {
+		utils.Equal(t, settings.GeminiSafetyThreshold, "BLOCK_NONE")
+		utils.Equal(t, settings.GeminiSystemInstruction, "Write in British English.")
+		return nil
+	}
+
+	service := services.NewAppSettingsService(mockRepo)
+	service.Startup(context.Background())
+
+	updatedSettings, err := service.SetGeminiOptions(" BLOCK_NONE ", " Write in British English.\n")
+	utils.NilError(t, err)
+	utils.Equal(t, updatedSettings.GeminiSafetyThreshold, "BLOCK_NONE")
+
+	_, err = service.SetGeminiOptions("BLOCK_SOME", "")
+	utils.Equal(t, err.Error(), `unknown Gemini safety threshold "BLOCK_SOME"`)
+}
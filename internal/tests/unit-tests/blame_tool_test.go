@@ -0,0 +1,101 @@
+package unit_tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"narrabyte/internal/llm/tools"
+	"narrabyte/internal/utils"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// blameRepo commits session.go twice, the second time by another author who
+// changes its last line, and returns a session bound to a snapshot of HEAD.
+func blameRepo(t *testing.T, name string, withSnapshot bool) (context.Context, plumbing.Hash) {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	utils.NilError(t, err)
+	wt, err := repo.Worktree()
+	utils.NilError(t, err)
+
+	commit := func(content, message, author string, when time.Time) plumbing.Hash {
+		utils.NilError(t, os.WriteFile(filepath.Join(root, "session.go"), []byte(content), 0o644))
+		_, err := wt.Add("session.go")
+		utils.NilError(t, err)
+		hash, err := wt.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: author, Email: strings.ToLower(author) + "@example.com", When: when},
+		})
+		utils.NilError(t, err)
+		return hash
+	}
+	commit("package auth\n\nconst ttl = 30\n", "Add session TTL", "Alice", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	second := commit("package auth\n\nconst ttl = 60\n", "Double the session TTL\n\nUsers were logged out too often.", "Bob", time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	// Uncommitted edits must not be attributed
+	utils.NilError(t, os.WriteFile(filepath.Join(root, "session.go"), []byte("package auth\n\nconst ttl = 90\n"), 0o644))
+
+	sessionID := "blame-test-" + name
+	t.Cleanup(func() { tools.ClearSession(sessionID) })
+	tools.SetCodeRootForSession(sessionID, root)
+	if withSnapshot {
+		head, err := repo.CommitObject(second)
+		utils.NilError(t, err)
+		snapshot, err := tools.NewGitSnapshot(repo, head, root, "main")
+		utils.NilError(t, err)
+		tools.SetGitSnapshotForSession(sessionID, snapshot)
+	}
+	return tools.ContextWithSession(context.Background(), sessionID), second
+}
+
+func TestBlame_AttributesLinesFromSnapshot(t *testing.T) {
+	ctx, head := blameRepo(t, "snapshot", true)
+
+	out, err := tools.Blame(ctx, &tools.BlameInput{FilePath: "session.go"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["commit"], head.String())
+	utils.Equal(t, out.Metadata["lines"], "3")
+	utils.Equal(t, out.Metadata["commits"], "2")
+	utils.Equal(t, strings.Contains(out.Output, "2024-03-01 Alice | package auth"), true)
+	utils.Equal(t, strings.Contains(out.Output, head.String()[:7]+" 2024-06-01 Bob | const ttl = 60"), true)
+	utils.Equal(t, strings.Contains(out.Output, ": Double the session TTL\n"), true)
+	utils.Equal(t, strings.Contains(out.Output, "ttl = 90"), false)
+}
+
+func TestBlame_LineRangeWithoutSnapshot(t *testing.T) {
+	ctx, head := blameRepo(t, "head", false)
+
+	out, err := tools.Blame(ctx, &tools.BlameInput{FilePath: "session.go", StartLine: 3, EndLine: 3})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["lines"], "1")
+	utils.Equal(t, out.Metadata["commit"], head.String())
+	utils.Equal(t, strings.Contains(out.Output, "Bob | const ttl = 60"), true)
+	utils.Equal(t, strings.Contains(out.Output, "Alice"), false)
+}
+
+func TestBlame_InvalidRequests(t *testing.T) {
+	ctx, _ := blameRepo(t, "invalid", true)
+
+	out, err := tools.Blame(ctx, &tools.BlameInput{FilePath: "missing.go"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["error"], "not_found")
+
+	out, err = tools.Blame(ctx, &tools.BlameInput{FilePath: "session.go", StartLine: 3, EndLine: 2})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["error"], "format_error")
+
+	out, err = tools.Blame(ctx, &tools.BlameInput{FilePath: "session.go", StartLine: 10})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["error"], "format_error")
+
+	out, err = tools.Blame(ctx, &tools.BlameInput{FilePath: "../outside.go"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["error"], "format_error")
+}
@@ -0,0 +1,1121 @@
+package unit_tests
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"narrabyte/internal/events"
+	"narrabyte/internal/llm/client"
+	"narrabyte/internal/models"
+	"narrabyte/internal/services"
+	"narrabyte/internal/tests/mocks"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// newNoChangesProject creates a repository whose "feature" branch points at
+// the same commit as master, with docs living in the same repository.
+func newNoChangesProject(t *testing.T) *models.RepoLink {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "index.md"), []byte("# Docs\n"), 0o644))
+
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, err = w.Add(".")
+	assert.NoError(t, err)
+	head, err := w.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), head)))
+
+	return &models.RepoLink{
+		ID:                      1,
+		ProjectName:             "demo",
+		CodebaseRepo:            dir,
+		DocumentationRepo:       filepath.Join(dir, "docs"),
+		DocumentationBaseBranch: "master",
+	}
+}
+
+func newNoChangesClientService(t *testing.T, project *models.RepoLink, sessions *mocks.GenerationSessionRepositoryMock, settings *models.AppSettings) *services.ClientService {
+	t.Helper()
+	return newProfileClientService(t, project, sessions, settings, &mocks.TemplateRepositoryMock{}, &mocks.GenerationProfileRepositoryMock{})
+}
+
+// newProfileClientService is newNoChangesClientService with the template and
+// generation profile repositories supplied by the test.
+func newProfileClientService(t *testing.T, project *models.RepoLink, sessions *mocks.GenerationSessionRepositoryMock, settings *models.AppSettings, templateRepo *mocks.TemplateRepositoryMock, profileRepo *mocks.GenerationProfileRepositoryMock) *services.ClientService {
+	t.Helper()
+	ctx := context.Background()
+
+	keyringService := newMockKeyringService(t)
+	assert.NoError(t, keyringService.StoreApiKey("openai", []byte("sk-test")))
+
+	modelConfigs := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, modelConfigs.Startup(ctx))
+
+	repoLinks := services.NewRepoLinkService(&mocks.RepoLinkRepositoryMock{
+		FindByIDFunc: func(ctx context.Context, id uint) (*models.RepoLink, error) {
+			return project, nil
+		},
+	}, services.FumadocsService{}, services.GitService{}, modelConfigs)
+
+	appSettings := services.NewAppSettingsService(&mocks.AppSettingsRepositoryMock{
+		GetFunc: func(ctx context.Context) (*models.AppSettings, error) {
+			copied := *settings
+			return &copied, nil
+		},
+	})
+
+	templates := services.NewTemplateService(templateRepo)
+	templates.Startup(ctx)
+	profiles := services.NewGenerationProfileService(profileRepo, templates, modelConfigs)
+	profiles.Startup(ctx)
+
+	svc := services.NewClientService(
+		repoLinks,
+		services.NewGitService(),
+		keyringService,
+		services.NewGenerationSessionService(sessions),
+		modelConfigs,
+		appSettings,
+		templates,
+		profiles,
+		services.NewFumadocsService(),
+	)
+	assert.NoError(t, svc.Startup(ctx))
+	return svc
+}
+
+func TestGenerateDocs_NoChangesDeletesSession(t *testing.T) {
+	project := newNoChangesProject(t)
+
+	live := map[uint]bool{}
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			session.ID = 7
+			live[session.ID] = true
+			return nil
+		},
+		DeleteByIDFunc: func(id uint) error {
+			delete(live, id)
+			return nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	result, err := svc.GenerateDocs(project.ID, "feature", "master", models.DefaultModelKeyValue, "", "", "", "")
+	assert.Nil(t, result)
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "ERR_NO_CHANGES:"), err.Error())
+	}
+	assert.Empty(t, live, "no session should survive a run without changes")
+
+	_, statErr := os.Stat(filepath.Join(project.CodebaseRepo, ".git", "refs", "heads", "docs", "feature"))
+	assert.True(t, os.IsNotExist(statErr), "no docs branch should be created")
+}
+
+func TestGenerateDocsFromWorktree_CleanWorktreeIsNoChanges(t *testing.T) {
+	project := newNoChangesProject(t)
+
+	live := map[uint]bool{}
+	var created *models.GenerationSession
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			session.ID = 9
+			live[session.ID] = true
+			created = session
+			return nil
+		},
+		DeleteByIDFunc: func(id uint) error {
+			delete(live, id)
+			return nil
+		},
+	}
+	// A clean working tree is never documented, even when runs without changes are allowed
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1, GenerateWithoutChanges: true})
+
+	result, err := svc.GenerateDocsFromWorktree(project.ID, models.DefaultModelKeyValue, "", "", "", "")
+	assert.Nil(t, result)
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "ERR_NO_CHANGES:"), err.Error())
+	}
+	if assert.NotNil(t, created) {
+		assert.True(t, created.FromWorktree)
+		assert.Equal(t, "master", created.SourceBranch)
+	}
+	assert.Empty(t, live, "no session should survive a clean working tree")
+}
+
+func TestGenerateDocs_FallsBackToProjectDefaultModel(t *testing.T) {
+	project := newNoChangesProject(t)
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			session.ID = 7
+			return nil
+		},
+		DeleteByIDFunc: func(id uint) error { return nil },
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	_, err := svc.GenerateDocs(project.ID, "feature", "master", "", "", "", "", "")
+	assert.ErrorContains(t, err, "model is required")
+
+	// Reaching the no-changes check means a model was resolved
+	for _, defaultKey := range []string{models.DefaultModelKeyValue, "openai:retired-model"} {
+		project.DefaultModelKey = defaultKey
+		_, err = svc.GenerateDocs(project.ID, "feature", "master", "", "", "", "", "")
+		if assert.Error(t, err) {
+			assert.True(t, strings.HasPrefix(err.Error(), "ERR_NO_CHANGES:"), err.Error())
+		}
+	}
+}
+
+func TestGenerateDocs_SameIdempotencyKeyCreatesOneSession(t *testing.T) {
+	project := newNoChangesProject(t)
+	// The run fails after its session is stored, leaving the session behind
+	project.CodebaseRepo = filepath.Join(t.TempDir(), "missing")
+
+	var mu sync.Mutex
+	created := 0
+	byKey := map[string]*models.GenerationSession{}
+	entered, release := make(chan struct{}), make(chan struct{})
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			// Hold the first run until the duplicate call is waiting on it
+			if created == 0 {
+				close(entered)
+				<-release
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if session.IdempotencyKey != nil {
+				if _, taken := byKey[*session.IdempotencyKey]; taken {
+					return fmt.Errorf("UNIQUE constraint failed: generation_sessions.idempotency_key")
+				}
+				byKey[*session.IdempotencyKey] = session
+			}
+			created++
+			session.ID = uint(created)
+			session.CreatedAt = time.Now()
+			return nil
+		},
+		GetByIdempotencyKeyFunc: func(key string) (*models.GenerationSession, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return byKey[key], nil
+		},
+		UpdateByIDFunc: func(id uint, updates map[string]interface{}) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if value, ok := updates["idempotency_key"]; ok && value == nil {
+				for key, session := range byKey {
+					if session.ID == id {
+						delete(byKey, key)
+					}
+				}
+			}
+			return nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	var wg sync.WaitGroup
+	generate := func() {
+		defer wg.Done()
+		_, err := svc.GenerateDocs(project.ID, "feature", "master", models.DefaultModelKeyValue, "", "", "", "retry-1")
+		assert.Error(t, err)
+	}
+	wg.Add(2)
+	go generate()
+	<-entered
+	go generate()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, created, "concurrent calls with the same idempotency key must not start a second session")
+	assert.Empty(t, byKey, "a failed run must release its idempotency key")
+
+	_, err := svc.GenerateDocs(project.ID, "feature", "master", models.DefaultModelKeyValue, "", "", "", "retry-2")
+	assert.Error(t, err)
+	assert.Equal(t, 2, created)
+}
+
+func TestGenerateDocs_RetryAfterFailureStartsFreshRun(t *testing.T) {
+	project := newNoChangesProject(t)
+	workingCodebase := project.CodebaseRepo
+	project.CodebaseRepo = filepath.Join(t.TempDir(), "missing")
+
+	created := 0
+	byKey := map[string]*models.GenerationSession{}
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			if session.IdempotencyKey != nil {
+				if _, taken := byKey[*session.IdempotencyKey]; taken {
+					return fmt.Errorf("UNIQUE constraint failed: generation_sessions.idempotency_key")
+				}
+				byKey[*session.IdempotencyKey] = session
+			}
+			created++
+			session.ID = uint(created)
+			session.CreatedAt = time.Now()
+			return nil
+		},
+		GetByIdempotencyKeyFunc: func(key string) (*models.GenerationSession, error) {
+			return byKey[key], nil
+		},
+		UpdateByIDFunc: func(id uint, updates map[string]interface{}) error {
+			if value, ok := updates["idempotency_key"]; ok && value == nil {
+				for key, session := range byKey {
+					if session.ID == id {
+						delete(byKey, key)
+					}
+				}
+			}
+			return nil
+		},
+		DeleteByIDFunc: func(id uint) error { return nil },
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	_, err := svc.GenerateDocs(project.ID, "feature", "master", models.DefaultModelKeyValue, "", "", "", "retry-1")
+	assert.ErrorContains(t, err, "missing")
+
+	// Replaying the key runs again instead of returning the failed session
+	project.CodebaseRepo = workingCodebase
+	_, err = svc.GenerateDocs(project.ID, "feature", "master", models.DefaultModelKeyValue, "", "", "", "retry-1")
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "ERR_NO_CHANGES:"), err.Error())
+	}
+	assert.Equal(t, 2, created)
+}
+
+func TestRenameDocsBranch_RenamesBranchAndSession(t *testing.T) {
+	project := newNoChangesProject(t)
+	repo, err := git.PlainOpen(project.CodebaseRepo)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("docs/feature"), head.Hash())))
+
+	var updates map[string]interface{}
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id, ProjectID: project.ID, DocsBranch: "docs/feature"}, nil
+		},
+		GetByDocsBranchFunc: func(projectID uint, docsBranch string) (*models.GenerationSession, error) {
+			if docsBranch == "docs/taken" {
+				return &models.GenerationSession{ID: 9, ProjectID: projectID, DocsBranch: docsBranch}, nil
+			}
+			return nil, nil
+		},
+		UpdateByIDFunc: func(id uint, u map[string]interface{}) error {
+			updates = u
+			return nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	err = svc.RenameDocsBranch(3, "docs/taken")
+	assert.EqualError(t, err, "ERR_SESSION_EXISTS:9:docs/taken")
+
+	assert.NoError(t, svc.RenameDocsBranch(3, "docs/pr-42"))
+	assert.Equal(t, map[string]interface{}{"docs_branch": "docs/pr-42"}, updates)
+	renamed, err := repo.Reference(plumbing.NewBranchReferenceName("docs/pr-42"), true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, head.Hash(), renamed.Hash())
+	}
+	_, err = repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+}
+
+func TestMergeDocsIntoSource_AfterRename(t *testing.T) {
+	project := newNoChangesProject(t)
+	dir := project.CodebaseRepo
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/feature"), Create: true}))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte("# Guide\n"), 0o644))
+	_, err = w.Add("docs/guide.md")
+	assert.NoError(t, err)
+	docsCommit, err := w.Commit("docs", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")}))
+
+	session := &models.GenerationSession{ID: 3, ProjectID: project.ID, SourceBranch: "feature", DocsBranch: "docs/feature"}
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			copied := *session
+			return &copied, nil
+		},
+		GetByDocsBranchFunc: func(projectID uint, docsBranch string) (*models.GenerationSession, error) {
+			return nil, nil
+		},
+		UpdateByIDFunc: func(id uint, u map[string]interface{}) error {
+			session.DocsBranch = u["docs_branch"].(string)
+			return nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	assert.NoError(t, svc.RenameDocsBranch(3, "docs/pr-42"))
+
+	counts, err := svc.DocsBranchAheadBehind(3)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, counts.Ahead)
+		assert.Equal(t, 0, counts.Behind)
+	}
+
+	assert.NoError(t, svc.MergeDocsIntoSource(3))
+	feature, err := repo.Reference(plumbing.NewBranchReferenceName("feature"), true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, docsCommit, feature.Hash(), "the renamed docs branch must be merged")
+	}
+}
+
+func TestDocsBranchAheadBehind_SeparateDocsRepoUsesDocsBase(t *testing.T) {
+	project := newNoChangesProject(t)
+	docsDir := t.TempDir()
+	project.DocumentationRepo = docsDir
+	repo, err := git.PlainInit(docsDir, false)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	commit := func(content string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(docsDir, "index.md"), []byte(content), 0o644))
+		_, err := w.Add("index.md")
+		assert.NoError(t, err)
+		_, err = w.Commit("docs", &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+		})
+		assert.NoError(t, err)
+	}
+	commit("# Docs\n")
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/feature"), Create: true}))
+	commit("# Docs v2\n")
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id, ProjectID: project.ID, SourceBranch: "feature", DocsBranch: "docs/feature"}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	counts, err := svc.DocsBranchAheadBehind(3)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, counts.Ahead)
+		assert.Equal(t, 0, counts.Behind)
+	}
+}
+
+func TestLoadGenerationSession_RestoresLastSummary(t *testing.T) {
+	project := newNoChangesProject(t)
+	repo, err := git.PlainOpen(project.CodebaseRepo)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("docs/feature"), head.Hash())))
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{
+				ID:           id,
+				ProjectID:    project.ID,
+				ModelKey:     "openai:gpt-5.5",
+				SourceBranch: "feature",
+				TargetBranch: "master",
+				DocsBranch:   "docs/feature",
+				LastSummary:  "Documented the new install flags.",
+			}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	result, err := svc.LoadGenerationSession(3)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Documented the new install flags.", result.Summary)
+	}
+}
+
+func TestListDocsBranches_JoinsSessionsAndCountsAhead(t *testing.T) {
+	project := newNoChangesProject(t)
+	repo, err := git.PlainOpen(project.CodebaseRepo)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	for _, branch := range []string{"docs/orphan", "docs/pr-42", "scratch"} {
+		assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head.Hash())))
+	}
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/pr-42")}))
+	assert.NoError(t, os.WriteFile(filepath.Join(project.CodebaseRepo, "docs", "guide.md"), []byte("# Guide\n"), 0o644))
+	_, err = w.Add("docs/guide.md")
+	assert.NoError(t, err)
+	_, err = w.Commit("docs", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		ListByProjectFunc: func(projectID uint) ([]models.GenerationSession, error) {
+			return []models.GenerationSession{{ID: 4, ProjectID: projectID, SourceBranch: "feature", DocsBranch: "docs/pr-42"}}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	branches, err := svc.ListDocsBranches(project.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, branches, 2) {
+		assert.Equal(t, "docs/orphan", branches[0].Name)
+		assert.Zero(t, branches[0].SessionID)
+		assert.Equal(t, "master", branches[0].Base)
+		assert.Equal(t, 0, branches[0].Ahead)
+
+		assert.Equal(t, "docs/pr-42", branches[1].Name)
+		assert.Equal(t, uint(4), branches[1].SessionID)
+		assert.Equal(t, "feature", branches[1].Base)
+		assert.Equal(t, 1, branches[1].Ahead)
+		assert.False(t, branches[1].LastCommitDate.IsZero())
+	}
+}
+
+func TestCommitDocs_DetachedOnlyUpdatesCommittedIndexEntries(t *testing.T) {
+	project := newNoChangesProject(t)
+	dir := project.CodebaseRepo
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/feature"), Create: true}))
+
+	// A local edit staged by the user, and the page being committed
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "draft.md"), []byte("# Draft\n"), 0o644))
+	_, err = w.Add("docs/draft.md")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "index.md"), []byte("# Docs v2\n"), 0o644))
+
+	headBefore, err := repo.Storer.Reference(plumbing.HEAD)
+	assert.NoError(t, err)
+	branchBefore, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	assert.NoError(t, err)
+	indexBefore, err := repo.Storer.Index()
+	assert.NoError(t, err)
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id, ProjectID: project.ID, DocsBranch: "docs/feature"}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1, DetachedDocCommits: true})
+
+	assert.NoError(t, svc.CommitDocs(project.ID, 3, []string{"docs/index.md"}))
+
+	headAfter, err := repo.Storer.Reference(plumbing.HEAD)
+	assert.NoError(t, err)
+	assert.Equal(t, headBefore.String(), headAfter.String(), "HEAD must stay on the docs branch")
+
+	// The branch moved under the checked-out worktree, so the committed page's
+	// entry must follow it; every other entry stays exactly as it was.
+	indexAfter, err := repo.Storer.Index()
+	assert.NoError(t, err)
+	if assert.Len(t, indexAfter.Entries, len(indexBefore.Entries)) {
+		for i, before := range indexBefore.Entries {
+			after := indexAfter.Entries[i]
+			if before.Name != "docs/index.md" {
+				assert.Equal(t, before, after, "index entry %s must not change", before.Name)
+				continue
+			}
+			assert.NotEqual(t, before.Hash, after.Hash, "the committed page's entry must be updated")
+		}
+	}
+	status, err := w.Status()
+	assert.NoError(t, err)
+	assert.Len(t, status, 1, "the committed page must not show as changed: %s", status)
+	assert.Equal(t, git.Added, status.File("docs/draft.md").Staging, "the user's draft must stay staged")
+
+	branchAfter, err := repo.Reference(plumbing.NewBranchReferenceName("docs/feature"), true)
+	assert.NoError(t, err)
+	commit, err := repo.CommitObject(branchAfter.Hash())
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Add documentation for docs/feature", commit.Message)
+		assert.Equal(t, []plumbing.Hash{branchBefore.Hash()}, commit.ParentHashes)
+		page, err := commit.File("docs/index.md")
+		if assert.NoError(t, err) {
+			content, _ := page.Contents()
+			assert.Equal(t, "# Docs v2\n", content)
+			entry, err := indexAfter.Entry("docs/index.md")
+			if assert.NoError(t, err) {
+				assert.Equal(t, page.Hash, entry.Hash, "the index must hold the committed page")
+			}
+		}
+		_, err = commit.File("docs/draft.md")
+		assert.Error(t, err, "the user's staged draft must not be committed")
+	}
+}
+
+func TestDiscardStagedDocs_KeepsOtherStagedChangesWithDocsAtRepoRoot(t *testing.T) {
+	project := newNoChangesProject(t)
+	dir := project.CodebaseRepo
+	project.DocumentationRepo = dir
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	// The run changed docs/index.md on the docs branch
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/feature"), Create: true}))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "index.md"), []byte("# Docs v2\n"), 0o644))
+	_, err = w.Add("docs/index.md")
+	assert.NoError(t, err)
+	_, err = w.Commit("Generated documentation updates", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+
+	// Staged edits of the session's page and of the user's own code
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "index.md"), []byte("# Docs v3\n"), 0o644))
+	_, err = w.Add("docs/index.md")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	_, err = w.Add("main.go")
+	assert.NoError(t, err)
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id, ProjectID: project.ID, SourceBranch: "feature", DocsBranch: "docs/feature"}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	assert.NoError(t, svc.DiscardStagedDocs(3))
+
+	status, err := w.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, git.Unmodified, status.File("docs/index.md").Staging, "the session's page must be unstaged")
+	assert.Equal(t, git.Modified, status.File("docs/index.md").Worktree, "the page's edits must stay in the worktree")
+	assert.Equal(t, git.Modified, status.File("main.go").Staging, "the user's staged code must stay staged")
+}
+
+func TestExportDocsChanges_FilesAndZip(t *testing.T) {
+	project := newNoChangesProject(t)
+	dir := project.CodebaseRepo
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/feature"), Create: true}))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "index.md"), []byte("# Docs v2\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte("# Guide\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "logo.png"), []byte{0x89, 'P', 'N', 'G', 0x00, 0x01}, 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	_, err = w.Add(".")
+	assert.NoError(t, err)
+	_, err = w.Commit("docs", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id, ProjectID: project.ID, SourceBranch: "feature", DocsBranch: "docs/feature"}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	export, err := svc.ExportDocsChanges(3, services.DocsExportFiles, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs/guide.md", "docs/index.md", "docs/logo.png"}, export.Paths, "code changes are not exported")
+	assert.Equal(t, map[string]string{"docs/guide.md": "# Guide\n", "docs/index.md": "# Docs v2\n"}, export.Files)
+	assert.Equal(t, []string{"docs/logo.png"}, export.Binary)
+
+	export, err = svc.ExportDocsChanges(3, services.DocsExportFiles, []string{"docs/guide.md"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"docs/guide.md": "# Guide\n"}, export.Files)
+
+	archivePath := filepath.Join(t.TempDir(), "docs.zip")
+	export, err = svc.ExportDocsChanges(3, services.DocsExportZip, nil, archivePath)
+	assert.NoError(t, err)
+	assert.Equal(t, archivePath, export.ArchivePath)
+	zr, err := zip.OpenReader(archivePath)
+	if assert.NoError(t, err) {
+		defer zr.Close()
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"docs/guide.md", "docs/index.md", "docs/logo.png"}, names)
+	}
+
+	_, err = svc.ExportDocsChanges(3, services.DocsExportZip, nil, "")
+	assert.EqualError(t, err, "archive path is required")
+	_, err = svc.ExportDocsChanges(3, "tar", nil, "")
+	assert.Error(t, err)
+}
+
+func TestExportDocsChanges_MatchesDisplayedDiff(t *testing.T) {
+	project := newNoChangesProject(t)
+	dir := project.CodebaseRepo
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/feature"), Create: true}))
+	_, err = w.Remove("docs/index.md")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "start.md"), []byte("# Docs\n"), 0o644))
+	_, err = w.Add("docs/start.md")
+	assert.NoError(t, err)
+	_, err = w.Commit("docs", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id, ProjectID: project.ID, SourceBranch: "feature", DocsBranch: "docs/feature"}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	export, err := svc.ExportDocsChanges(3, services.DocsExportFiles, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs/index.md"}, export.Deleted)
+	assert.Equal(t, map[string]string{"docs/start.md": "# Docs\n"}, export.Files)
+
+	stats, err := services.NewGitService().DiffStat(repo, "master", "docs/feature")
+	assert.NoError(t, err)
+	var displayed []string
+	for _, stat := range stats {
+		displayed = append(displayed, stat.Path)
+	}
+	assert.Equal(t, displayed, export.Paths, "the export must list the files the displayed diff lists")
+}
+
+func TestInitNarrabyteConfig_CreatesTemplateOnce(t *testing.T) {
+	project := newNoChangesProject(t)
+	svc := newNoChangesClientService(t, project, &mocks.GenerationSessionRepositoryMock{}, &models.AppSettings{ID: 1})
+	path := filepath.Join(project.DocumentationRepo, ".narrabyte", "llm_instructions.md")
+
+	result, err := svc.InitNarrabyteConfig(project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.NarrabyteConfigInit{Path: path, Created: true}, result)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, client.LLMInstructionsTemplate(), string(content))
+
+	assert.NoError(t, os.WriteFile(path, []byte("Use sentence case.\n"), 0o644))
+	result, err = svc.InitNarrabyteConfig(project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.NarrabyteConfigInit{Existed: true}, result)
+	content, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "Use sentence case.\n", string(content), "existing instructions are never overwritten")
+}
+
+func TestInitNarrabyteConfig_KeepsInstructionsWithOtherExtension(t *testing.T) {
+	project := newNoChangesProject(t)
+	dir := filepath.Join(project.DocumentationRepo, ".narrabyte")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "llm_instructions.txt"), []byte("custom\n"), 0o644))
+	svc := newNoChangesClientService(t, project, &mocks.GenerationSessionRepositoryMock{}, &models.AppSettings{ID: 1})
+
+	result, err := svc.InitNarrabyteConfig(project.ID)
+	assert.NoError(t, err)
+	assert.True(t, result.Existed)
+	_, err = os.Stat(filepath.Join(dir, "llm_instructions.md"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestInitNarrabyteConfig_ReadOnlyRepoIsNoOp(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+	project := newNoChangesProject(t)
+	assert.NoError(t, os.Chmod(project.DocumentationRepo, 0o555))
+	t.Cleanup(func() { _ = os.Chmod(project.DocumentationRepo, 0o755) })
+	svc := newNoChangesClientService(t, project, &mocks.GenerationSessionRepositoryMock{}, &models.AppSettings{ID: 1})
+
+	result, err := svc.InitNarrabyteConfig(project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.NarrabyteConfigInit{ReadOnly: true}, result)
+	_, err = os.Stat(filepath.Join(project.DocumentationRepo, ".narrabyte"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateDocsWithProfile_AppliesExcludedPatterns(t *testing.T) {
+	project := newNoChangesProject(t)
+	repo, err := git.PlainOpen(project.CodebaseRepo)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature")}))
+	assert.NoError(t, os.WriteFile(filepath.Join(project.CodebaseRepo, "schema.sql"), []byte("CREATE TABLE t (id int);\n"), 0o644))
+	_, err = w.Add("schema.sql")
+	assert.NoError(t, err)
+	_, err = w.Commit("add schema", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			assert.Equal(t, models.DefaultModelKeyValue, session.ModelKey)
+			session.ID = 7
+			return nil
+		},
+		DeleteByIDFunc: func(id uint) error { return nil },
+	}
+	profiles := &mocks.GenerationProfileRepositoryMock{
+		GetFunc: func(ctx context.Context, id uint) (*models.GenerationProfile, error) {
+			return &models.GenerationProfile{ID: id, ProjectID: project.ID, Name: "no sql", ModelKey: models.DefaultModelKeyValue, ExcludedPatterns: "*.sql"}, nil
+		},
+	}
+	svc := newProfileClientService(t, project, sessions, &models.AppSettings{ID: 1}, &mocks.TemplateRepositoryMock{}, profiles)
+
+	// The only change is excluded by the profile, so the run has nothing to document
+	_, err = svc.GenerateDocsWithProfile(project.ID, 5, "feature", "master", "", "", "", "")
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "ERR_NO_CHANGES:"), err.Error())
+	}
+}
+
+func TestGenerateDocsWithProfile_ValidatesTemplateWhenApplied(t *testing.T) {
+	project := newNoChangesProject(t)
+	created := 0
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			created++
+			return nil
+		},
+	}
+	templates := &mocks.TemplateRepositoryMock{
+		GetFunc: func(ctx context.Context, id uint) (*models.Template, error) {
+			return nil, fmt.Errorf("template %d not found", id)
+		},
+	}
+	profiles := &mocks.GenerationProfileRepositoryMock{
+		GetFunc: func(ctx context.Context, id uint) (*models.GenerationProfile, error) {
+			return &models.GenerationProfile{ID: id, ProjectID: project.ID, Name: "guide", TemplateID: 3}, nil
+		},
+	}
+	svc := newProfileClientService(t, project, sessions, &models.AppSettings{ID: 1}, templates, profiles)
+
+	_, err := svc.GenerateDocsWithProfile(project.ID, 5, "feature", "master", "", "", "", "")
+	assert.ErrorContains(t, err, `profile "guide": template 3 not found`)
+	assert.Zero(t, created, "no session should be created for an invalid profile")
+}
+
+func TestGenerateDocsWithProfile_RejectsAnotherProjectsProfile(t *testing.T) {
+	project := newNoChangesProject(t)
+	profiles := &mocks.GenerationProfileRepositoryMock{
+		GetFunc: func(ctx context.Context, id uint) (*models.GenerationProfile, error) {
+			return &models.GenerationProfile{ID: id, ProjectID: project.ID + 1, Name: "other"}, nil
+		},
+	}
+	svc := newProfileClientService(t, project, &mocks.GenerationSessionRepositoryMock{}, &models.AppSettings{ID: 1}, &mocks.TemplateRepositoryMock{}, profiles)
+
+	_, err := svc.GenerateDocsWithProfile(project.ID, 5, "feature", "master", "", "", "", "")
+	assert.ErrorContains(t, err, "belongs to another project")
+}
+
+func TestGetAvailableTabSessions_DerivesStatusFromDocsBranch(t *testing.T) {
+	project := newNoChangesProject(t)
+	repo, err := git.PlainOpen(project.CodebaseRepo)
+	assert.NoError(t, err)
+	w, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("docs/feature"), Create: true}))
+	assert.NoError(t, os.WriteFile(filepath.Join(project.CodebaseRepo, "docs", "guide.md"), []byte("# Guide\n"), 0o644))
+	_, err = w.Add("docs/guide.md")
+	assert.NoError(t, err)
+	docsHead, err := w.Commit("Generated documentation updates", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	assert.NoError(t, err)
+	// "released" already contains its docs branch
+	for _, name := range []string{"released", "docs/released"} {
+		assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), docsHead)))
+	}
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		ListByProjectFunc: func(projectID uint) ([]models.GenerationSession, error) {
+			return []models.GenerationSession{
+				{ID: 1, ProjectID: projectID, SourceBranch: "feature", TargetBranch: "master", DocsBranch: "docs/feature"},
+				{ID: 2, ProjectID: projectID, SourceBranch: "released", TargetBranch: "master", DocsBranch: "docs/released"},
+				{ID: 3, ProjectID: projectID, SourceBranch: "deleted", TargetBranch: "master", DocsBranch: "docs/deleted"},
+			}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	infos, err := svc.GetAvailableTabSessions(project.ID)
+	assert.NoError(t, err)
+	statuses := map[uint]string{}
+	for _, info := range infos {
+		statuses[info.ID] = info.Status
+	}
+	assert.Equal(t, map[uint]string{
+		1: services.SessionStatusReady,
+		2: services.SessionStatusMerged,
+		3: services.SessionStatusStale,
+	}, statuses)
+}
+
+func TestArchiveDocsBranch_RestoresLatestArchive(t *testing.T) {
+	project := newNoChangesProject(t)
+	repo, err := git.PlainOpen(project.CodebaseRepo)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	docsRef := plumbing.NewBranchReferenceName("docs/feature")
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(docsRef, head.Hash())))
+
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		GetByIDFunc: func(id uint) (*models.GenerationSession, error) {
+			return &models.GenerationSession{ID: id, ProjectID: project.ID, SourceBranch: "feature", TargetBranch: "master", DocsBranch: "docs/feature"}, nil
+		},
+	}
+	svc := newNoChangesClientService(t, project, sessions, &models.AppSettings{ID: 1})
+
+	tag, err := svc.ArchiveDocsBranch(4)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(tag, "archive/docs/feature/"), tag)
+	_, err = repo.Reference(docsRef, true)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+
+	assert.NoError(t, svc.RestoreDocsBranch(4))
+	restored, err := repo.Reference(docsRef, true)
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash(), restored.Hash())
+
+	assert.ErrorContains(t, svc.RestoreDocsBranch(4), "has not been archived")
+}
+
+// newGatewayValidationService returns a client service whose only usable model
+// is an OpenAI-compatible gateway served by handler, and that model's key.
+// settings, when set, are the app settings the service reads.
+func newGatewayValidationService(t *testing.T, handler http.HandlerFunc, settings *models.AppSettings) (*services.ClientService, string) {
+	t.Helper()
+	return newGatewayRunService(t, handler, nil, &mocks.GenerationSessionRepositoryMock{}, settings)
+}
+
+// newGatewayRunService is newGatewayValidationService for runs on project,
+// whose sessions are stored in sessions.
+func newGatewayRunService(t *testing.T, handler http.HandlerFunc, project *models.RepoLink, sessions *mocks.GenerationSessionRepositoryMock, settings *models.AppSettings) (*services.ClientService, string) {
+	t.Helper()
+	ctx := context.Background()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	keyringService := newMockKeyringService(t)
+	assert.NoError(t, keyringService.StoreApiKey("litellm", []byte("sk-gateway")))
+	modelConfigs := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, modelConfigs.Startup(ctx))
+	gateway, err := modelConfigs.RegisterCustomModel("litellm", "Gateway", "gateway-model", server.URL)
+	assert.NoError(t, err)
+
+	repoLinkRepo := &mocks.RepoLinkRepositoryMock{}
+	if project != nil {
+		repoLinkRepo.FindByIDFunc = func(ctx context.Context, id uint) (*models.RepoLink, error) {
+			return project, nil
+		}
+	}
+	appSettingsRepo := &mocks.AppSettingsRepositoryMock{}
+	if settings != nil {
+		appSettingsRepo.GetFunc = func(ctx context.Context) (*models.AppSettings, error) {
+			copied := *settings
+			return &copied, nil
+		}
+	}
+	templates := services.NewTemplateService(&mocks.TemplateRepositoryMock{})
+	templates.Startup(ctx)
+	profiles := services.NewGenerationProfileService(&mocks.GenerationProfileRepositoryMock{}, templates, modelConfigs)
+	profiles.Startup(ctx)
+	svc := services.NewClientService(
+		services.NewRepoLinkService(repoLinkRepo, services.FumadocsService{}, services.GitService{}, nil),
+		services.NewGitService(),
+		keyringService,
+		services.NewGenerationSessionService(sessions),
+		modelConfigs,
+		services.NewAppSettingsService(appSettingsRepo),
+		templates,
+		profiles,
+		services.NewFumadocsService(),
+	)
+	assert.NoError(t, svc.Startup(ctx))
+	return svc, gateway.Key
+}
+
+func TestValidateModel_ClassifiesRejectedKey(t *testing.T) {
+	svc, key := newGatewayValidationService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"Incorrect API key provided.","type":"invalid_request_error","code":"invalid_api_key"}}`)
+	}, nil)
+
+	err := svc.ValidateModel(key)
+	var providerErr *client.ProviderError
+	if assert.ErrorAs(t, err, &providerErr) {
+		assert.Equal(t, client.ProviderErrorInvalidKey, providerErr.Kind)
+		assert.Equal(t, "litellm", providerErr.Provider)
+	}
+}
+
+func TestValidateModel_ReportsUnclassifiedFailure(t *testing.T) {
+	svc, key := newGatewayValidationService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":{"message":"upstream exploded","type":"server_error"}}`)
+	}, nil)
+
+	err := svc.ValidateModel(key)
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "ERR_MODEL_VALIDATION_FAILED:"+key+":"), err.Error())
+		var providerErr *client.ProviderError
+		assert.False(t, errors.As(err, &providerErr))
+	}
+}
+
+func TestValidateModel_AcceptsWorkingGateway(t *testing.T) {
+	svc, key := newGatewayValidationService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","model":"gateway-model","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}, nil)
+
+	assert.NoError(t, svc.ValidateModel(key))
+}
+
+func TestValidateModel_UnavailableCompactionModelFallsBackWithWarning(t *testing.T) {
+	var warnings []string
+	previous := events.Emit
+	events.Emit = func(_ context.Context, _ string, evt events.ToolEvent) {
+		if evt.Type == events.EventWarn {
+			warnings = append(warnings, evt.Message)
+		}
+	}
+	defer func() { events.Emit = previous }()
+
+	svc, key := newGatewayValidationService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","model":"gateway-model","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}, &models.AppSettings{ID: 1, HistoryCompactModelKey: "anthropic:claude-sonnet-5"})
+
+	assert.NoError(t, svc.ValidateModel(key))
+	if assert.Len(t, warnings, 1) {
+		assert.Contains(t, warnings[0], "history compaction model anthropic:claude-sonnet-5 is unavailable, summarizing with Gateway")
+	}
+}
+
+func TestGenerateDocs_RejectedRunCanBeRetried(t *testing.T) {
+	project := newNoChangesProject(t)
+	repo, err := git.PlainOpen(project.CodebaseRepo)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("other"), head.Hash())))
+
+	var mu sync.Mutex
+	created := 0
+	live := map[uint]*models.GenerationSession{}
+	sessions := &mocks.GenerationSessionRepositoryMock{
+		CreateFunc: func(session *models.GenerationSession) error {
+			mu.Lock()
+			defer mu.Unlock()
+			created++
+			session.ID = uint(created)
+			live[session.ID] = session
+			return nil
+		},
+		GetByDocsBranchFunc: func(projectID uint, docsBranch string) (*models.GenerationSession, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, session := range live {
+				if session.DocsBranch == docsBranch {
+					return session, nil
+				}
+			}
+			return nil, nil
+		},
+		DeleteByIDFunc: func(id uint) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(live, id)
+			return nil
+		},
+	}
+
+	// The gateway holds the first run's model call until it is told to fail it
+	requested := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		<-unblock
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":{"message":"upstream exploded","type":"server_error"}}`)
+	}
+	settings := &models.AppSettings{ID: 1, GenerateWithoutChanges: true, MaxConcurrentRuns: 1, RejectExcessRuns: true}
+	svc, key := newGatewayRunService(t, handler, project, sessions, settings)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := svc.GenerateDocs(project.ID, "feature", "master", key, "", "", "", "")
+		first <- err
+	}()
+	select {
+	case <-requested:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the first run to reach the model")
+	}
+
+	_, err = svc.GenerateDocs(project.ID, "other", "master", key, "", "", "", "")
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "ERR_TOO_MANY_RUNS:"), err.Error())
+	}
+	mu.Lock()
+	assert.Len(t, live, 1, "the rejected run should not leave its session behind")
+	mu.Unlock()
+
+	close(unblock)
+	select {
+	case <-first:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the first run to finish")
+	}
+
+	// The retry gets a slot and runs instead of finding its docs branch taken
+	_, err = svc.GenerateDocs(project.ID, "other", "master", key, "", "", "", "")
+	if assert.Error(t, err) {
+		assert.False(t, strings.HasPrefix(err.Error(), "ERR_SESSION_EXISTS:"), err.Error())
+		assert.False(t, strings.HasPrefix(err.Error(), "ERR_TOO_MANY_RUNS:"), err.Error())
+	}
+	assert.Equal(t, 3, created)
+}
@@ -187,6 +187,72 @@ func TestEdit_CreateNewFile(t *testing.T) {
 	utils.Equal(t, string(content), "new file content")
 }
 
+func TestEdit_AppendToExistingFile(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	testFile := filepath.Join(tempDir, "guide.md")
+	err := os.WriteFile(testFile, []byte("# Guide\n\nIntro"), 0644)
+	utils.NilError(t, err)
+
+	output, err := tools.Edit(context.Background(), &tools.EditInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "guide.md",
+		NewString:  "\n## Usage\n",
+		Append:     true,
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, output.Metadata["error"], "")
+	utils.Equal(t, output.Metadata["replaced"], "true")
+
+	output, err = tools.Edit(context.Background(), &tools.EditInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "guide.md",
+		NewString:  "---\ntitle: Guide\n---",
+		Prepend:    true,
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, output.Metadata["error"], "")
+
+	content, err := os.ReadFile(testFile)
+	utils.NilError(t, err)
+	utils.Equal(t, string(content), "---\ntitle: Guide\n---\n# Guide\n\nIntro\n## Usage\n")
+}
+
+func TestEdit_AppendCreatesNewFile(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	output, err := tools.Edit(context.Background(), &tools.EditInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "changelog.md",
+		NewString:  "## v2\n",
+		Append:     true,
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, output.Metadata["error"], "")
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "changelog.md"))
+	utils.NilError(t, err)
+	utils.Equal(t, string(content), "## v2\n")
+}
+
+func TestEdit_AppendRejectsOldString(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	output, err := tools.Edit(context.Background(), &tools.EditInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "guide.md",
+		OldString:  "Intro",
+		NewString:  "## Usage\n",
+		Append:     true,
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, output.Metadata["error"], "format_error")
+	utils.Equal(t, output.Output, "Format error: old_string must be empty when appending or prepending")
+}
+
 func TestEdit_SimpleReplace(t *testing.T) {
 	tempDir := t.TempDir()
 	tools.SetListDirectoryBaseRoot(tempDir)
@@ -386,3 +452,58 @@ func TestEdit_IndentationFlexibleReplace(t *testing.T) {
 	utils.NilError(t, err)
 	utils.Equal(t, strings.Contains(string(result), "doSomethingElse()"), true)
 }
+
+func TestEdit_MaxFileSize(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+	tools.SetMaxFileSize(1024)
+	defer tools.SetMaxFileSize(0)
+
+	testFile := filepath.Join(tempDir, "guide.md")
+	original := strings.Repeat("a", 1020) + "TAIL"
+	utils.NilError(t, os.WriteFile(testFile, []byte(original), 0644))
+
+	output, err := tools.Edit(context.Background(), &tools.EditInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "guide.md",
+		OldString:  "TAIL",
+		NewString:  "END!",
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, output.Metadata["replaced"], "true")
+
+	output, err = tools.Edit(context.Background(), &tools.EditInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "guide.md",
+		OldString:  "END!",
+		NewString:  "END!!",
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, output.Metadata["error"], "format_error")
+	utils.Equal(t, strings.Contains(output.Output, "1025 bytes, over the 1024 byte limit"), true)
+	content, err := os.ReadFile(testFile)
+	utils.NilError(t, err)
+	utils.Equal(t, len(content), 1024)
+}
+
+func TestEdit_SymlinkEscapesBase(t *testing.T) {
+	tempDir := t.TempDir()
+	outside := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	secret := filepath.Join(outside, "secret.md")
+	utils.NilError(t, os.WriteFile(secret, []byte("secret"), 0644))
+	utils.NilError(t, os.Symlink(outside, filepath.Join(tempDir, "linked")))
+
+	output, err := tools.Edit(context.Background(), &tools.EditInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "linked/secret.md",
+		OldString:  "secret",
+		NewString:  "changed",
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, output.Metadata["error"], "format_error")
+	content, err := os.ReadFile(secret)
+	utils.NilError(t, err)
+	utils.Equal(t, string(content), "secret")
+}
@@ -0,0 +1,96 @@
+package unit_tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"narrabyte/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []events.ToolEvent
+}
+
+func (r *recordingSink) emit(_ context.Context, _ string, evt events.ToolEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, evt)
+}
+
+func (r *recordingSink) snapshot() []events.ToolEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]events.ToolEvent(nil), r.events...)
+}
+
+func TestCoalescer_MergesToolBurst(t *testing.T) {
+	sink := &recordingSink{}
+	c := events.NewCoalescer(sink.emit)
+	c.SetOptions(events.CoalesceOptions{Window: time.Hour})
+
+	ctx := context.Background()
+	for i := 0; i < 12; i++ {
+		c.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Read file", "read", "docs:a.md"))
+	}
+	c.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventError, "Read file", "read", "docs:b.md"))
+
+	got := sink.snapshot()
+	assert.Len(t, got, 2)
+	assert.Equal(t, events.EventError, got[1].Type)
+
+	c.Flush()
+	got = sink.snapshot()
+	assert.Len(t, got, 3)
+	assert.Equal(t, "Read file (11 more)", got[2].Message)
+	assert.Equal(t, "11", got[2].Metadata[events.CoalescedMetadataKey])
+	assert.Equal(t, "read", got[2].ToolName)
+}
+
+func TestCoalescer_DoneFlushesSessionFirst(t *testing.T) {
+	sink := &recordingSink{}
+	c := events.NewCoalescer(sink.emit)
+	c.SetOptions(events.CoalesceOptions{Window: time.Hour})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		evt := events.NewToolEvent(events.EventSuccess, "Glob", "glob", "docs:")
+		evt.SessionKey = "session-1"
+		c.Emit(ctx, events.LLMEventTool, evt)
+	}
+	done := events.NewError("LLM processing error")
+	done.SessionKey = "session-1"
+	c.Emit(ctx, events.LLMEventDone, done)
+
+	got := sink.snapshot()
+	assert.Len(t, got, 3)
+	assert.Equal(t, "Glob (2 more)", got[1].Message)
+	assert.Equal(t, "LLM processing error", got[2].Message)
+}
+
+func TestCoalescer_VerbosePassesEverything(t *testing.T) {
+	sink := &recordingSink{}
+	c := events.NewCoalescer(sink.emit)
+	c.SetOptions(events.CoalesceOptions{Window: time.Hour, Verbose: true})
+
+	for i := 0; i < 5; i++ {
+		c.Emit(context.Background(), events.LLMEventTool, events.NewSuccess("WriteFile: starting"))
+	}
+	assert.Len(t, sink.snapshot(), 5)
+}
+
+func TestCoalescer_WindowExpiry(t *testing.T) {
+	sink := &recordingSink{}
+	c := events.NewCoalescer(sink.emit)
+	c.SetOptions(events.CoalesceOptions{Window: 20 * time.Millisecond})
+
+	for i := 0; i < 4; i++ {
+		c.Emit(context.Background(), events.LLMEventTool, events.NewSuccess("WriteFile: starting"))
+	}
+	assert.Eventually(t, func() bool { return len(sink.snapshot()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "WriteFile: starting (3 more)", sink.snapshot()[1].Message)
+}
@@ -0,0 +1,54 @@
+package unit_tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"narrabyte/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_RoutesToSessionAndRedactsSecrets(t *testing.T) {
+	var received []events.ToolEvent
+	unsubscribe := events.Subscribe(events.EventFilter{SessionKey: "session-1"}, func(_ context.Context, _ string, evt events.ToolEvent) {
+		received = append(received, evt)
+	})
+	defer unsubscribe()
+
+	ctx := events.WithSession(context.Background(), "session-1")
+	logger := events.LoggerFrom(ctx).Redacting("sk-secret", "")
+	logger.Errorf("Error creating client: %v", fmt.Errorf("invalid key sk-secret"))
+	logger.Debugf("dropped while debug is disabled")
+
+	assert.Len(t, received, 1)
+	assert.Equal(t, events.EventError, received[0].Type)
+	assert.Equal(t, "Error creating client: invalid key [REDACTED]", received[0].Message)
+	assert.Equal(t, "session-1", received[0].SessionKey)
+}
+
+func TestLogger_InjectedThroughContext(t *testing.T) {
+	ctx := events.WithSession(context.Background(), "session-2")
+	logger := events.LoggerFrom(ctx).Redacting("token")
+	injected := events.WithLogger(context.Background(), logger)
+
+	assert.Same(t, logger, events.LoggerFrom(injected))
+	assert.NotSame(t, logger, events.LoggerFrom(ctx))
+}
+
+func TestLogger_WriterEmitsCompleteLines(t *testing.T) {
+	var messages []string
+	unsubscribe := events.Subscribe(events.EventFilter{SessionKey: "session-3"}, func(_ context.Context, _ string, evt events.ToolEvent) {
+		messages = append(messages, evt.Message)
+	})
+	defer unsubscribe()
+
+	w := events.LoggerFrom(events.WithSession(context.Background(), "session-3")).Writer(events.EventInfo)
+	_, _ = w.Write([]byte("first line\nsecond "))
+	assert.Equal(t, []string{"first line"}, messages)
+	_, _ = w.Write([]byte("line\r\n\npartial"))
+	assert.Equal(t, []string{"first line", "second line"}, messages)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, []string{"first line", "second line", "partial"}, messages)
+}
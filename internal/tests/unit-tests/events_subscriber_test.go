@@ -0,0 +1,103 @@
+package unit_tests
+
+import (
+	"context"
+	"testing"
+
+	"narrabyte/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe_FiltersByKindAndTool(t *testing.T) {
+	var received []events.ToolEvent
+	unsubscribe := events.Subscribe(events.EventFilter{
+		Kinds: []events.EventKind{events.EventKindTool},
+		Tools: []string{"read"},
+	}, func(_ context.Context, _ string, evt events.ToolEvent) {
+		received = append(received, evt)
+	})
+
+	ctx := events.WithSession(context.Background(), "session-1")
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo("ReadFile: starting"))
+	events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Write file", "write", "docs:a.md"))
+	events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Read file", "read", "docs:a.md"))
+
+	assert.Len(t, received, 1)
+	assert.Equal(t, "read", received[0].ToolName)
+	assert.Equal(t, events.EventKindTool, received[0].Kind)
+	assert.Equal(t, "session-1", received[0].SessionKey)
+
+	unsubscribe()
+	events.Emit(ctx, events.LLMEventTool, events.NewToolEvent(events.EventSuccess, "Read file", "read", "docs:b.md"))
+	assert.Len(t, received, 1)
+}
+
+func TestEventFilter_Matches(t *testing.T) {
+	evt := events.NewWarn("WriteFile(policy): policy violation - must read before write").WithTool(events.EventKindPolicy, "write")
+	evt.SessionKey = "session-1"
+
+	assert.True(t, events.EventFilter{}.Matches(events.LLMEventTool, evt))
+	assert.True(t, events.EventFilter{Kinds: []events.EventKind{events.EventKindPolicy}, SessionKey: "session-1"}.Matches(events.LLMEventTool, evt))
+	assert.False(t, events.EventFilter{SessionKey: "session-2"}.Matches(events.LLMEventTool, evt))
+	assert.False(t, events.EventFilter{Names: []string{events.LLMEventDone}}.Matches(events.LLMEventTool, evt))
+	assert.False(t, events.EventFilter{Kinds: []events.EventKind{events.EventKindLog}}.Matches(events.LLMEventTool, evt))
+}
+
+func TestEmit_DropsDebugUnlessEnabled(t *testing.T) {
+	var received int
+	unsubscribe := events.Subscribe(events.EventFilter{}, func(context.Context, string, events.ToolEvent) {
+		received++
+	})
+	defer unsubscribe()
+
+	events.Emit(context.Background(), events.LLMEventTool, events.NewDebug("history dump"))
+	assert.Equal(t, 0, received)
+
+	events.SetDebugEnabled(true)
+	defer events.SetDebugEnabled(false)
+	events.Emit(context.Background(), events.LLMEventTool, events.NewDebug("history dump"))
+	assert.Equal(t, 1, received)
+}
+
+func TestRecorder_KeepsMostRecentEvents(t *testing.T) {
+	recorder := events.NewRecorder(2)
+	ctx := events.WithRecorder(context.Background(), recorder)
+
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo("first"))
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo("second"))
+	reasoning := events.NewSuccess("thinking")
+	reasoning.Kind = events.EventKindReasoning
+	events.Emit(ctx, events.LLMEventTool, reasoning)
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo("third"))
+	events.Emit(context.Background(), events.LLMEventTool, events.NewInfo("not recorded"))
+
+	recorded := recorder.Drain()
+	assert.Len(t, recorded, 2)
+	assert.Equal(t, "second", recorded[0].Message)
+	assert.Equal(t, "third", recorded[1].Message)
+	assert.Empty(t, recorder.Drain())
+}
+
+func TestEmit_FlagsCompletionOfBackgroundSession(t *testing.T) {
+	var received []events.ToolEvent
+	unsubscribe := events.Subscribe(events.EventFilter{SessionKey: "session-1"}, func(_ context.Context, _ string, evt events.ToolEvent) {
+		received = append(received, evt)
+	})
+	defer unsubscribe()
+
+	inTab := true
+	ctx := events.WithTabCheck(events.WithSession(context.Background(), "session-1"), func() bool { return inTab })
+	events.Emit(ctx, events.LLMEventDone, events.NewSuccess("LLM processing complete"))
+
+	// The session is moved to the background while its next run is in progress
+	inTab = false
+	events.Emit(ctx, events.LLMEventTool, events.NewInfo("WriteFile: done"))
+	events.Emit(ctx, events.LLMEventDone, events.NewError("LLM processing error"))
+
+	if assert.Len(t, received, 3) {
+		assert.False(t, received[0].Background, "a tab-bound session is not background")
+		assert.False(t, received[1].Background, "only completion events are flagged")
+		assert.True(t, received[2].Background, "an unbound session's completion is background")
+	}
+}
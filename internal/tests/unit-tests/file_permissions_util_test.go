@@ -16,7 +16,7 @@ func newFileServiceWithRepoMock(findFunc func(ctx context.Context, id uint) (*mo
 	}
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
-	service := services.NewRepoLinkService(repoMock, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(repoMock, fumaTest, gitSvc, nil)
 	return &services.FilePermissionsUtil{RepoLinks: service}
 }
 
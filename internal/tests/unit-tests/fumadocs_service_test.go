@@ -1,10 +1,13 @@
 package unit_tests
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"narrabyte/internal/models"
 	"narrabyte/internal/services"
 )
 
@@ -94,3 +97,425 @@ func TestCreateFumadocsProject_Success(t *testing.T) {
 		t.Error("expected non-empty output from CreateFumadocsProject")
 	}
 }
+
+func writeDocsFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestReconcileMetaFiles_AppendsNewPage(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"docs/guides/meta.json":   "{\n  \"title\": \"Guides\",\n  \"pages\": [\"setup\", \"---Advanced---\", \"intro\"]\n}\n",
+		"docs/guides/intro.mdx":   "# Intro",
+		"docs/guides/setup.mdx":   "# Setup",
+		"docs/guides/plugins.mdx": "# Plugins",
+	})
+
+	updated, err := service.ReconcileMetaFiles(root, []models.DocChangedFile{
+		{Path: "docs/guides/plugins.mdx", Status: "untracked"},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileMetaFiles returned error: %v", err)
+	}
+	if len(updated) != 1 || updated[0].Path != "docs/guides/meta.json" {
+		t.Fatalf("expected docs/guides/meta.json to be updated, got %+v", updated)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "docs", "guides", "meta.json"))
+	if err != nil {
+		t.Fatalf("failed to read meta.json: %v", err)
+	}
+	expected := "{\n  \"title\": \"Guides\",\n  \"pages\": [\n    \"setup\",\n    \"---Advanced---\",\n    \"intro\",\n    \"plugins\"\n  ]\n}\n"
+	if string(data) != expected {
+		t.Fatalf("unexpected meta.json:\n%s", data)
+	}
+}
+
+func TestReconcileMetaFiles_KeepsOmittedPageUnlisted(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"docs/meta.json":     `{"pages": ["index"]}`,
+		"docs/index.mdx":     "# Home",
+		"docs/internal.mdx":  "# Left out of the sidebar on purpose",
+		"docs/changelog.mdx": "# Changelog",
+	})
+
+	updated, err := service.ReconcileMetaFiles(root, []models.DocChangedFile{
+		{Path: "docs/internal.mdx", Status: "modified"},
+		{Path: "docs/changelog.mdx", Status: "added"},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileMetaFiles returned error: %v", err)
+	}
+	if len(updated) != 1 || updated[0].Path != "docs/meta.json" {
+		t.Fatalf("expected docs/meta.json to be updated, got %+v", updated)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "docs", "meta.json"))
+	if err != nil {
+		t.Fatalf("failed to read meta.json: %v", err)
+	}
+	expected := "{\n  \"pages\": [\n    \"index\",\n    \"changelog\"\n  ]\n}\n"
+	if string(data) != expected {
+		t.Fatalf("unexpected meta.json:\n%s", data)
+	}
+}
+
+func TestReconcileMetaFiles_RemovesDeletedPage(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"docs/meta.json":         `{"pages": ["index", "legacy", "api", "[GitHub](https://github.com)"]}`,
+		"docs/index.mdx":         "# Home",
+		"docs/api/overview.mdx":  "# API",
+		"docs/reference/cli.mdx": "# CLI",
+	})
+
+	updated, err := service.ReconcileMetaFiles(root, []models.DocChangedFile{
+		{Path: "docs/legacy.mdx", Status: "deleted"},
+		{Path: "docs/reference/cli.mdx", Status: "modified"},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileMetaFiles returned error: %v", err)
+	}
+	if len(updated) != 1 || updated[0].Path != "docs/meta.json" {
+		t.Fatalf("expected only docs/meta.json to be updated, got %+v", updated)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "docs", "meta.json"))
+	if err != nil {
+		t.Fatalf("failed to read meta.json: %v", err)
+	}
+	expected := "{\n  \"pages\": [\n    \"index\",\n    \"api\",\n    \"[GitHub](https://github.com)\"\n  ]\n}\n"
+	if string(data) != expected {
+		t.Fatalf("unexpected meta.json:\n%s", data)
+	}
+}
+
+func TestReconcileMetaFiles_LeavesRestEntryAlone(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	original := `{"pages": ["index", "..."]}`
+	writeDocsFiles(t, root, map[string]string{
+		"docs/meta.json": original,
+		"docs/index.mdx": "# Home",
+		"docs/extra.mdx": "# Extra",
+	})
+
+	updated, err := service.ReconcileMetaFiles(root, []models.DocChangedFile{
+		{Path: "docs/extra.mdx", Status: "untracked"},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileMetaFiles returned error: %v", err)
+	}
+	if len(updated) != 0 {
+		t.Fatalf("expected no updates, got %+v", updated)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "docs", "meta.json"))
+	if string(data) != original {
+		t.Fatalf("meta.json should be unchanged, got %s", data)
+	}
+}
+
+func TestValidateFrontmatter_ReportsViolations(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"docs/ok.mdx":          "---\ntitle: Getting started\ndescription: First steps\n---\n\n# Hi",
+		"docs/no-title.mdx":    "---\ndescription: Missing\n---\n",
+		"docs/no-block.md":     "# Just a heading",
+		"docs/broken.mdx":      "---\ntitle: [unclosed\n---\n",
+		"docs/duplicate.mdx":   "---\ntitle: One\ntitle: Two\n---\n",
+		"docs/unterminated.md": "---\ntitle: Open\n",
+		"docs/meta.json":       `{"pages": []}`,
+	})
+
+	violations, err := service.ValidateFrontmatter(root, []models.DocChangedFile{
+		{Path: "docs/ok.mdx", Status: "untracked"},
+		{Path: "docs/no-title.mdx", Status: "modified"},
+		{Path: "docs/no-block.md", Status: "modified"},
+		{Path: "docs/broken.mdx", Status: "modified"},
+		{Path: "docs/duplicate.mdx", Status: "modified"},
+		{Path: "docs/unterminated.md", Status: "modified"},
+		{Path: "docs/meta.json", Status: "modified"},
+		{Path: "docs/removed.mdx", Status: "deleted"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateFrontmatter returned error: %v", err)
+	}
+
+	if _, ok := violations["docs/ok.mdx"]; ok {
+		t.Errorf("valid page should have no violations, got %v", violations["docs/ok.mdx"])
+	}
+	if _, ok := violations["docs/meta.json"]; ok {
+		t.Error("non-page files should not be validated")
+	}
+	expectations := map[string]string{
+		"docs/no-title.mdx":    "missing title",
+		"docs/no-block.md":     "missing frontmatter with a title",
+		"docs/duplicate.mdx":   `duplicate key "title"`,
+		"docs/unterminated.md": "malformed YAML: frontmatter is not closed with ---",
+	}
+	for path, want := range expectations {
+		got := violations[path]
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("%s: expected [%s], got %v", path, want, got)
+		}
+	}
+	if got := violations["docs/broken.mdx"]; len(got) != 1 || !strings.HasPrefix(got[0], "malformed YAML:") {
+		t.Errorf("docs/broken.mdx: expected malformed YAML violation, got %v", got)
+	}
+	if len(violations) != 5 {
+		t.Errorf("expected 5 files with violations, got %d: %v", len(violations), violations)
+	}
+}
+
+func TestBuildRouteTree_NestedFoldersAndIndexPages(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"content/docs/meta.json":                `{"title": "Handbook", "pages": ["index", "---Guides---", "guides", "[GitHub](https://github.com/acme)", "..."]}`,
+		"content/docs/index.mdx":                "---\ntitle: Welcome\n---\n",
+		"content/docs/faq.mdx":                  "---\ntitle: FAQ\n---\n",
+		"content/docs/guides/index.mdx":         "---\ntitle: Guides overview\n---\n",
+		"content/docs/guides/setup.mdx":         "---\ntitle: Setup\n---\n",
+		"content/docs/guides/advanced/hooks.md": "# Hooks without frontmatter",
+		"content/docs/api/meta.json":            `{"title": "API Reference"}`,
+		"content/docs/api/client.mdx":           "---\ntitle: Client\n---\n",
+		"content/docs/empty/notes.txt":          "not a page",
+	})
+
+	tree, err := service.BuildRouteTree(root)
+	if err != nil {
+		t.Fatalf("BuildRouteTree returned error: %v", err)
+	}
+	if tree == nil {
+		t.Fatal("expected a route tree for a Fumadocs project")
+	}
+	if tree.Type != services.RouteNodeRoot || tree.Title != "Handbook" {
+		t.Fatalf("unexpected root: %+v", tree)
+	}
+
+	var got []string
+	for _, child := range tree.Children {
+		got = append(got, child.Type+":"+child.Title+":"+child.URL)
+	}
+	expected := []string{
+		"page:Welcome:/",
+		"separator:Guides:",
+		"folder:Guides overview:/guides",
+		"link:GitHub:https://github.com/acme",
+		"folder:API Reference:",
+		"page:FAQ:/faq",
+	}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Fatalf("unexpected root children:\n got %v\nwant %v", got, expected)
+	}
+
+	guides := tree.Children[2]
+	if len(guides.Children) != 2 {
+		t.Fatalf("expected guides to have 2 children, got %+v", guides.Children)
+	}
+	advanced := guides.Children[0]
+	if advanced.Type != services.RouteNodeFolder || advanced.Title != "Advanced" || len(advanced.Children) != 1 {
+		t.Fatalf("unexpected nested folder: %+v", advanced)
+	}
+	if hooks := advanced.Children[0]; hooks.Title != "Hooks" || hooks.URL != "/guides/advanced/hooks" || hooks.Path != "guides/advanced/hooks.md" {
+		t.Fatalf("unexpected nested page: %+v", hooks)
+	}
+	if setup := guides.Children[1]; setup.Title != "Setup" || setup.URL != "/guides/setup" {
+		t.Fatalf("unexpected guides page: %+v", setup)
+	}
+}
+
+func TestBuildRouteTree_NonFumadocsProject(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"README.md": "# Plain docs",
+	})
+
+	tree, err := service.BuildRouteTree(root)
+	if err != nil {
+		t.Fatalf("BuildRouteTree returned error: %v", err)
+	}
+	if tree != nil {
+		t.Fatalf("expected no route tree, got %+v", tree)
+	}
+}
+
+func TestLintMDXComponents_FlagsUnknownComponents(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	page := strings.Join([]string{
+		"---",
+		"title: Install",
+		"---",
+		"import { Tabs, Tab as Pane } from 'fumadocs-ui/components/tabs';",
+		"import {",
+		"  Step,",
+		"  Steps,",
+		"} from 'fumadocs-ui/components/steps';",
+		"",
+		"export const Note = ({ children }) => <div>{children}</div>;",
+		"",
+		"<Callout>Default component</Callout>",
+		"<Tabs items={['npm']}><Pane value=\"npm\">npm i</Pane></Tabs>",
+		"<Steps><Step>One</Step></Steps>",
+		"<Note>Local</Note>",
+		"<Accordion title=\"More\">",
+		"Use `<Banner>` in code spans without a warning.",
+		"```tsx",
+		"<Banner />",
+		"```",
+		"<Banner variant=\"rainbow\" />",
+		"<TypeTable />",
+		"<Accordion title=\"Again\" />",
+	}, "\n")
+	writeDocsFiles(t, root, map[string]string{
+		"docs/install.mdx": page,
+		"docs/plain.md":    "<Unknown />",
+	})
+
+	files := []models.DocChangedFile{
+		{Path: "docs/install.mdx", Status: "untracked"},
+		{Path: "docs/plain.md", Status: "modified"},
+	}
+	warnings, err := service.LintMDXComponents(root, files, []string{"TypeTable"})
+	if err != nil {
+		t.Fatalf("LintMDXComponents returned error: %v", err)
+	}
+
+	var got []string
+	for _, w := range warnings {
+		got = append(got, fmt.Sprintf("%s:%d:%s", w.Path, w.Line, w.Component))
+	}
+	expected := []string{"docs/install.mdx:16:Accordion", "docs/install.mdx:21:Banner"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Fatalf("unexpected warnings:\n got %v\nwant %v", got, expected)
+	}
+}
+
+func TestParseMDXComponentList(t *testing.T) {
+	got := services.ParseMDXComponentList(" Banner, TypeTable\nBanner  Mermaid,,")
+	expected := []string{"Banner", "TypeTable", "Mermaid"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestDetectContentRoot_AppRouterLayout(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"source.config.ts":                   "import { defineDocs } from 'fumadocs-mdx/config';\n\nexport const docs = defineDocs({\n  dir: 'content/docs',\n});\n",
+		"app/docs/[[...slug]]/page.tsx":      "export default function Page() {}",
+		"content/docs/meta.json":             `{"pages": ["index"]}`,
+		"content/docs/index.mdx":             "---\ntitle: Welcome\n---\n",
+		"node_modules/pkg/content/docs/a.md": "ignored",
+	})
+
+	detection, err := service.DetectContentRoot(root)
+	if err != nil {
+		t.Fatalf("DetectContentRoot returned error: %v", err)
+	}
+	if detection.Best != "content/docs" || detection.Confidence != 1 || detection.Ambiguous {
+		t.Fatalf("unexpected detection: %+v", detection)
+	}
+	if len(detection.Candidates) != 1 || len(detection.Candidates[0].Reasons) != 5 {
+		t.Fatalf("expected one candidate backed by every kind of evidence, got %+v", detection.Candidates)
+	}
+}
+
+func TestDetectContentRoot_PagesRouterLayoutWithSrcDir(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"src/pages/docs/[[...slug]].tsx":       "export default function Page() {}",
+		"src/content/docs/getting-started.mdx": "---\ntitle: Getting started\n---\n",
+		"docs/README.md":                       "# Not Fumadocs",
+	})
+
+	detection, err := service.DetectContentRoot(root)
+	if err != nil {
+		t.Fatalf("DetectContentRoot returned error: %v", err)
+	}
+	if detection.Best != "src/content/docs" || detection.Ambiguous {
+		t.Fatalf("unexpected detection: %+v", detection)
+	}
+	if detection.Confidence != 0.55 {
+		t.Fatalf("expected the conventional location, pages and route as evidence, got %v", detection.Confidence)
+	}
+}
+
+func TestDetectContentRoot_CustomDirFromSourceConfig(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"apps/site/source.config.mts":                 "export const docs = defineDocs({ dir: \"handbook\" });\n",
+		"apps/site/src/app/docs/[[...slug]]/page.tsx": "export default function Page() {}",
+		"apps/site/handbook/index.mdx":                "---\ntitle: Handbook\n---\n",
+		"apps/legacy/content/docs/old.md":             "# Old",
+	})
+
+	detection, err := service.DetectContentRoot(root)
+	if err != nil {
+		t.Fatalf("DetectContentRoot returned error: %v", err)
+	}
+	if detection.Best != "apps/site/handbook" || detection.Ambiguous {
+		t.Fatalf("unexpected detection: %+v", detection)
+	}
+	if len(detection.Candidates) != 2 || detection.Candidates[1].Path != "apps/legacy/content/docs" {
+		t.Fatalf("expected the legacy content directory as a second candidate, got %+v", detection.Candidates)
+	}
+}
+
+func TestDetectContentRoot_ReturnsAllCandidatesWhenAmbiguous(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{
+		"apps/docs/content/docs/meta.json":  `{"pages": []}`,
+		"apps/docs/content/docs/index.mdx":  "# Docs",
+		"apps/guide/content/docs/meta.json": `{"pages": []}`,
+		"apps/guide/content/docs/index.mdx": "# Guide",
+	})
+
+	detection, err := service.DetectContentRoot(root)
+	if err != nil {
+		t.Fatalf("DetectContentRoot returned error: %v", err)
+	}
+	if !detection.Ambiguous || len(detection.Candidates) != 2 {
+		t.Fatalf("expected an ambiguous detection with two candidates, got %+v", detection)
+	}
+	if detection.Best != "apps/docs/content/docs" || detection.Candidates[1].Path != "apps/guide/content/docs" {
+		t.Fatalf("expected candidates ordered by path on a tie, got %+v", detection.Candidates)
+	}
+}
+
+func TestDetectContentRoot_NonFumadocsRepository(t *testing.T) {
+	service := services.NewFumadocsService()
+	root := t.TempDir()
+	writeDocsFiles(t, root, map[string]string{"README.md": "# Plain docs"})
+
+	detection, err := service.DetectContentRoot(root)
+	if err != nil {
+		t.Fatalf("DetectContentRoot returned error: %v", err)
+	}
+	if detection.Best != "" || len(detection.Candidates) != 0 {
+		t.Fatalf("expected no candidates, got %+v", detection)
+	}
+	if _, err := service.DetectContentRoot(filepath.Join(root, "missing")); err == nil {
+		t.Fatal("expected an error for a missing path")
+	}
+}
@@ -0,0 +1,96 @@
+package unit_tests
+
+import (
+	"context"
+	"testing"
+
+	"narrabyte/internal/models"
+	"narrabyte/internal/services"
+	"narrabyte/internal/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newGenerationProfileService(t *testing.T, repo *mocks.GenerationProfileRepositoryMock, templateRepo *mocks.TemplateRepositoryMock) services.GenerationProfileService {
+	t.Helper()
+	ctx := context.Background()
+	modelConfigs := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, modelConfigs.Startup(ctx))
+	templates := services.NewTemplateService(templateRepo)
+	templates.Startup(ctx)
+	service := services.NewGenerationProfileService(repo, templates, modelConfigs)
+	service.Startup(ctx)
+	return service
+}
+
+func TestGenerationProfileService_CreateProfile_NormalizesFields(t *testing.T) {
+	var stored *models.GenerationProfile
+	repo := &mocks.GenerationProfileRepositoryMock{
+		CreateFunc: func(ctx context.Context, profile *models.GenerationProfile) error {
+			profile.ID = 9
+			stored = profile
+			return nil
+		},
+	}
+	templates := &mocks.TemplateRepositoryMock{
+		GetFunc: func(ctx context.Context, id uint) (*models.Template, error) {
+			return &models.Template{ID: id, Name: "Guide", Content: "Write a guide"}, nil
+		},
+	}
+	service := newGenerationProfileService(t, repo, templates)
+
+	result, err := service.CreateProfile(&models.GenerationProfile{
+		ProjectID:        1,
+		Name:             "  Release notes ",
+		ModelKey:         models.DefaultModelKeyValue,
+		TemplateID:       2,
+		ExcludedPatterns: " *.sql, ./migrations/** ,*.sql,",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), result.ID)
+	assert.Same(t, stored, result)
+	assert.Equal(t, "Release notes", result.Name)
+	assert.Equal(t, "*.sql,migrations/**", result.ExcludedPatterns)
+}
+
+func TestGenerationProfileService_CreateProfile_RejectsInvalidProfiles(t *testing.T) {
+	repo := &mocks.GenerationProfileRepositoryMock{
+		CreateFunc: func(ctx context.Context, profile *models.GenerationProfile) error {
+			t.Fatalf("invalid profile %q was stored", profile.Name)
+			return nil
+		},
+	}
+	templates := &mocks.TemplateRepositoryMock{
+		GetFunc: func(ctx context.Context, id uint) (*models.Template, error) {
+			return nil, assert.AnError
+		},
+	}
+	service := newGenerationProfileService(t, repo, templates)
+
+	cases := map[string]*models.GenerationProfile{
+		"project id is required":      {Name: "p"},
+		"profile name is required":    {ProjectID: 1, Name: " "},
+		"invalid excluded pattern":    {ProjectID: 1, Name: "p", ExcludedPatterns: "src/[a"},
+		"model openai:nope is not":    {ProjectID: 1, Name: "p", ModelKey: "openai:nope"},
+		`profile "p": template 4 not`: {ProjectID: 1, Name: "p", TemplateID: 4},
+	}
+	for want, profile := range cases {
+		_, err := service.CreateProfile(profile)
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestGenerationProfileService_ListProfiles(t *testing.T) {
+	repo := &mocks.GenerationProfileRepositoryMock{
+		ListByProjectFunc: func(ctx context.Context, projectID uint) ([]*models.GenerationProfile, error) {
+			return []*models.GenerationProfile{{ID: 1, ProjectID: projectID, Name: "Default"}}, nil
+		},
+	}
+	service := newGenerationProfileService(t, repo, &mocks.TemplateRepositoryMock{})
+
+	list, err := service.ListProfiles(3)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, uint(3), list[0].ProjectID)
+	}
+}
@@ -3,6 +3,7 @@ package unit_tests
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"narrabyte/internal/models"
@@ -207,3 +208,49 @@ func TestGenerationSessionService_DeleteAll_Delegates(t *testing.T) {
 		t.Fatalf("repository not called")
 	}
 }
+
+func TestGenerationSessionService_AppendEventLog_CapsEntries(t *testing.T) {
+	stored := &models.GenerationSession{ID: 5}
+	repo := &mocks.GenerationSessionRepositoryMock{}
+	repo.GetByIDFunc = func(id uint) (*models.GenerationSession, error) {
+		return stored, nil
+	}
+	repo.UpdateByIDFunc = func(id uint, updates map[string]interface{}) error {
+		utils.Equal(t, id, uint(5))
+		stored.EventLogJSON = updates["event_log_json"].(string)
+		return nil
+	}
+
+	svc := services.NewGenerationSessionService(repo)
+	svc.Startup(context.Background())
+
+	entries := make([]models.SessionEventLogEntry, services.MaxSessionEventLogEntries)
+	for i := range entries {
+		entries[i] = models.SessionEventLogEntry{Type: "success", Kind: "tool", ToolName: "read", Message: fmt.Sprintf("Read file %d", i)}
+	}
+	utils.NilError(t, svc.AppendEventLog(5, entries))
+	utils.NilError(t, svc.AppendEventLog(5, []models.SessionEventLogEntry{{Type: "info", Kind: "log", Message: "LLM processing complete"}}))
+
+	log, err := svc.GetEventLog(5)
+	utils.NilError(t, err)
+	utils.Equal(t, len(log), services.MaxSessionEventLogEntries)
+	utils.Equal(t, log[0].Message, "Read file 1")
+	utils.Equal(t, log[len(log)-1].Message, "LLM processing complete")
+}
+
+func TestGenerationSessionService_GetEventLog_Empty(t *testing.T) {
+	repo := &mocks.GenerationSessionRepositoryMock{}
+	repo.GetByIDFunc = func(id uint) (*models.GenerationSession, error) {
+		return &models.GenerationSession{ID: id}, nil
+	}
+
+	svc := services.NewGenerationSessionService(repo)
+	svc.Startup(context.Background())
+
+	log, err := svc.GetEventLog(9)
+	utils.NilError(t, err)
+	utils.Equal(t, len(log), 0)
+
+	_, err = svc.GetEventLog(0)
+	utils.Equal(t, err.Error(), "session ID is required")
+}
@@ -7,9 +7,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"narrabyte/internal/llm/tools"
 	"narrabyte/internal/utils"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 func TestGrep_NilInput(t *testing.T) {
@@ -536,3 +540,48 @@ func TestGrep_CaseInsensitive(t *testing.T) {
 	utils.Equal(t, strings.Contains(result.Output, "Line 1: Hello World"), true)
 	utils.Equal(t, strings.Contains(result.Output, "Line 2: hello world"), true)
 }
+
+func TestGrep_PublishedDocsSnapshot(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	utils.NilError(t, err)
+	docsDir := filepath.Join(root, "docs")
+	utils.NilError(t, os.MkdirAll(docsDir, 0o755))
+	utils.NilError(t, os.WriteFile(filepath.Join(docsDir, "guide.md"), []byte("# Old heading\n"), 0o644))
+	wt, err := repo.Worktree()
+	utils.NilError(t, err)
+	_, err = wt.Add("docs/guide.md")
+	utils.NilError(t, err)
+	hash, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	utils.NilError(t, err)
+	commit, err := repo.CommitObject(hash)
+	utils.NilError(t, err)
+
+	// In-progress edit in the workspace
+	utils.NilError(t, os.WriteFile(filepath.Join(docsDir, "guide.md"), []byte("# New heading\n"), 0o644))
+
+	sessionID := "grep-published-docs"
+	ctx := tools.ContextWithSession(context.Background(), sessionID)
+	defer tools.ClearSession(sessionID)
+	tools.SetDocsRootForSession(sessionID, docsDir)
+
+	result, err := tools.Grep(ctx, &tools.GrepInput{Repository: tools.RepositoryDocs, Pattern: "heading", Published: true})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["error"], "format_error")
+
+	snapshot, err := tools.NewGitSnapshot(repo, commit, root, "main")
+	utils.NilError(t, err)
+	tools.SetDocsSnapshotForSession(sessionID, snapshot)
+
+	result, err = tools.Grep(ctx, &tools.GrepInput{Repository: tools.RepositoryDocs, Pattern: "heading", Published: true})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["matches"], "1")
+	utils.Equal(t, strings.Contains(result.Output, "in the published docs branch"), true)
+	utils.Equal(t, strings.Contains(result.Output, "Line 1: # Old heading"), true)
+
+	result, err = tools.Grep(ctx, &tools.GrepInput{Repository: tools.RepositoryDocs, Pattern: "heading"})
+	utils.NilError(t, err)
+	utils.Equal(t, strings.Contains(result.Output, "Line 1: # New heading"), true)
+}
@@ -0,0 +1,90 @@
+package unit_tests
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"narrabyte/internal/llm/tools"
+	"narrabyte/internal/utils"
+)
+
+func inspectSession(t *testing.T, name string) (context.Context, string) {
+	t.Helper()
+	docsRoot := t.TempDir()
+	sessionID := "inspect-test-" + name
+	tools.SetDocsRootForSession(sessionID, docsRoot)
+	t.Cleanup(func() { tools.ClearSession(sessionID) })
+	return tools.ContextWithSession(context.Background(), sessionID), docsRoot
+}
+
+func TestInspectAsset_PNG(t *testing.T) {
+	ctx, docsRoot := inspectSession(t, "png")
+	f, err := os.Create(filepath.Join(docsRoot, "diagram.png"))
+	utils.NilError(t, err)
+	utils.NilError(t, png.Encode(f, image.NewRGBA(image.Rect(0, 0, 64, 32))))
+	utils.NilError(t, f.Close())
+	st, err := os.Stat(filepath.Join(docsRoot, "diagram.png"))
+	utils.NilError(t, err)
+
+	out, err := tools.InspectAsset(ctx, &tools.InspectAssetInput{Repository: tools.RepositoryDocs, FilePath: "diagram.png"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["type"], "image/png")
+	utils.Equal(t, out.Metadata["width"], "64")
+	utils.Equal(t, out.Metadata["height"], "32")
+	utils.Equal(t, out.Metadata["size_bytes"], strconv.FormatInt(st.Size(), 10))
+}
+
+func TestInspectAsset_SVGViewBox(t *testing.T) {
+	ctx, docsRoot := inspectSession(t, "svg")
+	svg := `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="100%" viewBox="0 0 120.5 48"><rect/></svg>`
+	utils.NilError(t, os.WriteFile(filepath.Join(docsRoot, "logo.svg"), []byte(svg), 0o644))
+
+	out, err := tools.InspectAsset(ctx, &tools.InspectAssetInput{Repository: tools.RepositoryDocs, FilePath: "logo.svg"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["type"], "image/svg+xml")
+	utils.Equal(t, out.Metadata["width"], "120.5")
+	utils.Equal(t, out.Metadata["height"], "48")
+}
+
+func TestInspectAsset_WebPLossless(t *testing.T) {
+	ctx, docsRoot := inspectSession(t, "webp")
+	// 300x200 VP8L header: 14-bit width-1 and height-1 packed little endian
+	bits := uint32(299) | uint32(199)<<14
+	data := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f")
+	data = append(data, byte(bits), byte(bits>>8), byte(bits>>16), byte(bits>>24))
+	data = append(data, make([]byte, 8)...)
+	utils.NilError(t, os.WriteFile(filepath.Join(docsRoot, "shot.webp"), data, 0o644))
+
+	out, err := tools.InspectAsset(ctx, &tools.InspectAssetInput{Repository: tools.RepositoryDocs, FilePath: "shot.webp"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["type"], "image/webp")
+	utils.Equal(t, out.Metadata["width"], "300")
+	utils.Equal(t, out.Metadata["height"], "200")
+}
+
+func TestInspectAsset_NonImageReportsTypeOnly(t *testing.T) {
+	ctx, docsRoot := inspectSession(t, "text")
+	utils.NilError(t, os.WriteFile(filepath.Join(docsRoot, "notes.md"), []byte("# Notes\n"), 0o644))
+
+	out, err := tools.InspectAsset(ctx, &tools.InspectAssetInput{Repository: tools.RepositoryDocs, FilePath: "notes.md"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["type"], "text/plain; charset=utf-8")
+	utils.Equal(t, out.Metadata["size_bytes"], "8")
+	if _, ok := out.Metadata["width"]; ok {
+		t.Fatalf("expected no dimensions for a text file, got %v", out.Metadata)
+	}
+}
+
+func TestInspectAsset_MissingFile(t *testing.T) {
+	ctx, _ := inspectSession(t, "missing")
+
+	out, err := tools.InspectAsset(ctx, &tools.InspectAssetInput{Repository: tools.RepositoryDocs, FilePath: "missing.png"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["error"], "not_found")
+}
@@ -0,0 +1,151 @@
+package unit_tests
+
+import (
+	"testing"
+
+	"narrabyte/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+func newMockKeyringService(t *testing.T) *services.KeyringService {
+	t.Helper()
+	keyring.MockInit()
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	t.Setenv("AppData", configDir)
+	return services.NewKeyringService()
+}
+
+func TestKeyringService_ListStoredProviders(t *testing.T) {
+	service := newMockKeyringService(t)
+
+	assert.Empty(t, service.ListStoredProviders())
+	assert.NoError(t, service.StoreApiKey("openai", []byte("sk-test")))
+	assert.NoError(t, service.StoreApiKey("anthropic", []byte("sk-ant-test")))
+
+	assert.ElementsMatch(t, []string{"openai", "anthropic"}, service.ListStoredProviders())
+	assert.True(t, service.HasApiKey("openai"))
+	assert.False(t, service.HasApiKey("gemini"))
+	assert.False(t, service.HasApiKey(""))
+}
+
+func TestKeyringService_DeleteApiKey_Idempotent(t *testing.T) {
+	service := newMockKeyringService(t)
+
+	assert.NoError(t, service.StoreApiKey("openai", []byte("sk-test")))
+	assert.NoError(t, service.DeleteApiKey("openai"))
+	assert.False(t, service.HasApiKey("openai"))
+	assert.Empty(t, service.ListStoredProviders())
+
+	assert.NoError(t, service.DeleteApiKey("openai"))
+	assert.Error(t, service.DeleteApiKey(""))
+}
+
+func TestKeyringService_ApiKeyFingerprint(t *testing.T) {
+	service := newMockKeyringService(t)
+
+	assert.NoError(t, service.StoreApiKey("openai", []byte("sk-first-key")))
+	first, err := service.ApiKeyFingerprint("openai")
+	assert.NoError(t, err)
+	assert.Len(t, first.Fingerprint, 8)
+	assert.Equal(t, len("sk-first-key"), first.Length)
+	assert.NotContains(t, first.Fingerprint, "sk-")
+
+	again, err := service.ApiKeyFingerprint("openai")
+	assert.NoError(t, err)
+	assert.Equal(t, first.Fingerprint, again.Fingerprint)
+
+	assert.NoError(t, service.StoreApiKey("openai", []byte("sk-second-key")))
+	changed, err := service.ApiKeyFingerprint("openai")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Fingerprint, changed.Fingerprint)
+
+	_, err = service.ApiKeyFingerprint("gemini")
+	assert.Error(t, err)
+}
+
+func TestKeyringService_SetApiKey_Validation(t *testing.T) {
+	cases := []struct {
+		name        string
+		provider    string
+		key         string
+		warningCode string
+	}{
+		{"anthropic ok", "anthropic", "sk-ant-REDACTED", ""},
+		{"openai ok", "openai", "sk-proj-abcdefghijklmnopqrstuvwxyz", ""},
+		{"gemini ok", "gemini", "AIzaSyAbcdefghijklmnopqrstuvwxyz012345", ""},
+		{"anthropic key in openai slot", "openai", "sk-ant-REDACTED", services.ApiKeyWarningOtherProvider},
+		{"openai key in anthropic slot", "anthropic", "sk-proj-abcdefghijklmnopqrstuvwxyz", services.ApiKeyWarningOtherProvider},
+		{"unexpected gemini shape", "gemini", "not-a-google-key-at-all-abcdefghij", services.ApiKeyWarningUnexpectedFormat},
+		{"trailing newline", "openai", "sk-proj-abcdefghijklmnopqrstuvwxyz\n", services.ApiKeyWarningWhitespace},
+		{"unknown provider", "mistral", "anything-goes", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := newMockKeyringService(t)
+
+			result, err := service.SetApiKey(tc.provider, tc.key, false)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.warningCode, result.WarningCode)
+			assert.Equal(t, tc.warningCode == "", result.Stored)
+			assert.Equal(t, tc.warningCode == "", service.HasApiKey(tc.provider))
+		})
+	}
+}
+
+func TestKeyringService_SetApiKey_Override(t *testing.T) {
+	service := newMockKeyringService(t)
+
+	result, err := service.SetApiKey("anthropic", "sk-proj-abcdefghijklmnopqrstuvwxyz", true)
+	assert.NoError(t, err)
+	assert.True(t, result.Stored)
+	assert.Equal(t, services.ApiKeyWarningOtherProvider, result.WarningCode)
+	assert.True(t, service.HasApiKey("anthropic"))
+}
+
+func TestKeyringService_ResolveApiKey_PrefersProjectKey(t *testing.T) {
+	service := newMockKeyringService(t)
+
+	assert.NoError(t, service.StoreApiKey("anthropic", []byte("global-key")))
+	assert.NoError(t, service.SetProjectApiKey("anthropic", 7, []byte("project-key")))
+
+	key, err := service.ResolveApiKey("anthropic", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "project-key", key)
+
+	key, err = service.ResolveApiKey("anthropic", 8)
+	assert.NoError(t, err)
+	assert.Equal(t, "global-key", key)
+
+	key, err = service.ResolveApiKey("anthropic", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "global-key", key)
+
+	assert.Equal(t, []string{"anthropic"}, service.ListStoredProviders())
+
+	assert.NoError(t, service.DeleteProjectApiKey("anthropic", 7))
+	key, err = service.ResolveApiKey("anthropic", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "global-key", key)
+
+	_, err = service.GetProjectApiKey("anthropic", 7)
+	assert.Error(t, err)
+}
+
+func TestKeyringService_ResolveApiKey_ProjectKeyWithoutGlobal(t *testing.T) {
+	service := newMockKeyringService(t)
+
+	assert.NoError(t, service.SetProjectApiKey("openai", 3, []byte("project-only")))
+
+	key, err := service.ResolveApiKey("openai", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "project-only", key)
+
+	_, err = service.ResolveApiKey("openai", 4)
+	assert.Error(t, err)
+	assert.Error(t, service.SetProjectApiKey("openai", 0, []byte("key")))
+}
@@ -7,9 +7,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"narrabyte/internal/llm/tools"
 	"narrabyte/internal/utils"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 func TestListDirectory_NilInput(t *testing.T) {
@@ -461,6 +465,44 @@ func TestListDirectory_ScopedIgnores(t *testing.T) {
 	}
 }
 
+func TestListDirectory_NarrabyteIgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	tools.SetListDirectoryBaseRoot(root)
+
+	utils.NilError(t, os.MkdirAll(filepath.Join(root, "archive"), 0o755))
+	utils.NilError(t, os.WriteFile(filepath.Join(root, "archive", "old.md"), []byte("content"), 0o644))
+	utils.NilError(t, os.WriteFile(filepath.Join(root, "manual.pdf"), []byte("content"), 0o644))
+	utils.NilError(t, os.WriteFile(filepath.Join(root, "guide.md"), []byte("content"), 0o644))
+	ignoreFile := "# hidden from the agent\n/archive/\n*.pdf\n!keep.pdf\n"
+	utils.NilError(t, os.WriteFile(filepath.Join(root, tools.IgnoreFileName), []byte(ignoreFile), 0o644))
+
+	patterns, err := tools.LoadIgnoreFile(root)
+	utils.NilError(t, err)
+	utils.Equal(t, strings.Join(patterns, ","), "archive/,*.pdf")
+
+	tools.SetRepositoryIgnorePatternsForSession("", tools.RepositoryDocs, patterns)
+	t.Cleanup(func() {
+		tools.SetRepositoryIgnorePatternsForSession("", tools.RepositoryDocs, nil)
+	})
+
+	docs, err := tools.ListDirectory(context.Background(), &tools.ListLSInput{Repository: tools.RepositoryDocs, Path: "."})
+	utils.NilError(t, err)
+	utils.Equal(t, strings.Contains(docs.Output, "archive"), false)
+	utils.Equal(t, strings.Contains(docs.Output, "manual.pdf"), false)
+	utils.Equal(t, strings.Contains(docs.Output, "guide.md"), true)
+
+	// Patterns are per repository: the code repository still sees everything
+	code, err := tools.ListDirectory(context.Background(), &tools.ListLSInput{Repository: tools.RepositoryCode, Path: "."})
+	utils.NilError(t, err)
+	utils.Equal(t, strings.Contains(code.Output, "archive"), true)
+}
+
+func TestLoadIgnoreFile_MissingFile(t *testing.T) {
+	patterns, err := tools.LoadIgnoreFile(t.TempDir())
+	utils.NilError(t, err)
+	utils.Equal(t, len(patterns), 0)
+}
+
 func TestListDirectory_UnicodeNames(t *testing.T) {
 	tempDir := t.TempDir()
 	tools.SetListDirectoryBaseRoot(tempDir)
@@ -503,3 +545,99 @@ func TestListDirectory_Symlinks(t *testing.T) {
 	utils.Equal(t, strings.Contains(result.Output, "real.txt"), true)
 	utils.Equal(t, strings.Contains(result.Output, "link.txt"), true)
 }
+
+func TestListDirectory_TreeDepth(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	utils.NilError(t, os.MkdirAll(filepath.Join(tempDir, "a", "b", "c"), 0755))
+	utils.NilError(t, os.MkdirAll(filepath.Join(tempDir, "node_modules", "pkg"), 0755))
+	utils.NilError(t, os.WriteFile(filepath.Join(tempDir, "root.md"), []byte("x"), 0644))
+	utils.NilError(t, os.WriteFile(filepath.Join(tempDir, "a", "one.md"), []byte("x"), 0644))
+	utils.NilError(t, os.WriteFile(filepath.Join(tempDir, "a", "b", "two.md"), []byte("x"), 0644))
+	utils.NilError(t, os.WriteFile(filepath.Join(tempDir, "a", "b", "c", "three.md"), []byte("x"), 0644))
+
+	result, err := tools.ListDirectory(context.Background(), &tools.ListLSInput{
+		Repository: tools.RepositoryDocs,
+		Path:       ".",
+		Depth:      2,
+		Tree:       true,
+	})
+	utils.NilError(t, err)
+	want := strings.Join([]string{
+		"├── a/",
+		"│   ├── b/",
+		"│   └── one.md",
+		"└── root.md",
+	}, "\n")
+	utils.Equal(t, strings.Contains(result.Output, want), true)
+	utils.Equal(t, strings.Contains(result.Output, "two.md"), false)
+	utils.Equal(t, strings.Contains(result.Output, "node_modules"), false)
+	utils.Equal(t, result.Metadata["entries_count"], "4")
+	utils.Equal(t, result.Metadata["limited"], "false")
+}
+
+func TestListDirectory_TreeTruncates(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	for i := 0; i < 310; i++ {
+		utils.NilError(t, os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("file%03d.txt", i)), []byte("x"), 0644))
+	}
+
+	result, err := tools.ListDirectory(context.Background(), &tools.ListLSInput{
+		Repository: tools.RepositoryDocs,
+		Depth:      1,
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["entries_count"], "300")
+	utils.Equal(t, result.Metadata["limited"], "true")
+	utils.Equal(t, strings.Contains(result.Output, "(truncated after 300 entries"), true)
+	utils.Equal(t, strings.Contains(result.Output, "  file299.txt"), true)
+	utils.Equal(t, strings.Contains(result.Output, "file300.txt"), false)
+}
+
+func TestListDirectory_TreeFromSnapshot(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	utils.NilError(t, err)
+	utils.NilError(t, os.MkdirAll(filepath.Join(root, "internal", "deep"), 0o755))
+	utils.NilError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644))
+	utils.NilError(t, os.WriteFile(filepath.Join(root, "internal", "deep", "x.go"), []byte("package deep\n"), 0o644))
+	wt, err := repo.Worktree()
+	utils.NilError(t, err)
+	_, err = wt.Add(".")
+	utils.NilError(t, err)
+	hash, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	utils.NilError(t, err)
+	commit, err := repo.CommitObject(hash)
+	utils.NilError(t, err)
+
+	// Uncommitted files must not show up in snapshot listings
+	utils.NilError(t, os.WriteFile(filepath.Join(root, "scratch.go"), []byte("package main\n"), 0o644))
+
+	sessionID := "list-tree-snapshot"
+	ctx := tools.ContextWithSession(context.Background(), sessionID)
+	defer tools.ClearSession(sessionID)
+	tools.SetCodeRootForSession(sessionID, root)
+	snapshot, err := tools.NewGitSnapshot(repo, commit, root, "main")
+	utils.NilError(t, err)
+	tools.SetGitSnapshotForSession(sessionID, snapshot)
+
+	result, err := tools.ListDirectory(ctx, &tools.ListLSInput{Repository: tools.RepositoryCode, Tree: true, Depth: 2})
+	utils.NilError(t, err)
+	utils.Equal(t, strings.Contains(result.Output, "├── internal/\n│   └── deep/\n└── main.go"), true)
+	utils.Equal(t, strings.Contains(result.Output, "x.go"), false)
+	utils.Equal(t, strings.Contains(result.Output, "scratch.go"), false)
+}
+
+func TestListDirectory_NegativeDepth(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	result, err := tools.ListDirectory(context.Background(), &tools.ListLSInput{Repository: tools.RepositoryDocs, Depth: -1})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["error"], "format_error")
+}
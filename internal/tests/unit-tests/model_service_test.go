@@ -0,0 +1,314 @@
+package unit_tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"narrabyte/internal/models"
+	"narrabyte/internal/services"
+	"narrabyte/internal/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelConfigService_SeedDefaultModels_EnablesMissingCuratedModels(t *testing.T) {
+	var upserted []string
+	repo := &mocks.ModelSettingRepositoryMock{
+		// Anthropic was turned off in an earlier session, then Sonnet re-enabled
+		ListFunc: func() ([]models.ModelSetting, error) {
+			return []models.ModelSetting{
+				{ModelKey: "anthropic:claude-sonnet-5", Provider: "anthropic", Enabled: true},
+				{ModelKey: "anthropic:claude-opus-4-8", Provider: "anthropic", Enabled: false},
+				{ModelKey: "anthropic:claude-opus-4-7", Provider: "anthropic", Enabled: false},
+			}, nil
+		},
+		UpsertFunc: func(modelKey, provider string, enabled bool) (*models.ModelSetting, error) {
+			upserted = append(upserted, modelKey)
+			return &models.ModelSetting{ModelKey: modelKey, Provider: provider, Enabled: enabled}, nil
+		},
+	}
+	service := services.NewModelConfigService(repo)
+	assert.NoError(t, service.Startup(context.Background()))
+	upserted = nil
+
+	created, err := service.SeedDefaultModels("anthropic")
+	assert.NoError(t, err)
+	if assert.Len(t, created, 1) {
+		assert.Equal(t, "anthropic:claude-opus-4-8", created[0].Key)
+		assert.True(t, created[0].Enabled)
+	}
+	assert.Equal(t, []string{"anthropic:claude-opus-4-8"}, upserted)
+
+	again, err := service.SeedDefaultModels("anthropic")
+	assert.NoError(t, err)
+	assert.Empty(t, again)
+}
+
+func TestModelConfigService_SeedDefaultModels_OnlyInstallsShippedModels(t *testing.T) {
+	for _, providerID := range []string{"openai", "anthropic", "gemini"} {
+		service := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+		assert.NoError(t, service.Startup(context.Background()))
+		_, err := service.SetProviderEnabled(providerID, false)
+		assert.NoError(t, err)
+
+		created, err := service.SeedDefaultModels(providerID)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, created, providerID)
+		for _, seeded := range created {
+			model, err := service.GetModel(seeded.Key)
+			if assert.NoError(t, err, seeded.Key) {
+				assert.Equal(t, providerID, model.ProviderID)
+				assert.NotEmpty(t, model.APIName, seeded.Key)
+				assert.True(t, model.Enabled, seeded.Key)
+			}
+		}
+	}
+}
+
+func TestModelConfigService_SeedDefaultModels_UnknownProvider(t *testing.T) {
+	service := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, service.Startup(context.Background()))
+
+	_, err := service.SeedDefaultModels("unknown")
+	assert.Error(t, err)
+	_, err = service.SeedDefaultModels(" ")
+	assert.Error(t, err)
+}
+
+func TestModelConfigService_Startup_RestoresPersistedModels(t *testing.T) {
+	repo := &mocks.ModelSettingRepositoryMock{
+		ListFunc: func() ([]models.ModelSetting, error) {
+			return []models.ModelSetting{{
+				ModelKey:        "openai:gpt-legacy",
+				Provider:        "openai",
+				Enabled:         true,
+				DisplayName:     "GPT Legacy",
+				APIName:         "gpt-legacy",
+				ReasoningEffort: "low",
+			}}, nil
+		},
+	}
+	service := services.NewModelConfigService(repo)
+	assert.NoError(t, service.Startup(context.Background()))
+
+	model, err := service.GetModel("openai:gpt-legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, "gpt-legacy", model.APIName)
+	assert.Equal(t, "OpenAI", model.ProviderName)
+	assert.True(t, model.Enabled)
+}
+
+func TestModelConfigService_SetModelPriority_OrdersGroup(t *testing.T) {
+	service := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, service.Startup(context.Background()))
+
+	model, err := service.SetModelPriority("anthropic:claude-opus-4-7", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, model.Priority)
+
+	groups, err := service.ListModelGroups()
+	assert.NoError(t, err)
+	for _, group := range groups {
+		if group.ProviderID == "anthropic" {
+			assert.Equal(t, "anthropic:claude-opus-4-7", group.Models[0].Key)
+		}
+	}
+
+	_, err = service.SetModelPriority("missing", 1)
+	assert.Error(t, err)
+}
+
+func TestModelConfigService_SetModelShowReasoning(t *testing.T) {
+	hidden := false
+	repo := &mocks.ModelSettingRepositoryMock{
+		ListFunc: func() ([]models.ModelSetting, error) {
+			return []models.ModelSetting{{ModelKey: "openai:gpt-5.4", Provider: "openai", Enabled: true, ShowReasoning: &hidden}}, nil
+		},
+	}
+	service := services.NewModelConfigService(repo)
+	assert.NoError(t, service.Startup(context.Background()))
+
+	model, err := service.GetModel("openai:gpt-5.4")
+	assert.NoError(t, err)
+	assert.False(t, model.ShowReasoning)
+
+	// Models without a stored preference hide reasoning until the user opts in
+	model, err = service.GetModel("openai:gpt-5.4-mini")
+	assert.NoError(t, err)
+	assert.False(t, model.ShowReasoning)
+
+	model, err = service.SetModelShowReasoning("openai:gpt-5.4", true)
+	assert.NoError(t, err)
+	assert.True(t, model.ShowReasoning)
+
+	_, err = service.SetModelShowReasoning("missing", true)
+	assert.Error(t, err)
+}
+
+func TestModelConfigService_SetModelReasoningBudget(t *testing.T) {
+	repo := &mocks.ModelSettingRepositoryMock{
+		ListFunc: func() ([]models.ModelSetting, error) {
+			return []models.ModelSetting{{ModelKey: "openai:gpt-5.4", Provider: "openai", Enabled: true, ReasoningTokenBudget: 5000, ReasoningBudgetAction: "abort"}}, nil
+		},
+	}
+	service := services.NewModelConfigService(repo)
+	assert.NoError(t, service.Startup(context.Background()))
+
+	model, err := service.GetModel("openai:gpt-5.4")
+	assert.NoError(t, err)
+	assert.Equal(t, 5000, model.ReasoningTokenBudget)
+	assert.Equal(t, "abort", model.ReasoningBudgetAction)
+
+	model, err = service.SetModelReasoningBudget("openai:gpt-5.4-mini", 2000, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, model.ReasoningTokenBudget)
+	assert.Equal(t, "warn", model.ReasoningBudgetAction)
+
+	model, err = service.SetModelReasoningBudget("openai:gpt-5.4", 0, "disable")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, model.ReasoningTokenBudget)
+
+	_, err = service.SetModelReasoningBudget("openai:gpt-5.4", 100, "explode")
+	assert.Error(t, err)
+	_, err = service.SetModelReasoningBudget("openai:gpt-5.4", -1, "warn")
+	assert.Error(t, err)
+}
+
+func TestModelConfigService_SetModelsEnabled_Bulk(t *testing.T) {
+	var persisted []string
+	repo := &mocks.ModelSettingRepositoryMock{
+		SetEnabledForKeysFunc: func(modelKeys []string, enabled bool) error {
+			persisted = modelKeys
+			return nil
+		},
+	}
+	service := services.NewModelConfigService(repo)
+	assert.NoError(t, service.Startup(context.Background()))
+
+	updated, err := service.SetModelsEnabled([]string{"openai:gpt-5.4", "openai:gpt-5.4-mini", "openai:gpt-5.4"}, false)
+	assert.NoError(t, err)
+	assert.Len(t, updated, 2)
+	assert.ElementsMatch(t, []string{"openai:gpt-5.4", "openai:gpt-5.4-mini"}, persisted)
+	for _, mdl := range updated {
+		assert.False(t, mdl.Enabled)
+	}
+
+	persisted = nil
+	_, err = service.SetModelsEnabled([]string{"openai:gpt-5.5", "missing"}, false)
+	assert.Error(t, err)
+	assert.Nil(t, persisted)
+
+	model, err := service.GetModel("openai:gpt-5.5")
+	assert.NoError(t, err)
+	assert.True(t, model.Enabled)
+}
+
+func TestModelConfigService_RegisterCustomModel(t *testing.T) {
+	var saved *models.ModelSetting
+	repo := &mocks.ModelSettingRepositoryMock{
+		SaveFunc: func(setting *models.ModelSetting) (*models.ModelSetting, error) {
+			saved = setting
+			return setting, nil
+		},
+	}
+	service := services.NewModelConfigService(repo)
+	assert.NoError(t, service.Startup(context.Background()))
+
+	model, err := service.RegisterCustomModel("litellm", "Llama 4", "llama-4-70b", "http://localhost:4000/v1/")
+	assert.NoError(t, err)
+	assert.Equal(t, "litellm:llama-4-70b", model.Key)
+	assert.Equal(t, models.ProviderKindOpenAICompatible, model.ProviderKind)
+	assert.Equal(t, "http://localhost:4000/v1", model.BaseURL)
+	assert.Equal(t, "http://localhost:4000/v1", saved.BaseURL)
+
+	fetched, err := service.GetModel("litellm:llama-4-70b")
+	assert.NoError(t, err)
+	assert.Equal(t, "litellm", fetched.ProviderID)
+	assert.True(t, fetched.Enabled)
+}
+
+func TestModelConfigService_GetModel_CarriesProviderCapabilities(t *testing.T) {
+	service := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, service.Startup(context.Background()))
+
+	claude, err := service.GetModel("anthropic:claude-sonnet-5")
+	assert.NoError(t, err)
+	assert.True(t, claude.Capabilities.Thinking)
+	assert.False(t, claude.Capabilities.StructuredOutput)
+	assert.Equal(t, 200000, claude.Capabilities.MaxContextTokens)
+
+	custom, err := service.RegisterCustomModel("litellm", "Llama 4", "llama-4-70b", "http://localhost:4000/v1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ModelCapabilities{}, custom.Capabilities)
+}
+
+func TestDropUnsupportedOptions_WarnsAndDropsUnsupportedOptions(t *testing.T) {
+	thinking := true
+	model := &models.LLMModel{
+		DisplayName:     "Mini",
+		ProviderID:      "openai",
+		ReasoningEffort: "high",
+		Thinking:        &thinking,
+		Capabilities:    models.ModelCapabilities{MaxContextTokens: 8000},
+	}
+	settings := &models.AppSettings{StructuredSummary: true, ExistingDocsContextChars: 20000}
+
+	warnings := services.DropUnsupportedOptions(model, settings)
+	assert.Equal(t, []string{
+		"Mini does not support thinking; it is turned off",
+		"Mini does not support a reasoning effort; ignoring \"high\"",
+		"Mini does not support structured output; using a free-text summary",
+		"Mini has a 8000 token context; limiting existing docs context to 8000 characters",
+	}, warnings)
+	assert.NotNil(t, model.Thinking)
+	assert.False(t, *model.Thinking)
+	assert.Empty(t, model.ReasoningEffort)
+	assert.False(t, settings.StructuredSummary)
+	assert.Equal(t, 8000, settings.ExistingDocsContextChars)
+}
+
+func TestDropUnsupportedOptions_KeepsSupportedOptions(t *testing.T) {
+	model := &models.LLMModel{
+		DisplayName:     "Claude",
+		ProviderID:      "anthropic",
+		ReasoningEffort: "high",
+		Capabilities:    models.ModelCapabilities{Thinking: true, ReasoningEffort: true, MaxContextTokens: 200000},
+	}
+	settings := &models.AppSettings{StructuredSummary: true, ExistingDocsContextChars: 20000}
+
+	warnings := services.DropUnsupportedOptions(model, settings)
+	assert.Empty(t, warnings, "structured summaries are not requested from Anthropic models")
+	assert.Nil(t, model.Thinking)
+	assert.Equal(t, "high", model.ReasoningEffort)
+	assert.Equal(t, 20000, settings.ExistingDocsContextChars)
+}
+
+func TestModelConfigService_RegisterCustomModel_InvalidURL(t *testing.T) {
+	service := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, service.Startup(context.Background()))
+
+	for _, baseURL := range []string{"", "localhost:4000", "ftp://example.com", "http://", "https://example.com/v1?x=1"} {
+		_, err := service.RegisterCustomModel("litellm", "", "llama", baseURL)
+		assert.Error(t, err, baseURL)
+	}
+	_, err := service.RegisterCustomModel("openai", "", "gpt-5.5", "https://example.com/v1")
+	assert.Error(t, err)
+}
+
+func TestModelConfigService_CheckEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	service := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, service.CheckEndpoint(server.URL+"/v1"))
+
+	unreachable := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	unreachable.Close()
+	assert.Error(t, service.CheckEndpoint(unreachable.URL+"/v1"))
+	assert.Error(t, service.CheckEndpoint("not a url"))
+}
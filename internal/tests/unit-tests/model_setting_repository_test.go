@@ -0,0 +1,70 @@
+package unit_tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"narrabyte/internal/database"
+	"narrabyte/internal/repositories"
+)
+
+func newModelSettingRepository(t *testing.T) repositories.ModelSettingRepository {
+	t.Helper()
+	db, err := database.Init(database.Config{Path: filepath.Join(t.TempDir(), "narrabyte.db")})
+	if err != nil {
+		t.Fatalf("init database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("database handle: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return repositories.NewModelSettingRepository(db)
+}
+
+func TestModelSettingRepository_SetPriorityLeavesUntouchedModelDisabled(t *testing.T) {
+	repo := newModelSettingRepository(t)
+
+	_, err := repo.SetPriority("openai:gpt-5", "openai", 3)
+	assert.NoError(t, err)
+	_, err = repo.SetShowReasoning("openai:gpt-5-mini", "openai", true)
+	assert.NoError(t, err)
+	_, err = repo.SetReasoningBudget("openai:gpt-5-nano", "openai", 1000, "warn")
+	assert.NoError(t, err)
+
+	for _, key := range []string{"openai:gpt-5", "openai:gpt-5-mini", "openai:gpt-5-nano"} {
+		setting, err := repo.GetByKey(key)
+		if assert.NoError(t, err) && assert.NotNil(t, setting, key) {
+			assert.False(t, setting.Enabled, key)
+		}
+	}
+	setting, err := repo.GetByKey("openai:gpt-5")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, setting.Priority)
+	}
+}
+
+func TestModelSettingRepository_SetPriorityKeepsEnabledModelEnabled(t *testing.T) {
+	repo := newModelSettingRepository(t)
+
+	_, err := repo.Upsert("openai:gpt-5", "openai", true)
+	assert.NoError(t, err)
+	setting, err := repo.SetPriority("openai:gpt-5", "openai", 2)
+	if assert.NoError(t, err) {
+		assert.True(t, setting.Enabled)
+		assert.Equal(t, 2, setting.Priority)
+	}
+}
+
+func TestModelSettingRepository_UpsertStoresDisabled(t *testing.T) {
+	repo := newModelSettingRepository(t)
+
+	_, err := repo.Upsert("openai:gpt-5", "openai", false)
+	assert.NoError(t, err)
+	setting, err := repo.GetByKey("openai:gpt-5")
+	if assert.NoError(t, err) && assert.NotNil(t, setting) {
+		assert.False(t, setting.Enabled)
+	}
+}
@@ -0,0 +1,87 @@
+package unit_tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"narrabyte/internal/llm/tools"
+	"narrabyte/internal/utils"
+)
+
+func planSession(t *testing.T, name string) (context.Context, string) {
+	t.Helper()
+	docsRoot := t.TempDir()
+	sessionID := "plan-test-" + name
+	tools.SetDocsRootForSession(sessionID, docsRoot)
+	t.Cleanup(func() { tools.ClearSession(sessionID) })
+	return tools.ContextWithSession(context.Background(), sessionID), docsRoot
+}
+
+func TestPlanChange_WriteExistingFileIsModify(t *testing.T) {
+	ctx, docsRoot := planSession(t, "existing")
+	utils.NilError(t, os.WriteFile(filepath.Join(docsRoot, "guide.md"), []byte("# Guide\n"), 0o644))
+
+	result, err := tools.PlanChange(ctx, tools.PlanOperationCreate, &tools.PlanChangeInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "guide.md",
+		Rationale:  "Document the new flag",
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["operation"], tools.PlanOperationModify)
+
+	plan := tools.PlannedChangesForSession(ctx)
+	utils.Equal(t, len(plan), 1)
+	utils.Equal(t, plan[0].Path, "guide.md")
+	utils.Equal(t, plan[0].Operation, tools.PlanOperationModify)
+	utils.Equal(t, plan[0].Rationale, "Document the new flag")
+
+	data, err := os.ReadFile(filepath.Join(docsRoot, "guide.md"))
+	utils.NilError(t, err)
+	utils.Equal(t, string(data), "# Guide\n")
+}
+
+func TestPlanChange_MissingFile(t *testing.T) {
+	ctx, _ := planSession(t, "missing")
+
+	result, err := tools.PlanChange(ctx, tools.PlanOperationModify, &tools.PlanChangeInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "missing.md",
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["error"], "not_found")
+	utils.Equal(t, len(tools.PlannedChangesForSession(ctx)), 0)
+}
+
+func TestPlanChange_CreateThenEditAndDelete(t *testing.T) {
+	ctx, docsRoot := planSession(t, "create")
+	in := &tools.PlanChangeInput{Repository: tools.RepositoryDocs, FilePath: "new.md", Rationale: "New page"}
+
+	_, err := tools.PlanChange(ctx, tools.PlanOperationCreate, in)
+	utils.NilError(t, err)
+	_, err = tools.PlanChange(ctx, tools.PlanOperationModify, in)
+	utils.NilError(t, err)
+
+	plan := tools.PlannedChangesForSession(ctx)
+	utils.Equal(t, len(plan), 1)
+	utils.Equal(t, plan[0].Operation, tools.PlanOperationCreate)
+	if _, err := os.Stat(filepath.Join(docsRoot, "new.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected plan to leave new.md unwritten, got %v", err)
+	}
+
+	_, err = tools.PlanChange(ctx, tools.PlanOperationDelete, in)
+	utils.NilError(t, err)
+	utils.Equal(t, len(tools.PlannedChangesForSession(ctx)), 0)
+}
+
+func TestPlanChange_RejectsCodeRepository(t *testing.T) {
+	ctx, _ := planSession(t, "code")
+
+	result, err := tools.PlanChange(ctx, tools.PlanOperationCreate, &tools.PlanChangeInput{
+		Repository: tools.RepositoryCode,
+		FilePath:   "main.go",
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["error"], "format_error")
+}
@@ -22,7 +22,7 @@ func TestRepoLinkService_Register_Success(t *testing.T) {
 	}
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -50,7 +50,7 @@ func TestRepoLinkService_Register_MissingGitRepo(t *testing.T) {
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
 	mockRepo := &mocks.RepoLinkRepositoryMock{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -65,7 +65,7 @@ func TestRepoLinkService_Register_MissingDocumentationRepo(t *testing.T) {
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
 	mockRepo := &mocks.RepoLinkRepositoryMock{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -80,7 +80,7 @@ func TestRepoLinkService_Register_MissingCodebaseRepo(t *testing.T) {
 	mockRepo := &mocks.RepoLinkRepositoryMock{}
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -95,7 +95,7 @@ func TestRepoLinkService_Register_MissingProjectName(t *testing.T) {
 	mockRepo := &mocks.RepoLinkRepositoryMock{}
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -110,7 +110,7 @@ func TestRepoLinkService_Register_MissingBaseBranchForSeparateRepos(t *testing.T
 	mockRepo := &mocks.RepoLinkRepositoryMock{}
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -133,7 +133,7 @@ func TestRepoLinkService_Register_SharedRepoAllowsEmptyBaseBranch(t *testing.T)
 			return nil
 		},
 	}
-	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{})
+	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{}, nil)
 	service.Startup(context.Background())
 
 	sharedDir := t.TempDir()
@@ -153,7 +153,7 @@ func TestRepoLinkService_Register_InitFumaDocsAllowsEmptyBaseBranch(t *testing.T
 			return nil
 		},
 	}
-	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{})
+	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{}, nil)
 	service.Startup(context.Background())
 
 	docDir := t.TempDir()
@@ -173,7 +173,7 @@ func TestRepoLinkService_LinkRepositories_WithLLMInstructions(t *testing.T) {
 	mockRepo := &mocks.RepoLinkRepositoryMock{}
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -202,7 +202,7 @@ func TestRepoLinkService_LinkRepositories_NoLLMInstructions(t *testing.T) {
 	mockRepo := &mocks.RepoLinkRepositoryMock{}
 	fumaTest := services.FumadocsService{}
 	gitSvc := services.GitService{}
-	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc)
+	service := services.NewRepoLinkService(mockRepo, fumaTest, gitSvc, nil)
 
 	ctx := context.Background()
 	service.Startup(ctx)
@@ -224,7 +224,7 @@ func TestRepoLinkService_LinkRepositories_LLMInstructionsIsDirectory(t *testing.
 	mockRepo := &mocks.RepoLinkRepositoryMock{
 		CreateFunc: func(ctx context.Context, link *models.RepoLink) error { return nil },
 	}
-	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{})
+	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{}, nil)
 	service.Startup(context.Background())
 
 	docDir := t.TempDir()
@@ -256,7 +256,7 @@ func TestRepoLinkService_UpdateProjectPaths_RequiresBaseBranch(t *testing.T) {
 			}, nil
 		},
 	}
-	service := services.NewRepoLinkService(repo, services.FumadocsService{}, services.GitService{})
+	service := services.NewRepoLinkService(repo, services.FumadocsService{}, services.GitService{}, nil)
 	service.Startup(context.Background())
 
 	err := service.UpdateProjectPaths(1, "", "", "")
@@ -286,7 +286,7 @@ func TestRepoLinkService_UpdateProjectPaths_UpdatesBaseBranch(t *testing.T) {
 			return nil
 		},
 	}
-	service := services.NewRepoLinkService(repo, services.FumadocsService{}, services.GitService{})
+	service := services.NewRepoLinkService(repo, services.FumadocsService{}, services.GitService{}, nil)
 	service.Startup(context.Background())
 
 	err := service.UpdateProjectPaths(2, "", "", " docs ")
@@ -303,7 +303,7 @@ func TestRepoLinkService_Register_NestedPathsSameRepo(t *testing.T) {
 			return nil
 		},
 	}
-	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{})
+	service := services.NewRepoLinkService(mockRepo, services.FumadocsService{}, services.GitService{}, nil)
 	service.Startup(context.Background())
 
 	// Create a shared repo with docs subdirectory
@@ -318,3 +318,31 @@ func TestRepoLinkService_Register_NestedPathsSameRepo(t *testing.T) {
 	assert.Equal(t, uint(100), link.ID)
 	assert.Equal(t, "", link.DocumentationBaseBranch) // Should allow empty since same repo
 }
+
+func TestRepoLinkService_SetDefaultModelKey_ValidatesModel(t *testing.T) {
+	var updated *models.RepoLink
+	repo := &mocks.RepoLinkRepositoryMock{
+		FindByIDFunc: func(ctx context.Context, id uint) (*models.RepoLink, error) {
+			return &models.RepoLink{ID: id, DefaultModelKey: models.DefaultModelKeyValue}, nil
+		},
+		UpdateFunc: func(ctx context.Context, link *models.RepoLink) error {
+			updated = link
+			return nil
+		},
+	}
+	modelConfigs := services.NewModelConfigService(&mocks.ModelSettingRepositoryMock{})
+	assert.NoError(t, modelConfigs.Startup(context.Background()))
+	service := services.NewRepoLinkService(repo, services.FumadocsService{}, services.GitService{}, modelConfigs)
+	service.Startup(context.Background())
+
+	assert.ErrorContains(t, service.SetDefaultModelKey(1, "openai:retired-model"), "not available")
+	assert.Nil(t, updated)
+
+	assert.NoError(t, service.SetDefaultModelKey(1, " "+models.DefaultModelKeyValue+" "))
+	if assert.NotNil(t, updated) {
+		assert.Equal(t, models.DefaultModelKeyValue, updated.DefaultModelKey)
+	}
+
+	assert.NoError(t, service.SetDefaultModelKey(1, ""))
+	assert.Equal(t, "", updated.DefaultModelKey)
+}
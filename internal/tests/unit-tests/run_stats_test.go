@@ -0,0 +1,73 @@
+package unit_tests
+
+import (
+	"context"
+	"testing"
+
+	"narrabyte/internal/llm/tools"
+	"narrabyte/internal/utils"
+)
+
+func TestRunStats_AccumulatesPerSession(t *testing.T) {
+	ctx := tools.ContextWithSession(context.Background(), "run-stats-session")
+	other := tools.ContextWithSession(context.Background(), "run-stats-other")
+	defer tools.ClearSession("run-stats-session")
+	defer tools.ClearSession("run-stats-other")
+
+	tools.ResetRunStats(ctx)
+	tools.RecordToolCall(ctx, "read")
+	tools.RecordToolCall(ctx, "read")
+	tools.RecordToolCall(ctx, "write")
+	tools.RecordToolCall(other, "grep")
+	tools.RecordFileChange(ctx, "docs/new.md", "created")
+	tools.RecordFileChange(ctx, "docs/a.md", "modified")
+	tools.RecordFileChange(ctx, "docs/b.md", "modified")
+	tools.RecordFileChange(ctx, "docs/c.md", "renamed")
+	tools.RecordTokenUsage(ctx, 100, 20, 120)
+	tools.RecordTokenUsage(ctx, 150, 30, 180)
+
+	stats := tools.RunStatsForSession(ctx)
+	utils.Equal(t, stats.ToolCalls["read"], 2)
+	utils.Equal(t, stats.ToolCalls["write"], 1)
+	utils.Equal(t, stats.ToolCalls["grep"], 0)
+	utils.Equal(t, stats.FilesCreated, 1)
+	utils.Equal(t, stats.FilesModified, 2)
+	utils.Equal(t, stats.FilesDeleted, 0)
+	utils.Equal(t, stats.PromptTokens, 250)
+	utils.Equal(t, stats.CompletionTokens, 50)
+	utils.Equal(t, stats.TotalTokens, 300)
+
+	tools.ResetRunStats(ctx)
+	stats = tools.RunStatsForSession(ctx)
+	utils.Equal(t, len(stats.ToolCalls), 0)
+	utils.Equal(t, stats.TotalTokens, 0)
+	utils.Equal(t, tools.RunStatsForSession(other).ToolCalls["grep"], 1)
+}
+
+func TestRunStats_CountsEachFileOnceWithItsNetChange(t *testing.T) {
+	ctx := tools.ContextWithSession(context.Background(), "run-stats-files")
+	defer tools.ClearSession("run-stats-files")
+
+	tools.ResetRunStats(ctx)
+	// Edited three times
+	tools.RecordFileChange(ctx, "docs/guide.md", "modified")
+	tools.RecordFileChange(ctx, "docs/guide.md", "modified")
+	tools.RecordFileChange(ctx, "docs/guide.md", "modified")
+	// Created, then edited
+	tools.RecordFileChange(ctx, "docs/new.md", "created")
+	tools.RecordFileChange(ctx, "docs/new.md", "modified")
+	// Created, then deleted again
+	tools.RecordFileChange(ctx, "docs/scratch.md", "created")
+	tools.RecordFileChange(ctx, "docs/scratch.md", "deleted")
+	// Deleted, then written again
+	tools.RecordFileChange(ctx, "docs/old.md", "deleted")
+	tools.RecordFileChange(ctx, "docs/old.md", "created")
+	// Edited, then deleted
+	tools.RecordFileChange(ctx, "docs/gone.md", "modified")
+	tools.RecordFileChange(ctx, "docs/gone.md", "deleted")
+
+	stats := tools.RunStatsForSession(ctx)
+	utils.Equal(t, stats.FilesCreated, 1)
+	utils.Equal(t, stats.FilesModified, 2)
+	utils.Equal(t, stats.FilesDeleted, 1)
+}
@@ -0,0 +1,142 @@
+package unit_tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"narrabyte/internal/llm/tools"
+	"narrabyte/internal/utils"
+)
+
+type isolatedSession struct {
+	ctx      context.Context
+	docsRoot string
+	codeRoot string
+	secret   string
+}
+
+func newIsolatedSession(t *testing.T, name string) isolatedSession {
+	t.Helper()
+	s := isolatedSession{
+		docsRoot: t.TempDir(),
+		codeRoot: t.TempDir(),
+		secret:   "secret-of-" + name,
+	}
+	utils.NilError(t, os.WriteFile(filepath.Join(s.docsRoot, "secret.md"), []byte(s.secret+"\n"), 0o644))
+	utils.NilError(t, os.WriteFile(filepath.Join(s.codeRoot, "secret.go"), []byte("// "+s.secret+"\n"), 0o644))
+
+	sessionID := "isolation-" + name
+	tools.SetDocsRootForSession(sessionID, s.docsRoot)
+	tools.SetCodeRootForSession(sessionID, s.codeRoot)
+	t.Cleanup(func() { tools.ClearSession(sessionID) })
+	s.ctx = tools.ContextWithSession(context.Background(), sessionID)
+	return s
+}
+
+func TestSessions_ConcurrentRunsCannotReadEachOther(t *testing.T) {
+	a := newIsolatedSession(t, "a")
+	b := newIsolatedSession(t, "b")
+
+	check := func(self, other isolatedSession) {
+		read := func(repo tools.Repository, path string) string {
+			out, err := tools.ReadFile(self.ctx, &tools.ReadFileInput{Repository: repo, FilePath: path})
+			if err != nil {
+				return err.Error()
+			}
+			return out.Output
+		}
+
+		if got := read(tools.RepositoryDocs, "secret.md"); !strings.Contains(got, self.secret) {
+			t.Errorf("expected own docs file, got %q", got)
+		}
+		if got := read(tools.RepositoryCode, "secret.go"); !strings.Contains(got, self.secret) {
+			t.Errorf("expected own code file, got %q", got)
+		}
+
+		otherDocs := filepath.Join(other.docsRoot, "secret.md")
+		otherCode := filepath.Join(other.codeRoot, "secret.go")
+		relDocs, err := filepath.Rel(self.docsRoot, otherDocs)
+		if err != nil {
+			t.Errorf("rel: %v", err)
+			return
+		}
+		relCode, err := filepath.Rel(self.codeRoot, otherCode)
+		if err != nil {
+			t.Errorf("rel: %v", err)
+			return
+		}
+		for _, attempt := range []struct {
+			repo tools.Repository
+			path string
+		}{
+			{tools.RepositoryDocs, relDocs},
+			{tools.RepositoryDocs, otherDocs},
+			{tools.RepositoryCode, relCode},
+			{tools.RepositoryCode, otherCode},
+		} {
+			if got := read(attempt.repo, attempt.path); strings.Contains(got, other.secret) {
+				t.Errorf("read %s:%s leaked the other session's file", attempt.repo, attempt.path)
+			}
+		}
+
+		grep, err := tools.Grep(self.ctx, &tools.GrepInput{Repository: tools.RepositoryDocs, Pattern: "secret-of-"})
+		if err != nil {
+			t.Errorf("grep: %v", err)
+			return
+		}
+		if strings.Contains(grep.Output, other.secret) || strings.Contains(grep.Output, other.docsRoot) {
+			t.Errorf("grep leaked the other session's docs: %q", grep.Output)
+		}
+		list, err := tools.ListDirectory(self.ctx, &tools.ListLSInput{Repository: tools.RepositoryCode, Path: relCode})
+		if err != nil {
+			t.Errorf("list: %v", err)
+			return
+		}
+		if list.Metadata["error"] == "" {
+			t.Errorf("expected listing the other session's code to fail, got %q", list.Output)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); check(a, b) }()
+		go func() { defer wg.Done(); check(b, a) }()
+	}
+	wg.Wait()
+}
+
+func TestSessions_DefaultContextRejectedWhileSessionsRun(t *testing.T) {
+	s := newIsolatedSession(t, "active")
+	tools.SetListDirectoryBaseRoot(s.docsRoot)
+	defer tools.SetListDirectoryBaseRoot("")
+
+	out, err := tools.ReadFile(context.Background(), &tools.ReadFileInput{Repository: tools.RepositoryDocs, FilePath: "secret.md"})
+	utils.NilError(t, err)
+	utils.Equal(t, out.Metadata["error"], "format_error")
+	utils.Equal(t, strings.Contains(out.Output, tools.ErrNoSession.Error()), true)
+
+	_, err = tools.GetRepositoryRoot(context.Background(), tools.RepositoryDocs)
+	utils.Equal(t, err, tools.ErrNoSession)
+}
+
+func TestSessions_ReleasedRootsNoLongerBlockDefaultContext(t *testing.T) {
+	s := newIsolatedSession(t, "paused")
+	tools.SetListDirectoryBaseRoot(s.docsRoot)
+	defer tools.SetListDirectoryBaseRoot("")
+
+	_, err := tools.GetRepositoryRoot(context.Background(), tools.RepositoryDocs)
+	utils.Equal(t, err, tools.ErrNoSession)
+
+	tools.ReleaseSessionRoots("isolation-paused")
+
+	root, err := tools.GetRepositoryRoot(context.Background(), tools.RepositoryDocs)
+	utils.NilError(t, err)
+	utils.Equal(t, root, s.docsRoot)
+	_, err = tools.GetRepositoryRoot(s.ctx, tools.RepositoryDocs)
+	utils.Equal(t, err != nil, true)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"narrabyte/internal/llm/tools"
 	"narrabyte/internal/utils"
@@ -175,6 +176,7 @@ func TestWriteFile_OverwriteExistingFile(t *testing.T) {
 	utils.Equal(t, result.Output, "Overwrote file: "+filepath.ToSlash(fullPath))
 	utils.Equal(t, result.Metadata["filepath"], filepath.ToSlash(fullPath))
 	utils.Equal(t, result.Metadata["exists"], "true")
+	utils.Equal(t, result.Metadata["status"], "overwritten")
 
 	// Verify file was overwritten with correct content
 	content, err := os.ReadFile(fullPath)
@@ -182,6 +184,38 @@ func TestWriteFile_OverwriteExistingFile(t *testing.T) {
 	utils.Equal(t, string(content), "new content")
 }
 
+func TestWriteFile_IdenticalContentIsUnchanged(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	targetPath := "same.md"
+	fullPath := filepath.Join(tempDir, targetPath)
+	err := os.WriteFile(fullPath, []byte("# Same\n"), 0644)
+	utils.NilError(t, err)
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	utils.NilError(t, os.Chtimes(fullPath, past, past))
+
+	input := &tools.WriteFileInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   targetPath,
+		Content:    "# Same\n",
+	}
+	result, err := tools.WriteFile(context.Background(), input)
+	utils.NilError(t, err)
+
+	utils.Equal(t, result.Metadata["status"], "unchanged")
+	utils.Equal(t, result.Metadata["exists"], "true")
+	utils.Equal(t, result.Metadata["error"], "")
+
+	// The file must not have been rewritten
+	info, err := os.Stat(fullPath)
+	utils.NilError(t, err)
+	utils.Equal(t, info.ModTime().Equal(past), true)
+	content, err := os.ReadFile(fullPath)
+	utils.NilError(t, err)
+	utils.Equal(t, string(content), "# Same\n")
+}
+
 func TestWriteFile_EmptyContent(t *testing.T) {
 	tempDir := t.TempDir()
 	tools.SetListDirectoryBaseRoot(tempDir)
@@ -312,3 +346,68 @@ func TestWriteFile_LargeContent(t *testing.T) {
 	utils.Equal(t, string(content), largeContent)
 	utils.Equal(t, len(content), len(largeContent))
 }
+
+func TestWriteFile_MaxFileSize(t *testing.T) {
+	tempDir := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+	tools.SetMaxFileSize(1024)
+	defer tools.SetMaxFileSize(0)
+
+	result, err := tools.WriteFile(context.Background(), &tools.WriteFileInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "under.md",
+		Content:    strings.Repeat("a", 1024),
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["error"], "")
+	info, err := os.Stat(filepath.Join(tempDir, "under.md"))
+	utils.NilError(t, err)
+	utils.Equal(t, info.Size(), int64(1024))
+
+	result, err = tools.WriteFile(context.Background(), &tools.WriteFileInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "over.md",
+		Content:    strings.Repeat("a", 1025),
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["error"], "format_error")
+	utils.Equal(t, strings.Contains(result.Output, "1025 bytes, over the 1024 byte limit"), true)
+	_, err = os.Stat(filepath.Join(tempDir, "over.md"))
+	utils.Equal(t, os.IsNotExist(err), true)
+}
+
+func TestWriteFile_SymlinkEscapesBase(t *testing.T) {
+	tempDir := t.TempDir()
+	outside := t.TempDir()
+	tools.SetListDirectoryBaseRoot(tempDir)
+
+	utils.NilError(t, os.Symlink(outside, filepath.Join(tempDir, "linked")))
+	utils.NilError(t, os.Symlink(filepath.Join(outside, "target.md"), filepath.Join(tempDir, "dangling.md")))
+	utils.NilError(t, os.MkdirAll(filepath.Join(tempDir, "real"), 0755))
+	utils.NilError(t, os.Symlink(filepath.Join(tempDir, "real"), filepath.Join(tempDir, "alias")))
+
+	for _, path := range []string{"linked/new.md", "linked/nested/new.md", "dangling.md"} {
+		result, err := tools.WriteFile(context.Background(), &tools.WriteFileInput{
+			Repository: tools.RepositoryDocs,
+			FilePath:   path,
+			Content:    "escaped",
+		})
+		utils.NilError(t, err)
+		utils.Equal(t, result.Metadata["error"], "format_error")
+		utils.Equal(t, strings.Contains(result.Output, "escapes"), true)
+	}
+	entries, err := os.ReadDir(outside)
+	utils.NilError(t, err)
+	utils.Equal(t, len(entries), 0)
+
+	// New files under a link that stays inside the root are still allowed
+	result, err := tools.WriteFile(context.Background(), &tools.WriteFileInput{
+		Repository: tools.RepositoryDocs,
+		FilePath:   "alias/new.md",
+		Content:    "inside",
+	})
+	utils.NilError(t, err)
+	utils.Equal(t, result.Metadata["error"], "")
+	_, err = os.Stat(filepath.Join(tempDir, "real", "new.md"))
+	utils.NilError(t, err)
+}
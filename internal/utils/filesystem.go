@@ -23,8 +23,9 @@ func HasGitRepo(path string) bool {
 }
 
 // FindGitRepoRoot traverses upward from the given path until it finds a
-// directory containing a .git folder. The second return value reports whether a
-// repository root was discovered.
+// directory containing a .git folder, or a .git file pointing at the git
+// directory elsewhere as submodules and linked worktrees have. The second
+// return value reports whether a repository root was discovered.
 func FindGitRepoRoot(path string) (string, bool) {
 	if strings.TrimSpace(path) == "" {
 		return "", false
@@ -40,7 +41,7 @@ func FindGitRepoRoot(path string) (string, bool) {
 			return "", false
 		}
 		gitDir := filepath.Join(current, ".git")
-		if gitInfo, gitErr := os.Stat(gitDir); gitErr == nil && gitInfo.IsDir() {
+		if gitInfo, gitErr := os.Stat(gitDir); gitErr == nil && (gitInfo.IsDir() || IsGitDirFile(gitDir)) {
 			return current, true
 		}
 		parent := filepath.Dir(current)
@@ -51,6 +52,20 @@ func FindGitRepoRoot(path string) (string, bool) {
 	}
 }
 
+// IsGitDirFile reports whether path is a .git file whose "gitdir:" line
+// points at the git directory elsewhere, rather than the git directory itself.
+func IsGitDirFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(string(data)), "gitdir:")
+}
+
 // SamePath returns true when two filesystem paths resolve to the same absolute
 // location.
 func SamePath(a, b string) bool {
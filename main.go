@@ -45,7 +45,7 @@ func main() {
 	gitService := services.NewGitService()
 	keyringService := services.NewKeyringService()
 	dbService := services.NewDbServices(db, *fumadocsService, *gitService)
-	clientService := services.NewClientService(dbService.RepoLinks, gitService, keyringService, dbService.GenerationSessions, dbService.ModelConfigs)
+	clientService := services.NewClientService(dbService.RepoLinks, gitService, keyringService, dbService.GenerationSessions, dbService.ModelConfigs, dbService.AppSettings, dbService.Templates, dbService.Profiles, fumadocsService)
 
 	// Create application with options
 	err = wails.Run(&options.App{
@@ -81,6 +81,7 @@ func main() {
 			dbService.GenerationSessions,
 			dbService.ModelConfigs,
 			dbService.Templates,
+			dbService.Profiles,
 			fumadocsService,
 			gitService,
 			clientService,